@@ -0,0 +1,47 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/tksky1/glimgate/internal/cli"
+	"github.com/tksky1/glimgate/pkg/config"
+	"github.com/tksky1/glimgate/pkg/database"
+	"github.com/tksky1/glimgate/pkg/storage"
+)
+
+func main() {
+	configPath := flag.String("config", "config/config.yaml", "配置文件路径")
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, cli.Usage)
+	}
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	// 加载配置
+	if err := config.LoadConfig(*configPath); err != nil {
+		log.Fatalf("加载配置文件失败: %v", err)
+	}
+
+	// 连接数据库
+	db, err := database.InitDB()
+	if err != nil {
+		log.Fatalf("初始化数据库失败: %v", err)
+	}
+
+	// 初始化附件存储
+	store, err := storage.InitStorage()
+	if err != nil {
+		log.Fatalf("初始化附件存储失败: %v", err)
+	}
+
+	if err := cli.New(db, store, os.Stdout).Run(flag.Args()); err != nil {
+		log.Fatalf("%v", err)
+	}
+}
@@ -24,14 +24,14 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/api/admin/directions": {
-            "post": {
+        "/api/admin/audit-logs": {
+            "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "管理员创建新的方向",
+                "description": "管理员按操作者、操作类型、对象和时间范围查询审计日志",
                 "consumes": [
                     "application/json"
                 ],
@@ -39,23 +39,70 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "方向管理"
+                    "审计日志"
                 ],
-                "summary": "创建方向",
+                "summary": "查询审计日志",
                 "parameters": [
                     {
-                        "description": "方向信息",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/service.CreateDirectionRequest"
-                        }
+                        "type": "integer",
+                        "description": "操作者ID",
+                        "name": "operator_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "操作类型(create/update/delete)",
+                        "name": "action",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "对象类型(user/direction/problem/submission_point/score)",
+                        "name": "entity_type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "对象ID",
+                        "name": "entity_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "请求ID",
+                        "name": "request_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "开始时间(RFC3339或2006-01-02)",
+                        "name": "start",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "结束时间(RFC3339或2006-01-02)",
+                        "name": "end",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "页码",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "每页数量",
+                        "name": "page_size",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "创建成功",
+                        "description": "获取成功",
                         "schema": {
                             "allOf": [
                                 {
@@ -65,7 +112,8 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/model.Direction"
+                                            "type": "object",
+                                            "additionalProperties": true
                                         }
                                     }
                                 }
@@ -93,14 +141,101 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/admin/directions/{id}": {
+        "/api/admin/audit-logs/export": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "管理员按查询条件将审计日志导出为CSV",
+                "produces": [
+                    "text/csv"
+                ],
+                "tags": [
+                    "审计日志"
+                ],
+                "summary": "导出审计日志",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "操作者ID",
+                        "name": "operator_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "操作类型(create/update/delete)",
+                        "name": "action",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "对象类型(user/direction/problem/submission_point/score)",
+                        "name": "entity_type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "对象ID",
+                        "name": "entity_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "请求ID",
+                        "name": "request_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "开始时间(RFC3339或2006-01-02)",
+                        "name": "start",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "结束时间(RFC3339或2006-01-02)",
+                        "name": "end",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "CSV文件",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "权限不足",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/admin/check-steps/{id}": {
             "put": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "管理员更新方向信息",
+                "description": "管理员修改检查步骤，未提供的字段不修改",
                 "consumes": [
                     "application/json"
                 ],
@@ -108,30 +243,30 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "方向管理"
+                    "代码仓库检查"
                 ],
-                "summary": "更新方向",
+                "summary": "修改检查步骤",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "方向ID",
+                        "description": "检查步骤ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "更新信息",
+                        "description": "检查步骤",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/service.UpdateDirectionRequest"
+                            "$ref": "#/definitions/service.UpdateCheckStepRequest"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "更新成功",
+                        "description": "修改成功",
                         "schema": {
                             "allOf": [
                                 {
@@ -141,7 +276,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/model.Direction"
+                                            "$ref": "#/definitions/model.CheckStep"
                                         }
                                     }
                                 }
@@ -167,7 +302,7 @@ const docTemplate = `{
                         }
                     },
                     "404": {
-                        "description": "方向不存在",
+                        "description": "检查步骤不存在",
                         "schema": {
                             "$ref": "#/definitions/response.Response"
                         }
@@ -180,21 +315,18 @@ const docTemplate = `{
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "管理员删除方向",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "管理员删除检查步骤，不会重新检查已有的提交",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "方向管理"
+                    "代码仓库检查"
                 ],
-                "summary": "删除方向",
+                "summary": "删除检查步骤",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "方向ID",
+                        "description": "检查步骤ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -226,7 +358,7 @@ const docTemplate = `{
                         }
                     },
                     "404": {
-                        "description": "方向不存在",
+                        "description": "检查步骤不存在",
                         "schema": {
                             "$ref": "#/definitions/response.Response"
                         }
@@ -234,14 +366,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/admin/problems": {
-            "post": {
+        "/api/admin/clarifications/unanswered": {
+            "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "管理员创建新的题目",
+                "description": "方向负责人获取负责方向下尚未回复的提问",
                 "consumes": [
                     "application/json"
                 ],
@@ -249,23 +381,20 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "题目管理"
+                    "答疑管理"
                 ],
-                "summary": "创建题目",
+                "summary": "获取待回复答疑队列",
                 "parameters": [
                     {
-                        "description": "题目信息",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/service.CreateProblemRequest"
-                        }
+                        "type": "integer",
+                        "description": "题目ID",
+                        "name": "problem_id",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "创建成功",
+                        "description": "获取成功",
                         "schema": {
                             "allOf": [
                                 {
@@ -275,19 +404,16 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/model.Problem"
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/model.Clarification"
+                                            }
                                         }
                                     }
                                 }
                             ]
                         }
                     },
-                    "400": {
-                        "description": "参数错误",
-                        "schema": {
-                            "$ref": "#/definitions/response.Response"
-                        }
-                    },
                     "401": {
                         "description": "未授权",
                         "schema": {
@@ -303,14 +429,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/admin/problems/{id}": {
-            "put": {
+        "/api/admin/clarifications/{id}/answer": {
+            "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "管理员更新题目信息",
+                "description": "方向负责人回复提问，可选择同时公开",
                 "consumes": [
                     "application/json"
                 ],
@@ -318,30 +444,30 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "题目管理"
+                    "答疑管理"
                 ],
-                "summary": "更新题目",
+                "summary": "回复答疑",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "题目ID",
+                        "description": "答疑ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "更新信息",
+                        "description": "回复内容",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/service.UpdateProblemRequest"
+                            "$ref": "#/definitions/service.AnswerClarificationRequest"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "更新成功",
+                        "description": "回复成功",
                         "schema": {
                             "allOf": [
                                 {
@@ -351,7 +477,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/model.Problem"
+                                            "$ref": "#/definitions/model.Clarification"
                                         }
                                     }
                                 }
@@ -377,20 +503,22 @@ const docTemplate = `{
                         }
                     },
                     "404": {
-                        "description": "题目不存在",
+                        "description": "答疑不存在",
                         "schema": {
                             "$ref": "#/definitions/response.Response"
                         }
                     }
                 }
-            },
-            "delete": {
+            }
+        },
+        "/api/admin/clarifications/{id}/public": {
+            "put": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "管理员删除题目",
+                "description": "方向负责人将已回复的答疑公开或撤回为私有",
                 "consumes": [
                     "application/json"
                 ],
@@ -398,23 +526,44 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "题目管理"
+                    "答疑管理"
                 ],
-                "summary": "删除题目",
+                "summary": "设置答疑公开状态",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "题目ID",
+                        "description": "答疑ID",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "公开状态",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/service.SetClarificationPublicRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "删除成功",
+                        "description": "设置成功",
                         "schema": {
-                            "$ref": "#/definitions/response.Response"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.Clarification"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
                     "400": {
@@ -436,7 +585,7 @@ const docTemplate = `{
                         }
                     },
                     "404": {
-                        "description": "题目不存在",
+                        "description": "答疑不存在",
                         "schema": {
                             "$ref": "#/definitions/response.Response"
                         }
@@ -444,14 +593,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/admin/problems/{id}/submission-points": {
+        "/api/admin/directions": {
             "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "管理员为题目创建提交点",
+                "description": "管理员创建新的方向，描述为Markdown，包含脚本、事件属性、内嵌框架或危险链接时返回参数错误",
                 "consumes": [
                     "application/json"
                 ],
@@ -459,24 +608,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "题目管理"
+                    "方向管理"
                 ],
-                "summary": "创建提交点",
+                "summary": "创建方向",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "题目ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "提交点信息",
+                        "description": "方向信息",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/service.CreateSubmissionPointRequest"
+                            "$ref": "#/definitions/service.CreateDirectionRequest"
                         }
                     }
                 ],
@@ -492,7 +634,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/model.SubmissionPoint"
+                                            "$ref": "#/definitions/model.Direction"
                                         }
                                     }
                                 }
@@ -516,24 +658,18 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/response.Response"
                         }
-                    },
-                    "404": {
-                        "description": "题目不存在",
-                        "schema": {
-                            "$ref": "#/definitions/response.Response"
-                        }
                     }
                 }
             }
         },
-        "/api/admin/scores": {
-            "post": {
+        "/api/admin/directions/{id}": {
+            "put": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "管理员对提交进行评分",
+                "description": "管理员更新方向信息，描述为Markdown，包含脚本、事件属性、内嵌框架或危险链接时返回参数错误",
                 "consumes": [
                     "application/json"
                 ],
@@ -541,23 +677,30 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "评分管理"
+                    "方向管理"
                 ],
-                "summary": "创建评分",
+                "summary": "更新方向",
                 "parameters": [
                     {
-                        "description": "评分信息",
+                        "type": "integer",
+                        "description": "方向ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "更新信息",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/service.CreateScoreRequest"
+                            "$ref": "#/definitions/service.UpdateDirectionRequest"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "评分成功",
+                        "description": "更新成功",
                         "schema": {
                             "allOf": [
                                 {
@@ -567,7 +710,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/model.Score"
+                                            "$ref": "#/definitions/model.Direction"
                                         }
                                     }
                                 }
@@ -591,18 +734,22 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/response.Response"
                         }
+                    },
+                    "404": {
+                        "description": "方向不存在",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
                     }
                 }
-            }
-        },
-        "/api/admin/scores/my": {
-            "get": {
+            },
+            "delete": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "管理员获取自己的评分记录",
+                "description": "管理员删除方向",
                 "consumes": [
                     "application/json"
                 ],
@@ -610,37 +757,29 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "评分管理"
+                    "方向管理"
                 ],
-                "summary": "获取评分者的评分列表",
+                "summary": "删除方向",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "题目ID",
-                        "name": "problem_id",
-                        "in": "query"
+                        "description": "方向ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "获取成功",
+                        "description": "删除成功",
                         "schema": {
-                            "allOf": [
-                                {
-                                    "$ref": "#/definitions/response.Response"
-                                },
-                                {
-                                    "type": "object",
-                                    "properties": {
-                                        "data": {
-                                            "type": "array",
-                                            "items": {
-                                                "$ref": "#/definitions/model.Score"
-                                            }
-                                        }
-                                    }
-                                }
-                            ]
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
                         }
                     },
                     "401": {
@@ -654,18 +793,24 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/response.Response"
                         }
+                    },
+                    "404": {
+                        "description": "方向不存在",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
                     }
                 }
             }
         },
-        "/api/admin/scores/{id}": {
+        "/api/admin/judge-cases/{id}": {
             "put": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "管理员更新评分信息",
+                "description": "管理员修改测试点的顺序和权重，未提供的字段不修改。需要更换测试数据时删除后重新上传",
                 "consumes": [
                     "application/json"
                 ],
@@ -673,30 +818,30 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "评分管理"
+                    "自动评测"
                 ],
-                "summary": "更新评分",
+                "summary": "修改测试点",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "评分ID",
+                        "description": "测试点ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "更新信息",
+                        "description": "测试点信息",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/service.UpdateScoreRequest"
+                            "$ref": "#/definitions/service.UpdateJudgeCaseRequest"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "更新成功",
+                        "description": "修改成功",
                         "schema": {
                             "allOf": [
                                 {
@@ -706,7 +851,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/model.Score"
+                                            "$ref": "#/definitions/model.JudgeCase"
                                         }
                                     }
                                 }
@@ -732,7 +877,7 @@ const docTemplate = `{
                         }
                     },
                     "404": {
-                        "description": "评分不存在",
+                        "description": "测试点不存在",
                         "schema": {
                             "$ref": "#/definitions/response.Response"
                         }
@@ -745,21 +890,18 @@ const docTemplate = `{
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "管理员删除评分",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "管理员删除测试点及其测试数据，不可恢复，不会重新评测已有的提交",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "评分管理"
+                    "自动评测"
                 ],
-                "summary": "删除评分",
+                "summary": "删除测试点",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "评分ID",
+                        "description": "测试点ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -791,7 +933,7 @@ const docTemplate = `{
                         }
                     },
                     "404": {
-                        "description": "评分不存在",
+                        "description": "测试点不存在",
                         "schema": {
                             "$ref": "#/definitions/response.Response"
                         }
@@ -799,59 +941,42 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/admin/submission-points/{id}": {
-            "put": {
+        "/api/admin/judge-cases/{id}/{file}": {
+            "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "管理员更新提交点信息",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "管理员下载测试点的输入(input)或标准答案(output)",
                 "produces": [
-                    "application/json"
+                    "application/octet-stream"
                 ],
                 "tags": [
-                    "题目管理"
+                    "自动评测"
                 ],
-                "summary": "更新提交点",
+                "summary": "下载测试数据",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "提交点ID",
+                        "description": "测试点ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "更新信息",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/service.UpdateSubmissionPointRequest"
-                        }
+                        "type": "string",
+                        "description": "input或output",
+                        "name": "file",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "更新成功",
+                        "description": "测试数据",
                         "schema": {
-                            "allOf": [
-                                {
-                                    "$ref": "#/definitions/response.Response"
-                                },
-                                {
-                                    "type": "object",
-                                    "properties": {
-                                        "data": {
-                                            "$ref": "#/definitions/model.SubmissionPoint"
-                                        }
-                                    }
-                                }
-                            ]
+                            "type": "file"
                         }
                     },
                     "400": {
@@ -873,34 +998,33 @@ const docTemplate = `{
                         }
                     },
                     "404": {
-                        "description": "提交点不存在",
+                        "description": "测试点不存在",
                         "schema": {
                             "$ref": "#/definitions/response.Response"
                         }
                     }
                 }
-            },
+            }
+        },
+        "/api/admin/problem-attachments/{id}": {
             "delete": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "管理员删除提交点",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "管理员删除题目附件，删除后可从回收站恢复，回收站清理时文件一并删除",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "题目管理"
+                    "题目附件"
                 ],
-                "summary": "删除提交点",
+                "summary": "删除题目附件",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "提交点ID",
+                        "description": "附件ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -930,18 +1054,24 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/response.Response"
                         }
+                    },
+                    "404": {
+                        "description": "附件不存在",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
                     }
                 }
             }
         },
-        "/api/admin/submissions/review": {
-            "get": {
+        "/api/admin/problem-hints/{id}": {
+            "put": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "管理员获取需要评分的提交列表",
+                "description": "管理员更新提示的内容、惩罚分或顺序，未传的字段不修改。惩罚分在选手解锁时确定，修改后只影响之后的解锁",
                 "consumes": [
                     "application/json"
                 ],
@@ -949,20 +1079,30 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "提交管理"
+                    "题目提示"
                 ],
-                "summary": "获取待评分的提交列表",
+                "summary": "更新题目提示",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "题目ID",
-                        "name": "problem_id",
-                        "in": "query"
+                        "description": "提示ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "更新信息",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/service.UpdateHintRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "获取成功",
+                        "description": "更新成功",
                         "schema": {
                             "allOf": [
                                 {
@@ -972,16 +1112,19 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "type": "array",
-                                            "items": {
-                                                "$ref": "#/definitions/model.Submission"
-                                            }
+                                            "$ref": "#/definitions/model.ProblemHint"
                                         }
                                     }
                                 }
                             ]
                         }
                     },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
                     "401": {
                         "description": "未授权",
                         "schema": {
@@ -993,62 +1136,49 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/response.Response"
                         }
+                    },
+                    "404": {
+                        "description": "提示不存在",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
                     }
                 }
-            }
-        },
-        "/api/admin/users": {
-            "get": {
+            },
+            "delete": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "管理员获取用户列表",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "管理员删除提示，已有选手解锁的提示不能删除",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "用户管理"
+                    "题目提示"
                 ],
-                "summary": "获取用户列表",
+                "summary": "删除题目提示",
                 "parameters": [
                     {
                         "type": "integer",
-                        "default": 1,
-                        "description": "页码",
-                        "name": "page",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "default": 10,
-                        "description": "每页数量",
-                        "name": "page_size",
-                        "in": "query"
+                        "description": "提示ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "获取成功",
+                        "description": "删除成功",
                         "schema": {
-                            "allOf": [
-                                {
-                                    "$ref": "#/definitions/response.Response"
-                                },
-                                {
-                                    "type": "object",
-                                    "properties": {
-                                        "data": {
-                                            "type": "object",
-                                            "additionalProperties": true
-                                        }
-                                    }
-                                }
-                            ]
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
                         }
                     },
                     "401": {
@@ -1062,35 +1192,43 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/response.Response"
                         }
+                    },
+                    "404": {
+                        "description": "提示不存在",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
                     }
                 }
             }
         },
-        "/api/admin/users/{id}": {
+        "/api/admin/problems": {
             "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "管理员获取指定用户的详细信息",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "管理员获取包含草稿、定时发布和已归档在内的全部题目，可按方向和可见状态筛选",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "用户管理"
+                    "题目管理"
                 ],
-                "summary": "获取指定用户信息",
+                "summary": "获取全部题目列表",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "用户ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "方向ID",
+                        "name": "direction_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "可见状态(draft/scheduled/published/archived)",
+                        "name": "status",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -1105,40 +1243,43 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/model.User"
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/model.Problem"
+                                            }
                                         }
                                     }
                                 }
                             ]
                         }
                     },
-                    "401": {
-                        "description": "未授权",
+                    "400": {
+                        "description": "参数错误",
                         "schema": {
                             "$ref": "#/definitions/response.Response"
                         }
                     },
-                    "403": {
-                        "description": "权限不足",
+                    "401": {
+                        "description": "未授权",
                         "schema": {
                             "$ref": "#/definitions/response.Response"
                         }
                     },
-                    "404": {
-                        "description": "用户不存在",
+                    "403": {
+                        "description": "权限不足",
                         "schema": {
                             "$ref": "#/definitions/response.Response"
                         }
                     }
                 }
             },
-            "put": {
+            "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "管理员更新用户信息",
+                "description": "管理员创建新的题目，未指定status时创建为草稿，发布前选手不可见；status为scheduled时需指定publish_at，到期自动发布。可设置难度等级(1-5)、预计用时和标签，team_size为2-20时为组队题目。题面为Markdown，包含脚本、事件属性、内嵌框架或危险链接时返回参数错误",
                 "consumes": [
                     "application/json"
                 ],
@@ -1146,30 +1287,23 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "用户管理"
+                    "题目管理"
                 ],
-                "summary": "更新用户信息",
+                "summary": "创建题目",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "用户ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "更新信息",
+                        "description": "题目信息",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/service.UpdateUserRequest"
+                            "$ref": "#/definitions/service.CreateProblemRequest"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "更新成功",
+                        "description": "创建成功",
                         "schema": {
                             "allOf": [
                                 {
@@ -1179,7 +1313,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/model.User"
+                                            "$ref": "#/definitions/model.Problem"
                                         }
                                     }
                                 }
@@ -1203,44 +1337,64 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/response.Response"
                         }
-                    },
-                    "404": {
-                        "description": "用户不存在",
-                        "schema": {
-                            "$ref": "#/definitions/response.Response"
-                        }
                     }
                 }
-            },
-            "delete": {
+            }
+        },
+        "/api/admin/problems/import": {
+            "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "管理员删除用户",
+                "description": "管理员上传题目包（包含problem.yaml的ZIP压缩包，或单独的problem.yaml）创建或更新题目。按方向名称和slug匹配已有题目，只应用有差异的字段、提交点和附件，重复导入同一题目包不会产生变更；题目包中没有的提交点会被删除，已有提交的提交点不能删除；清单填写attachments时按文件名和SHA-256同步attachments目录中的附件。",
                 "consumes": [
-                    "application/json"
+                    "multipart/form-data"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "用户管理"
+                    "题目管理"
                 ],
-                "summary": "删除用户",
+                "summary": "导入题目包",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "用户ID",
-                        "name": "id",
-                        "in": "path",
+                        "type": "file",
+                        "description": "题目包(.zip/.yaml/.yml)",
+                        "name": "file",
+                        "in": "formData",
                         "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "仅计算差异不导入",
+                        "name": "dry_run",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "删除成功",
+                        "description": "导入结果及差异",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/service.ImportBundleResult"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
                         "schema": {
                             "$ref": "#/definitions/response.Response"
                         }
@@ -1258,7 +1412,7 @@ const docTemplate = `{
                         }
                     },
                     "404": {
-                        "description": "用户不存在",
+                        "description": "方向不存在",
                         "schema": {
                             "$ref": "#/definitions/response.Response"
                         }
@@ -1266,33 +1420,89 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/auth/login": {
-            "post": {
-                "description": "用户登录接口",
-                "consumes": [
-                    "application/json"
+        "/api/admin/problems/search": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
                 ],
+                "description": "管理员搜索包含草稿、定时发布和已归档在内的全部题目，参数与选手侧搜索相同，status为空时不限可见状态",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "用户管理"
+                    "题目管理"
                 ],
-                "summary": "用户登录",
+                "summary": "搜索全部题目",
                 "parameters": [
                     {
-                        "description": "登录信息",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/service.LoginRequest"
-                        }
+                        "type": "string",
+                        "description": "关键词",
+                        "name": "keyword",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "方向ID",
+                        "name": "direction_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "可见状态(draft/scheduled/published/archived)",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "标签，多个以逗号分隔，需全部包含",
+                        "name": "tags",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "最低难度(1-5)",
+                        "name": "min_difficulty",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "最高难度(1-5)",
+                        "name": "max_difficulty",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "排序字段(relevance/id/title/difficulty/estimated_minutes/created_at/published_at)",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "asc",
+                        "description": "排序方向(asc/desc)",
+                        "name": "order",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "页码",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "每页数量",
+                        "name": "page_size",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "登录成功",
+                        "description": "获取成功",
                         "schema": {
                             "allOf": [
                                 {
@@ -1302,7 +1512,8 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/service.LoginResponse"
+                                            "type": "object",
+                                            "additionalProperties": true
                                         }
                                     }
                                 }
@@ -1316,7 +1527,13 @@ const docTemplate = `{
                         }
                     },
                     "401": {
-                        "description": "认证失败",
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "权限不足",
                         "schema": {
                             "$ref": "#/definitions/response.Response"
                         }
@@ -1324,33 +1541,24 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/auth/register": {
-            "post": {
-                "description": "用户注册接口",
-                "consumes": [
-                    "application/json"
+        "/api/admin/problems/tags": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
                 ],
+                "description": "管理员获取全部题目（含草稿）使用的标签及题目数量，按题目数量降序排列",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "用户管理"
-                ],
-                "summary": "用户注册",
-                "parameters": [
-                    {
-                        "description": "注册信息",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/service.RegisterRequest"
-                        }
-                    }
+                    "题目管理"
                 ],
+                "summary": "获取全部题目标签",
                 "responses": {
                     "200": {
-                        "description": "注册成功",
+                        "description": "获取成功",
                         "schema": {
                             "allOf": [
                                 {
@@ -1360,21 +1568,24 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/model.User"
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/repository.TagCount"
+                                            }
                                         }
                                     }
                                 }
                             ]
                         }
                     },
-                    "400": {
-                        "description": "参数错误",
+                    "401": {
+                        "description": "未授权",
                         "schema": {
                             "$ref": "#/definitions/response.Response"
                         }
                     },
-                    "500": {
-                        "description": "内部错误",
+                    "403": {
+                        "description": "权限不足",
                         "schema": {
                             "$ref": "#/definitions/response.Response"
                         }
@@ -1382,19 +1593,29 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/directions": {
+        "/api/admin/problems/unlock-stats": {
             "get": {
-                "description": "获取所有方向的列表",
-                "consumes": [
-                    "application/json"
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
                 ],
+                "description": "管理员查看各题目的解锁条件以及已解锁的选手人数，可按方向筛选。没有解锁条件的题目对全部选手解锁，管理员和已删除的用户不计入",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "方向管理"
+                    "题目管理"
+                ],
+                "summary": "获取题目解锁统计",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "方向ID",
+                        "name": "direction_id",
+                        "in": "query"
+                    }
                 ],
-                "summary": "获取方向列表",
                 "responses": {
                     "200": {
                         "description": "获取成功",
@@ -1409,7 +1630,7 @@ const docTemplate = `{
                                         "data": {
                                             "type": "array",
                                             "items": {
-                                                "$ref": "#/definitions/model.Direction"
+                                                "$ref": "#/definitions/service.ProblemUnlockStat"
                                             }
                                         }
                                     }
@@ -1417,8 +1638,14 @@ const docTemplate = `{
                             ]
                         }
                     },
-                    "500": {
-                        "description": "内部错误",
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "权限不足",
                         "schema": {
                             "$ref": "#/definitions/response.Response"
                         }
@@ -1426,23 +1653,25 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/directions/{id}": {
+        "/api/admin/problems/{id}": {
             "get": {
-                "description": "根据ID获取方向的详细信息",
-                "consumes": [
-                    "application/json"
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
                 ],
+                "description": "管理员查看任意可见状态的题目详情，用于发布前预览草稿",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "方向管理"
+                    "题目管理"
                 ],
-                "summary": "获取方向详情",
+                "summary": "预览题目详情",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "方向ID",
+                        "description": "题目ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -1460,7 +1689,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/model.Direction"
+                                            "$ref": "#/definitions/model.Problem"
                                         }
                                     }
                                 }
@@ -1473,18 +1702,33 @@ const docTemplate = `{
                             "$ref": "#/definitions/response.Response"
                         }
                     },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "权限不足",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
                     "404": {
-                        "description": "方向不存在",
+                        "description": "题目不存在",
                         "schema": {
                             "$ref": "#/definitions/response.Response"
                         }
                     }
                 }
-            }
-        },
-        "/api/problems": {
-            "get": {
-                "description": "获取题目列表，可按方向筛选",
+            },
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "管理员更新题目信息，未传的字段不修改，tags传空列表时清除全部标签。team_size修改队伍人数上限，已有提交后不能在个人题目和组队题目之间切换，改为0时解散全部队伍。题面为Markdown，包含脚本、事件属性、内嵌框架或危险链接时返回参数错误。标题或题面有变化时记录新版本；significant为true时标记为重要修改，已发布或已归档的题目会通知已提交的选手，并在其题目详情中显示statement_updated",
                 "consumes": [
                     "application/json"
                 ],
@@ -1494,18 +1738,28 @@ const docTemplate = `{
                 "tags": [
                     "题目管理"
                 ],
-                "summary": "获取题目列表",
+                "summary": "更新题目",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "方向ID",
-                        "name": "direction_id",
-                        "in": "query"
+                        "description": "题目ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "更新信息",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/service.UpdateProblemRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "获取成功",
+                        "description": "更新成功",
                         "schema": {
                             "allOf": [
                                 {
@@ -1515,28 +1769,46 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "type": "array",
-                                            "items": {
-                                                "$ref": "#/definitions/model.Problem"
-                                            }
+                                            "$ref": "#/definitions/model.Problem"
                                         }
                                     }
                                 }
                             ]
                         }
                     },
-                    "500": {
-                        "description": "内部错误",
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "权限不足",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "题目不存在",
                         "schema": {
                             "$ref": "#/definitions/response.Response"
                         }
                     }
                 }
-            }
-        },
-        "/api/problems/{id}": {
-            "get": {
-                "description": "根据ID获取题目的详细信息",
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "管理员删除题目",
                 "consumes": [
                     "application/json"
                 ],
@@ -1546,7 +1818,7 @@ const docTemplate = `{
                 "tags": [
                     "题目管理"
                 ],
-                "summary": "获取题目详情",
+                "summary": "删除题目",
                 "parameters": [
                     {
                         "type": "integer",
@@ -1558,21 +1830,9 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "获取成功",
+                        "description": "删除成功",
                         "schema": {
-                            "allOf": [
-                                {
-                                    "$ref": "#/definitions/response.Response"
-                                },
-                                {
-                                    "type": "object",
-                                    "properties": {
-                                        "data": {
-                                            "$ref": "#/definitions/model.Problem"
-                                        }
-                                    }
-                                }
-                            ]
+                            "$ref": "#/definitions/response.Response"
                         }
                     },
                     "400": {
@@ -1581,6 +1841,18 @@ const docTemplate = `{
                             "$ref": "#/definitions/response.Response"
                         }
                     },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "权限不足",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
                     "404": {
                         "description": "题目不存在",
                         "schema": {
@@ -1590,19 +1862,24 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/problems/{id}/submission-points": {
-            "get": {
-                "description": "获取指定题目的提交点列表",
+        "/api/admin/problems/{id}/attachments": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "管理员为题目上传附件（初始代码、数据集、设计稿等），上传时计算SHA-256校验和，同一题目下文件名不能重复，大小上限由storage.max_upload_mb配置",
                 "consumes": [
-                    "application/json"
+                    "multipart/form-data"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "题目管理"
+                    "题目附件"
                 ],
-                "summary": "获取提交点列表",
+                "summary": "上传题目附件",
                 "parameters": [
                     {
                         "type": "integer",
@@ -1610,11 +1887,18 @@ const docTemplate = `{
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "附件",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "获取成功",
+                        "description": "上传成功",
                         "schema": {
                             "allOf": [
                                 {
@@ -1624,10 +1908,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "type": "array",
-                                            "items": {
-                                                "$ref": "#/definitions/model.SubmissionPoint"
-                                            }
+                                            "$ref": "#/definitions/model.ProblemAttachment"
                                         }
                                     }
                                 }
@@ -1640,8 +1921,20 @@ const docTemplate = `{
                             "$ref": "#/definitions/response.Response"
                         }
                     },
-                    "500": {
-                        "description": "内部错误",
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "权限不足",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "题目不存在",
                         "schema": {
                             "$ref": "#/definitions/response.Response"
                         }
@@ -1649,58 +1942,57 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/ranking": {
+        "/api/admin/problems/{id}/export": {
             "get": {
-                "description": "获取指定方向的排行榜",
-                "consumes": [
-                    "application/json"
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
                 ],
+                "description": "管理员将题目及其提交点、附件导出为ZIP格式的题目包，根目录为problem.yaml，附件位于attachments目录，可直接重新导入",
                 "produces": [
-                    "application/json"
+                    "application/zip"
                 ],
                 "tags": [
-                    "评分管理"
+                    "题目管理"
                 ],
-                "summary": "获取排行榜",
+                "summary": "导出题目包",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "方向ID",
-                        "name": "direction_id",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "default": 10,
-                        "description": "限制数量",
-                        "name": "limit",
-                        "in": "query"
+                        "description": "题目ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "获取成功",
+                        "description": "题目包",
                         "schema": {
-                            "allOf": [
-                                {
-                                    "$ref": "#/definitions/response.Response"
-                                },
-                                {
-                                    "type": "object",
-                                    "properties": {
-                                        "data": {
-                                            "type": "array",
-                                            "items": {
-                                                "$ref": "#/definitions/service.RankingItem"
-                                            }
-                                        }
-                                    }
-                                }
-                            ]
+                            "type": "file"
                         }
                     },
-                    "500": {
-                        "description": "内部错误",
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "权限不足",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "题目不存在",
                         "schema": {
                             "$ref": "#/definitions/response.Response"
                         }
@@ -1708,30 +2000,28 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/scores/my": {
+        "/api/admin/problems/{id}/hints": {
             "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "获取当前用户的评分记录",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "管理员获取题目的全部提示内容及各提示的解锁人数，草稿题目同样可用",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "评分管理"
+                    "题目提示"
                 ],
-                "summary": "获取我的评分列表",
+                "summary": "获取题目全部提示",
                 "parameters": [
                     {
                         "type": "integer",
                         "description": "题目ID",
-                        "name": "problem_id",
-                        "in": "query"
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
@@ -1748,7 +2038,7 @@ const docTemplate = `{
                                         "data": {
                                             "type": "array",
                                             "items": {
-                                                "$ref": "#/definitions/model.Score"
+                                                "$ref": "#/definitions/service.ProblemHintStat"
                                             }
                                         }
                                     }
@@ -1756,23 +2046,39 @@ const docTemplate = `{
                             ]
                         }
                     },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
                     "401": {
                         "description": "未授权",
                         "schema": {
                             "$ref": "#/definitions/response.Response"
                         }
+                    },
+                    "403": {
+                        "description": "权限不足",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "题目不存在",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
                     }
                 }
-            }
-        },
-        "/api/submissions": {
+            },
             "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "用户提交作业",
+                "description": "管理员为题目添加提示，每道题目最多10个。内容为Markdown，包含脚本、事件属性、内嵌框架或危险链接时返回参数错误；未指定position时排在最后",
                 "consumes": [
                     "application/json"
                 ],
@@ -1780,23 +2086,30 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "提交管理"
+                    "题目提示"
                 ],
-                "summary": "创建提交",
+                "summary": "创建题目提示",
                 "parameters": [
                     {
-                        "description": "提交信息",
+                        "type": "integer",
+                        "description": "题目ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "提示信息",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/service.CreateSubmissionRequest"
+                            "$ref": "#/definitions/service.CreateHintRequest"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "提交成功",
+                        "description": "创建成功",
                         "schema": {
                             "allOf": [
                                 {
@@ -1806,7 +2119,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/model.Submission"
+                                            "$ref": "#/definitions/model.ProblemHint"
                                         }
                                     }
                                 }
@@ -1824,18 +2137,30 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/response.Response"
                         }
+                    },
+                    "403": {
+                        "description": "权限不足",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "题目不存在",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
                     }
                 }
             }
         },
-        "/api/submissions/my": {
-            "get": {
+        "/api/admin/problems/{id}/prerequisites": {
+            "put": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "获取当前用户的提交列表",
+                "description": "管理员设置题目的解锁条件，替换已有的全部条件，选手需满足全部条件才能查看题面和提交。type为submitted时在前置题目有提交即满足，为score时前置题目的总分不低于min_score才满足。前置题目不能是题目自身，条件之间不能形成循环依赖，传空列表时取消解锁条件",
                 "consumes": [
                     "application/json"
                 ],
@@ -1843,20 +2168,30 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "提交管理"
+                    "题目管理"
                 ],
-                "summary": "获取我的提交列表",
+                "summary": "设置题目解锁条件",
                 "parameters": [
                     {
                         "type": "integer",
                         "description": "题目ID",
-                        "name": "problem_id",
-                        "in": "query"
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "解锁条件",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/service.UpdatePrerequisitesRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "获取成功",
+                        "description": "设置成功",
                         "schema": {
                             "allOf": [
                                 {
@@ -1866,47 +2201,59 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "type": "array",
-                                            "items": {
-                                                "$ref": "#/definitions/service.SubmissionResponse"
-                                            }
+                                            "$ref": "#/definitions/model.Problem"
                                         }
                                     }
                                 }
                             ]
                         }
                     },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
                     "401": {
                         "description": "未授权",
                         "schema": {
                             "$ref": "#/definitions/response.Response"
                         }
-                    }
-                }
-            }
+                    },
+                    "403": {
+                        "description": "权限不足",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "题目不存在",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            }
         },
-        "/api/submissions/{id}": {
+        "/api/admin/problems/{id}/revisions": {
             "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "获取指定提交的详细信息",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "管理员获取题目的修订记录，按版本从新到旧排列，不包含题面。题目创建以及标题、题面、提交点设置每次变化时都会记录新版本",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "提交管理"
+                    "题目管理"
                 ],
-                "summary": "获取提交详情",
+                "summary": "获取题目修订记录",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "提交ID",
+                        "description": "题目ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -1924,7 +2271,10 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/model.Submission"
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/model.ProblemRevision"
+                                            }
                                         }
                                     }
                                 }
@@ -1943,45 +2293,74 @@ const docTemplate = `{
                             "$ref": "#/definitions/response.Response"
                         }
                     },
+                    "403": {
+                        "description": "权限不足",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
                     "404": {
-                        "description": "提交不存在",
+                        "description": "题目不存在",
                         "schema": {
                             "$ref": "#/definitions/response.Response"
                         }
                     }
                 }
-            },
-            "delete": {
+            }
+        },
+        "/api/admin/problems/{id}/revisions/diff": {
+            "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "用户删除自己的提交",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "管理员比较题目的两个版本：标题和提交点设置按字段列出差异，提交点按名称匹配；题面以统一差异格式（unified diff）返回。to默认为最新版本，from默认为to的上一个版本",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "提交管理"
+                    "题目管理"
                 ],
-                "summary": "删除提交",
+                "summary": "比较题目的两个版本",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "提交ID",
+                        "description": "题目ID",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "起始版本",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "目标版本",
+                        "name": "to",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "删除成功",
+                        "description": "获取成功",
                         "schema": {
-                            "$ref": "#/definitions/response.Response"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/service.ProblemRevisionDiff"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
                     "400": {
@@ -2003,7 +2382,7 @@ const docTemplate = `{
                         }
                     },
                     "404": {
-                        "description": "提交不存在",
+                        "description": "题目或版本不存在",
                         "schema": {
                             "$ref": "#/definitions/response.Response"
                         }
@@ -2011,31 +2390,35 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/submissions/{id}/scores": {
+        "/api/admin/problems/{id}/revisions/{version}": {
             "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "获取指定提交的所有评分",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "管理员获取题目指定版本的标题、题面和提交点设置",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "评分管理"
+                    "题目管理"
                 ],
-                "summary": "获取提交的评分列表",
+                "summary": "获取题目的指定版本",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "提交ID",
+                        "description": "题目ID",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "版本号",
+                        "name": "version",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
@@ -2050,10 +2433,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "type": "array",
-                                            "items": {
-                                                "$ref": "#/definitions/model.Score"
-                                            }
+                                            "$ref": "#/definitions/model.ProblemRevision"
                                         }
                                     }
                                 }
@@ -2071,18 +2451,30 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/response.Response"
                         }
+                    },
+                    "403": {
+                        "description": "权限不足",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "题目或版本不存在",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
                     }
                 }
             }
         },
-        "/api/user/profile": {
-            "get": {
+        "/api/admin/problems/{id}/status": {
+            "put": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "获取当前登录用户的信息",
+                "description": "管理员发布、撤回、定时发布或归档题目。status为scheduled时需指定晚于当前的publish_at，到期后自动发布，服务重启不影响；只有已发布的题目可以归档",
                 "consumes": [
                     "application/json"
                 ],
@@ -2090,12 +2482,30 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "用户管理"
+                    "题目管理"
+                ],
+                "summary": "修改题目可见状态",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "题目ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "可见状态",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/service.UpdateProblemStatusRequest"
+                        }
+                    }
                 ],
-                "summary": "获取用户信息",
                 "responses": {
                     "200": {
-                        "description": "获取成功",
+                        "description": "修改成功",
                         "schema": {
                             "allOf": [
                                 {
@@ -2105,21 +2515,33 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/model.User"
+                                            "$ref": "#/definitions/model.Problem"
                                         }
                                     }
                                 }
                             ]
                         }
                     },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
                     "401": {
                         "description": "未授权",
                         "schema": {
                             "$ref": "#/definitions/response.Response"
                         }
                     },
+                    "403": {
+                        "description": "权限不足",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
                     "404": {
-                        "description": "用户不存在",
+                        "description": "题目不存在",
                         "schema": {
                             "$ref": "#/definitions/response.Response"
                         }
@@ -2127,14 +2549,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/users/{id}/scores": {
-            "get": {
+        "/api/admin/problems/{id}/submission-points": {
+            "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "获取指定用户的评分记录",
+                "description": "管理员为题目创建提交点。type为auto时为自动评测提交点，提交内容为源代码，judge_language须为配置的评测语言",
                 "consumes": [
                     "application/json"
                 ],
@@ -2142,22 +2564,95 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "评分管理"
+                    "题目管理"
                 ],
-                "summary": "获取用户的评分列表",
+                "summary": "创建提交点",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "用户ID",
+                        "description": "题目ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
+                    {
+                        "description": "提交点信息",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/service.CreateSubmissionPointRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "创建成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.SubmissionPoint"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "权限不足",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "题目不存在",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/admin/problems/{id}/teams": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "管理员获取题目下的全部队伍及成员，按创建顺序排列",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "组队"
+                ],
+                "summary": "获取题目的队伍列表",
+                "parameters": [
                     {
                         "type": "integer",
                         "description": "题目ID",
-                        "name": "problem_id",
-                        "in": "query"
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
@@ -2174,7 +2669,7 @@ const docTemplate = `{
                                         "data": {
                                             "type": "array",
                                             "items": {
-                                                "$ref": "#/definitions/model.Score"
+                                                "$ref": "#/definitions/service.TeamResponse"
                                             }
                                         }
                                     }
@@ -2193,157 +2688,7619 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/response.Response"
                         }
+                    },
+                    "403": {
+                        "description": "权限不足",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "题目不存在",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "model.Direction": {
-            "type": "object",
-            "required": [
-                "name"
-            ],
-            "properties": {
-                "created_at": {
-                    "type": "string"
-                },
-                "description": {
-                    "type": "string",
+        },
+        "/api/admin/scores": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "管理员对提交进行评分，对同一提交重复评分会更新原评分",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "评分管理"
+                ],
+                "summary": "创建评分",
+                "parameters": [
+                    {
+                        "description": "评分信息",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/service.CreateScoreRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "评分成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.Score"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "权限不足",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/admin/scores/export": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "管理员按方向或题目导出评分宽表：每位有提交的候选人一行，按总分排名（同分同名次）；每个提交点的每位评分者一列分数，另有一列汇总该提交点的评语",
+                "produces": [
+                    "text/csv",
+                    "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+                ],
+                "tags": [
+                    "评分管理"
+                ],
+                "summary": "导出评分汇总表",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "方向ID，与题目ID二选一",
+                        "name": "direction_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "题目ID，与方向ID二选一",
+                        "name": "problem_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "csv",
+                        "description": "导出格式(csv/xlsx)",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "表格文件",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "权限不足",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "方向或题目不存在",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/admin/scores/my": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "管理员获取自己的评分记录",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "评分管理"
+                ],
+                "summary": "获取评分者的评分列表",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "题目ID",
+                        "name": "problem_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "获取成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/model.Score"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "权限不足",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/admin/scores/{id}": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "管理员更新评分信息",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "评分管理"
+                ],
+                "summary": "更新评分",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "评分ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "更新信息",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/service.UpdateScoreRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "更新成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.Score"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "权限不足",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "评分不存在",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "管理员删除评分",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "评分管理"
+                ],
+                "summary": "删除评分",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "评分ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "删除成功",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "权限不足",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "评分不存在",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/admin/submission-points/{id}": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "管理员更新提交点信息，设置有变化时记录题目的新版本；significant为true时标记为重要修改并通知已提交的选手。改为自动评测后已有的提交不会自动评测，需要重新评测",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "题目管理"
+                ],
+                "summary": "更新提交点",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "提交点ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "更新信息",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/service.UpdateSubmissionPointRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "更新成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.SubmissionPoint"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "权限不足",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "提交点不存在",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "管理员删除提交点",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "题目管理"
+                ],
+                "summary": "删除提交点",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "提交点ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "删除成功",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "权限不足",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/admin/submission-points/{id}/check-steps": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "管理员获取代码仓库提交点的检查步骤，按顺序排列",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "代码仓库检查"
+                ],
+                "summary": "获取检查步骤列表",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "提交点ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "获取成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/model.CheckStep"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "权限不足",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "提交点不存在",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "管理员为代码仓库提交点添加检查步骤。file_exists检查仓库内的路径（支持通配符）是否存在；build、test、lint在仓库根目录执行命令，退出码为0表示通过，构建未通过时跳过之后的命令步骤。通过的步骤按points计入建议分数。添加步骤不会重新检查已有的提交",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "代码仓库检查"
+                ],
+                "summary": "添加检查步骤",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "提交点ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "检查步骤",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/service.CreateCheckStepRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "添加成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.CheckStep"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "权限不足",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "提交点不存在",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/admin/submission-points/{id}/checker": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "管理员设置自动评测提交点的检查脚本。检查脚本在选手程序运行后执行，命令末尾追加输入、标准答案和选手输出的文件路径，退出码为0判为通过，其他退出码判为答案错误。checker为空时清除检查脚本，按逐行比较（忽略行尾空白和末尾空行）判定。检查脚本不对选手公开",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "自动评测"
+                ],
+                "summary": "设置检查脚本",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "提交点ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "检查脚本",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/service.UpdateCheckerRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "设置成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.SubmissionPoint"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "权限不足",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "提交点不存在",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/admin/submission-points/{id}/judge-cases": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "管理员获取自动评测提交点的测试点列表，按顺序排列，不包含测试数据",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "自动评测"
+                ],
+                "summary": "获取测试点列表",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "提交点ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "获取成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/model.JudgeCase"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "权限不足",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "提交点不存在",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "管理员为自动评测提交点上传测试点的输入和标准答案，大小上限与附件相同。得分按通过测试点的权重占比折算，添加测试点不会重新评测已有的提交",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "自动评测"
+                ],
+                "summary": "上传测试点",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "提交点ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "输入",
+                        "name": "input",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "标准答案",
+                        "name": "output",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "顺序，默认0",
+                        "name": "position",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "权重，默认1",
+                        "name": "weight",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "上传成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.JudgeCase"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "权限不足",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "提交点不存在",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/admin/submission-points/{id}/recheck": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "管理员将代码仓库提交点下的全部提交重新放入检查队列，用于修改检查步骤之后",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "代码仓库检查"
+                ],
+                "summary": "重新检查提交点",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "提交点ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "已加入检查队列",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/service.RecheckResult"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "权限不足",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "提交点不存在",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/admin/submission-points/{id}/rejudge": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "管理员将自动评测提交点下的全部提交重新放入评测队列，用于修改测试点或检查脚本之后。评测完成后更新系统评分",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "自动评测"
+                ],
+                "summary": "重新评测提交点",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "提交点ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "已加入评测队列",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/service.RejudgeResult"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "权限不足",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "提交点不存在",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/admin/submission-points/{id}/similarity": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "管理员获取提交点下相似度达到阈值的提交分组，相似的提交对连成一组，按组内最高相似度从高到低排列",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "相似度检测"
+                ],
+                "summary": "获取可疑提交分组",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "提交点ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "number",
+                        "description": "相似度阈值（0-1），默认使用配置的阈值",
+                        "name": "threshold",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "获取成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/service.SimilarityCluster"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "权限不足",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "提交点不存在",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "管理员比较提交点下的全部提交，替换该提交点原有的相似度报告，并标记最高相似度达到阈值的提交。代码按词法单元指纹比较，文字按连续词组比较，内容只是一个链接时比较链接是否相同，代码仓库提交点比较仓库地址、检出的提交和提交作者邮箱",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "相似度检测"
+                ],
+                "summary": "检测提交相似度",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "提交点ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "检测完成",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/service.SimilarityResult"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "权限不足",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "提交点不存在",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/admin/submissions/archive": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "管理员按方向或题目将全部提交打包为ZIP，目录结构为 学号_姓名/题目/提交点.txt",
+                "produces": [
+                    "application/zip"
+                ],
+                "tags": [
+                    "提交管理"
+                ],
+                "summary": "打包下载提交内容",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "方向ID，与题目ID二选一",
+                        "name": "direction_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "题目ID，与方向ID二选一",
+                        "name": "problem_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ZIP文件",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "权限不足",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "方向或题目不存在",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/admin/submissions/review": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "管理员获取需要评分的提交列表",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "提交管理"
+                ],
+                "summary": "获取待评分的提交列表",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "题目ID",
+                        "name": "problem_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "获取成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/model.Submission"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "权限不足",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/admin/submissions/{id}/recheck": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "管理员将代码仓库提交点的一个提交重新放入检查队列",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "代码仓库检查"
+                ],
+                "summary": "重新检查提交",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "提交ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "已加入检查队列",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.CheckRun"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "权限不足",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "提交不存在",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/admin/submissions/{id}/rejudge": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "管理员将自动评测提交点的一个提交重新放入评测队列",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "自动评测"
+                ],
+                "summary": "重新评测提交",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "提交ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "已加入评测队列",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.JudgeRun"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "权限不足",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "提交不存在",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/admin/submissions/{id}/similarity": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "管理员获取与某个提交相关的全部相似度报告及双方提交的内容，按相似度从高到低排列，用于逐一比对",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "相似度检测"
+                ],
+                "summary": "获取提交的相似度报告",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "提交ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "获取成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/model.SimilarityReport"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "权限不足",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "提交不存在",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/admin/trash/{type}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "管理员按对象类型查看已删除的记录，按删除时间倒序",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "回收站"
+                ],
+                "summary": "获取回收站记录",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "对象类型(user/direction/problem/submission_point/submission/score/problem_attachment)",
+                        "name": "type",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "页码",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "每页数量",
+                        "name": "page_size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "获取成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "object",
+                                            "additionalProperties": true
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "权限不足",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/admin/trash/{type}/{id}/restore": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "管理员恢复已删除的记录，与其一同删除的下级记录（题目的提交点、提交的评分）会一并恢复",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "回收站"
+                ],
+                "summary": "恢复回收站记录",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "对象类型(user/direction/problem/submission_point/submission/score/problem_attachment)",
+                        "name": "type",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "记录ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "恢复成功",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "权限不足",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "回收站记录不存在",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/admin/users": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "管理员获取用户列表",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "用户管理"
+                ],
+                "summary": "获取用户列表",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "页码",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "每页数量",
+                        "name": "page_size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "获取成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "object",
+                                            "additionalProperties": true
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "权限不足",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/admin/users/export": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "管理员按条件导出用户名单及联系方式、总分，按用户ID排序。指定方向时只导出在该方向有提交的用户，总分也只统计该方向；指定阶段时只导出处于该阶段的选手（不含管理员）：registered已注册但没有提交，submitted有提交但都未评分，scored至少有一个提交已评分，同时指定方向时按该方向的提交划分",
+                "produces": [
+                    "text/csv",
+                    "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+                ],
+                "tags": [
+                    "用户管理"
+                ],
+                "summary": "导出用户名单",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "csv",
+                        "description": "文件格式(csv/xlsx)",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "方向ID",
+                        "name": "direction_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "招新阶段(registered/submitted/scored)",
+                        "name": "stage",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "最低总分",
+                        "name": "min_score",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "最高总分",
+                        "name": "max_score",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "用户表格",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "权限不足",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/admin/users/import": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "管理员上传CSV或XLSX文件批量创建用户，第一行为表头，列名可用英文或中文：username(用户名)、password(密码)、nickname(昵称)、real_name(姓名)、college(学院)、student_id(学号)、qq(QQ)、email(邮箱)，其中password、qq、email可省略。\n逐行校验必填项、长度和格式，并检查用户名、学号在文件内及与已有用户是否重复；存在任何错误时不导入任何用户。未提供密码的用户生成随机初始密码，仅在本次响应中返回。",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "用户管理"
+                ],
+                "summary": "批量导入用户",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "用户表格(.csv/.xlsx)",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "仅校验不导入",
+                        "name": "dry_run",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "校验或导入结果，errors不为空时未导入",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/service.ImportUsersResult"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "权限不足",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/admin/users/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "管理员获取指定用户的详细信息",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "用户管理"
+                ],
+                "summary": "获取指定用户信息",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "用户ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "获取成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.User"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "权限不足",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "用户不存在",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "管理员更新用户信息",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "用户管理"
+                ],
+                "summary": "更新用户信息",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "用户ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "更新信息",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/service.UpdateUserRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "更新成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.User"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "权限不足",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "用户不存在",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "管理员删除用户",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "用户管理"
+                ],
+                "summary": "删除用户",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "用户ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "删除成功",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "权限不足",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "用户不存在",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/admin/webhook-deliveries/{id}/redeliver": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "管理员以相同事件内容重新投递，生成新的投递记录",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhook管理"
+                ],
+                "summary": "重新投递Webhook",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "投递记录ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "已加入投递队列",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.WebhookDelivery"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "权限不足",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "投递记录不存在",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/admin/webhooks": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "管理员获取全部Webhook订阅",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhook管理"
+                ],
+                "summary": "获取Webhook订阅列表",
+                "responses": {
+                    "200": {
+                        "description": "获取成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/model.WebhookSubscription"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "权限不足",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "管理员创建Webhook订阅，event_types为空表示订阅全部事件，direction_id为0表示不限方向，secret为空时自动生成",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhook管理"
+                ],
+                "summary": "创建Webhook订阅",
+                "parameters": [
+                    {
+                        "description": "订阅信息",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/service.CreateWebhookRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "创建成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.WebhookSubscription"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "权限不足",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/admin/webhooks/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "管理员获取指定Webhook订阅",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhook管理"
+                ],
+                "summary": "获取Webhook订阅详情",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "订阅ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "获取成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.WebhookSubscription"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "权限不足",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Webhook不存在",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "管理员更新Webhook订阅，重新启用时清空连续失败计数",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhook管理"
+                ],
+                "summary": "更新Webhook订阅",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "订阅ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "更新信息",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/service.UpdateWebhookRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "更新成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.WebhookSubscription"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "权限不足",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Webhook不存在",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "管理员删除Webhook订阅",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhook管理"
+                ],
+                "summary": "删除Webhook订阅",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "订阅ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "删除成功",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "权限不足",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Webhook不存在",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/admin/webhooks/{id}/deliveries": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "管理员查看指定订阅的投递日志",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhook管理"
+                ],
+                "summary": "获取Webhook投递记录",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "订阅ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "投递状态(pending/succeeded/failed)",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "页码",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "每页数量",
+                        "name": "page_size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "获取成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "object",
+                                            "additionalProperties": true
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "权限不足",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/attachments/{id}/download": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "下载题目附件，所属题目已删除或未发布时无法下载。可选携带token，未满足解锁条件时返回题目尚未解锁。携带签名下载链接中的expires和signature参数时按签名校验，链接过期或签名错误返回403，签名链接只能在题目解锁后生成，下载时不再检查解锁条件；管理员为未发布题目生成的预览链接带有scope=preview，其余签名链接在题目下线或删除后失效，预览链接在题目删除后失效。响应头X-Checksum-SHA256和ETag为文件的SHA-256校验和，支持Range断点续传",
+                "produces": [
+                    "application/octet-stream"
+                ],
+                "tags": [
+                    "题目附件"
+                ],
+                "summary": "下载题目附件",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "附件ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "签名链接过期时间(Unix时间戳)",
+                        "name": "expires",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "签名链接的用途，预览链接为preview",
+                        "name": "scope",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "签名",
+                        "name": "signature",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "附件内容",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "下载链接已过期、签名无效或题目尚未解锁",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "附件不存在",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/attachments/{id}/signed-url": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "为可见的附件生成带签名和过期时间的下载链接，持有链接即可下载，无需登录。有效期不超过storage.signed_url_ttl_minutes配置。未满足解锁条件时返回题目尚未解锁。管理员可为未发布或未解锁题目的附件生成链接用于预览，未发布题目的链接只用于预览，题目删除后失效",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "题目附件"
+                ],
+                "summary": "生成签名下载链接",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "附件ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "有效期(秒)，默认及上限为配置的最长有效期",
+                        "name": "expires_in",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "生成成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/service.SignedURL"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "题目尚未解锁",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "附件不存在",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/auth/login": {
+            "post": {
+                "description": "用户登录接口",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "用户管理"
+                ],
+                "summary": "用户登录",
+                "parameters": [
+                    {
+                        "description": "登录信息",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/service.LoginRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "登录成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/service.LoginResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "认证失败",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/auth/register": {
+            "post": {
+                "description": "用户注册接口",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "用户管理"
+                ],
+                "summary": "用户注册",
+                "parameters": [
+                    {
+                        "description": "注册信息",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/service.RegisterRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "注册成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.User"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/clarifications/my": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "获取当前用户的提问及未读回复数",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "答疑管理"
+                ],
+                "summary": "获取我的提问列表",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "题目ID",
+                        "name": "problem_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "获取成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/service.MyClarificationsResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/clarifications/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "获取答疑详情，提问者查看时会将回复标记为已读",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "答疑管理"
+                ],
+                "summary": "获取答疑详情",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "答疑ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "获取成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.Clarification"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "权限不足",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "答疑不存在",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/directions": {
+            "get": {
+                "description": "获取所有方向的列表，description_html为描述渲染并清理后的HTML",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "方向管理"
+                ],
+                "summary": "获取方向列表",
+                "responses": {
+                    "200": {
+                        "description": "获取成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/model.Direction"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/directions/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "根据ID获取方向的详细信息，题目列表只包含已发布和已归档的题目。可选携带token，未解锁的题目locked为true且不返回题面",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "方向管理"
+                ],
+                "summary": "获取方向详情",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "方向ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "获取成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.Direction"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "方向不存在",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/notifications": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "获取当前用户的站内通知，按时间倒序",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "通知管理"
+                ],
+                "summary": "获取我的通知列表",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "description": "仅未读",
+                        "name": "unread_only",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "页码",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "每页数量",
+                        "name": "page_size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "获取成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "object",
+                                            "additionalProperties": true
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/notifications/read-all": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "将当前用户的全部通知标记为已读",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "通知管理"
+                ],
+                "summary": "全部标记为已读",
+                "responses": {
+                    "200": {
+                        "description": "标记成功",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/notifications/settings": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "获取当前用户的通知渠道设置及系统可用渠道",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "通知管理"
+                ],
+                "summary": "获取通知设置",
+                "responses": {
+                    "200": {
+                        "description": "获取成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/service.NotificationSettingResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "选择需要接收通知的外部渠道。Webhook地址须为http或https，默认不能指向回环、私有网段等内网地址",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "通知管理"
+                ],
+                "summary": "更新通知设置",
+                "parameters": [
+                    {
+                        "description": "通知设置",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/service.UpdateNotificationSettingRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "更新成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/service.NotificationSettingResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/notifications/unread-count": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "获取当前用户的未读通知数量",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "通知管理"
+                ],
+                "summary": "获取未读通知数",
+                "responses": {
+                    "200": {
+                        "description": "获取成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "object",
+                                            "additionalProperties": {
+                                                "type": "integer",
+                                                "format": "int64"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/notifications/{id}/read": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "将指定通知标记为已读",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "通知管理"
+                ],
+                "summary": "标记通知为已读",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "通知ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "标记成功",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "通知不存在",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/problems": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "获取对选手可见的题目列表，可按方向筛选。默认只返回已发布的题目，status=archived时返回已归档的题目。可选携带token，未解锁的题目locked为true且不返回题面",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "题目管理"
+                ],
+                "summary": "获取题目列表",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "方向ID",
+                        "name": "direction_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "可见状态(published/archived)，默认published",
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "获取成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/model.Problem"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/problems/search": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "按关键词在标题和描述中全文搜索已发布的题目，可按方向、标签、难度筛选并排序。多个关键词以空格分隔，需全部命中；指定关键词且未指定排序时按相关度排序。可选携带token，未解锁的题目只按标题匹配关键词，locked为true且不返回题面",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "题目管理"
+                ],
+                "summary": "搜索题目",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "关键词",
+                        "name": "keyword",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "方向ID",
+                        "name": "direction_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "可见状态(published/archived)，默认published",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "标签，多个以逗号分隔，需全部包含",
+                        "name": "tags",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "最低难度(1-5)",
+                        "name": "min_difficulty",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "最高难度(1-5)",
+                        "name": "max_difficulty",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "排序字段(relevance/id/title/difficulty/estimated_minutes/created_at/published_at)",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "asc",
+                        "description": "排序方向(asc/desc)",
+                        "name": "order",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "页码",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "每页数量",
+                        "name": "page_size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "获取成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "object",
+                                            "additionalProperties": true
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/problems/tags": {
+            "get": {
+                "description": "获取已发布题目使用的标签及题目数量，按题目数量降序排列",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "题目管理"
+                ],
+                "summary": "获取题目标签",
+                "responses": {
+                    "200": {
+                        "description": "获取成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/repository.TagCount"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/api/problems/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "根据ID获取题目的详细信息，草稿和定时发布中的题目返回题目不存在。description_html为题面渲染并清理后的HTML，attachments/下的相对链接指向题目附件。可选携带token，未满足解锁条件时locked为true，只返回标题和解锁条件",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "题目管理"
+                ],
+                "summary": "获取题目详情",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "题目ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "获取成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.Problem"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "题目不存在",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/problems/{id}/attachments": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "获取题目的附件列表，按文件名排序，包含大小和SHA-256校验和。题目未发布时返回题目不存在，可选携带token，未满足解锁条件时返回题目尚未解锁",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "题目附件"
+                ],
+                "summary": "获取题目附件列表",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "题目ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "获取成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/model.ProblemAttachment"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "题目尚未解锁",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "题目不存在",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/problems/{id}/attachments/{filename}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "按文件名下载已发布题目的附件，题面中attachments/\u003c文件名\u003e形式的相对链接渲染后指向此地址，附件被替换后链接依然有效。可选携带token，未满足解锁条件时返回题目尚未解锁。响应头与按ID下载相同",
+                "produces": [
+                    "application/octet-stream"
+                ],
+                "tags": [
+                    "题目附件"
+                ],
+                "summary": "按文件名下载题目附件",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "题目ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "附件文件名",
+                        "name": "filename",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "附件内容",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "题目尚未解锁",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "题目或附件不存在",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/problems/{id}/clarifications": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "获取指定题目已公开的答疑列表，题目未发布时返回题目不存在。可选携带token，未满足解锁条件时返回题目尚未解锁",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "答疑管理"
+                ],
+                "summary": "获取公开答疑",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "题目ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "获取成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/model.Clarification"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "题目尚未解锁",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "题目不存在",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "用户针对已发布的题目提交私有提问，已归档的题目不再接受提问",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "答疑管理"
+                ],
+                "summary": "提问",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "题目ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "提问内容",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/service.CreateClarificationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "提问成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.Clarification"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "题目已归档",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "题目不存在",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/problems/{id}/hints": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "获取题目的提示列表，按解锁顺序排列。已解锁的提示返回内容，未解锁的提示只返回顺序和惩罚分",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "题目提示"
+                ],
+                "summary": "获取题目提示列表",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "题目ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "获取成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/model.ProblemHint"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "题目尚未解锁",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "题目不存在",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/problems/{id}/hints/{hint_id}/unlock": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "解锁题目的提示并返回内容，解锁会被记录，提示的惩罚分从该题得分中扣除（扣至0为止），排行榜和评分汇总同步生效。需按顺序解锁，重复解锁不会重复扣分；已归档的题目不能再解锁提示",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "题目提示"
+                ],
+                "summary": "解锁题目提示",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "题目ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "提示ID",
+                        "name": "hint_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "解锁成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.ProblemHint"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "题目尚未解锁或已归档",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "题目或提示不存在",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/problems/{id}/submission-points": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "获取指定题目的提交点列表，题目未发布时返回题目不存在。可选携带token，未满足解锁条件时返回题目尚未解锁",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "题目管理"
+                ],
+                "summary": "获取提交点列表",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "题目ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "获取成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/model.SubmissionPoint"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "题目尚未解锁",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "题目不存在",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/problems/{id}/team": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "获取当前用户在题目中加入的队伍，包括成员和队伍发出的待处理邀请。成员只返回用户名和昵称",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "组队"
+                ],
+                "summary": "获取我的队伍",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "题目ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "获取成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/service.TeamResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "题目不存在或尚未加入队伍",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/problems/{id}/teams": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "在组队题目中创建队伍，创建者成为队长。每位用户在一道题目中只能加入一支队伍，创建后清除其在该题目收到的其他邀请。队伍名称在题目内唯一，不超过50个字符",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "组队"
+                ],
+                "summary": "创建队伍",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "题目ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "队伍信息",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/service.CreateTeamRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "创建成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/service.TeamResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "题目已归档或尚未解锁",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "题目不存在",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/ranking": {
+            "get": {
+                "description": "获取指定方向的排行榜，总分为各题目扣除提示惩罚分后的得分之和",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "评分管理"
+                ],
+                "summary": "获取排行榜",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "方向ID",
+                        "name": "direction_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "限制数量",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "获取成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/service.RankingItem"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/scores/my": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "获取当前用户的评分记录",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "评分管理"
+                ],
+                "summary": "获取我的评分列表",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "题目ID",
+                        "name": "problem_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "获取成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/model.Score"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/scores/my/problems": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "按题目汇总当前用户的得分和解锁提示扣除的分数，字段含义同获取用户的题目得分",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "评分管理"
+                ],
+                "summary": "获取我的题目得分",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "方向ID",
+                        "name": "direction_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "获取成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/service.ProblemScore"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/submissions": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "用户提交作业，同一提交点重复提交会覆盖原提交内容。代码仓库提交点的内容为http(s)仓库地址，可用#\u003c提交哈希\u003e固定检查的提交。只能提交已发布的题目，已归档的题目不再接受提交，设置了解锁条件的题目需先满足条件。组队题目需先加入队伍，每支队伍在一个提交点只有一份提交，任一队员提交都会覆盖",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "提交管理"
+                ],
+                "summary": "创建提交",
+                "parameters": [
+                    {
+                        "description": "提交信息",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/service.CreateSubmissionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "提交成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.Submission"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "题目已归档、尚未解锁或未加入队伍",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "题目不存在",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/submissions/my": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "获取当前用户的提交列表，包括所在队伍的提交",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "提交管理"
+                ],
+                "summary": "获取我的提交列表",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "题目ID",
+                        "name": "problem_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "获取成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/service.SubmissionResponse"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/submissions/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "获取指定提交的详细信息",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "提交管理"
+                ],
+                "summary": "获取提交详情",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "提交ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "获取成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.Submission"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "提交不存在",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "用户删除自己的提交",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "提交管理"
+                ],
+                "summary": "删除提交",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "提交ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "删除成功",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "权限不足",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "提交不存在",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/submissions/{id}/checks": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "获取代码仓库提交点的提交的检查状态、实际检查的提交、各步骤的结果和日志，以及建议分数和评语，只有提交者本人、所属队伍的成员或管理员可以查看；步骤日志只对管理员返回",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "代码仓库检查"
+                ],
+                "summary": "获取提交的检查报告",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "提交ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "获取成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.CheckRun"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "权限不足",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "提交或检查报告不存在",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/submissions/{id}/judge": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "获取自动评测提交点的提交的评测状态、结果和各测试点的结果，只有提交者本人、所属队伍的成员或管理员可以查看。测试点的输入和标准答案不公开",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "自动评测"
+                ],
+                "summary": "获取提交的评测记录",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "提交ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "获取成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.JudgeRun"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "权限不足",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "提交或评测记录不存在",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/submissions/{id}/scores": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "获取指定提交的所有评分",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "评分管理"
+                ],
+                "summary": "获取提交的评分列表",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "提交ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "获取成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/model.Score"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/team-invites": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "获取当前用户收到的待处理组队邀请，按时间倒序排列",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "组队"
+                ],
+                "summary": "获取我收到的组队邀请",
+                "responses": {
+                    "200": {
+                        "description": "获取成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/service.MyTeamInvite"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/team-invites/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "被邀请的用户拒绝邀请，或队长撤回队伍发出的邀请",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "组队"
+                ],
+                "summary": "拒绝或撤回组队邀请",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "邀请ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "操作成功",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "邀请不存在",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/team-invites/{id}/accept": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "接受邀请加入队伍，并清除在该题目收到的其他邀请。已在该题目中加入队伍、队伍人数已满或队伍已有提交时失败",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "组队"
+                ],
+                "summary": "接受组队邀请",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "邀请ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "加入成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/service.TeamResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "题目已归档或尚未解锁",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "邀请不存在",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/teams/{id}/invites": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "队长按用户名邀请队员，被邀请的用户会收到通知。被邀请的用户在该题目中不能已有队伍，队伍人数不能超过题目的上限，队伍有提交后不能再邀请",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "组队"
+                ],
+                "summary": "邀请队员",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "队伍ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "被邀请的用户",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/service.InviteTeamMemberRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "邀请成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/service.TeamInviteInfo"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "不是队长",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "队伍或用户不存在",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/teams/{id}/leave": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "退出队伍，队伍有提交后成员不能再变动。队长退出时由最早加入的队员接任，最后一名队员退出时解散队伍",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "组队"
+                ],
+                "summary": "退出队伍",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "队伍ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "退出成功",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误或队伍已有提交",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "队伍不存在",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/teams/{id}/members/{user_id}": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "队长移除队员，队伍有提交后成员不能再变动",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "组队"
+                ],
+                "summary": "移除队员",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "队伍ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "队员的用户ID",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "移除成功",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误或队伍已有提交",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "不是队长",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "队伍不存在",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/profile": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "获取当前登录用户的信息",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "用户管理"
+                ],
+                "summary": "获取用户信息",
+                "responses": {
+                    "200": {
+                        "description": "获取成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.User"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "用户不存在",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/{id}/scores": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "获取指定用户的评分记录",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "评分管理"
+                ],
+                "summary": "获取用户的评分列表",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "用户ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "题目ID",
+                        "name": "problem_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "获取成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/model.Score"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/{id}/scores/problems": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "按题目汇总指定用户的得分：raw_score为全部评分者的评分之和，hint_penalty为解锁提示扣除的分数，score为扣除后计入排行榜的得分（不低于0）",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "评分管理"
+                ],
+                "summary": "获取用户的题目得分",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "用户ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "方向ID",
+                        "name": "direction_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "获取成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/service.ProblemScore"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "model.CheckRun": {
+            "type": "object",
+            "properties": {
+                "commit": {
+                    "description": "实际检查的提交",
+                    "type": "string",
+                    "example": "3f2a9c1e0b7d4f8a6e5c2b1a0d9f8e7c6b5a4f3e"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "finished_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "message": {
+                    "description": "检查失败的原因",
+                    "type": "string",
+                    "example": "克隆仓库失败，请确认仓库地址正确且可以公开访问"
+                },
+                "passed_count": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "started_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "description": "pending/running/finished/failed",
+                    "type": "string",
+                    "example": "finished"
+                },
+                "step_count": {
+                    "type": "integer",
+                    "example": 4
+                },
+                "steps": {
+                    "description": "各步骤的结果，由Results解析，不入库",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.CheckStepResult"
+                    }
+                },
+                "submission_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "suggested_comment": {
+                    "type": "string",
+                    "example": "[通过] 项目能够成功构建 +20"
+                },
+                "suggested_score": {
+                    "description": "按通过步骤的分数汇总的建议分数（不超过提交点满分）和评语，评分时可据此预填",
+                    "type": "integer",
+                    "example": 60
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.CheckStep": {
+            "type": "object",
+            "properties": {
+                "command": {
+                    "description": "build、test、lint在仓库根目录用/bin/sh执行的命令",
+                    "type": "string",
+                    "example": "make build"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "criterion": {
+                    "type": "string",
+                    "example": "项目能够成功构建"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "kind": {
+                    "description": "file_exists文件存在，build构建，test测试，lint代码检查",
+                    "type": "string",
+                    "example": "build"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "构建"
+                },
+                "path": {
+                    "description": "file_exists检查的路径，支持通配符",
+                    "type": "string",
+                    "example": "README.md"
+                },
+                "points": {
+                    "description": "通过后建议给出的分数和对应的评分项，用于预填评分",
+                    "type": "integer",
+                    "example": 20
+                },
+                "position": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "submission_point_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "timeout_seconds": {
+                    "description": "为0时使用配置中的默认值",
+                    "type": "integer",
+                    "example": 300
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.CheckStepResult": {
+            "type": "object",
+            "properties": {
+                "criterion": {
+                    "type": "string",
+                    "example": "项目能够成功构建"
+                },
+                "duration_ms": {
+                    "type": "integer",
+                    "example": 5230
+                },
+                "exit_code": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "kind": {
+                    "type": "string",
+                    "example": "build"
+                },
+                "log": {
+                    "description": "标准输出和标准错误，超过配置的大小时截断，只对管理员返回",
+                    "type": "string",
+                    "example": "go build ./..."
+                },
+                "name": {
+                    "type": "string",
+                    "example": "构建"
+                },
+                "points": {
+                    "description": "本步骤获得的建议分数",
+                    "type": "integer",
+                    "example": 20
+                },
+                "position": {
+                    "description": "第几个步骤，从1开始",
+                    "type": "integer",
+                    "example": 1
+                },
+                "status": {
+                    "description": "passed通过，failed未通过，timeout超时，skipped因构建失败跳过",
+                    "type": "string",
+                    "example": "passed"
+                }
+            }
+        },
+        "model.Clarification": {
+            "type": "object",
+            "properties": {
+                "answer": {
+                    "type": "string",
+                    "example": "保证合法，无需额外校验"
+                },
+                "answered_at": {
+                    "type": "string"
+                },
+                "answerer": {
+                    "$ref": "#/definitions/model.User"
+                },
+                "answerer_id": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "asker": {
+                    "$ref": "#/definitions/model.User"
+                },
+                "asker_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "is_public": {
+                    "type": "boolean"
+                },
+                "problem": {
+                    "description": "关联关系",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.Problem"
+                        }
+                    ]
+                },
+                "problem_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "question": {
+                    "type": "string",
+                    "example": "输入数据是否保证合法？"
+                },
+                "reply_unread": {
+                    "type": "boolean"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.Direction": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "description": {
+                    "description": "Markdown格式",
+                    "type": "string",
                     "example": "负责前端页面开发和用户交互"
                 },
+                "description_html": {
+                    "description": "描述渲染并清理后的HTML，不入库",
+                    "type": "string",
+                    "example": "\u003cp\u003e负责前端页面开发和用户交互\u003c/p\u003e\n"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "managers": {
+                    "description": "关联关系",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.User"
+                    }
+                },
+                "name": {
+                    "type": "string",
+                    "example": "前端开发"
+                },
+                "problems": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.Problem"
+                    }
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.JudgeCase": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "input_size": {
+                    "type": "integer",
+                    "example": 128
+                },
+                "output_size": {
+                    "type": "integer",
+                    "example": 16
+                },
+                "position": {
+                    "description": "测试点顺序",
+                    "type": "integer",
+                    "example": 1
+                },
+                "submission_point_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "weight": {
+                    "description": "得分权重，得分按通过的权重占比折算",
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "model.JudgeCaseResult": {
+            "type": "object",
+            "properties": {
+                "memory_kb": {
+                    "type": "integer",
+                    "example": 8272
+                },
+                "position": {
+                    "description": "第几个测试点，从1开始",
+                    "type": "integer",
+                    "example": 1
+                },
+                "time_ms": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "verdict": {
+                    "type": "string",
+                    "example": "accepted"
+                }
+            }
+        },
+        "model.JudgeRun": {
+            "type": "object",
+            "properties": {
+                "case_count": {
+                    "type": "integer",
+                    "example": 5
+                },
+                "cases": {
+                    "description": "各测试点的结果，由Results解析，不入库",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.JudgeCaseResult"
+                    }
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "finished_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "message": {
+                    "description": "编译错误输出或评测失败的原因",
+                    "type": "string",
+                    "example": "main.cpp:3:1: error: expected ';'"
+                },
+                "passed_count": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "score": {
+                    "type": "integer",
+                    "example": 60
+                },
+                "started_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "description": "pending/running/finished/failed",
+                    "type": "string",
+                    "example": "finished"
+                },
+                "submission_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "verdict": {
+                    "description": "评测结束时为第一个未通过测试点的结果，全部通过时为accepted",
+                    "type": "string",
+                    "example": "wrong_answer"
+                }
+            }
+        },
+        "model.Problem": {
+            "type": "object",
+            "required": [
+                "description",
+                "direction_id",
+                "title"
+            ],
+            "properties": {
+                "attachments": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.ProblemAttachment"
+                    }
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "description": {
+                    "description": "Markdown格式",
+                    "type": "string",
+                    "example": "使用HTML、CSS、JavaScript实现一个基本的计算器功能"
+                },
+                "description_html": {
+                    "description": "题面渲染并清理后的HTML，不入库，attachments/下的相对链接指向题目附件",
+                    "type": "string",
+                    "example": "\u003cp\u003e使用HTML、CSS、JavaScript实现一个基本的计算器功能\u003c/p\u003e\n"
+                },
+                "difficulty": {
+                    "description": "难度等级1-5，0表示未设置",
+                    "type": "integer",
+                    "example": 3
+                },
+                "direction": {
+                    "description": "关联关系",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.Direction"
+                        }
+                    ]
+                },
+                "direction_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "estimated_minutes": {
+                    "description": "预计用时（分钟），0表示未设置",
+                    "type": "integer",
+                    "example": 120
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "locked": {
+                    "description": "当前用户是否尚未满足解锁条件，不入库；未解锁时不返回题面、提交点和附件",
+                    "type": "boolean",
+                    "example": false
+                },
+                "prerequisites": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.ProblemPrerequisite"
+                    }
+                },
+                "publish_at": {
+                    "description": "定时发布时间，仅scheduled状态有效",
+                    "type": "string",
+                    "example": "2024-09-01T09:00:00+08:00"
+                },
+                "published_at": {
+                    "description": "首次发布时间",
+                    "type": "string",
+                    "example": "2024-09-01T09:00:00+08:00"
+                },
+                "slug": {
+                    "description": "题目包标识，同一方向内唯一，导入时据此匹配已有题目",
+                    "type": "string",
+                    "example": "calculator"
+                },
+                "statement_updated": {
+                    "description": "当前用户最后一次提交之后题目是否有重要修改，不入库",
+                    "type": "boolean",
+                    "example": true
+                },
+                "statement_updated_at": {
+                    "description": "最近一次重要修改的时间",
+                    "type": "string",
+                    "example": "2024-09-10T12:00:00+08:00"
+                },
+                "status": {
+                    "description": "可见状态：draft草稿、scheduled定时发布、published已发布、archived已归档，只有已发布和已归档的题目对选手可见",
+                    "type": "string",
+                    "example": "published"
+                },
+                "submission_points": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.SubmissionPoint"
+                    }
+                },
+                "submissions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.Submission"
+                    }
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "算法",
+                        "动态规划"
+                    ]
+                },
+                "team_size": {
+                    "description": "队伍人数上限，0表示个人提交",
+                    "type": "integer",
+                    "example": 4
+                },
+                "title": {
+                    "type": "string",
+                    "example": "实现一个简单的计算器"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.ProblemAttachment": {
+            "type": "object",
+            "properties": {
+                "content_type": {
+                    "type": "string",
+                    "example": "application/zip"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "filename": {
+                    "type": "string",
+                    "example": "starter.zip"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "problem": {
+                    "description": "关联关系",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.Problem"
+                        }
+                    ]
+                },
+                "problem_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "sha256": {
+                    "type": "string",
+                    "example": "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"
+                },
+                "size": {
+                    "type": "integer",
+                    "example": 10240
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "uploader_id": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "model.ProblemHint": {
+            "type": "object",
+            "properties": {
+                "content": {
+                    "type": "string",
+                    "example": "可以先用栈把中缀表达式转换为后缀表达式"
+                },
+                "content_html": {
+                    "type": "string",
+                    "example": "\u003cp\u003e可以先用栈把中缀表达式转换为后缀表达式\u003c/p\u003e\n"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "penalty": {
+                    "type": "integer",
+                    "example": 10
+                },
+                "position": {
+                    "description": "提示顺序，需按从小到大的顺序解锁",
+                    "type": "integer",
+                    "example": 1
+                },
+                "problem_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "unlocked": {
+                    "description": "当前用户是否已解锁，不入库；未解锁时不返回提示内容",
+                    "type": "boolean",
+                    "example": true
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.ProblemPrerequisite": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "min_score": {
+                    "description": "仅score类型有效",
+                    "type": "integer",
+                    "example": 60
+                },
+                "problem_id": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "required_problem_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "type": {
+                    "description": "submitted在前置题目有提交，score在前置题目的总分不低于MinScore",
+                    "type": "string",
+                    "example": "score"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.ProblemRevision": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string",
+                    "example": "使用HTML、CSS、JavaScript实现一个基本的计算器功能"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "note": {
+                    "type": "string",
+                    "example": "修正了输入格式的说明"
+                },
+                "operator_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "operator_name": {
+                    "type": "string",
+                    "example": "admin"
+                },
+                "problem_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "significant": {
+                    "description": "重要修改会通知已提交的选手",
+                    "type": "boolean",
+                    "example": true
+                },
+                "submission_points": {
+                    "description": "解析后的提交点设置，不入库",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.RevisionPoint"
+                    }
+                },
+                "title": {
+                    "type": "string",
+                    "example": "实现一个简单的计算器"
+                },
+                "version": {
+                    "type": "integer",
+                    "example": 2
+                }
+            }
+        },
+        "model.RevisionPoint": {
+            "type": "object",
+            "properties": {
+                "deadline": {
+                    "type": "string",
+                    "example": "2024-10-01T23:59:59+08:00"
+                },
+                "max_score": {
+                    "type": "integer",
+                    "example": 100
+                },
+                "name": {
+                    "type": "string",
+                    "example": "源代码提交"
+                },
+                "rubric": {
+                    "type": "string",
+                    "example": "功能完整60分，代码规范20分，文档20分"
+                }
+            }
+        },
+        "model.Score": {
+            "type": "object",
+            "required": [
+                "reviewer_id",
+                "score",
+                "submission_id",
+                "user_id"
+            ],
+            "properties": {
+                "comment": {
+                    "type": "string",
+                    "example": "代码实现良好，但缺少注释"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "reviewer": {
+                    "$ref": "#/definitions/model.User"
+                },
+                "reviewer_id": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "score": {
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 85
+                },
+                "submission": {
+                    "$ref": "#/definitions/model.Submission"
+                },
+                "submission_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user": {
+                    "description": "关联关系",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.User"
+                        }
+                    ]
+                },
+                "user_id": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "model.SimilarityFlag": {
+            "type": "object",
+            "properties": {
+                "flagged": {
+                    "description": "最高相似度达到可疑阈值",
+                    "type": "boolean",
+                    "example": true
+                },
+                "score": {
+                    "description": "与同一提交点其他提交的最高相似度，0-1",
+                    "type": "number",
+                    "example": 0.92
+                }
+            }
+        },
+        "model.SimilarityReport": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "detail": {
+                    "type": "string",
+                    "example": "共同指纹46个"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "method": {
+                    "description": "code代码指纹，text文本指纹，link相同链接，repository代码仓库",
+                    "type": "string",
+                    "example": "code"
+                },
+                "score": {
+                    "description": "0-1",
+                    "type": "number",
+                    "example": 0.92
+                },
+                "submission_a": {
+                    "$ref": "#/definitions/model.Submission"
+                },
+                "submission_a_id": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "submission_b": {
+                    "$ref": "#/definitions/model.Submission"
+                },
+                "submission_b_id": {
+                    "type": "integer",
+                    "example": 7
+                },
+                "submission_point_id": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "model.Submission": {
+            "type": "object",
+            "required": [
+                "content",
+                "problem_id",
+                "submission_point_id",
+                "user_id"
+            ],
+            "properties": {
+                "check_run": {
+                    "description": "代码仓库提交点的检查报告，仅待评分列表加载",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.CheckRun"
+                        }
+                    ]
+                },
+                "content": {
+                    "type": "string",
+                    "example": "https://github.com/user/project"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "problem": {
+                    "$ref": "#/definitions/model.Problem"
+                },
+                "problem_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "scores": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.Score"
+                    }
+                },
+                "similarity": {
+                    "description": "相似度标记，不入库，仅待评分列表返回",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.SimilarityFlag"
+                        }
+                    ]
+                },
+                "submission_point": {
+                    "$ref": "#/definitions/model.SubmissionPoint"
+                },
+                "submission_point_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "team": {
+                    "$ref": "#/definitions/model.Team"
+                },
+                "team_id": {
+                    "description": "组队题目的提交所属队伍，个人提交为空",
+                    "type": "integer",
+                    "example": 2
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user": {
+                    "description": "关联关系",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.User"
+                        }
+                    ]
+                },
+                "user_id": {
+                    "description": "组队题目为首次提交的队员",
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "model.SubmissionPoint": {
+            "type": "object",
+            "required": [
+                "max_score",
+                "name",
+                "problem_id"
+            ],
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "deadline": {
+                    "type": "string",
+                    "example": "2024-10-01T23:59:59+08:00"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "judge_language": {
+                    "type": "string",
+                    "example": "python"
+                },
+                "max_score": {
+                    "type": "integer",
+                    "minimum": 1,
+                    "example": 100
+                },
+                "memory_limit_mb": {
+                    "type": "integer",
+                    "example": 256
+                },
+                "name": {
+                    "type": "string",
+                    "example": "源代码提交"
+                },
+                "problem": {
+                    "description": "关联关系",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.Problem"
+                        }
+                    ]
+                },
+                "problem_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "rubric": {
+                    "type": "string",
+                    "example": "功能完整60分，代码规范20分，文档20分"
+                },
+                "submissions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.Submission"
+                    }
+                },
+                "time_limit_ms": {
+                    "type": "integer",
+                    "example": 1000
+                },
+                "type": {
+                    "description": "自动评测设置，仅type为auto时有效；限制为0时使用配置中的默认值，检查脚本不对选手公开",
+                    "type": "string",
+                    "example": "manual"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.Team": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "invites": {
+                    "description": "待处理的邀请，仅队伍详情返回",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.TeamInvite"
+                    }
+                },
+                "leader": {
+                    "$ref": "#/definitions/model.User"
+                },
+                "leader_id": {
+                    "type": "integer",
+                    "example": 5
+                },
+                "members": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.TeamMember"
+                    }
+                },
+                "name": {
+                    "type": "string",
+                    "example": "第一小队"
+                },
+                "problem_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.TeamInvite": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "inviter": {
+                    "$ref": "#/definitions/model.User"
+                },
+                "inviter_id": {
+                    "type": "integer",
+                    "example": 5
+                },
+                "team": {
+                    "$ref": "#/definitions/model.Team"
+                },
+                "team_id": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "user": {
+                    "$ref": "#/definitions/model.User"
+                },
+                "user_id": {
+                    "description": "被邀请的用户",
+                    "type": "integer",
+                    "example": 6
+                }
+            }
+        },
+        "model.TeamMember": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "description": "加入时间",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "problem_id": {
+                    "description": "与队伍的题目相同，保证每位用户在一道题目中只加入一支队伍",
+                    "type": "integer",
+                    "example": 1
+                },
+                "team_id": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "user": {
+                    "$ref": "#/definitions/model.User"
+                },
+                "user_id": {
+                    "type": "integer",
+                    "example": 5
+                }
+            }
+        },
+        "model.User": {
+            "type": "object",
+            "required": [
+                "college",
+                "nickname",
+                "real_name",
+                "student_id",
+                "username"
+            ],
+            "properties": {
+                "college": {
+                    "type": "string",
+                    "example": "计算机学院"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string",
+                    "example": "user@example.com"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "is_admin": {
+                    "type": "boolean"
+                },
+                "nickname": {
+                    "type": "string",
+                    "example": "小明"
+                },
+                "qq": {
+                    "type": "string",
+                    "example": "123456789"
+                },
+                "real_name": {
+                    "type": "string",
+                    "example": "张三"
+                },
+                "student_id": {
+                    "type": "string",
+                    "example": "2021001001"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string",
+                    "example": "user123"
+                }
+            }
+        },
+        "model.WebhookDelivery": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "event_id": {
+                    "type": "string",
+                    "example": "9b2c..."
+                },
+                "event_type": {
+                    "type": "string",
+                    "example": "score.created"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "last_attempt_at": {
+                    "type": "string"
+                },
+                "last_error": {
+                    "type": "string"
+                },
+                "next_attempt_at": {
+                    "type": "string"
+                },
+                "payload": {
+                    "type": "string"
+                },
+                "response_code": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "pending"
+                },
+                "subscription_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.WebhookSubscription": {
+            "type": "object",
+            "properties": {
+                "consecutive_failures": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "direction_id": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "disabled_reason": {
+                    "type": "string"
+                },
+                "enabled": {
+                    "type": "boolean"
+                },
+                "event_types": {
+                    "type": "string",
+                    "example": "submission.created,score.created"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "招新群机器人"
+                },
+                "secret": {
+                    "type": "string",
+                    "example": "3f9a..."
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://bot.example.com/glimgate"
+                }
+            }
+        },
+        "repository.TagCount": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "example": "算法"
+                },
+                "problem_count": {
+                    "type": "integer",
+                    "example": 3
+                }
+            }
+        },
+        "response.Response": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "data": {},
+                "msg": {
+                    "type": "string",
+                    "example": "success"
+                }
+            }
+        },
+        "service.AnswerClarificationRequest": {
+            "type": "object",
+            "required": [
+                "answer"
+            ],
+            "properties": {
+                "answer": {
+                    "type": "string",
+                    "example": "保证合法，无需额外校验"
+                },
+                "is_public": {
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
+        "service.BundleChange": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string",
+                    "example": "update"
+                },
+                "after": {
+                    "type": "string",
+                    "example": "80"
+                },
+                "before": {
+                    "type": "string",
+                    "example": "100"
+                },
+                "path": {
+                    "type": "string",
+                    "example": "submission_points[源代码提交].max_score"
+                }
+            }
+        },
+        "service.CreateCheckStepRequest": {
+            "type": "object",
+            "required": [
+                "kind",
+                "name"
+            ],
+            "properties": {
+                "command": {
+                    "description": "Command build、test、lint步骤在仓库根目录用/bin/sh执行的命令，退出码为0表示通过",
+                    "type": "string",
+                    "example": "make build"
+                },
+                "criterion": {
+                    "type": "string",
+                    "maxLength": 200,
+                    "example": "项目能够成功构建"
+                },
+                "kind": {
+                    "description": "Kind 步骤类型：file_exists检查路径存在，build构建，test测试，lint代码检查；构建失败后跳过之后的命令步骤",
+                    "type": "string",
+                    "example": "build"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "构建"
+                },
+                "path": {
+                    "description": "Path file_exists步骤检查的仓库内相对路径，支持通配符",
+                    "type": "string",
+                    "example": "README.md"
+                },
+                "points": {
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 20
+                },
+                "position": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "timeout_seconds": {
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 300
+                }
+            }
+        },
+        "service.CreateClarificationRequest": {
+            "type": "object",
+            "required": [
+                "question"
+            ],
+            "properties": {
+                "question": {
+                    "type": "string",
+                    "example": "输入数据是否保证合法？"
+                }
+            }
+        },
+        "service.CreateDirectionRequest": {
+            "type": "object"
+        },
+        "service.CreateHintRequest": {
+            "type": "object",
+            "required": [
+                "content"
+            ],
+            "properties": {
+                "content": {
+                    "type": "string",
+                    "example": "可以先用栈把中缀表达式转换为后缀表达式"
+                },
+                "penalty": {
+                    "type": "integer",
+                    "example": 10
+                },
+                "position": {
+                    "description": "Position 解锁顺序，未指定时排在最后",
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "service.CreateProblemRequest": {
+            "type": "object",
+            "required": [
+                "description",
+                "direction_id",
+                "title"
+            ],
+            "properties": {
+                "description": {
+                    "type": "string",
+                    "example": "使用HTML、CSS、JavaScript实现一个基本的计算器功能"
+                },
+                "difficulty": {
+                    "description": "Difficulty 难度等级1-5，0表示未设置",
+                    "type": "integer",
+                    "example": 3
+                },
+                "direction_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "estimated_minutes": {
+                    "type": "integer",
+                    "example": 120
+                },
+                "publish_at": {
+                    "type": "string",
+                    "example": "2024-09-01T09:00:00+08:00"
+                },
+                "slug": {
+                    "type": "string",
+                    "example": "calculator"
+                },
+                "status": {
+                    "description": "Status 初始可见状态(draft/scheduled/published)，默认draft",
+                    "type": "string",
+                    "example": "draft"
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "算法",
+                        "动态规划"
+                    ]
+                },
+                "team_size": {
+                    "description": "TeamSize 队伍人数上限2-20，0表示个人题目",
+                    "type": "integer",
+                    "example": 0
+                },
+                "title": {
+                    "type": "string",
+                    "example": "实现一个简单的计算器"
+                }
+            }
+        },
+        "service.CreateScoreRequest": {
+            "type": "object",
+            "required": [
+                "score",
+                "submission_id"
+            ],
+            "properties": {
+                "comment": {
+                    "type": "string",
+                    "example": "代码实现良好，但缺少注释"
+                },
+                "score": {
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 85
+                },
+                "submission_id": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "service.CreateSubmissionPointRequest": {
+            "type": "object",
+            "required": [
+                "max_score",
+                "name"
+            ],
+            "properties": {
+                "deadline": {
+                    "type": "string",
+                    "example": "2024-10-01T23:59:59+08:00"
+                },
+                "judge_language": {
+                    "type": "string",
+                    "example": "python"
+                },
+                "max_score": {
+                    "type": "integer",
+                    "minimum": 1,
+                    "example": 100
+                },
+                "memory_limit_mb": {
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 256
+                },
+                "name": {
+                    "type": "string",
+                    "example": "源代码提交"
+                },
+                "rubric": {
+                    "type": "string",
+                    "example": "功能完整60分，代码规范20分，文档20分"
+                },
+                "time_limit_ms": {
+                    "description": "TimeLimitMS、MemoryLimitMB 为0时使用配置中的默认值",
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 1000
+                },
+                "type": {
+                    "description": "Type 提交点类型，manual人工评分（默认），auto自动评测，git代码仓库；自动评测的提交内容为源代码，代码仓库的提交内容为仓库地址",
+                    "type": "string",
+                    "example": "auto"
+                }
+            }
+        },
+        "service.CreateSubmissionRequest": {
+            "type": "object",
+            "required": [
+                "content",
+                "problem_id",
+                "submission_point_id"
+            ],
+            "properties": {
+                "content": {
+                    "type": "string",
+                    "example": "https://github.com/user/project"
+                },
+                "problem_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "submission_point_id": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "service.CreateTeamRequest": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "example": "第一小队"
+                }
+            }
+        },
+        "service.CreateWebhookRequest": {
+            "type": "object",
+            "required": [
+                "name",
+                "url"
+            ],
+            "properties": {
+                "direction_id": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "enabled": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "event_types": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "submission.created",
+                        "score.created"
+                    ]
+                },
+                "name": {
+                    "type": "string",
+                    "example": "招新群机器人"
+                },
+                "secret": {
+                    "type": "string",
+                    "example": ""
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://bot.example.com/glimgate"
+                }
+            }
+        },
+        "service.ImportBundleResult": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string",
+                    "example": "update"
+                },
+                "changes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.BundleChange"
+                    }
+                },
+                "dry_run": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "problem_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "slug": {
+                    "type": "string",
+                    "example": "calculator"
+                }
+            }
+        },
+        "service.ImportUserRowError": {
+            "type": "object",
+            "properties": {
+                "field": {
+                    "type": "string",
+                    "example": "student_id"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "学号与第2行重复"
+                },
+                "row": {
+                    "type": "integer",
+                    "example": 3
+                }
+            }
+        },
+        "service.ImportUsersResult": {
+            "type": "object",
+            "properties": {
+                "dry_run": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.ImportUserRowError"
+                    }
+                },
+                "imported": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "total": {
+                    "type": "integer",
+                    "example": 120
+                },
+                "users": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.ImportedUser"
+                    }
+                }
+            }
+        },
+        "service.ImportedUser": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer",
+                    "example": 10
+                },
+                "initial_password": {
+                    "type": "string",
+                    "example": "a8Kd3mPq2x"
+                },
+                "real_name": {
+                    "type": "string",
+                    "example": "张三"
+                },
+                "row": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "student_id": {
+                    "type": "string",
+                    "example": "2024001"
+                },
+                "username": {
+                    "type": "string",
+                    "example": "2024001"
+                }
+            }
+        },
+        "service.InviteTeamMemberRequest": {
+            "type": "object",
+            "required": [
+                "username"
+            ],
+            "properties": {
+                "username": {
+                    "type": "string",
+                    "example": "user456"
+                }
+            }
+        },
+        "service.LoginRequest": {
+            "type": "object",
+            "required": [
+                "password",
+                "username"
+            ],
+            "properties": {
+                "password": {
+                    "type": "string",
+                    "example": "password123"
+                },
+                "username": {
+                    "type": "string",
+                    "example": "user123"
+                }
+            }
+        },
+        "service.LoginResponse": {
+            "type": "object",
+            "properties": {
+                "token": {
+                    "type": "string",
+                    "example": "eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."
+                },
+                "user": {
+                    "$ref": "#/definitions/model.User"
+                }
+            }
+        },
+        "service.MyClarificationsResponse": {
+            "type": "object",
+            "properties": {
+                "clarifications": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.Clarification"
+                    }
+                },
+                "unread_count": {
+                    "type": "integer"
+                }
+            }
+        },
+        "service.MyTeamInvite": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "inviter_nickname": {
+                    "type": "string",
+                    "example": "小明"
+                },
+                "problem_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "problem_title": {
+                    "type": "string",
+                    "example": "实现一个计算器"
+                },
+                "team_id": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "team_name": {
+                    "type": "string",
+                    "example": "第一小队"
+                }
+            }
+        },
+        "service.NotificationSettingResponse": {
+            "type": "object",
+            "properties": {
+                "available_channels": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "email",
+                        "onebot"
+                    ]
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "boolean"
+                },
                 "id": {
                     "type": "integer"
                 },
-                "managers": {
-                    "description": "关联关系",
+                "onebot": {
+                    "type": "boolean"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "webhook": {
+                    "type": "boolean"
+                },
+                "webhook_url": {
+                    "type": "string",
+                    "example": "https://example.com/hook"
+                }
+            }
+        },
+        "service.PrerequisiteRequest": {
+            "type": "object",
+            "required": [
+                "required_problem_id",
+                "type"
+            ],
+            "properties": {
+                "min_score": {
+                    "type": "integer",
+                    "example": 60
+                },
+                "required_problem_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "type": {
+                    "description": "Type submitted在前置题目有提交即解锁，score在前置题目的总分不低于min_score时解锁",
+                    "type": "string",
+                    "example": "score"
+                }
+            }
+        },
+        "service.ProblemHintStat": {
+            "type": "object",
+            "properties": {
+                "content": {
+                    "type": "string",
+                    "example": "可以先用栈把中缀表达式转换为后缀表达式"
+                },
+                "content_html": {
+                    "type": "string",
+                    "example": "\u003cp\u003e可以先用栈把中缀表达式转换为后缀表达式\u003c/p\u003e\n"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "penalty": {
+                    "type": "integer",
+                    "example": 10
+                },
+                "position": {
+                    "description": "提示顺序，需按从小到大的顺序解锁",
+                    "type": "integer",
+                    "example": 1
+                },
+                "problem_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "unlock_count": {
+                    "type": "integer",
+                    "example": 5
+                },
+                "unlocked": {
+                    "description": "当前用户是否已解锁，不入库；未解锁时不返回提示内容",
+                    "type": "boolean",
+                    "example": true
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "service.ProblemRevisionDiff": {
+            "type": "object",
+            "properties": {
+                "changes": {
+                    "description": "Changes 标题和提交点设置的差异，题面有变化时包含一项description，具体差异见DescriptionDiff",
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/model.User"
+                        "$ref": "#/definitions/service.BundleChange"
                     }
                 },
-                "name": {
+                "description_diff": {
+                    "description": "DescriptionDiff 题面的统一差异格式（unified diff），没有变化时为空",
+                    "type": "string",
+                    "example": "--- v1\n+++ v3\n@@ -1 +1 @@\n-输入一行表达式\n+输入多行表达式，每行一个\n"
+                },
+                "from": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "problem_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "to": {
+                    "type": "integer",
+                    "example": 3
+                }
+            }
+        },
+        "service.ProblemScore": {
+            "type": "object",
+            "properties": {
+                "direction_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "hint_penalty": {
+                    "description": "解锁提示扣除的分数，组队题目为队伍的惩罚分",
+                    "type": "integer",
+                    "example": 10
+                },
+                "problem_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "raw_score": {
+                    "description": "全部评分者的评分之和，组队题目为队伍的评分之和",
+                    "type": "integer",
+                    "example": 90
+                },
+                "score": {
+                    "description": "扣除提示惩罚分后计入排行榜的得分，不低于0",
+                    "type": "integer",
+                    "example": 80
+                },
+                "title": {
+                    "type": "string",
+                    "example": "实现一个简单的计算器"
+                }
+            }
+        },
+        "service.ProblemUnlockStat": {
+            "type": "object",
+            "properties": {
+                "candidate_count": {
+                    "type": "integer",
+                    "example": 40
+                },
+                "prerequisites": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.ProblemPrerequisite"
+                    }
+                },
+                "problem_id": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "status": {
+                    "type": "string",
+                    "example": "published"
+                },
+                "title": {
+                    "type": "string",
+                    "example": "实现一个简单的计算器"
+                },
+                "unlocked_count": {
+                    "type": "integer",
+                    "example": 12
+                }
+            }
+        },
+        "service.RankingItem": {
+            "type": "object",
+            "properties": {
+                "nickname": {
+                    "type": "string"
+                },
+                "score": {
+                    "type": "integer"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "service.RecheckResult": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "description": "重新进入等待检查的提交数",
+                    "type": "integer",
+                    "example": 12
+                }
+            }
+        },
+        "service.RegisterRequest": {
+            "type": "object",
+            "required": [
+                "college",
+                "nickname",
+                "password",
+                "real_name",
+                "student_id",
+                "username"
+            ],
+            "properties": {
+                "college": {
+                    "type": "string",
+                    "example": "计算机学院"
+                },
+                "email": {
+                    "type": "string",
+                    "example": "user@example.com"
+                },
+                "nickname": {
+                    "type": "string",
+                    "example": "小明"
+                },
+                "password": {
+                    "type": "string",
+                    "minLength": 6,
+                    "example": "password123"
+                },
+                "qq": {
+                    "type": "string",
+                    "example": "123456789"
+                },
+                "real_name": {
+                    "type": "string",
+                    "example": "张三"
+                },
+                "student_id": {
+                    "type": "string",
+                    "example": "2021001001"
+                },
+                "username": {
+                    "type": "string",
+                    "example": "user123"
+                }
+            }
+        },
+        "service.RejudgeResult": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "description": "重新进入等待评测的提交数",
+                    "type": "integer",
+                    "example": 12
+                }
+            }
+        },
+        "service.SetClarificationPublicRequest": {
+            "type": "object",
+            "properties": {
+                "is_public": {
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
+        "service.SignedURL": {
+            "type": "object",
+            "properties": {
+                "expires_at": {
                     "type": "string",
-                    "example": "前端开发"
-                },
-                "problems": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/model.Problem"
-                    }
+                    "example": "2024-10-01T23:59:59+08:00"
                 },
-                "updated_at": {
-                    "type": "string"
+                "url": {
+                    "type": "string",
+                    "example": "/api/attachments/1/download?expires=1727798399\u0026signature=5d41402abc4b2a76b9719d911017c592"
                 }
             }
         },
-        "model.Problem": {
+        "service.SimilarSubmission": {
             "type": "object",
-            "required": [
-                "description",
-                "direction_id",
-                "title"
-            ],
             "properties": {
-                "created_at": {
-                    "type": "string"
+                "id": {
+                    "type": "integer",
+                    "example": 3
                 },
-                "description": {
+                "nickname": {
                     "type": "string",
-                    "example": "使用HTML、CSS、JavaScript实现一个基本的计算器功能"
+                    "example": "小明"
                 },
-                "direction": {
-                    "description": "关联关系",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/model.Direction"
-                        }
-                    ]
+                "real_name": {
+                    "type": "string",
+                    "example": "张三"
                 },
-                "direction_id": {
-                    "type": "integer",
-                    "example": 1
+                "similarity_score": {
+                    "description": "该提交的最高相似度",
+                    "type": "number",
+                    "example": 0.92
                 },
-                "id": {
-                    "type": "integer"
+                "user_id": {
+                    "type": "integer",
+                    "example": 5
+                }
+            }
+        },
+        "service.SimilarityCluster": {
+            "type": "object",
+            "properties": {
+                "max_score": {
+                    "type": "number",
+                    "example": 0.92
                 },
-                "submission_points": {
+                "reports": {
+                    "description": "分组内达到阈值的提交对，不含提交内容",
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/model.SubmissionPoint"
+                        "$ref": "#/definitions/model.SimilarityReport"
                     }
                 },
                 "submissions": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/model.Submission"
+                        "$ref": "#/definitions/service.SimilarSubmission"
                     }
-                },
-                "title": {
-                    "type": "string",
-                    "example": "实现一个简单的计算器"
-                },
-                "updated_at": {
-                    "type": "string"
                 }
             }
         },
-        "model.Score": {
+        "service.SimilarityResult": {
             "type": "object",
-            "required": [
-                "reviewer_id",
-                "score",
-                "submission_id",
-                "user_id"
-            ],
             "properties": {
-                "comment": {
-                    "type": "string",
-                    "example": "代码实现良好，但缺少注释"
-                },
-                "created_at": {
-                    "type": "string"
-                },
-                "id": {
-                    "type": "integer"
-                },
-                "reviewer": {
-                    "$ref": "#/definitions/model.User"
-                },
-                "reviewer_id": {
+                "flagged": {
+                    "description": "标记为可疑的提交数",
                     "type": "integer",
-                    "example": 2
+                    "example": 4
                 },
-                "score": {
+                "reports": {
+                    "description": "保存的相似度报告数",
                     "type": "integer",
-                    "minimum": 0,
-                    "example": 85
+                    "example": 15
                 },
-                "submission": {
-                    "$ref": "#/definitions/model.Submission"
-                },
-                "submission_id": {
+                "submissions": {
+                    "description": "参与比较的提交数",
                     "type": "integer",
-                    "example": 1
-                },
-                "updated_at": {
-                    "type": "string"
-                },
-                "user": {
-                    "description": "关联关系",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/model.User"
-                        }
-                    ]
+                    "example": 42
                 },
-                "user_id": {
-                    "type": "integer",
-                    "example": 1
+                "threshold": {
+                    "type": "number",
+                    "example": 0.8
                 }
             }
         },
-        "model.Submission": {
+        "service.SubmissionResponse": {
             "type": "object",
             "required": [
                 "content",
@@ -2352,6 +10309,14 @@ const docTemplate = `{
                 "user_id"
             ],
             "properties": {
+                "check_run": {
+                    "description": "代码仓库提交点的检查报告，仅待评分列表加载",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.CheckRun"
+                        }
+                    ]
+                },
                 "content": {
                     "type": "string",
                     "example": "https://github.com/user/project"
@@ -2375,6 +10340,14 @@ const docTemplate = `{
                         "$ref": "#/definitions/model.Score"
                     }
                 },
+                "similarity": {
+                    "description": "相似度标记，不入库，仅待评分列表返回",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.SimilarityFlag"
+                        }
+                    ]
+                },
                 "submission_point": {
                     "$ref": "#/definitions/model.SubmissionPoint"
                 },
@@ -2382,6 +10355,17 @@ const docTemplate = `{
                     "type": "integer",
                     "example": 1
                 },
+                "team": {
+                    "$ref": "#/definitions/model.Team"
+                },
+                "team_id": {
+                    "description": "组队题目的提交所属队伍，个人提交为空",
+                    "type": "integer",
+                    "example": 2
+                },
+                "total_score": {
+                    "type": "integer"
+                },
                 "updated_at": {
                     "type": "string"
                 },
@@ -2394,371 +10378,280 @@ const docTemplate = `{
                     ]
                 },
                 "user_id": {
+                    "description": "组队题目为首次提交的队员",
                     "type": "integer",
                     "example": 1
                 }
             }
         },
-        "model.SubmissionPoint": {
+        "service.TeamInviteInfo": {
             "type": "object",
-            "required": [
-                "max_score",
-                "name",
-                "problem_id"
-            ],
             "properties": {
                 "created_at": {
                     "type": "string"
                 },
                 "id": {
-                    "type": "integer"
-                },
-                "max_score": {
-                    "type": "integer",
-                    "minimum": 1,
-                    "example": 100
-                },
-                "name": {
-                    "type": "string",
-                    "example": "源代码提交"
-                },
-                "problem": {
-                    "description": "关联关系",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/model.Problem"
-                        }
-                    ]
-                },
-                "problem_id": {
                     "type": "integer",
-                    "example": 1
-                },
-                "submissions": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/model.Submission"
-                    }
-                },
-                "updated_at": {
-                    "type": "string"
-                }
-            }
-        },
-        "model.User": {
-            "type": "object",
-            "required": [
-                "college",
-                "nickname",
-                "real_name",
-                "student_id",
-                "username"
-            ],
-            "properties": {
-                "college": {
-                    "type": "string",
-                    "example": "计算机学院"
-                },
-                "created_at": {
-                    "type": "string"
-                },
-                "email": {
-                    "type": "string",
-                    "example": "user@example.com"
-                },
-                "id": {
-                    "type": "integer"
-                },
-                "is_admin": {
-                    "type": "boolean"
+                    "example": 3
                 },
                 "nickname": {
                     "type": "string",
-                    "example": "小明"
-                },
-                "qq": {
-                    "type": "string",
-                    "example": "123456789"
-                },
-                "real_name": {
-                    "type": "string",
-                    "example": "张三"
-                },
-                "student_id": {
-                    "type": "string",
-                    "example": "2021001001"
-                },
-                "updated_at": {
-                    "type": "string"
+                    "example": "小红"
                 },
-                "username": {
-                    "type": "string",
-                    "example": "user123"
-                }
-            }
-        },
-        "response.Response": {
-            "type": "object",
-            "properties": {
-                "code": {
+                "user_id": {
                     "type": "integer",
-                    "example": 0
+                    "example": 6
                 },
-                "data": {},
-                "msg": {
+                "username": {
                     "type": "string",
-                    "example": "success"
+                    "example": "user456"
                 }
             }
         },
-        "service.CreateDirectionRequest": {
-            "type": "object"
-        },
-        "service.CreateProblemRequest": {
+        "service.TeamMemberInfo": {
             "type": "object",
-            "required": [
-                "description",
-                "direction_id",
-                "title"
-            ],
             "properties": {
-                "description": {
-                    "type": "string",
-                    "example": "使用HTML、CSS、JavaScript实现一个基本的计算器功能"
-                },
-                "direction_id": {
-                    "type": "integer",
-                    "example": 1
+                "joined_at": {
+                    "type": "string"
                 },
-                "title": {
-                    "type": "string",
-                    "example": "实现一个简单的计算器"
-                }
-            }
-        },
-        "service.CreateScoreRequest": {
-            "type": "object",
-            "required": [
-                "score",
-                "submission_id"
-            ],
-            "properties": {
-                "comment": {
+                "nickname": {
                     "type": "string",
-                    "example": "代码实现良好，但缺少注释"
+                    "example": "小明"
                 },
-                "score": {
+                "user_id": {
                     "type": "integer",
-                    "minimum": 0,
-                    "example": 85
+                    "example": 5
                 },
-                "submission_id": {
-                    "type": "integer",
-                    "example": 1
+                "username": {
+                    "type": "string",
+                    "example": "user123"
                 }
             }
         },
-        "service.CreateSubmissionPointRequest": {
+        "service.TeamResponse": {
             "type": "object",
-            "required": [
-                "max_score",
-                "name"
-            ],
             "properties": {
-                "max_score": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
                     "type": "integer",
-                    "minimum": 1,
-                    "example": 100
+                    "example": 2
+                },
+                "invites": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.TeamInviteInfo"
+                    }
+                },
+                "leader_id": {
+                    "type": "integer",
+                    "example": 5
+                },
+                "max_size": {
+                    "description": "题目的队伍人数上限",
+                    "type": "integer",
+                    "example": 3
+                },
+                "members": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.TeamMemberInfo"
+                    }
                 },
                 "name": {
                     "type": "string",
-                    "example": "源代码提交"
+                    "example": "第一小队"
+                },
+                "problem_id": {
+                    "type": "integer",
+                    "example": 1
                 }
             }
         },
-        "service.CreateSubmissionRequest": {
+        "service.UpdateCheckStepRequest": {
             "type": "object",
-            "required": [
-                "content",
-                "problem_id",
-                "submission_point_id"
-            ],
             "properties": {
-                "content": {
+                "command": {
                     "type": "string",
-                    "example": "https://github.com/user/project"
+                    "example": "make build"
                 },
-                "problem_id": {
+                "criterion": {
+                    "type": "string",
+                    "maxLength": 200,
+                    "example": "项目能够成功构建"
+                },
+                "kind": {
+                    "type": "string",
+                    "example": "build"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "构建"
+                },
+                "path": {
+                    "type": "string",
+                    "example": "README.md"
+                },
+                "points": {
                     "type": "integer",
-                    "example": 1
+                    "minimum": 0,
+                    "example": 20
                 },
-                "submission_point_id": {
+                "position": {
                     "type": "integer",
                     "example": 1
+                },
+                "timeout_seconds": {
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 300
                 }
             }
         },
-        "service.LoginRequest": {
+        "service.UpdateCheckerRequest": {
             "type": "object",
-            "required": [
-                "password",
-                "username"
-            ],
             "properties": {
-                "password": {
+                "checker": {
                     "type": "string",
-                    "example": "password123"
+                    "example": "import sys\nsys.exit(0 if open(sys.argv[2]).read().split() == open(sys.argv[3]).read().split() else 1)"
                 },
-                "username": {
+                "language": {
                     "type": "string",
-                    "example": "user123"
+                    "example": "python"
                 }
             }
         },
-        "service.LoginResponse": {
+        "service.UpdateDirectionRequest": {
+            "type": "object"
+        },
+        "service.UpdateHintRequest": {
             "type": "object",
             "properties": {
-                "token": {
+                "content": {
                     "type": "string",
-                    "example": "eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."
+                    "example": "可以先用栈把中缀表达式转换为后缀表达式"
                 },
-                "user": {
-                    "$ref": "#/definitions/model.User"
+                "penalty": {
+                    "type": "integer",
+                    "example": 10
+                },
+                "position": {
+                    "type": "integer",
+                    "example": 1
                 }
             }
         },
-        "service.RankingItem": {
+        "service.UpdateJudgeCaseRequest": {
             "type": "object",
             "properties": {
-                "nickname": {
-                    "type": "string"
-                },
-                "score": {
-                    "type": "integer"
+                "position": {
+                    "type": "integer",
+                    "example": 1
                 },
-                "user_id": {
-                    "type": "integer"
+                "weight": {
+                    "type": "integer",
+                    "minimum": 1,
+                    "example": 2
                 }
             }
         },
-        "service.RegisterRequest": {
+        "service.UpdateNotificationSettingRequest": {
             "type": "object",
-            "required": [
-                "college",
-                "nickname",
-                "password",
-                "real_name",
-                "student_id",
-                "username"
-            ],
             "properties": {
-                "college": {
-                    "type": "string",
-                    "example": "计算机学院"
-                },
                 "email": {
-                    "type": "string",
-                    "example": "user@example.com"
-                },
-                "nickname": {
-                    "type": "string",
-                    "example": "小明"
-                },
-                "password": {
-                    "type": "string",
-                    "minLength": 6,
-                    "example": "password123"
-                },
-                "qq": {
-                    "type": "string",
-                    "example": "123456789"
+                    "type": "boolean",
+                    "example": true
                 },
-                "real_name": {
-                    "type": "string",
-                    "example": "张三"
+                "onebot": {
+                    "type": "boolean",
+                    "example": true
                 },
-                "student_id": {
-                    "type": "string",
-                    "example": "2021001001"
+                "webhook": {
+                    "type": "boolean",
+                    "example": false
                 },
-                "username": {
+                "webhook_url": {
                     "type": "string",
-                    "example": "user123"
+                    "example": "https://example.com/hook"
                 }
             }
         },
-        "service.SubmissionResponse": {
+        "service.UpdatePrerequisitesRequest": {
             "type": "object",
-            "required": [
-                "content",
-                "problem_id",
-                "submission_point_id",
-                "user_id"
-            ],
             "properties": {
-                "content": {
-                    "type": "string",
-                    "example": "https://github.com/user/project"
-                },
-                "created_at": {
-                    "type": "string"
-                },
-                "id": {
-                    "type": "integer"
-                },
-                "problem": {
-                    "$ref": "#/definitions/model.Problem"
-                },
-                "problem_id": {
-                    "type": "integer",
-                    "example": 1
-                },
-                "scores": {
+                "prerequisites": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/model.Score"
+                        "$ref": "#/definitions/service.PrerequisiteRequest"
                     }
+                }
+            }
+        },
+        "service.UpdateProblemRequest": {
+            "type": "object",
+            "properties": {
+                "change_note": {
+                    "type": "string",
+                    "example": "修正了输入格式的说明"
                 },
-                "submission_point": {
-                    "$ref": "#/definitions/model.SubmissionPoint"
+                "description": {
+                    "type": "string",
+                    "example": "使用HTML、CSS、JavaScript实现一个基本的计算器功能"
                 },
-                "submission_point_id": {
+                "difficulty": {
+                    "description": "Difficulty、EstimatedMinutes为空时不修改，为0时清除",
                     "type": "integer",
-                    "example": 1
+                    "example": 3
                 },
-                "total_score": {
-                    "type": "integer"
+                "estimated_minutes": {
+                    "type": "integer",
+                    "example": 120
                 },
-                "updated_at": {
-                    "type": "string"
+                "significant": {
+                    "description": "Significant 标题或题面有变化时标记为重要修改，通知已提交的选手",
+                    "type": "boolean",
+                    "example": false
                 },
-                "user": {
-                    "description": "关联关系",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/model.User"
-                        }
+                "slug": {
+                    "type": "string",
+                    "example": "calculator"
+                },
+                "tags": {
+                    "description": "Tags 为空时不修改，为空列表时清除全部标签",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "算法",
+                        "动态规划"
                     ]
                 },
-                "user_id": {
+                "team_size": {
+                    "description": "TeamSize 为空时不修改；已有提交后不能在个人题目和组队题目之间切换，改为0时解散全部队伍",
                     "type": "integer",
-                    "example": 1
+                    "example": 3
+                },
+                "title": {
+                    "type": "string",
+                    "example": "实现一个简单的计算器"
                 }
             }
         },
-        "service.UpdateDirectionRequest": {
-            "type": "object"
-        },
-        "service.UpdateProblemRequest": {
+        "service.UpdateProblemStatusRequest": {
             "type": "object",
+            "required": [
+                "status"
+            ],
             "properties": {
-                "description": {
+                "publish_at": {
                     "type": "string",
-                    "example": "使用HTML、CSS、JavaScript实现一个基本的计算器功能"
+                    "example": "2024-09-01T09:00:00+08:00"
                 },
-                "title": {
+                "status": {
                     "type": "string",
-                    "example": "实现一个简单的计算器"
+                    "example": "scheduled"
                 }
             }
         },
@@ -2782,14 +10675,50 @@ const docTemplate = `{
         "service.UpdateSubmissionPointRequest": {
             "type": "object",
             "properties": {
+                "change_note": {
+                    "type": "string",
+                    "example": "截止时间延后一周"
+                },
+                "deadline": {
+                    "type": "string",
+                    "example": "2024-10-01T23:59:59+08:00"
+                },
+                "judge_language": {
+                    "type": "string",
+                    "example": "python"
+                },
                 "max_score": {
                     "type": "integer",
                     "minimum": 1,
                     "example": 100
                 },
+                "memory_limit_mb": {
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 256
+                },
                 "name": {
                     "type": "string",
                     "example": "源代码提交"
+                },
+                "rubric": {
+                    "type": "string",
+                    "example": "功能完整60分，代码规范20分，文档20分"
+                },
+                "significant": {
+                    "description": "Significant 设置有变化时标记为重要修改，通知已提交的选手",
+                    "type": "boolean",
+                    "example": false
+                },
+                "time_limit_ms": {
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 1000
+                },
+                "type": {
+                    "description": "Type、JudgeLanguage 为空时不修改；TimeLimitMS、MemoryLimitMB为空时不修改，为0时使用配置中的默认值",
+                    "type": "string",
+                    "example": "auto"
                 }
             }
         },
@@ -2825,6 +10754,41 @@ const docTemplate = `{
                     "example": "2021001001"
                 }
             }
+        },
+        "service.UpdateWebhookRequest": {
+            "type": "object",
+            "properties": {
+                "direction_id": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "enabled": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "event_types": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "submission.created",
+                        "score.created"
+                    ]
+                },
+                "name": {
+                    "type": "string",
+                    "example": "招新群机器人"
+                },
+                "secret": {
+                    "type": "string",
+                    "example": ""
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://bot.example.com/glimgate"
+                }
+            }
         }
     },
     "securityDefinitions": {
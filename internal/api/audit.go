@@ -0,0 +1,177 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tksky1/glimgate/internal/service"
+	"github.com/tksky1/glimgate/pkg/response"
+)
+
+// AuditAPI 审计日志API处理器
+type AuditAPI struct {
+	auditService *service.AuditService
+}
+
+// NewAuditAPI 创建审计日志API实例
+func NewAuditAPI(auditService *service.AuditService) *AuditAPI {
+	return &AuditAPI{
+		auditService: auditService,
+	}
+}
+
+// GetAuditLogs 查询审计日志（管理员）
+// @Summary 查询审计日志
+// @Description 管理员按操作者、操作类型、对象和时间范围查询审计日志
+// @Tags 审计日志
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param operator_id query int false "操作者ID"
+// @Param action query string false "操作类型(create/update/delete)"
+// @Param entity_type query string false "对象类型(user/direction/problem/submission_point/score)"
+// @Param entity_id query int false "对象ID"
+// @Param request_id query string false "请求ID"
+// @Param start query string false "开始时间(RFC3339或2006-01-02)"
+// @Param end query string false "结束时间(RFC3339或2006-01-02)"
+// @Param page query int false "页码" default(1)
+// @Param page_size query int false "每页数量" default(20)
+// @Success 200 {object} response.Response{data=map[string]interface{}} "获取成功"
+// @Failure 400 {object} response.Response "参数错误"
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 403 {object} response.Response "权限不足"
+// @Router /api/admin/audit-logs [get]
+func (a *AuditAPI) GetAuditLogs(c *gin.Context) {
+	filter, err := parseAuditLogFilter(c)
+	if err != nil {
+		response.ErrorWithMsg(c, response.CodeInvalidParams, err.Error())
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	logs, total, err := a.auditService.GetAuditLogs(filter, page, pageSize)
+	if err != nil {
+		response.ErrorWithMsg(c, response.CodeInternalError, err.Error())
+		return
+	}
+
+	data := map[string]interface{}{
+		"logs":      logs,
+		"total":     total,
+		"page":      page,
+		"page_size": pageSize,
+	}
+
+	response.Success(c, data)
+}
+
+// ExportAuditLogs 导出审计日志（管理员）
+// @Summary 导出审计日志
+// @Description 管理员按查询条件将审计日志导出为CSV
+// @Tags 审计日志
+// @Produce text/csv
+// @Security ApiKeyAuth
+// @Param operator_id query int false "操作者ID"
+// @Param action query string false "操作类型(create/update/delete)"
+// @Param entity_type query string false "对象类型(user/direction/problem/submission_point/score)"
+// @Param entity_id query int false "对象ID"
+// @Param request_id query string false "请求ID"
+// @Param start query string false "开始时间(RFC3339或2006-01-02)"
+// @Param end query string false "结束时间(RFC3339或2006-01-02)"
+// @Success 200 {file} file "CSV文件"
+// @Failure 400 {object} response.Response "参数错误"
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 403 {object} response.Response "权限不足"
+// @Router /api/admin/audit-logs/export [get]
+func (a *AuditAPI) ExportAuditLogs(c *gin.Context) {
+	filter, err := parseAuditLogFilter(c)
+	if err != nil {
+		response.ErrorWithMsg(c, response.CodeInvalidParams, err.Error())
+		return
+	}
+
+	filename := fmt.Sprintf("audit-logs-%s.csv", time.Now().Format("20060102150405"))
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+
+	// 写入UTF-8 BOM，便于Excel正确识别中文
+	c.Writer.Write([]byte("\xEF\xBB\xBF"))
+	if err := a.auditService.ExportAuditLogs(filter, c.Writer); err != nil {
+		c.Error(err)
+	}
+}
+
+// parseAuditLogFilter 解析审计日志查询参数
+func parseAuditLogFilter(c *gin.Context) (*service.AuditLogFilter, error) {
+	operatorID, _ := strconv.ParseUint(c.DefaultQuery("operator_id", "0"), 10, 32)
+	entityID, _ := strconv.ParseUint(c.DefaultQuery("entity_id", "0"), 10, 32)
+
+	filter := &service.AuditLogFilter{
+		OperatorID: uint(operatorID),
+		Action:     c.Query("action"),
+		EntityType: c.Query("entity_type"),
+		EntityID:   uint(entityID),
+		RequestID:  c.Query("request_id"),
+	}
+
+	if start := c.Query("start"); start != "" {
+		t, err := parseQueryTime(start)
+		if err != nil {
+			return nil, errors.New("开始时间格式错误")
+		}
+		filter.Start = &t
+	}
+	if end := c.Query("end"); end != "" {
+		t, err := parseQueryTime(end)
+		if err != nil {
+			return nil, errors.New("结束时间格式错误")
+		}
+		// 仅给出日期时包含当天全部记录
+		if len(end) == len("2006-01-02") {
+			t = t.Add(24*time.Hour - time.Nanosecond)
+		}
+		filter.End = &t
+	}
+
+	return filter, nil
+}
+
+// parseQueryTime 解析RFC3339或日期格式的时间参数
+func parseQueryTime(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.ParseInLocation("2006-01-02", value, time.Local)
+}
+
+// optionalUserID 获取可选认证接口中的当前用户ID，未登录时返回0
+func optionalUserID(c *gin.Context) uint {
+	userID, _ := c.Get("user_id")
+	id, _ := userID.(uint)
+	return id
+}
+
+// getOperator 从请求上下文构造操作者信息
+func getOperator(c *gin.Context) *service.Operator {
+	op := &service.Operator{
+		IP:        c.ClientIP(),
+		RequestID: c.GetString("request_id"),
+	}
+	if userID, exists := c.Get("user_id"); exists {
+		op.UserID = userID.(uint)
+	}
+	op.Username = c.GetString("username")
+	return op
+}
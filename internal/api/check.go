@@ -0,0 +1,269 @@
+package api
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tksky1/glimgate/internal/service"
+	"github.com/tksky1/glimgate/pkg/response"
+)
+
+// CheckAPI 代码仓库检查API处理器
+type CheckAPI struct {
+	checkService *service.CheckService
+}
+
+// NewCheckAPI 创建代码仓库检查API实例
+func NewCheckAPI(checkService *service.CheckService) *CheckAPI {
+	return &CheckAPI{
+		checkService: checkService,
+	}
+}
+
+// GetCheckRun 获取提交的检查报告
+// @Summary 获取提交的检查报告
+// @Description 获取代码仓库提交点的提交的检查状态、实际检查的提交、各步骤的结果和日志，以及建议分数和评语，只有提交者本人、所属队伍的成员或管理员可以查看；步骤日志只对管理员返回
+// @Tags 代码仓库检查
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "提交ID"
+// @Success 200 {object} response.Response{data=model.CheckRun} "获取成功"
+// @Failure 400 {object} response.Response "参数错误"
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 403 {object} response.Response "权限不足"
+// @Failure 404 {object} response.Response "提交或检查报告不存在"
+// @Router /api/submissions/{id}/checks [get]
+func (a *CheckAPI) GetCheckRun(c *gin.Context) {
+	submissionID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, response.CodeInvalidParams)
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	isAdmin, _ := c.Get("is_admin")
+	run, err := a.checkService.GetCheckRun(uint(submissionID), userID.(uint), isAdmin.(bool))
+	if err != nil {
+		switch err.Error() {
+		case "提交不存在":
+			response.Error(c, response.CodeSubmissionNotFound)
+		case "检查报告不存在":
+			response.Error(c, response.CodeCheckRunNotFound)
+		case "无权限查看该提交":
+			response.Error(c, response.CodeForbidden)
+		default:
+			response.ErrorWithMsg(c, response.CodeInternalError, err.Error())
+		}
+		return
+	}
+
+	response.Success(c, run)
+}
+
+// GetSteps 获取提交点的检查步骤列表（管理员）
+// @Summary 获取检查步骤列表
+// @Description 管理员获取代码仓库提交点的检查步骤，按顺序排列
+// @Tags 代码仓库检查
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "提交点ID"
+// @Success 200 {object} response.Response{data=[]model.CheckStep} "获取成功"
+// @Failure 400 {object} response.Response "参数错误"
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 403 {object} response.Response "权限不足"
+// @Failure 404 {object} response.Response "提交点不存在"
+// @Router /api/admin/submission-points/{id}/check-steps [get]
+func (a *CheckAPI) GetSteps(c *gin.Context) {
+	pointID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, response.CodeInvalidParams)
+		return
+	}
+
+	steps, err := a.checkService.GetSteps(uint(pointID))
+	if err != nil {
+		respondCheckError(c, err)
+		return
+	}
+
+	response.Success(c, steps)
+}
+
+// CreateStep 添加检查步骤（管理员）
+// @Summary 添加检查步骤
+// @Description 管理员为代码仓库提交点添加检查步骤。file_exists检查仓库内的路径（支持通配符）是否存在；build、test、lint在仓库根目录执行命令，退出码为0表示通过，构建未通过时跳过之后的命令步骤。通过的步骤按points计入建议分数。添加步骤不会重新检查已有的提交
+// @Tags 代码仓库检查
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "提交点ID"
+// @Param request body service.CreateCheckStepRequest true "检查步骤"
+// @Success 200 {object} response.Response{data=model.CheckStep} "添加成功"
+// @Failure 400 {object} response.Response "参数错误"
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 403 {object} response.Response "权限不足"
+// @Failure 404 {object} response.Response "提交点不存在"
+// @Router /api/admin/submission-points/{id}/check-steps [post]
+func (a *CheckAPI) CreateStep(c *gin.Context) {
+	pointID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, response.CodeInvalidParams)
+		return
+	}
+
+	var req service.CreateCheckStepRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, response.CodeBindError)
+		return
+	}
+
+	step, err := a.checkService.CreateStep(getOperator(c), uint(pointID), &req)
+	if err != nil {
+		respondCheckError(c, err)
+		return
+	}
+
+	response.Success(c, step)
+}
+
+// UpdateStep 修改检查步骤（管理员）
+// @Summary 修改检查步骤
+// @Description 管理员修改检查步骤，未提供的字段不修改
+// @Tags 代码仓库检查
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "检查步骤ID"
+// @Param request body service.UpdateCheckStepRequest true "检查步骤"
+// @Success 200 {object} response.Response{data=model.CheckStep} "修改成功"
+// @Failure 400 {object} response.Response "参数错误"
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 403 {object} response.Response "权限不足"
+// @Failure 404 {object} response.Response "检查步骤不存在"
+// @Router /api/admin/check-steps/{id} [put]
+func (a *CheckAPI) UpdateStep(c *gin.Context) {
+	stepID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, response.CodeInvalidParams)
+		return
+	}
+
+	var req service.UpdateCheckStepRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, response.CodeBindError)
+		return
+	}
+
+	step, err := a.checkService.UpdateStep(getOperator(c), uint(stepID), &req)
+	if err != nil {
+		respondCheckError(c, err)
+		return
+	}
+
+	response.Success(c, step)
+}
+
+// DeleteStep 删除检查步骤（管理员）
+// @Summary 删除检查步骤
+// @Description 管理员删除检查步骤，不会重新检查已有的提交
+// @Tags 代码仓库检查
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "检查步骤ID"
+// @Success 200 {object} response.Response "删除成功"
+// @Failure 400 {object} response.Response "参数错误"
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 403 {object} response.Response "权限不足"
+// @Failure 404 {object} response.Response "检查步骤不存在"
+// @Router /api/admin/check-steps/{id} [delete]
+func (a *CheckAPI) DeleteStep(c *gin.Context) {
+	stepID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, response.CodeInvalidParams)
+		return
+	}
+
+	if err := a.checkService.DeleteStep(getOperator(c), uint(stepID)); err != nil {
+		respondCheckError(c, err)
+		return
+	}
+
+	response.Success(c, nil)
+}
+
+// RecheckPoint 重新检查提交点的全部提交（管理员）
+// @Summary 重新检查提交点
+// @Description 管理员将代码仓库提交点下的全部提交重新放入检查队列，用于修改检查步骤之后
+// @Tags 代码仓库检查
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "提交点ID"
+// @Success 200 {object} response.Response{data=service.RecheckResult} "已加入检查队列"
+// @Failure 400 {object} response.Response "参数错误"
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 403 {object} response.Response "权限不足"
+// @Failure 404 {object} response.Response "提交点不存在"
+// @Router /api/admin/submission-points/{id}/recheck [post]
+func (a *CheckAPI) RecheckPoint(c *gin.Context) {
+	pointID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, response.CodeInvalidParams)
+		return
+	}
+
+	result, err := a.checkService.RecheckPoint(uint(pointID))
+	if err != nil {
+		respondCheckError(c, err)
+		return
+	}
+
+	response.Success(c, result)
+}
+
+// RecheckSubmission 重新检查提交（管理员）
+// @Summary 重新检查提交
+// @Description 管理员将代码仓库提交点的一个提交重新放入检查队列
+// @Tags 代码仓库检查
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "提交ID"
+// @Success 200 {object} response.Response{data=model.CheckRun} "已加入检查队列"
+// @Failure 400 {object} response.Response "参数错误"
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 403 {object} response.Response "权限不足"
+// @Failure 404 {object} response.Response "提交不存在"
+// @Router /api/admin/submissions/{id}/recheck [post]
+func (a *CheckAPI) RecheckSubmission(c *gin.Context) {
+	submissionID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, response.CodeInvalidParams)
+		return
+	}
+
+	run, err := a.checkService.RecheckSubmission(uint(submissionID))
+	if err != nil {
+		if err.Error() == "提交不存在" {
+			response.Error(c, response.CodeSubmissionNotFound)
+			return
+		}
+		respondCheckError(c, err)
+		return
+	}
+
+	response.Success(c, run)
+}
+
+// respondCheckError 将代码仓库检查相关的错误转换为响应
+func respondCheckError(c *gin.Context, err error) {
+	switch err.Error() {
+	case "提交点不存在":
+		response.ErrorWithMsg(c, response.CodeProblemNotFound, err.Error())
+	case "检查步骤不存在":
+		response.Error(c, response.CodeCheckStepNotFound)
+	case "提交点不是代码仓库类型", "步骤名称不能为空", "file_exists步骤需要填写路径", "路径必须是仓库内的相对路径",
+		"路径格式不正确", "build、test和lint步骤需要填写命令", "检查步骤类型只能为file_exists、build、test或lint":
+		response.ErrorWithMsg(c, response.CodeInvalidParams, err.Error())
+	default:
+		response.ErrorWithMsg(c, response.CodeInternalError, err.Error())
+	}
+}
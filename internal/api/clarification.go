@@ -0,0 +1,294 @@
+package api
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tksky1/glimgate/internal/service"
+	"github.com/tksky1/glimgate/pkg/response"
+)
+
+// ClarificationAPI 答疑API处理器
+type ClarificationAPI struct {
+	clarificationService *service.ClarificationService
+}
+
+// NewClarificationAPI 创建答疑API实例
+func NewClarificationAPI(clarificationService *service.ClarificationService) *ClarificationAPI {
+	return &ClarificationAPI{
+		clarificationService: clarificationService,
+	}
+}
+
+// CreateClarification 针对题目提问
+// @Summary 提问
+// @Description 用户针对已发布的题目提交私有提问，已归档的题目不再接受提问
+// @Tags 答疑管理
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "题目ID"
+// @Param request body service.CreateClarificationRequest true "提问内容"
+// @Success 200 {object} response.Response{data=model.Clarification} "提问成功"
+// @Failure 400 {object} response.Response "参数错误"
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 403 {object} response.Response "题目已归档"
+// @Failure 404 {object} response.Response "题目不存在"
+// @Router /api/problems/{id}/clarifications [post]
+func (a *ClarificationAPI) CreateClarification(c *gin.Context) {
+	problemID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, response.CodeInvalidParams)
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+
+	var req service.CreateClarificationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, response.CodeBindError)
+		return
+	}
+
+	clarification, err := a.clarificationService.CreateClarification(userID.(uint), uint(problemID), &req)
+	if err != nil {
+		if err.Error() == "题目不存在" {
+			response.Error(c, response.CodeProblemNotFound)
+			return
+		}
+		if err.Error() == "题目已归档，不再接受提问" {
+			response.ErrorWithMsg(c, response.CodeForbidden, err.Error())
+			return
+		}
+		response.ErrorWithMsg(c, response.CodeInternalError, err.Error())
+		return
+	}
+
+	response.Success(c, clarification)
+}
+
+// GetPublicClarifications 获取题目的公开答疑
+// @Summary 获取公开答疑
+// @Description 获取指定题目已公开的答疑列表，题目未发布时返回题目不存在。可选携带token，未满足解锁条件时返回题目尚未解锁
+// @Tags 答疑管理
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "题目ID"
+// @Success 200 {object} response.Response{data=[]model.Clarification} "获取成功"
+// @Failure 400 {object} response.Response "参数错误"
+// @Failure 403 {object} response.Response "题目尚未解锁"
+// @Failure 404 {object} response.Response "题目不存在"
+// @Failure 500 {object} response.Response "内部错误"
+// @Router /api/problems/{id}/clarifications [get]
+func (a *ClarificationAPI) GetPublicClarifications(c *gin.Context) {
+	problemID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, response.CodeInvalidParams)
+		return
+	}
+
+	clarifications, err := a.clarificationService.GetPublicClarifications(uint(problemID), optionalUserID(c))
+	if err != nil {
+		if err.Error() == "题目不存在" {
+			response.Error(c, response.CodeProblemNotFound)
+			return
+		}
+		if err.Error() == "题目尚未解锁" {
+			response.ErrorWithMsg(c, response.CodeForbidden, err.Error())
+			return
+		}
+		response.ErrorWithMsg(c, response.CodeInternalError, err.Error())
+		return
+	}
+
+	response.Success(c, clarifications)
+}
+
+// GetMyClarifications 获取我的提问列表
+// @Summary 获取我的提问列表
+// @Description 获取当前用户的提问及未读回复数
+// @Tags 答疑管理
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param problem_id query int false "题目ID"
+// @Success 200 {object} response.Response{data=service.MyClarificationsResponse} "获取成功"
+// @Failure 401 {object} response.Response "未授权"
+// @Router /api/clarifications/my [get]
+func (a *ClarificationAPI) GetMyClarifications(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	problemID, _ := strconv.ParseUint(c.DefaultQuery("problem_id", "0"), 10, 32)
+
+	result, err := a.clarificationService.GetMyClarifications(userID.(uint), uint(problemID))
+	if err != nil {
+		response.ErrorWithMsg(c, response.CodeInternalError, err.Error())
+		return
+	}
+
+	response.Success(c, result)
+}
+
+// GetClarification 获取答疑详情
+// @Summary 获取答疑详情
+// @Description 获取答疑详情，提问者查看时会将回复标记为已读
+// @Tags 答疑管理
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "答疑ID"
+// @Success 200 {object} response.Response{data=model.Clarification} "获取成功"
+// @Failure 400 {object} response.Response "参数错误"
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 403 {object} response.Response "权限不足"
+// @Failure 404 {object} response.Response "答疑不存在"
+// @Router /api/clarifications/{id} [get]
+func (a *ClarificationAPI) GetClarification(c *gin.Context) {
+	clarificationID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, response.CodeInvalidParams)
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	isAdmin, _ := c.Get("is_admin")
+
+	clarification, err := a.clarificationService.GetClarificationByID(uint(clarificationID), userID.(uint), isAdmin.(bool))
+	if err != nil {
+		if err.Error() == "答疑不存在" {
+			response.Error(c, response.CodeClarificationNotFound)
+			return
+		}
+		if err.Error() == "无权限查看该答疑" {
+			response.Error(c, response.CodeForbidden)
+			return
+		}
+		response.ErrorWithMsg(c, response.CodeInternalError, err.Error())
+		return
+	}
+
+	response.Success(c, clarification)
+}
+
+// GetUnansweredClarifications 获取待回复答疑队列（管理员）
+// @Summary 获取待回复答疑队列
+// @Description 方向负责人获取负责方向下尚未回复的提问
+// @Tags 答疑管理
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param problem_id query int false "题目ID"
+// @Success 200 {object} response.Response{data=[]model.Clarification} "获取成功"
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 403 {object} response.Response "权限不足"
+// @Router /api/admin/clarifications/unanswered [get]
+func (a *ClarificationAPI) GetUnansweredClarifications(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	problemID, _ := strconv.ParseUint(c.DefaultQuery("problem_id", "0"), 10, 32)
+
+	clarifications, err := a.clarificationService.GetUnansweredClarifications(userID.(uint), uint(problemID))
+	if err != nil {
+		response.ErrorWithMsg(c, response.CodeInternalError, err.Error())
+		return
+	}
+
+	response.Success(c, clarifications)
+}
+
+// AnswerClarification 回复答疑（管理员）
+// @Summary 回复答疑
+// @Description 方向负责人回复提问，可选择同时公开
+// @Tags 答疑管理
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "答疑ID"
+// @Param request body service.AnswerClarificationRequest true "回复内容"
+// @Success 200 {object} response.Response{data=model.Clarification} "回复成功"
+// @Failure 400 {object} response.Response "参数错误"
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 403 {object} response.Response "权限不足"
+// @Failure 404 {object} response.Response "答疑不存在"
+// @Router /api/admin/clarifications/{id}/answer [post]
+func (a *ClarificationAPI) AnswerClarification(c *gin.Context) {
+	clarificationID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, response.CodeInvalidParams)
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+
+	var req service.AnswerClarificationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, response.CodeBindError)
+		return
+	}
+
+	clarification, err := a.clarificationService.AnswerClarification(uint(clarificationID), userID.(uint), &req)
+	if err != nil {
+		if err.Error() == "答疑不存在" {
+			response.Error(c, response.CodeClarificationNotFound)
+			return
+		}
+		if err.Error() == "无权限处理该答疑" {
+			response.Error(c, response.CodeForbidden)
+			return
+		}
+		response.ErrorWithMsg(c, response.CodeInternalError, err.Error())
+		return
+	}
+
+	response.Success(c, clarification)
+}
+
+// SetClarificationPublic 设置答疑公开状态（管理员）
+// @Summary 设置答疑公开状态
+// @Description 方向负责人将已回复的答疑公开或撤回为私有
+// @Tags 答疑管理
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "答疑ID"
+// @Param request body service.SetClarificationPublicRequest true "公开状态"
+// @Success 200 {object} response.Response{data=model.Clarification} "设置成功"
+// @Failure 400 {object} response.Response "参数错误"
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 403 {object} response.Response "权限不足"
+// @Failure 404 {object} response.Response "答疑不存在"
+// @Router /api/admin/clarifications/{id}/public [put]
+func (a *ClarificationAPI) SetClarificationPublic(c *gin.Context) {
+	clarificationID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, response.CodeInvalidParams)
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+
+	var req service.SetClarificationPublicRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, response.CodeBindError)
+		return
+	}
+
+	clarification, err := a.clarificationService.SetClarificationPublic(uint(clarificationID), userID.(uint), &req)
+	if err != nil {
+		if err.Error() == "答疑不存在" {
+			response.Error(c, response.CodeClarificationNotFound)
+			return
+		}
+		if err.Error() == "无权限处理该答疑" {
+			response.Error(c, response.CodeForbidden)
+			return
+		}
+		if err.Error() == "答疑尚未回复，无法公开" {
+			response.ErrorWithMsg(c, response.CodeInvalidParams, err.Error())
+			return
+		}
+		response.ErrorWithMsg(c, response.CodeInternalError, err.Error())
+		return
+	}
+
+	response.Success(c, clarification)
+}
@@ -1,10 +1,12 @@
 package api
 
 import (
+	"errors"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/tksky1/glimgate/internal/service"
+	"github.com/tksky1/glimgate/pkg/markdown"
 	"github.com/tksky1/glimgate/pkg/response"
 )
 
@@ -14,15 +16,15 @@ type DirectionAPI struct {
 }
 
 // NewDirectionAPI 创建方向API实例
-func NewDirectionAPI() *DirectionAPI {
+func NewDirectionAPI(directionService *service.DirectionService) *DirectionAPI {
 	return &DirectionAPI{
-		directionService: service.NewDirectionService(),
+		directionService: directionService,
 	}
 }
 
 // CreateDirection 创建方向（管理员）
 // @Summary 创建方向
-// @Description 管理员创建新的方向
+// @Description 管理员创建新的方向，描述为Markdown，包含脚本、事件属性、内嵌框架或危险链接时返回参数错误
 // @Tags 方向管理
 // @Accept json
 // @Produce json
@@ -40,8 +42,12 @@ func (a *DirectionAPI) CreateDirection(c *gin.Context) {
 		return
 	}
 
-	direction, err := a.directionService.CreateDirection(&req)
+	direction, err := a.directionService.CreateDirection(getOperator(c), &req)
 	if err != nil {
+		if errors.Is(err, markdown.ErrUnsafe) {
+			response.ErrorWithMsg(c, response.CodeInvalidParams, err.Error())
+			return
+		}
 		response.ErrorWithMsg(c, response.CodeInternalError, err.Error())
 		return
 	}
@@ -51,7 +57,7 @@ func (a *DirectionAPI) CreateDirection(c *gin.Context) {
 
 // GetDirections 获取方向列表
 // @Summary 获取方向列表
-// @Description 获取所有方向的列表
+// @Description 获取所有方向的列表，description_html为描述渲染并清理后的HTML
 // @Tags 方向管理
 // @Accept json
 // @Produce json
@@ -70,10 +76,11 @@ func (a *DirectionAPI) GetDirections(c *gin.Context) {
 
 // GetDirection 获取方向详情
 // @Summary 获取方向详情
-// @Description 根据ID获取方向的详细信息
+// @Description 根据ID获取方向的详细信息，题目列表只包含已发布和已归档的题目。可选携带token，未解锁的题目locked为true且不返回题面
 // @Tags 方向管理
 // @Accept json
 // @Produce json
+// @Security ApiKeyAuth
 // @Param id path int true "方向ID"
 // @Success 200 {object} response.Response{data=model.Direction} "获取成功"
 // @Failure 400 {object} response.Response "参数错误"
@@ -86,7 +93,7 @@ func (a *DirectionAPI) GetDirection(c *gin.Context) {
 		return
 	}
 
-	direction, err := a.directionService.GetDirectionByID(uint(directionID))
+	direction, err := a.directionService.GetDirectionByID(uint(directionID), optionalUserID(c))
 	if err != nil {
 		if err.Error() == "方向不存在" {
 			response.Error(c, response.CodeDirectionNotFound)
@@ -101,7 +108,7 @@ func (a *DirectionAPI) GetDirection(c *gin.Context) {
 
 // UpdateDirection 更新方向（管理员）
 // @Summary 更新方向
-// @Description 管理员更新方向信息
+// @Description 管理员更新方向信息，描述为Markdown，包含脚本、事件属性、内嵌框架或危险链接时返回参数错误
 // @Tags 方向管理
 // @Accept json
 // @Produce json
@@ -127,12 +134,16 @@ func (a *DirectionAPI) UpdateDirection(c *gin.Context) {
 		return
 	}
 
-	direction, err := a.directionService.UpdateDirection(uint(directionID), &req)
+	direction, err := a.directionService.UpdateDirection(getOperator(c), uint(directionID), &req)
 	if err != nil {
 		if err.Error() == "方向不存在" {
 			response.Error(c, response.CodeDirectionNotFound)
 			return
 		}
+		if errors.Is(err, markdown.ErrUnsafe) {
+			response.ErrorWithMsg(c, response.CodeInvalidParams, err.Error())
+			return
+		}
 		response.ErrorWithMsg(c, response.CodeInternalError, err.Error())
 		return
 	}
@@ -161,7 +172,7 @@ func (a *DirectionAPI) DeleteDirection(c *gin.Context) {
 		return
 	}
 
-	if err := a.directionService.DeleteDirection(uint(directionID)); err != nil {
+	if err := a.directionService.DeleteDirection(getOperator(c), uint(directionID)); err != nil {
 		if err.Error() == "方向不存在" {
 			response.Error(c, response.CodeDirectionNotFound)
 			return
@@ -0,0 +1,384 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tksky1/glimgate/internal/service"
+	"github.com/tksky1/glimgate/pkg/response"
+)
+
+// JudgeAPI 自动评测API处理器
+type JudgeAPI struct {
+	judgeService *service.JudgeService
+}
+
+// NewJudgeAPI 创建自动评测API实例
+func NewJudgeAPI(judgeService *service.JudgeService) *JudgeAPI {
+	return &JudgeAPI{
+		judgeService: judgeService,
+	}
+}
+
+// GetJudgeRun 获取提交的评测记录
+// @Summary 获取提交的评测记录
+// @Description 获取自动评测提交点的提交的评测状态、结果和各测试点的结果，只有提交者本人、所属队伍的成员或管理员可以查看。测试点的输入和标准答案不公开
+// @Tags 自动评测
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "提交ID"
+// @Success 200 {object} response.Response{data=model.JudgeRun} "获取成功"
+// @Failure 400 {object} response.Response "参数错误"
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 403 {object} response.Response "权限不足"
+// @Failure 404 {object} response.Response "提交或评测记录不存在"
+// @Router /api/submissions/{id}/judge [get]
+func (a *JudgeAPI) GetJudgeRun(c *gin.Context) {
+	submissionID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, response.CodeInvalidParams)
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	isAdmin, _ := c.Get("is_admin")
+	run, err := a.judgeService.GetJudgeRun(uint(submissionID), userID.(uint), isAdmin.(bool))
+	if err != nil {
+		switch err.Error() {
+		case "提交不存在":
+			response.Error(c, response.CodeSubmissionNotFound)
+		case "评测记录不存在":
+			response.Error(c, response.CodeJudgeRunNotFound)
+		case "无权限查看该提交":
+			response.Error(c, response.CodeForbidden)
+		default:
+			response.ErrorWithMsg(c, response.CodeInternalError, err.Error())
+		}
+		return
+	}
+
+	response.Success(c, run)
+}
+
+// GetCases 获取提交点的测试点列表（管理员）
+// @Summary 获取测试点列表
+// @Description 管理员获取自动评测提交点的测试点列表，按顺序排列，不包含测试数据
+// @Tags 自动评测
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "提交点ID"
+// @Success 200 {object} response.Response{data=[]model.JudgeCase} "获取成功"
+// @Failure 400 {object} response.Response "参数错误"
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 403 {object} response.Response "权限不足"
+// @Failure 404 {object} response.Response "提交点不存在"
+// @Router /api/admin/submission-points/{id}/judge-cases [get]
+func (a *JudgeAPI) GetCases(c *gin.Context) {
+	pointID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, response.CodeInvalidParams)
+		return
+	}
+
+	cases, err := a.judgeService.GetCases(uint(pointID))
+	if err != nil {
+		respondJudgePointError(c, err)
+		return
+	}
+
+	response.Success(c, cases)
+}
+
+// CreateCase 上传测试点（管理员）
+// @Summary 上传测试点
+// @Description 管理员为自动评测提交点上传测试点的输入和标准答案，大小上限与附件相同。得分按通过测试点的权重占比折算，添加测试点不会重新评测已有的提交
+// @Tags 自动评测
+// @Accept multipart/form-data
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "提交点ID"
+// @Param input formData file true "输入"
+// @Param output formData file true "标准答案"
+// @Param position formData int false "顺序，默认0"
+// @Param weight formData int false "权重，默认1"
+// @Success 200 {object} response.Response{data=model.JudgeCase} "上传成功"
+// @Failure 400 {object} response.Response "参数错误"
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 403 {object} response.Response "权限不足"
+// @Failure 404 {object} response.Response "提交点不存在"
+// @Router /api/admin/submission-points/{id}/judge-cases [post]
+func (a *JudgeAPI) CreateCase(c *gin.Context) {
+	pointID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, response.CodeInvalidParams)
+		return
+	}
+	position, err := strconv.Atoi(c.DefaultPostForm("position", "0"))
+	if err != nil {
+		response.Error(c, response.CodeInvalidParams)
+		return
+	}
+	weight, err := strconv.Atoi(c.DefaultPostForm("weight", "1"))
+	if err != nil {
+		response.Error(c, response.CodeInvalidParams)
+		return
+	}
+
+	input, _, err := c.Request.FormFile("input")
+	if err != nil {
+		response.ErrorWithMsg(c, response.CodeInvalidParams, "请上传输入文件")
+		return
+	}
+	defer input.Close()
+	output, _, err := c.Request.FormFile("output")
+	if err != nil {
+		response.ErrorWithMsg(c, response.CodeInvalidParams, "请上传标准答案文件")
+		return
+	}
+	defer output.Close()
+
+	judgeCase, err := a.judgeService.CreateCase(getOperator(c), uint(pointID), position, weight, input, output)
+	if err != nil {
+		if errors.Is(err, service.ErrJudgeCaseTooLarge) || err.Error() == "测试点权重不能小于1" {
+			response.ErrorWithMsg(c, response.CodeInvalidParams, err.Error())
+			return
+		}
+		respondJudgePointError(c, err)
+		return
+	}
+
+	response.Success(c, judgeCase)
+}
+
+// UpdateCase 修改测试点（管理员）
+// @Summary 修改测试点
+// @Description 管理员修改测试点的顺序和权重，未提供的字段不修改。需要更换测试数据时删除后重新上传
+// @Tags 自动评测
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "测试点ID"
+// @Param request body service.UpdateJudgeCaseRequest true "测试点信息"
+// @Success 200 {object} response.Response{data=model.JudgeCase} "修改成功"
+// @Failure 400 {object} response.Response "参数错误"
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 403 {object} response.Response "权限不足"
+// @Failure 404 {object} response.Response "测试点不存在"
+// @Router /api/admin/judge-cases/{id} [put]
+func (a *JudgeAPI) UpdateCase(c *gin.Context) {
+	caseID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, response.CodeInvalidParams)
+		return
+	}
+
+	var req service.UpdateJudgeCaseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, response.CodeBindError)
+		return
+	}
+
+	judgeCase, err := a.judgeService.UpdateCase(getOperator(c), uint(caseID), &req)
+	if err != nil {
+		if err.Error() == "测试点不存在" {
+			response.Error(c, response.CodeJudgeCaseNotFound)
+			return
+		}
+		response.ErrorWithMsg(c, response.CodeInternalError, err.Error())
+		return
+	}
+
+	response.Success(c, judgeCase)
+}
+
+// DeleteCase 删除测试点（管理员）
+// @Summary 删除测试点
+// @Description 管理员删除测试点及其测试数据，不可恢复，不会重新评测已有的提交
+// @Tags 自动评测
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "测试点ID"
+// @Success 200 {object} response.Response "删除成功"
+// @Failure 400 {object} response.Response "参数错误"
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 403 {object} response.Response "权限不足"
+// @Failure 404 {object} response.Response "测试点不存在"
+// @Router /api/admin/judge-cases/{id} [delete]
+func (a *JudgeAPI) DeleteCase(c *gin.Context) {
+	caseID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, response.CodeInvalidParams)
+		return
+	}
+
+	if err := a.judgeService.DeleteCase(getOperator(c), uint(caseID)); err != nil {
+		if err.Error() == "测试点不存在" {
+			response.Error(c, response.CodeJudgeCaseNotFound)
+			return
+		}
+		response.ErrorWithMsg(c, response.CodeInternalError, err.Error())
+		return
+	}
+
+	response.Success(c, nil)
+}
+
+// DownloadCaseFile 下载测试数据（管理员）
+// @Summary 下载测试数据
+// @Description 管理员下载测试点的输入(input)或标准答案(output)
+// @Tags 自动评测
+// @Produce application/octet-stream
+// @Security ApiKeyAuth
+// @Param id path int true "测试点ID"
+// @Param file path string true "input或output"
+// @Success 200 {file} file "测试数据"
+// @Failure 400 {object} response.Response "参数错误"
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 403 {object} response.Response "权限不足"
+// @Failure 404 {object} response.Response "测试点不存在"
+// @Router /api/admin/judge-cases/{id}/{file} [get]
+func (a *JudgeAPI) DownloadCaseFile(c *gin.Context) {
+	caseID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, response.CodeInvalidParams)
+		return
+	}
+
+	judgeCase, file, err := a.judgeService.OpenCaseFile(uint(caseID), c.Param("file"))
+	if err != nil {
+		switch err.Error() {
+		case "测试点不存在", "测试数据文件不存在":
+			response.ErrorWithMsg(c, response.CodeJudgeCaseNotFound, err.Error())
+		case "文件只能为input或output":
+			response.ErrorWithMsg(c, response.CodeInvalidParams, err.Error())
+		default:
+			response.ErrorWithMsg(c, response.CodeInternalError, err.Error())
+		}
+		return
+	}
+	defer file.Close()
+
+	filename := fmt.Sprintf("%d.%s", judgeCase.ID, map[string]string{"input": "in", "output": "out"}[c.Param("file")])
+	c.Header("Content-Type", "application/octet-stream")
+	c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+	http.ServeContent(c.Writer, c.Request, filename, judgeCase.UpdatedAt, file)
+}
+
+// UpdateChecker 设置检查脚本（管理员）
+// @Summary 设置检查脚本
+// @Description 管理员设置自动评测提交点的检查脚本。检查脚本在选手程序运行后执行，命令末尾追加输入、标准答案和选手输出的文件路径，退出码为0判为通过，其他退出码判为答案错误。checker为空时清除检查脚本，按逐行比较（忽略行尾空白和末尾空行）判定。检查脚本不对选手公开
+// @Tags 自动评测
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "提交点ID"
+// @Param request body service.UpdateCheckerRequest true "检查脚本"
+// @Success 200 {object} response.Response{data=model.SubmissionPoint} "设置成功"
+// @Failure 400 {object} response.Response "参数错误"
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 403 {object} response.Response "权限不足"
+// @Failure 404 {object} response.Response "提交点不存在"
+// @Router /api/admin/submission-points/{id}/checker [put]
+func (a *JudgeAPI) UpdateChecker(c *gin.Context) {
+	pointID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, response.CodeInvalidParams)
+		return
+	}
+
+	var req service.UpdateCheckerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, response.CodeBindError)
+		return
+	}
+
+	point, err := a.judgeService.UpdateChecker(getOperator(c), uint(pointID), &req)
+	if err != nil {
+		if err.Error() == "评测语言未配置" {
+			response.ErrorWithMsg(c, response.CodeInvalidParams, err.Error())
+			return
+		}
+		respondJudgePointError(c, err)
+		return
+	}
+
+	response.Success(c, point)
+}
+
+// RejudgePoint 重新评测提交点的全部提交（管理员）
+// @Summary 重新评测提交点
+// @Description 管理员将自动评测提交点下的全部提交重新放入评测队列，用于修改测试点或检查脚本之后。评测完成后更新系统评分
+// @Tags 自动评测
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "提交点ID"
+// @Success 200 {object} response.Response{data=service.RejudgeResult} "已加入评测队列"
+// @Failure 400 {object} response.Response "参数错误"
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 403 {object} response.Response "权限不足"
+// @Failure 404 {object} response.Response "提交点不存在"
+// @Router /api/admin/submission-points/{id}/rejudge [post]
+func (a *JudgeAPI) RejudgePoint(c *gin.Context) {
+	pointID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, response.CodeInvalidParams)
+		return
+	}
+
+	result, err := a.judgeService.RejudgePoint(uint(pointID))
+	if err != nil {
+		respondJudgePointError(c, err)
+		return
+	}
+
+	response.Success(c, result)
+}
+
+// RejudgeSubmission 重新评测提交（管理员）
+// @Summary 重新评测提交
+// @Description 管理员将自动评测提交点的一个提交重新放入评测队列
+// @Tags 自动评测
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "提交ID"
+// @Success 200 {object} response.Response{data=model.JudgeRun} "已加入评测队列"
+// @Failure 400 {object} response.Response "参数错误"
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 403 {object} response.Response "权限不足"
+// @Failure 404 {object} response.Response "提交不存在"
+// @Router /api/admin/submissions/{id}/rejudge [post]
+func (a *JudgeAPI) RejudgeSubmission(c *gin.Context) {
+	submissionID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, response.CodeInvalidParams)
+		return
+	}
+
+	run, err := a.judgeService.RejudgeSubmission(uint(submissionID))
+	if err != nil {
+		if err.Error() == "提交不存在" {
+			response.Error(c, response.CodeSubmissionNotFound)
+			return
+		}
+		respondJudgePointError(c, err)
+		return
+	}
+
+	response.Success(c, run)
+}
+
+// respondJudgePointError 将自动评测提交点相关的错误转换为响应
+func respondJudgePointError(c *gin.Context, err error) {
+	switch err.Error() {
+	case "提交点不存在":
+		response.ErrorWithMsg(c, response.CodeProblemNotFound, err.Error())
+	case "提交点不是自动评测类型":
+		response.ErrorWithMsg(c, response.CodeInvalidParams, err.Error())
+	default:
+		response.ErrorWithMsg(c, response.CodeInternalError, err.Error())
+	}
+}
@@ -0,0 +1,197 @@
+package api
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tksky1/glimgate/internal/service"
+	"github.com/tksky1/glimgate/pkg/response"
+)
+
+// NotificationAPI 通知API处理器
+type NotificationAPI struct {
+	notificationService *service.NotificationService
+}
+
+// NewNotificationAPI 创建通知API实例
+func NewNotificationAPI(notificationService *service.NotificationService) *NotificationAPI {
+	return &NotificationAPI{
+		notificationService: notificationService,
+	}
+}
+
+// GetNotifications 获取我的通知列表
+// @Summary 获取我的通知列表
+// @Description 获取当前用户的站内通知，按时间倒序
+// @Tags 通知管理
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param unread_only query bool false "仅未读"
+// @Param page query int false "页码" default(1)
+// @Param page_size query int false "每页数量" default(10)
+// @Success 200 {object} response.Response{data=map[string]interface{}} "获取成功"
+// @Failure 401 {object} response.Response "未授权"
+// @Router /api/notifications [get]
+func (a *NotificationAPI) GetNotifications(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	unreadOnly, _ := strconv.ParseBool(c.DefaultQuery("unread_only", "false"))
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
+
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 10
+	}
+
+	notifications, total, err := a.notificationService.GetNotifications(userID.(uint), unreadOnly, page, pageSize)
+	if err != nil {
+		response.ErrorWithMsg(c, response.CodeInternalError, err.Error())
+		return
+	}
+
+	data := map[string]interface{}{
+		"notifications": notifications,
+		"total":         total,
+		"page":          page,
+		"page_size":     pageSize,
+	}
+
+	response.Success(c, data)
+}
+
+// GetUnreadCount 获取未读通知数
+// @Summary 获取未读通知数
+// @Description 获取当前用户的未读通知数量
+// @Tags 通知管理
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} response.Response{data=map[string]int64} "获取成功"
+// @Failure 401 {object} response.Response "未授权"
+// @Router /api/notifications/unread-count [get]
+func (a *NotificationAPI) GetUnreadCount(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	count, err := a.notificationService.GetUnreadCount(userID.(uint))
+	if err != nil {
+		response.ErrorWithMsg(c, response.CodeInternalError, err.Error())
+		return
+	}
+
+	response.Success(c, map[string]int64{"unread_count": count})
+}
+
+// MarkRead 标记通知为已读
+// @Summary 标记通知为已读
+// @Description 将指定通知标记为已读
+// @Tags 通知管理
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "通知ID"
+// @Success 200 {object} response.Response "标记成功"
+// @Failure 400 {object} response.Response "参数错误"
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 404 {object} response.Response "通知不存在"
+// @Router /api/notifications/{id}/read [put]
+func (a *NotificationAPI) MarkRead(c *gin.Context) {
+	notificationID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, response.CodeInvalidParams)
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+
+	if err := a.notificationService.MarkRead(uint(notificationID), userID.(uint)); err != nil {
+		if err.Error() == "通知不存在" {
+			response.Error(c, response.CodeNotificationNotFound)
+			return
+		}
+		response.ErrorWithMsg(c, response.CodeInternalError, err.Error())
+		return
+	}
+
+	response.Success(c, nil)
+}
+
+// MarkAllRead 全部标记为已读
+// @Summary 全部标记为已读
+// @Description 将当前用户的全部通知标记为已读
+// @Tags 通知管理
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} response.Response "标记成功"
+// @Failure 401 {object} response.Response "未授权"
+// @Router /api/notifications/read-all [put]
+func (a *NotificationAPI) MarkAllRead(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	if err := a.notificationService.MarkAllRead(userID.(uint)); err != nil {
+		response.ErrorWithMsg(c, response.CodeInternalError, err.Error())
+		return
+	}
+
+	response.Success(c, nil)
+}
+
+// GetSetting 获取通知设置
+// @Summary 获取通知设置
+// @Description 获取当前用户的通知渠道设置及系统可用渠道
+// @Tags 通知管理
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} response.Response{data=service.NotificationSettingResponse} "获取成功"
+// @Failure 401 {object} response.Response "未授权"
+// @Router /api/notifications/settings [get]
+func (a *NotificationAPI) GetSetting(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	setting, err := a.notificationService.GetSetting(userID.(uint))
+	if err != nil {
+		response.ErrorWithMsg(c, response.CodeInternalError, err.Error())
+		return
+	}
+
+	response.Success(c, setting)
+}
+
+// UpdateSetting 更新通知设置
+// @Summary 更新通知设置
+// @Description 选择需要接收通知的外部渠道。Webhook地址须为http或https，默认不能指向回环、私有网段等内网地址
+// @Tags 通知管理
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param request body service.UpdateNotificationSettingRequest true "通知设置"
+// @Success 200 {object} response.Response{data=service.NotificationSettingResponse} "更新成功"
+// @Failure 400 {object} response.Response "参数错误"
+// @Failure 401 {object} response.Response "未授权"
+// @Router /api/notifications/settings [put]
+func (a *NotificationAPI) UpdateSetting(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	var req service.UpdateNotificationSettingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, response.CodeBindError)
+		return
+	}
+
+	setting, err := a.notificationService.UpdateSetting(userID.(uint), &req)
+	if err != nil {
+		switch err.Error() {
+		case "开启Webhook通知需要设置Webhook地址", "Webhook地址格式错误", "Webhook地址不能指向内网地址", "无法解析Webhook地址的域名":
+			response.ErrorWithMsg(c, response.CodeInvalidParams, err.Error())
+			return
+		}
+		response.ErrorWithMsg(c, response.CodeInternalError, err.Error())
+		return
+	}
+
+	response.Success(c, setting)
+}
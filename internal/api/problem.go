@@ -1,13 +1,21 @@
 package api
 
 import (
+	"errors"
+	"fmt"
+	"io"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/tksky1/glimgate/internal/service"
+	"github.com/tksky1/glimgate/pkg/bundle"
+	"github.com/tksky1/glimgate/pkg/markdown"
 	"github.com/tksky1/glimgate/pkg/response"
 )
 
+// maxBundleFileSize 上传题目包的最大字节数，题目包中可能带有附件
+const maxBundleFileSize = 100 << 20
+
 // ProblemAPI 题目API处理器
 type ProblemAPI struct {
 	problemService   *service.ProblemService
@@ -15,16 +23,16 @@ type ProblemAPI struct {
 }
 
 // NewProblemAPI 创建题目API实例
-func NewProblemAPI() *ProblemAPI {
+func NewProblemAPI(problemService *service.ProblemService, directionService *service.DirectionService) *ProblemAPI {
 	return &ProblemAPI{
-		problemService:   service.NewProblemService(),
-		directionService: service.NewDirectionService(),
+		problemService:   problemService,
+		directionService: directionService,
 	}
 }
 
 // CreateProblem 创建题目（管理员）
 // @Summary 创建题目
-// @Description 管理员创建新的题目
+// @Description 管理员创建新的题目，未指定status时创建为草稿，发布前选手不可见；status为scheduled时需指定publish_at，到期自动发布。可设置难度等级(1-5)、预计用时和标签，team_size为2-20时为组队题目。题面为Markdown，包含脚本、事件属性、内嵌框架或危险链接时返回参数错误
 // @Tags 题目管理
 // @Accept json
 // @Produce json
@@ -58,12 +66,24 @@ func (a *ProblemAPI) CreateProblem(c *gin.Context) {
 		}
 	}
 
-	problem, err := a.problemService.CreateProblem(&req)
+	problem, err := a.problemService.CreateProblem(getOperator(c), &req)
 	if err != nil {
 		if err.Error() == "方向不存在" {
 			response.Error(c, response.CodeDirectionNotFound)
 			return
 		}
+		if errors.Is(err, markdown.ErrUnsafe) {
+			response.ErrorWithMsg(c, response.CodeInvalidParams, err.Error())
+			return
+		}
+		switch err.Error() {
+		case "题目标识格式错误", "题目标识已被使用", "题目状态无效", "只有已发布的题目可以归档",
+			"定时发布需要指定发布时间", "定时发布时间必须晚于当前时间",
+			"难度等级应为1到5", "预计用时不能为负数", "标签不能包含逗号或超过50个字符", "标签不能超过20个",
+			"队伍人数上限应为2到20，0表示个人题目":
+			response.ErrorWithMsg(c, response.CodeInvalidParams, err.Error())
+			return
+		}
 		response.ErrorWithMsg(c, response.CodeInternalError, err.Error())
 		return
 	}
@@ -73,19 +93,26 @@ func (a *ProblemAPI) CreateProblem(c *gin.Context) {
 
 // GetProblems 获取题目列表
 // @Summary 获取题目列表
-// @Description 获取题目列表，可按方向筛选
+// @Description 获取对选手可见的题目列表，可按方向筛选。默认只返回已发布的题目，status=archived时返回已归档的题目。可选携带token，未解锁的题目locked为true且不返回题面
 // @Tags 题目管理
 // @Accept json
 // @Produce json
+// @Security ApiKeyAuth
 // @Param direction_id query int false "方向ID"
+// @Param status query string false "可见状态(published/archived)，默认published"
 // @Success 200 {object} response.Response{data=[]model.Problem} "获取成功"
+// @Failure 400 {object} response.Response "参数错误"
 // @Failure 500 {object} response.Response "内部错误"
 // @Router /api/problems [get]
 func (a *ProblemAPI) GetProblems(c *gin.Context) {
 	directionID, _ := strconv.ParseUint(c.DefaultQuery("direction_id", "0"), 10, 32)
 
-	problems, err := a.problemService.GetProblems(uint(directionID))
+	problems, err := a.problemService.GetVisibleProblems(uint(directionID), c.Query("status"), optionalUserID(c))
 	if err != nil {
+		if err.Error() == "题目状态无效" {
+			response.ErrorWithMsg(c, response.CodeInvalidParams, err.Error())
+			return
+		}
 		response.ErrorWithMsg(c, response.CodeInternalError, err.Error())
 		return
 	}
@@ -95,10 +122,11 @@ func (a *ProblemAPI) GetProblems(c *gin.Context) {
 
 // GetProblem 获取题目详情
 // @Summary 获取题目详情
-// @Description 根据ID获取题目的详细信息
+// @Description 根据ID获取题目的详细信息，草稿和定时发布中的题目返回题目不存在。description_html为题面渲染并清理后的HTML，attachments/下的相对链接指向题目附件。可选携带token，未满足解锁条件时locked为true，只返回标题和解锁条件
 // @Tags 题目管理
 // @Accept json
 // @Produce json
+// @Security ApiKeyAuth
 // @Param id path int true "题目ID"
 // @Success 200 {object} response.Response{data=model.Problem} "获取成功"
 // @Failure 400 {object} response.Response "参数错误"
@@ -111,7 +139,7 @@ func (a *ProblemAPI) GetProblem(c *gin.Context) {
 		return
 	}
 
-	problem, err := a.problemService.GetProblemByID(uint(problemID))
+	problem, err := a.problemService.GetVisibleProblem(uint(problemID), optionalUserID(c))
 	if err != nil {
 		if err.Error() == "题目不存在" {
 			response.Error(c, response.CodeProblemNotFound)
@@ -126,7 +154,7 @@ func (a *ProblemAPI) GetProblem(c *gin.Context) {
 
 // UpdateProblem 更新题目（管理员）
 // @Summary 更新题目
-// @Description 管理员更新题目信息
+// @Description 管理员更新题目信息，未传的字段不修改，tags传空列表时清除全部标签。team_size修改队伍人数上限，已有提交后不能在个人题目和组队题目之间切换，改为0时解散全部队伍。题面为Markdown，包含脚本、事件属性、内嵌框架或危险链接时返回参数错误。标题或题面有变化时记录新版本；significant为true时标记为重要修改，已发布或已归档的题目会通知已提交的选手，并在其题目详情中显示statement_updated
 // @Tags 题目管理
 // @Accept json
 // @Produce json
@@ -179,12 +207,24 @@ func (a *ProblemAPI) UpdateProblem(c *gin.Context) {
 		return
 	}
 
-	problem, err := a.problemService.UpdateProblem(uint(problemID), &req)
+	problem, err := a.problemService.UpdateProblem(getOperator(c), uint(problemID), &req)
 	if err != nil {
 		if err.Error() == "题目不存在" {
 			response.Error(c, response.CodeProblemNotFound)
 			return
 		}
+		if errors.Is(err, markdown.ErrUnsafe) {
+			response.ErrorWithMsg(c, response.CodeInvalidParams, err.Error())
+			return
+		}
+		switch err.Error() {
+		case "题目标识格式错误", "题目标识已被使用",
+			"难度等级应为1到5", "预计用时不能为负数", "标签不能包含逗号或超过50个字符", "标签不能超过20个",
+			"修改说明不能超过500个字符", "队伍人数上限应为2到20，0表示个人题目",
+			"题目已有提交，不能在个人题目和组队题目之间切换", "已有队伍人数超过新的人数上限":
+			response.ErrorWithMsg(c, response.CodeInvalidParams, err.Error())
+			return
+		}
 		response.ErrorWithMsg(c, response.CodeInternalError, err.Error())
 		return
 	}
@@ -240,7 +280,7 @@ func (a *ProblemAPI) DeleteProblem(c *gin.Context) {
 		}
 	}
 
-	if err := a.problemService.DeleteProblem(uint(problemID)); err != nil {
+	if err := a.problemService.DeleteProblem(getOperator(c), uint(problemID)); err != nil {
 		if err.Error() == "题目不存在" {
 			response.Error(c, response.CodeProblemNotFound)
 			return
@@ -254,7 +294,7 @@ func (a *ProblemAPI) DeleteProblem(c *gin.Context) {
 
 // CreateSubmissionPoint 创建提交点（管理员）
 // @Summary 创建提交点
-// @Description 管理员为题目创建提交点
+// @Description 管理员为题目创建提交点。type为auto时为自动评测提交点，提交内容为源代码，judge_language须为配置的评测语言
 // @Tags 题目管理
 // @Accept json
 // @Produce json
@@ -307,12 +347,16 @@ func (a *ProblemAPI) CreateSubmissionPoint(c *gin.Context) {
 		return
 	}
 
-	submissionPoint, err := a.problemService.CreateSubmissionPoint(uint(problemID), &req)
+	submissionPoint, err := a.problemService.CreateSubmissionPoint(getOperator(c), uint(problemID), &req)
 	if err != nil {
 		if err.Error() == "题目不存在" {
 			response.Error(c, response.CodeProblemNotFound)
 			return
 		}
+		if err.Error() == "提交点类型只能为manual、auto或git" || err.Error() == "评测语言未配置" {
+			response.ErrorWithMsg(c, response.CodeInvalidParams, err.Error())
+			return
+		}
 		response.ErrorWithMsg(c, response.CodeInternalError, err.Error())
 		return
 	}
@@ -322,13 +366,16 @@ func (a *ProblemAPI) CreateSubmissionPoint(c *gin.Context) {
 
 // GetSubmissionPoints 获取提交点列表
 // @Summary 获取提交点列表
-// @Description 获取指定题目的提交点列表
+// @Description 获取指定题目的提交点列表，题目未发布时返回题目不存在。可选携带token，未满足解锁条件时返回题目尚未解锁
 // @Tags 题目管理
 // @Accept json
 // @Produce json
+// @Security ApiKeyAuth
 // @Param id path int true "题目ID"
 // @Success 200 {object} response.Response{data=[]model.SubmissionPoint} "获取成功"
 // @Failure 400 {object} response.Response "参数错误"
+// @Failure 403 {object} response.Response "题目尚未解锁"
+// @Failure 404 {object} response.Response "题目不存在"
 // @Failure 500 {object} response.Response "内部错误"
 // @Router /api/problems/{id}/submission-points [get]
 func (a *ProblemAPI) GetSubmissionPoints(c *gin.Context) {
@@ -338,8 +385,16 @@ func (a *ProblemAPI) GetSubmissionPoints(c *gin.Context) {
 		return
 	}
 
-	submissionPoints, err := a.problemService.GetSubmissionPoints(uint(problemID))
+	submissionPoints, err := a.problemService.GetSubmissionPoints(uint(problemID), optionalUserID(c))
 	if err != nil {
+		if err.Error() == "题目不存在" {
+			response.Error(c, response.CodeProblemNotFound)
+			return
+		}
+		if err.Error() == "题目尚未解锁" {
+			response.ErrorWithMsg(c, response.CodeForbidden, err.Error())
+			return
+		}
 		response.ErrorWithMsg(c, response.CodeInternalError, err.Error())
 		return
 	}
@@ -349,7 +404,7 @@ func (a *ProblemAPI) GetSubmissionPoints(c *gin.Context) {
 
 // UpdateSubmissionPoint 更新提交点（管理员）
 // @Summary 更新提交点
-// @Description 管理员更新提交点信息
+// @Description 管理员更新提交点信息，设置有变化时记录题目的新版本；significant为true时标记为重要修改并通知已提交的选手。改为自动评测后已有的提交不会自动评测，需要重新评测
 // @Tags 题目管理
 // @Accept json
 // @Produce json
@@ -375,9 +430,14 @@ func (a *ProblemAPI) UpdateSubmissionPoint(c *gin.Context) {
 		return
 	}
 
-	submissionPoint, err := a.problemService.UpdateSubmissionPoint(uint(submissionPointID), &req)
+	submissionPoint, err := a.problemService.UpdateSubmissionPoint(getOperator(c), uint(submissionPointID), &req)
 	if err != nil {
-		response.ErrorWithMsg(c, response.CodeInternalError, err.Error())
+		switch err.Error() {
+		case "修改说明不能超过500个字符", "提交点类型只能为manual、auto或git", "评测语言未配置":
+			response.ErrorWithMsg(c, response.CodeInvalidParams, err.Error())
+		default:
+			response.ErrorWithMsg(c, response.CodeInternalError, err.Error())
+		}
 		return
 	}
 
@@ -404,10 +464,105 @@ func (a *ProblemAPI) DeleteSubmissionPoint(c *gin.Context) {
 		return
 	}
 
-	if err := a.problemService.DeleteSubmissionPoint(uint(submissionPointID)); err != nil {
+	if err := a.problemService.DeleteSubmissionPoint(getOperator(c), uint(submissionPointID)); err != nil {
 		response.ErrorWithMsg(c, response.CodeInternalError, err.Error())
 		return
 	}
 
 	response.Success(c, nil)
-}
\ No newline at end of file
+}
+
+// ImportProblemBundle 导入题目包（管理员）
+// @Summary 导入题目包
+// @Description 管理员上传题目包（包含problem.yaml的ZIP压缩包，或单独的problem.yaml）创建或更新题目。按方向名称和slug匹配已有题目，只应用有差异的字段、提交点和附件，重复导入同一题目包不会产生变更；题目包中没有的提交点会被删除，已有提交的提交点不能删除；清单填写attachments时按文件名和SHA-256同步attachments目录中的附件。
+// @Tags 题目管理
+// @Accept multipart/form-data
+// @Produce json
+// @Security ApiKeyAuth
+// @Param file formData file true "题目包(.zip/.yaml/.yml)"
+// @Param dry_run query bool false "仅计算差异不导入"
+// @Success 200 {object} response.Response{data=service.ImportBundleResult} "导入结果及差异"
+// @Failure 400 {object} response.Response "参数错误"
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 403 {object} response.Response "权限不足"
+// @Failure 404 {object} response.Response "方向不存在"
+// @Router /api/admin/problems/import [post]
+func (a *ProblemAPI) ImportProblemBundle(c *gin.Context) {
+	file, header, err := c.Request.FormFile("file")
+	if err != nil {
+		response.ErrorWithMsg(c, response.CodeInvalidParams, "请上传题目包")
+		return
+	}
+	defer file.Close()
+
+	if header.Size > maxBundleFileSize {
+		response.ErrorWithMsg(c, response.CodeInvalidParams, "题目包不能超过100MB")
+		return
+	}
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		response.ErrorWithMsg(c, response.CodeInternalError, err.Error())
+		return
+	}
+
+	b, err := bundle.ReadFile(header.Filename, data)
+	if err != nil {
+		response.ErrorWithMsg(c, response.CodeInvalidParams, err.Error())
+		return
+	}
+
+	dryRun, _ := strconv.ParseBool(c.DefaultQuery("dry_run", "false"))
+	result, err := a.problemService.ImportBundle(getOperator(c), b, dryRun)
+	if err != nil {
+		if err.Error() == "方向不存在" {
+			response.Error(c, response.CodeDirectionNotFound)
+			return
+		}
+		if errors.Is(err, bundle.ErrInvalid) || errors.Is(err, service.ErrBundleConflict) || errors.Is(err, service.ErrAttachmentTooLarge) {
+			response.ErrorWithMsg(c, response.CodeInvalidParams, err.Error())
+			return
+		}
+		response.ErrorWithMsg(c, response.CodeInternalError, err.Error())
+		return
+	}
+
+	response.Success(c, result)
+}
+
+// ExportProblemBundle 导出题目包（管理员）
+// @Summary 导出题目包
+// @Description 管理员将题目及其提交点、附件导出为ZIP格式的题目包，根目录为problem.yaml，附件位于attachments目录，可直接重新导入
+// @Tags 题目管理
+// @Produce application/zip
+// @Security ApiKeyAuth
+// @Param id path int true "题目ID"
+// @Success 200 {file} file "题目包"
+// @Failure 400 {object} response.Response "参数错误"
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 403 {object} response.Response "权限不足"
+// @Failure 404 {object} response.Response "题目不存在"
+// @Router /api/admin/problems/{id}/export [get]
+func (a *ProblemAPI) ExportProblemBundle(c *gin.Context) {
+	problemID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, response.CodeInvalidParams)
+		return
+	}
+
+	b, err := a.problemService.ExportBundle(uint(problemID))
+	if err != nil {
+		if err.Error() == "题目不存在" {
+			response.Error(c, response.CodeProblemNotFound)
+			return
+		}
+		response.ErrorWithMsg(c, response.CodeInternalError, err.Error())
+		return
+	}
+
+	c.Header("Content-Type", "application/zip")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", b.Manifest.Slug+".zip"))
+	if err := bundle.WriteZip(c.Writer, b); err != nil {
+		c.Error(err)
+	}
+}
@@ -0,0 +1,281 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tksky1/glimgate/internal/model"
+	"github.com/tksky1/glimgate/internal/service"
+	"github.com/tksky1/glimgate/pkg/response"
+	"github.com/tksky1/glimgate/pkg/storage"
+)
+
+// UploadAttachment 上传题目附件（管理员）
+// @Summary 上传题目附件
+// @Description 管理员为题目上传附件（初始代码、数据集、设计稿等），上传时计算SHA-256校验和，同一题目下文件名不能重复，大小上限由storage.max_upload_mb配置
+// @Tags 题目附件
+// @Accept multipart/form-data
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "题目ID"
+// @Param file formData file true "附件"
+// @Success 200 {object} response.Response{data=model.ProblemAttachment} "上传成功"
+// @Failure 400 {object} response.Response "参数错误"
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 403 {object} response.Response "权限不足"
+// @Failure 404 {object} response.Response "题目不存在"
+// @Router /api/admin/problems/{id}/attachments [post]
+func (a *ProblemAPI) UploadAttachment(c *gin.Context) {
+	problemID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, response.CodeInvalidParams)
+		return
+	}
+
+	file, header, err := c.Request.FormFile("file")
+	if err != nil {
+		response.ErrorWithMsg(c, response.CodeInvalidParams, "请上传附件")
+		return
+	}
+	defer file.Close()
+
+	attachment, err := a.problemService.UploadAttachment(getOperator(c), uint(problemID), header.Filename, header.Header.Get("Content-Type"), file)
+	if err != nil {
+		if err.Error() == "题目不存在" {
+			response.Error(c, response.CodeProblemNotFound)
+			return
+		}
+		switch {
+		case errors.Is(err, service.ErrAttachmentTooLarge),
+			err.Error() == "该题目已有同名附件",
+			err.Error() == "附件文件名无效",
+			err.Error() == "附件文件名不能超过255个字符":
+			response.ErrorWithMsg(c, response.CodeInvalidParams, err.Error())
+		default:
+			response.ErrorWithMsg(c, response.CodeInternalError, err.Error())
+		}
+		return
+	}
+
+	response.Success(c, attachment)
+}
+
+// GetAttachments 获取题目附件列表
+// @Summary 获取题目附件列表
+// @Description 获取题目的附件列表，按文件名排序，包含大小和SHA-256校验和。题目未发布时返回题目不存在，可选携带token，未满足解锁条件时返回题目尚未解锁
+// @Tags 题目附件
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "题目ID"
+// @Success 200 {object} response.Response{data=[]model.ProblemAttachment} "获取成功"
+// @Failure 400 {object} response.Response "参数错误"
+// @Failure 403 {object} response.Response "题目尚未解锁"
+// @Failure 404 {object} response.Response "题目不存在"
+// @Router /api/problems/{id}/attachments [get]
+func (a *ProblemAPI) GetAttachments(c *gin.Context) {
+	problemID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, response.CodeInvalidParams)
+		return
+	}
+
+	attachments, err := a.problemService.GetAttachments(uint(problemID), optionalUserID(c))
+	if err != nil {
+		if err.Error() == "题目不存在" {
+			response.Error(c, response.CodeProblemNotFound)
+			return
+		}
+		if err.Error() == "题目尚未解锁" {
+			response.ErrorWithMsg(c, response.CodeForbidden, err.Error())
+			return
+		}
+		response.ErrorWithMsg(c, response.CodeInternalError, err.Error())
+		return
+	}
+
+	response.Success(c, attachments)
+}
+
+// DeleteAttachment 删除题目附件（管理员）
+// @Summary 删除题目附件
+// @Description 管理员删除题目附件，删除后可从回收站恢复，回收站清理时文件一并删除
+// @Tags 题目附件
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "附件ID"
+// @Success 200 {object} response.Response "删除成功"
+// @Failure 400 {object} response.Response "参数错误"
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 403 {object} response.Response "权限不足"
+// @Failure 404 {object} response.Response "附件不存在"
+// @Router /api/admin/problem-attachments/{id} [delete]
+func (a *ProblemAPI) DeleteAttachment(c *gin.Context) {
+	attachmentID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, response.CodeInvalidParams)
+		return
+	}
+
+	if err := a.problemService.DeleteAttachment(getOperator(c), uint(attachmentID)); err != nil {
+		if err.Error() == "附件不存在" {
+			response.Error(c, response.CodeAttachmentNotFound)
+			return
+		}
+		response.ErrorWithMsg(c, response.CodeInternalError, err.Error())
+		return
+	}
+
+	response.Success(c, nil)
+}
+
+// DownloadAttachment 下载题目附件
+// @Summary 下载题目附件
+// @Description 下载题目附件，所属题目已删除或未发布时无法下载。可选携带token，未满足解锁条件时返回题目尚未解锁。携带签名下载链接中的expires和signature参数时按签名校验，链接过期或签名错误返回403，签名链接只能在题目解锁后生成，下载时不再检查解锁条件；管理员为未发布题目生成的预览链接带有scope=preview，其余签名链接在题目下线或删除后失效，预览链接在题目删除后失效。响应头X-Checksum-SHA256和ETag为文件的SHA-256校验和，支持Range断点续传
+// @Tags 题目附件
+// @Produce application/octet-stream
+// @Security ApiKeyAuth
+// @Param id path int true "附件ID"
+// @Param expires query int false "签名链接过期时间(Unix时间戳)"
+// @Param scope query string false "签名链接的用途，预览链接为preview"
+// @Param signature query string false "签名"
+// @Success 200 {file} file "附件内容"
+// @Failure 400 {object} response.Response "参数错误"
+// @Failure 403 {object} response.Response "下载链接已过期、签名无效或题目尚未解锁"
+// @Failure 404 {object} response.Response "附件不存在"
+// @Router /api/attachments/{id}/download [get]
+func (a *ProblemAPI) DownloadAttachment(c *gin.Context) {
+	attachmentID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, response.CodeInvalidParams)
+		return
+	}
+
+	// 携带签名参数时必须通过校验
+	scope := ""
+	if c.Query("signature") != "" || c.Query("expires") != "" {
+		expires, err := strconv.ParseInt(c.Query("expires"), 10, 64)
+		if err != nil {
+			response.Error(c, response.CodeInvalidParams)
+			return
+		}
+		scope, err = a.problemService.VerifyAttachmentSignature(uint(attachmentID), expires, c.Query("scope"), c.Query("signature"))
+		if err != nil {
+			c.JSON(http.StatusForbidden, response.Response{Code: response.CodeForbidden, Msg: err.Error()})
+			return
+		}
+	}
+
+	attachment, file, err := a.problemService.OpenAttachment(uint(attachmentID), optionalUserID(c), scope)
+	if err != nil {
+		switch err.Error() {
+		case "附件不存在", "附件文件不存在":
+			response.ErrorWithMsg(c, response.CodeAttachmentNotFound, err.Error())
+		case "题目尚未解锁":
+			response.ErrorWithMsg(c, response.CodeForbidden, err.Error())
+		default:
+			response.ErrorWithMsg(c, response.CodeInternalError, err.Error())
+		}
+		return
+	}
+	defer file.Close()
+
+	serveAttachment(c, attachment, file)
+}
+
+// DownloadAttachmentByName 按文件名下载题目附件
+// @Summary 按文件名下载题目附件
+// @Description 按文件名下载已发布题目的附件，题面中attachments/<文件名>形式的相对链接渲染后指向此地址，附件被替换后链接依然有效。可选携带token，未满足解锁条件时返回题目尚未解锁。响应头与按ID下载相同
+// @Tags 题目附件
+// @Produce application/octet-stream
+// @Security ApiKeyAuth
+// @Param id path int true "题目ID"
+// @Param filename path string true "附件文件名"
+// @Success 200 {file} file "附件内容"
+// @Failure 400 {object} response.Response "参数错误"
+// @Failure 403 {object} response.Response "题目尚未解锁"
+// @Failure 404 {object} response.Response "题目或附件不存在"
+// @Router /api/problems/{id}/attachments/{filename} [get]
+func (a *ProblemAPI) DownloadAttachmentByName(c *gin.Context) {
+	problemID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, response.CodeInvalidParams)
+		return
+	}
+
+	attachment, file, err := a.problemService.OpenAttachmentByName(uint(problemID), optionalUserID(c), c.Param("filename"))
+	if err != nil {
+		switch err.Error() {
+		case "题目不存在":
+			response.Error(c, response.CodeProblemNotFound)
+		case "附件不存在", "附件文件不存在":
+			response.ErrorWithMsg(c, response.CodeAttachmentNotFound, err.Error())
+		case "题目尚未解锁":
+			response.ErrorWithMsg(c, response.CodeForbidden, err.Error())
+		default:
+			response.ErrorWithMsg(c, response.CodeInternalError, err.Error())
+		}
+		return
+	}
+	defer file.Close()
+
+	serveAttachment(c, attachment, file)
+}
+
+// serveAttachment 输出附件内容，带上原文件名和SHA-256校验和，支持Range和条件请求
+func serveAttachment(c *gin.Context, attachment *model.ProblemAttachment, file storage.File) {
+	c.Header("Content-Type", attachment.ContentType)
+	c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Filename}))
+	c.Header("ETag", fmt.Sprintf("%q", attachment.SHA256))
+	c.Header("X-Checksum-SHA256", attachment.SHA256)
+	http.ServeContent(c.Writer, c.Request, attachment.Filename, attachment.UpdatedAt, file)
+}
+
+// SignAttachmentURL 生成附件的签名下载链接
+// @Summary 生成签名下载链接
+// @Description 为可见的附件生成带签名和过期时间的下载链接，持有链接即可下载，无需登录。有效期不超过storage.signed_url_ttl_minutes配置。未满足解锁条件时返回题目尚未解锁。管理员可为未发布或未解锁题目的附件生成链接用于预览，未发布题目的链接只用于预览，题目删除后失效
+// @Tags 题目附件
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "附件ID"
+// @Param expires_in query int false "有效期(秒)，默认及上限为配置的最长有效期"
+// @Success 200 {object} response.Response{data=service.SignedURL} "生成成功"
+// @Failure 400 {object} response.Response "参数错误"
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 403 {object} response.Response "题目尚未解锁"
+// @Failure 404 {object} response.Response "附件不存在"
+// @Router /api/attachments/{id}/signed-url [post]
+func (a *ProblemAPI) SignAttachmentURL(c *gin.Context) {
+	attachmentID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, response.CodeInvalidParams)
+		return
+	}
+
+	expiresIn, err := strconv.Atoi(c.DefaultQuery("expires_in", "0"))
+	if err != nil || expiresIn < 0 {
+		response.Error(c, response.CodeInvalidParams)
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	isAdmin, _ := c.Get("is_admin")
+	signedURL, err := a.problemService.SignAttachmentURL(uint(attachmentID), userID.(uint), time.Duration(expiresIn)*time.Second, isAdmin.(bool))
+	if err != nil {
+		switch err.Error() {
+		case "附件不存在":
+			response.Error(c, response.CodeAttachmentNotFound)
+		case "题目尚未解锁":
+			response.ErrorWithMsg(c, response.CodeForbidden, err.Error())
+		default:
+			response.ErrorWithMsg(c, response.CodeInternalError, err.Error())
+		}
+		return
+	}
+
+	response.Success(c, signedURL)
+}
@@ -0,0 +1,248 @@
+package api
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tksky1/glimgate/internal/service"
+	"github.com/tksky1/glimgate/pkg/markdown"
+	"github.com/tksky1/glimgate/pkg/response"
+)
+
+// GetHints 获取题目提示列表
+// @Summary 获取题目提示列表
+// @Description 获取题目的提示列表，按解锁顺序排列。已解锁的提示返回内容，未解锁的提示只返回顺序和惩罚分
+// @Tags 题目提示
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "题目ID"
+// @Success 200 {object} response.Response{data=[]model.ProblemHint} "获取成功"
+// @Failure 400 {object} response.Response "参数错误"
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 403 {object} response.Response "题目尚未解锁"
+// @Failure 404 {object} response.Response "题目不存在"
+// @Router /api/problems/{id}/hints [get]
+func (a *ProblemAPI) GetHints(c *gin.Context) {
+	problemID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, response.CodeInvalidParams)
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	hints, err := a.problemService.GetHints(uint(problemID), userID.(uint))
+	if err != nil {
+		switch err.Error() {
+		case "题目不存在":
+			response.Error(c, response.CodeProblemNotFound)
+		case "题目尚未解锁":
+			response.ErrorWithMsg(c, response.CodeForbidden, err.Error())
+		default:
+			response.ErrorWithMsg(c, response.CodeInternalError, err.Error())
+		}
+		return
+	}
+
+	response.Success(c, hints)
+}
+
+// UnlockHint 解锁题目提示
+// @Summary 解锁题目提示
+// @Description 解锁题目的提示并返回内容，解锁会被记录，提示的惩罚分从该题得分中扣除（扣至0为止），排行榜和评分汇总同步生效。需按顺序解锁，重复解锁不会重复扣分；已归档的题目不能再解锁提示
+// @Tags 题目提示
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "题目ID"
+// @Param hint_id path int true "提示ID"
+// @Success 200 {object} response.Response{data=model.ProblemHint} "解锁成功"
+// @Failure 400 {object} response.Response "参数错误"
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 403 {object} response.Response "题目尚未解锁或已归档"
+// @Failure 404 {object} response.Response "题目或提示不存在"
+// @Router /api/problems/{id}/hints/{hint_id}/unlock [post]
+func (a *ProblemAPI) UnlockHint(c *gin.Context) {
+	problemID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, response.CodeInvalidParams)
+		return
+	}
+	hintID, err := strconv.ParseUint(c.Param("hint_id"), 10, 32)
+	if err != nil {
+		response.Error(c, response.CodeInvalidParams)
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	hint, err := a.problemService.UnlockHint(userID.(uint), uint(problemID), uint(hintID))
+	if err != nil {
+		switch err.Error() {
+		case "题目不存在":
+			response.Error(c, response.CodeProblemNotFound)
+		case "提示不存在":
+			response.Error(c, response.CodeHintNotFound)
+		case "题目尚未解锁", "题目已归档，不能再解锁提示":
+			response.ErrorWithMsg(c, response.CodeForbidden, err.Error())
+		case "请先解锁前面的提示":
+			response.ErrorWithMsg(c, response.CodeInvalidParams, err.Error())
+		default:
+			response.ErrorWithMsg(c, response.CodeInternalError, err.Error())
+		}
+		return
+	}
+
+	response.Success(c, hint)
+}
+
+// GetAdminHints 获取题目全部提示（管理员）
+// @Summary 获取题目全部提示
+// @Description 管理员获取题目的全部提示内容及各提示的解锁人数，草稿题目同样可用
+// @Tags 题目提示
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "题目ID"
+// @Success 200 {object} response.Response{data=[]service.ProblemHintStat} "获取成功"
+// @Failure 400 {object} response.Response "参数错误"
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 403 {object} response.Response "权限不足"
+// @Failure 404 {object} response.Response "题目不存在"
+// @Router /api/admin/problems/{id}/hints [get]
+func (a *ProblemAPI) GetAdminHints(c *gin.Context) {
+	problemID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, response.CodeInvalidParams)
+		return
+	}
+
+	hints, err := a.problemService.GetAdminHints(uint(problemID))
+	if err != nil {
+		if err.Error() == "题目不存在" {
+			response.Error(c, response.CodeProblemNotFound)
+			return
+		}
+		response.ErrorWithMsg(c, response.CodeInternalError, err.Error())
+		return
+	}
+
+	response.Success(c, hints)
+}
+
+// CreateHint 创建题目提示（管理员）
+// @Summary 创建题目提示
+// @Description 管理员为题目添加提示，每道题目最多10个。内容为Markdown，包含脚本、事件属性、内嵌框架或危险链接时返回参数错误；未指定position时排在最后
+// @Tags 题目提示
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "题目ID"
+// @Param request body service.CreateHintRequest true "提示信息"
+// @Success 200 {object} response.Response{data=model.ProblemHint} "创建成功"
+// @Failure 400 {object} response.Response "参数错误"
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 403 {object} response.Response "权限不足"
+// @Failure 404 {object} response.Response "题目不存在"
+// @Router /api/admin/problems/{id}/hints [post]
+func (a *ProblemAPI) CreateHint(c *gin.Context) {
+	problemID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, response.CodeInvalidParams)
+		return
+	}
+
+	var req service.CreateHintRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, response.CodeBindError)
+		return
+	}
+
+	hint, err := a.problemService.CreateHint(getOperator(c), uint(problemID), &req)
+	if err != nil {
+		respondHintError(c, err)
+		return
+	}
+
+	response.Success(c, hint)
+}
+
+// UpdateHint 更新题目提示（管理员）
+// @Summary 更新题目提示
+// @Description 管理员更新提示的内容、惩罚分或顺序，未传的字段不修改。惩罚分在选手解锁时确定，修改后只影响之后的解锁
+// @Tags 题目提示
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "提示ID"
+// @Param request body service.UpdateHintRequest true "更新信息"
+// @Success 200 {object} response.Response{data=model.ProblemHint} "更新成功"
+// @Failure 400 {object} response.Response "参数错误"
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 403 {object} response.Response "权限不足"
+// @Failure 404 {object} response.Response "提示不存在"
+// @Router /api/admin/problem-hints/{id} [put]
+func (a *ProblemAPI) UpdateHint(c *gin.Context) {
+	hintID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, response.CodeInvalidParams)
+		return
+	}
+
+	var req service.UpdateHintRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, response.CodeBindError)
+		return
+	}
+
+	hint, err := a.problemService.UpdateHint(getOperator(c), uint(hintID), &req)
+	if err != nil {
+		respondHintError(c, err)
+		return
+	}
+
+	response.Success(c, hint)
+}
+
+// DeleteHint 删除题目提示（管理员）
+// @Summary 删除题目提示
+// @Description 管理员删除提示，已有选手解锁的提示不能删除
+// @Tags 题目提示
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "提示ID"
+// @Success 200 {object} response.Response "删除成功"
+// @Failure 400 {object} response.Response "参数错误"
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 403 {object} response.Response "权限不足"
+// @Failure 404 {object} response.Response "提示不存在"
+// @Router /api/admin/problem-hints/{id} [delete]
+func (a *ProblemAPI) DeleteHint(c *gin.Context) {
+	hintID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, response.CodeInvalidParams)
+		return
+	}
+
+	if err := a.problemService.DeleteHint(getOperator(c), uint(hintID)); err != nil {
+		respondHintError(c, err)
+		return
+	}
+
+	response.Success(c, nil)
+}
+
+// respondHintError 将提示管理的错误转换为响应
+func respondHintError(c *gin.Context, err error) {
+	if errors.Is(err, markdown.ErrUnsafe) {
+		response.ErrorWithMsg(c, response.CodeInvalidParams, err.Error())
+		return
+	}
+	switch err.Error() {
+	case "题目不存在":
+		response.Error(c, response.CodeProblemNotFound)
+	case "提示不存在":
+		response.Error(c, response.CodeHintNotFound)
+	case "提示不能超过10个", "提示顺序不能为负数", "惩罚分不能为负数", "提示已被选手解锁，无法删除":
+		response.ErrorWithMsg(c, response.CodeInvalidParams, err.Error())
+	default:
+		response.ErrorWithMsg(c, response.CodeInternalError, err.Error())
+	}
+}
@@ -0,0 +1,77 @@
+package api
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tksky1/glimgate/internal/service"
+	"github.com/tksky1/glimgate/pkg/response"
+)
+
+// UpdatePrerequisites 设置题目解锁条件（管理员）
+// @Summary 设置题目解锁条件
+// @Description 管理员设置题目的解锁条件，替换已有的全部条件，选手需满足全部条件才能查看题面和提交。type为submitted时在前置题目有提交即满足，为score时前置题目的总分不低于min_score才满足。前置题目不能是题目自身，条件之间不能形成循环依赖，传空列表时取消解锁条件
+// @Tags 题目管理
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "题目ID"
+// @Param request body service.UpdatePrerequisitesRequest true "解锁条件"
+// @Success 200 {object} response.Response{data=model.Problem} "设置成功"
+// @Failure 400 {object} response.Response "参数错误"
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 403 {object} response.Response "权限不足"
+// @Failure 404 {object} response.Response "题目不存在"
+// @Router /api/admin/problems/{id}/prerequisites [put]
+func (a *ProblemAPI) UpdatePrerequisites(c *gin.Context) {
+	problemID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, response.CodeInvalidParams)
+		return
+	}
+
+	var req service.UpdatePrerequisitesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, response.CodeBindError)
+		return
+	}
+
+	problem, err := a.problemService.UpdatePrerequisites(getOperator(c), uint(problemID), &req)
+	if err != nil {
+		switch err.Error() {
+		case "题目不存在":
+			response.Error(c, response.CodeProblemNotFound)
+		case "解锁条件不能超过10个", "不能以题目自身作为前置题目", "前置题目重复", "得分条件的最低分必须大于0",
+			"解锁条件类型无效", "前置题目不存在", "解锁条件存在循环依赖":
+			response.ErrorWithMsg(c, response.CodeInvalidParams, err.Error())
+		default:
+			response.ErrorWithMsg(c, response.CodeInternalError, err.Error())
+		}
+		return
+	}
+
+	response.Success(c, problem)
+}
+
+// GetUnlockStats 获取题目解锁统计（管理员）
+// @Summary 获取题目解锁统计
+// @Description 管理员查看各题目的解锁条件以及已解锁的选手人数，可按方向筛选。没有解锁条件的题目对全部选手解锁，管理员和已删除的用户不计入
+// @Tags 题目管理
+// @Produce json
+// @Security ApiKeyAuth
+// @Param direction_id query int false "方向ID"
+// @Success 200 {object} response.Response{data=[]service.ProblemUnlockStat} "获取成功"
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 403 {object} response.Response "权限不足"
+// @Router /api/admin/problems/unlock-stats [get]
+func (a *ProblemAPI) GetUnlockStats(c *gin.Context) {
+	directionID, _ := strconv.ParseUint(c.DefaultQuery("direction_id", "0"), 10, 32)
+
+	stats, err := a.problemService.GetUnlockStats(uint(directionID))
+	if err != nil {
+		response.ErrorWithMsg(c, response.CodeInternalError, err.Error())
+		return
+	}
+
+	response.Success(c, stats)
+}
@@ -0,0 +1,129 @@
+package api
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tksky1/glimgate/pkg/response"
+)
+
+// GetRevisions 获取题目修订记录（管理员）
+// @Summary 获取题目修订记录
+// @Description 管理员获取题目的修订记录，按版本从新到旧排列，不包含题面。题目创建以及标题、题面、提交点设置每次变化时都会记录新版本
+// @Tags 题目管理
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "题目ID"
+// @Success 200 {object} response.Response{data=[]model.ProblemRevision} "获取成功"
+// @Failure 400 {object} response.Response "参数错误"
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 403 {object} response.Response "权限不足"
+// @Failure 404 {object} response.Response "题目不存在"
+// @Router /api/admin/problems/{id}/revisions [get]
+func (a *ProblemAPI) GetRevisions(c *gin.Context) {
+	problemID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, response.CodeInvalidParams)
+		return
+	}
+
+	revisions, err := a.problemService.GetRevisions(uint(problemID))
+	if err != nil {
+		if err.Error() == "题目不存在" {
+			response.Error(c, response.CodeProblemNotFound)
+			return
+		}
+		response.ErrorWithMsg(c, response.CodeInternalError, err.Error())
+		return
+	}
+
+	response.Success(c, revisions)
+}
+
+// GetRevision 获取题目的指定版本（管理员）
+// @Summary 获取题目的指定版本
+// @Description 管理员获取题目指定版本的标题、题面和提交点设置
+// @Tags 题目管理
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "题目ID"
+// @Param version path int true "版本号"
+// @Success 200 {object} response.Response{data=model.ProblemRevision} "获取成功"
+// @Failure 400 {object} response.Response "参数错误"
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 403 {object} response.Response "权限不足"
+// @Failure 404 {object} response.Response "题目或版本不存在"
+// @Router /api/admin/problems/{id}/revisions/{version} [get]
+func (a *ProblemAPI) GetRevision(c *gin.Context) {
+	problemID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, response.CodeInvalidParams)
+		return
+	}
+	version, err := strconv.Atoi(c.Param("version"))
+	if err != nil {
+		response.Error(c, response.CodeInvalidParams)
+		return
+	}
+
+	revision, err := a.problemService.GetRevision(uint(problemID), version)
+	if err != nil {
+		respondRevisionError(c, err)
+		return
+	}
+
+	response.Success(c, revision)
+}
+
+// DiffRevisions 比较题目的两个版本（管理员）
+// @Summary 比较题目的两个版本
+// @Description 管理员比较题目的两个版本：标题和提交点设置按字段列出差异，提交点按名称匹配；题面以统一差异格式（unified diff）返回。to默认为最新版本，from默认为to的上一个版本
+// @Tags 题目管理
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "题目ID"
+// @Param from query int false "起始版本"
+// @Param to query int false "目标版本"
+// @Success 200 {object} response.Response{data=service.ProblemRevisionDiff} "获取成功"
+// @Failure 400 {object} response.Response "参数错误"
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 403 {object} response.Response "权限不足"
+// @Failure 404 {object} response.Response "题目或版本不存在"
+// @Router /api/admin/problems/{id}/revisions/diff [get]
+func (a *ProblemAPI) DiffRevisions(c *gin.Context) {
+	problemID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, response.CodeInvalidParams)
+		return
+	}
+	from, err := strconv.Atoi(c.DefaultQuery("from", "0"))
+	if err != nil {
+		response.Error(c, response.CodeInvalidParams)
+		return
+	}
+	to, err := strconv.Atoi(c.DefaultQuery("to", "0"))
+	if err != nil {
+		response.Error(c, response.CodeInvalidParams)
+		return
+	}
+
+	diff, err := a.problemService.DiffRevisions(uint(problemID), from, to)
+	if err != nil {
+		respondRevisionError(c, err)
+		return
+	}
+
+	response.Success(c, diff)
+}
+
+// respondRevisionError 将修订记录查询的错误转换为响应
+func respondRevisionError(c *gin.Context, err error) {
+	switch err.Error() {
+	case "题目不存在":
+		response.Error(c, response.CodeProblemNotFound)
+	case "版本不存在":
+		response.Error(c, response.CodeRevisionNotFound)
+	default:
+		response.ErrorWithMsg(c, response.CodeInternalError, err.Error())
+	}
+}
@@ -0,0 +1,149 @@
+package api
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tksky1/glimgate/internal/service"
+	"github.com/tksky1/glimgate/pkg/response"
+)
+
+// SearchProblems 搜索题目
+// @Summary 搜索题目
+// @Description 按关键词在标题和描述中全文搜索已发布的题目，可按方向、标签、难度筛选并排序。多个关键词以空格分隔，需全部命中；指定关键词且未指定排序时按相关度排序。可选携带token，未解锁的题目只按标题匹配关键词，locked为true且不返回题面
+// @Tags 题目管理
+// @Produce json
+// @Security ApiKeyAuth
+// @Param keyword query string false "关键词"
+// @Param direction_id query int false "方向ID"
+// @Param status query string false "可见状态(published/archived)，默认published"
+// @Param tags query string false "标签，多个以逗号分隔，需全部包含"
+// @Param min_difficulty query int false "最低难度(1-5)"
+// @Param max_difficulty query int false "最高难度(1-5)"
+// @Param sort query string false "排序字段(relevance/id/title/difficulty/estimated_minutes/created_at/published_at)"
+// @Param order query string false "排序方向(asc/desc)" default(asc)
+// @Param page query int false "页码" default(1)
+// @Param page_size query int false "每页数量" default(20)
+// @Success 200 {object} response.Response{data=map[string]interface{}} "获取成功"
+// @Failure 400 {object} response.Response "参数错误"
+// @Router /api/problems/search [get]
+func (a *ProblemAPI) SearchProblems(c *gin.Context) {
+	a.searchProblems(c, true)
+}
+
+// GetProblemTags 获取题目标签
+// @Summary 获取题目标签
+// @Description 获取已发布题目使用的标签及题目数量，按题目数量降序排列
+// @Tags 题目管理
+// @Produce json
+// @Success 200 {object} response.Response{data=[]repository.TagCount} "获取成功"
+// @Router /api/problems/tags [get]
+func (a *ProblemAPI) GetProblemTags(c *gin.Context) {
+	tags, err := a.problemService.GetTags(true)
+	if err != nil {
+		response.ErrorWithMsg(c, response.CodeInternalError, err.Error())
+		return
+	}
+
+	response.Success(c, tags)
+}
+
+// SearchAdminProblems 搜索全部题目（管理员）
+// @Summary 搜索全部题目
+// @Description 管理员搜索包含草稿、定时发布和已归档在内的全部题目，参数与选手侧搜索相同，status为空时不限可见状态
+// @Tags 题目管理
+// @Produce json
+// @Security ApiKeyAuth
+// @Param keyword query string false "关键词"
+// @Param direction_id query int false "方向ID"
+// @Param status query string false "可见状态(draft/scheduled/published/archived)"
+// @Param tags query string false "标签，多个以逗号分隔，需全部包含"
+// @Param min_difficulty query int false "最低难度(1-5)"
+// @Param max_difficulty query int false "最高难度(1-5)"
+// @Param sort query string false "排序字段(relevance/id/title/difficulty/estimated_minutes/created_at/published_at)"
+// @Param order query string false "排序方向(asc/desc)" default(asc)
+// @Param page query int false "页码" default(1)
+// @Param page_size query int false "每页数量" default(20)
+// @Success 200 {object} response.Response{data=map[string]interface{}} "获取成功"
+// @Failure 400 {object} response.Response "参数错误"
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 403 {object} response.Response "权限不足"
+// @Router /api/admin/problems/search [get]
+func (a *ProblemAPI) SearchAdminProblems(c *gin.Context) {
+	a.searchProblems(c, false)
+}
+
+// GetAdminProblemTags 获取全部题目标签（管理员）
+// @Summary 获取全部题目标签
+// @Description 管理员获取全部题目（含草稿）使用的标签及题目数量，按题目数量降序排列
+// @Tags 题目管理
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} response.Response{data=[]repository.TagCount} "获取成功"
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 403 {object} response.Response "权限不足"
+// @Router /api/admin/problems/tags [get]
+func (a *ProblemAPI) GetAdminProblemTags(c *gin.Context) {
+	tags, err := a.problemService.GetTags(false)
+	if err != nil {
+		response.ErrorWithMsg(c, response.CodeInternalError, err.Error())
+		return
+	}
+
+	response.Success(c, tags)
+}
+
+// searchProblems 解析搜索参数并返回分页结果
+func (a *ProblemAPI) searchProblems(c *gin.Context, visibleOnly bool) {
+	directionID, _ := strconv.ParseUint(c.DefaultQuery("direction_id", "0"), 10, 32)
+	minDifficulty, _ := strconv.Atoi(c.DefaultQuery("min_difficulty", "0"))
+	maxDifficulty, _ := strconv.Atoi(c.DefaultQuery("max_difficulty", "0"))
+	req := &service.SearchProblemsRequest{
+		Keyword:       c.Query("keyword"),
+		DirectionID:   uint(directionID),
+		Status:        c.Query("status"),
+		MinDifficulty: minDifficulty,
+		MaxDifficulty: maxDifficulty,
+		Sort:          c.Query("sort"),
+		Order:         c.Query("order"),
+	}
+	if visibleOnly {
+		req.UserID = optionalUserID(c)
+	}
+	for _, tag := range strings.Split(c.Query("tags"), ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			req.Tags = append(req.Tags, tag)
+		}
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	problems, total, err := a.problemService.SearchProblems(req, visibleOnly, page, pageSize)
+	if err != nil {
+		switch err.Error() {
+		case "题目状态无效", "难度范围无效", "搜索关键词过长", "排序字段无效", "排序方向无效":
+			response.ErrorWithMsg(c, response.CodeInvalidParams, err.Error())
+		default:
+			response.ErrorWithMsg(c, response.CodeInternalError, err.Error())
+		}
+		return
+	}
+
+	data := map[string]interface{}{
+		"problems":  problems,
+		"total":     total,
+		"page":      page,
+		"page_size": pageSize,
+	}
+
+	response.Success(c, data)
+}
@@ -0,0 +1,115 @@
+package api
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tksky1/glimgate/internal/service"
+	"github.com/tksky1/glimgate/pkg/response"
+)
+
+// GetAdminProblems 获取全部题目列表（管理员）
+// @Summary 获取全部题目列表
+// @Description 管理员获取包含草稿、定时发布和已归档在内的全部题目，可按方向和可见状态筛选
+// @Tags 题目管理
+// @Produce json
+// @Security ApiKeyAuth
+// @Param direction_id query int false "方向ID"
+// @Param status query string false "可见状态(draft/scheduled/published/archived)"
+// @Success 200 {object} response.Response{data=[]model.Problem} "获取成功"
+// @Failure 400 {object} response.Response "参数错误"
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 403 {object} response.Response "权限不足"
+// @Router /api/admin/problems [get]
+func (a *ProblemAPI) GetAdminProblems(c *gin.Context) {
+	directionID, _ := strconv.ParseUint(c.DefaultQuery("direction_id", "0"), 10, 32)
+
+	problems, err := a.problemService.GetProblems(uint(directionID), c.Query("status"))
+	if err != nil {
+		if err.Error() == "题目状态无效" {
+			response.ErrorWithMsg(c, response.CodeInvalidParams, err.Error())
+			return
+		}
+		response.ErrorWithMsg(c, response.CodeInternalError, err.Error())
+		return
+	}
+
+	response.Success(c, problems)
+}
+
+// GetAdminProblem 预览题目详情（管理员）
+// @Summary 预览题目详情
+// @Description 管理员查看任意可见状态的题目详情，用于发布前预览草稿
+// @Tags 题目管理
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "题目ID"
+// @Success 200 {object} response.Response{data=model.Problem} "获取成功"
+// @Failure 400 {object} response.Response "参数错误"
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 403 {object} response.Response "权限不足"
+// @Failure 404 {object} response.Response "题目不存在"
+// @Router /api/admin/problems/{id} [get]
+func (a *ProblemAPI) GetAdminProblem(c *gin.Context) {
+	problemID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, response.CodeInvalidParams)
+		return
+	}
+
+	problem, err := a.problemService.GetProblemByID(uint(problemID))
+	if err != nil {
+		if err.Error() == "题目不存在" {
+			response.Error(c, response.CodeProblemNotFound)
+			return
+		}
+		response.ErrorWithMsg(c, response.CodeInternalError, err.Error())
+		return
+	}
+
+	response.Success(c, problem)
+}
+
+// UpdateProblemStatus 修改题目可见状态（管理员）
+// @Summary 修改题目可见状态
+// @Description 管理员发布、撤回、定时发布或归档题目。status为scheduled时需指定晚于当前的publish_at，到期后自动发布，服务重启不影响；只有已发布的题目可以归档
+// @Tags 题目管理
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "题目ID"
+// @Param request body service.UpdateProblemStatusRequest true "可见状态"
+// @Success 200 {object} response.Response{data=model.Problem} "修改成功"
+// @Failure 400 {object} response.Response "参数错误"
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 403 {object} response.Response "权限不足"
+// @Failure 404 {object} response.Response "题目不存在"
+// @Router /api/admin/problems/{id}/status [put]
+func (a *ProblemAPI) UpdateProblemStatus(c *gin.Context) {
+	problemID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, response.CodeInvalidParams)
+		return
+	}
+
+	var req service.UpdateProblemStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, response.CodeBindError)
+		return
+	}
+
+	problem, err := a.problemService.UpdateProblemStatus(getOperator(c), uint(problemID), &req)
+	if err != nil {
+		switch err.Error() {
+		case "题目不存在":
+			response.Error(c, response.CodeProblemNotFound)
+		case "题目状态无效", "只有已发布的题目可以归档", "定时发布需要指定发布时间", "定时发布时间必须晚于当前时间":
+			response.ErrorWithMsg(c, response.CodeInvalidParams, err.Error())
+		default:
+			response.ErrorWithMsg(c, response.CodeInternalError, err.Error())
+		}
+		return
+	}
+
+	response.Success(c, problem)
+}
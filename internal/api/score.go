@@ -1,11 +1,15 @@
 package api
 
 import (
+	"errors"
+	"fmt"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/tksky1/glimgate/internal/service"
 	"github.com/tksky1/glimgate/pkg/response"
+	"github.com/tksky1/glimgate/pkg/sheet"
 )
 
 // ScoreAPI 评分API处理器
@@ -14,15 +18,15 @@ type ScoreAPI struct {
 }
 
 // NewScoreAPI 创建评分API实例
-func NewScoreAPI() *ScoreAPI {
+func NewScoreAPI(scoreService *service.ScoreService) *ScoreAPI {
 	return &ScoreAPI{
-		scoreService: service.NewScoreService(),
+		scoreService: scoreService,
 	}
 }
 
 // CreateScore 创建评分（管理员）
 // @Summary 创建评分
-// @Description 管理员对提交进行评分
+// @Description 管理员对提交进行评分，对同一提交重复评分会更新原评分
 // @Tags 评分管理
 // @Accept json
 // @Produce json
@@ -42,7 +46,7 @@ func (a *ScoreAPI) CreateScore(c *gin.Context) {
 		return
 	}
 
-	score, err := a.scoreService.CreateScore(reviewerID.(uint), &req)
+	score, err := a.scoreService.CreateScore(getOperator(c), reviewerID.(uint), &req)
 	if err != nil {
 		if err.Error() == "提交不存在" {
 			response.Error(c, response.CodeSubmissionNotFound)
@@ -146,6 +150,59 @@ func (a *ScoreAPI) GetMyScores(c *gin.Context) {
 	response.Success(c, scores)
 }
 
+// GetProblemScoresByUser 获取用户的题目得分
+// @Summary 获取用户的题目得分
+// @Description 按题目汇总指定用户的得分：raw_score为全部评分者的评分之和，hint_penalty为解锁提示扣除的分数，score为扣除后计入排行榜的得分（不低于0）
+// @Tags 评分管理
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "用户ID"
+// @Param direction_id query int false "方向ID"
+// @Success 200 {object} response.Response{data=[]service.ProblemScore} "获取成功"
+// @Failure 400 {object} response.Response "参数错误"
+// @Failure 401 {object} response.Response "未授权"
+// @Router /api/users/{id}/scores/problems [get]
+func (a *ScoreAPI) GetProblemScoresByUser(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, response.CodeInvalidParams)
+		return
+	}
+
+	directionID, _ := strconv.ParseUint(c.DefaultQuery("direction_id", "0"), 10, 32)
+
+	scores, err := a.scoreService.GetProblemScores(uint(userID), uint(directionID))
+	if err != nil {
+		response.ErrorWithMsg(c, response.CodeInternalError, err.Error())
+		return
+	}
+
+	response.Success(c, scores)
+}
+
+// GetMyProblemScores 获取我的题目得分
+// @Summary 获取我的题目得分
+// @Description 按题目汇总当前用户的得分和解锁提示扣除的分数，字段含义同获取用户的题目得分
+// @Tags 评分管理
+// @Produce json
+// @Security ApiKeyAuth
+// @Param direction_id query int false "方向ID"
+// @Success 200 {object} response.Response{data=[]service.ProblemScore} "获取成功"
+// @Failure 401 {object} response.Response "未授权"
+// @Router /api/scores/my/problems [get]
+func (a *ScoreAPI) GetMyProblemScores(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	directionID, _ := strconv.ParseUint(c.DefaultQuery("direction_id", "0"), 10, 32)
+
+	scores, err := a.scoreService.GetProblemScores(userID.(uint), uint(directionID))
+	if err != nil {
+		response.ErrorWithMsg(c, response.CodeInternalError, err.Error())
+		return
+	}
+
+	response.Success(c, scores)
+}
+
 // GetScoresByReviewer 获取评分者的评分列表（管理员）
 // @Summary 获取评分者的评分列表
 // @Description 管理员获取自己的评分记录
@@ -201,7 +258,7 @@ func (a *ScoreAPI) UpdateScore(c *gin.Context) {
 		return
 	}
 
-	score, err := a.scoreService.UpdateScore(uint(scoreID), reviewerID.(uint), &req)
+	score, err := a.scoreService.UpdateScore(getOperator(c), uint(scoreID), reviewerID.(uint), &req)
 	if err != nil {
 		if err.Error() == "评分不存在或无权限修改" {
 			response.Error(c, response.CodeForbidden)
@@ -241,7 +298,7 @@ func (a *ScoreAPI) DeleteScore(c *gin.Context) {
 
 	reviewerID, _ := c.Get("user_id")
 
-	if err := a.scoreService.DeleteScore(uint(scoreID), reviewerID.(uint)); err != nil {
+	if err := a.scoreService.DeleteScore(getOperator(c), uint(scoreID), reviewerID.(uint)); err != nil {
 		if err.Error() == "评分不存在或无权限删除" {
 			response.Error(c, response.CodeForbidden)
 			return
@@ -255,7 +312,7 @@ func (a *ScoreAPI) DeleteScore(c *gin.Context) {
 
 // GetRanking 获取排行榜
 // @Summary 获取排行榜
-// @Description 获取指定方向的排行榜
+// @Description 获取指定方向的排行榜，总分为各题目扣除提示惩罚分后的得分之和
 // @Tags 评分管理
 // @Accept json
 // @Produce json
@@ -279,4 +336,75 @@ func (a *ScoreAPI) GetRanking(c *gin.Context) {
 	}
 
 	response.Success(c, rankings)
-}
\ No newline at end of file
+}
+
+// ExportScores 导出评分汇总表（管理员）
+// @Summary 导出评分汇总表
+// @Description 管理员按方向或题目导出评分宽表：每位有提交的候选人一行，按总分排名（同分同名次）；每个提交点的每位评分者一列分数，另有一列汇总该提交点的评语
+// @Tags 评分管理
+// @Produce text/csv
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Security ApiKeyAuth
+// @Param direction_id query int false "方向ID，与题目ID二选一"
+// @Param problem_id query int false "题目ID，与方向ID二选一"
+// @Param format query string false "导出格式(csv/xlsx)" default(csv)
+// @Success 200 {file} file "表格文件"
+// @Failure 400 {object} response.Response "参数错误"
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 403 {object} response.Response "权限不足"
+// @Failure 404 {object} response.Response "方向或题目不存在"
+// @Router /api/admin/scores/export [get]
+func (a *ScoreAPI) ExportScores(c *gin.Context) {
+	format, err := sheet.ParseFormat(c.Query("format"))
+	if err != nil {
+		response.ErrorWithMsg(c, response.CodeInvalidParams, err.Error())
+		return
+	}
+
+	directionID, problemID, err := parseExportScopeQuery(c)
+	if err != nil {
+		response.ErrorWithMsg(c, response.CodeInvalidParams, err.Error())
+		return
+	}
+
+	scope, err := a.scoreService.ResolveExportScope(directionID, problemID)
+	if err != nil {
+		respondExportScopeError(c, err)
+		return
+	}
+
+	filename := fmt.Sprintf("scores-%s-%s.%s", scope.Name, time.Now().Format("20060102150405"), format)
+	c.Header("Content-Type", sheet.ContentType(format))
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+
+	if err := a.scoreService.ExportScoreSheet(scope, format, c.Writer); err != nil {
+		c.Error(err)
+	}
+}
+
+// parseExportScopeQuery 解析导出范围参数direction_id和problem_id
+func parseExportScopeQuery(c *gin.Context) (uint, uint, error) {
+	directionID, err := strconv.ParseUint(c.DefaultQuery("direction_id", "0"), 10, 32)
+	if err != nil {
+		return 0, 0, errors.New("方向ID格式错误")
+	}
+	problemID, err := strconv.ParseUint(c.DefaultQuery("problem_id", "0"), 10, 32)
+	if err != nil {
+		return 0, 0, errors.New("题目ID格式错误")
+	}
+	return uint(directionID), uint(problemID), nil
+}
+
+// respondExportScopeError 返回解析导出范围失败的响应
+func respondExportScopeError(c *gin.Context, err error) {
+	switch err.Error() {
+	case "方向不存在":
+		response.Error(c, response.CodeDirectionNotFound)
+	case "题目不存在":
+		response.Error(c, response.CodeProblemNotFound)
+	case "必须指定方向ID或题目ID之一":
+		response.ErrorWithMsg(c, response.CodeInvalidParams, err.Error())
+	default:
+		response.ErrorWithMsg(c, response.CodeInternalError, err.Error())
+	}
+}
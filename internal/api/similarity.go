@@ -0,0 +1,126 @@
+package api
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tksky1/glimgate/internal/service"
+	"github.com/tksky1/glimgate/pkg/response"
+)
+
+// SimilarityAPI 相似度检测API处理器
+type SimilarityAPI struct {
+	similarityService *service.SimilarityService
+}
+
+// NewSimilarityAPI 创建相似度检测API实例
+func NewSimilarityAPI(similarityService *service.SimilarityService) *SimilarityAPI {
+	return &SimilarityAPI{
+		similarityService: similarityService,
+	}
+}
+
+// Analyze 检测提交点下全部提交的相似度（管理员）
+// @Summary 检测提交相似度
+// @Description 管理员比较提交点下的全部提交，替换该提交点原有的相似度报告，并标记最高相似度达到阈值的提交。代码按词法单元指纹比较，文字按连续词组比较，内容只是一个链接时比较链接是否相同，代码仓库提交点比较仓库地址、检出的提交和提交作者邮箱
+// @Tags 相似度检测
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "提交点ID"
+// @Success 200 {object} response.Response{data=service.SimilarityResult} "检测完成"
+// @Failure 400 {object} response.Response "参数错误"
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 403 {object} response.Response "权限不足"
+// @Failure 404 {object} response.Response "提交点不存在"
+// @Router /api/admin/submission-points/{id}/similarity [post]
+func (a *SimilarityAPI) Analyze(c *gin.Context) {
+	pointID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, response.CodeInvalidParams)
+		return
+	}
+
+	result, err := a.similarityService.Analyze(uint(pointID))
+	if err != nil {
+		respondSimilarityError(c, err)
+		return
+	}
+
+	response.Success(c, result)
+}
+
+// GetClusters 获取提交点下的可疑提交分组（管理员）
+// @Summary 获取可疑提交分组
+// @Description 管理员获取提交点下相似度达到阈值的提交分组，相似的提交对连成一组，按组内最高相似度从高到低排列
+// @Tags 相似度检测
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "提交点ID"
+// @Param threshold query number false "相似度阈值（0-1），默认使用配置的阈值"
+// @Success 200 {object} response.Response{data=[]service.SimilarityCluster} "获取成功"
+// @Failure 400 {object} response.Response "参数错误"
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 403 {object} response.Response "权限不足"
+// @Failure 404 {object} response.Response "提交点不存在"
+// @Router /api/admin/submission-points/{id}/similarity [get]
+func (a *SimilarityAPI) GetClusters(c *gin.Context) {
+	pointID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, response.CodeInvalidParams)
+		return
+	}
+	threshold, err := strconv.ParseFloat(c.DefaultQuery("threshold", "0"), 64)
+	if err != nil || threshold < 0 || threshold > 1 {
+		response.Error(c, response.CodeInvalidParams)
+		return
+	}
+
+	clusters, err := a.similarityService.GetClusters(uint(pointID), threshold)
+	if err != nil {
+		respondSimilarityError(c, err)
+		return
+	}
+
+	response.Success(c, clusters)
+}
+
+// GetSubmissionReports 获取提交的相似度报告（管理员）
+// @Summary 获取提交的相似度报告
+// @Description 管理员获取与某个提交相关的全部相似度报告及双方提交的内容，按相似度从高到低排列，用于逐一比对
+// @Tags 相似度检测
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "提交ID"
+// @Success 200 {object} response.Response{data=[]model.SimilarityReport} "获取成功"
+// @Failure 400 {object} response.Response "参数错误"
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 403 {object} response.Response "权限不足"
+// @Failure 404 {object} response.Response "提交不存在"
+// @Router /api/admin/submissions/{id}/similarity [get]
+func (a *SimilarityAPI) GetSubmissionReports(c *gin.Context) {
+	submissionID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, response.CodeInvalidParams)
+		return
+	}
+
+	reports, err := a.similarityService.GetSubmissionReports(uint(submissionID))
+	if err != nil {
+		respondSimilarityError(c, err)
+		return
+	}
+
+	response.Success(c, reports)
+}
+
+// respondSimilarityError 将相似度检测服务的错误映射为响应码
+func respondSimilarityError(c *gin.Context, err error) {
+	switch err.Error() {
+	case "提交点不存在":
+		response.ErrorWithMsg(c, response.CodeProblemNotFound, err.Error())
+	case "提交不存在":
+		response.Error(c, response.CodeSubmissionNotFound)
+	default:
+		response.ErrorWithMsg(c, response.CodeInternalError, err.Error())
+	}
+}
@@ -1,7 +1,9 @@
 package api
 
 import (
+	"fmt"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/tksky1/glimgate/internal/service"
@@ -14,15 +16,15 @@ type SubmissionAPI struct {
 }
 
 // NewSubmissionAPI 创建提交API实例
-func NewSubmissionAPI() *SubmissionAPI {
+func NewSubmissionAPI(submissionService *service.SubmissionService) *SubmissionAPI {
 	return &SubmissionAPI{
-		submissionService: service.NewSubmissionService(),
+		submissionService: submissionService,
 	}
 }
 
 // CreateSubmission 创建提交
 // @Summary 创建提交
-// @Description 用户提交作业
+// @Description 用户提交作业，同一提交点重复提交会覆盖原提交内容。代码仓库提交点的内容为http(s)仓库地址，可用#<提交哈希>固定检查的提交。只能提交已发布的题目，已归档的题目不再接受提交，设置了解锁条件的题目需先满足条件。组队题目需先加入队伍，每支队伍在一个提交点只有一份提交，任一队员提交都会覆盖
 // @Tags 提交管理
 // @Accept json
 // @Produce json
@@ -31,6 +33,8 @@ func NewSubmissionAPI() *SubmissionAPI {
 // @Success 200 {object} response.Response{data=model.Submission} "提交成功"
 // @Failure 400 {object} response.Response "参数错误"
 // @Failure 401 {object} response.Response "未授权"
+// @Failure 403 {object} response.Response "题目已归档、尚未解锁或未加入队伍"
+// @Failure 404 {object} response.Response "题目不存在"
 // @Router /api/submissions [post]
 func (a *SubmissionAPI) CreateSubmission(c *gin.Context) {
 	userID, _ := c.Get("user_id")
@@ -51,6 +55,14 @@ func (a *SubmissionAPI) CreateSubmission(c *gin.Context) {
 			response.Error(c, response.CodeInvalidParams)
 			return
 		}
+		if err.Error() == "仓库地址必须是http或https地址" || err.Error() == "仓库地址不能指向内网地址" || err.Error() == "提交哈希格式不正确" {
+			response.ErrorWithMsg(c, response.CodeInvalidParams, err.Error())
+			return
+		}
+		if err.Error() == "题目已归档，不再接受提交" || err.Error() == "题目尚未解锁" || err.Error() == "该题目需要组队提交，请先创建或加入队伍" {
+			response.ErrorWithMsg(c, response.CodeForbidden, err.Error())
+			return
+		}
 		response.ErrorWithMsg(c, response.CodeInternalError, err.Error())
 		return
 	}
@@ -60,7 +72,7 @@ func (a *SubmissionAPI) CreateSubmission(c *gin.Context) {
 
 // GetMySubmissions 获取我的提交列表
 // @Summary 获取我的提交列表
-// @Description 获取当前用户的提交列表
+// @Description 获取当前用户的提交列表，包括所在队伍的提交
 // @Tags 提交管理
 // @Accept json
 // @Produce json
@@ -112,11 +124,16 @@ func (a *SubmissionAPI) GetSubmission(c *gin.Context) {
 		return
 	}
 
-	// 检查权限：只有提交者本人或管理员可以查看
+	// 检查权限：只有提交者本人、所属队伍的成员或管理员可以查看
 	userID, _ := c.Get("user_id")
 	isAdmin, _ := c.Get("is_admin")
-	
-	if !isAdmin.(bool) && submission.UserID != userID.(uint) {
+
+	allowed, err := a.submissionService.CanViewSubmission(submission, userID.(uint), isAdmin.(bool))
+	if err != nil {
+		response.ErrorWithMsg(c, response.CodeInternalError, err.Error())
+		return
+	}
+	if !allowed {
 		response.Error(c, response.CodeForbidden)
 		return
 	}
@@ -149,6 +166,42 @@ func (a *SubmissionAPI) GetSubmissionsForReview(c *gin.Context) {
 	response.Success(c, submissions)
 }
 
+// ExportSubmissionArchive 打包下载提交内容（管理员）
+// @Summary 打包下载提交内容
+// @Description 管理员按方向或题目将全部提交打包为ZIP，目录结构为 学号_姓名/题目/提交点.txt
+// @Tags 提交管理
+// @Produce application/zip
+// @Security ApiKeyAuth
+// @Param direction_id query int false "方向ID，与题目ID二选一"
+// @Param problem_id query int false "题目ID，与方向ID二选一"
+// @Success 200 {file} file "ZIP文件"
+// @Failure 400 {object} response.Response "参数错误"
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 403 {object} response.Response "权限不足"
+// @Failure 404 {object} response.Response "方向或题目不存在"
+// @Router /api/admin/submissions/archive [get]
+func (a *SubmissionAPI) ExportSubmissionArchive(c *gin.Context) {
+	directionID, problemID, err := parseExportScopeQuery(c)
+	if err != nil {
+		response.ErrorWithMsg(c, response.CodeInvalidParams, err.Error())
+		return
+	}
+
+	scope, err := a.submissionService.ResolveExportScope(directionID, problemID)
+	if err != nil {
+		respondExportScopeError(c, err)
+		return
+	}
+
+	filename := fmt.Sprintf("submissions-%s-%s.zip", scope.Name, time.Now().Format("20060102150405"))
+	c.Header("Content-Type", "application/zip")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+
+	if err := a.submissionService.ExportSubmissionArchive(scope, c.Writer); err != nil {
+		c.Error(err)
+	}
+}
+
 // DeleteSubmission 删除提交
 // @Summary 删除提交
 // @Description 用户删除自己的提交
@@ -0,0 +1,318 @@
+package api
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tksky1/glimgate/internal/service"
+	"github.com/tksky1/glimgate/pkg/response"
+)
+
+// TeamAPI 组队API处理器
+type TeamAPI struct {
+	teamService *service.TeamService
+}
+
+// NewTeamAPI 创建组队API实例
+func NewTeamAPI(teamService *service.TeamService) *TeamAPI {
+	return &TeamAPI{
+		teamService: teamService,
+	}
+}
+
+// CreateTeam 创建队伍
+// @Summary 创建队伍
+// @Description 在组队题目中创建队伍，创建者成为队长。每位用户在一道题目中只能加入一支队伍，创建后清除其在该题目收到的其他邀请。队伍名称在题目内唯一，不超过50个字符
+// @Tags 组队
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "题目ID"
+// @Param request body service.CreateTeamRequest true "队伍信息"
+// @Success 200 {object} response.Response{data=service.TeamResponse} "创建成功"
+// @Failure 400 {object} response.Response "参数错误"
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 403 {object} response.Response "题目已归档或尚未解锁"
+// @Failure 404 {object} response.Response "题目不存在"
+// @Router /api/problems/{id}/teams [post]
+func (a *TeamAPI) CreateTeam(c *gin.Context) {
+	problemID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, response.CodeInvalidParams)
+		return
+	}
+
+	var req service.CreateTeamRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, response.CodeBindError)
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	team, err := a.teamService.CreateTeam(userID.(uint), uint(problemID), &req)
+	if err != nil {
+		respondTeamError(c, err)
+		return
+	}
+
+	response.Success(c, team)
+}
+
+// GetMyTeam 获取我在题目中的队伍
+// @Summary 获取我的队伍
+// @Description 获取当前用户在题目中加入的队伍，包括成员和队伍发出的待处理邀请。成员只返回用户名和昵称
+// @Tags 组队
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "题目ID"
+// @Success 200 {object} response.Response{data=service.TeamResponse} "获取成功"
+// @Failure 400 {object} response.Response "参数错误"
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 404 {object} response.Response "题目不存在或尚未加入队伍"
+// @Router /api/problems/{id}/team [get]
+func (a *TeamAPI) GetMyTeam(c *gin.Context) {
+	problemID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, response.CodeInvalidParams)
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	team, err := a.teamService.GetMyTeam(userID.(uint), uint(problemID))
+	if err != nil {
+		respondTeamError(c, err)
+		return
+	}
+
+	response.Success(c, team)
+}
+
+// Invite 邀请队员
+// @Summary 邀请队员
+// @Description 队长按用户名邀请队员，被邀请的用户会收到通知。被邀请的用户在该题目中不能已有队伍，队伍人数不能超过题目的上限，队伍有提交后不能再邀请
+// @Tags 组队
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "队伍ID"
+// @Param request body service.InviteTeamMemberRequest true "被邀请的用户"
+// @Success 200 {object} response.Response{data=service.TeamInviteInfo} "邀请成功"
+// @Failure 400 {object} response.Response "参数错误"
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 403 {object} response.Response "不是队长"
+// @Failure 404 {object} response.Response "队伍或用户不存在"
+// @Router /api/teams/{id}/invites [post]
+func (a *TeamAPI) Invite(c *gin.Context) {
+	teamID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, response.CodeInvalidParams)
+		return
+	}
+
+	var req service.InviteTeamMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, response.CodeBindError)
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	invite, err := a.teamService.Invite(userID.(uint), uint(teamID), &req)
+	if err != nil {
+		respondTeamError(c, err)
+		return
+	}
+
+	response.Success(c, invite)
+}
+
+// LeaveTeam 退出队伍
+// @Summary 退出队伍
+// @Description 退出队伍，队伍有提交后成员不能再变动。队长退出时由最早加入的队员接任，最后一名队员退出时解散队伍
+// @Tags 组队
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "队伍ID"
+// @Success 200 {object} response.Response "退出成功"
+// @Failure 400 {object} response.Response "参数错误或队伍已有提交"
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 404 {object} response.Response "队伍不存在"
+// @Router /api/teams/{id}/leave [post]
+func (a *TeamAPI) LeaveTeam(c *gin.Context) {
+	teamID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, response.CodeInvalidParams)
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	if err := a.teamService.LeaveTeam(userID.(uint), uint(teamID)); err != nil {
+		respondTeamError(c, err)
+		return
+	}
+
+	response.Success(c, nil)
+}
+
+// RemoveMember 移除队员
+// @Summary 移除队员
+// @Description 队长移除队员，队伍有提交后成员不能再变动
+// @Tags 组队
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "队伍ID"
+// @Param user_id path int true "队员的用户ID"
+// @Success 200 {object} response.Response "移除成功"
+// @Failure 400 {object} response.Response "参数错误或队伍已有提交"
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 403 {object} response.Response "不是队长"
+// @Failure 404 {object} response.Response "队伍不存在"
+// @Router /api/teams/{id}/members/{user_id} [delete]
+func (a *TeamAPI) RemoveMember(c *gin.Context) {
+	teamID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, response.CodeInvalidParams)
+		return
+	}
+	memberID, err := strconv.ParseUint(c.Param("user_id"), 10, 32)
+	if err != nil {
+		response.Error(c, response.CodeInvalidParams)
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	if err := a.teamService.RemoveMember(userID.(uint), uint(teamID), uint(memberID)); err != nil {
+		respondTeamError(c, err)
+		return
+	}
+
+	response.Success(c, nil)
+}
+
+// GetMyInvites 获取我收到的组队邀请
+// @Summary 获取我收到的组队邀请
+// @Description 获取当前用户收到的待处理组队邀请，按时间倒序排列
+// @Tags 组队
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} response.Response{data=[]service.MyTeamInvite} "获取成功"
+// @Failure 401 {object} response.Response "未授权"
+// @Router /api/team-invites [get]
+func (a *TeamAPI) GetMyInvites(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	invites, err := a.teamService.GetMyInvites(userID.(uint))
+	if err != nil {
+		response.ErrorWithMsg(c, response.CodeInternalError, err.Error())
+		return
+	}
+
+	response.Success(c, invites)
+}
+
+// AcceptInvite 接受组队邀请
+// @Summary 接受组队邀请
+// @Description 接受邀请加入队伍，并清除在该题目收到的其他邀请。已在该题目中加入队伍、队伍人数已满或队伍已有提交时失败
+// @Tags 组队
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "邀请ID"
+// @Success 200 {object} response.Response{data=service.TeamResponse} "加入成功"
+// @Failure 400 {object} response.Response "参数错误"
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 403 {object} response.Response "题目已归档或尚未解锁"
+// @Failure 404 {object} response.Response "邀请不存在"
+// @Router /api/team-invites/{id}/accept [post]
+func (a *TeamAPI) AcceptInvite(c *gin.Context) {
+	inviteID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, response.CodeInvalidParams)
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	team, err := a.teamService.AcceptInvite(userID.(uint), uint(inviteID))
+	if err != nil {
+		respondTeamError(c, err)
+		return
+	}
+
+	response.Success(c, team)
+}
+
+// DeleteInvite 拒绝或撤回组队邀请
+// @Summary 拒绝或撤回组队邀请
+// @Description 被邀请的用户拒绝邀请，或队长撤回队伍发出的邀请
+// @Tags 组队
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "邀请ID"
+// @Success 200 {object} response.Response "操作成功"
+// @Failure 400 {object} response.Response "参数错误"
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 404 {object} response.Response "邀请不存在"
+// @Router /api/team-invites/{id} [delete]
+func (a *TeamAPI) DeleteInvite(c *gin.Context) {
+	inviteID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, response.CodeInvalidParams)
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	if err := a.teamService.DeleteInvite(userID.(uint), uint(inviteID)); err != nil {
+		respondTeamError(c, err)
+		return
+	}
+
+	response.Success(c, nil)
+}
+
+// GetTeams 获取题目下的全部队伍（管理员）
+// @Summary 获取题目的队伍列表
+// @Description 管理员获取题目下的全部队伍及成员，按创建顺序排列
+// @Tags 组队
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "题目ID"
+// @Success 200 {object} response.Response{data=[]service.TeamResponse} "获取成功"
+// @Failure 400 {object} response.Response "参数错误"
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 403 {object} response.Response "权限不足"
+// @Failure 404 {object} response.Response "题目不存在"
+// @Router /api/admin/problems/{id}/teams [get]
+func (a *TeamAPI) GetTeams(c *gin.Context) {
+	problemID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, response.CodeInvalidParams)
+		return
+	}
+
+	teams, err := a.teamService.ListTeams(uint(problemID))
+	if err != nil {
+		respondTeamError(c, err)
+		return
+	}
+
+	response.Success(c, teams)
+}
+
+// respondTeamError 将组队服务的错误映射为响应码
+func respondTeamError(c *gin.Context, err error) {
+	switch err.Error() {
+	case "题目不存在":
+		response.Error(c, response.CodeProblemNotFound)
+	case "队伍不存在":
+		response.Error(c, response.CodeTeamNotFound)
+	case "邀请不存在":
+		response.Error(c, response.CodeTeamInviteNotFound)
+	case "用户不存在":
+		response.Error(c, response.CodeUserNotFound)
+	case "题目尚未解锁", "题目已归档，不能再组队", "只有队长可以邀请队员", "只有队长可以移除队员":
+		response.ErrorWithMsg(c, response.CodeForbidden, err.Error())
+	case "该题目不是组队题目", "队伍名称长度应为1-50个字符", "队伍名称已被使用", "你已加入该题目的队伍",
+		"该用户已加入该题目的队伍", "队伍人数已满", "已邀请过该用户", "队伍已有提交，成员不能再变动",
+		"队长不能移除自己，请退出队伍", "该用户不是队伍成员":
+		response.ErrorWithMsg(c, response.CodeInvalidParams, err.Error())
+	default:
+		response.ErrorWithMsg(c, response.CodeInternalError, err.Error())
+	}
+}
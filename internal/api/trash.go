@@ -0,0 +1,112 @@
+package api
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tksky1/glimgate/internal/service"
+	"github.com/tksky1/glimgate/pkg/response"
+)
+
+// TrashAPI 回收站API处理器
+type TrashAPI struct {
+	trashService *service.TrashService
+}
+
+// NewTrashAPI 创建回收站API实例
+func NewTrashAPI(trashService *service.TrashService) *TrashAPI {
+	return &TrashAPI{
+		trashService: trashService,
+	}
+}
+
+// GetDeletedItems 获取回收站记录（管理员）
+// @Summary 获取回收站记录
+// @Description 管理员按对象类型查看已删除的记录，按删除时间倒序
+// @Tags 回收站
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param type path string true "对象类型(user/direction/problem/submission_point/submission/score/problem_attachment)"
+// @Param page query int false "页码" default(1)
+// @Param page_size query int false "每页数量" default(20)
+// @Success 200 {object} response.Response{data=map[string]interface{}} "获取成功"
+// @Failure 400 {object} response.Response "参数错误"
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 403 {object} response.Response "权限不足"
+// @Router /api/admin/trash/{type} [get]
+func (a *TrashAPI) GetDeletedItems(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	items, total, err := a.trashService.GetDeletedItems(c.Param("type"), page, pageSize)
+	if err != nil {
+		if err.Error() == "不支持的回收站类型" {
+			response.ErrorWithMsg(c, response.CodeInvalidParams, err.Error())
+			return
+		}
+		response.ErrorWithMsg(c, response.CodeInternalError, err.Error())
+		return
+	}
+
+	data := map[string]interface{}{
+		"items":     items,
+		"total":     total,
+		"page":      page,
+		"page_size": pageSize,
+	}
+
+	response.Success(c, data)
+}
+
+// RestoreItem 恢复回收站记录（管理员）
+// @Summary 恢复回收站记录
+// @Description 管理员恢复已删除的记录，与其一同删除的下级记录（题目的提交点、提交的评分）会一并恢复
+// @Tags 回收站
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param type path string true "对象类型(user/direction/problem/submission_point/submission/score/problem_attachment)"
+// @Param id path int true "记录ID"
+// @Success 200 {object} response.Response "恢复成功"
+// @Failure 400 {object} response.Response "参数错误"
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 403 {object} response.Response "权限不足"
+// @Failure 404 {object} response.Response "回收站记录不存在"
+// @Router /api/admin/trash/{type}/{id}/restore [post]
+func (a *TrashAPI) RestoreItem(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, response.CodeInvalidParams)
+		return
+	}
+
+	restored, err := a.trashService.RestoreItem(getOperator(c), c.Param("type"), uint(id))
+	if err != nil {
+		switch err.Error() {
+		case "回收站记录不存在":
+			response.Error(c, response.CodeTrashItemNotFound)
+		case "不支持的回收站类型",
+			"所属方向已删除，请先恢复方向",
+			"所属题目已删除，请先恢复题目",
+			"所属提交点已删除，请先恢复提交点",
+			"所属提交已删除，请先恢复提交",
+			"该提交点已有新的提交，无法恢复",
+			"该评审已重新评分，无法恢复",
+			"该题目已有同名附件，无法恢复":
+			response.ErrorWithMsg(c, response.CodeInvalidParams, err.Error())
+		default:
+			response.ErrorWithMsg(c, response.CodeInternalError, err.Error())
+		}
+		return
+	}
+
+	response.Success(c, restored)
+}
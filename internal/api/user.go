@@ -1,22 +1,29 @@
 package api
 
 import (
+	"errors"
+	"fmt"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/tksky1/glimgate/internal/service"
 	"github.com/tksky1/glimgate/pkg/response"
+	"github.com/tksky1/glimgate/pkg/sheet"
 )
 
+// maxImportFileSize 导入文件的最大字节数
+const maxImportFileSize = 10 << 20
+
 // UserAPI 用户API处理器
 type UserAPI struct {
 	userService *service.UserService
 }
 
 // NewUserAPI 创建用户API实例
-func NewUserAPI() *UserAPI {
+func NewUserAPI(userService *service.UserService) *UserAPI {
 	return &UserAPI{
-		userService: service.NewUserService(),
+		userService: userService,
 	}
 }
 
@@ -214,7 +221,7 @@ func (a *UserAPI) UpdateUser(c *gin.Context) {
 		return
 	}
 
-	user, err := a.userService.UpdateUser(uint(userID), &req)
+	user, err := a.userService.UpdateUser(getOperator(c), uint(userID), &req)
 	if err != nil {
 		if err.Error() == "用户不存在" {
 			response.Error(c, response.CodeUserNotFound)
@@ -247,7 +254,7 @@ func (a *UserAPI) DeleteUser(c *gin.Context) {
 		return
 	}
 
-	if err := a.userService.DeleteUser(uint(userID)); err != nil {
+	if err := a.userService.DeleteUser(getOperator(c), uint(userID)); err != nil {
 		if err.Error() == "用户不存在" {
 			response.Error(c, response.CodeUserNotFound)
 			return
@@ -257,4 +264,130 @@ func (a *UserAPI) DeleteUser(c *gin.Context) {
 	}
 
 	response.Success(c, nil)
-}
\ No newline at end of file
+}
+
+// ImportUsers 批量导入用户（管理员）
+// @Summary 批量导入用户
+// @Description 管理员上传CSV或XLSX文件批量创建用户，第一行为表头，列名可用英文或中文：username(用户名)、password(密码)、nickname(昵称)、real_name(姓名)、college(学院)、student_id(学号)、qq(QQ)、email(邮箱)，其中password、qq、email可省略。
+// @Description 逐行校验必填项、长度和格式，并检查用户名、学号在文件内及与已有用户是否重复；存在任何错误时不导入任何用户。未提供密码的用户生成随机初始密码，仅在本次响应中返回。
+// @Tags 用户管理
+// @Accept multipart/form-data
+// @Produce json
+// @Security ApiKeyAuth
+// @Param file formData file true "用户表格(.csv/.xlsx)"
+// @Param dry_run query bool false "仅校验不导入"
+// @Success 200 {object} response.Response{data=service.ImportUsersResult} "校验或导入结果，errors不为空时未导入"
+// @Failure 400 {object} response.Response "参数错误"
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 403 {object} response.Response "权限不足"
+// @Router /api/admin/users/import [post]
+func (a *UserAPI) ImportUsers(c *gin.Context) {
+	file, header, err := c.Request.FormFile("file")
+	if err != nil {
+		response.ErrorWithMsg(c, response.CodeInvalidParams, "请上传导入文件")
+		return
+	}
+	defer file.Close()
+
+	if header.Size > maxImportFileSize {
+		response.ErrorWithMsg(c, response.CodeInvalidParams, "导入文件不能超过10MB")
+		return
+	}
+
+	format, err := sheet.FormatFromFilename(header.Filename)
+	if err != nil {
+		response.ErrorWithMsg(c, response.CodeInvalidParams, err.Error())
+		return
+	}
+
+	rows, err := sheet.ReadAll(file, format)
+	if err != nil {
+		response.ErrorWithMsg(c, response.CodeInvalidParams, "解析导入文件失败: "+err.Error())
+		return
+	}
+
+	dryRun, _ := strconv.ParseBool(c.DefaultQuery("dry_run", "false"))
+	result, err := a.userService.ImportUsers(getOperator(c), rows, dryRun)
+	if err != nil {
+		response.ErrorWithMsg(c, response.CodeInternalError, err.Error())
+		return
+	}
+
+	response.Success(c, result)
+}
+
+// ExportUsers 导出用户名单（管理员）
+// @Summary 导出用户名单
+// @Description 管理员按条件导出用户名单及联系方式、总分，按用户ID排序。指定方向时只导出在该方向有提交的用户，总分也只统计该方向；指定阶段时只导出处于该阶段的选手（不含管理员）：registered已注册但没有提交，submitted有提交但都未评分，scored至少有一个提交已评分，同时指定方向时按该方向的提交划分
+// @Tags 用户管理
+// @Produce text/csv
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Security ApiKeyAuth
+// @Param format query string false "文件格式(csv/xlsx)" default(csv)
+// @Param direction_id query int false "方向ID"
+// @Param stage query string false "招新阶段(registered/submitted/scored)"
+// @Param min_score query int false "最低总分"
+// @Param max_score query int false "最高总分"
+// @Success 200 {file} file "用户表格"
+// @Failure 400 {object} response.Response "参数错误"
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 403 {object} response.Response "权限不足"
+// @Router /api/admin/users/export [get]
+func (a *UserAPI) ExportUsers(c *gin.Context) {
+	format, err := sheet.ParseFormat(c.Query("format"))
+	if err != nil {
+		response.ErrorWithMsg(c, response.CodeInvalidParams, err.Error())
+		return
+	}
+
+	filter, err := parseUserExportFilter(c)
+	if err != nil {
+		response.ErrorWithMsg(c, response.CodeInvalidParams, err.Error())
+		return
+	}
+
+	filename := fmt.Sprintf("users-%s.%s", time.Now().Format("20060102150405"), format)
+	c.Header("Content-Type", sheet.ContentType(format))
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+
+	if err := a.userService.ExportUsers(filter, format, c.Writer); err != nil {
+		// 查询失败时尚未写入表格，改为返回错误信息
+		if !c.Writer.Written() {
+			c.Writer.Header().Del("Content-Type")
+			c.Writer.Header().Del("Content-Disposition")
+			if err.Error() == "阶段无效" {
+				response.ErrorWithMsg(c, response.CodeInvalidParams, err.Error())
+				return
+			}
+			response.ErrorWithMsg(c, response.CodeInternalError, err.Error())
+			return
+		}
+		c.Error(err)
+	}
+}
+
+// parseUserExportFilter 解析用户导出筛选参数
+func parseUserExportFilter(c *gin.Context) (*service.UserExportFilter, error) {
+	directionID, err := strconv.ParseUint(c.DefaultQuery("direction_id", "0"), 10, 32)
+	if err != nil {
+		return nil, errors.New("方向ID格式错误")
+	}
+	filter := &service.UserExportFilter{DirectionID: uint(directionID), Stage: c.Query("stage")}
+
+	if minScore := c.Query("min_score"); minScore != "" {
+		v, err := strconv.Atoi(minScore)
+		if err != nil {
+			return nil, errors.New("最低总分格式错误")
+		}
+		filter.MinScore = &v
+	}
+	if maxScore := c.Query("max_score"); maxScore != "" {
+		v, err := strconv.Atoi(maxScore)
+		if err != nil {
+			return nil, errors.New("最高总分格式错误")
+		}
+		filter.MaxScore = &v
+	}
+
+	return filter, nil
+}
@@ -0,0 +1,267 @@
+package api
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tksky1/glimgate/internal/service"
+	"github.com/tksky1/glimgate/pkg/response"
+)
+
+// WebhookAPI Webhook API处理器
+type WebhookAPI struct {
+	webhookService *service.WebhookService
+}
+
+// NewWebhookAPI 创建Webhook API实例
+func NewWebhookAPI(webhookService *service.WebhookService) *WebhookAPI {
+	return &WebhookAPI{
+		webhookService: webhookService,
+	}
+}
+
+// CreateWebhook 创建Webhook订阅（管理员）
+// @Summary 创建Webhook订阅
+// @Description 管理员创建Webhook订阅，event_types为空表示订阅全部事件，direction_id为0表示不限方向，secret为空时自动生成
+// @Tags Webhook管理
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param request body service.CreateWebhookRequest true "订阅信息"
+// @Success 200 {object} response.Response{data=model.WebhookSubscription} "创建成功"
+// @Failure 400 {object} response.Response "参数错误"
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 403 {object} response.Response "权限不足"
+// @Router /api/admin/webhooks [post]
+func (a *WebhookAPI) CreateWebhook(c *gin.Context) {
+	var req service.CreateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, response.CodeBindError)
+		return
+	}
+
+	subscription, err := a.webhookService.CreateWebhook(&req)
+	if err != nil {
+		if err.Error() == "Webhook地址格式错误" || err.Error() == "不支持的事件类型" {
+			response.ErrorWithMsg(c, response.CodeInvalidParams, err.Error())
+			return
+		}
+		response.ErrorWithMsg(c, response.CodeInternalError, err.Error())
+		return
+	}
+
+	response.Success(c, subscription)
+}
+
+// GetWebhooks 获取Webhook订阅列表（管理员）
+// @Summary 获取Webhook订阅列表
+// @Description 管理员获取全部Webhook订阅
+// @Tags Webhook管理
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} response.Response{data=[]model.WebhookSubscription} "获取成功"
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 403 {object} response.Response "权限不足"
+// @Router /api/admin/webhooks [get]
+func (a *WebhookAPI) GetWebhooks(c *gin.Context) {
+	subscriptions, err := a.webhookService.GetWebhooks()
+	if err != nil {
+		response.ErrorWithMsg(c, response.CodeInternalError, err.Error())
+		return
+	}
+
+	response.Success(c, subscriptions)
+}
+
+// GetWebhook 获取Webhook订阅详情（管理员）
+// @Summary 获取Webhook订阅详情
+// @Description 管理员获取指定Webhook订阅
+// @Tags Webhook管理
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "订阅ID"
+// @Success 200 {object} response.Response{data=model.WebhookSubscription} "获取成功"
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 403 {object} response.Response "权限不足"
+// @Failure 404 {object} response.Response "Webhook不存在"
+// @Router /api/admin/webhooks/{id} [get]
+func (a *WebhookAPI) GetWebhook(c *gin.Context) {
+	subscriptionID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, response.CodeInvalidParams)
+		return
+	}
+
+	subscription, err := a.webhookService.GetWebhookByID(uint(subscriptionID))
+	if err != nil {
+		if err.Error() == "Webhook不存在" {
+			response.Error(c, response.CodeWebhookNotFound)
+			return
+		}
+		response.ErrorWithMsg(c, response.CodeInternalError, err.Error())
+		return
+	}
+
+	response.Success(c, subscription)
+}
+
+// UpdateWebhook 更新Webhook订阅（管理员）
+// @Summary 更新Webhook订阅
+// @Description 管理员更新Webhook订阅，重新启用时清空连续失败计数
+// @Tags Webhook管理
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "订阅ID"
+// @Param request body service.UpdateWebhookRequest true "更新信息"
+// @Success 200 {object} response.Response{data=model.WebhookSubscription} "更新成功"
+// @Failure 400 {object} response.Response "参数错误"
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 403 {object} response.Response "权限不足"
+// @Failure 404 {object} response.Response "Webhook不存在"
+// @Router /api/admin/webhooks/{id} [put]
+func (a *WebhookAPI) UpdateWebhook(c *gin.Context) {
+	subscriptionID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, response.CodeInvalidParams)
+		return
+	}
+
+	var req service.UpdateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, response.CodeBindError)
+		return
+	}
+
+	subscription, err := a.webhookService.UpdateWebhook(uint(subscriptionID), &req)
+	if err != nil {
+		if err.Error() == "Webhook不存在" {
+			response.Error(c, response.CodeWebhookNotFound)
+			return
+		}
+		if err.Error() == "Webhook地址格式错误" || err.Error() == "不支持的事件类型" {
+			response.ErrorWithMsg(c, response.CodeInvalidParams, err.Error())
+			return
+		}
+		response.ErrorWithMsg(c, response.CodeInternalError, err.Error())
+		return
+	}
+
+	response.Success(c, subscription)
+}
+
+// DeleteWebhook 删除Webhook订阅（管理员）
+// @Summary 删除Webhook订阅
+// @Description 管理员删除Webhook订阅
+// @Tags Webhook管理
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "订阅ID"
+// @Success 200 {object} response.Response "删除成功"
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 403 {object} response.Response "权限不足"
+// @Failure 404 {object} response.Response "Webhook不存在"
+// @Router /api/admin/webhooks/{id} [delete]
+func (a *WebhookAPI) DeleteWebhook(c *gin.Context) {
+	subscriptionID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, response.CodeInvalidParams)
+		return
+	}
+
+	if err := a.webhookService.DeleteWebhook(uint(subscriptionID)); err != nil {
+		if err.Error() == "Webhook不存在" {
+			response.Error(c, response.CodeWebhookNotFound)
+			return
+		}
+		response.ErrorWithMsg(c, response.CodeInternalError, err.Error())
+		return
+	}
+
+	response.Success(c, nil)
+}
+
+// GetDeliveries 获取Webhook投递记录（管理员）
+// @Summary 获取Webhook投递记录
+// @Description 管理员查看指定订阅的投递日志
+// @Tags Webhook管理
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "订阅ID"
+// @Param status query string false "投递状态(pending/succeeded/failed)"
+// @Param page query int false "页码" default(1)
+// @Param page_size query int false "每页数量" default(20)
+// @Success 200 {object} response.Response{data=map[string]interface{}} "获取成功"
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 403 {object} response.Response "权限不足"
+// @Router /api/admin/webhooks/{id}/deliveries [get]
+func (a *WebhookAPI) GetDeliveries(c *gin.Context) {
+	subscriptionID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, response.CodeInvalidParams)
+		return
+	}
+
+	status := c.Query("status")
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	deliveries, total, err := a.webhookService.GetDeliveries(uint(subscriptionID), status, page, pageSize)
+	if err != nil {
+		response.ErrorWithMsg(c, response.CodeInternalError, err.Error())
+		return
+	}
+
+	data := map[string]interface{}{
+		"deliveries": deliveries,
+		"total":      total,
+		"page":       page,
+		"page_size":  pageSize,
+	}
+
+	response.Success(c, data)
+}
+
+// Redeliver 重新投递（管理员）
+// @Summary 重新投递Webhook
+// @Description 管理员以相同事件内容重新投递，生成新的投递记录
+// @Tags Webhook管理
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "投递记录ID"
+// @Success 200 {object} response.Response{data=model.WebhookDelivery} "已加入投递队列"
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 403 {object} response.Response "权限不足"
+// @Failure 404 {object} response.Response "投递记录不存在"
+// @Router /api/admin/webhook-deliveries/{id}/redeliver [post]
+func (a *WebhookAPI) Redeliver(c *gin.Context) {
+	deliveryID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, response.CodeInvalidParams)
+		return
+	}
+
+	delivery, err := a.webhookService.Redeliver(uint(deliveryID))
+	if err != nil {
+		if err.Error() == "投递记录不存在" {
+			response.Error(c, response.CodeDeliveryNotFound)
+			return
+		}
+		response.ErrorWithMsg(c, response.CodeInternalError, err.Error())
+		return
+	}
+
+	response.Success(c, delivery)
+}
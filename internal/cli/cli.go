@@ -0,0 +1,107 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/tksky1/glimgate/internal/repository"
+	"github.com/tksky1/glimgate/internal/service"
+	"github.com/tksky1/glimgate/pkg/database"
+	"github.com/tksky1/glimgate/pkg/storage"
+	"gorm.io/gorm"
+)
+
+// Usage glimgatectl的用法说明
+const Usage = `用法: glimgatectl [-config 配置文件] <命令> [参数]
+
+命令:
+  admin create [-generate] [-nickname 昵称] [-email 邮箱] <用户名>   创建管理员账户
+  admin reset-password [-generate] <用户名>                         重置用户密码
+  user promote <用户名>                                             设为管理员
+  user demote <用户名>                                              取消管理员
+  manager list <方向ID>                                             查看方向负责人
+  manager add <方向ID> <用户名>                                     添加方向负责人
+  manager remove <方向ID> <用户名>                                  移除方向负责人
+  problem export <题目ID> <目录>                                    导出题目包到目录
+  problem import [-dry-run] <目录|zip|yaml>                         导入题目包并输出差异
+  problem status [-at 发布时间] <题目ID> <状态>                     修改题目可见状态(draft/scheduled/published/archived)
+  export <文件>                                                     导出全部数据为JSON
+  import <文件>                                                     向空数据库导入JSON数据
+  ranking recompute [-direction 方向ID] [-limit 数量]               校正评分归属并输出排行榜
+  migrate up|down [N]|status                                        执行、回滚或查看数据库迁移
+
+未指定 -generate 时从终端提示输入密码，非终端时从标准输入读取一行。`
+
+// operator 命令行操作在审计日志中的操作者
+var operator = &service.Operator{Username: "glimgatectl"}
+
+// App 管理命令的运行环境，直接操作配置的数据库，不依赖HTTP服务
+type App struct {
+	db             *gorm.DB
+	store          storage.Storage
+	repos          *repository.Repositories
+	auditService   *service.AuditService
+	problemService *service.ProblemService
+	out            io.Writer
+}
+
+// New 创建管理命令运行环境，附件文件读写store，命令输出写入out
+func New(db *gorm.DB, store storage.Storage, out io.Writer) *App {
+	repos := repository.NewRepositories(db)
+	auditService := service.NewAuditService(repos)
+	return &App{
+		db:             db,
+		store:          store,
+		repos:          repos,
+		auditService:   auditService,
+		problemService: service.NewProblemService(repos, store, service.NewNotificationService(repos), auditService),
+		out:            out,
+	}
+}
+
+// Run 执行一条管理命令，args为命令名及其参数
+func (a *App) Run(args []string) error {
+	if len(args) == 0 {
+		return errors.New(Usage)
+	}
+
+	// 除迁移外的命令都要求数据库结构为最新版本
+	if args[0] != "migrate" {
+		if err := database.CheckSchema(a.db); err != nil {
+			return err
+		}
+	}
+
+	switch args[0] {
+	case "admin":
+		return a.runAdmin(args[1:])
+	case "user":
+		return a.runUser(args[1:])
+	case "manager":
+		return a.runManager(args[1:])
+	case "problem":
+		return a.runProblem(args[1:])
+	case "export":
+		if len(args) != 2 {
+			return errors.New("用法: glimgatectl export <文件>")
+		}
+		return a.Export(args[1])
+	case "import":
+		if len(args) != 2 {
+			return errors.New("用法: glimgatectl import <文件>")
+		}
+		return a.Import(args[1])
+	case "ranking":
+		return a.runRanking(args[1:])
+	case "migrate":
+		return a.Migrate(args[1:])
+	default:
+		return fmt.Errorf("未知的命令: %s\n\n%s", args[0], Usage)
+	}
+}
+
+// printf 向命令输出写入一行格式化文本
+func (a *App) printf(format string, args ...interface{}) {
+	fmt.Fprintf(a.out, format+"\n", args...)
+}
@@ -0,0 +1,233 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/tksky1/glimgate/internal/model"
+	"github.com/tksky1/glimgate/pkg/database"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// directionManager 方向负责人关联表，导出导入时作为普通表处理
+type directionManager struct {
+	DirectionID uint `gorm:"primaryKey"`
+	UserID      uint `gorm:"primaryKey"`
+}
+
+func (directionManager) TableName() string { return "direction_managers" }
+
+// dataModels 导出导入的数据表，按外键依赖顺序排列，新增数据表时需同步追加
+var dataModels = []interface{}{
+	&model.User{},
+	&model.Direction{},
+	&directionManager{},
+	&model.Problem{},
+	&model.SubmissionPoint{},
+	&model.JudgeCase{},
+	&model.CheckStep{},
+	&model.ProblemAttachment{},
+	&model.ProblemTag{},
+	&model.ProblemPrerequisite{},
+	&model.ProblemHint{},
+	&model.HintUnlock{},
+	&model.ProblemRevision{},
+	&model.Team{},
+	&model.TeamMember{},
+	&model.TeamInvite{},
+	&model.Submission{},
+	&model.Score{},
+	&model.JudgeRun{},
+	&model.CheckRun{},
+	&model.SimilarityReport{},
+	&model.Clarification{},
+	&model.Notification{},
+	&model.NotificationSetting{},
+	&model.WebhookSubscription{},
+	&model.WebhookDelivery{},
+	&model.AuditLog{},
+}
+
+// dataDump 导出文件格式，包含全部列（密码哈希、删除时间等接口中隐藏的字段也会导出）
+type dataDump struct {
+	SchemaVersion uint        `json:"schema_version"`
+	ExportedAt    time.Time   `json:"exported_at"`
+	Tables        []dataTable `json:"tables"`
+}
+
+// dataTable 一张表的数据，每行为列名到值的映射
+type dataTable struct {
+	Name string                       `json:"name"`
+	Rows []map[string]json.RawMessage `json:"rows"`
+}
+
+// Export 将全部数据（含已删除的记录）导出为JSON文件，可用于备份或在不同数据库之间迁移
+func (a *App) Export(path string) error {
+	version, err := schemaVersion(a.db)
+	if err != nil {
+		return err
+	}
+
+	dump := dataDump{SchemaVersion: version, ExportedAt: time.Now()}
+	for _, m := range dataModels {
+		sch, err := parseSchema(a.db, m)
+		if err != nil {
+			return err
+		}
+
+		records := reflect.New(reflect.SliceOf(sch.ModelType))
+		if err := a.db.Unscoped().Order(strings.Join(sch.PrimaryFieldDBNames, ", ")).Find(records.Interface()).Error; err != nil {
+			return fmt.Errorf("导出 %s 失败: %w", sch.Table, err)
+		}
+
+		table := dataTable{Name: sch.Table, Rows: []map[string]json.RawMessage{}}
+		for i := 0; i < records.Elem().Len(); i++ {
+			record := records.Elem().Index(i)
+			row := make(map[string]json.RawMessage)
+			for _, field := range sch.Fields {
+				if field.DBName == "" {
+					continue
+				}
+				value, err := json.Marshal(field.ReflectValueOf(context.Background(), record).Interface())
+				if err != nil {
+					return err
+				}
+				row[field.DBName] = value
+			}
+			table.Rows = append(table.Rows, row)
+		}
+		dump.Tables = append(dump.Tables, table)
+		a.printf("已导出 %s: %d 条", sch.Table, len(table.Rows))
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if err := json.NewEncoder(file).Encode(&dump); err != nil {
+		return err
+	}
+	return file.Close()
+}
+
+// Import 将Export导出的JSON文件导入空数据库，保留原记录ID，导入在同一事务中完成
+func (a *App) Import(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var dump dataDump
+	if err := json.Unmarshal(data, &dump); err != nil {
+		return fmt.Errorf("解析导入文件失败: %w", err)
+	}
+
+	version, err := schemaVersion(a.db)
+	if err != nil {
+		return err
+	}
+	if dump.SchemaVersion != version {
+		return fmt.Errorf("导入文件的数据库结构版本为 %d，当前数据库为 %d", dump.SchemaVersion, version)
+	}
+
+	schemas := make(map[string]*schema.Schema)
+	for _, m := range dataModels {
+		sch, err := parseSchema(a.db, m)
+		if err != nil {
+			return err
+		}
+
+		var count int64
+		if err := a.db.Unscoped().Model(m).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return fmt.Errorf("数据表 %s 不为空，只能向空数据库导入", sch.Table)
+		}
+		schemas[sch.Table] = sch
+	}
+
+	return a.db.Transaction(func(tx *gorm.DB) error {
+		for _, table := range dump.Tables {
+			sch, ok := schemas[table.Name]
+			if !ok {
+				return fmt.Errorf("未知的数据表: %s", table.Name)
+			}
+			if len(table.Rows) == 0 {
+				continue
+			}
+
+			records := reflect.MakeSlice(reflect.SliceOf(sch.ModelType), len(table.Rows), len(table.Rows))
+			for i, row := range table.Rows {
+				record := records.Index(i)
+				for _, field := range sch.Fields {
+					value, ok := row[field.DBName]
+					if field.DBName == "" || !ok {
+						continue
+					}
+					target := field.ReflectValueOf(context.Background(), record).Addr().Interface()
+					if err := json.Unmarshal(value, target); err != nil {
+						return fmt.Errorf("解析 %s.%s 失败: %w", table.Name, field.DBName, err)
+					}
+				}
+			}
+
+			if err := tx.Omit(clause.Associations).CreateInBatches(records.Interface(), 100).Error; err != nil {
+				return fmt.Errorf("导入 %s 失败: %w", table.Name, err)
+			}
+			if err := resetSequence(tx, sch); err != nil {
+				return err
+			}
+			a.printf("已导入 %s: %d 条", table.Name, len(table.Rows))
+		}
+		return nil
+	})
+}
+
+// parseSchema 解析模型对应的表结构
+func parseSchema(db *gorm.DB, m interface{}) (*schema.Schema, error) {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(m); err != nil {
+		return nil, err
+	}
+	return stmt.Schema, nil
+}
+
+// resetSequence 显式写入ID后，PostgreSQL的自增序列不会随之推进，需要手动设置到当前最大ID
+func resetSequence(tx *gorm.DB, sch *schema.Schema) error {
+	if tx.Dialector.Name() != "postgres" || sch.PrioritizedPrimaryField == nil || !sch.PrioritizedPrimaryField.AutoIncrement {
+		return nil
+	}
+
+	column := sch.PrioritizedPrimaryField.DBName
+	return tx.Exec(fmt.Sprintf("SELECT setval(pg_get_serial_sequence('%s', '%s'), (SELECT MAX(%s) FROM %s))",
+		sch.Table, column, column, sch.Table)).Error
+}
+
+// schemaVersion 返回数据库已执行的最新迁移版本
+func schemaVersion(db *gorm.DB) (uint, error) {
+	statuses, err := database.GetMigrationStatus(db)
+	if err != nil {
+		return 0, err
+	}
+
+	var version uint
+	for _, status := range statuses {
+		if status.AppliedAt != nil && status.Version > version {
+			version = status.Version
+		}
+	}
+	if version == 0 {
+		return 0, errors.New("数据库尚未执行迁移")
+	}
+	return version, nil
+}
@@ -0,0 +1,125 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/tksky1/glimgate/internal/model"
+	"github.com/tksky1/glimgate/internal/repository"
+	"github.com/tksky1/glimgate/internal/service"
+)
+
+// runManager 执行manager子命令
+func (a *App) runManager(args []string) error {
+	if len(args) < 2 {
+		return errors.New("用法: glimgatectl manager list <方向ID> | manager add|remove <方向ID> <用户名>")
+	}
+
+	directionID, err := strconv.ParseUint(args[1], 10, 32)
+	if err != nil {
+		return fmt.Errorf("方向ID无效: %s", args[1])
+	}
+
+	switch args[0] {
+	case "list":
+		return a.ListManagers(uint(directionID))
+	case "add", "remove":
+		if len(args) != 3 {
+			return fmt.Errorf("用法: glimgatectl manager %s <方向ID> <用户名>", args[0])
+		}
+		return a.SetManager(uint(directionID), args[2], args[0] == "add")
+	default:
+		return fmt.Errorf("未知的manager命令: %s", args[0])
+	}
+}
+
+// ListManagers 输出方向负责人
+func (a *App) ListManagers(directionID uint) error {
+	direction, err := a.findDirection(a.repos, directionID)
+	if err != nil {
+		return err
+	}
+
+	a.printf("方向 %s (ID: %d) 的负责人:", direction.Name, direction.ID)
+	if len(direction.Managers) == 0 {
+		a.printf("  (无)")
+	}
+	for _, manager := range direction.Managers {
+		a.printf("  %s (%s, ID: %d)", manager.Username, manager.Nickname, manager.ID)
+	}
+	return nil
+}
+
+// SetManager 添加或移除方向负责人
+func (a *App) SetManager(directionID uint, username string, add bool) error {
+	changed := false
+	err := a.repos.Transaction(func(tx *repository.Repositories) error {
+		direction, err := a.findDirection(tx, directionID)
+		if err != nil {
+			return err
+		}
+		user, err := tx.Users.FindByUsername(username)
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return errors.New("用户不存在")
+			}
+			return err
+		}
+
+		var managerIDs []uint
+		found := false
+		for _, manager := range direction.Managers {
+			if manager.ID == user.ID {
+				found = true
+				if add {
+					managerIDs = append(managerIDs, manager.ID)
+				}
+				continue
+			}
+			managerIDs = append(managerIDs, manager.ID)
+		}
+		if found == add {
+			return nil
+		}
+		if add {
+			managerIDs = append(managerIDs, user.ID)
+		}
+		changed = true
+
+		before := *direction
+		if err := tx.Directions.ReplaceManagers(direction, managerIDs); err != nil {
+			return err
+		}
+		after, err := tx.Directions.FindByID(direction.ID, "Managers")
+		if err != nil {
+			return err
+		}
+		return a.auditService.Record(tx.AuditLogs, operator, service.AuditActionUpdate, service.AuditEntityDirection, direction.ID, before, after)
+	})
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case !changed:
+		a.printf("负责人未变化")
+	case add:
+		a.printf("已将 %s 设为方向 %d 的负责人", username, directionID)
+	default:
+		a.printf("已移除 %s 在方向 %d 的负责人身份", username, directionID)
+	}
+	return nil
+}
+
+// findDirection 查找方向并加载负责人
+func (a *App) findDirection(repos *repository.Repositories, directionID uint) (*model.Direction, error) {
+	direction, err := repos.Directions.FindByID(directionID, "Managers")
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, errors.New("方向不存在")
+		}
+		return nil, err
+	}
+	return direction, nil
+}
@@ -0,0 +1,74 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/tksky1/glimgate/pkg/database"
+)
+
+// Migrate 执行数据库迁移命令：migrate up|down [steps]|status
+func (a *App) Migrate(args []string) error {
+	if len(args) == 0 {
+		return errors.New("用法: migrate up|down [steps]|status")
+	}
+
+	switch args[0] {
+	case "up":
+		done, err := database.MigrateUp(a.db)
+		for _, m := range done {
+			a.printf("已执行迁移 %d: %s", m.Version, m.Description)
+		}
+		if err != nil {
+			return err
+		}
+		if len(done) == 0 {
+			a.printf("数据库结构已是最新版本")
+		}
+		return nil
+
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n < 1 {
+				return fmt.Errorf("回滚步数无效: %s", args[1])
+			}
+			steps = n
+		}
+
+		done, err := database.MigrateDown(a.db, steps)
+		for _, m := range done {
+			a.printf("已回滚迁移 %d: %s", m.Version, m.Description)
+		}
+		if err != nil {
+			return err
+		}
+		if len(done) == 0 {
+			a.printf("没有可回滚的迁移")
+		}
+		return nil
+
+	case "status":
+		statuses, err := database.GetMigrationStatus(a.db)
+		if err != nil {
+			return err
+		}
+
+		w := tabwriter.NewWriter(a.out, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "版本\t说明\t执行时间")
+		for _, status := range statuses {
+			appliedAt := "未执行"
+			if status.AppliedAt != nil {
+				appliedAt = status.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Fprintf(w, "%d\t%s\t%s\n", status.Version, status.Description, appliedAt)
+		}
+		return w.Flush()
+
+	default:
+		return fmt.Errorf("未知的迁移命令: %s", args[0])
+	}
+}
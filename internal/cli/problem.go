@@ -0,0 +1,135 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/tksky1/glimgate/internal/service"
+	"github.com/tksky1/glimgate/pkg/bundle"
+)
+
+// runProblem 执行problem子命令
+func (a *App) runProblem(args []string) error {
+	if len(args) == 0 {
+		return errors.New("用法: glimgatectl problem export <题目ID> <目录> | problem import [-dry-run] <目录|zip|yaml> | problem status [-at 发布时间] <题目ID> <状态>")
+	}
+
+	switch args[0] {
+	case "export":
+		if len(args) != 3 {
+			return errors.New("用法: glimgatectl problem export <题目ID> <目录>")
+		}
+		problemID, err := strconv.ParseUint(args[1], 10, 32)
+		if err != nil {
+			return fmt.Errorf("题目ID无效: %s", args[1])
+		}
+		return a.ExportProblem(uint(problemID), args[2])
+
+	case "import":
+		fs := flag.NewFlagSet("problem import", flag.ContinueOnError)
+		fs.SetOutput(a.out)
+		dryRun := fs.Bool("dry-run", false, "只输出差异，不写入数据库")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+		if fs.NArg() != 1 {
+			return errors.New("用法: glimgatectl problem import [-dry-run] <目录|zip|yaml>")
+		}
+		return a.ImportProblem(fs.Arg(0), *dryRun)
+
+	case "status":
+		fs := flag.NewFlagSet("problem status", flag.ContinueOnError)
+		fs.SetOutput(a.out)
+		at := fs.String("at", "", "定时发布时间(RFC3339格式，如2024-09-01T09:00:00+08:00)，状态为scheduled时必填")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+		if fs.NArg() != 2 {
+			return errors.New("用法: glimgatectl problem status [-at 发布时间] <题目ID> <状态>")
+		}
+		problemID, err := strconv.ParseUint(fs.Arg(0), 10, 32)
+		if err != nil {
+			return fmt.Errorf("题目ID无效: %s", fs.Arg(0))
+		}
+		req := &service.UpdateProblemStatusRequest{Status: fs.Arg(1)}
+		if *at != "" {
+			publishAt, err := time.Parse(time.RFC3339, *at)
+			if err != nil {
+				return fmt.Errorf("发布时间格式错误: %s", *at)
+			}
+			req.PublishAt = &publishAt
+		}
+		return a.SetProblemStatus(uint(problemID), req)
+
+	default:
+		return fmt.Errorf("未知的problem命令: %s", args[0])
+	}
+}
+
+// ExportProblem 将题目导出为题目包目录，目录中已有的problem.yaml和attachments目录会被覆盖
+func (a *App) ExportProblem(problemID uint, dir string) error {
+	b, err := a.problemService.ExportBundle(problemID)
+	if err != nil {
+		return err
+	}
+	if err := bundle.WriteDir(dir, b); err != nil {
+		return err
+	}
+
+	a.printf("已导出题目 %d (%s) 到 %s", problemID, b.Manifest.Slug, dir)
+	return nil
+}
+
+// SetProblemStatus 修改题目可见状态，定时发布由运行中的服务在发布时间到达后执行
+func (a *App) SetProblemStatus(problemID uint, req *service.UpdateProblemStatusRequest) error {
+	problem, err := a.problemService.UpdateProblemStatus(operator, problemID, req)
+	if err != nil {
+		return err
+	}
+
+	if problem.Status == service.ProblemStatusScheduled {
+		a.printf("题目 %d (%s) 将于 %s 发布", problem.ID, problem.Title, problem.PublishAt.Format(time.RFC3339))
+		return nil
+	}
+	a.printf("题目 %d (%s) 的状态已修改为 %s", problem.ID, problem.Title, problem.Status)
+	return nil
+}
+
+// ImportProblem 导入题目包并输出与已有题目的差异
+func (a *App) ImportProblem(path string, dryRun bool) error {
+	b, err := bundle.ReadPath(path)
+	if err != nil {
+		return err
+	}
+
+	result, err := a.problemService.ImportBundle(operator, b, dryRun)
+	if err != nil {
+		return err
+	}
+
+	for _, change := range result.Changes {
+		switch change.Action {
+		case service.BundleChangeCreate:
+			a.printf("+ %s: %s", change.Path, change.After)
+		case service.BundleChangeDelete:
+			a.printf("- %s: %s", change.Path, change.Before)
+		default:
+			a.printf("~ %s: %q -> %q", change.Path, change.Before, change.After)
+		}
+	}
+
+	switch {
+	case result.Action == service.BundleActionUnchanged:
+		a.printf("题目 %s 没有变化", result.Slug)
+	case dryRun:
+		a.printf("试运行，未写入数据库")
+	case result.Action == service.BundleActionCreate:
+		a.printf("已创建题目 %s (ID: %d)，题目为草稿，发布后选手可见", result.Slug, result.ProblemID)
+	default:
+		a.printf("已更新题目 %s (ID: %d)", result.Slug, result.ProblemID)
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"text/tabwriter"
+
+	"github.com/tksky1/glimgate/internal/service"
+)
+
+// runRanking 执行ranking子命令
+func (a *App) runRanking(args []string) error {
+	if len(args) == 0 || args[0] != "recompute" {
+		return errors.New("用法: glimgatectl ranking recompute [-direction 方向ID] [-limit 数量]")
+	}
+
+	fs := flag.NewFlagSet("ranking recompute", flag.ContinueOnError)
+	fs.SetOutput(a.out)
+	directionID := fs.Uint("direction", 0, "方向ID，0表示全部方向")
+	limit := fs.Int("limit", 50, "输出的排名数量")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	return a.RecomputeRanking(*directionID, *limit)
+}
+
+// RecomputeRanking 校正评分的归属用户后输出排行榜
+// 排行榜实时按评分统计，这里修正的是评分上冗余的user_id
+func (a *App) RecomputeRanking(directionID uint, limit int) error {
+	fixed, err := a.repos.Scores.SyncUserIDs()
+	if err != nil {
+		return err
+	}
+	a.printf("已校正 %d 条评分的归属用户", fixed)
+
+	rows, err := a.repos.Scores.Ranking(directionID, limit, service.TeamScoreSplit())
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(a.out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "排名\t用户ID\t昵称\t总分")
+	for i, row := range rows {
+		fmt.Fprintf(w, "%d\t%d\t%s\t%d\n", i+1, row.UserID, row.Nickname, row.Score)
+	}
+	return w.Flush()
+}
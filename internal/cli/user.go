@@ -0,0 +1,250 @@
+package cli
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/tksky1/glimgate/internal/model"
+	"github.com/tksky1/glimgate/internal/repository"
+	"github.com/tksky1/glimgate/internal/service"
+	"github.com/tksky1/glimgate/pkg/utils"
+	"golang.org/x/term"
+)
+
+// minPasswordLength 密码最小长度，与注册接口一致
+const minPasswordLength = 6
+
+// runAdmin 执行admin子命令
+func (a *App) runAdmin(args []string) error {
+	if len(args) == 0 {
+		return errors.New("用法: glimgatectl admin create|reset-password ...")
+	}
+
+	switch args[0] {
+	case "create":
+		fs := flag.NewFlagSet("admin create", flag.ContinueOnError)
+		fs.SetOutput(a.out)
+		generate := fs.Bool("generate", false, "生成随机密码")
+		nickname := fs.String("nickname", "系统管理员", "昵称")
+		email := fs.String("email", "", "邮箱")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+		if fs.NArg() != 1 {
+			return errors.New("用法: glimgatectl admin create [-generate] [-nickname 昵称] [-email 邮箱] <用户名>")
+		}
+		return a.CreateAdmin(fs.Arg(0), *nickname, *email, *generate)
+
+	case "reset-password":
+		fs := flag.NewFlagSet("admin reset-password", flag.ContinueOnError)
+		fs.SetOutput(a.out)
+		generate := fs.Bool("generate", false, "生成随机密码")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+		if fs.NArg() != 1 {
+			return errors.New("用法: glimgatectl admin reset-password [-generate] <用户名>")
+		}
+		return a.ResetPassword(fs.Arg(0), *generate)
+
+	default:
+		return fmt.Errorf("未知的admin命令: %s", args[0])
+	}
+}
+
+// runUser 执行user子命令
+func (a *App) runUser(args []string) error {
+	if len(args) != 2 {
+		return errors.New("用法: glimgatectl user promote|demote <用户名>")
+	}
+
+	switch args[0] {
+	case "promote":
+		return a.SetAdmin(args[1], true)
+	case "demote":
+		return a.SetAdmin(args[1], false)
+	default:
+		return fmt.Errorf("未知的user命令: %s", args[0])
+	}
+}
+
+// CreateAdmin 创建管理员账户，generate为true时生成随机密码，否则提示输入
+func (a *App) CreateAdmin(username, nickname, email string, generate bool) error {
+	if _, err := a.repos.Users.FindByUsername(username); err == nil {
+		return errors.New("用户名已存在")
+	} else if !errors.Is(err, repository.ErrNotFound) {
+		return err
+	}
+
+	password, err := a.obtainPassword(generate)
+	if err != nil {
+		return err
+	}
+	hashedPassword, err := utils.HashPassword(password)
+	if err != nil {
+		return err
+	}
+
+	user := &model.User{
+		Username:  username,
+		Password:  hashedPassword,
+		Nickname:  nickname,
+		RealName:  "管理员",
+		College:   "系统",
+		StudentID: username,
+		Email:     email,
+		IsAdmin:   true,
+	}
+	err = a.repos.Transaction(func(tx *repository.Repositories) error {
+		if err := tx.Users.Create(user); err != nil {
+			return err
+		}
+		return a.auditService.Record(tx.AuditLogs, operator, service.AuditActionCreate, service.AuditEntityUser, user.ID, nil, user)
+	})
+	if err != nil {
+		return err
+	}
+
+	a.printf("管理员账户 %s 创建成功 (ID: %d)", username, user.ID)
+	if generate {
+		a.printf("密码: %s", password)
+	}
+	return nil
+}
+
+// ResetPassword 重置用户密码，generate为true时生成随机密码，否则提示输入
+func (a *App) ResetPassword(username string, generate bool) error {
+	user, err := a.findUser(username)
+	if err != nil {
+		return err
+	}
+
+	password, err := a.obtainPassword(generate)
+	if err != nil {
+		return err
+	}
+	hashedPassword, err := utils.HashPassword(password)
+	if err != nil {
+		return err
+	}
+
+	err = a.repos.Transaction(func(tx *repository.Repositories) error {
+		before := *user
+		if err := tx.Users.Update(user, map[string]interface{}{"password": hashedPassword}); err != nil {
+			return err
+		}
+		return a.auditService.Record(tx.AuditLogs, operator, service.AuditActionUpdate, service.AuditEntityUser, user.ID, before, user)
+	})
+	if err != nil {
+		return err
+	}
+
+	a.printf("用户 %s 的密码已重置", username)
+	if generate {
+		a.printf("密码: %s", password)
+	}
+	return nil
+}
+
+// SetAdmin 设置或取消用户的管理员身份，不允许取消最后一个管理员
+func (a *App) SetAdmin(username string, isAdmin bool) error {
+	changed := false
+	err := a.repos.Transaction(func(tx *repository.Repositories) error {
+		user, err := tx.Users.FindByUsername(username)
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return errors.New("用户不存在")
+			}
+			return err
+		}
+		if user.IsAdmin == isAdmin {
+			return nil
+		}
+		changed = true
+
+		if !isAdmin {
+			count, err := tx.Users.CountAdmins()
+			if err != nil {
+				return err
+			}
+			if count <= 1 {
+				return errors.New("不能取消最后一个管理员")
+			}
+		}
+
+		before := *user
+		if err := tx.Users.Update(user, map[string]interface{}{"is_admin": isAdmin}); err != nil {
+			return err
+		}
+		return a.auditService.Record(tx.AuditLogs, operator, service.AuditActionUpdate, service.AuditEntityUser, user.ID, before, user)
+	})
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case !changed:
+		a.printf("用户 %s 的管理员身份未变化", username)
+	case isAdmin:
+		a.printf("用户 %s 已设为管理员", username)
+	default:
+		a.printf("用户 %s 已取消管理员", username)
+	}
+	return nil
+}
+
+// findUser 按用户名查找用户
+func (a *App) findUser(username string) (*model.User, error) {
+	user, err := a.repos.Users.FindByUsername(username)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, errors.New("用户不存在")
+		}
+		return nil, err
+	}
+	return user, nil
+}
+
+// obtainPassword 生成随机密码，或从终端提示输入两次，非终端时从标准输入读取一行
+func (a *App) obtainPassword(generate bool) (string, error) {
+	if generate {
+		return utils.GeneratePassword(16)
+	}
+
+	var password string
+	fd := int(os.Stdin.Fd())
+	if term.IsTerminal(fd) {
+		fmt.Fprint(os.Stderr, "输入密码: ")
+		first, err := term.ReadPassword(fd)
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprint(os.Stderr, "再次输入密码: ")
+		second, err := term.ReadPassword(fd)
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			return "", err
+		}
+		if string(first) != string(second) {
+			return "", errors.New("两次输入的密码不一致")
+		}
+		password = string(first)
+	} else {
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return "", err
+		}
+		password = strings.TrimRight(line, "\r\n")
+	}
+
+	if len(password) < minPasswordLength {
+		return "", fmt.Errorf("密码长度不能少于%d位", minPasswordLength)
+	}
+	return password, nil
+}
@@ -0,0 +1,23 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/tksky1/glimgate/pkg/utils"
+)
+
+// RequestIDHeader 请求ID请求头
+const RequestIDHeader = "X-Request-ID"
+
+// RequestIDMiddleware 请求ID中间件，沿用客户端传入的请求ID或生成新的ID
+func RequestIDMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(RequestIDHeader)
+		if requestID == "" || len(requestID) > 64 {
+			requestID, _ = utils.RandomHex(16)
+		}
+
+		c.Set("request_id", requestID)
+		c.Header(RequestIDHeader, requestID)
+		c.Next()
+	}
+}
@@ -1,6 +1,7 @@
 package model
 
 import (
+	"encoding/json"
 	"time"
 
 	"gorm.io/gorm"
@@ -13,15 +14,15 @@ type User struct {
 	UpdatedAt time.Time      `json:"updated_at"`
 	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
 
-	Username string `json:"username" gorm:"uniqueIndex;size:50;not null" binding:"required" example:"user123"`
-	Password string `json:"-" gorm:"size:255;not null"`
-	Nickname string `json:"nickname" gorm:"size:50;not null" binding:"required" example:"小明"`
-	RealName string `json:"real_name" gorm:"size:50;not null" binding:"required" example:"张三"`
-	College  string `json:"college" gorm:"size:100;not null" binding:"required" example:"计算机学院"`
+	Username  string `json:"username" gorm:"uniqueIndex;size:50;not null" binding:"required" example:"user123"`
+	Password  string `json:"-" gorm:"size:255;not null"`
+	Nickname  string `json:"nickname" gorm:"size:50;not null" binding:"required" example:"小明"`
+	RealName  string `json:"real_name" gorm:"size:50;not null" binding:"required" example:"张三"`
+	College   string `json:"college" gorm:"size:100;not null" binding:"required" example:"计算机学院"`
 	StudentID string `json:"student_id" gorm:"size:20;not null" binding:"required" example:"2021001001"`
-	QQ       string `json:"qq" gorm:"size:20" example:"123456789"`
-	Email    string `json:"email" gorm:"size:100" example:"user@example.com"`
-	IsAdmin  bool   `json:"is_admin" gorm:"default:false"`
+	QQ        string `json:"qq" gorm:"size:20" example:"123456789"`
+	Email     string `json:"email" gorm:"size:100" example:"user@example.com"`
+	IsAdmin   bool   `json:"is_admin" gorm:"default:false"`
 }
 
 // Direction 方向模型
@@ -32,7 +33,10 @@ type Direction struct {
 	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
 
 	Name        string `json:"name" gorm:"size:100;not null" binding:"required" example:"前端开发"`
-	Description string `json:"description" gorm:"type:text" example:"负责前端页面开发和用户交互"`
+	Description string `json:"description" gorm:"type:text" example:"负责前端页面开发和用户交互"` // Markdown格式
+
+	// 描述渲染并清理后的HTML，不入库
+	DescriptionHTML string `json:"description_html,omitempty" gorm:"-" example:"<p>负责前端页面开发和用户交互</p>\n"`
 
 	// 关联关系
 	Managers []User    `json:"managers" gorm:"many2many:direction_managers;"`
@@ -47,13 +51,134 @@ type Problem struct {
 	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
 
 	Title       string `json:"title" gorm:"size:200;not null" binding:"required" example:"实现一个简单的计算器"`
-	Description string `json:"description" gorm:"type:text" binding:"required" example:"使用HTML、CSS、JavaScript实现一个基本的计算器功能"`
+	Description string `json:"description" gorm:"type:text" binding:"required" example:"使用HTML、CSS、JavaScript实现一个基本的计算器功能"` // Markdown格式
 	DirectionID uint   `json:"direction_id" binding:"required" example:"1"`
+	Slug        string `json:"slug" gorm:"size:100;not null;default:'';index" example:"calculator"` // 题目包标识，同一方向内唯一，导入时据此匹配已有题目
+
+	// 可见状态：draft草稿、scheduled定时发布、published已发布、archived已归档，只有已发布和已归档的题目对选手可见
+	Status      string     `json:"status" gorm:"size:20;not null;default:'published';index" example:"published"`
+	PublishAt   *time.Time `json:"publish_at" example:"2024-09-01T09:00:00+08:00"`   // 定时发布时间，仅scheduled状态有效
+	PublishedAt *time.Time `json:"published_at" example:"2024-09-01T09:00:00+08:00"` // 首次发布时间
+
+	Difficulty       int `json:"difficulty" gorm:"not null;default:0;index" example:"3"`    // 难度等级1-5，0表示未设置
+	EstimatedMinutes int `json:"estimated_minutes" gorm:"not null;default:0" example:"120"` // 预计用时（分钟），0表示未设置
+
+	StatementUpdatedAt *time.Time `json:"statement_updated_at" example:"2024-09-10T12:00:00+08:00"` // 最近一次重要修改的时间
+
+	TeamSize int `json:"team_size" gorm:"not null;default:0" example:"4"` // 队伍人数上限，0表示个人提交
+
+	// 题面渲染并清理后的HTML，不入库，attachments/下的相对链接指向题目附件
+	DescriptionHTML string `json:"description_html,omitempty" gorm:"-" example:"<p>使用HTML、CSS、JavaScript实现一个基本的计算器功能</p>\n"`
+
+	// 关联关系
+	Direction        Direction             `json:"direction,omitempty"`
+	SubmissionPoints []SubmissionPoint     `json:"submission_points,omitempty"`
+	Submissions      []Submission          `json:"submissions,omitempty"`
+	Attachments      []ProblemAttachment   `json:"attachments,omitempty"`
+	Tags             []ProblemTag          `json:"tags,omitempty" swaggertype:"array,string" example:"算法,动态规划"`
+	Prerequisites    []ProblemPrerequisite `json:"prerequisites,omitempty" gorm:"foreignKey:ProblemID"`
+
+	// 当前用户是否尚未满足解锁条件，不入库；未解锁时不返回题面、提交点和附件
+	Locked bool `json:"locked,omitempty" gorm:"-" example:"false"`
+	// 当前用户最后一次提交之后题目是否有重要修改，不入库
+	StatementUpdated bool `json:"statement_updated,omitempty" gorm:"-" example:"true"`
+}
+
+// ProblemPrerequisite 题目解锁条件，题目的全部条件都满足后选手才能查看题面和提交
+type ProblemPrerequisite struct {
+	ID        uint      `json:"id" gorm:"primarykey"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	ProblemID         uint   `json:"problem_id" gorm:"not null;uniqueIndex:idx_prerequisites_problem_required" example:"2"`
+	RequiredProblemID uint   `json:"required_problem_id" gorm:"not null;uniqueIndex:idx_prerequisites_problem_required;index" example:"1"`
+	Type              string `json:"type" gorm:"size:20;not null" example:"score"`     // submitted在前置题目有提交，score在前置题目的总分不低于MinScore
+	MinScore          int    `json:"min_score" gorm:"not null;default:0" example:"60"` // 仅score类型有效
+}
+
+// ProblemHint 题目提示，选手按顺序主动解锁，解锁后该题的得分扣除提示的惩罚分
+type ProblemHint struct {
+	ID        uint      `json:"id" gorm:"primarykey"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	ProblemID   uint   `json:"problem_id" gorm:"not null;index" example:"1"`
+	Position    int    `json:"position" gorm:"not null;default:0" example:"1"` // 提示顺序，需按从小到大的顺序解锁
+	Content     string `json:"content,omitempty" gorm:"type:text;not null" example:"可以先用栈把中缀表达式转换为后缀表达式"`
+	ContentHTML string `json:"content_html,omitempty" gorm:"-" example:"<p>可以先用栈把中缀表达式转换为后缀表达式</p>\n"`
+	Penalty     int    `json:"penalty" gorm:"not null;default:0" example:"10"`
+
+	// 当前用户是否已解锁，不入库；未解锁时不返回提示内容
+	Unlocked bool `json:"unlocked,omitempty" gorm:"-" example:"true"`
+}
+
+// HintUnlock 选手解锁提示的记录，惩罚分在解锁时确定，之后修改提示不影响已解锁的记录
+type HintUnlock struct {
+	ID        uint      `json:"id" gorm:"primarykey"`
+	CreatedAt time.Time `json:"created_at"`
+
+	UserID    uint `json:"user_id" gorm:"not null;uniqueIndex:idx_hint_unlocks_user_hint" example:"2"`
+	HintID    uint `json:"hint_id" gorm:"not null;uniqueIndex:idx_hint_unlocks_user_hint;index" example:"1"`
+	ProblemID uint `json:"problem_id" gorm:"not null;index" example:"1"`
+	Penalty   int  `json:"penalty" gorm:"not null" example:"10"`
+}
+
+// ProblemRevision 题目修订记录，保存每次修改后的标题、题面和提交点设置，版本号从1开始递增
+type ProblemRevision struct {
+	ID        uint      `json:"id" gorm:"primarykey"`
+	CreatedAt time.Time `json:"created_at"`
+
+	ProblemID        uint   `json:"problem_id" gorm:"not null;uniqueIndex:idx_problem_revisions_problem_version" example:"1"`
+	Version          int    `json:"version" gorm:"not null;uniqueIndex:idx_problem_revisions_problem_version" example:"2"`
+	Title            string `json:"title" gorm:"size:200;not null" example:"实现一个简单的计算器"`
+	Description      string `json:"description,omitempty" gorm:"type:text" example:"使用HTML、CSS、JavaScript实现一个基本的计算器功能"`
+	SubmissionPoints string `json:"-" gorm:"type:text"` // 提交点设置的JSON快照
+
+	Significant  bool   `json:"significant" gorm:"not null;default:false" example:"true"` // 重要修改会通知已提交的选手
+	Note         string `json:"note" gorm:"size:500" example:"修正了输入格式的说明"`
+	OperatorID   uint   `json:"operator_id" example:"1"`
+	OperatorName string `json:"operator_name" gorm:"size:50" example:"admin"`
+
+	// 解析后的提交点设置，不入库
+	Points []RevisionPoint `json:"submission_points,omitempty" gorm:"-"`
+}
+
+// RevisionPoint 修订记录中的提交点设置
+type RevisionPoint struct {
+	Name     string     `json:"name" example:"源代码提交"`
+	MaxScore int        `json:"max_score" example:"100"`
+	Rubric   string     `json:"rubric,omitempty" example:"功能完整60分，代码规范20分，文档20分"`
+	Deadline *time.Time `json:"deadline,omitempty" example:"2024-10-01T23:59:59+08:00"`
+}
+
+// ProblemTag 题目标签，没有删除时间，题目移入回收站时保留，彻底删除题目时一并删除
+type ProblemTag struct {
+	ProblemID uint   `json:"-" gorm:"primaryKey"`
+	Name      string `json:"name" gorm:"primaryKey;size:50;index" example:"算法"`
+}
+
+// MarshalJSON 标签序列化为名称字符串
+func (t ProblemTag) MarshalJSON() ([]byte, error) {
+	return json.Marshal(t.Name)
+}
+
+// ProblemAttachment 题目附件，如初始代码、数据集和设计稿，文件内容保存在附件存储中
+type ProblemAttachment struct {
+	ID        uint           `json:"id" gorm:"primarykey"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+
+	ProblemID   uint   `json:"problem_id" gorm:"not null;index" example:"1"`
+	Filename    string `json:"filename" gorm:"size:255;not null" example:"starter.zip"`
+	ContentType string `json:"content_type" gorm:"size:100" example:"application/zip"`
+	Size        int64  `json:"size" example:"10240"`
+	SHA256      string `json:"sha256" gorm:"column:sha256;size:64;not null" example:"9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"`
+	StorageKey  string `json:"-" gorm:"size:255;not null"`
+	UploaderID  *uint  `json:"uploader_id" example:"1"`
 
 	// 关联关系
-	Direction        Direction        `json:"direction,omitempty"`
-	SubmissionPoints []SubmissionPoint `json:"submission_points,omitempty"`
-	Submissions      []Submission     `json:"submissions,omitempty"`
+	Problem *Problem `json:"problem,omitempty"`
 }
 
 // SubmissionPoint 提交点模型
@@ -67,12 +192,70 @@ type SubmissionPoint struct {
 	MaxScore  int    `json:"max_score" gorm:"not null" binding:"required,min=1" example:"100"`
 	ProblemID uint   `json:"problem_id" binding:"required" example:"1"`
 
+	Rubric         string     `json:"rubric" gorm:"type:text" example:"功能完整60分，代码规范20分，文档20分"`
+	Deadline       *time.Time `json:"deadline" example:"2024-10-01T23:59:59+08:00"`
+	ReminderSentAt *time.Time `json:"-"`
+
+	// 自动评测设置，仅type为auto时有效；限制为0时使用配置中的默认值，检查脚本不对选手公开
+	Type            string `json:"type" gorm:"size:20;not null;default:'manual'" example:"manual"` // manual人工评分，auto自动评测，git代码仓库
+	JudgeLanguage   string `json:"judge_language,omitempty" gorm:"size:20" example:"python"`
+	TimeLimitMS     int    `json:"time_limit_ms,omitempty" gorm:"not null;default:0" example:"1000"`
+	MemoryLimitMB   int    `json:"memory_limit_mb,omitempty" gorm:"not null;default:0" example:"256"`
+	Checker         string `json:"-" gorm:"type:text"`
+	CheckerLanguage string `json:"-" gorm:"size:20"`
+
 	// 关联关系
 	Problem     Problem      `json:"problem,omitempty"`
 	Submissions []Submission `json:"submissions,omitempty"`
 }
 
-// Submission 提交模型
+// JudgeCase 自动评测的测试点，输入和标准答案保存在文件存储中，不对选手公开
+type JudgeCase struct {
+	ID        uint      `json:"id" gorm:"primarykey"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	SubmissionPointID uint   `json:"submission_point_id" gorm:"not null;index" example:"1"`
+	Position          int    `json:"position" gorm:"not null;default:0" example:"1"` // 测试点顺序
+	Weight            int    `json:"weight" gorm:"not null;default:1" example:"1"`   // 得分权重，得分按通过的权重占比折算
+	InputSize         int64  `json:"input_size" example:"128"`
+	OutputSize        int64  `json:"output_size" example:"16"`
+	InputKey          string `json:"-" gorm:"size:255;not null"`
+	OutputKey         string `json:"-" gorm:"size:255;not null"`
+}
+
+// JudgeRun 提交的自动评测记录，每个提交只有一条，重新提交或重新评测时重置为等待评测
+type JudgeRun struct {
+	ID        uint      `json:"id" gorm:"primarykey"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	SubmissionID uint   `json:"submission_id" gorm:"not null;uniqueIndex" example:"1"`
+	Status       string `json:"status" gorm:"size:20;not null;index" example:"finished"` // pending/running/finished/failed
+	Attempt      int    `json:"-" gorm:"not null;default:0"`                             // 每次重置加1，评测结束时据此丢弃过期的结果
+	Verdict      string `json:"verdict" gorm:"size:30" example:"wrong_answer"`           // 评测结束时为第一个未通过测试点的结果，全部通过时为accepted
+	Score        int    `json:"score" example:"60"`
+	PassedCount  int    `json:"passed_count" example:"3"`
+	CaseCount    int    `json:"case_count" example:"5"`
+	Message      string `json:"message" gorm:"type:text" example:"main.cpp:3:1: error: expected ';'"` // 编译错误输出或评测失败的原因
+	Results      string `json:"-" gorm:"type:text"`                                                   // 各测试点结果的JSON
+
+	StartedAt  *time.Time `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at"`
+
+	// 各测试点的结果，由Results解析，不入库
+	Cases []JudgeCaseResult `json:"cases" gorm:"-"`
+}
+
+// JudgeCaseResult 单个测试点的评测结果
+type JudgeCaseResult struct {
+	Position int    `json:"position" example:"1"` // 第几个测试点，从1开始
+	Verdict  string `json:"verdict" example:"accepted"`
+	TimeMS   int64  `json:"time_ms" example:"12"`
+	MemoryKB int64  `json:"memory_kb" example:"8272"`
+}
+
+// Submission 提交模型，每个用户在每个提交点只有一条提交（含已删除的记录）
 type Submission struct {
 	ID        uint           `json:"id" gorm:"primarykey"`
 	CreatedAt time.Time      `json:"created_at"`
@@ -80,18 +263,151 @@ type Submission struct {
 	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
 
 	Content           string `json:"content" gorm:"type:text" binding:"required" example:"https://github.com/user/project"`
-	UserID            uint   `json:"user_id" binding:"required" example:"1"`
+	UserID            uint   `json:"user_id" gorm:"uniqueIndex:idx_submissions_user_point" binding:"required" example:"1"` // 组队题目为首次提交的队员
 	ProblemID         uint   `json:"problem_id" binding:"required" example:"1"`
-	SubmissionPointID uint   `json:"submission_point_id" binding:"required" example:"1"`
+	SubmissionPointID uint   `json:"submission_point_id" gorm:"uniqueIndex:idx_submissions_user_point;uniqueIndex:idx_submissions_team_point" binding:"required" example:"1"`
+	TeamID            *uint  `json:"team_id" gorm:"uniqueIndex:idx_submissions_team_point" example:"2"` // 组队题目的提交所属队伍，个人提交为空
+
+	// 最近一次相似度检测中与同一提交点其他提交的最高相似度，及是否达到可疑阈值，只对评分者公开
+	SimilarityScore   float64 `json:"-" gorm:"not null;default:0"`
+	SimilarityFlagged bool    `json:"-" gorm:"not null;default:false"`
+
+	// 相似度标记，不入库，仅待评分列表返回
+	Similarity *SimilarityFlag `json:"similarity,omitempty" gorm:"-"`
 
 	// 关联关系
 	User            User            `json:"user,omitempty"`
 	Problem         Problem         `json:"problem,omitempty"`
 	SubmissionPoint SubmissionPoint `json:"submission_point,omitempty"`
 	Scores          []Score         `json:"scores,omitempty"`
+	CheckRun        *CheckRun       `json:"check_run,omitempty"` // 代码仓库提交点的检查报告，仅待评分列表加载
+	Team            *Team           `json:"team,omitempty"`
+}
+
+// Team 组队题目的队伍，每位用户在一道题目中最多加入一支队伍
+type Team struct {
+	ID        uint      `json:"id" gorm:"primarykey"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	ProblemID uint   `json:"problem_id" gorm:"not null;uniqueIndex:idx_teams_problem_name" example:"1"`
+	Name      string `json:"name" gorm:"size:50;not null;uniqueIndex:idx_teams_problem_name" example:"第一小队"`
+	LeaderID  uint   `json:"leader_id" gorm:"not null;index" example:"5"`
+
+	Leader  *User        `json:"leader,omitempty"`
+	Members []TeamMember `json:"members,omitempty"`
+	Invites []TeamInvite `json:"invites,omitempty"` // 待处理的邀请，仅队伍详情返回
+}
+
+// TeamMember 队伍成员，包括队长
+type TeamMember struct {
+	ID        uint      `json:"id" gorm:"primarykey"`
+	CreatedAt time.Time `json:"created_at"` // 加入时间
+
+	TeamID    uint `json:"team_id" gorm:"not null;index" example:"2"`
+	ProblemID uint `json:"problem_id" gorm:"not null;uniqueIndex:idx_team_members_problem_user" example:"1"` // 与队伍的题目相同，保证每位用户在一道题目中只加入一支队伍
+	UserID    uint `json:"user_id" gorm:"not null;uniqueIndex:idx_team_members_problem_user;index" example:"5"`
+
+	User User `json:"user,omitempty"`
+}
+
+// TeamInvite 待处理的入队邀请，接受或拒绝后删除
+type TeamInvite struct {
+	ID        uint      `json:"id" gorm:"primarykey"`
+	CreatedAt time.Time `json:"created_at"`
+
+	TeamID    uint `json:"team_id" gorm:"not null;uniqueIndex:idx_team_invites_team_user" example:"2"`
+	UserID    uint `json:"user_id" gorm:"not null;uniqueIndex:idx_team_invites_team_user;index" example:"6"` // 被邀请的用户
+	InviterID uint `json:"inviter_id" gorm:"not null" example:"5"`
+
+	Team    *Team `json:"team,omitempty"`
+	User    User  `json:"user,omitempty"`
+	Inviter User  `json:"inviter,omitempty"`
+}
+
+// SimilarityFlag 提交的相似度标记
+type SimilarityFlag struct {
+	Score   float64 `json:"score" example:"0.92"`   // 与同一提交点其他提交的最高相似度，0-1
+	Flagged bool    `json:"flagged" example:"true"` // 最高相似度达到可疑阈值
+}
+
+// SimilarityReport 同一提交点两个提交的相似度，相似度检测时整体替换；SubmissionAID小于SubmissionBID
+type SimilarityReport struct {
+	ID        uint      `json:"id" gorm:"primarykey"`
+	CreatedAt time.Time `json:"created_at"`
+
+	SubmissionPointID uint    `json:"submission_point_id" gorm:"not null;index" example:"1"`
+	SubmissionAID     uint    `json:"submission_a_id" gorm:"not null;uniqueIndex:idx_similarity_reports_pair" example:"3"`
+	SubmissionBID     uint    `json:"submission_b_id" gorm:"not null;uniqueIndex:idx_similarity_reports_pair;index" example:"7"`
+	Method            string  `json:"method" gorm:"size:20;not null" example:"code"` // code代码指纹，text文本指纹，link相同链接，repository代码仓库
+	Score             float64 `json:"score" gorm:"not null;index" example:"0.92"`    // 0-1
+	Detail            string  `json:"detail" gorm:"size:500" example:"共同指纹46个"`
+
+	SubmissionA *Submission `json:"submission_a,omitempty" gorm:"foreignKey:SubmissionAID"`
+	SubmissionB *Submission `json:"submission_b,omitempty" gorm:"foreignKey:SubmissionBID"`
+}
+
+// CheckStep 代码仓库提交点的检查步骤，按顺序在克隆的仓库中执行
+type CheckStep struct {
+	ID        uint      `json:"id" gorm:"primarykey"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	SubmissionPointID uint   `json:"submission_point_id" gorm:"not null;index" example:"1"`
+	Position          int    `json:"position" gorm:"not null;default:0" example:"1"`
+	Name              string `json:"name" gorm:"size:100;not null" example:"构建"`
+	Kind              string `json:"kind" gorm:"size:20;not null" example:"build"`            // file_exists文件存在，build构建，test测试，lint代码检查
+	Path              string `json:"path,omitempty" gorm:"size:255" example:"README.md"`      // file_exists检查的路径，支持通配符
+	Command           string `json:"command,omitempty" gorm:"type:text" example:"make build"` // build、test、lint在仓库根目录用/bin/sh执行的命令
+	TimeoutSeconds    int    `json:"timeout_seconds" gorm:"not null;default:0" example:"300"` // 为0时使用配置中的默认值
+	// 通过后建议给出的分数和对应的评分项，用于预填评分
+	Points    int    `json:"points" gorm:"not null;default:0" example:"20"`
+	Criterion string `json:"criterion" gorm:"size:200" example:"项目能够成功构建"`
 }
 
-// Score 评分模型
+// CheckRun 提交的代码仓库检查报告，每个提交只有一条，重新提交或重新检查时重置为等待检查
+type CheckRun struct {
+	ID        uint      `json:"id" gorm:"primarykey"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	SubmissionID uint   `json:"submission_id" gorm:"not null;uniqueIndex" example:"1"`
+	Status       string `json:"status" gorm:"size:20;not null;index" example:"finished"`                  // pending/running/finished/failed
+	Attempt      int    `json:"-" gorm:"not null;default:0"`                                              // 每次重置加1，检查结束时据此丢弃过期的结果
+	Commit       string `json:"commit" gorm:"size:64" example:"3f2a9c1e0b7d4f8a6e5c2b1a0d9f8e7c6b5a4f3e"` // 实际检查的提交
+	PassedCount  int    `json:"passed_count" example:"3"`
+	StepCount    int    `json:"step_count" example:"4"`
+	// 按通过步骤的分数汇总的建议分数（不超过提交点满分）和评语，评分时可据此预填
+	SuggestedScore   int    `json:"suggested_score" example:"60"`
+	SuggestedComment string `json:"suggested_comment" gorm:"type:text" example:"[通过] 项目能够成功构建 +20"`
+	Message          string `json:"message" gorm:"type:text" example:"克隆仓库失败，请确认仓库地址正确且可以公开访问"` // 检查失败的原因
+	Results          string `json:"-" gorm:"type:text"`                                         // 各步骤结果的JSON
+	Authors          string `json:"-" gorm:"type:text"`                                         // 检出提交的历史中的作者邮箱，每行一个
+
+	StartedAt  *time.Time `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at"`
+
+	// 各步骤的结果，由Results解析，不入库
+	Steps []CheckStepResult `json:"steps" gorm:"-"`
+}
+
+// CheckStepResult 单个检查步骤的结果
+type CheckStepResult struct {
+	Position   int    `json:"position" example:"1"` // 第几个步骤，从1开始
+	Name       string `json:"name" example:"构建"`
+	Kind       string `json:"kind" example:"build"`
+	Status     string `json:"status" example:"passed"` // passed通过，failed未通过，timeout超时，skipped因构建失败跳过
+	ExitCode   int    `json:"exit_code" example:"0"`
+	DurationMS int64  `json:"duration_ms" example:"5230"`
+	Log        string `json:"log" example:"go build ./..."` // 标准输出和标准错误，超过配置的大小时截断，只对管理员返回
+	Criterion  string `json:"criterion" example:"项目能够成功构建"`
+	Points     int    `json:"points" example:"20"` // 本步骤获得的建议分数
+}
+
+// JudgeReviewerID 自动评测写入的系统评分使用的评分者ID，同一提交有人工评分时系统评分不计入总分
+const JudgeReviewerID = 0
+
+// Score 评分模型，每个评分者对每个提交只有一条评分（含已删除的记录）
 type Score struct {
 	ID        uint           `json:"id" gorm:"primarykey"`
 	CreatedAt time.Time      `json:"created_at"`
@@ -101,8 +417,8 @@ type Score struct {
 	Score        int    `json:"score" gorm:"not null" binding:"required,min=0" example:"85"`
 	Comment      string `json:"comment" gorm:"type:text" example:"代码实现良好，但缺少注释"`
 	UserID       uint   `json:"user_id" binding:"required" example:"1"`
-	SubmissionID uint   `json:"submission_id" binding:"required" example:"1"`
-	ReviewerID   uint   `json:"reviewer_id" binding:"required" example:"2"`
+	SubmissionID uint   `json:"submission_id" gorm:"uniqueIndex:idx_scores_submission_reviewer" binding:"required" example:"1"`
+	ReviewerID   uint   `json:"reviewer_id" gorm:"uniqueIndex:idx_scores_submission_reviewer" binding:"required" example:"2"`
 
 	// 关联关系
 	User       User       `json:"user,omitempty"`
@@ -110,6 +426,110 @@ type Score struct {
 	Reviewer   User       `json:"reviewer,omitempty" gorm:"foreignKey:ReviewerID"`
 }
 
+// Clarification 题目答疑模型
+type Clarification struct {
+	ID        uint           `json:"id" gorm:"primarykey"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+
+	ProblemID   uint       `json:"problem_id" gorm:"index;not null" example:"1"`
+	AskerID     uint       `json:"asker_id" gorm:"index;not null" example:"1"`
+	Question    string     `json:"question" gorm:"type:text;not null" example:"输入数据是否保证合法？"`
+	Answer      string     `json:"answer" gorm:"type:text" example:"保证合法，无需额外校验"`
+	AnswererID  *uint      `json:"answerer_id" example:"2"`
+	AnsweredAt  *time.Time `json:"answered_at"`
+	IsPublic    bool       `json:"is_public" gorm:"default:false"`
+	ReplyUnread bool       `json:"reply_unread" gorm:"default:false"`
+
+	// 关联关系
+	Problem  Problem `json:"problem,omitempty"`
+	Asker    User    `json:"asker,omitempty" gorm:"foreignKey:AskerID"`
+	Answerer *User   `json:"answerer,omitempty" gorm:"foreignKey:AnswererID"`
+}
+
+// Notification 站内通知模型
+type Notification struct {
+	ID        uint           `json:"id" gorm:"primarykey"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+
+	UserID    uint       `json:"user_id" gorm:"index;not null" example:"1"`
+	Type      string     `json:"type" gorm:"size:50;not null" example:"score_created"`
+	Title     string     `json:"title" gorm:"size:200;not null" example:"你的提交已被评分"`
+	Content   string     `json:"content" gorm:"type:text" example:"题目《实现一个简单的计算器》的提交点「源代码提交」获得 85 分"`
+	RelatedID uint       `json:"related_id" example:"1"`
+	ReadAt    *time.Time `json:"read_at"`
+}
+
+// NotificationSetting 用户通知渠道设置模型
+type NotificationSetting struct {
+	ID        uint           `json:"id" gorm:"primarykey"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+
+	UserID     uint   `json:"user_id" gorm:"uniqueIndex;not null" example:"1"`
+	Email      bool   `json:"email" gorm:"default:false"`
+	Webhook    bool   `json:"webhook" gorm:"default:false"`
+	WebhookURL string `json:"webhook_url" gorm:"size:500" example:"https://example.com/hook"`
+	OneBot     bool   `json:"onebot" gorm:"default:false"`
+}
+
+// WebhookSubscription Webhook订阅模型
+type WebhookSubscription struct {
+	ID        uint           `json:"id" gorm:"primarykey"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+
+	Name        string `json:"name" gorm:"size:100;not null" example:"招新群机器人"`
+	URL         string `json:"url" gorm:"size:500;not null" example:"https://bot.example.com/glimgate"`
+	Secret      string `json:"secret" gorm:"size:100;not null" example:"3f9a..."`
+	EventTypes  string `json:"event_types" gorm:"size:500" example:"submission.created,score.created"`
+	DirectionID uint   `json:"direction_id" gorm:"index" example:"0"`
+	Enabled     bool   `json:"enabled" gorm:"not null"`
+
+	ConsecutiveFailures int    `json:"consecutive_failures" gorm:"not null;default:0"`
+	DisabledReason      string `json:"disabled_reason" gorm:"size:255"`
+}
+
+// WebhookDelivery Webhook投递记录模型，同时作为待投递的发件箱
+type WebhookDelivery struct {
+	ID        uint           `json:"id" gorm:"primarykey"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+
+	SubscriptionID uint       `json:"subscription_id" gorm:"index;not null" example:"1"`
+	EventID        string     `json:"event_id" gorm:"size:64;index;not null" example:"9b2c..."`
+	EventType      string     `json:"event_type" gorm:"size:50;not null" example:"score.created"`
+	Payload        string     `json:"payload" gorm:"type:text;not null"`
+	Status         string     `json:"status" gorm:"size:20;index;not null" example:"pending"`
+	Attempts       int        `json:"attempts" gorm:"not null;default:0"`
+	NextAttemptAt  *time.Time `json:"next_attempt_at" gorm:"index"`
+	LastAttemptAt  *time.Time `json:"last_attempt_at"`
+	ResponseCode   int        `json:"response_code"`
+	LastError      string     `json:"last_error" gorm:"type:text"`
+}
+
+// AuditLog 审计日志模型，只追加不修改
+type AuditLog struct {
+	ID        uint      `json:"id" gorm:"primarykey"`
+	CreatedAt time.Time `json:"created_at" gorm:"index"`
+
+	OperatorID   uint   `json:"operator_id" gorm:"index;not null" example:"2"`
+	OperatorName string `json:"operator_name" gorm:"size:50" example:"admin"`
+	Action       string `json:"action" gorm:"size:20;index;not null" example:"update"`
+	EntityType   string `json:"entity_type" gorm:"size:50;index:idx_audit_entity;not null" example:"score"`
+	EntityID     uint   `json:"entity_id" gorm:"index:idx_audit_entity" example:"1"`
+	Before       string `json:"before" gorm:"type:text"`
+	After        string `json:"after" gorm:"type:text"`
+	IP           string `json:"ip" gorm:"size:64" example:"127.0.0.1"`
+	RequestID    string `json:"request_id" gorm:"size:64;index" example:"9b2c7f0e5d3a4c1b8e6f2a0d4c7b9e1f"`
+}
+
 // TableName 指定表名
 func (User) TableName() string {
 	return "users"
@@ -133,4 +553,48 @@ func (Submission) TableName() string {
 
 func (Score) TableName() string {
 	return "scores"
-}
\ No newline at end of file
+}
+
+func (JudgeCase) TableName() string {
+	return "judge_cases"
+}
+
+func (JudgeRun) TableName() string {
+	return "judge_runs"
+}
+
+func (CheckStep) TableName() string {
+	return "check_steps"
+}
+
+func (CheckRun) TableName() string {
+	return "check_runs"
+}
+
+func (SimilarityReport) TableName() string {
+	return "similarity_reports"
+}
+
+func (Clarification) TableName() string {
+	return "clarifications"
+}
+
+func (Notification) TableName() string {
+	return "notifications"
+}
+
+func (NotificationSetting) TableName() string {
+	return "notification_settings"
+}
+
+func (WebhookSubscription) TableName() string {
+	return "webhook_subscriptions"
+}
+
+func (WebhookDelivery) TableName() string {
+	return "webhook_deliveries"
+}
+
+func (AuditLog) TableName() string {
+	return "audit_logs"
+}
@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/tksky1/glimgate/internal/model"
+	"gorm.io/gorm"
+)
+
+// AuditLogFilter 审计日志查询条件，零值字段不作限制
+type AuditLogFilter struct {
+	OperatorID uint
+	Action     string
+	EntityType string
+	EntityID   uint
+	RequestID  string
+	Start      *time.Time
+	End        *time.Time
+}
+
+// AuditLogRepository 审计日志数据访问接口，写入与业务仓储共享事务
+type AuditLogRepository interface {
+	Create(auditLog *model.AuditLog) error
+	List(filter AuditLogFilter, offset, limit int) ([]model.AuditLog, int64, error)
+	FindInBatches(filter AuditLogFilter, batchSize int, fn func(logs []model.AuditLog) error) error
+}
+
+type auditLogRepository struct {
+	db *gorm.DB
+}
+
+// NewAuditLogRepository 创建审计日志仓储
+func NewAuditLogRepository(db *gorm.DB) AuditLogRepository {
+	return &auditLogRepository{db: db}
+}
+
+func (r *auditLogRepository) Create(auditLog *model.AuditLog) error {
+	return r.db.Create(auditLog).Error
+}
+
+// List 按条件分页获取审计日志，最新的在前
+func (r *auditLogRepository) List(filter AuditLogFilter, offset, limit int) ([]model.AuditLog, int64, error) {
+	query := r.filterQuery(filter)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var logs []model.AuditLog
+	if err := query.Order("id DESC").Offset(offset).Limit(limit).Find(&logs).Error; err != nil {
+		return nil, 0, err
+	}
+	return logs, total, nil
+}
+
+// FindInBatches 按ID顺序分批读取符合条件的审计日志，fn返回错误时停止
+func (r *auditLogRepository) FindInBatches(filter AuditLogFilter, batchSize int, fn func(logs []model.AuditLog) error) error {
+	var batch []model.AuditLog
+	return r.filterQuery(filter).Order("id ASC").FindInBatches(&batch, batchSize, func(tx *gorm.DB, _ int) error {
+		return fn(batch)
+	}).Error
+}
+
+// filterQuery 根据查询条件构建查询
+func (r *auditLogRepository) filterQuery(filter AuditLogFilter) *gorm.DB {
+	query := r.db.Model(&model.AuditLog{})
+	if filter.OperatorID > 0 {
+		query = query.Where("operator_id = ?", filter.OperatorID)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.EntityType != "" {
+		query = query.Where("entity_type = ?", filter.EntityType)
+	}
+	if filter.EntityID > 0 {
+		query = query.Where("entity_id = ?", filter.EntityID)
+	}
+	if filter.RequestID != "" {
+		query = query.Where("request_id = ?", filter.RequestID)
+	}
+	if filter.Start != nil {
+		query = query.Where("created_at >= ?", *filter.Start)
+	}
+	if filter.End != nil {
+		query = query.Where("created_at <= ?", *filter.End)
+	}
+	return query
+}
@@ -0,0 +1,148 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/tksky1/glimgate/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 检查报告状态
+const (
+	CheckStatusPending  = "pending"
+	CheckStatusRunning  = "running"
+	CheckStatusFinished = "finished"
+	CheckStatusFailed   = "failed"
+)
+
+// CheckRepository 代码仓库检查数据访问接口
+type CheckRepository interface {
+	FindStep(id uint) (*model.CheckStep, error)
+	ListSteps(pointID uint) ([]model.CheckStep, error)
+	CreateStep(step *model.CheckStep) error
+	UpdateStep(step *model.CheckStep, updates map[string]interface{}) error
+	DeleteStep(step *model.CheckStep) error
+
+	FindRun(submissionID uint) (*model.CheckRun, error)
+	ResetRun(submissionID uint) error
+	ClaimNextRun() (*model.CheckRun, error)
+	FinishRun(run *model.CheckRun, updates map[string]interface{}) (bool, error)
+	RequeueRunning() (int64, error)
+}
+
+type checkRepository struct {
+	db *gorm.DB
+}
+
+// NewCheckRepository 创建代码仓库检查仓储
+func NewCheckRepository(db *gorm.DB) CheckRepository {
+	return &checkRepository{db: db}
+}
+
+func (r *checkRepository) FindStep(id uint) (*model.CheckStep, error) {
+	var step model.CheckStep
+	if err := r.db.First(&step, id).Error; err != nil {
+		return nil, err
+	}
+	return &step, nil
+}
+
+// ListSteps 按顺序获取提交点的检查步骤
+func (r *checkRepository) ListSteps(pointID uint) ([]model.CheckStep, error) {
+	var steps []model.CheckStep
+	if err := r.db.Where("submission_point_id = ?", pointID).Order("position, id").Find(&steps).Error; err != nil {
+		return nil, err
+	}
+	return steps, nil
+}
+
+func (r *checkRepository) CreateStep(step *model.CheckStep) error {
+	return r.db.Create(step).Error
+}
+
+func (r *checkRepository) UpdateStep(step *model.CheckStep, updates map[string]interface{}) error {
+	return r.db.Model(step).Updates(updates).Error
+}
+
+func (r *checkRepository) DeleteStep(step *model.CheckStep) error {
+	return r.db.Delete(step).Error
+}
+
+func (r *checkRepository) FindRun(submissionID uint) (*model.CheckRun, error) {
+	var run model.CheckRun
+	if err := r.db.Where("submission_id = ?", submissionID).First(&run).Error; err != nil {
+		return nil, err
+	}
+	return &run, nil
+}
+
+// ResetRun 将提交的检查报告重置为等待检查，不存在时创建
+// 正在进行的检查结束时会因检查次数变化而丢弃结果
+func (r *checkRepository) ResetRun(submissionID uint) error {
+	res := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&model.CheckRun{
+		SubmissionID: submissionID,
+		Status:       CheckStatusPending,
+		Attempt:      1,
+	})
+	if res.Error != nil || res.RowsAffected == 1 {
+		return res.Error
+	}
+
+	return r.db.Model(&model.CheckRun{}).Where("submission_id = ?", submissionID).Updates(map[string]interface{}{
+		"status":            CheckStatusPending,
+		"attempt":           gorm.Expr("attempt + 1"),
+		"commit":            "",
+		"authors":           "",
+		"passed_count":      0,
+		"step_count":        0,
+		"suggested_score":   0,
+		"suggested_comment": "",
+		"message":           "",
+		"results":           "",
+		"started_at":        nil,
+		"finished_at":       nil,
+	}).Error
+}
+
+// ClaimNextRun 取出最早的等待检查记录并标记为检查中，没有时返回ErrNotFound
+func (r *checkRepository) ClaimNextRun() (*model.CheckRun, error) {
+	for {
+		var runs []model.CheckRun
+		if err := r.db.Where("status = ?", CheckStatusPending).Order("updated_at, id").Limit(1).Find(&runs).Error; err != nil {
+			return nil, err
+		}
+		if len(runs) == 0 {
+			return nil, ErrNotFound
+		}
+		run := runs[0]
+
+		now := time.Now()
+		res := r.db.Model(&model.CheckRun{}).
+			Where("id = ? AND status = ? AND attempt = ?", run.ID, CheckStatusPending, run.Attempt).
+			Updates(map[string]interface{}{"status": CheckStatusRunning, "started_at": now})
+		if res.Error != nil {
+			return nil, res.Error
+		}
+		if res.RowsAffected == 1 {
+			run.Status = CheckStatusRunning
+			run.StartedAt = &now
+			return &run, nil
+		}
+	}
+}
+
+// FinishRun 写入检查结果，检查期间记录被重置时不写入并返回false
+func (r *checkRepository) FinishRun(run *model.CheckRun, updates map[string]interface{}) (bool, error) {
+	res := r.db.Model(&model.CheckRun{}).
+		Where("id = ? AND status = ? AND attempt = ?", run.ID, CheckStatusRunning, run.Attempt).
+		Updates(updates)
+	return res.RowsAffected == 1, res.Error
+}
+
+// RequeueRunning 将检查中的记录放回等待队列，用于服务重启后继续中断的检查
+func (r *checkRepository) RequeueRunning() (int64, error) {
+	res := r.db.Model(&model.CheckRun{}).Where("status = ?", CheckStatusRunning).
+		Updates(map[string]interface{}{"status": CheckStatusPending, "started_at": nil})
+	return res.RowsAffected, res.Error
+}
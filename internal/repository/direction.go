@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"github.com/tksky1/glimgate/internal/model"
+	"gorm.io/gorm"
+)
+
+// DirectionRepository 方向数据访问接口，包含方向负责人关系
+type DirectionRepository interface {
+	FindByID(id uint, preloads ...string) (*model.Direction, error)
+	FindByName(name string) (*model.Direction, error)
+	List() ([]model.Direction, error)
+	Create(direction *model.Direction) error
+	Update(direction *model.Direction, updates map[string]interface{}) error
+	Delete(direction *model.Direction) error
+	ReplaceManagers(direction *model.Direction, managerIDs []uint) error
+	IsManager(directionID, userID uint) (bool, error)
+	ManagerIDs(directionID uint) ([]uint, error)
+	ManagedDirectionIDs(userID uint) ([]uint, error)
+}
+
+type directionRepository struct {
+	db *gorm.DB
+}
+
+// NewDirectionRepository 创建方向仓储
+func NewDirectionRepository(db *gorm.DB) DirectionRepository {
+	return &directionRepository{db: db}
+}
+
+func (r *directionRepository) FindByID(id uint, preloads ...string) (*model.Direction, error) {
+	var direction model.Direction
+	if err := withPreloads(r.db, preloads).First(&direction, id).Error; err != nil {
+		return nil, err
+	}
+	return &direction, nil
+}
+
+// FindByName 按名称查找方向，重名时返回ID最小的一个
+func (r *directionRepository) FindByName(name string) (*model.Direction, error) {
+	var direction model.Direction
+	if err := r.db.Where("name = ?", name).Order("id").First(&direction).Error; err != nil {
+		return nil, err
+	}
+	return &direction, nil
+}
+
+func (r *directionRepository) List() ([]model.Direction, error) {
+	var directions []model.Direction
+	if err := r.db.Preload("Managers").Find(&directions).Error; err != nil {
+		return nil, err
+	}
+	return directions, nil
+}
+
+func (r *directionRepository) Create(direction *model.Direction) error {
+	return r.db.Create(direction).Error
+}
+
+func (r *directionRepository) Update(direction *model.Direction, updates map[string]interface{}) error {
+	return r.db.Model(direction).Updates(updates).Error
+}
+
+// Delete 软删除方向，负责人关联保留到回收站彻底删除时清理，以便恢复
+func (r *directionRepository) Delete(direction *model.Direction) error {
+	return r.db.Delete(direction).Error
+}
+
+func (r *directionRepository) ReplaceManagers(direction *model.Direction, managerIDs []uint) error {
+	var managers []model.User
+	if len(managerIDs) > 0 {
+		if err := r.db.Where("id IN ?", managerIDs).Find(&managers).Error; err != nil {
+			return err
+		}
+	}
+	return r.db.Model(direction).Association("Managers").Replace(managers)
+}
+
+func (r *directionRepository) IsManager(directionID, userID uint) (bool, error) {
+	var count int64
+	if err := r.db.Table("direction_managers").
+		Where("direction_id = ? AND user_id = ?", directionID, userID).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (r *directionRepository) ManagerIDs(directionID uint) ([]uint, error) {
+	var managerIDs []uint
+	if err := r.db.Table("direction_managers").Where("direction_id = ?", directionID).Pluck("user_id", &managerIDs).Error; err != nil {
+		return nil, err
+	}
+	return managerIDs, nil
+}
+
+func (r *directionRepository) ManagedDirectionIDs(userID uint) ([]uint, error) {
+	var directionIDs []uint
+	if err := r.db.Table("direction_managers").Where("user_id = ?", userID).Pluck("direction_id", &directionIDs).Error; err != nil {
+		return nil, err
+	}
+	return directionIDs, nil
+}
@@ -0,0 +1,148 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/tksky1/glimgate/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 评测记录状态
+const (
+	JudgeStatusPending  = "pending"
+	JudgeStatusRunning  = "running"
+	JudgeStatusFinished = "finished"
+	JudgeStatusFailed   = "failed"
+)
+
+// JudgeRepository 自动评测数据访问接口
+type JudgeRepository interface {
+	FindCase(id uint) (*model.JudgeCase, error)
+	ListCases(pointID uint) ([]model.JudgeCase, error)
+	CreateCase(judgeCase *model.JudgeCase) error
+	UpdateCase(judgeCase *model.JudgeCase, updates map[string]interface{}) error
+	DeleteCase(judgeCase *model.JudgeCase) error
+
+	FindRun(submissionID uint) (*model.JudgeRun, error)
+	ResetRun(submissionID uint) error
+	ClaimNextRun() (*model.JudgeRun, error)
+	FinishRun(run *model.JudgeRun, updates map[string]interface{}) (bool, error)
+	RequeueRunning() (int64, error)
+}
+
+type judgeRepository struct {
+	db *gorm.DB
+}
+
+// NewJudgeRepository 创建自动评测仓储
+func NewJudgeRepository(db *gorm.DB) JudgeRepository {
+	return &judgeRepository{db: db}
+}
+
+func (r *judgeRepository) FindCase(id uint) (*model.JudgeCase, error) {
+	var judgeCase model.JudgeCase
+	if err := r.db.First(&judgeCase, id).Error; err != nil {
+		return nil, err
+	}
+	return &judgeCase, nil
+}
+
+// ListCases 按顺序获取提交点的测试点
+func (r *judgeRepository) ListCases(pointID uint) ([]model.JudgeCase, error) {
+	var cases []model.JudgeCase
+	if err := r.db.Where("submission_point_id = ?", pointID).Order("position, id").Find(&cases).Error; err != nil {
+		return nil, err
+	}
+	return cases, nil
+}
+
+func (r *judgeRepository) CreateCase(judgeCase *model.JudgeCase) error {
+	return r.db.Create(judgeCase).Error
+}
+
+func (r *judgeRepository) UpdateCase(judgeCase *model.JudgeCase, updates map[string]interface{}) error {
+	return r.db.Model(judgeCase).Updates(updates).Error
+}
+
+func (r *judgeRepository) DeleteCase(judgeCase *model.JudgeCase) error {
+	return r.db.Delete(judgeCase).Error
+}
+
+func (r *judgeRepository) FindRun(submissionID uint) (*model.JudgeRun, error) {
+	var run model.JudgeRun
+	if err := r.db.Where("submission_id = ?", submissionID).First(&run).Error; err != nil {
+		return nil, err
+	}
+	return &run, nil
+}
+
+// ResetRun 将提交的评测记录重置为等待评测，不存在时创建
+// 正在进行的评测结束时会因评测次数变化而丢弃结果
+func (r *judgeRepository) ResetRun(submissionID uint) error {
+	res := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&model.JudgeRun{
+		SubmissionID: submissionID,
+		Status:       JudgeStatusPending,
+		Attempt:      1,
+	})
+	if res.Error != nil || res.RowsAffected == 1 {
+		return res.Error
+	}
+
+	return r.db.Model(&model.JudgeRun{}).Where("submission_id = ?", submissionID).Updates(map[string]interface{}{
+		"status":       JudgeStatusPending,
+		"attempt":      gorm.Expr("attempt + 1"),
+		"verdict":      "",
+		"score":        0,
+		"passed_count": 0,
+		"case_count":   0,
+		"message":      "",
+		"results":      "",
+		"started_at":   nil,
+		"finished_at":  nil,
+	}).Error
+}
+
+// ClaimNextRun 取出最早的等待评测记录并标记为评测中，没有时返回ErrNotFound
+// 按评测次数条件更新，多个评测任务并发时每条记录只会被一个任务取出
+func (r *judgeRepository) ClaimNextRun() (*model.JudgeRun, error) {
+	for {
+		// 队列为空是常态，用Find避免每次轮询都记录未找到的日志
+		var runs []model.JudgeRun
+		if err := r.db.Where("status = ?", JudgeStatusPending).Order("updated_at, id").Limit(1).Find(&runs).Error; err != nil {
+			return nil, err
+		}
+		if len(runs) == 0 {
+			return nil, ErrNotFound
+		}
+		run := runs[0]
+
+		now := time.Now()
+		res := r.db.Model(&model.JudgeRun{}).
+			Where("id = ? AND status = ? AND attempt = ?", run.ID, JudgeStatusPending, run.Attempt).
+			Updates(map[string]interface{}{"status": JudgeStatusRunning, "started_at": now})
+		if res.Error != nil {
+			return nil, res.Error
+		}
+		if res.RowsAffected == 1 {
+			run.Status = JudgeStatusRunning
+			run.StartedAt = &now
+			return &run, nil
+		}
+	}
+}
+
+// FinishRun 写入评测结果，评测期间记录被重置时不写入并返回false
+func (r *judgeRepository) FinishRun(run *model.JudgeRun, updates map[string]interface{}) (bool, error) {
+	res := r.db.Model(&model.JudgeRun{}).
+		Where("id = ? AND status = ? AND attempt = ?", run.ID, JudgeStatusRunning, run.Attempt).
+		Updates(updates)
+	return res.RowsAffected == 1, res.Error
+}
+
+// RequeueRunning 将评测中的记录放回等待队列，用于服务重启后继续中断的评测
+func (r *judgeRepository) RequeueRunning() (int64, error) {
+	res := r.db.Model(&model.JudgeRun{}).Where("status = ?", JudgeStatusRunning).
+		Updates(map[string]interface{}{"status": JudgeStatusPending, "started_at": nil})
+	return res.RowsAffected, res.Error
+}
@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/tksky1/glimgate/internal/model"
+	"gorm.io/gorm"
+)
+
+// NotificationRepository 站内通知及通知设置数据访问接口
+type NotificationRepository interface {
+	Create(notifications []model.Notification) error
+	List(userID uint, unreadOnly bool, offset, limit int) ([]model.Notification, int64, error)
+	CountUnread(userID uint) (int64, error)
+	FindByIDAndUser(id, userID uint) (*model.Notification, error)
+	MarkRead(notification *model.Notification, readAt time.Time) error
+	MarkAllRead(userID uint, readAt time.Time) error
+
+	FindSetting(userID uint) (*model.NotificationSetting, error)
+	ListSettings(userIDs []uint) ([]model.NotificationSetting, error)
+	SaveSetting(setting *model.NotificationSetting) error
+}
+
+type notificationRepository struct {
+	db *gorm.DB
+}
+
+// NewNotificationRepository 创建通知仓储
+func NewNotificationRepository(db *gorm.DB) NotificationRepository {
+	return &notificationRepository{db: db}
+}
+
+func (r *notificationRepository) Create(notifications []model.Notification) error {
+	return r.db.Create(&notifications).Error
+}
+
+// List 分页获取用户的通知，最新的在前
+func (r *notificationRepository) List(userID uint, unreadOnly bool, offset, limit int) ([]model.Notification, int64, error) {
+	query := r.db.Model(&model.Notification{}).Where("user_id = ?", userID)
+	if unreadOnly {
+		query = query.Where("read_at IS NULL")
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var notifications []model.Notification
+	if err := query.Order("created_at DESC").Offset(offset).Limit(limit).Find(&notifications).Error; err != nil {
+		return nil, 0, err
+	}
+	return notifications, total, nil
+}
+
+func (r *notificationRepository) CountUnread(userID uint) (int64, error) {
+	var count int64
+	if err := r.db.Model(&model.Notification{}).Where("user_id = ? AND read_at IS NULL", userID).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (r *notificationRepository) FindByIDAndUser(id, userID uint) (*model.Notification, error) {
+	var notification model.Notification
+	if err := r.db.Where("id = ? AND user_id = ?", id, userID).First(&notification).Error; err != nil {
+		return nil, err
+	}
+	return &notification, nil
+}
+
+func (r *notificationRepository) MarkRead(notification *model.Notification, readAt time.Time) error {
+	return r.db.Model(notification).Update("read_at", readAt).Error
+}
+
+// MarkAllRead 将用户的全部未读通知标记为已读
+func (r *notificationRepository) MarkAllRead(userID uint, readAt time.Time) error {
+	return r.db.Model(&model.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Update("read_at", readAt).Error
+}
+
+func (r *notificationRepository) FindSetting(userID uint) (*model.NotificationSetting, error) {
+	var setting model.NotificationSetting
+	if err := r.db.Where("user_id = ?", userID).First(&setting).Error; err != nil {
+		return nil, err
+	}
+	return &setting, nil
+}
+
+func (r *notificationRepository) ListSettings(userIDs []uint) ([]model.NotificationSetting, error) {
+	var settings []model.NotificationSetting
+	if err := r.db.Where("user_id IN ?", userIDs).Find(&settings).Error; err != nil {
+		return nil, err
+	}
+	return settings, nil
+}
+
+func (r *notificationRepository) SaveSetting(setting *model.NotificationSetting) error {
+	return r.db.Save(setting).Error
+}
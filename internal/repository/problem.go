@@ -0,0 +1,571 @@
+package repository
+
+import (
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/tksky1/glimgate/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ProblemSortRelevance 按关键词相关度排序
+const ProblemSortRelevance = "relevance"
+
+// mysqlMinTokenLen MySQL ngram分词的默认长度，短于该长度的关键词无法使用全文索引
+const mysqlMinTokenLen = 2
+
+// ProblemSearchFilter 题目搜索条件
+type ProblemSearchFilter struct {
+	Keywords      []string // 每个关键词都需出现在标题或描述中
+	TitleOnlyIDs  []uint   // 这些题目的关键词只匹配标题，用于不向未解锁的选手暴露题面
+	DirectionID   uint
+	Statuses      []string
+	Tags          []string // 需同时包含全部标签
+	MinDifficulty int
+	MaxDifficulty int
+	Sort          string // 排序列名或ProblemSortRelevance，由调用方校验；为空时按ID排序
+	Desc          bool
+}
+
+// HintPenalty 用户在一道题目上因解锁提示被扣除的分数
+type HintPenalty struct {
+	UserID    uint
+	ProblemID uint
+	Penalty   int
+}
+
+// TagCount 标签及使用该标签的题目数量
+type TagCount struct {
+	Name         string `json:"name" example:"算法"`
+	ProblemCount int64  `json:"problem_count" example:"3"`
+}
+
+// ProblemRepository 题目数据访问接口，包含题目下的提交点
+type ProblemRepository interface {
+	FindByID(id uint, preloads ...string) (*model.Problem, error)
+	FindBySlug(directionID uint, slug string, preloads ...string) (*model.Problem, error)
+	ListByTitle(directionID uint, title string, preloads ...string) ([]model.Problem, error)
+	ListByIDs(ids []uint) ([]model.Problem, error)
+	List(directionID uint, statuses ...string) ([]model.Problem, error)
+	Search(filter ProblemSearchFilter, offset, limit int) ([]model.Problem, int64, error)
+	ListTags(statuses ...string) ([]TagCount, error)
+	ReplaceTags(problemID uint, names []string) error
+	ListPrerequisites(problemIDs ...uint) ([]model.ProblemPrerequisite, error)
+	ReplacePrerequisites(problemID uint, prerequisites []model.ProblemPrerequisite) error
+	ListPublishDue(status string, before time.Time) ([]model.Problem, error)
+	NextPublishAt(status string) (*time.Time, error)
+	IDsByDirections(directionIDs []uint) ([]uint, error)
+	CountByDirection(directionID uint) (int64, error)
+	Create(problem *model.Problem) error
+	Update(problem *model.Problem, updates map[string]interface{}) error
+	Delete(problem *model.Problem) error
+
+	FindPointByID(id uint, preloads ...string) (*model.SubmissionPoint, error)
+	FindPointInProblem(problemID, pointID uint) (*model.SubmissionPoint, error)
+	ListPoints(problemID uint) ([]model.SubmissionPoint, error)
+	ListReminderDuePoints(after, before time.Time) ([]model.SubmissionPoint, error)
+	CreatePoint(point *model.SubmissionPoint) error
+	UpdatePoint(point *model.SubmissionPoint, updates map[string]interface{}) error
+	DeletePoint(point *model.SubmissionPoint) error
+
+	FindAttachmentByID(id uint, preloads ...string) (*model.ProblemAttachment, error)
+	FindAttachmentByName(problemID uint, filename string) (*model.ProblemAttachment, error)
+	ListAttachments(problemID uint) ([]model.ProblemAttachment, error)
+	CreateAttachment(attachment *model.ProblemAttachment) error
+	DeleteAttachment(attachment *model.ProblemAttachment) error
+
+	FindHintByID(id uint) (*model.ProblemHint, error)
+	ListHints(problemID uint) ([]model.ProblemHint, error)
+	CreateHint(hint *model.ProblemHint) error
+	UpdateHint(hint *model.ProblemHint, updates map[string]interface{}) error
+	DeleteHint(hint *model.ProblemHint) error
+	HintUnlockCounts(problemID uint) (map[uint]int64, error)
+	ListHintUnlocks(userID, problemID uint) ([]model.HintUnlock, error)
+	CreateHintUnlock(unlock *model.HintUnlock) (bool, error)
+	HintPenalties(problemIDs []uint) ([]HintPenalty, error)
+
+	FindRevision(problemID uint, version int) (*model.ProblemRevision, error)
+	LatestRevision(problemID uint) (*model.ProblemRevision, error)
+	ListRevisions(problemID uint) ([]model.ProblemRevision, error)
+	CreateRevision(revision *model.ProblemRevision) error
+}
+
+type problemRepository struct {
+	db *gorm.DB
+}
+
+// NewProblemRepository 创建题目仓储
+func NewProblemRepository(db *gorm.DB) ProblemRepository {
+	return &problemRepository{db: db}
+}
+
+func (r *problemRepository) FindByID(id uint, preloads ...string) (*model.Problem, error) {
+	var problem model.Problem
+	if err := withPreloads(r.db, preloads).First(&problem, id).Error; err != nil {
+		return nil, err
+	}
+	return &problem, nil
+}
+
+// FindBySlug 按题目包标识查找方向内的题目
+func (r *problemRepository) FindBySlug(directionID uint, slug string, preloads ...string) (*model.Problem, error) {
+	var problem model.Problem
+	if err := withPreloads(r.db, preloads).Where("direction_id = ? AND slug = ?", directionID, slug).First(&problem).Error; err != nil {
+		return nil, err
+	}
+	return &problem, nil
+}
+
+// ListByTitle 获取方向内指定标题的题目
+func (r *problemRepository) ListByTitle(directionID uint, title string, preloads ...string) ([]model.Problem, error) {
+	var problems []model.Problem
+	if err := withPreloads(r.db, preloads).Where("direction_id = ? AND title = ?", directionID, title).Order("id").Find(&problems).Error; err != nil {
+		return nil, err
+	}
+	return problems, nil
+}
+
+// List 获取题目列表，directionID为0时不限方向，未指定statuses时不限可见状态
+func (r *problemRepository) List(directionID uint, statuses ...string) ([]model.Problem, error) {
+	query := r.db.Preload("Direction").Preload("SubmissionPoints").Preload("Tags")
+	if directionID > 0 {
+		query = query.Where("direction_id = ?", directionID)
+	}
+	if len(statuses) > 0 {
+		query = query.Where("status IN ?", statuses)
+	}
+
+	var problems []model.Problem
+	if err := query.Find(&problems).Error; err != nil {
+		return nil, err
+	}
+	return problems, nil
+}
+
+// Search 按条件分页搜索题目，返回当前页的题目和总数
+// MySQL使用标题和描述上的ngram全文索引，其他数据库及短于分词长度的关键词退化为不区分大小写的LIKE匹配
+func (r *problemRepository) Search(filter ProblemSearchFilter, offset, limit int) ([]model.Problem, int64, error) {
+	query := r.db.Model(&model.Problem{})
+	if filter.DirectionID > 0 {
+		query = query.Where("direction_id = ?", filter.DirectionID)
+	}
+	if len(filter.Statuses) > 0 {
+		query = query.Where("status IN ?", filter.Statuses)
+	}
+	if filter.MinDifficulty > 0 {
+		query = query.Where("difficulty >= ?", filter.MinDifficulty)
+	}
+	if filter.MaxDifficulty > 0 {
+		query = query.Where("difficulty <= ?", filter.MaxDifficulty)
+	}
+	if len(filter.Tags) > 0 {
+		query = query.Where("id IN (?)", r.db.Model(&model.ProblemTag{}).Select("problem_id").
+			Where("name IN ?", filter.Tags).Group("problem_id").Having("COUNT(*) = ?", len(filter.Tags)))
+	}
+
+	fullText, likes := r.splitKeywords(filter.Keywords)
+	var keywordConds []clause.Expression
+	if fullText != "" {
+		keywordConds = append(keywordConds, clause.Expr{SQL: "MATCH(title, description) AGAINST(? IN BOOLEAN MODE)", Vars: []interface{}{fullText}})
+	}
+	for _, keyword := range likes {
+		pattern := likePattern(keyword)
+		keywordConds = append(keywordConds, clause.Expr{SQL: "(LOWER(title) LIKE ? ESCAPE '!' OR LOWER(description) LIKE ? ESCAPE '!')", Vars: []interface{}{pattern, pattern}})
+	}
+	titleOnly := len(keywordConds) > 0 && len(filter.TitleOnlyIDs) > 0
+	if titleOnly {
+		titleConds := []clause.Expression{clause.Expr{SQL: "id IN ?", Vars: []interface{}{filter.TitleOnlyIDs}}}
+		for _, keyword := range filter.Keywords {
+			titleConds = append(titleConds, clause.Expr{SQL: "LOWER(title) LIKE ? ESCAPE '!'", Vars: []interface{}{likePattern(keyword)}})
+		}
+		keywordConds = append([]clause.Expression{clause.Expr{SQL: "id NOT IN ?", Vars: []interface{}{filter.TitleOnlyIDs}}}, keywordConds...)
+		query = query.Where(clause.Or(clause.And(keywordConds...), clause.And(titleConds...)))
+	} else {
+		for _, cond := range keywordConds {
+			query = query.Where(cond)
+		}
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	switch {
+	case filter.Sort == ProblemSortRelevance && fullText != "" && titleOnly:
+		// 只匹配标题的题目不按描述计算相关度，排在其他命中的题目之后
+		query = query.Order(clause.OrderBy{Expression: clause.Expr{
+			SQL:  "CASE WHEN id IN ? THEN 0 ELSE MATCH(title, description) AGAINST(? IN BOOLEAN MODE) END DESC",
+			Vars: []interface{}{filter.TitleOnlyIDs, fullText},
+		}})
+	case filter.Sort == ProblemSortRelevance && fullText != "":
+		query = query.Order(clause.OrderBy{Expression: clause.Expr{SQL: "MATCH(title, description) AGAINST(? IN BOOLEAN MODE) DESC", Vars: []interface{}{fullText}}})
+	case filter.Sort == ProblemSortRelevance && len(likes) > 0:
+		// 没有全文索引时以标题命中的关键词数量近似相关度
+		hits := make([]string, len(likes))
+		vars := make([]interface{}, len(likes))
+		for i, keyword := range likes {
+			hits[i] = "CASE WHEN LOWER(title) LIKE ? ESCAPE '!' THEN 1 ELSE 0 END"
+			vars[i] = likePattern(keyword)
+		}
+		query = query.Order(clause.OrderBy{Expression: clause.Expr{SQL: "(" + strings.Join(hits, " + ") + ") DESC", Vars: vars}})
+	case filter.Sort != "" && filter.Sort != ProblemSortRelevance:
+		query = query.Order(clause.OrderByColumn{Column: clause.Column{Name: filter.Sort}, Desc: filter.Desc})
+	}
+	query = query.Order(clause.OrderByColumn{Column: clause.Column{Name: "id"}, Desc: filter.Desc && filter.Sort != ProblemSortRelevance})
+
+	var problems []model.Problem
+	if err := query.Preload("Direction").Preload("Tags").Offset(offset).Limit(limit).Find(&problems).Error; err != nil {
+		return nil, 0, err
+	}
+	return problems, total, nil
+}
+
+// splitKeywords 将关键词分为走全文索引的部分和走LIKE匹配的部分
+// 全文索引部分组合为布尔模式的查询串，每个关键词作为必须出现的短语
+func (r *problemRepository) splitKeywords(keywords []string) (string, []string) {
+	if r.db.Dialector.Name() != "mysql" {
+		return "", keywords
+	}
+	var phrases, likes []string
+	for _, keyword := range keywords {
+		phrase := strings.ReplaceAll(keyword, `"`, "")
+		if utf8.RuneCountInString(phrase) < mysqlMinTokenLen {
+			likes = append(likes, keyword)
+			continue
+		}
+		phrases = append(phrases, `+"`+phrase+`"`)
+	}
+	return strings.Join(phrases, " "), likes
+}
+
+// likePattern 构造不区分大小写的包含匹配模式，以!转义LIKE通配符
+func likePattern(keyword string) string {
+	escaped := strings.NewReplacer("!", "!!", "%", "!%", "_", "!_").Replace(strings.ToLower(keyword))
+	return "%" + escaped + "%"
+}
+
+// ListTags 获取标签及使用该标签的题目数量，按题目数量降序排列；未指定statuses时不限题目可见状态
+func (r *problemRepository) ListTags(statuses ...string) ([]TagCount, error) {
+	query := r.db.Table("problem_tags t").
+		Select("t.name AS name, COUNT(*) AS problem_count").
+		Joins("JOIN problems p ON p.id = t.problem_id AND p.deleted_at IS NULL")
+	if len(statuses) > 0 {
+		query = query.Where("p.status IN ?", statuses)
+	}
+
+	var tags []TagCount
+	if err := query.Group("t.name").Order("problem_count DESC, t.name").Scan(&tags).Error; err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+// ReplaceTags 将题目的标签替换为names
+func (r *problemRepository) ReplaceTags(problemID uint, names []string) error {
+	if err := r.db.Where("problem_id = ?", problemID).Delete(&model.ProblemTag{}).Error; err != nil {
+		return err
+	}
+	if len(names) == 0 {
+		return nil
+	}
+	tags := make([]model.ProblemTag, len(names))
+	for i, name := range names {
+		tags[i] = model.ProblemTag{ProblemID: problemID, Name: name}
+	}
+	return r.db.Create(&tags).Error
+}
+
+// ListPrerequisites 获取题目的解锁条件，未指定problemIDs时返回全部；前置题目已删除的条件不返回
+func (r *problemRepository) ListPrerequisites(problemIDs ...uint) ([]model.ProblemPrerequisite, error) {
+	query := r.db.Where("required_problem_id IN (?)", r.db.Model(&model.Problem{}).Select("id"))
+	if len(problemIDs) > 0 {
+		query = query.Where("problem_id IN ?", problemIDs)
+	}
+
+	var prerequisites []model.ProblemPrerequisite
+	if err := query.Order("problem_id, id").Find(&prerequisites).Error; err != nil {
+		return nil, err
+	}
+	return prerequisites, nil
+}
+
+// ReplacePrerequisites 将题目的解锁条件替换为prerequisites
+func (r *problemRepository) ReplacePrerequisites(problemID uint, prerequisites []model.ProblemPrerequisite) error {
+	if err := r.db.Where("problem_id = ?", problemID).Delete(&model.ProblemPrerequisite{}).Error; err != nil {
+		return err
+	}
+	if len(prerequisites) == 0 {
+		return nil
+	}
+	for i := range prerequisites {
+		prerequisites[i].ProblemID = problemID
+	}
+	return r.db.Create(&prerequisites).Error
+}
+
+// ListPublishDue 获取指定状态下发布时间不晚于before的题目
+func (r *problemRepository) ListPublishDue(status string, before time.Time) ([]model.Problem, error) {
+	var problems []model.Problem
+	if err := r.db.Where("status = ? AND publish_at <= ?", status, before).Order("publish_at, id").Find(&problems).Error; err != nil {
+		return nil, err
+	}
+	return problems, nil
+}
+
+// NextPublishAt 获取指定状态下最早的发布时间，没有符合条件的题目时返回nil
+func (r *problemRepository) NextPublishAt(status string) (*time.Time, error) {
+	var problems []model.Problem
+	if err := r.db.Select("publish_at").Where("status = ? AND publish_at IS NOT NULL", status).Order("publish_at").Limit(1).Find(&problems).Error; err != nil {
+		return nil, err
+	}
+	if len(problems) == 0 {
+		return nil, nil
+	}
+	return problems[0].PublishAt, nil
+}
+
+// ListByIDs 按ID批量获取题目，已删除的题目不返回
+func (r *problemRepository) ListByIDs(ids []uint) ([]model.Problem, error) {
+	var problems []model.Problem
+	if len(ids) == 0 {
+		return problems, nil
+	}
+	if err := r.db.Where("id IN ?", ids).Order("id").Find(&problems).Error; err != nil {
+		return nil, err
+	}
+	return problems, nil
+}
+
+func (r *problemRepository) IDsByDirections(directionIDs []uint) ([]uint, error) {
+	var problemIDs []uint
+	if err := r.db.Model(&model.Problem{}).Where("direction_id IN ?", directionIDs).Pluck("id", &problemIDs).Error; err != nil {
+		return nil, err
+	}
+	return problemIDs, nil
+}
+
+func (r *problemRepository) CountByDirection(directionID uint) (int64, error) {
+	var count int64
+	if err := r.db.Model(&model.Problem{}).Where("direction_id = ?", directionID).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (r *problemRepository) Create(problem *model.Problem) error {
+	return r.db.Create(problem).Error
+}
+
+func (r *problemRepository) Update(problem *model.Problem, updates map[string]interface{}) error {
+	return r.db.Model(problem).Updates(updates).Error
+}
+
+// Delete 删除题目及其提交点和附件，三者使用同一删除时间
+func (r *problemRepository) Delete(problem *model.Problem) error {
+	tx := cascadeDeleteSession(r.db)
+	if err := tx.Where("problem_id = ?", problem.ID).Delete(&model.SubmissionPoint{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("problem_id = ?", problem.ID).Delete(&model.ProblemAttachment{}).Error; err != nil {
+		return err
+	}
+	return tx.Delete(problem).Error
+}
+
+func (r *problemRepository) FindPointByID(id uint, preloads ...string) (*model.SubmissionPoint, error) {
+	var point model.SubmissionPoint
+	if err := withPreloads(r.db, preloads).First(&point, id).Error; err != nil {
+		return nil, err
+	}
+	return &point, nil
+}
+
+func (r *problemRepository) FindPointInProblem(problemID, pointID uint) (*model.SubmissionPoint, error) {
+	var point model.SubmissionPoint
+	if err := r.db.Where("id = ? AND problem_id = ?", pointID, problemID).First(&point).Error; err != nil {
+		return nil, err
+	}
+	return &point, nil
+}
+
+func (r *problemRepository) ListPoints(problemID uint) ([]model.SubmissionPoint, error) {
+	var points []model.SubmissionPoint
+	if err := r.db.Preload("Problem").Where("problem_id = ?", problemID).Find(&points).Error; err != nil {
+		return nil, err
+	}
+	return points, nil
+}
+
+// ListReminderDuePoints 获取截止时间在(after, before]内且尚未发送截止提醒的提交点，预加载所属题目
+func (r *problemRepository) ListReminderDuePoints(after, before time.Time) ([]model.SubmissionPoint, error) {
+	var points []model.SubmissionPoint
+	if err := r.db.Preload("Problem").
+		Where("deadline IS NOT NULL AND deadline > ? AND deadline <= ? AND reminder_sent_at IS NULL", after, before).
+		Find(&points).Error; err != nil {
+		return nil, err
+	}
+	return points, nil
+}
+
+func (r *problemRepository) CreatePoint(point *model.SubmissionPoint) error {
+	return r.db.Create(point).Error
+}
+
+func (r *problemRepository) UpdatePoint(point *model.SubmissionPoint, updates map[string]interface{}) error {
+	return r.db.Model(point).Updates(updates).Error
+}
+
+func (r *problemRepository) DeletePoint(point *model.SubmissionPoint) error {
+	return r.db.Delete(point).Error
+}
+
+func (r *problemRepository) FindAttachmentByID(id uint, preloads ...string) (*model.ProblemAttachment, error) {
+	var attachment model.ProblemAttachment
+	if err := withPreloads(r.db, preloads).First(&attachment, id).Error; err != nil {
+		return nil, err
+	}
+	return &attachment, nil
+}
+
+// FindAttachmentByName 按文件名查找题目下的附件
+func (r *problemRepository) FindAttachmentByName(problemID uint, filename string) (*model.ProblemAttachment, error) {
+	var attachment model.ProblemAttachment
+	if err := r.db.Where("problem_id = ? AND filename = ?", problemID, filename).First(&attachment).Error; err != nil {
+		return nil, err
+	}
+	return &attachment, nil
+}
+
+// ListAttachments 获取题目下的附件，按文件名排序
+func (r *problemRepository) ListAttachments(problemID uint) ([]model.ProblemAttachment, error) {
+	var attachments []model.ProblemAttachment
+	if err := r.db.Where("problem_id = ?", problemID).Order("filename").Find(&attachments).Error; err != nil {
+		return nil, err
+	}
+	return attachments, nil
+}
+
+func (r *problemRepository) CreateAttachment(attachment *model.ProblemAttachment) error {
+	return r.db.Create(attachment).Error
+}
+
+func (r *problemRepository) DeleteAttachment(attachment *model.ProblemAttachment) error {
+	return r.db.Delete(attachment).Error
+}
+
+func (r *problemRepository) FindHintByID(id uint) (*model.ProblemHint, error) {
+	var hint model.ProblemHint
+	if err := r.db.First(&hint, id).Error; err != nil {
+		return nil, err
+	}
+	return &hint, nil
+}
+
+// ListHints 获取题目下的提示，按解锁顺序排序
+func (r *problemRepository) ListHints(problemID uint) ([]model.ProblemHint, error) {
+	var hints []model.ProblemHint
+	if err := r.db.Where("problem_id = ?", problemID).Order("position, id").Find(&hints).Error; err != nil {
+		return nil, err
+	}
+	return hints, nil
+}
+
+func (r *problemRepository) CreateHint(hint *model.ProblemHint) error {
+	return r.db.Create(hint).Error
+}
+
+func (r *problemRepository) UpdateHint(hint *model.ProblemHint, updates map[string]interface{}) error {
+	return r.db.Model(hint).Updates(updates).Error
+}
+
+func (r *problemRepository) DeleteHint(hint *model.ProblemHint) error {
+	return r.db.Delete(hint).Error
+}
+
+// HintUnlockCounts 统计题目下各提示的解锁人数
+func (r *problemRepository) HintUnlockCounts(problemID uint) (map[uint]int64, error) {
+	var rows []struct {
+		HintID uint
+		Count  int64
+	}
+	err := r.db.Model(&model.HintUnlock{}).
+		Select("hint_id, COUNT(*) AS count").
+		Where("problem_id = ?", problemID).
+		Group("hint_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	counts := make(map[uint]int64, len(rows))
+	for _, row := range rows {
+		counts[row.HintID] = row.Count
+	}
+	return counts, nil
+}
+
+// ListHintUnlocks 获取用户在题目上的提示解锁记录，problemID为0时不限题目
+func (r *problemRepository) ListHintUnlocks(userID, problemID uint) ([]model.HintUnlock, error) {
+	query := r.db.Where("user_id = ?", userID)
+	if problemID > 0 {
+		query = query.Where("problem_id = ?", problemID)
+	}
+
+	var unlocks []model.HintUnlock
+	if err := query.Order("id").Find(&unlocks).Error; err != nil {
+		return nil, err
+	}
+	return unlocks, nil
+}
+
+// CreateHintUnlock 记录提示解锁，由唯一索引idx_hint_unlocks_user_hint保证重复解锁只记录一次，返回是否为新记录
+func (r *problemRepository) CreateHintUnlock(unlock *model.HintUnlock) (bool, error) {
+	res := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(unlock)
+	return res.RowsAffected == 1, res.Error
+}
+
+// HintPenalties 按(用户, 题目)统计提示惩罚分，加入队伍的题目为队伍的惩罚分，与排行榜一致
+func (r *problemRepository) HintPenalties(problemIDs []uint) ([]HintPenalty, error) {
+	var penalties []HintPenalty
+	if len(problemIDs) == 0 {
+		return penalties, nil
+	}
+	if err := hintPenalties(r.db, problemIDs...).Scan(&penalties).Error; err != nil {
+		return nil, err
+	}
+	return penalties, nil
+}
+
+// FindRevision 查找题目的指定版本
+func (r *problemRepository) FindRevision(problemID uint, version int) (*model.ProblemRevision, error) {
+	var revision model.ProblemRevision
+	if err := r.db.Where("problem_id = ? AND version = ?", problemID, version).First(&revision).Error; err != nil {
+		return nil, err
+	}
+	return &revision, nil
+}
+
+// LatestRevision 查找题目的最新版本
+func (r *problemRepository) LatestRevision(problemID uint) (*model.ProblemRevision, error) {
+	var revision model.ProblemRevision
+	if err := r.db.Where("problem_id = ?", problemID).Order("version DESC").First(&revision).Error; err != nil {
+		return nil, err
+	}
+	return &revision, nil
+}
+
+// ListRevisions 获取题目的修订记录，按版本从新到旧排序，不加载题面
+func (r *problemRepository) ListRevisions(problemID uint) ([]model.ProblemRevision, error) {
+	var revisions []model.ProblemRevision
+	if err := r.db.Omit("description").Where("problem_id = ?", problemID).Order("version DESC").Find(&revisions).Error; err != nil {
+		return nil, err
+	}
+	return revisions, nil
+}
+
+// CreateRevision 写入修订记录，由唯一索引idx_problem_revisions_problem_version保证同一版本只写入一次
+func (r *problemRepository) CreateRevision(revision *model.ProblemRevision) error {
+	return r.db.Create(revision).Error
+}
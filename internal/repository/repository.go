@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrNotFound 记录不存在
+var ErrNotFound = gorm.ErrRecordNotFound
+
+// Repositories 数据访问层集合，同一实例中的仓储共享同一数据库连接或事务
+type Repositories struct {
+	db *gorm.DB
+
+	Users         UserRepository
+	Directions    DirectionRepository
+	Problems      ProblemRepository
+	Submissions   SubmissionRepository
+	Scores        ScoreRepository
+	Judge         JudgeRepository
+	Checks        CheckRepository
+	Similarity    SimilarityRepository
+	Teams         TeamRepository
+	AuditLogs     AuditLogRepository
+	Notifications NotificationRepository
+	Webhooks      WebhookRepository
+	Trash         TrashRepository
+}
+
+// NewRepositories 创建基于GORM的仓储集合
+func NewRepositories(db *gorm.DB) *Repositories {
+	return &Repositories{
+		db:            db,
+		Users:         NewUserRepository(db),
+		Directions:    NewDirectionRepository(db),
+		Problems:      NewProblemRepository(db),
+		Submissions:   NewSubmissionRepository(db),
+		Scores:        NewScoreRepository(db),
+		Judge:         NewJudgeRepository(db),
+		Checks:        NewCheckRepository(db),
+		Similarity:    NewSimilarityRepository(db),
+		Teams:         NewTeamRepository(db),
+		AuditLogs:     NewAuditLogRepository(db),
+		Notifications: NewNotificationRepository(db),
+		Webhooks:      NewWebhookRepository(db),
+		Trash:         NewTrashRepository(db),
+	}
+}
+
+// Transaction 在事务中执行fn，fn返回错误时回滚
+func (r *Repositories) Transaction(fn func(tx *Repositories) error) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return fn(NewRepositories(tx))
+	})
+}
+
+// withPreloads 依次预加载关联
+func withPreloads(db *gorm.DB, preloads []string) *gorm.DB {
+	for _, preload := range preloads {
+		db = db.Preload(preload)
+	}
+	return db
+}
+
+// cascadeDeleteSession 返回删除时间固定的会话，级联删除的记录共享同一删除时间，回收站恢复时据此识别
+func cascadeDeleteSession(db *gorm.DB) *gorm.DB {
+	now := db.NowFunc()
+	return db.Session(&gorm.Session{NowFunc: func() time.Time { return now }})
+}
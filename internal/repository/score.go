@@ -0,0 +1,306 @@
+package repository
+
+import (
+	"github.com/tksky1/glimgate/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RankingRow 排行榜统计行
+type RankingRow struct {
+	UserID   uint
+	Nickname string
+	Score    int
+}
+
+// ProblemScoreTotal 用户在一道题目上的总分
+type ProblemScoreTotal struct {
+	ProblemID uint
+	RawScore  int // 全部评分者的评分之和
+	Penalty   int // 提示惩罚分
+	Score     int // 扣除提示惩罚分后的得分，不低于0
+}
+
+// 队伍得分计入队员的方式
+const (
+	TeamScoreSplitFull  = "full"  // 每位队员计全部得分
+	TeamScoreSplitEqual = "equal" // 队员平分得分，向下取整
+)
+
+// problemTotalFilter 题目得分的统计范围，零值表示不限
+type problemTotalFilter struct {
+	userID      uint
+	problemID   uint
+	directionID uint
+	split       string // 队伍得分计入队员的方式，为空时按TeamScoreSplitFull
+}
+
+// creditedUser 评分计入的用户：组队提交为每位队员，个人提交为提交者
+const creditedUser = "COALESCE(tm.user_id, sub.user_id)"
+
+// hintPenalties 按(用户, 题目)统计提示惩罚分，结果列为user_id、problem_id和penalty，problemIDs为空时不限题目
+// 未加入队伍的题目为用户解锁的提示的惩罚分之和；加入队伍的题目按队伍统计，全体当前队员解锁的提示各计一次，
+// 每位队员都计队伍的惩罚分，队员分享提示内容同样会扣分
+func hintPenalties(db *gorm.DB, problemIDs ...uint) *gorm.DB {
+	// 加入队伍时按队伍统计，否则按用户统计；GROUP BY与SELECT使用同一表达式，兼容不支持按列别名分组的数据库
+	teamID := "COALESCE(tm.team_id, 0)"
+	soloID := "CASE WHEN tm.team_id IS NULL THEN hu.user_id ELSE 0 END"
+	perHint := db.Table("hint_unlocks hu").
+		Select(teamID + " AS team_id, " + soloID + " AS solo_id, hu.problem_id, hu.hint_id, MAX(hu.penalty) AS penalty").
+		Joins("LEFT JOIN team_members tm ON tm.user_id = hu.user_id AND tm.problem_id = hu.problem_id").
+		Group(teamID + ", " + soloID + ", hu.problem_id, hu.hint_id")
+	if len(problemIDs) > 0 {
+		perHint = perHint.Where("hu.problem_id IN ?", problemIDs)
+	}
+	perOwner := db.Table("(?) AS h", perHint).
+		Select("h.team_id, h.solo_id, h.problem_id, SUM(h.penalty) AS penalty").
+		Group("h.team_id, h.solo_id, h.problem_id")
+	return db.Table("(?) AS o", perOwner).
+		Select("COALESCE(m.user_id, o.solo_id) AS user_id, o.problem_id, o.penalty").
+		Joins("LEFT JOIN team_members m ON m.team_id = o.team_id")
+}
+
+// problemTotals 按(用户, 题目)统计题目得分，结果列为user_id、problem_id、direction_id、raw_score、penalty和score
+// 得分为全部评分者的评分之和扣除提示惩罚分（见hintPenalties），不低于0；已删除的提交和评分不计入，
+// 同一提交有人工评分时自动评测的系统评分不计入，没有评分的题目不出现在结果中。
+// 组队提交先由队伍的评分之和扣除队伍的惩罚分，再按filter.split计入每位当前队员，raw_score和penalty为队伍的数值
+func problemTotals(db *gorm.DB, filter problemTotalFilter) *gorm.DB {
+	columns := creditedUser + " AS user_id, sub.problem_id, p.direction_id, SUM(s.score) AS raw_score"
+	net := "CASE WHEN t.raw_score > COALESCE(hp.penalty, 0) THEN t.raw_score - COALESCE(hp.penalty, 0) ELSE 0 END"
+	score := net
+	if filter.split == TeamScoreSplitEqual {
+		// 三种数据库的整数除法写法不同，MySQL的/返回小数
+		div := "/"
+		if db.Dialector.Name() == "mysql" {
+			div = "DIV"
+		}
+		columns += ", MAX(COALESCE(ts.size, 1)) AS team_size"
+		score = "(" + net + ") " + div + " t.team_size"
+	}
+
+	scores := db.Table("scores s").
+		Select(columns).
+		Joins("JOIN submissions sub ON sub.id = s.submission_id AND sub.deleted_at IS NULL").
+		Joins("JOIN problems p ON p.id = sub.problem_id").
+		Joins("LEFT JOIN team_members tm ON tm.team_id = sub.team_id").
+		Where("s.deleted_at IS NULL").
+		Where("s.reviewer_id <> ? OR NOT EXISTS (SELECT 1 FROM scores h WHERE h.submission_id = s.submission_id AND h.reviewer_id <> ? AND h.deleted_at IS NULL)",
+			model.JudgeReviewerID, model.JudgeReviewerID)
+	if filter.split == TeamScoreSplitEqual {
+		sizes := db.Table("team_members").Select("team_id, COUNT(*) AS size").Group("team_id")
+		scores = scores.Joins("LEFT JOIN (?) AS ts ON ts.team_id = sub.team_id", sizes)
+	}
+	penalties := hintPenalties(db)
+	if filter.userID > 0 {
+		scores = scores.Where(creditedUser+" = ?", filter.userID)
+	}
+	if filter.problemID > 0 {
+		scores = scores.Where("sub.problem_id = ?", filter.problemID)
+		penalties = hintPenalties(db, filter.problemID)
+	}
+	if filter.directionID > 0 {
+		scores = scores.Where("p.direction_id = ?", filter.directionID)
+	}
+	scores = scores.Group(creditedUser + ", sub.problem_id, p.direction_id")
+
+	return db.Table("(?) AS t", scores).
+		Select("t.user_id, t.problem_id, t.direction_id, t.raw_score, COALESCE(hp.penalty, 0) AS penalty, "+score+" AS score").
+		Joins("LEFT JOIN (?) AS hp ON hp.user_id = t.user_id AND hp.problem_id = t.problem_id", penalties)
+}
+
+// ScoreRepository 评分数据访问接口
+type ScoreRepository interface {
+	FindByID(id uint, preloads ...string) (*model.Score, error)
+	FindByIDAndReviewer(id, reviewerID uint) (*model.Score, error)
+	FindBySubmissionAndReviewer(submissionID, reviewerID uint, preloads ...string) (*model.Score, error)
+	ListBySubmission(submissionID uint) ([]model.Score, error)
+	ListByUser(userID, problemID uint) ([]model.Score, error)
+	ListByReviewer(reviewerID, problemID uint) ([]model.Score, error)
+	Upsert(score *model.Score) (bool, error)
+	Update(score *model.Score, updates map[string]interface{}) error
+	Delete(score *model.Score) error
+	Ranking(directionID uint, limit int, split string) ([]RankingRow, error)
+	SyncUserIDs() (int64, error)
+	ProblemTotalsByUser(userID uint, split string) ([]ProblemScoreTotal, error)
+	CandidateIDsWithMinScore(problemID uint, minScore int, split string) ([]uint, error)
+}
+
+type scoreRepository struct {
+	db *gorm.DB
+}
+
+// NewScoreRepository 创建评分仓储
+func NewScoreRepository(db *gorm.DB) ScoreRepository {
+	return &scoreRepository{db: db}
+}
+
+func (r *scoreRepository) FindByID(id uint, preloads ...string) (*model.Score, error) {
+	var score model.Score
+	if err := withPreloads(r.db, preloads).First(&score, id).Error; err != nil {
+		return nil, err
+	}
+	return &score, nil
+}
+
+func (r *scoreRepository) FindByIDAndReviewer(id, reviewerID uint) (*model.Score, error) {
+	var score model.Score
+	if err := r.db.Where("id = ? AND reviewer_id = ?", id, reviewerID).First(&score).Error; err != nil {
+		return nil, err
+	}
+	return &score, nil
+}
+
+func (r *scoreRepository) FindBySubmissionAndReviewer(submissionID, reviewerID uint, preloads ...string) (*model.Score, error) {
+	var score model.Score
+	if err := withPreloads(r.db, preloads).Where("submission_id = ? AND reviewer_id = ?", submissionID, reviewerID).First(&score).Error; err != nil {
+		return nil, err
+	}
+	return &score, nil
+}
+
+func (r *scoreRepository) ListBySubmission(submissionID uint) ([]model.Score, error) {
+	var scores []model.Score
+	if err := r.db.Preload("User").Preload("Submission").Preload("Reviewer").Where("submission_id = ?", submissionID).Find(&scores).Error; err != nil {
+		return nil, err
+	}
+	return scores, nil
+}
+
+// ListByUser 获取用户及其所在队伍的提交获得的评分，problemID为0时不限题目
+func (r *scoreRepository) ListByUser(userID, problemID uint) ([]model.Score, error) {
+	query := r.db.Preload("User").Preload("Submission").Preload("Reviewer").
+		Where("scores.user_id = ? OR scores.submission_id IN (?)", userID,
+			r.db.Table("submissions sub").Select("sub.id").
+				Joins("JOIN team_members tm ON tm.team_id = sub.team_id").
+				Where("tm.user_id = ?", userID))
+	if problemID > 0 {
+		query = query.Joins("JOIN submissions ON scores.submission_id = submissions.id").
+			Where("submissions.problem_id = ?", problemID)
+	}
+
+	var scores []model.Score
+	if err := query.Find(&scores).Error; err != nil {
+		return nil, err
+	}
+	return scores, nil
+}
+
+// ListByReviewer 获取评分者给出的评分，problemID为0时不限题目
+func (r *scoreRepository) ListByReviewer(reviewerID, problemID uint) ([]model.Score, error) {
+	return r.list("reviewer_id", reviewerID, problemID)
+}
+
+func (r *scoreRepository) list(column string, id, problemID uint) ([]model.Score, error) {
+	query := r.db.Preload("User").Preload("Submission").Preload("Reviewer").Where("scores."+column+" = ?", id)
+
+	if problemID > 0 {
+		// 需要通过submission表关联查询
+		query = query.Joins("JOIN submissions ON scores.submission_id = submissions.id").
+			Where("submissions.problem_id = ?", problemID)
+	}
+
+	var scores []model.Score
+	if err := query.Find(&scores).Error; err != nil {
+		return nil, err
+	}
+	return scores, nil
+}
+
+// Upsert 按(提交, 评分者)写入评分：不存在时创建，已删除时恢复并覆盖，否则更新分数和评语
+// 由唯一索引idx_scores_submission_reviewer保证并发请求只产生一条记录，返回是否为新评分（含恢复已删除的评分）
+func (r *scoreRepository) Upsert(score *model.Score) (bool, error) {
+	res := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(score)
+	if res.Error != nil {
+		return false, res.Error
+	}
+	if res.RowsAffected == 1 {
+		return true, nil
+	}
+
+	// 已删除的评分恢复后视为新评分
+	res = r.db.Unscoped().Model(&model.Score{}).
+		Where("submission_id = ? AND reviewer_id = ? AND deleted_at IS NOT NULL", score.SubmissionID, score.ReviewerID).
+		Updates(map[string]interface{}{"score": score.Score, "comment": score.Comment, "deleted_at": nil})
+	if res.Error != nil {
+		return false, res.Error
+	}
+	if res.RowsAffected == 1 {
+		return true, nil
+	}
+
+	err := r.db.Model(&model.Score{}).
+		Where("submission_id = ? AND reviewer_id = ?", score.SubmissionID, score.ReviewerID).
+		Updates(map[string]interface{}{"score": score.Score, "comment": score.Comment}).Error
+	return false, err
+}
+
+func (r *scoreRepository) Update(score *model.Score, updates map[string]interface{}) error {
+	return r.db.Model(score).Updates(updates).Error
+}
+
+func (r *scoreRepository) Delete(score *model.Score) error {
+	return r.db.Delete(score).Error
+}
+
+// Ranking 按总分统计排行榜，directionID为0时不限方向，limit为0时不限数量，split为队伍得分计入队员的方式
+// 总分为各题目扣除提示惩罚分后的得分之和；指定方向时只包含在该方向有评分的用户
+// 使用查询构造器生成SQL，兼容MySQL、PostgreSQL和SQLite；已删除的用户、提交和评分不计入
+func (r *scoreRepository) Ranking(directionID uint, limit int, split string) ([]RankingRow, error) {
+	totals := r.db.Table("(?) AS pt", problemTotals(r.db, problemTotalFilter{directionID: directionID, split: split})).
+		Select("pt.user_id, SUM(pt.score) AS score").
+		Group("pt.user_id")
+
+	join := "LEFT JOIN (?) AS t ON t.user_id = u.id"
+	if directionID > 0 {
+		join = "JOIN (?) AS t ON t.user_id = u.id"
+	}
+	query := r.db.Table("users u").
+		Select("u.id AS user_id, u.nickname, COALESCE(t.score, 0) AS score").
+		Joins(join, totals).
+		Where("u.deleted_at IS NULL").
+		Order("score DESC, u.id ASC")
+
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+
+	var rankings []RankingRow
+	if err := query.Scan(&rankings).Error; err != nil {
+		return nil, err
+	}
+
+	return rankings, nil
+}
+
+// SyncUserIDs 将评分的user_id校正为所属提交的user_id（含已删除的记录），返回校正的评分数
+// 排行榜按评分的user_id统计，该冗余字段与提交不一致时排行榜会算错
+func (r *scoreRepository) SyncUserIDs() (int64, error) {
+	res := r.db.Exec(`UPDATE scores SET user_id = (SELECT submissions.user_id FROM submissions WHERE submissions.id = scores.submission_id)
+		WHERE user_id <> (SELECT submissions.user_id FROM submissions WHERE submissions.id = scores.submission_id)`)
+	return res.RowsAffected, res.Error
+}
+
+// ProblemTotalsByUser 按题目统计用户获得的总分（扣除提示惩罚分，与排行榜一致），已删除的提交和评分不计入
+func (r *scoreRepository) ProblemTotalsByUser(userID uint, split string) ([]ProblemScoreTotal, error) {
+	var totals []ProblemScoreTotal
+	err := problemTotals(r.db, problemTotalFilter{userID: userID, split: split}).
+		Order("t.problem_id").
+		Scan(&totals).Error
+	if err != nil {
+		return nil, err
+	}
+	return totals, nil
+}
+
+// CandidateIDsWithMinScore 获取在题目上总分（扣除提示惩罚分）不低于minScore的非管理员用户ID，已删除的用户不计入
+func (r *scoreRepository) CandidateIDsWithMinScore(problemID uint, minScore int, split string) ([]uint, error) {
+	var userIDs []uint
+	err := r.db.Table("(?) AS pt", problemTotals(r.db, problemTotalFilter{problemID: problemID, split: split})).
+		Joins("JOIN users u ON u.id = pt.user_id AND u.deleted_at IS NULL AND u.is_admin = ?", false).
+		Where("pt.score >= ?", minScore).
+		Pluck("pt.user_id", &userIDs).Error
+	if err != nil {
+		return nil, err
+	}
+	return userIDs, nil
+}
@@ -0,0 +1,326 @@
+package repository
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/tksky1/glimgate/internal/model"
+	"github.com/tksky1/glimgate/pkg/database/dbtest"
+	"gorm.io/gorm"
+)
+
+// scoreFixture 排行榜测试数据：两个方向，每个方向一道个人题目，另有一道组队题目
+type scoreFixture struct {
+	db    *gorm.DB
+	repos *Repositories
+
+	users                   []model.User
+	backend, frontend, team model.Problem
+}
+
+func newScoreFixture(t *testing.T) *scoreFixture {
+	t.Helper()
+	db := dbtest.New(t)
+	f := &scoreFixture{db: db, repos: NewRepositories(db)}
+
+	for i := 0; i < 5; i++ {
+		user := model.User{
+			Username:  fmt.Sprint("user", i),
+			Password:  "x",
+			Nickname:  fmt.Sprint("选手", i),
+			RealName:  "r",
+			College:   "c",
+			StudentID: fmt.Sprint(i),
+			IsAdmin:   i == 0,
+		}
+		f.create(t, &user)
+		f.users = append(f.users, user)
+	}
+
+	backend := model.Direction{Name: "后端"}
+	frontend := model.Direction{Name: "前端"}
+	f.create(t, &backend)
+	f.create(t, &frontend)
+	f.backend = model.Problem{Title: "后端题", Description: "d", DirectionID: backend.ID, Status: "published"}
+	f.frontend = model.Problem{Title: "前端题", Description: "d", DirectionID: frontend.ID, Status: "published"}
+	f.team = model.Problem{Title: "组队题", Description: "d", DirectionID: backend.ID, Status: "published", TeamSize: 3}
+	f.create(t, &f.backend)
+	f.create(t, &f.frontend)
+	f.create(t, &f.team)
+	return f
+}
+
+func (f *scoreFixture) create(t *testing.T, value interface{}) {
+	t.Helper()
+	if err := f.db.Create(value).Error; err != nil {
+		t.Fatalf("创建测试数据失败: %v", err)
+	}
+}
+
+// submit 创建提交点和提交，teamID为0时为个人提交
+func (f *scoreFixture) submit(t *testing.T, userID uint, problem model.Problem, teamID uint) model.Submission {
+	t.Helper()
+	point := model.SubmissionPoint{ProblemID: problem.ID, Name: fmt.Sprint("point", userID), MaxScore: 100, Type: "manual"}
+	f.create(t, &point)
+	sub := model.Submission{Content: "x", UserID: userID, ProblemID: problem.ID, SubmissionPointID: point.ID}
+	if teamID > 0 {
+		sub.TeamID = &teamID
+	}
+	f.create(t, &sub)
+	return sub
+}
+
+func (f *scoreFixture) score(t *testing.T, sub model.Submission, reviewerID uint, score int) model.Score {
+	t.Helper()
+	s := model.Score{Score: score, UserID: sub.UserID, SubmissionID: sub.ID, ReviewerID: reviewerID}
+	f.create(t, &s)
+	return s
+}
+
+// unlockHint 记录用户解锁了题目的一条提示
+func (f *scoreFixture) unlockHint(t *testing.T, userID uint, problem model.Problem, penalty int) {
+	t.Helper()
+	hint := model.ProblemHint{ProblemID: problem.ID, Content: "提示", Penalty: penalty}
+	f.create(t, &hint)
+	f.create(t, &model.HintUnlock{UserID: userID, HintID: hint.ID, ProblemID: problem.ID, Penalty: penalty})
+}
+
+// createTeam 创建组队题目的队伍，第一个成员为队长
+func (f *scoreFixture) createTeam(t *testing.T, members ...uint) model.Team {
+	t.Helper()
+	team := model.Team{ProblemID: f.team.ID, Name: fmt.Sprint("队伍", members[0]), LeaderID: members[0]}
+	f.create(t, &team)
+	for _, userID := range members {
+		f.create(t, &model.TeamMember{TeamID: team.ID, ProblemID: f.team.ID, UserID: userID})
+	}
+	return team
+}
+
+// rankingScores 将排行榜转换为用户ID到总分的映射，并检查排序
+func rankingScores(t *testing.T, rows []RankingRow) map[uint]int {
+	t.Helper()
+	scores := make(map[uint]int, len(rows))
+	for i, row := range rows {
+		if i > 0 {
+			prev := rows[i-1]
+			if prev.Score < row.Score || (prev.Score == row.Score && prev.UserID > row.UserID) {
+				t.Fatalf("排行榜排序错误: %+v", rows)
+			}
+		}
+		scores[row.UserID] = row.Score
+	}
+	return scores
+}
+
+func TestRankingSumsProblemScores(t *testing.T) {
+	f := newScoreFixture(t)
+	u1, u2, u3 := f.users[1].ID, f.users[2].ID, f.users[3].ID
+	reviewerA, reviewerB := f.users[0].ID, f.users[4].ID
+
+	// 两位评分者的评分相加，不同题目的得分相加
+	sub := f.submit(t, u1, f.backend, 0)
+	f.score(t, sub, reviewerA, 30)
+	f.score(t, sub, reviewerB, 20)
+	f.score(t, f.submit(t, u1, f.frontend, 0), reviewerA, 15)
+
+	// 有人工评分时系统评分不计入，只有系统评分时计入
+	sub = f.submit(t, u2, f.backend, 0)
+	f.score(t, sub, model.JudgeReviewerID, 100)
+	f.score(t, sub, reviewerA, 40)
+	f.score(t, f.submit(t, u2, f.frontend, 0), model.JudgeReviewerID, 25)
+
+	// 已删除的提交和评分不计入
+	sub = f.submit(t, u3, f.backend, 0)
+	f.score(t, sub, reviewerA, 90)
+	if err := f.db.Delete(&sub).Error; err != nil {
+		t.Fatal(err)
+	}
+	sub = f.submit(t, u3, f.frontend, 0)
+	deleted := f.score(t, sub, reviewerA, 80)
+	f.score(t, sub, reviewerB, 5)
+	if err := f.db.Delete(&deleted).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	rows, err := f.repos.Scores.Ranking(0, 0, TeamScoreSplitFull)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != len(f.users) {
+		t.Fatalf("排行榜应包含全部用户，得到%d行", len(rows))
+	}
+	got := rankingScores(t, rows)
+	want := map[uint]int{u1: 65, u2: 65, u3: 5, reviewerA: 0, reviewerB: 0}
+	for userID, score := range want {
+		if got[userID] != score {
+			t.Errorf("用户%d总分为%d，应为%d", userID, got[userID], score)
+		}
+	}
+	if rows[0].UserID != u1 || rows[0].Nickname != "选手1" {
+		t.Errorf("同分时应按用户ID升序: %+v", rows[0])
+	}
+
+	limited, err := f.repos.Scores.Ranking(0, 2, TeamScoreSplitFull)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(limited) != 2 || limited[1].UserID != u2 {
+		t.Errorf("limit=2的排行榜: %+v", limited)
+	}
+}
+
+func TestRankingHintPenalty(t *testing.T) {
+	f := newScoreFixture(t)
+	u1, u2 := f.users[1].ID, f.users[2].ID
+
+	f.score(t, f.submit(t, u1, f.backend, 0), f.users[0].ID, 50)
+	f.unlockHint(t, u1, f.backend, 10)
+	f.unlockHint(t, u1, f.backend, 5)
+	// 惩罚分超过得分时题目得分为0，不影响其他题目
+	f.score(t, f.submit(t, u2, f.backend, 0), f.users[0].ID, 20)
+	f.unlockHint(t, u2, f.backend, 30)
+	f.score(t, f.submit(t, u2, f.frontend, 0), f.users[0].ID, 12)
+	// 没有评分的题目不扣分
+	f.unlockHint(t, u1, f.frontend, 40)
+
+	rows, err := f.repos.Scores.Ranking(0, 0, TeamScoreSplitFull)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := rankingScores(t, rows)
+	if got[u1] != 35 || got[u2] != 12 {
+		t.Fatalf("扣除惩罚分后的总分: %v", got)
+	}
+
+	totals, err := f.repos.Scores.ProblemTotalsByUser(u2, TeamScoreSplitFull)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []ProblemScoreTotal{
+		{ProblemID: f.backend.ID, RawScore: 20, Penalty: 30, Score: 0},
+		{ProblemID: f.frontend.ID, RawScore: 12, Penalty: 0, Score: 12},
+	}
+	if len(totals) != len(want) {
+		t.Fatalf("题目得分: %+v", totals)
+	}
+	for i := range want {
+		if totals[i] != want[i] {
+			t.Errorf("题目得分%d为%+v，应为%+v", i, totals[i], want[i])
+		}
+	}
+}
+
+func TestRankingByDirection(t *testing.T) {
+	f := newScoreFixture(t)
+	u1, u2 := f.users[1].ID, f.users[2].ID
+
+	f.score(t, f.submit(t, u1, f.backend, 0), f.users[0].ID, 40)
+	f.score(t, f.submit(t, u1, f.frontend, 0), f.users[0].ID, 10)
+	f.score(t, f.submit(t, u2, f.frontend, 0), f.users[0].ID, 30)
+
+	// 指定方向时只包含在该方向有评分的用户，且只统计该方向的题目
+	rows, err := f.repos.Scores.Ranking(f.frontend.DirectionID, 0, TeamScoreSplitFull)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 2 || rows[0].UserID != u2 || rows[0].Score != 30 || rows[1].UserID != u1 || rows[1].Score != 10 {
+		t.Fatalf("前端方向排行榜: %+v", rows)
+	}
+
+	// 已删除的用户不出现在排行榜中
+	if err := f.db.Delete(&f.users[2]).Error; err != nil {
+		t.Fatal(err)
+	}
+	rows, err = f.repos.Scores.Ranking(f.frontend.DirectionID, 0, TeamScoreSplitFull)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 1 || rows[0].UserID != u1 {
+		t.Fatalf("删除用户后的排行榜: %+v", rows)
+	}
+}
+
+func TestRankingTeamScoreSplit(t *testing.T) {
+	f := newScoreFixture(t)
+	u1, u2, u3, u4 := f.users[1].ID, f.users[2].ID, f.users[3].ID, f.users[4].ID
+
+	trio := f.createTeam(t, u1, u2, u3)
+	solo := f.createTeam(t, u4)
+	sub := f.submit(t, u2, f.team, trio.ID)
+	f.score(t, sub, f.users[0].ID, 50)
+	f.score(t, sub, model.JudgeReviewerID, 100)
+	f.score(t, f.submit(t, u4, f.team, solo.ID), f.users[0].ID, 20)
+	f.unlockHint(t, u3, f.team, 5)
+	// 两名队员解锁同一提示只扣一次
+	hint := model.ProblemHint{ProblemID: f.team.ID, Content: "提示", Penalty: 3}
+	f.create(t, &hint)
+	f.create(t, &model.HintUnlock{UserID: u1, HintID: hint.ID, ProblemID: f.team.ID, Penalty: 3})
+	f.create(t, &model.HintUnlock{UserID: u2, HintID: hint.ID, ProblemID: f.team.ID, Penalty: 3})
+
+	tests := []struct {
+		split string
+		want  map[uint]int
+	}{
+		// 队伍得分扣除全体队员解锁的提示的惩罚分后，每位队员计全部得分
+		{TeamScoreSplitFull, map[uint]int{u1: 42, u2: 42, u3: 42, u4: 20}},
+		// 扣除惩罚分后的42分由三人平分为14，整数除法在各数据库上结果一致
+		{TeamScoreSplitEqual, map[uint]int{u1: 14, u2: 14, u3: 14, u4: 20}},
+		// 未知的方式按全部得分计入
+		{"", map[uint]int{u1: 42, u2: 42, u3: 42, u4: 20}},
+	}
+	for _, tt := range tests {
+		rows, err := f.repos.Scores.Ranking(0, 0, tt.split)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := rankingScores(t, rows)
+		for userID, score := range tt.want {
+			if got[userID] != score {
+				t.Errorf("split=%q 用户%d总分为%d，应为%d", tt.split, userID, got[userID], score)
+			}
+		}
+	}
+
+	// 平分时原始分和惩罚分为队伍的数值，得分为该队员分得的部分
+	totals, err := f.repos.Scores.ProblemTotalsByUser(u1, TeamScoreSplitEqual)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(totals) != 1 || totals[0] != (ProblemScoreTotal{ProblemID: f.team.ID, RawScore: 50, Penalty: 8, Score: 14}) {
+		t.Fatalf("队员的题目得分: %+v", totals)
+	}
+
+	// 评分汇总表使用的惩罚分同样按队伍计算
+	penalties, err := f.repos.Problems.HintPenalties([]uint{f.team.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	byUser := make(map[uint]int)
+	for _, p := range penalties {
+		byUser[p.UserID] = p.Penalty
+	}
+	if !reflect.DeepEqual(byUser, map[uint]int{u1: 8, u2: 8, u3: 8}) {
+		t.Errorf("队员的提示惩罚分: %v", byUser)
+	}
+}
+
+func TestCandidateIDsWithMinScore(t *testing.T) {
+	f := newScoreFixture(t)
+	admin, u1, u2, u3 := f.users[0].ID, f.users[1].ID, f.users[2].ID, f.users[3].ID
+
+	f.score(t, f.submit(t, admin, f.backend, 0), u1, 90)
+	f.score(t, f.submit(t, u1, f.backend, 0), admin, 60)
+	f.score(t, f.submit(t, u2, f.backend, 0), admin, 70)
+	f.unlockHint(t, u2, f.backend, 15)
+	f.score(t, f.submit(t, u3, f.frontend, 0), admin, 100)
+
+	ids, err := f.repos.Scores.CandidateIDsWithMinScore(f.backend.ID, 60, TeamScoreSplitFull)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 管理员不计入，扣除惩罚分后不足的用户不计入，其他题目的得分不计入
+	if len(ids) != 1 || ids[0] != u1 {
+		t.Fatalf("达到分数的选手: %v", ids)
+	}
+}
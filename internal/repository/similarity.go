@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"github.com/tksky1/glimgate/internal/model"
+	"gorm.io/gorm"
+)
+
+// SimilarityRepository 相似度报告数据访问接口
+type SimilarityRepository interface {
+	ReplaceReports(pointID uint, reports []model.SimilarityReport) error
+	UpdateFlags(pointID uint, scores map[uint]float64, threshold float64) error
+	ListByPoint(pointID uint, minScore float64) ([]model.SimilarityReport, error)
+	ListBySubmission(submissionID uint) ([]model.SimilarityReport, error)
+}
+
+type similarityRepository struct {
+	db *gorm.DB
+}
+
+// NewSimilarityRepository 创建相似度报告仓储
+func NewSimilarityRepository(db *gorm.DB) SimilarityRepository {
+	return &similarityRepository{db: db}
+}
+
+// ReplaceReports 删除提交点原有的相似度报告并写入新的报告
+func (r *similarityRepository) ReplaceReports(pointID uint, reports []model.SimilarityReport) error {
+	if err := r.db.Where("submission_point_id = ?", pointID).Delete(&model.SimilarityReport{}).Error; err != nil {
+		return err
+	}
+	if len(reports) == 0 {
+		return nil
+	}
+	return r.db.CreateInBatches(reports, 200).Error
+}
+
+// UpdateFlags 更新提交点下各提交的最高相似度和可疑标记，scores中没有的提交清零；不修改提交的更新时间
+func (r *similarityRepository) UpdateFlags(pointID uint, scores map[uint]float64, threshold float64) error {
+	if err := r.db.Model(&model.Submission{}).Where("submission_point_id = ?", pointID).
+		UpdateColumns(map[string]interface{}{"similarity_score": 0, "similarity_flagged": false}).Error; err != nil {
+		return err
+	}
+	for submissionID, score := range scores {
+		if err := r.db.Model(&model.Submission{}).Where("id = ?", submissionID).
+			UpdateColumns(map[string]interface{}{"similarity_score": score, "similarity_flagged": score >= threshold}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ListByPoint 获取提交点下相似度不低于minScore的报告及双方提交，按相似度从高到低排列
+func (r *similarityRepository) ListByPoint(pointID uint, minScore float64) ([]model.SimilarityReport, error) {
+	var reports []model.SimilarityReport
+	if err := r.db.Preload("SubmissionA.User").Preload("SubmissionB.User").
+		Where("submission_point_id = ? AND score >= ?", pointID, minScore).
+		Order("score DESC, id").Find(&reports).Error; err != nil {
+		return nil, err
+	}
+	return reports, nil
+}
+
+// ListBySubmission 获取涉及某个提交的报告及双方提交，按相似度从高到低排列
+func (r *similarityRepository) ListBySubmission(submissionID uint) ([]model.SimilarityReport, error) {
+	var reports []model.SimilarityReport
+	if err := r.db.Preload("SubmissionA.User").Preload("SubmissionB.User").
+		Where("submission_a_id = ? OR submission_b_id = ?", submissionID, submissionID).
+		Order("score DESC, id").Find(&reports).Error; err != nil {
+		return nil, err
+	}
+	return reports, nil
+}
@@ -0,0 +1,266 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/tksky1/glimgate/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SubmissionRepository 提交数据访问接口
+type SubmissionRepository interface {
+	FindByID(id uint, preloads ...string) (*model.Submission, error)
+	FindByIDAndUser(id, userID uint, preloads ...string) (*model.Submission, error)
+	FindByUserAndPoint(userID, pointID uint, preloads ...string) (*model.Submission, error)
+	ListByUser(userID, problemID uint) ([]model.Submission, error)
+	ListByProblems(problemIDs []uint) ([]model.Submission, error)
+	ListForExport(problemIDs []uint) ([]model.Submission, error)
+	CountByProblem(problemID uint) (int64, error)
+	CountAllByProblem(problemID uint) (int64, error)
+	CountByPoint(pointID uint) (int64, error)
+	IDsByPoint(pointID uint) ([]uint, error)
+	ExistsAtPoint(submission *model.Submission) (bool, error)
+	ListByPoint(pointID uint, preloads ...string) ([]model.Submission, error)
+	ProblemIDsByUser(userID uint) ([]uint, error)
+	CandidateIDsByProblem(problemID uint) ([]uint, error)
+	UserIDsMissingPoint(problemID, pointID uint) ([]uint, error)
+	LastSubmittedAt(userID uint, problemIDs []uint) (map[uint]time.Time, error)
+	Upsert(submission *model.Submission) (bool, error)
+	Delete(submission *model.Submission) error
+}
+
+type submissionRepository struct {
+	db *gorm.DB
+}
+
+// NewSubmissionRepository 创建提交仓储
+func NewSubmissionRepository(db *gorm.DB) SubmissionRepository {
+	return &submissionRepository{db: db}
+}
+
+func (r *submissionRepository) FindByID(id uint, preloads ...string) (*model.Submission, error) {
+	var submission model.Submission
+	if err := withPreloads(r.db, preloads).First(&submission, id).Error; err != nil {
+		return nil, err
+	}
+	return &submission, nil
+}
+
+// ownedBy 限定为用户自己的提交及其所在队伍的提交
+func (r *submissionRepository) ownedBy(db *gorm.DB, userID uint) *gorm.DB {
+	return db.Where("(user_id = ? OR team_id IN (?))", userID,
+		r.db.Model(&model.TeamMember{}).Select("team_id").Where("user_id = ?", userID))
+}
+
+// FindByIDAndUser 获取用户自己或其所在队伍的提交
+func (r *submissionRepository) FindByIDAndUser(id, userID uint, preloads ...string) (*model.Submission, error) {
+	var submission model.Submission
+	if err := r.ownedBy(withPreloads(r.db, preloads), userID).Where("id = ?", id).First(&submission).Error; err != nil {
+		return nil, err
+	}
+	return &submission, nil
+}
+
+// FindByUserAndPoint 获取用户自己或其所在队伍在提交点的提交
+func (r *submissionRepository) FindByUserAndPoint(userID, pointID uint, preloads ...string) (*model.Submission, error) {
+	var submission model.Submission
+	if err := r.ownedBy(withPreloads(r.db, preloads), userID).Where("submission_point_id = ?", pointID).First(&submission).Error; err != nil {
+		return nil, err
+	}
+	return &submission, nil
+}
+
+// ListByUser 获取用户自己及其所在队伍的提交，problemID为0时不限题目
+func (r *submissionRepository) ListByUser(userID, problemID uint) ([]model.Submission, error) {
+	query := r.ownedBy(r.db.Preload("User").Preload("Problem").Preload("SubmissionPoint").Preload("Scores").Preload("Team"), userID)
+	if problemID > 0 {
+		query = query.Where("problem_id = ?", problemID)
+	}
+
+	var submissions []model.Submission
+	if err := query.Find(&submissions).Error; err != nil {
+		return nil, err
+	}
+	return submissions, nil
+}
+
+func (r *submissionRepository) ListByProblems(problemIDs []uint) ([]model.Submission, error) {
+	var submissions []model.Submission
+	if err := r.db.Preload("User").Preload("Problem").Preload("SubmissionPoint").Preload("Scores").Preload("CheckRun").Preload("Team").
+		Where("problem_id IN ?", problemIDs).Find(&submissions).Error; err != nil {
+		return nil, err
+	}
+	return submissions, nil
+}
+
+// ListForExport 获取题目下的全部提交及评分者信息，按用户和提交点排序，用于导出
+func (r *submissionRepository) ListForExport(problemIDs []uint) ([]model.Submission, error) {
+	var submissions []model.Submission
+	if err := r.db.Preload("User").Preload("Problem").Preload("SubmissionPoint").Preload("Scores.Reviewer").
+		Where("problem_id IN ?", problemIDs).Order("user_id, submission_point_id").Find(&submissions).Error; err != nil {
+		return nil, err
+	}
+	return submissions, nil
+}
+
+func (r *submissionRepository) CountByProblem(problemID uint) (int64, error) {
+	var count int64
+	if err := r.db.Model(&model.Submission{}).Where("problem_id = ?", problemID).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// CountAllByProblem 统计题目下的提交数，已删除的提交也计入
+func (r *submissionRepository) CountAllByProblem(problemID uint) (int64, error) {
+	var count int64
+	if err := r.db.Unscoped().Model(&model.Submission{}).Where("problem_id = ?", problemID).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (r *submissionRepository) CountByPoint(pointID uint) (int64, error) {
+	var count int64
+	if err := r.db.Model(&model.Submission{}).Where("submission_point_id = ?", pointID).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// IDsByPoint 获取提交点下的提交ID
+func (r *submissionRepository) IDsByPoint(pointID uint) ([]uint, error) {
+	var ids []uint
+	if err := r.db.Model(&model.Submission{}).Where("submission_point_id = ?", pointID).Order("id").Pluck("id", &ids).Error; err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// ExistsAtPoint 判断提交者在同一提交点是否有其他未删除的提交，队伍提交按队伍判断
+func (r *submissionRepository) ExistsAtPoint(submission *model.Submission) (bool, error) {
+	query := r.db.Model(&model.Submission{}).Where("submission_point_id = ? AND id <> ?", submission.SubmissionPointID, submission.ID)
+	if submission.TeamID != nil {
+		query = query.Where("team_id = ?", *submission.TeamID)
+	} else {
+		query = query.Where("user_id = ?", submission.UserID)
+	}
+
+	var count int64
+	if err := query.Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// ListByPoint 获取提交点下的全部提交，按ID排序
+func (r *submissionRepository) ListByPoint(pointID uint, preloads ...string) ([]model.Submission, error) {
+	var submissions []model.Submission
+	if err := withPreloads(r.db, preloads).Where("submission_point_id = ?", pointID).Order("id").Find(&submissions).Error; err != nil {
+		return nil, err
+	}
+	return submissions, nil
+}
+
+// Upsert 按(用户, 提交点)写入提交，组队提交按(队伍, 提交点)：不存在时创建，已删除时恢复并覆盖内容，否则只更新内容
+// 由唯一索引idx_submissions_user_point和idx_submissions_team_point保证并发请求只产生一条记录，返回是否为新提交（含恢复已删除的提交）
+func (r *submissionRepository) Upsert(submission *model.Submission) (bool, error) {
+	key, owner := "user_id = ? AND submission_point_id = ?", submission.UserID
+	if submission.TeamID != nil {
+		key, owner = "team_id = ? AND submission_point_id = ?", *submission.TeamID
+	}
+
+	res := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(submission)
+	if res.Error != nil {
+		return false, res.Error
+	}
+	if res.RowsAffected == 1 {
+		return true, nil
+	}
+
+	// 已删除的提交恢复后视为新提交，原评分仍留在回收站中
+	res = r.db.Unscoped().Model(&model.Submission{}).
+		Where(key+" AND deleted_at IS NOT NULL", owner, submission.SubmissionPointID).
+		Updates(map[string]interface{}{"content": submission.Content, "deleted_at": nil})
+	if res.Error != nil {
+		return false, res.Error
+	}
+	if res.RowsAffected == 1 {
+		return true, nil
+	}
+
+	err := r.db.Model(&model.Submission{}).
+		Where(key, owner, submission.SubmissionPointID).
+		Update("content", submission.Content).Error
+	return false, err
+}
+
+// ProblemIDsByUser 获取用户自己或其所在队伍有提交的题目ID
+func (r *submissionRepository) ProblemIDsByUser(userID uint) ([]uint, error) {
+	var problemIDs []uint
+	if err := r.ownedBy(r.db.Model(&model.Submission{}), userID).Distinct().Pluck("problem_id", &problemIDs).Error; err != nil {
+		return nil, err
+	}
+	return problemIDs, nil
+}
+
+// CandidateIDsByProblem 获取在题目下有提交的非管理员用户ID，组队提交计入全部队员，已删除的用户不计入
+func (r *submissionRepository) CandidateIDsByProblem(problemID uint) ([]uint, error) {
+	var userIDs []uint
+	err := r.db.Table("submissions sub").
+		Joins("LEFT JOIN team_members tm ON tm.team_id = sub.team_id").
+		Joins("JOIN users u ON u.id = COALESCE(tm.user_id, sub.user_id) AND u.deleted_at IS NULL AND u.is_admin = ?", false).
+		Where("sub.problem_id = ? AND sub.deleted_at IS NULL", problemID).
+		Distinct().Pluck("u.id", &userIDs).Error
+	if err != nil {
+		return nil, err
+	}
+	return userIDs, nil
+}
+
+// UserIDsMissingPoint 获取在题目下有提交、但还没有提交该提交点的用户ID
+func (r *submissionRepository) UserIDsMissingPoint(problemID, pointID uint) ([]uint, error) {
+	submitted := r.db.Model(&model.Submission{}).Select("user_id").Where("submission_point_id = ?", pointID)
+
+	var userIDs []uint
+	if err := r.db.Model(&model.Submission{}).
+		Where("problem_id = ? AND user_id NOT IN (?)", problemID, submitted).
+		Distinct().
+		Pluck("user_id", &userIDs).Error; err != nil {
+		return nil, err
+	}
+	return userIDs, nil
+}
+
+// LastSubmittedAt 获取用户自己或其所在队伍在各题目上最后一次提交（含重新提交）的时间，没有提交的题目不在结果中
+func (r *submissionRepository) LastSubmittedAt(userID uint, problemIDs []uint) (map[uint]time.Time, error) {
+	var rows []struct {
+		ProblemID uint
+		UpdatedAt time.Time
+	}
+	err := r.ownedBy(r.db.Model(&model.Submission{}), userID).Select("problem_id, updated_at").
+		Where("problem_id IN ?", problemIDs).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	// SQLite中聚合后的时间列以字符串返回，在内存中取最大值以兼容三种数据库
+	latest := make(map[uint]time.Time, len(rows))
+	for _, row := range rows {
+		if row.UpdatedAt.After(latest[row.ProblemID]) {
+			latest[row.ProblemID] = row.UpdatedAt
+		}
+	}
+	return latest, nil
+}
+
+// Delete 删除提交及其评分，两者使用同一删除时间
+func (r *submissionRepository) Delete(submission *model.Submission) error {
+	tx := cascadeDeleteSession(r.db)
+	if err := tx.Where("submission_id = ?", submission.ID).Delete(&model.Score{}).Error; err != nil {
+		return err
+	}
+	return tx.Delete(submission).Error
+}
@@ -0,0 +1,215 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/tksky1/glimgate/internal/model"
+	"gorm.io/gorm"
+)
+
+// TeamRepository 队伍数据访问接口
+type TeamRepository interface {
+	Create(team *model.Team) error
+	FindByID(id uint, preloads ...string) (*model.Team, error)
+	Lock(id uint) error
+	FindByUserAndProblem(userID, problemID uint, preloads ...string) (*model.Team, error)
+	NameExists(problemID uint, name string) (bool, error)
+	ListByProblem(problemID uint) ([]model.Team, error)
+	Update(team *model.Team, updates map[string]interface{}) error
+	Delete(team *model.Team) error
+	DeleteByProblem(problemID uint) error
+	AddMember(member *model.TeamMember) error
+	RemoveMember(teamID, userID uint) error
+	ListMembers(teamID uint) ([]model.TeamMember, error)
+	MemberIDs(teamID uint) ([]uint, error)
+	IsMember(teamID, userID uint) (bool, error)
+	MaxMemberCount(problemID uint) (int64, error)
+	HasSubmissions(teamID uint) (bool, error)
+	CreateInvite(invite *model.TeamInvite) error
+	FindInvite(id uint, preloads ...string) (*model.TeamInvite, error)
+	FindInviteByTeamAndUser(teamID, userID uint) (*model.TeamInvite, error)
+	ListInvitesByUser(userID uint) ([]model.TeamInvite, error)
+	DeleteInvite(invite *model.TeamInvite) error
+	DeleteInvitesByUserAndProblem(userID, problemID uint) error
+}
+
+type teamRepository struct {
+	db *gorm.DB
+}
+
+// NewTeamRepository 创建队伍仓储
+func NewTeamRepository(db *gorm.DB) TeamRepository {
+	return &teamRepository{db: db}
+}
+
+func (r *teamRepository) Create(team *model.Team) error {
+	return r.db.Create(team).Error
+}
+
+func (r *teamRepository) FindByID(id uint, preloads ...string) (*model.Team, error) {
+	var team model.Team
+	if err := withPreloads(r.db, preloads).First(&team, id).Error; err != nil {
+		return nil, err
+	}
+	return &team, nil
+}
+
+// Lock 在事务中锁定队伍直到事务结束，用于串行化成员变动和提交
+// 通过更新updated_at取得行锁，三种数据库都支持，SQLite会取得整个数据库的写锁；队伍不存在时不做任何事
+func (r *teamRepository) Lock(id uint) error {
+	return r.db.Model(&model.Team{}).Where("id = ?", id).UpdateColumn("updated_at", time.Now()).Error
+}
+
+// FindByUserAndProblem 获取用户在题目中加入的队伍
+func (r *teamRepository) FindByUserAndProblem(userID, problemID uint, preloads ...string) (*model.Team, error) {
+	var team model.Team
+	err := withPreloads(r.db, preloads).
+		Where("id IN (?)", r.db.Model(&model.TeamMember{}).Select("team_id").Where("user_id = ? AND problem_id = ?", userID, problemID)).
+		First(&team).Error
+	if err != nil {
+		return nil, err
+	}
+	return &team, nil
+}
+
+// NameExists 判断题目下是否已有同名队伍
+func (r *teamRepository) NameExists(problemID uint, name string) (bool, error) {
+	var count int64
+	if err := r.db.Model(&model.Team{}).Where("problem_id = ? AND name = ?", problemID, name).Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// ListByProblem 获取题目下的全部队伍及成员，按ID排序
+func (r *teamRepository) ListByProblem(problemID uint) ([]model.Team, error) {
+	var teams []model.Team
+	if err := r.db.Preload("Leader").Preload("Members", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id")
+	}).Preload("Members.User").Where("problem_id = ?", problemID).Order("id").Find(&teams).Error; err != nil {
+		return nil, err
+	}
+	return teams, nil
+}
+
+func (r *teamRepository) Update(team *model.Team, updates map[string]interface{}) error {
+	return r.db.Model(team).Updates(updates).Error
+}
+
+// Delete 删除队伍及其成员和邀请
+func (r *teamRepository) Delete(team *model.Team) error {
+	if err := r.db.Where("team_id = ?", team.ID).Delete(&model.TeamInvite{}).Error; err != nil {
+		return err
+	}
+	if err := r.db.Where("team_id = ?", team.ID).Delete(&model.TeamMember{}).Error; err != nil {
+		return err
+	}
+	return r.db.Delete(team).Error
+}
+
+// DeleteByProblem 删除题目下的全部队伍及其成员和邀请
+func (r *teamRepository) DeleteByProblem(problemID uint) error {
+	teamIDs := r.db.Model(&model.Team{}).Select("id").Where("problem_id = ?", problemID)
+	if err := r.db.Where("team_id IN (?)", teamIDs).Delete(&model.TeamInvite{}).Error; err != nil {
+		return err
+	}
+	if err := r.db.Where("problem_id = ?", problemID).Delete(&model.TeamMember{}).Error; err != nil {
+		return err
+	}
+	return r.db.Where("problem_id = ?", problemID).Delete(&model.Team{}).Error
+}
+
+// AddMember 添加队伍成员，由唯一索引idx_team_members_problem_user保证用户在一道题目中只加入一支队伍
+func (r *teamRepository) AddMember(member *model.TeamMember) error {
+	return r.db.Create(member).Error
+}
+
+func (r *teamRepository) RemoveMember(teamID, userID uint) error {
+	return r.db.Where("team_id = ? AND user_id = ?", teamID, userID).Delete(&model.TeamMember{}).Error
+}
+
+// ListMembers 获取队伍成员，按加入顺序排列
+func (r *teamRepository) ListMembers(teamID uint) ([]model.TeamMember, error) {
+	var members []model.TeamMember
+	if err := r.db.Preload("User").Where("team_id = ?", teamID).Order("id").Find(&members).Error; err != nil {
+		return nil, err
+	}
+	return members, nil
+}
+
+// MemberIDs 获取队伍成员的用户ID，按加入顺序排列
+func (r *teamRepository) MemberIDs(teamID uint) ([]uint, error) {
+	var userIDs []uint
+	if err := r.db.Model(&model.TeamMember{}).Where("team_id = ?", teamID).Order("id").Pluck("user_id", &userIDs).Error; err != nil {
+		return nil, err
+	}
+	return userIDs, nil
+}
+
+func (r *teamRepository) IsMember(teamID, userID uint) (bool, error) {
+	var count int64
+	if err := r.db.Model(&model.TeamMember{}).Where("team_id = ? AND user_id = ?", teamID, userID).Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// MaxMemberCount 获取题目下人数最多的队伍的人数，没有队伍时为0
+func (r *teamRepository) MaxMemberCount(problemID uint) (int64, error) {
+	var count int64
+	sizes := r.db.Model(&model.TeamMember{}).Select("COUNT(*) AS size").Where("problem_id = ?", problemID).Group("team_id")
+	if err := r.db.Table("(?) AS t", sizes).Select("COALESCE(MAX(t.size), 0)").Scan(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// HasSubmissions 判断队伍是否有提交，已删除的提交也计入
+func (r *teamRepository) HasSubmissions(teamID uint) (bool, error) {
+	var count int64
+	if err := r.db.Unscoped().Model(&model.Submission{}).Where("team_id = ?", teamID).Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// CreateInvite 创建邀请，由唯一索引idx_team_invites_team_user保证同一队伍对同一用户只有一条邀请
+func (r *teamRepository) CreateInvite(invite *model.TeamInvite) error {
+	return r.db.Create(invite).Error
+}
+
+func (r *teamRepository) FindInvite(id uint, preloads ...string) (*model.TeamInvite, error) {
+	var invite model.TeamInvite
+	if err := withPreloads(r.db, preloads).First(&invite, id).Error; err != nil {
+		return nil, err
+	}
+	return &invite, nil
+}
+
+func (r *teamRepository) FindInviteByTeamAndUser(teamID, userID uint) (*model.TeamInvite, error) {
+	var invite model.TeamInvite
+	if err := r.db.Where("team_id = ? AND user_id = ?", teamID, userID).First(&invite).Error; err != nil {
+		return nil, err
+	}
+	return &invite, nil
+}
+
+// ListInvitesByUser 获取用户收到的邀请及所属队伍和邀请人，按时间倒序排列
+func (r *teamRepository) ListInvitesByUser(userID uint) ([]model.TeamInvite, error) {
+	var invites []model.TeamInvite
+	if err := r.db.Preload("Team").Preload("Inviter").Where("user_id = ?", userID).Order("id DESC").Find(&invites).Error; err != nil {
+		return nil, err
+	}
+	return invites, nil
+}
+
+func (r *teamRepository) DeleteInvite(invite *model.TeamInvite) error {
+	return r.db.Delete(invite).Error
+}
+
+// DeleteInvitesByUserAndProblem 删除用户收到的该题目下全部队伍的邀请
+func (r *teamRepository) DeleteInvitesByUserAndProblem(userID, problemID uint) error {
+	return r.db.Where("user_id = ? AND team_id IN (?)", userID,
+		r.db.Model(&model.Team{}).Select("id").Where("problem_id = ?", problemID)).
+		Delete(&model.TeamInvite{}).Error
+}
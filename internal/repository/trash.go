@@ -0,0 +1,219 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/tksky1/glimgate/internal/model"
+	"gorm.io/gorm"
+)
+
+// TrashRepository 回收站数据访问接口，查询和恢复已软删除的记录，彻底删除超过保留期的记录
+type TrashRepository interface {
+	ListDeleted(records interface{}, offset, limit int, preloads ...string) (int64, error)
+	FindDeleted(record interface{}, id uint) error
+	Restore(record interface{}, id uint) error
+	RestoreProblem(problem *model.Problem) error
+	RestoreSubmission(submission *model.Submission) error
+
+	PurgeScores(cutoff time.Time) (int64, error)
+	PurgeSubmissions(cutoff time.Time) (int64, error)
+	ListPurgeableJudgeCases(cutoff time.Time) ([]model.JudgeCase, error)
+	PurgeSubmissionPoints(cutoff time.Time) (int64, error)
+	ListPurgeableAttachments(cutoff time.Time) ([]model.ProblemAttachment, error)
+	PurgeAttachments(ids []uint) (int64, error)
+	PurgeProblems(cutoff time.Time) (int64, error)
+	PurgeDirections(cutoff time.Time) (int64, error)
+	PurgeUsers(cutoff time.Time) (int64, error)
+}
+
+type trashRepository struct {
+	db *gorm.DB
+}
+
+// NewTrashRepository 创建回收站仓储
+func NewTrashRepository(db *gorm.DB) TrashRepository {
+	return &trashRepository{db: db}
+}
+
+// ListDeleted 分页获取已删除的记录，最近删除的在前，records为模型切片的指针
+func (r *trashRepository) ListDeleted(records interface{}, offset, limit int, preloads ...string) (int64, error) {
+	query := r.db.Unscoped().Model(records).Where("deleted_at IS NOT NULL")
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return 0, err
+	}
+
+	if err := withPreloads(query, preloads).Order("deleted_at DESC").Offset(offset).Limit(limit).Find(records).Error; err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+// FindDeleted 获取已删除的记录，record为模型指针，记录不存在或未删除时返回ErrNotFound
+func (r *trashRepository) FindDeleted(record interface{}, id uint) error {
+	return r.db.Unscoped().Where("id = ? AND deleted_at IS NOT NULL", id).First(record).Error
+}
+
+// Restore 清除记录的删除标记，record为模型指针，只用于确定表
+func (r *trashRepository) Restore(record interface{}, id uint) error {
+	return r.db.Unscoped().Model(record).Where("id = ?", id).Update("deleted_at", nil).Error
+}
+
+// RestoreProblem 恢复题目及与其一同删除的提交点和附件
+func (r *trashRepository) RestoreProblem(problem *model.Problem) error {
+	deletedAt := problem.DeletedAt.Time
+	if err := r.db.Unscoped().Model(&model.SubmissionPoint{}).
+		Where("problem_id = ? AND deleted_at = ?", problem.ID, deletedAt).
+		Update("deleted_at", nil).Error; err != nil {
+		return err
+	}
+	if err := r.db.Unscoped().Model(&model.ProblemAttachment{}).
+		Where("problem_id = ? AND deleted_at = ?", problem.ID, deletedAt).
+		Update("deleted_at", nil).Error; err != nil {
+		return err
+	}
+	return r.Restore(&model.Problem{}, problem.ID)
+}
+
+// RestoreSubmission 恢复提交及与其一同删除的评分
+func (r *trashRepository) RestoreSubmission(submission *model.Submission) error {
+	if err := r.db.Unscoped().Model(&model.Score{}).
+		Where("submission_id = ? AND deleted_at = ?", submission.ID, submission.DeletedAt.Time).
+		Update("deleted_at", nil).Error; err != nil {
+		return err
+	}
+	return r.Restore(&model.Submission{}, submission.ID)
+}
+
+// PurgeScores 彻底删除在cutoff之前删除的评分
+func (r *trashRepository) PurgeScores(cutoff time.Time) (int64, error) {
+	res := r.db.Unscoped().Where("deleted_at < ?", cutoff).Delete(&model.Score{})
+	return res.RowsAffected, res.Error
+}
+
+// PurgeSubmissions 彻底删除在cutoff之前删除且不再有评分的提交
+func (r *trashRepository) PurgeSubmissions(cutoff time.Time) (int64, error) {
+	res := r.db.Unscoped().Where("deleted_at < ?", cutoff).
+		Where("NOT EXISTS (SELECT 1 FROM scores WHERE scores.submission_id = submissions.id)").
+		Delete(&model.Submission{})
+	return res.RowsAffected, res.Error
+}
+
+// purgeablePoints 在cutoff之前删除且不再有提交的提交点
+func (r *trashRepository) purgeablePoints(cutoff time.Time) *gorm.DB {
+	return r.db.Unscoped().Model(&model.SubmissionPoint{}).
+		Where("deleted_at < ?", cutoff).
+		Where("NOT EXISTS (SELECT 1 FROM submissions WHERE submissions.submission_point_id = submission_points.id)")
+}
+
+// ListPurgeableJudgeCases 获取将随提交点一同彻底删除的测试点
+func (r *trashRepository) ListPurgeableJudgeCases(cutoff time.Time) ([]model.JudgeCase, error) {
+	var cases []model.JudgeCase
+	if err := r.db.Where("submission_point_id IN (?)", r.purgeablePoints(cutoff).Select("id")).Find(&cases).Error; err != nil {
+		return nil, err
+	}
+	return cases, nil
+}
+
+// PurgeSubmissionPoints 彻底删除在cutoff之前删除且不再有提交的提交点，其测试点和检查步骤由外键级联删除
+func (r *trashRepository) PurgeSubmissionPoints(cutoff time.Time) (int64, error) {
+	// MySQL不允许删除语句的子查询读取同一张表，先取出ID
+	var pointIDs []uint
+	if err := r.purgeablePoints(cutoff).Pluck("id", &pointIDs).Error; err != nil {
+		return 0, err
+	}
+	if len(pointIDs) == 0 {
+		return 0, nil
+	}
+	res := r.db.Unscoped().Where("id IN ?", pointIDs).Delete(&model.SubmissionPoint{})
+	return res.RowsAffected, res.Error
+}
+
+// ListPurgeableAttachments 获取在cutoff之前删除的附件
+func (r *trashRepository) ListPurgeableAttachments(cutoff time.Time) ([]model.ProblemAttachment, error) {
+	var attachments []model.ProblemAttachment
+	if err := r.db.Unscoped().Where("deleted_at < ?", cutoff).Find(&attachments).Error; err != nil {
+		return nil, err
+	}
+	return attachments, nil
+}
+
+// PurgeAttachments 彻底删除指定的附件记录
+func (r *trashRepository) PurgeAttachments(ids []uint) (int64, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	res := r.db.Unscoped().Where("id IN ?", ids).Delete(&model.ProblemAttachment{})
+	return res.RowsAffected, res.Error
+}
+
+// PurgeProblems 彻底删除在cutoff之前删除且不再被提交点、附件、提交和提问引用的题目
+func (r *trashRepository) PurgeProblems(cutoff time.Time) (int64, error) {
+	res := r.db.Unscoped().Where("deleted_at < ?", cutoff).
+		Where("NOT EXISTS (SELECT 1 FROM submission_points WHERE submission_points.problem_id = problems.id)").
+		Where("NOT EXISTS (SELECT 1 FROM problem_attachments WHERE problem_attachments.problem_id = problems.id)").
+		Where("NOT EXISTS (SELECT 1 FROM submissions WHERE submissions.problem_id = problems.id)").
+		Where("NOT EXISTS (SELECT 1 FROM clarifications WHERE clarifications.problem_id = problems.id)").
+		Delete(&model.Problem{})
+	return res.RowsAffected, res.Error
+}
+
+// PurgeDirections 彻底删除在cutoff之前删除且不再有题目的方向，连同其负责人关联
+func (r *trashRepository) PurgeDirections(cutoff time.Time) (int64, error) {
+	var directionIDs []uint
+	if err := r.db.Unscoped().Model(&model.Direction{}).
+		Where("deleted_at < ?", cutoff).
+		Where("NOT EXISTS (SELECT 1 FROM problems WHERE problems.direction_id = directions.id)").
+		Pluck("id", &directionIDs).Error; err != nil {
+		return 0, err
+	}
+	if len(directionIDs) == 0 {
+		return 0, nil
+	}
+
+	var count int64
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM direction_managers WHERE direction_id IN ?", directionIDs).Error; err != nil {
+			return err
+		}
+		res := tx.Unscoped().Where("id IN ?", directionIDs).Delete(&model.Direction{})
+		count = res.RowsAffected
+		return res.Error
+	})
+	return count, err
+}
+
+// PurgeUsers 彻底删除在cutoff之前删除且不再被提交、评分、提问和队伍引用的用户，连同其负责人关联和通知
+func (r *trashRepository) PurgeUsers(cutoff time.Time) (int64, error) {
+	var userIDs []uint
+	if err := r.db.Unscoped().Model(&model.User{}).
+		Where("deleted_at < ?", cutoff).
+		Where("NOT EXISTS (SELECT 1 FROM submissions WHERE submissions.user_id = users.id)").
+		Where("NOT EXISTS (SELECT 1 FROM scores WHERE scores.user_id = users.id OR scores.reviewer_id = users.id)").
+		Where("NOT EXISTS (SELECT 1 FROM clarifications WHERE clarifications.asker_id = users.id OR clarifications.answerer_id = users.id)").
+		Where("NOT EXISTS (SELECT 1 FROM teams WHERE teams.leader_id = users.id)").
+		Pluck("id", &userIDs).Error; err != nil {
+		return 0, err
+	}
+	if len(userIDs) == 0 {
+		return 0, nil
+	}
+
+	var count int64
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM direction_managers WHERE user_id IN ?", userIDs).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Where("user_id IN ?", userIDs).Delete(&model.Notification{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Where("user_id IN ?", userIDs).Delete(&model.NotificationSetting{}).Error; err != nil {
+			return err
+		}
+		res := tx.Unscoped().Where("id IN ?", userIDs).Delete(&model.User{})
+		count = res.RowsAffected
+		return res.Error
+	})
+	return count, err
+}
@@ -0,0 +1,189 @@
+package repository
+
+import (
+	"github.com/tksky1/glimgate/internal/model"
+	"gorm.io/gorm"
+)
+
+// 选手的招新阶段，按提交和评分情况划分
+const (
+	UserStageRegistered = "registered" // 已注册，还没有提交
+	UserStageSubmitted  = "submitted"  // 有提交，但都还没有评分
+	UserStageScored     = "scored"     // 至少有一个提交已评分
+)
+
+// UserScoreFilter 用户总分查询条件
+type UserScoreFilter struct {
+	DirectionID uint   // 只统计该方向的得分，并只返回在该方向有提交的用户
+	Stage       string // 只返回处于该阶段的选手，不含管理员；指定方向时按该方向的提交划分
+	MinScore    *int
+	MaxScore    *int
+}
+
+// UserScoreRow 用户及其总分
+type UserScoreRow struct {
+	model.User `gorm:"embedded"`
+	TotalScore int
+}
+
+// UserRepository 用户数据访问接口
+type UserRepository interface {
+	FindByID(id uint) (*model.User, error)
+	FindByUsername(username string) (*model.User, error)
+	List(offset, limit int) ([]model.User, int64, error)
+	CountAdmins() (int64, error)
+	CountCandidates() (int64, error)
+	ListByIDs(ids []uint) ([]model.User, error)
+	ListByUsernames(usernames []string) ([]model.User, error)
+	ListByStudentIDs(studentIDs []string) ([]model.User, error)
+	ListWithScores(filter UserScoreFilter) ([]UserScoreRow, error)
+	Create(user *model.User) error
+	Update(user *model.User, updates map[string]interface{}) error
+	Delete(user *model.User) error
+}
+
+type userRepository struct {
+	db *gorm.DB
+}
+
+// NewUserRepository 创建用户仓储
+func NewUserRepository(db *gorm.DB) UserRepository {
+	return &userRepository{db: db}
+}
+
+func (r *userRepository) FindByID(id uint) (*model.User, error) {
+	var user model.User
+	if err := r.db.First(&user, id).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r *userRepository) FindByUsername(username string) (*model.User, error) {
+	var user model.User
+	if err := r.db.Where("username = ?", username).First(&user).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r *userRepository) List(offset, limit int) ([]model.User, int64, error) {
+	var total int64
+	if err := r.db.Model(&model.User{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var users []model.User
+	if err := r.db.Offset(offset).Limit(limit).Find(&users).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return users, total, nil
+}
+
+func (r *userRepository) CountAdmins() (int64, error) {
+	var count int64
+	if err := r.db.Model(&model.User{}).Where("is_admin = ?", true).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// CountCandidates 统计非管理员用户数量
+func (r *userRepository) CountCandidates() (int64, error) {
+	var count int64
+	if err := r.db.Model(&model.User{}).Where("is_admin = ?", false).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (r *userRepository) ListByIDs(ids []uint) ([]model.User, error) {
+	var users []model.User
+	if len(ids) == 0 {
+		return users, nil
+	}
+	if err := r.db.Where("id IN ?", ids).Find(&users).Error; err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// ListByUsernames 按用户名查找用户，包含已删除的用户（用户名唯一索引同样约束已删除的记录）
+func (r *userRepository) ListByUsernames(usernames []string) ([]model.User, error) {
+	var users []model.User
+	if len(usernames) == 0 {
+		return users, nil
+	}
+	if err := r.db.Unscoped().Where("username IN ?", usernames).Find(&users).Error; err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+func (r *userRepository) ListByStudentIDs(studentIDs []string) ([]model.User, error) {
+	var users []model.User
+	if len(studentIDs) == 0 {
+		return users, nil
+	}
+	if err := r.db.Where("student_id IN ?", studentIDs).Find(&users).Error; err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// ListWithScores 查询用户及其总分（扣除提示惩罚分，与排行榜一致），按用户ID排序
+func (r *userRepository) ListWithScores(filter UserScoreFilter) ([]UserScoreRow, error) {
+	totals := r.db.Table("(?) AS pt", problemTotals(r.db, problemTotalFilter{directionID: filter.DirectionID})).
+		Select("pt.user_id, SUM(pt.score) AS total").
+		Group("pt.user_id")
+
+	users := r.db.Table("users u").
+		Select("u.*, COALESCE(ts.total, 0) AS total_score").
+		Joins("LEFT JOIN (?) AS ts ON ts.user_id = u.id", totals).
+		Where("u.deleted_at IS NULL")
+	// submitted 用户的提交，scored 用户已评分的提交，指定方向时只包括该方向的提交
+	submitted := r.db.Table("submissions sub").Where("sub.user_id = u.id AND sub.deleted_at IS NULL")
+	scored := r.db.Table("scores sc").
+		Joins("JOIN submissions sub ON sub.id = sc.submission_id").
+		Where("sc.deleted_at IS NULL AND sub.user_id = u.id AND sub.deleted_at IS NULL")
+	if filter.DirectionID > 0 {
+		submitted = submitted.Joins("JOIN problems p ON p.id = sub.problem_id").Where("p.direction_id = ?", filter.DirectionID)
+		scored = scored.Joins("JOIN problems p ON p.id = sub.problem_id").Where("p.direction_id = ?", filter.DirectionID)
+		users = users.Where("EXISTS (?)", submitted)
+	}
+	switch filter.Stage {
+	case UserStageRegistered:
+		users = users.Where("u.is_admin = ? AND NOT EXISTS (?)", false, submitted)
+	case UserStageSubmitted:
+		users = users.Where("u.is_admin = ? AND EXISTS (?) AND NOT EXISTS (?)", false, submitted, scored)
+	case UserStageScored:
+		users = users.Where("u.is_admin = ? AND EXISTS (?)", false, scored)
+	}
+
+	query := r.db.Table("(?) AS t", users)
+	if filter.MinScore != nil {
+		query = query.Where("total_score >= ?", *filter.MinScore)
+	}
+	if filter.MaxScore != nil {
+		query = query.Where("total_score <= ?", *filter.MaxScore)
+	}
+
+	var rows []UserScoreRow
+	if err := query.Order("id ASC").Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+func (r *userRepository) Create(user *model.User) error {
+	return r.db.Create(user).Error
+}
+
+func (r *userRepository) Update(user *model.User, updates map[string]interface{}) error {
+	return r.db.Model(user).Updates(updates).Error
+}
+
+func (r *userRepository) Delete(user *model.User) error {
+	return r.db.Delete(user).Error
+}
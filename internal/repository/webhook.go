@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/tksky1/glimgate/internal/model"
+	"gorm.io/gorm"
+)
+
+// Webhook投递状态
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliverySucceeded = "succeeded"
+	WebhookDeliveryFailed    = "failed"
+)
+
+// WebhookRepository Webhook订阅及投递记录数据访问接口
+type WebhookRepository interface {
+	FindSubscription(id uint) (*model.WebhookSubscription, error)
+	ListSubscriptions(enabledOnly bool) ([]model.WebhookSubscription, error)
+	CreateSubscription(subscription *model.WebhookSubscription) error
+	UpdateSubscription(subscription *model.WebhookSubscription, updates map[string]interface{}) error
+	DeleteSubscription(subscription *model.WebhookSubscription) error
+
+	FindDelivery(id uint) (*model.WebhookDelivery, error)
+	ListDeliveries(subscriptionID uint, status string, offset, limit int) ([]model.WebhookDelivery, int64, error)
+	ListDueDeliveries(now time.Time, limit int) ([]model.WebhookDelivery, error)
+	CreateDelivery(delivery *model.WebhookDelivery) error
+	CreateDeliveries(deliveries []model.WebhookDelivery) error
+	UpdateDelivery(delivery *model.WebhookDelivery, updates map[string]interface{}) error
+}
+
+type webhookRepository struct {
+	db *gorm.DB
+}
+
+// NewWebhookRepository 创建Webhook仓储
+func NewWebhookRepository(db *gorm.DB) WebhookRepository {
+	return &webhookRepository{db: db}
+}
+
+func (r *webhookRepository) FindSubscription(id uint) (*model.WebhookSubscription, error) {
+	var subscription model.WebhookSubscription
+	if err := r.db.First(&subscription, id).Error; err != nil {
+		return nil, err
+	}
+	return &subscription, nil
+}
+
+// ListSubscriptions 按ID顺序获取订阅，enabledOnly时只返回已启用的订阅
+func (r *webhookRepository) ListSubscriptions(enabledOnly bool) ([]model.WebhookSubscription, error) {
+	query := r.db.Order("id ASC")
+	if enabledOnly {
+		query = query.Where("enabled = ?", true)
+	}
+
+	var subscriptions []model.WebhookSubscription
+	if err := query.Find(&subscriptions).Error; err != nil {
+		return nil, err
+	}
+	return subscriptions, nil
+}
+
+func (r *webhookRepository) CreateSubscription(subscription *model.WebhookSubscription) error {
+	return r.db.Create(subscription).Error
+}
+
+func (r *webhookRepository) UpdateSubscription(subscription *model.WebhookSubscription, updates map[string]interface{}) error {
+	return r.db.Model(subscription).Updates(updates).Error
+}
+
+func (r *webhookRepository) DeleteSubscription(subscription *model.WebhookSubscription) error {
+	return r.db.Delete(subscription).Error
+}
+
+func (r *webhookRepository) FindDelivery(id uint) (*model.WebhookDelivery, error) {
+	var delivery model.WebhookDelivery
+	if err := r.db.First(&delivery, id).Error; err != nil {
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+// ListDeliveries 分页获取订阅的投递记录，最新的在前，status为空时不限状态
+func (r *webhookRepository) ListDeliveries(subscriptionID uint, status string, offset, limit int) ([]model.WebhookDelivery, int64, error) {
+	query := r.db.Model(&model.WebhookDelivery{}).Where("subscription_id = ?", subscriptionID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var deliveries []model.WebhookDelivery
+	if err := query.Order("id DESC").Offset(offset).Limit(limit).Find(&deliveries).Error; err != nil {
+		return nil, 0, err
+	}
+	return deliveries, total, nil
+}
+
+// ListDueDeliveries 按ID顺序获取已到重试时间的待投递记录，只包括启用中的订阅
+func (r *webhookRepository) ListDueDeliveries(now time.Time, limit int) ([]model.WebhookDelivery, error) {
+	var deliveries []model.WebhookDelivery
+	if err := r.db.Joins("JOIN webhook_subscriptions ON webhook_subscriptions.id = webhook_deliveries.subscription_id").
+		Where("webhook_subscriptions.enabled = ? AND webhook_subscriptions.deleted_at IS NULL", true).
+		Where("webhook_deliveries.status = ? AND webhook_deliveries.next_attempt_at <= ?", WebhookDeliveryPending, now).
+		Order("webhook_deliveries.id ASC").
+		Limit(limit).
+		Find(&deliveries).Error; err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+func (r *webhookRepository) CreateDelivery(delivery *model.WebhookDelivery) error {
+	return r.db.Create(delivery).Error
+}
+
+func (r *webhookRepository) CreateDeliveries(deliveries []model.WebhookDelivery) error {
+	return r.db.Create(&deliveries).Error
+}
+
+func (r *webhookRepository) UpdateDelivery(delivery *model.WebhookDelivery, updates map[string]interface{}) error {
+	return r.db.Model(delivery).Updates(updates).Error
+}
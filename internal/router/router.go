@@ -6,32 +6,55 @@ import (
 	"github.com/tksky1/glimgate/internal/middleware"
 )
 
-// SetupRoutes 设置路由
-func SetupRoutes(r *gin.Engine) {
-	// 创建API实例
-	userAPI := api.NewUserAPI()
-	directionAPI := api.NewDirectionAPI()
-	problemAPI := api.NewProblemAPI()
-	submissionAPI := api.NewSubmissionAPI()
-	scoreAPI := api.NewScoreAPI()
+// Handlers 路由使用的API处理器集合，由main组装后传入
+type Handlers struct {
+	User          *api.UserAPI
+	Direction     *api.DirectionAPI
+	Problem       *api.ProblemAPI
+	Submission    *api.SubmissionAPI
+	Score         *api.ScoreAPI
+	Clarification *api.ClarificationAPI
+	Notification  *api.NotificationAPI
+	Webhook       *api.WebhookAPI
+	Audit         *api.AuditAPI
+	Trash         *api.TrashAPI
+	Judge         *api.JudgeAPI
+	Check         *api.CheckAPI
+	Similarity    *api.SimilarityAPI
+	Team          *api.TeamAPI
+}
 
+// SetupRoutes 设置路由
+func SetupRoutes(r *gin.Engine, h *Handlers) {
 	// API路由组
 	apiGroup := r.Group("/api")
 	{
 		// 认证相关路由（无需认证）
 		authGroup := apiGroup.Group("/auth")
 		{
-			authGroup.POST("/register", userAPI.Register)
-			authGroup.POST("/login", userAPI.Login)
+			authGroup.POST("/register", h.User.Register)
+			authGroup.POST("/login", h.User.Login)
 		}
 
 		// 公开路由（无需认证）
-		apiGroup.GET("/directions", directionAPI.GetDirections)
-		apiGroup.GET("/directions/:id", directionAPI.GetDirection)
-		apiGroup.GET("/problems", problemAPI.GetProblems)
-		apiGroup.GET("/problems/:id", problemAPI.GetProblem)
-		apiGroup.GET("/problems/:id/submission-points", problemAPI.GetSubmissionPoints)
-		apiGroup.GET("/ranking", scoreAPI.GetRanking)
+		apiGroup.GET("/directions", h.Direction.GetDirections)
+		apiGroup.GET("/problems/tags", h.Problem.GetProblemTags)
+		apiGroup.GET("/ranking", h.Score.GetRanking)
+
+		// 可选认证的公开路由，按当前用户判断题目是否解锁
+		optionalAuth := apiGroup.Group("")
+		optionalAuth.Use(middleware.OptionalAuthMiddleware())
+		{
+			optionalAuth.GET("/directions/:id", h.Direction.GetDirection)
+			optionalAuth.GET("/problems", h.Problem.GetProblems)
+			optionalAuth.GET("/problems/search", h.Problem.SearchProblems)
+			optionalAuth.GET("/problems/:id", h.Problem.GetProblem)
+			optionalAuth.GET("/problems/:id/submission-points", h.Problem.GetSubmissionPoints)
+			optionalAuth.GET("/problems/:id/attachments", h.Problem.GetAttachments)
+			optionalAuth.GET("/problems/:id/attachments/:filename", h.Problem.DownloadAttachmentByName)
+			optionalAuth.GET("/attachments/:id/download", h.Problem.DownloadAttachment)
+			optionalAuth.GET("/problems/:id/clarifications", h.Clarification.GetPublicClarifications)
+		}
 
 		// 需要认证的路由
 		authRequired := apiGroup.Group("")
@@ -40,27 +63,73 @@ func SetupRoutes(r *gin.Engine) {
 			// 用户相关路由
 			userGroup := authRequired.Group("/user")
 			{
-				userGroup.GET("/profile", userAPI.GetProfile)
+				userGroup.GET("/profile", h.User.GetProfile)
 			}
 
 			// 提交相关路由
 			submissionGroup := authRequired.Group("/submissions")
 			{
-				submissionGroup.POST("", submissionAPI.CreateSubmission)
-				submissionGroup.GET("/my", submissionAPI.GetMySubmissions)
-				submissionGroup.GET("/:id", submissionAPI.GetSubmission)
-				submissionGroup.DELETE("/:id", submissionAPI.DeleteSubmission)
-				submissionGroup.GET("/:id/scores", scoreAPI.GetScoresBySubmission)
+				submissionGroup.POST("", h.Submission.CreateSubmission)
+				submissionGroup.GET("/my", h.Submission.GetMySubmissions)
+				submissionGroup.GET("/:id", h.Submission.GetSubmission)
+				submissionGroup.DELETE("/:id", h.Submission.DeleteSubmission)
+				submissionGroup.GET("/:id/scores", h.Score.GetScoresBySubmission)
+				submissionGroup.GET("/:id/judge", h.Judge.GetJudgeRun)
+				submissionGroup.GET("/:id/checks", h.Check.GetCheckRun)
 			}
 
 			// 评分相关路由
 			scoreGroup := authRequired.Group("/scores")
 			{
-				scoreGroup.GET("/my", scoreAPI.GetMyScores)
+				scoreGroup.GET("/my", h.Score.GetMyScores)
+				scoreGroup.GET("/my/problems", h.Score.GetMyProblemScores)
+			}
+
+			// 题目提示相关路由
+			authRequired.GET("/problems/:id/hints", h.Problem.GetHints)
+			authRequired.POST("/problems/:id/hints/:hint_id/unlock", h.Problem.UnlockHint)
+
+			// 组队相关路由
+			authRequired.POST("/problems/:id/teams", h.Team.CreateTeam)
+			authRequired.GET("/problems/:id/team", h.Team.GetMyTeam)
+			teamGroup := authRequired.Group("/teams")
+			{
+				teamGroup.POST("/:id/invites", h.Team.Invite)
+				teamGroup.POST("/:id/leave", h.Team.LeaveTeam)
+				teamGroup.DELETE("/:id/members/:user_id", h.Team.RemoveMember)
+			}
+			teamInviteGroup := authRequired.Group("/team-invites")
+			{
+				teamInviteGroup.GET("", h.Team.GetMyInvites)
+				teamInviteGroup.POST("/:id/accept", h.Team.AcceptInvite)
+				teamInviteGroup.DELETE("/:id", h.Team.DeleteInvite)
+			}
+
+			// 答疑相关路由
+			authRequired.POST("/problems/:id/clarifications", h.Clarification.CreateClarification)
+			clarificationGroup := authRequired.Group("/clarifications")
+			{
+				clarificationGroup.GET("/my", h.Clarification.GetMyClarifications)
+				clarificationGroup.GET("/:id", h.Clarification.GetClarification)
 			}
 
+			// 通知相关路由
+			notificationGroup := authRequired.Group("/notifications")
+			{
+				notificationGroup.GET("", h.Notification.GetNotifications)
+				notificationGroup.GET("/unread-count", h.Notification.GetUnreadCount)
+				notificationGroup.PUT("/read-all", h.Notification.MarkAllRead)
+				notificationGroup.PUT("/:id/read", h.Notification.MarkRead)
+				notificationGroup.GET("/settings", h.Notification.GetSetting)
+				notificationGroup.PUT("/settings", h.Notification.UpdateSetting)
+			}
+
+			// 附件签名下载链接
+			authRequired.POST("/attachments/:id/signed-url", h.Problem.SignAttachmentURL)
+
 			// 用户评分查询路由
-			authRequired.GET("/users/:id/scores", scoreAPI.GetScoresByUser)
+			authRequired.GET("/users/:id/scores", h.Score.GetScoresByUser)
+			authRequired.GET("/users/:id/scores/problems", h.Score.GetProblemScoresByUser)
 
 			// 管理员路由
 			adminGroup := authRequired.Group("/admin")
@@ -69,48 +138,126 @@ func SetupRoutes(r *gin.Engine) {
 				// 用户管理
 				adminUserGroup := adminGroup.Group("/users")
 				{
-					adminUserGroup.GET("", userAPI.GetUsers)
-					adminUserGroup.GET("/:id", userAPI.GetUser)
-					adminUserGroup.PUT("/:id", userAPI.UpdateUser)
-					adminUserGroup.DELETE("/:id", userAPI.DeleteUser)
+					adminUserGroup.GET("", h.User.GetUsers)
+					adminUserGroup.POST("/import", h.User.ImportUsers)
+					adminUserGroup.GET("/export", h.User.ExportUsers)
+					adminUserGroup.GET("/:id", h.User.GetUser)
+					adminUserGroup.PUT("/:id", h.User.UpdateUser)
+					adminUserGroup.DELETE("/:id", h.User.DeleteUser)
 				}
 
 				// 方向管理
 				adminDirectionGroup := adminGroup.Group("/directions")
 				{
-					adminDirectionGroup.POST("", directionAPI.CreateDirection)
-					adminDirectionGroup.PUT("/:id", directionAPI.UpdateDirection)
-					adminDirectionGroup.DELETE("/:id", directionAPI.DeleteDirection)
+					adminDirectionGroup.POST("", h.Direction.CreateDirection)
+					adminDirectionGroup.PUT("/:id", h.Direction.UpdateDirection)
+					adminDirectionGroup.DELETE("/:id", h.Direction.DeleteDirection)
 				}
 
 				// 题目管理
 				adminProblemGroup := adminGroup.Group("/problems")
 				{
-					adminProblemGroup.POST("", problemAPI.CreateProblem)
-					adminProblemGroup.PUT("/:id", problemAPI.UpdateProblem)
-					adminProblemGroup.DELETE("/:id", problemAPI.DeleteProblem)
-					adminProblemGroup.POST("/:id/submission-points", problemAPI.CreateSubmissionPoint)
+					adminProblemGroup.POST("", h.Problem.CreateProblem)
+					adminProblemGroup.GET("", h.Problem.GetAdminProblems)
+					adminProblemGroup.GET("/search", h.Problem.SearchAdminProblems)
+					adminProblemGroup.GET("/tags", h.Problem.GetAdminProblemTags)
+					adminProblemGroup.GET("/unlock-stats", h.Problem.GetUnlockStats)
+					adminProblemGroup.POST("/import", h.Problem.ImportProblemBundle)
+					adminProblemGroup.GET("/:id/export", h.Problem.ExportProblemBundle)
+					adminProblemGroup.GET("/:id", h.Problem.GetAdminProblem)
+					adminProblemGroup.PUT("/:id", h.Problem.UpdateProblem)
+					adminProblemGroup.PUT("/:id/status", h.Problem.UpdateProblemStatus)
+					adminProblemGroup.PUT("/:id/prerequisites", h.Problem.UpdatePrerequisites)
+					adminProblemGroup.DELETE("/:id", h.Problem.DeleteProblem)
+					adminProblemGroup.POST("/:id/submission-points", h.Problem.CreateSubmissionPoint)
+					adminProblemGroup.POST("/:id/attachments", h.Problem.UploadAttachment)
+					adminProblemGroup.GET("/:id/hints", h.Problem.GetAdminHints)
+					adminProblemGroup.POST("/:id/hints", h.Problem.CreateHint)
+					adminProblemGroup.GET("/:id/revisions", h.Problem.GetRevisions)
+					adminProblemGroup.GET("/:id/revisions/diff", h.Problem.DiffRevisions)
+					adminProblemGroup.GET("/:id/revisions/:version", h.Problem.GetRevision)
+					adminProblemGroup.GET("/:id/teams", h.Team.GetTeams)
 				}
 
+				// 题目提示管理
+				adminGroup.PUT("/problem-hints/:id", h.Problem.UpdateHint)
+				adminGroup.DELETE("/problem-hints/:id", h.Problem.DeleteHint)
+
+				// 题目附件管理
+				adminGroup.DELETE("/problem-attachments/:id", h.Problem.DeleteAttachment)
+
 				// 提交点管理
-				adminGroup.PUT("/submission-points/:id", problemAPI.UpdateSubmissionPoint)
-				adminGroup.DELETE("/submission-points/:id", problemAPI.DeleteSubmissionPoint)
+				adminGroup.PUT("/submission-points/:id", h.Problem.UpdateSubmissionPoint)
+				adminGroup.DELETE("/submission-points/:id", h.Problem.DeleteSubmissionPoint)
+
+				// 自动评测管理
+				adminGroup.GET("/submission-points/:id/judge-cases", h.Judge.GetCases)
+				adminGroup.POST("/submission-points/:id/judge-cases", h.Judge.CreateCase)
+				adminGroup.PUT("/submission-points/:id/checker", h.Judge.UpdateChecker)
+				adminGroup.POST("/submission-points/:id/rejudge", h.Judge.RejudgePoint)
+				adminGroup.PUT("/judge-cases/:id", h.Judge.UpdateCase)
+				adminGroup.DELETE("/judge-cases/:id", h.Judge.DeleteCase)
+				adminGroup.GET("/judge-cases/:id/:file", h.Judge.DownloadCaseFile)
+
+				// 代码仓库检查管理
+				adminGroup.GET("/submission-points/:id/check-steps", h.Check.GetSteps)
+				adminGroup.POST("/submission-points/:id/check-steps", h.Check.CreateStep)
+				adminGroup.POST("/submission-points/:id/recheck", h.Check.RecheckPoint)
+				adminGroup.PUT("/check-steps/:id", h.Check.UpdateStep)
+				adminGroup.DELETE("/check-steps/:id", h.Check.DeleteStep)
+
+				// 相似度检测
+				adminGroup.POST("/submission-points/:id/similarity", h.Similarity.Analyze)
+				adminGroup.GET("/submission-points/:id/similarity", h.Similarity.GetClusters)
 
 				// 提交管理
 				adminSubmissionGroup := adminGroup.Group("/submissions")
 				{
-					adminSubmissionGroup.GET("/review", submissionAPI.GetSubmissionsForReview)
+					adminSubmissionGroup.GET("/review", h.Submission.GetSubmissionsForReview)
+					adminSubmissionGroup.GET("/archive", h.Submission.ExportSubmissionArchive)
+					adminSubmissionGroup.POST("/:id/rejudge", h.Judge.RejudgeSubmission)
+					adminSubmissionGroup.POST("/:id/recheck", h.Check.RecheckSubmission)
+					adminSubmissionGroup.GET("/:id/similarity", h.Similarity.GetSubmissionReports)
 				}
 
 				// 评分管理
 				adminScoreGroup := adminGroup.Group("/scores")
 				{
-					adminScoreGroup.POST("", scoreAPI.CreateScore)
-					adminScoreGroup.GET("/my", scoreAPI.GetScoresByReviewer)
-					adminScoreGroup.PUT("/:id", scoreAPI.UpdateScore)
-					adminScoreGroup.DELETE("/:id", scoreAPI.DeleteScore)
+					adminScoreGroup.POST("", h.Score.CreateScore)
+					adminScoreGroup.GET("/my", h.Score.GetScoresByReviewer)
+					adminScoreGroup.GET("/export", h.Score.ExportScores)
+					adminScoreGroup.PUT("/:id", h.Score.UpdateScore)
+					adminScoreGroup.DELETE("/:id", h.Score.DeleteScore)
 				}
+
+				// 答疑管理
+				adminClarificationGroup := adminGroup.Group("/clarifications")
+				{
+					adminClarificationGroup.GET("/unanswered", h.Clarification.GetUnansweredClarifications)
+					adminClarificationGroup.POST("/:id/answer", h.Clarification.AnswerClarification)
+					adminClarificationGroup.PUT("/:id/public", h.Clarification.SetClarificationPublic)
+				}
+
+				// Webhook管理
+				adminWebhookGroup := adminGroup.Group("/webhooks")
+				{
+					adminWebhookGroup.POST("", h.Webhook.CreateWebhook)
+					adminWebhookGroup.GET("", h.Webhook.GetWebhooks)
+					adminWebhookGroup.GET("/:id", h.Webhook.GetWebhook)
+					adminWebhookGroup.PUT("/:id", h.Webhook.UpdateWebhook)
+					adminWebhookGroup.DELETE("/:id", h.Webhook.DeleteWebhook)
+					adminWebhookGroup.GET("/:id/deliveries", h.Webhook.GetDeliveries)
+				}
+				adminGroup.POST("/webhook-deliveries/:id/redeliver", h.Webhook.Redeliver)
+
+				// 审计日志
+				adminGroup.GET("/audit-logs", h.Audit.GetAuditLogs)
+				adminGroup.GET("/audit-logs/export", h.Audit.ExportAuditLogs)
+
+				// 回收站
+				adminGroup.GET("/trash/:type", h.Trash.GetDeletedItems)
+				adminGroup.POST("/trash/:type/:id/restore", h.Trash.RestoreItem)
 			}
 		}
 	}
-}
\ No newline at end of file
+}
@@ -0,0 +1,160 @@
+package service
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/tksky1/glimgate/internal/model"
+	"github.com/tksky1/glimgate/internal/repository"
+)
+
+// 审计操作类型
+const (
+	AuditActionCreate  = "create"
+	AuditActionUpdate  = "update"
+	AuditActionDelete  = "delete"
+	AuditActionRestore = "restore"
+)
+
+// 审计对象类型
+const (
+	AuditEntityUser              = "user"
+	AuditEntityDirection         = "direction"
+	AuditEntityProblem           = "problem"
+	AuditEntitySubmissionPoint   = "submission_point"
+	AuditEntitySubmission        = "submission"
+	AuditEntityScore             = "score"
+	AuditEntityProblemAttachment = "problem_attachment"
+	AuditEntityProblemHint       = "problem_hint"
+	AuditEntityJudgeCase         = "judge_case"
+	AuditEntityCheckStep         = "check_step"
+)
+
+// Operator 操作者信息，由API层根据请求上下文构造
+type Operator struct {
+	UserID    uint
+	Username  string
+	IP        string
+	RequestID string
+}
+
+// AuditLogFilter 审计日志查询条件
+type AuditLogFilter struct {
+	OperatorID uint
+	Action     string
+	EntityType string
+	EntityID   uint
+	RequestID  string
+	Start      *time.Time
+	End        *time.Time
+}
+
+// AuditService 审计日志服务
+type AuditService struct {
+	repos *repository.Repositories
+}
+
+// NewAuditService 创建审计日志服务实例
+func NewAuditService(repos *repository.Repositories) *AuditService {
+	return &AuditService{repos: repos}
+}
+
+// Record 通过repo记录一条审计日志，before/after为变更前后的对象，创建时before为nil，删除时after为nil
+// repo应取自业务变更所在事务，审计日志写入失败时业务变更一并回滚
+func (s *AuditService) Record(repo repository.AuditLogRepository, op *Operator, action, entityType string, entityID uint, before, after interface{}) error {
+	beforeJSON, err := marshalAuditValue(before)
+	if err != nil {
+		return err
+	}
+	afterJSON, err := marshalAuditValue(after)
+	if err != nil {
+		return err
+	}
+
+	auditLog := model.AuditLog{
+		Action:     action,
+		EntityType: entityType,
+		EntityID:   entityID,
+		Before:     beforeJSON,
+		After:      afterJSON,
+	}
+	if op != nil {
+		auditLog.OperatorID = op.UserID
+		auditLog.OperatorName = op.Username
+		auditLog.IP = op.IP
+		auditLog.RequestID = op.RequestID
+	}
+
+	return repo.Create(&auditLog)
+}
+
+// GetAuditLogs 分页查询审计日志
+func (s *AuditService) GetAuditLogs(filter *AuditLogFilter, page, pageSize int) ([]model.AuditLog, int64, error) {
+	offset := (page - 1) * pageSize
+	return s.repos.AuditLogs.List(filter.repositoryFilter(), offset, pageSize)
+}
+
+// ExportAuditLogs 将符合条件的审计日志以CSV格式写入w
+func (s *AuditService) ExportAuditLogs(filter *AuditLogFilter, w io.Writer) error {
+	writer := csv.NewWriter(w)
+	header := []string{"id", "created_at", "operator_id", "operator_name", "action", "entity_type", "entity_id", "before", "after", "ip", "request_id"}
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	err := s.repos.AuditLogs.FindInBatches(filter.repositoryFilter(), 500, func(logs []model.AuditLog) error {
+		for _, l := range logs {
+			record := []string{
+				strconv.FormatUint(uint64(l.ID), 10),
+				l.CreatedAt.Format(time.RFC3339),
+				strconv.FormatUint(uint64(l.OperatorID), 10),
+				l.OperatorName,
+				l.Action,
+				l.EntityType,
+				strconv.FormatUint(uint64(l.EntityID), 10),
+				l.Before,
+				l.After,
+				l.IP,
+				l.RequestID,
+			}
+			if err := writer.Write(record); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// repositoryFilter 转换为仓储层的查询条件
+func (f *AuditLogFilter) repositoryFilter() repository.AuditLogFilter {
+	return repository.AuditLogFilter{
+		OperatorID: f.OperatorID,
+		Action:     f.Action,
+		EntityType: f.EntityType,
+		EntityID:   f.EntityID,
+		RequestID:  f.RequestID,
+		Start:      f.Start,
+		End:        f.End,
+	}
+}
+
+// marshalAuditValue 将对象序列化为JSON，nil返回空字符串
+func marshalAuditValue(v interface{}) (string, error) {
+	if v == nil {
+		return "", nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
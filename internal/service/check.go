@@ -0,0 +1,814 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"net"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/tksky1/glimgate/internal/model"
+	"github.com/tksky1/glimgate/internal/repository"
+	"github.com/tksky1/glimgate/pkg/config"
+	"github.com/tksky1/glimgate/pkg/sandbox"
+	"github.com/tksky1/glimgate/pkg/utils"
+)
+
+// 检查步骤类型
+const (
+	CheckKindFileExists = "file_exists"
+	CheckKindBuild      = "build"
+	CheckKindTest       = "test"
+	CheckKindLint       = "lint"
+)
+
+// 检查步骤结果
+const (
+	CheckStepPassed  = "passed"
+	CheckStepFailed  = "failed"
+	CheckStepTimeout = "timeout"
+	CheckStepSkipped = "skipped"
+)
+
+// gitCommitPattern 提交内容中固定的提交哈希
+var gitCommitPattern = regexp.MustCompile(`^[0-9a-fA-F]{7,40}$`)
+
+// checkWakeup 有新的等待检查记录时唤醒检查任务
+var checkWakeup = make(chan struct{}, 1)
+
+// CheckService 代码仓库检查服务
+type CheckService struct {
+	repos        *repository.Repositories
+	auditService *AuditService
+}
+
+// CreateCheckStepRequest 创建检查步骤请求结构
+type CreateCheckStepRequest struct {
+	Position int    `json:"position" example:"1"`
+	Name     string `json:"name" binding:"required" example:"构建"`
+	// Kind 步骤类型：file_exists检查路径存在，build构建，test测试，lint代码检查；构建失败后跳过之后的命令步骤
+	Kind string `json:"kind" binding:"required" example:"build"`
+	// Path file_exists步骤检查的仓库内相对路径，支持通配符
+	Path string `json:"path" example:"README.md"`
+	// Command build、test、lint步骤在仓库根目录用/bin/sh执行的命令，退出码为0表示通过
+	Command        string `json:"command" example:"make build"`
+	TimeoutSeconds int    `json:"timeout_seconds" binding:"min=0" example:"300"`
+	Points         int    `json:"points" binding:"min=0" example:"20"`
+	Criterion      string `json:"criterion" binding:"max=200" example:"项目能够成功构建"`
+}
+
+// UpdateCheckStepRequest 更新检查步骤请求结构，字段为空时不修改
+type UpdateCheckStepRequest struct {
+	Position       *int    `json:"position" example:"1"`
+	Name           *string `json:"name" example:"构建"`
+	Kind           *string `json:"kind" example:"build"`
+	Path           *string `json:"path" example:"README.md"`
+	Command        *string `json:"command" example:"make build"`
+	TimeoutSeconds *int    `json:"timeout_seconds" binding:"omitempty,min=0" example:"300"`
+	Points         *int    `json:"points" binding:"omitempty,min=0" example:"20"`
+	Criterion      *string `json:"criterion" binding:"omitempty,max=200" example:"项目能够成功构建"`
+}
+
+// RecheckResult 重新检查结果
+type RecheckResult struct {
+	Count int `json:"count" example:"12"` // 重新进入等待检查的提交数
+}
+
+// NewCheckService 创建代码仓库检查服务实例
+func NewCheckService(repos *repository.Repositories, auditService *AuditService) *CheckService {
+	return &CheckService{
+		repos:        repos,
+		auditService: auditService,
+	}
+}
+
+// parseGitSubmission 解析代码仓库提交，格式为http(s)仓库地址，可在#后固定提交哈希
+// 未允许内网地址时拒绝内网IP和localhost，域名解析结果在克隆前检查
+func parseGitSubmission(content string) (string, string, error) {
+	repoURL, commit, _ := strings.Cut(strings.TrimSpace(content), "#")
+	u, err := url.Parse(repoURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return "", "", errors.New("仓库地址必须是http或https地址")
+	}
+	if !checksConfig().AllowPrivateHosts {
+		host := strings.ToLower(strings.TrimSuffix(u.Hostname(), "."))
+		ip := net.ParseIP(host)
+		if (ip != nil && utils.IsInternalIP(ip)) || host == "localhost" || strings.HasSuffix(host, ".localhost") {
+			return "", "", errors.New("仓库地址不能指向内网地址")
+		}
+	}
+	if commit != "" && !gitCommitPattern.MatchString(commit) {
+		return "", "", errors.New("提交哈希格式不正确")
+	}
+	return repoURL, strings.ToLower(commit), nil
+}
+
+// validateCheckStep 检查步骤类型及对应的路径或命令
+func validateCheckStep(step *model.CheckStep) error {
+	switch step.Kind {
+	case CheckKindFileExists:
+		if step.Path == "" {
+			return errors.New("file_exists步骤需要填写路径")
+		}
+		clean := path.Clean(step.Path)
+		if path.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, "../") {
+			return errors.New("路径必须是仓库内的相对路径")
+		}
+		if _, err := path.Match(clean, ""); err != nil {
+			return errors.New("路径格式不正确")
+		}
+	case CheckKindBuild, CheckKindTest, CheckKindLint:
+		if strings.TrimSpace(step.Command) == "" {
+			return errors.New("build、test和lint步骤需要填写命令")
+		}
+	default:
+		return errors.New("检查步骤类型只能为file_exists、build、test或lint")
+	}
+	return nil
+}
+
+// findGitPoint 获取代码仓库提交点
+func findGitPoint(repos *repository.Repositories, pointID uint) (*model.SubmissionPoint, error) {
+	point, err := repos.Problems.FindPointByID(pointID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, errors.New("提交点不存在")
+		}
+		return nil, err
+	}
+	if point.Type != SubmissionPointGit {
+		return nil, errors.New("提交点不是代码仓库类型")
+	}
+	return point, nil
+}
+
+// findCheckStep 获取检查步骤
+func findCheckStep(repos *repository.Repositories, stepID uint) (*model.CheckStep, error) {
+	step, err := repos.Checks.FindStep(stepID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, errors.New("检查步骤不存在")
+		}
+		return nil, err
+	}
+	return step, nil
+}
+
+// GetSteps 获取提交点的检查步骤列表
+func (s *CheckService) GetSteps(pointID uint) ([]model.CheckStep, error) {
+	if _, err := findGitPoint(s.repos, pointID); err != nil {
+		return nil, err
+	}
+	return s.repos.Checks.ListSteps(pointID)
+}
+
+// CreateStep 为提交点添加检查步骤，不会自动重新检查已有的提交
+func (s *CheckService) CreateStep(op *Operator, pointID uint, req *CreateCheckStepRequest) (*model.CheckStep, error) {
+	step := &model.CheckStep{
+		SubmissionPointID: pointID,
+		Position:          req.Position,
+		Name:              req.Name,
+		Kind:              req.Kind,
+		Path:              req.Path,
+		Command:           req.Command,
+		TimeoutSeconds:    req.TimeoutSeconds,
+		Points:            req.Points,
+		Criterion:         req.Criterion,
+	}
+	if err := validateCheckStep(step); err != nil {
+		return nil, err
+	}
+
+	err := s.repos.Transaction(func(tx *repository.Repositories) error {
+		if _, err := findGitPoint(tx, pointID); err != nil {
+			return err
+		}
+		if err := tx.Checks.CreateStep(step); err != nil {
+			return err
+		}
+		return s.auditService.Record(tx.AuditLogs, op, AuditActionCreate, AuditEntityCheckStep, step.ID, nil, step)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return step, nil
+}
+
+// UpdateStep 修改检查步骤
+func (s *CheckService) UpdateStep(op *Operator, stepID uint, req *UpdateCheckStepRequest) (*model.CheckStep, error) {
+	var after *model.CheckStep
+	err := s.repos.Transaction(func(tx *repository.Repositories) error {
+		step, err := findCheckStep(tx, stepID)
+		if err != nil {
+			return err
+		}
+		before := *step
+
+		updates := make(map[string]interface{})
+		if req.Position != nil {
+			updates["position"], step.Position = *req.Position, *req.Position
+		}
+		if req.Name != nil {
+			if *req.Name == "" {
+				return errors.New("步骤名称不能为空")
+			}
+			updates["name"], step.Name = *req.Name, *req.Name
+		}
+		if req.Kind != nil {
+			updates["kind"], step.Kind = *req.Kind, *req.Kind
+		}
+		if req.Path != nil {
+			updates["path"], step.Path = *req.Path, *req.Path
+		}
+		if req.Command != nil {
+			updates["command"], step.Command = *req.Command, *req.Command
+		}
+		if req.TimeoutSeconds != nil {
+			updates["timeout_seconds"], step.TimeoutSeconds = *req.TimeoutSeconds, *req.TimeoutSeconds
+		}
+		if req.Points != nil {
+			updates["points"], step.Points = *req.Points, *req.Points
+		}
+		if req.Criterion != nil {
+			updates["criterion"], step.Criterion = *req.Criterion, *req.Criterion
+		}
+		if err := validateCheckStep(step); err != nil {
+			return err
+		}
+		if len(updates) > 0 {
+			if err := tx.Checks.UpdateStep(step, updates); err != nil {
+				return err
+			}
+		}
+
+		if after, err = tx.Checks.FindStep(stepID); err != nil {
+			return err
+		}
+		return s.auditService.Record(tx.AuditLogs, op, AuditActionUpdate, AuditEntityCheckStep, stepID, before, after)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return after, nil
+}
+
+// DeleteStep 删除检查步骤，不会自动重新检查已有的提交
+func (s *CheckService) DeleteStep(op *Operator, stepID uint) error {
+	return s.repos.Transaction(func(tx *repository.Repositories) error {
+		step, err := findCheckStep(tx, stepID)
+		if err != nil {
+			return err
+		}
+		if err := tx.Checks.DeleteStep(step); err != nil {
+			return err
+		}
+		return s.auditService.Record(tx.AuditLogs, op, AuditActionDelete, AuditEntityCheckStep, step.ID, step, nil)
+	})
+}
+
+// RecheckPoint 将提交点下的全部提交重新放入检查队列
+func (s *CheckService) RecheckPoint(pointID uint) (*RecheckResult, error) {
+	result := &RecheckResult{}
+	err := s.repos.Transaction(func(tx *repository.Repositories) error {
+		if _, err := findGitPoint(tx, pointID); err != nil {
+			return err
+		}
+
+		submissionIDs, err := tx.Submissions.IDsByPoint(pointID)
+		if err != nil {
+			return err
+		}
+		for _, submissionID := range submissionIDs {
+			if err := tx.Checks.ResetRun(submissionID); err != nil {
+				return err
+			}
+		}
+		result.Count = len(submissionIDs)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	wakeCheckWorker()
+	return result, nil
+}
+
+// RecheckSubmission 将提交重新放入检查队列
+func (s *CheckService) RecheckSubmission(submissionID uint) (*model.CheckRun, error) {
+	var run *model.CheckRun
+	err := s.repos.Transaction(func(tx *repository.Repositories) error {
+		submission, err := tx.Submissions.FindByID(submissionID)
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return errors.New("提交不存在")
+			}
+			return err
+		}
+		if _, err := findGitPoint(tx, submission.SubmissionPointID); err != nil {
+			return err
+		}
+
+		if err := tx.Checks.ResetRun(submissionID); err != nil {
+			return err
+		}
+		run, err = tx.Checks.FindRun(submissionID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	wakeCheckWorker()
+	run.Steps = []model.CheckStepResult{}
+	return run, nil
+}
+
+// GetCheckRun 获取提交的检查报告，只有提交者本人、所属队伍的成员或管理员可以查看
+func (s *CheckService) GetCheckRun(submissionID, userID uint, isAdmin bool) (*model.CheckRun, error) {
+	submission, err := s.repos.Submissions.FindByID(submissionID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, errors.New("提交不存在")
+		}
+		return nil, err
+	}
+	if !isAdmin {
+		allowed, err := canViewSubmission(s.repos, submission, userID)
+		if err != nil {
+			return nil, err
+		}
+		if !allowed {
+			return nil, errors.New("无权限查看该提交")
+		}
+	}
+
+	run, err := s.repos.Checks.FindRun(submissionID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, errors.New("检查报告不存在")
+		}
+		return nil, err
+	}
+	if err := parseCheckSteps(run); err != nil {
+		return nil, err
+	}
+	// 日志包含检查命令的原始输出，可能带有服务环境中的信息，只对管理员显示
+	if !isAdmin {
+		for i := range run.Steps {
+			run.Steps[i].Log = ""
+		}
+	}
+	return run, nil
+}
+
+// parseCheckSteps 解析检查报告中各步骤的结果
+func parseCheckSteps(run *model.CheckRun) error {
+	run.Steps = []model.CheckStepResult{}
+	if run.Results == "" {
+		return nil
+	}
+	return json.Unmarshal([]byte(run.Results), &run.Steps)
+}
+
+// StartWorker 检查配置并启动检查任务，在后台循环检查等待中的提交
+// 克隆仓库需要访问网络，不受isolate_network影响；未配置专用的运行用户或当前平台不支持沙箱时返回错误
+func (s *CheckService) StartWorker() error {
+	cfg := checksConfig()
+	if err := checkRunAsUser(cfg.RunAsUID, cfg.RunAsGID); err != nil {
+		return err
+	}
+	// 每个检查任务使用一个专用用户，同时检查的提交之间互相隔离，评测任务使用的UID不能与之重叠
+	if judge := judgeConfig(); judge.Enabled && cfg.RunAsUID < judge.RunAsUID+2*judge.Workers && judge.RunAsUID < cfg.RunAsUID+cfg.Workers {
+		return errors.New("checks和judge的run_as_uid占用的UID范围重叠")
+	}
+	workers := make([]*checkWorker, cfg.Workers)
+	for i := range workers {
+		uid := cfg.RunAsUID + i
+		cloner, err := sandbox.New(sandbox.Options{UID: uid, GID: cfg.RunAsGID})
+		if err != nil {
+			return err
+		}
+		runner, err := sandbox.New(sandbox.Options{UID: uid, GID: cfg.RunAsGID, IsolateNetwork: cfg.IsolateNetwork})
+		if err != nil {
+			return err
+		}
+		workers[i] = &checkWorker{cloner: cloner, runner: runner, owner: &judgeRunner{uid: uid, gid: cfg.RunAsGID}}
+	}
+	if err := os.MkdirAll(cfg.GetWorkDir(), 0o755); err != nil {
+		return fmt.Errorf("创建检查目录失败: %w", err)
+	}
+
+	if n, err := s.repos.Checks.RequeueRunning(); err != nil {
+		log.Printf("恢复中断的检查失败: %v", err)
+	} else if n > 0 {
+		log.Printf("恢复了 %d 个中断的检查", n)
+	}
+
+	for _, worker := range workers {
+		go s.work(worker)
+	}
+	return nil
+}
+
+// checkWorker 一个检查任务使用的沙箱，克隆仓库和运行检查命令使用同一个用户，只有运行检查命令时隔离网络
+type checkWorker struct {
+	cloner *sandbox.Sandbox
+	runner *sandbox.Sandbox
+	owner  *judgeRunner // 临时目录的所有者
+}
+
+// work 循环取出并检查等待中的提交，队列为空时等待唤醒或下一次轮询
+func (s *CheckService) work(worker *checkWorker) {
+	interval := time.Duration(checksConfig().PollIntervalSeconds) * time.Second
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		run, err := s.repos.Checks.ClaimNextRun()
+		if err == nil {
+			// 队列中可能还有其他记录，唤醒空闲的检查任务
+			wakeCheckWorker()
+			s.check(worker, run)
+			continue
+		}
+		if !errors.Is(err, repository.ErrNotFound) {
+			log.Printf("获取待检查记录失败: %v", err)
+		}
+
+		select {
+		case <-ticker.C:
+		case <-checkWakeup:
+		}
+	}
+}
+
+// checkOutcome 一次检查的结果，failure不为空时表示检查失败
+type checkOutcome struct {
+	commit  string
+	authors string
+	passed  int
+	score   int
+	comment string
+	steps   []model.CheckStepResult
+	failure string
+}
+
+// check 检查一条记录并写入结果
+func (s *CheckService) check(worker *checkWorker, run *model.CheckRun) {
+	submission, err := s.repos.Submissions.FindByID(run.SubmissionID, "SubmissionPoint")
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			s.finish(run, &checkOutcome{failure: "提交不存在"})
+		} else {
+			log.Printf("加载提交失败: %v", err)
+			s.finish(run, &checkOutcome{failure: "加载提交失败"})
+		}
+		return
+	}
+
+	outcome, err := s.evaluate(worker, submission)
+	if err != nil {
+		log.Printf("检查提交 %d 失败: %v", submission.ID, err)
+		outcome = &checkOutcome{failure: err.Error()}
+	}
+	s.finish(run, outcome)
+}
+
+// evaluate 在临时目录中克隆提交的仓库并依次执行检查步骤
+func (s *CheckService) evaluate(worker *checkWorker, submission *model.Submission) (*checkOutcome, error) {
+	cfg := checksConfig()
+	point := submission.SubmissionPoint
+	if point.Type != SubmissionPointGit {
+		return nil, errors.New("提交点不是代码仓库类型")
+	}
+	repoURL, commit, err := parseGitSubmission(submission.Content)
+	if err != nil {
+		return nil, err
+	}
+	steps, err := s.repos.Checks.ListSteps(point.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	base, err := os.MkdirTemp(cfg.GetWorkDir(), "run-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(base)
+	if err := os.Chmod(base, 0o711); err != nil {
+		return nil, err
+	}
+	home, err := makeJudgeDir(worker.owner, base, "home")
+	if err != nil {
+		return nil, err
+	}
+	work, err := makeJudgeDir(worker.owner, base, "work")
+	if err != nil {
+		return nil, err
+	}
+	// 仓库目录由git创建，属于运行检查的用户，避免git拒绝所有者不同的仓库
+	repo := filepath.Join(work, "repo")
+	env := []string{"PATH=" + os.Getenv("PATH"), "HOME=" + home}
+
+	outcome := &checkOutcome{steps: make([]model.CheckStepResult, 0, len(steps))}
+	if outcome.commit, outcome.authors, err = cloneRepository(worker.cloner, cfg, env, repo, repoURL, commit); err != nil {
+		return nil, err
+	}
+
+	var lines []string
+	buildFailed := false
+	for i := range steps {
+		result := runCheckStep(worker.runner, cfg, env, repo, &steps[i], buildFailed)
+		result.Position = i + 1
+		if result.Status == CheckStepPassed {
+			outcome.passed++
+			outcome.score += steps[i].Points
+			result.Points = steps[i].Points
+		} else if steps[i].Kind == CheckKindBuild {
+			buildFailed = true
+		}
+		outcome.steps = append(outcome.steps, result)
+
+		label := steps[i].Criterion
+		if label == "" {
+			label = steps[i].Name
+		}
+		if result.Status == CheckStepPassed {
+			lines = append(lines, fmt.Sprintf("[通过] %s +%d", label, steps[i].Points))
+		} else {
+			lines = append(lines, fmt.Sprintf("[未通过] %s", label))
+		}
+	}
+	outcome.score = min(outcome.score, point.MaxScore)
+	outcome.comment = strings.Join(lines, "\n")
+	return outcome, nil
+}
+
+// cloneRepository 将仓库克隆到新目录dir并检出指定的提交，未指定时检出默认分支的最新提交，
+// 返回实际检出的提交和最近1000个提交的作者邮箱（去重后每行一个，用于相似度检测）
+// 只克隆最近1000个提交的历史且只下载检出所需的文件；只允许http(s)协议，子模块不会被克隆
+// 克隆期间每个文件(包括下载的对象包)不能超过大小限制，检出后再检查仓库目录的总大小
+// git的错误输出只记录在服务日志中，返回的错误不包含访问地址得到的响应
+func cloneRepository(sb *sandbox.Sandbox, cfg config.ChecksConfig, env []string, dir, repoURL, commit string) (string, string, error) {
+	env = append(env, "GIT_TERMINAL_PROMPT=0", "GIT_CONFIG_NOSYSTEM=1", "GIT_ALLOW_PROTOCOL=http:https")
+	options, err := gitHostOptions(cfg, repoURL)
+	if err != nil {
+		return "", "", err
+	}
+	limits := sandbox.Limits{
+		WallTime:   time.Duration(cfg.CloneTimeoutSeconds) * time.Second,
+		Processes:  cfg.MaxProcesses,
+		FileSizeMB: cfg.MaxRepoMB,
+	}
+	git := func(workDir string, args ...string) (string, error) {
+		result, err := sb.Run(&sandbox.Command{
+			Args:           append(append([]string{"git"}, options...), args...),
+			Dir:            workDir,
+			Env:            env,
+			Limits:         limits,
+			TruncateOutput: true,
+		})
+		if err != nil {
+			return "", err
+		}
+		switch result.Status {
+		case sandbox.StatusOK:
+			return strings.TrimSpace(string(result.Stdout)), nil
+		case sandbox.StatusTimeLimit:
+			return "", errors.New("克隆仓库超时")
+		default:
+			log.Printf("克隆仓库 %s 失败: %s", repoURL, strings.TrimSpace(truncateJudgeMessage(string(result.Stderr))))
+			return "", fmt.Errorf("克隆仓库失败，请确认仓库地址正确、可以公开访问且不超过大小限制(%dMB)", cfg.MaxRepoMB)
+		}
+	}
+
+	if commit == "" {
+		if _, err := git(filepath.Dir(dir), "clone", "--quiet", "--depth", cloneDepth, "--filter=blob:none", "--", repoURL, dir); err != nil {
+			return "", "", err
+		}
+	} else {
+		// 指定的提交可能不在默认分支上，浅克隆默认只获取默认分支
+		if _, err := git(filepath.Dir(dir), "clone", "--quiet", "--depth", cloneDepth, "--no-single-branch", "--filter=blob:none", "--no-checkout", "--", repoURL, dir); err != nil {
+			return "", "", err
+		}
+		// 先确认提交存在，避免把哈希当作路径检出
+		if _, err := git(dir, "rev-parse", "--verify", "--quiet", commit+"^{commit}"); err != nil {
+			return "", "", fmt.Errorf("仓库中不存在提交%s", commit)
+		}
+		if _, err := git(dir, "checkout", "--quiet", "--detach", commit); err != nil {
+			return "", "", err
+		}
+	}
+
+	var size int64
+	err = filepath.WalkDir(dir, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		size += info.Size()
+		return nil
+	})
+	if err != nil {
+		return "", "", err
+	}
+	if size > int64(cfg.MaxRepoMB)<<20 {
+		return "", "", fmt.Errorf("仓库超过大小限制(%dMB)", cfg.MaxRepoMB)
+	}
+
+	head, err := git(dir, "rev-parse", "HEAD")
+	if err != nil {
+		return "", "", err
+	}
+	output, err := git(dir, "log", "--format=%aE", "-n", "1000", "HEAD")
+	if err != nil {
+		return "", "", err
+	}
+	seen := make(map[string]bool)
+	var authors []string
+	for _, email := range strings.Fields(strings.ToLower(output)) {
+		if !seen[email] {
+			seen[email] = true
+			authors = append(authors, email)
+		}
+	}
+	sort.Strings(authors)
+	return head, strings.Join(authors, "\n"), nil
+}
+
+// cloneDepth 克隆的提交历史深度，与读取作者邮箱的提交数一致，指定的提交需要在各分支最近的这些提交中
+const cloneDepth = "1000"
+
+// gitHostOptions 未允许内网地址时解析仓库主机并拒绝内网地址，返回让git固定连接检查过的IP且不跟随重定向的配置
+func gitHostOptions(cfg config.ChecksConfig, repoURL string) ([]string, error) {
+	if cfg.AllowPrivateHosts {
+		return nil, nil
+	}
+	u, err := url.Parse(repoURL)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	ips, err := utils.ResolvePublicHost(ctx, u.Hostname())
+	if errors.Is(err, utils.ErrInternalHost) {
+		return nil, errors.New("仓库地址不能指向内网地址")
+	}
+	if err != nil {
+		return nil, errors.New("无法解析仓库地址的域名")
+	}
+
+	port := u.Port()
+	if port == "" {
+		port = map[string]string{"http": "80", "https": "443"}[u.Scheme]
+	}
+	addrs := make([]string, len(ips))
+	for i, ip := range ips {
+		if ip.To4() == nil {
+			addrs[i] = "[" + ip.String() + "]"
+		} else {
+			addrs[i] = ip.String()
+		}
+	}
+	return []string{
+		"-c", "http.followRedirects=false",
+		"-c", "http.curloptResolve=" + u.Hostname() + ":" + port + ":" + strings.Join(addrs, ","),
+	}, nil
+}
+
+// runCheckStep 在仓库目录中执行一个检查步骤，构建失败后跳过之后的命令步骤
+func runCheckStep(sb *sandbox.Sandbox, cfg config.ChecksConfig, env []string, dir string, step *model.CheckStep, buildFailed bool) model.CheckStepResult {
+	result := model.CheckStepResult{
+		Name:      step.Name,
+		Kind:      step.Kind,
+		Criterion: step.Criterion,
+		Status:    CheckStepFailed,
+	}
+
+	if step.Kind == CheckKindFileExists {
+		matches, err := filepath.Glob(filepath.Join(dir, filepath.FromSlash(path.Clean(step.Path))))
+		if err == nil && len(matches) > 0 {
+			result.Status = CheckStepPassed
+		} else {
+			result.Log = "未找到 " + step.Path
+		}
+		return result
+	}
+	if buildFailed {
+		result.Status = CheckStepSkipped
+		result.Log = "构建未通过，已跳过"
+		return result
+	}
+
+	timeout := time.Duration(cfg.DefaultStepTimeoutSeconds) * time.Second
+	if step.TimeoutSeconds > 0 {
+		timeout = time.Duration(step.TimeoutSeconds) * time.Second
+	}
+	// 标准错误合并到标准输出，日志按输出顺序保留开头部分
+	run, err := sb.Run(&sandbox.Command{
+		Args: []string{"/bin/sh", "-c", "exec 2>&1\n" + step.Command},
+		Dir:  dir,
+		Env:  env,
+		Limits: sandbox.Limits{
+			WallTime:    timeout,
+			MemoryMB:    cfg.MemoryLimitMB,
+			OutputBytes: int64(cfg.MaxLogKB) << 10,
+			Processes:   cfg.MaxProcesses,
+		},
+		TruncateOutput: true,
+	})
+	if err != nil {
+		result.Log = err.Error()
+		return result
+	}
+
+	result.ExitCode = run.ExitCode
+	result.DurationMS = run.WallTime.Milliseconds()
+	result.Log = strings.ToValidUTF8(string(run.Stdout), "")
+	switch run.Status {
+	case sandbox.StatusOK:
+		result.Status = CheckStepPassed
+	case sandbox.StatusTimeLimit:
+		result.Status = CheckStepTimeout
+	}
+	return result
+}
+
+// finish 写入检查结果，检查期间提交被重新提交或重新检查时丢弃本次结果
+// 检查报告只作为评分参考，不写入评分
+func (s *CheckService) finish(run *model.CheckRun, outcome *checkOutcome) {
+	updates := map[string]interface{}{"finished_at": time.Now()}
+	if outcome.failure != "" {
+		updates["status"] = repository.CheckStatusFailed
+		updates["message"] = outcome.failure
+	} else {
+		results, err := json.Marshal(outcome.steps)
+		if err != nil {
+			log.Printf("保存检查结果失败: %v", err)
+			return
+		}
+		updates["status"] = repository.CheckStatusFinished
+		updates["commit"] = outcome.commit
+		updates["authors"] = outcome.authors
+		updates["passed_count"] = outcome.passed
+		updates["step_count"] = len(outcome.steps)
+		updates["suggested_score"] = outcome.score
+		updates["suggested_comment"] = outcome.comment
+		updates["results"] = string(results)
+	}
+
+	if _, err := s.repos.Checks.FinishRun(run, updates); err != nil {
+		log.Printf("保存检查结果失败: %v", err)
+	}
+}
+
+// checksConfig 获取代码仓库检查配置，未配置的项使用默认值
+func checksConfig() config.ChecksConfig {
+	cfg := config.AppConfig.Checks
+	if cfg.Workers <= 0 {
+		cfg.Workers = 1
+	}
+	if cfg.PollIntervalSeconds <= 0 {
+		cfg.PollIntervalSeconds = 5
+	}
+	if cfg.CloneTimeoutSeconds <= 0 {
+		cfg.CloneTimeoutSeconds = 120
+	}
+	if cfg.MaxRepoMB <= 0 {
+		cfg.MaxRepoMB = 200
+	}
+	if cfg.DefaultStepTimeoutSeconds <= 0 {
+		cfg.DefaultStepTimeoutSeconds = 300
+	}
+	if cfg.MaxLogKB <= 0 {
+		cfg.MaxLogKB = 64
+	}
+	if cfg.MaxProcesses <= 0 {
+		cfg.MaxProcesses = 1024
+	}
+	return cfg
+}
+
+// wakeCheckWorker 非阻塞地唤醒检查任务
+func wakeCheckWorker() {
+	select {
+	case checkWakeup <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,255 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"net/http"
+	"net/http/cgi"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/tksky1/glimgate/internal/model"
+	"github.com/tksky1/glimgate/internal/repository"
+	"github.com/tksky1/glimgate/pkg/config"
+	"github.com/tksky1/glimgate/pkg/sandbox"
+)
+
+func TestParseGitSubmission(t *testing.T) {
+	config.AppConfig = &config.Config{}
+	tests := []struct {
+		content string
+		repoURL string
+		commit  string
+		err     string
+	}{
+		{"https://github.com/user/project", "https://github.com/user/project", "", ""},
+		{" https://github.com/user/project#3F2A9C1 ", "https://github.com/user/project", "3f2a9c1", ""},
+		{"http://8.8.8.8:8080/project.git", "http://8.8.8.8:8080/project.git", "", ""},
+		{"git@github.com:user/project.git", "", "", "仓库地址必须是http或https地址"},
+		{"file:///etc", "", "", "仓库地址必须是http或https地址"},
+		{"https://github.com/user/project#main", "", "", "提交哈希格式不正确"},
+		{"http://127.0.0.1:3306/", "", "", "仓库地址不能指向内网地址"},
+		{"http://[::1]/project.git", "", "", "仓库地址不能指向内网地址"},
+		{"http://10.0.0.5/project.git", "", "", "仓库地址不能指向内网地址"},
+		{"http://169.254.169.254/latest/meta-data", "", "", "仓库地址不能指向内网地址"},
+		{"http://localhost:8080/project.git", "", "", "仓库地址不能指向内网地址"},
+		{"http://LOCALHOST./project.git", "", "", "仓库地址不能指向内网地址"},
+		{"http://admin.localhost/project.git", "", "", "仓库地址不能指向内网地址"},
+	}
+	for _, tt := range tests {
+		repoURL, commit, err := parseGitSubmission(tt.content)
+		if tt.err != "" {
+			if err == nil || err.Error() != tt.err {
+				t.Errorf("%q应返回%q，得到%v", tt.content, tt.err, err)
+			}
+			continue
+		}
+		if err != nil || repoURL != tt.repoURL || commit != tt.commit {
+			t.Errorf("%q解析为%q %q %v", tt.content, repoURL, commit, err)
+		}
+	}
+
+	// 使用内网代码托管服务时可以允许内网地址
+	config.AppConfig.Checks.AllowPrivateHosts = true
+	if _, _, err := parseGitSubmission("http://10.0.0.5/project.git"); err != nil {
+		t.Errorf("允许内网地址时不应拒绝: %v", err)
+	}
+}
+
+func TestGitHostOptions(t *testing.T) {
+	tests := []struct {
+		repoURL string
+		resolve string
+	}{
+		{"https://8.8.8.8/project.git", "8.8.8.8:443:8.8.8.8"},
+		{"http://8.8.8.8/project.git", "8.8.8.8:80:8.8.8.8"},
+		{"http://8.8.8.8:8080/project.git", "8.8.8.8:8080:8.8.8.8"},
+		{"https://[2001:4860:4860::8888]/project.git", "2001:4860:4860::8888:443:[2001:4860:4860::8888]"},
+	}
+	for _, tt := range tests {
+		options, err := gitHostOptions(config.ChecksConfig{}, tt.repoURL)
+		if err != nil {
+			t.Errorf("%s: %v", tt.repoURL, err)
+			continue
+		}
+		want := []string{"-c", "http.followRedirects=false", "-c", "http.curloptResolve=" + tt.resolve}
+		if !reflect.DeepEqual(options, want) {
+			t.Errorf("%s: %v，应为%v", tt.repoURL, options, want)
+		}
+	}
+
+	for _, repoURL := range []string{"http://127.0.0.1/project.git", "http://localhost/project.git", "http://[fd00::1]/project.git"} {
+		if _, err := gitHostOptions(config.ChecksConfig{}, repoURL); err == nil || err.Error() != "仓库地址不能指向内网地址" {
+			t.Errorf("%s应被拒绝，得到%v", repoURL, err)
+		}
+	}
+	if options, err := gitHostOptions(config.ChecksConfig{AllowPrivateHosts: true}, "http://127.0.0.1/project.git"); err != nil || options != nil {
+		t.Errorf("允许内网地址时不应限制: %v %v", options, err)
+	}
+}
+
+// TestCloneRepositoryHidesResponse 克隆失败时返回的错误不包含访问地址得到的响应
+func TestCloneRepositoryHidesResponse(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("未安装git")
+	}
+	sb, err := sandbox.New(sandbox.Options{})
+	if err != nil {
+		t.Skip(err)
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "internal-secret-token", http.StatusInternalServerError)
+	}))
+	t.Cleanup(server.Close)
+
+	base := t.TempDir()
+	env := []string{"PATH=" + os.Getenv("PATH"), "HOME=" + base}
+	cfg := config.ChecksConfig{CloneTimeoutSeconds: 30, MaxRepoMB: 10}
+
+	// 默认拒绝内网地址，不会发起请求
+	_, _, err = cloneRepository(sb, cfg, env, filepath.Join(base, "denied"), server.URL+"/project.git", "")
+	if err == nil || err.Error() != "仓库地址不能指向内网地址" {
+		t.Fatalf("应拒绝内网地址，得到%v", err)
+	}
+
+	cfg.AllowPrivateHosts = true
+	_, _, err = cloneRepository(sb, cfg, env, filepath.Join(base, "repo"), server.URL+"/project.git", "")
+	if err == nil || err.Error() != "克隆仓库失败，请确认仓库地址正确、可以公开访问且不超过大小限制(10MB)" {
+		t.Fatalf("克隆失败时应返回固定的提示，得到%v", err)
+	}
+}
+
+// serveGitRepository 通过git http-backend以http协议提供dir下的仓库，返回服务地址
+func serveGitRepository(t *testing.T, dir string) string {
+	t.Helper()
+	execPath, err := exec.Command("git", "--exec-path").Output()
+	if err != nil {
+		t.Skip("未安装git")
+	}
+	server := httptest.NewServer(&cgi.Handler{
+		Path: filepath.Join(strings.TrimSpace(string(execPath)), "git-http-backend"),
+		Env:  []string{"GIT_PROJECT_ROOT=" + dir, "GIT_HTTP_EXPORT_ALL=1"},
+	})
+	t.Cleanup(server.Close)
+	return server.URL
+}
+
+// TestCloneRepositorySizeLimit 克隆期间就按大小限制中止，而不是克隆完成后才检查
+func TestCloneRepositorySizeLimit(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("未安装git")
+	}
+	sb, err := sandbox.New(sandbox.Options{})
+	if err != nil {
+		t.Skip(err)
+	}
+
+	// 仓库中有一个3MB的随机文件，压缩后大小不变
+	base := t.TempDir()
+	source := filepath.Join(base, "project")
+	data := make([]byte, 3<<20)
+	if _, err := rand.Read(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(source, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(source, "data.bin"), data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range [][]string{
+		{"init", "--quiet"},
+		{"add", "data.bin"},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "--quiet", "-m", "init"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = source
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v %s", args, err, output)
+		}
+	}
+	repoURL := serveGitRepository(t, base) + "/project"
+
+	env := []string{"PATH=" + os.Getenv("PATH"), "HOME=" + base}
+	cfg := config.ChecksConfig{AllowPrivateHosts: true, CloneTimeoutSeconds: 30, MaxRepoMB: 1}
+	dir := filepath.Join(base, "small")
+	_, _, err = cloneRepository(sb, cfg, env, dir, repoURL, "")
+	if err == nil || !strings.Contains(err.Error(), "不超过大小限制(1MB)") {
+		t.Fatalf("超过大小限制的仓库应克隆失败，得到%v", err)
+	}
+	if info, err := os.Stat(filepath.Join(dir, "data.bin")); err == nil && info.Size() > 1<<20 {
+		t.Errorf("克隆期间写入了%d字节的文件", info.Size())
+	}
+
+	cfg.MaxRepoMB = 10
+	commit, authors, err := cloneRepository(sb, cfg, env, filepath.Join(base, "large"), repoURL, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(commit) != 40 || authors != "test@example.com" {
+		t.Errorf("提交%q，作者%q", commit, authors)
+	}
+	if pinned, _, err := cloneRepository(sb, cfg, env, filepath.Join(base, "pinned"), repoURL, commit); err != nil || pinned != commit {
+		t.Errorf("检出指定提交得到%q %v，应为%q", pinned, err, commit)
+	}
+}
+
+func TestGetCheckRunHidesLogs(t *testing.T) {
+	env := newTestEnv(t)
+	checks := NewCheckService(env.repos, env.audit)
+	user := env.createUser(t, "user", false)
+	problem, _ := env.createProblem(t, "仓库题")
+	point := model.SubmissionPoint{ProblemID: problem.ID, Name: "仓库", MaxScore: 100, Type: SubmissionPointGit}
+	env.create(t, &point)
+	submission := model.Submission{Content: "https://github.com/user/project", UserID: user.ID, ProblemID: problem.ID, SubmissionPointID: point.ID}
+	env.create(t, &submission)
+
+	results, err := json.Marshal([]model.CheckStepResult{
+		{Position: 1, Name: "构建", Kind: CheckKindBuild, Status: CheckStepFailed, ExitCode: 1, Log: "cat: config/config.yaml: jwt secret"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	env.create(t, &model.CheckRun{SubmissionID: submission.ID, Status: repository.CheckStatusFinished, StepCount: 1, Results: string(results)})
+
+	run, err := checks.GetCheckRun(submission.ID, user.ID, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(run.Steps) != 1 || run.Steps[0].Status != CheckStepFailed || run.Steps[0].Log != "" {
+		t.Errorf("选手应只看到步骤结果，不含日志: %+v", run.Steps)
+	}
+
+	run, err = checks.GetCheckRun(submission.ID, env.admin.ID, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(run.Steps) != 1 || !strings.Contains(run.Steps[0].Log, "jwt secret") {
+		t.Errorf("管理员应能看到日志: %+v", run.Steps)
+	}
+}
+
+func TestCheckStartWorkerRequiresRunAsUser(t *testing.T) {
+	env := newTestEnv(t)
+	checks := NewCheckService(env.repos, env.audit)
+
+	config.AppConfig.Checks = config.ChecksConfig{Enabled: true}
+	if err := checks.StartWorker(); err == nil || err.Error() != "run_as_uid和run_as_gid必须配置为专用的低权限用户" {
+		t.Errorf("未配置运行用户时应拒绝启动，得到%v", err)
+	}
+
+	if os.Geteuid() != 0 {
+		return
+	}
+	// 评测占用2000和2001
+	config.AppConfig.Judge = config.JudgeConfig{Enabled: true, RunAsUID: 2000, RunAsGID: 2000}
+	config.AppConfig.Checks = config.ChecksConfig{Enabled: true, RunAsUID: 2001, RunAsGID: 2000}
+	if err := checks.StartWorker(); err == nil || err.Error() != "checks和judge的run_as_uid占用的UID范围重叠" {
+		t.Errorf("UID与评测重叠时应拒绝启动，得到%v", err)
+	}
+}
@@ -0,0 +1,280 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/tksky1/glimgate/internal/model"
+	"github.com/tksky1/glimgate/internal/repository"
+	"gorm.io/gorm"
+)
+
+// ClarificationService 答疑服务
+type ClarificationService struct {
+	db                  *gorm.DB
+	directionService    *DirectionService
+	notificationService *NotificationService
+}
+
+// CreateClarificationRequest 提问请求结构
+type CreateClarificationRequest struct {
+	Question string `json:"question" binding:"required" example:"输入数据是否保证合法？"`
+}
+
+// AnswerClarificationRequest 回复答疑请求结构
+type AnswerClarificationRequest struct {
+	Answer   string `json:"answer" binding:"required" example:"保证合法，无需额外校验"`
+	IsPublic *bool  `json:"is_public" example:"true"`
+}
+
+// SetClarificationPublicRequest 设置答疑公开状态请求结构
+type SetClarificationPublicRequest struct {
+	IsPublic bool `json:"is_public" example:"true"`
+}
+
+// MyClarificationsResponse 我的答疑列表响应结构
+type MyClarificationsResponse struct {
+	Clarifications []model.Clarification `json:"clarifications"`
+	UnreadCount    int64                 `json:"unread_count"`
+}
+
+// NewClarificationService 创建答疑服务实例
+func NewClarificationService(db *gorm.DB, directionService *DirectionService, notificationService *NotificationService) *ClarificationService {
+	return &ClarificationService{
+		db:                  db,
+		directionService:    directionService,
+		notificationService: notificationService,
+	}
+}
+
+// CreateClarification 针对题目提问
+func (s *ClarificationService) CreateClarification(userID, problemID uint, req *CreateClarificationRequest) (*model.Clarification, error) {
+	db := s.db
+
+	// 检查题目是否存在且已发布，已归档的题目不再接受提问
+	var problem model.Problem
+	if err := db.First(&problem, problemID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("题目不存在")
+		}
+		return nil, err
+	}
+	if !problemVisible(&problem) {
+		return nil, errors.New("题目不存在")
+	}
+	if problem.Status == ProblemStatusArchived {
+		return nil, errors.New("题目已归档，不再接受提问")
+	}
+
+	clarification := model.Clarification{
+		ProblemID: problemID,
+		AskerID:   userID,
+		Question:  req.Question,
+	}
+	if err := db.Create(&clarification).Error; err != nil {
+		return nil, err
+	}
+
+	if err := db.Preload("Problem").Preload("Asker").First(&clarification, clarification.ID).Error; err != nil {
+		return nil, err
+	}
+
+	return &clarification, nil
+}
+
+// GetPublicClarifications 获取题目的公开答疑列表，题目不可见时视为不存在，userID未解锁题目时返回错误
+func (s *ClarificationService) GetPublicClarifications(problemID, userID uint) ([]model.Clarification, error) {
+	db := s.db
+
+	var problem model.Problem
+	if err := db.First(&problem, problemID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("题目不存在")
+		}
+		return nil, err
+	}
+	if !problemVisible(&problem) {
+		return nil, errors.New("题目不存在")
+	}
+	if err := checkProblemUnlocked(repository.NewRepositories(db), userID, &problem); err != nil {
+		return nil, err
+	}
+
+	var clarifications []model.Clarification
+	if err := db.Preload("Answerer").
+		Where("problem_id = ? AND is_public = ? AND answered_at IS NOT NULL", problemID, true).
+		Order("answered_at DESC").
+		Find(&clarifications).Error; err != nil {
+		return nil, err
+	}
+
+	return clarifications, nil
+}
+
+// GetMyClarifications 获取用户自己的提问列表及未读回复数
+func (s *ClarificationService) GetMyClarifications(userID, problemID uint) (*MyClarificationsResponse, error) {
+	db := s.db
+
+	query := db.Preload("Problem").Preload("Answerer").Where("asker_id = ?", userID)
+	if problemID > 0 {
+		query = query.Where("problem_id = ?", problemID)
+	}
+
+	var clarifications []model.Clarification
+	if err := query.Order("created_at DESC").Find(&clarifications).Error; err != nil {
+		return nil, err
+	}
+
+	var unreadCount int64
+	if err := db.Model(&model.Clarification{}).
+		Where("asker_id = ? AND reply_unread = ?", userID, true).
+		Count(&unreadCount).Error; err != nil {
+		return nil, err
+	}
+
+	return &MyClarificationsResponse{
+		Clarifications: clarifications,
+		UnreadCount:    unreadCount,
+	}, nil
+}
+
+// GetClarificationByID 获取答疑详情，提问者查看时标记回复为已读
+func (s *ClarificationService) GetClarificationByID(clarificationID, userID uint, isAdmin bool) (*model.Clarification, error) {
+	db := s.db
+
+	var clarification model.Clarification
+	if err := db.Preload("Problem").Preload("Asker").Preload("Answerer").First(&clarification, clarificationID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("答疑不存在")
+		}
+		return nil, err
+	}
+
+	// 公开答疑任何登录用户可见，私有答疑仅提问者和管理员可见
+	if !clarification.IsPublic && !isAdmin && clarification.AskerID != userID {
+		return nil, errors.New("无权限查看该答疑")
+	}
+
+	if clarification.AskerID == userID && clarification.ReplyUnread {
+		if err := db.Model(&clarification).Update("reply_unread", false).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	return &clarification, nil
+}
+
+// GetUnansweredClarifications 获取负责方向下待回复的答疑队列
+func (s *ClarificationService) GetUnansweredClarifications(managerID, problemID uint) ([]model.Clarification, error) {
+	db := s.db
+
+	// 获取该管理员负责的方向
+	var directionIDs []uint
+	if err := db.Table("direction_managers").Where("user_id = ?", managerID).Pluck("direction_id", &directionIDs).Error; err != nil {
+		return nil, err
+	}
+	if len(directionIDs) == 0 {
+		return []model.Clarification{}, nil
+	}
+
+	query := db.Preload("Problem").Preload("Asker").
+		Joins("JOIN problems ON problems.id = clarifications.problem_id").
+		Where("problems.direction_id IN ?", directionIDs).
+		Where("clarifications.answered_at IS NULL")
+	if problemID > 0 {
+		query = query.Where("clarifications.problem_id = ?", problemID)
+	}
+
+	var clarifications []model.Clarification
+	if err := query.Order("clarifications.created_at ASC").Find(&clarifications).Error; err != nil {
+		return nil, err
+	}
+
+	return clarifications, nil
+}
+
+// AnswerClarification 回复答疑
+func (s *ClarificationService) AnswerClarification(clarificationID, managerID uint, req *AnswerClarificationRequest) (*model.Clarification, error) {
+	db := s.db
+
+	clarification, err := s.getManagedClarification(clarificationID, managerID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	updates := map[string]interface{}{
+		"answer":       req.Answer,
+		"answerer_id":  managerID,
+		"answered_at":  now,
+		"reply_unread": true,
+	}
+	if req.IsPublic != nil {
+		updates["is_public"] = *req.IsPublic
+	}
+	if err := db.Model(clarification).Updates(updates).Error; err != nil {
+		return nil, err
+	}
+
+	if err := db.Preload("Problem").Preload("Asker").Preload("Answerer").First(clarification, clarification.ID).Error; err != nil {
+		return nil, err
+	}
+
+	title := "你的提问收到了回复"
+	content := fmt.Sprintf("题目《%s》的提问已回复：%s", clarification.Problem.Title, clarification.Answer)
+	if err := s.notificationService.Notify([]uint{clarification.AskerID}, NotificationClarificationAnswered, title, content, clarification.ID); err != nil {
+		log.Printf("发送答疑通知失败: %v", err)
+	}
+
+	return clarification, nil
+}
+
+// SetClarificationPublic 设置答疑是否公开
+func (s *ClarificationService) SetClarificationPublic(clarificationID, managerID uint, req *SetClarificationPublicRequest) (*model.Clarification, error) {
+	db := s.db
+
+	clarification, err := s.getManagedClarification(clarificationID, managerID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 未回复的提问不能公开
+	if req.IsPublic && clarification.AnsweredAt == nil {
+		return nil, errors.New("答疑尚未回复，无法公开")
+	}
+
+	if err := db.Model(clarification).Update("is_public", req.IsPublic).Error; err != nil {
+		return nil, err
+	}
+
+	if err := db.Preload("Problem").Preload("Asker").Preload("Answerer").First(clarification, clarification.ID).Error; err != nil {
+		return nil, err
+	}
+
+	return clarification, nil
+}
+
+// getManagedClarification 获取答疑并检查操作者是否为题目所属方向的负责人
+func (s *ClarificationService) getManagedClarification(clarificationID, managerID uint) (*model.Clarification, error) {
+	db := s.db
+
+	var clarification model.Clarification
+	if err := db.Preload("Problem").First(&clarification, clarificationID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("答疑不存在")
+		}
+		return nil, err
+	}
+
+	isManager, err := s.directionService.CheckDirectionManager(clarification.Problem.DirectionID, managerID)
+	if err != nil {
+		return nil, err
+	}
+	if !isManager {
+		return nil, errors.New("无权限处理该答疑")
+	}
+
+	return &clarification, nil
+}
@@ -4,12 +4,15 @@ import (
 	"errors"
 
 	"github.com/tksky1/glimgate/internal/model"
-	"github.com/tksky1/glimgate/pkg/database"
-	"gorm.io/gorm"
+	"github.com/tksky1/glimgate/internal/repository"
+	"github.com/tksky1/glimgate/pkg/markdown"
 )
 
 // DirectionService 方向服务
-type DirectionService struct{}
+type DirectionService struct {
+	repos        *repository.Repositories
+	auditService *AuditService
+}
 
 // CreateDirectionRequest 创建方向请求结构
 type CreateDirectionRequest struct {
@@ -26,157 +29,174 @@ type UpdateDirectionRequest struct {
 }
 
 // NewDirectionService 创建方向服务实例
-func NewDirectionService() *DirectionService {
-	return &DirectionService{}
+func NewDirectionService(repos *repository.Repositories, auditService *AuditService) *DirectionService {
+	return &DirectionService{
+		repos:        repos,
+		auditService: auditService,
+	}
 }
 
-// CreateDirection 创建方向
-func (s *DirectionService) CreateDirection(req *CreateDirectionRequest) (*model.Direction, error) {
-	db := database.GetDB()
-
-	// 创建方向
-	direction := model.Direction{
+// CreateDirection 创建方向，描述为Markdown，包含不安全的HTML时拒绝
+func (s *DirectionService) CreateDirection(op *Operator, req *CreateDirectionRequest) (*model.Direction, error) {
+	if err := markdown.Check(req.Description); err != nil {
+		return nil, err
+	}
+	direction := &model.Direction{
 		Name:        req.Name,
 		Description: req.Description,
 	}
 
-	if err := db.Create(&direction).Error; err != nil {
-		return nil, err
-	}
+	err := s.repos.Transaction(func(tx *repository.Repositories) error {
+		// 创建方向
+		if err := tx.Directions.Create(direction); err != nil {
+			return err
+		}
 
-	// 设置负责人
-	if len(req.ManagerIDs) > 0 {
-		var managers []model.User
-		if err := db.Where("id IN ?", req.ManagerIDs).Find(&managers).Error; err != nil {
-			return nil, err
+		// 设置负责人
+		if len(req.ManagerIDs) > 0 {
+			if err := tx.Directions.ReplaceManagers(direction, req.ManagerIDs); err != nil {
+				return err
+			}
 		}
-		if err := db.Model(&direction).Association("Managers").Replace(managers); err != nil {
-			return nil, err
+
+		// 重新加载包含关联数据的方向
+		var err error
+		direction, err = tx.Directions.FindByID(direction.ID, "Managers")
+		if err != nil {
+			return err
 		}
-	}
 
-	// 重新加载包含关联数据的方向
-	if err := db.Preload("Managers").First(&direction, direction.ID).Error; err != nil {
+		return s.auditService.Record(tx.AuditLogs, op, AuditActionCreate, AuditEntityDirection, direction.ID, nil, direction)
+	})
+	if err != nil {
 		return nil, err
 	}
 
-	return &direction, nil
+	renderDirection(direction)
+	return direction, nil
 }
 
 // GetDirections 获取方向列表
 func (s *DirectionService) GetDirections() ([]model.Direction, error) {
-	db := database.GetDB()
-
-	var directions []model.Direction
-	if err := db.Preload("Managers").Find(&directions).Error; err != nil {
+	directions, err := s.repos.Directions.List()
+	if err != nil {
 		return nil, err
 	}
-
+	for i := range directions {
+		renderDirection(&directions[i])
+	}
 	return directions, nil
 }
 
-// GetDirectionByID 根据ID获取方向
-func (s *DirectionService) GetDirectionByID(directionID uint) (*model.Direction, error) {
-	db := database.GetDB()
-
-	var direction model.Direction
-	if err := db.Preload("Managers").Preload("Problems").First(&direction, directionID).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
+// GetDirectionByID 根据ID获取方向，只包含对选手可见的题目
+// 按userID的提交和得分判断题目是否解锁，userID为0表示未登录
+func (s *DirectionService) GetDirectionByID(directionID, userID uint) (*model.Direction, error) {
+	direction, err := s.repos.Directions.FindByID(directionID, "Managers", "Problems")
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
 			return nil, errors.New("方向不存在")
 		}
 		return nil, err
 	}
 
-	return &direction, nil
+	problems := make([]model.Problem, 0, len(direction.Problems))
+	for _, problem := range direction.Problems {
+		if problemVisible(&problem) {
+			problems = append(problems, problem)
+		}
+	}
+	direction.Problems = problems
+
+	renderDirection(direction)
+	if err := applyCandidateView(s.repos, userID, direction.Problems); err != nil {
+		return nil, err
+	}
+	return direction, nil
 }
 
 // UpdateDirection 更新方向
-func (s *DirectionService) UpdateDirection(directionID uint, req *UpdateDirectionRequest) (*model.Direction, error) {
-	db := database.GetDB()
-
-	var direction model.Direction
-	if err := db.First(&direction, directionID).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("方向不存在")
+func (s *DirectionService) UpdateDirection(op *Operator, directionID uint, req *UpdateDirectionRequest) (*model.Direction, error) {
+	var after *model.Direction
+	err := s.repos.Transaction(func(tx *repository.Repositories) error {
+		direction, err := tx.Directions.FindByID(directionID, "Managers")
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return errors.New("方向不存在")
+			}
+			return err
 		}
-		return nil, err
-	}
+		before := *direction
 
-	// 更新基本信息
-	updates := make(map[string]interface{})
-	if req.Name != "" {
-		updates["name"] = req.Name
-	}
-	if req.Description != "" {
-		updates["description"] = req.Description
-	}
+		// 更新基本信息
+		updates := make(map[string]interface{})
+		if req.Name != "" {
+			updates["name"] = req.Name
+		}
+		if req.Description != "" {
+			if err := markdown.Check(req.Description); err != nil {
+				return err
+			}
+			updates["description"] = req.Description
+		}
 
-	if len(updates) > 0 {
-		if err := db.Model(&direction).Updates(updates).Error; err != nil {
-			return nil, err
+		if len(updates) > 0 {
+			if err := tx.Directions.Update(direction, updates); err != nil {
+				return err
+			}
 		}
-	}
 
-	// 更新负责人
-	if req.ManagerIDs != nil {
-		var managers []model.User
-		if len(req.ManagerIDs) > 0 {
-			if err := db.Where("id IN ?", req.ManagerIDs).Find(&managers).Error; err != nil {
-				return nil, err
+		// 更新负责人
+		if req.ManagerIDs != nil {
+			if err := tx.Directions.ReplaceManagers(direction, req.ManagerIDs); err != nil {
+				return err
 			}
 		}
-		if err := db.Model(&direction).Association("Managers").Replace(managers); err != nil {
-			return nil, err
+
+		// 重新加载包含关联数据的方向
+		after, err = tx.Directions.FindByID(direction.ID, "Managers")
+		if err != nil {
+			return err
 		}
-	}
 
-	// 重新加载包含关联数据的方向
-	if err := db.Preload("Managers").First(&direction, direction.ID).Error; err != nil {
+		return s.auditService.Record(tx.AuditLogs, op, AuditActionUpdate, AuditEntityDirection, direction.ID, before, after)
+	})
+	if err != nil {
 		return nil, err
 	}
 
-	return &direction, nil
+	renderDirection(after)
+	return after, nil
 }
 
 // DeleteDirection 删除方向
-func (s *DirectionService) DeleteDirection(directionID uint) error {
-	db := database.GetDB()
-
-	var direction model.Direction
-	if err := db.First(&direction, directionID).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return errors.New("方向不存在")
+func (s *DirectionService) DeleteDirection(op *Operator, directionID uint) error {
+	return s.repos.Transaction(func(tx *repository.Repositories) error {
+		direction, err := tx.Directions.FindByID(directionID, "Managers")
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return errors.New("方向不存在")
+			}
+			return err
 		}
-		return err
-	}
 
-	// 检查是否有关联的题目
-	var problemCount int64
-	if err := db.Model(&model.Problem{}).Where("direction_id = ?", directionID).Count(&problemCount).Error; err != nil {
-		return err
-	}
-	if problemCount > 0 {
-		return errors.New("该方向下还有题目，无法删除")
-	}
+		// 检查是否有关联的题目
+		problemCount, err := tx.Problems.CountByDirection(directionID)
+		if err != nil {
+			return err
+		}
+		if problemCount > 0 {
+			return errors.New("该方向下还有题目，无法删除")
+		}
 
-	// 清除关联的负责人
-	if err := db.Model(&direction).Association("Managers").Clear(); err != nil {
-		return err
-	}
+		if err := tx.Directions.Delete(direction); err != nil {
+			return err
+		}
 
-	return db.Delete(&direction).Error
+		return s.auditService.Record(tx.AuditLogs, op, AuditActionDelete, AuditEntityDirection, direction.ID, direction, nil)
+	})
 }
 
 // CheckDirectionManager 检查用户是否为方向负责人
 func (s *DirectionService) CheckDirectionManager(directionID, userID uint) (bool, error) {
-	db := database.GetDB()
-
-	var count int64
-	if err := db.Table("direction_managers").
-		Where("direction_id = ? AND user_id = ?", directionID, userID).
-		Count(&count).Error; err != nil {
-		return false, err
-	}
-
-	return count > 0, nil
-}
\ No newline at end of file
+	return s.repos.Directions.IsManager(directionID, userID)
+}
@@ -0,0 +1,92 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/tksky1/glimgate/internal/model"
+	"github.com/tksky1/glimgate/internal/repository"
+)
+
+// ExportScope 导出范围，按方向或单个题目导出
+type ExportScope struct {
+	// Name 用于导出文件名，如direction-1、problem-3
+	Name     string
+	problems []model.Problem
+}
+
+// problemIDs 范围内的题目ID
+func (e *ExportScope) problemIDs() []uint {
+	ids := make([]uint, 0, len(e.problems))
+	for _, problem := range e.problems {
+		ids = append(ids, problem.ID)
+	}
+	return ids
+}
+
+// resolveExportScope 解析导出范围，方向ID和题目ID必须且只能指定一个
+// 返回的题目按ID排序，题目的提交点也按ID排序
+func resolveExportScope(repos *repository.Repositories, directionID, problemID uint) (*ExportScope, error) {
+	if (directionID == 0) == (problemID == 0) {
+		return nil, errors.New("必须指定方向ID或题目ID之一")
+	}
+
+	scope := &ExportScope{}
+	if problemID > 0 {
+		problem, err := repos.Problems.FindByID(problemID, "SubmissionPoints")
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return nil, errors.New("题目不存在")
+			}
+			return nil, err
+		}
+		scope.Name = fmt.Sprintf("problem-%d", problem.ID)
+		scope.problems = []model.Problem{*problem}
+	} else {
+		if _, err := repos.Directions.FindByID(directionID); err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return nil, errors.New("方向不存在")
+			}
+			return nil, err
+		}
+		problems, err := repos.Problems.List(directionID)
+		if err != nil {
+			return nil, err
+		}
+		scope.Name = fmt.Sprintf("direction-%d", directionID)
+		scope.problems = problems
+	}
+
+	sort.Slice(scope.problems, func(i, j int) bool { return scope.problems[i].ID < scope.problems[j].ID })
+	for _, problem := range scope.problems {
+		points := problem.SubmissionPoints
+		sort.Slice(points, func(i, j int) bool { return points[i].ID < points[j].ID })
+	}
+	return scope, nil
+}
+
+// listExportSubmissions 获取导出范围内的全部提交，已删除提交点下的提交不导出
+func listExportSubmissions(repos *repository.Repositories, scope *ExportScope) ([]model.Submission, error) {
+	if len(scope.problems) == 0 {
+		return nil, nil
+	}
+	submissions, err := repos.Submissions.ListForExport(scope.problemIDs())
+	if err != nil {
+		return nil, err
+	}
+
+	points := make(map[uint]bool)
+	for _, problem := range scope.problems {
+		for _, point := range problem.SubmissionPoints {
+			points[point.ID] = true
+		}
+	}
+	result := submissions[:0]
+	for _, submission := range submissions {
+		if points[submission.SubmissionPointID] {
+			result = append(result, submission)
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,133 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/tksky1/glimgate/internal/model"
+	"github.com/tksky1/glimgate/internal/repository"
+	"github.com/tksky1/glimgate/pkg/config"
+	"github.com/tksky1/glimgate/pkg/database/dbtest"
+	"gorm.io/gorm"
+)
+
+// errInjected 测试中注入的数据库错误
+var errInjected = errors.New("注入的数据库错误")
+
+// testEnv 服务层测试环境：已迁移的SQLite数据库和使用它的服务，外部通知渠道均未启用
+type testEnv struct {
+	db    *gorm.DB
+	repos *repository.Repositories
+
+	notifications *NotificationService
+	audit         *AuditService
+	webhooks      *WebhookService
+	directions    *DirectionService
+	problems      *ProblemService
+	submissions   *SubmissionService
+	scores        *ScoreService
+	trash         *TrashService
+
+	admin model.User
+	op    *Operator
+}
+
+func newTestEnv(t *testing.T) *testEnv {
+	t.Helper()
+	config.AppConfig = &config.Config{}
+	db := dbtest.New(t)
+	repos := repository.NewRepositories(db)
+
+	env := &testEnv{db: db, repos: repos}
+	env.notifications = NewNotificationService(repos)
+	env.audit = NewAuditService(repos)
+	env.webhooks = NewWebhookService(repos)
+	env.directions = NewDirectionService(repos, env.audit)
+	env.problems = NewProblemService(repos, nil, env.notifications, env.audit)
+	env.submissions = NewSubmissionService(repos, env.notifications, env.webhooks)
+	env.scores = NewScoreService(repos, env.notifications, env.webhooks, env.audit)
+	env.trash = NewTrashService(repos, nil, env.audit)
+
+	env.admin = env.createUser(t, "admin", true)
+	env.op = &Operator{UserID: env.admin.ID, Username: env.admin.Username}
+	return env
+}
+
+func (env *testEnv) create(t *testing.T, value interface{}) {
+	t.Helper()
+	if err := env.db.Create(value).Error; err != nil {
+		t.Fatalf("创建测试数据失败: %v", err)
+	}
+}
+
+func (env *testEnv) createUser(t *testing.T, username string, isAdmin bool) model.User {
+	t.Helper()
+	user := model.User{
+		Username:  username,
+		Password:  "x",
+		Nickname:  username,
+		RealName:  "r",
+		College:   "c",
+		StudentID: username,
+		IsAdmin:   isAdmin,
+	}
+	env.create(t, &user)
+	return user
+}
+
+// createProblem 创建已发布的题目及一个人工评分的提交点
+func (env *testEnv) createProblem(t *testing.T, title string) (model.Problem, model.SubmissionPoint) {
+	t.Helper()
+	direction := model.Direction{Name: "方向" + title}
+	env.create(t, &direction)
+	problem := model.Problem{Title: title, Description: "d", DirectionID: direction.ID, Status: "published"}
+	env.create(t, &problem)
+	point := model.SubmissionPoint{ProblemID: problem.ID, Name: "提交点", MaxScore: 100, Type: "manual"}
+	env.create(t, &point)
+	return problem, point
+}
+
+// count 统计表中未删除的记录数，unscoped为true时包括已软删除的记录
+func (env *testEnv) count(t *testing.T, model interface{}, unscoped bool, query string, args ...interface{}) int64 {
+	t.Helper()
+	db := env.db
+	if unscoped {
+		db = db.Unscoped()
+	}
+	var n int64
+	if err := db.Model(model).Where(query, args...).Count(&n).Error; err != nil {
+		t.Fatalf("统计记录数失败: %v", err)
+	}
+	return n
+}
+
+// failOn 在table的create、update或delete语句执行前注入错误，用于验证事务回滚，测试结束时移除
+// 软删除经过delete回调，同样会失败
+func failOn(t *testing.T, db *gorm.DB, operation, table string) {
+	t.Helper()
+	name := fmt.Sprintf("test:fail_%s_%s", operation, table)
+	inject := func(tx *gorm.DB) {
+		if tx.Statement.Table == table {
+			tx.AddError(errInjected)
+		}
+	}
+
+	var err error
+	switch operation {
+	case "create":
+		err = db.Callback().Create().Before("gorm:create").Register(name, inject)
+		t.Cleanup(func() { db.Callback().Create().Remove(name) })
+	case "update":
+		err = db.Callback().Update().Before("gorm:update").Register(name, inject)
+		t.Cleanup(func() { db.Callback().Update().Remove(name) })
+	case "delete":
+		err = db.Callback().Delete().Before("gorm:delete").Register(name, inject)
+		t.Cleanup(func() { db.Callback().Delete().Remove(name) })
+	default:
+		t.Fatalf("未知的操作: %s", operation)
+	}
+	if err != nil {
+		t.Fatalf("注册回调失败: %v", err)
+	}
+}
@@ -0,0 +1,986 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/tksky1/glimgate/internal/model"
+	"github.com/tksky1/glimgate/internal/repository"
+	"github.com/tksky1/glimgate/pkg/config"
+	"github.com/tksky1/glimgate/pkg/sandbox"
+	"github.com/tksky1/glimgate/pkg/storage"
+	"github.com/tksky1/glimgate/pkg/utils"
+)
+
+// 提交点类型
+const (
+	SubmissionPointManual = "manual"
+	SubmissionPointAuto   = "auto"
+	SubmissionPointGit    = "git"
+)
+
+// 测试点评测结果，评测记录的结果为第一个未通过测试点的结果
+const (
+	VerdictAccepted     = "accepted"
+	VerdictWrongAnswer  = "wrong_answer"
+	VerdictRuntimeError = "runtime_error"
+	VerdictTimeLimit    = "time_limit_exceeded"
+	VerdictMemoryLimit  = "memory_limit_exceeded"
+	VerdictOutputLimit  = "output_limit_exceeded"
+	VerdictCompileError = "compile_error"
+)
+
+// judgeReviewerName 成绩表中系统评分一列的评分者名称
+const judgeReviewerName = "自动评测"
+
+// judgeMessageLimit 评测记录中保留的编译错误等提示信息的字节数
+const judgeMessageLimit = 4 << 10
+
+// ErrJudgeCaseTooLarge 测试数据超过大小限制
+var ErrJudgeCaseTooLarge = errors.New("测试数据超过大小限制")
+
+// judgeOperator 自动评测在审计日志中的操作人
+var judgeOperator = &Operator{Username: "judge"}
+
+// judgeWakeup 有新的等待评测记录时唤醒评测任务
+var judgeWakeup = make(chan struct{}, 1)
+
+// JudgeService 自动评测服务
+type JudgeService struct {
+	repos        *repository.Repositories
+	store        storage.Storage
+	scoreService *ScoreService
+	auditService *AuditService
+}
+
+// UpdateJudgeCaseRequest 更新测试点请求结构
+type UpdateJudgeCaseRequest struct {
+	Position *int `json:"position" example:"1"`
+	Weight   *int `json:"weight" binding:"omitempty,min=1" example:"2"`
+}
+
+// UpdateCheckerRequest 设置检查脚本请求结构，checker为空时清除检查脚本，按逐行比较判定
+type UpdateCheckerRequest struct {
+	Checker  string `json:"checker" example:"import sys\nsys.exit(0 if open(sys.argv[2]).read().split() == open(sys.argv[3]).read().split() else 1)"`
+	Language string `json:"language" example:"python"`
+}
+
+// RejudgeResult 重新评测结果
+type RejudgeResult struct {
+	Count int `json:"count" example:"12"` // 重新进入等待评测的提交数
+}
+
+// NewJudgeService 创建自动评测服务实例
+func NewJudgeService(repos *repository.Repositories, store storage.Storage, scoreService *ScoreService, auditService *AuditService) *JudgeService {
+	return &JudgeService{
+		repos:        repos,
+		store:        store,
+		scoreService: scoreService,
+		auditService: auditService,
+	}
+}
+
+// checkJudgeSettings 检查提交点类型和评测语言，自动评测的提交点必须使用配置中的评测语言
+func checkJudgeSettings(pointType, language string) error {
+	switch pointType {
+	case SubmissionPointManual, SubmissionPointGit:
+		return nil
+	case SubmissionPointAuto:
+		if _, ok := judgeConfig().Languages[language]; !ok {
+			return errors.New("评测语言未配置")
+		}
+		return nil
+	default:
+		return errors.New("提交点类型只能为manual、auto或git")
+	}
+}
+
+// findAutoPoint 获取自动评测的提交点
+func findAutoPoint(repos *repository.Repositories, pointID uint) (*model.SubmissionPoint, error) {
+	point, err := repos.Problems.FindPointByID(pointID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, errors.New("提交点不存在")
+		}
+		return nil, err
+	}
+	if point.Type != SubmissionPointAuto {
+		return nil, errors.New("提交点不是自动评测类型")
+	}
+	return point, nil
+}
+
+// GetCases 获取提交点的测试点列表
+func (s *JudgeService) GetCases(pointID uint) ([]model.JudgeCase, error) {
+	if _, err := findAutoPoint(s.repos, pointID); err != nil {
+		return nil, err
+	}
+	return s.repos.Judge.ListCases(pointID)
+}
+
+// CreateCase 上传测试点的输入和标准答案，不会自动重新评测已有的提交
+func (s *JudgeService) CreateCase(op *Operator, pointID uint, position, weight int, input, output io.Reader) (*model.JudgeCase, error) {
+	if weight < 1 {
+		return nil, errors.New("测试点权重不能小于1")
+	}
+	if _, err := findAutoPoint(s.repos, pointID); err != nil {
+		return nil, err
+	}
+
+	judgeCase := &model.JudgeCase{SubmissionPointID: pointID, Position: position, Weight: weight}
+	var err error
+	if judgeCase.InputKey, judgeCase.InputSize, err = s.storeCaseFile(pointID, "in", input); err != nil {
+		return nil, err
+	}
+	if judgeCase.OutputKey, judgeCase.OutputSize, err = s.storeCaseFile(pointID, "out", output); err != nil {
+		s.removeCaseFiles(judgeCase)
+		return nil, err
+	}
+
+	err = s.repos.Transaction(func(tx *repository.Repositories) error {
+		if err := tx.Judge.CreateCase(judgeCase); err != nil {
+			return err
+		}
+		return s.auditService.Record(tx.AuditLogs, op, AuditActionCreate, AuditEntityJudgeCase, judgeCase.ID, nil, judgeCase)
+	})
+	if err != nil {
+		s.removeCaseFiles(judgeCase)
+		return nil, err
+	}
+
+	return judgeCase, nil
+}
+
+// storeCaseFile 将测试数据写入存储，返回存储路径和大小，大小上限与附件相同
+func (s *JudgeService) storeCaseFile(pointID uint, ext string, r io.Reader) (string, int64, error) {
+	suffix, err := utils.RandomHex(16)
+	if err != nil {
+		return "", 0, err
+	}
+	key := fmt.Sprintf("judge/%d/%s.%s", pointID, suffix, ext)
+
+	maxSize := int64(attachmentConfig().MaxUploadMB) << 20
+	counter := &countingWriter{}
+	if err := s.store.Put(key, io.TeeReader(io.LimitReader(r, maxSize+1), counter)); err != nil {
+		return "", 0, err
+	}
+	if counter.n > maxSize {
+		s.removeStoredFile(key)
+		return "", 0, fmt.Errorf("%w(%dMB)", ErrJudgeCaseTooLarge, attachmentConfig().MaxUploadMB)
+	}
+	return key, counter.n, nil
+}
+
+// removeCaseFiles 删除测试点的数据文件，失败只记录日志
+func (s *JudgeService) removeCaseFiles(judgeCase *model.JudgeCase) {
+	for _, key := range []string{judgeCase.InputKey, judgeCase.OutputKey} {
+		if key != "" {
+			s.removeStoredFile(key)
+		}
+	}
+}
+
+// removeStoredFile 删除存储中的文件，失败只记录日志
+func (s *JudgeService) removeStoredFile(key string) {
+	if err := s.store.Delete(key); err != nil {
+		log.Printf("删除测试数据失败: %v", err)
+	}
+}
+
+// UpdateCase 修改测试点的顺序和权重
+func (s *JudgeService) UpdateCase(op *Operator, caseID uint, req *UpdateJudgeCaseRequest) (*model.JudgeCase, error) {
+	var after *model.JudgeCase
+	err := s.repos.Transaction(func(tx *repository.Repositories) error {
+		judgeCase, err := findJudgeCase(tx, caseID)
+		if err != nil {
+			return err
+		}
+		before := *judgeCase
+
+		updates := make(map[string]interface{})
+		if req.Position != nil {
+			updates["position"] = *req.Position
+		}
+		if req.Weight != nil {
+			updates["weight"] = *req.Weight
+		}
+		if len(updates) > 0 {
+			if err := tx.Judge.UpdateCase(judgeCase, updates); err != nil {
+				return err
+			}
+		}
+
+		if after, err = tx.Judge.FindCase(caseID); err != nil {
+			return err
+		}
+		return s.auditService.Record(tx.AuditLogs, op, AuditActionUpdate, AuditEntityJudgeCase, judgeCase.ID, before, after)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return after, nil
+}
+
+// DeleteCase 删除测试点及其数据文件，不会自动重新评测已有的提交
+func (s *JudgeService) DeleteCase(op *Operator, caseID uint) error {
+	var judgeCase *model.JudgeCase
+	err := s.repos.Transaction(func(tx *repository.Repositories) error {
+		var err error
+		if judgeCase, err = findJudgeCase(tx, caseID); err != nil {
+			return err
+		}
+		if err := tx.Judge.DeleteCase(judgeCase); err != nil {
+			return err
+		}
+		return s.auditService.Record(tx.AuditLogs, op, AuditActionDelete, AuditEntityJudgeCase, judgeCase.ID, judgeCase, nil)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.removeCaseFiles(judgeCase)
+	return nil
+}
+
+// OpenCaseFile 打开测试点的输入(input)或标准答案(output)，调用方负责关闭返回的文件
+func (s *JudgeService) OpenCaseFile(caseID uint, file string) (*model.JudgeCase, storage.File, error) {
+	judgeCase, err := findJudgeCase(s.repos, caseID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var key string
+	switch file {
+	case "input":
+		key = judgeCase.InputKey
+	case "output":
+		key = judgeCase.OutputKey
+	default:
+		return nil, nil, errors.New("文件只能为input或output")
+	}
+
+	f, err := s.store.Open(key)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotExist) {
+			return nil, nil, errors.New("测试数据文件不存在")
+		}
+		return nil, nil, err
+	}
+	return judgeCase, f, nil
+}
+
+// findJudgeCase 获取测试点
+func findJudgeCase(repos *repository.Repositories, caseID uint) (*model.JudgeCase, error) {
+	judgeCase, err := repos.Judge.FindCase(caseID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, errors.New("测试点不存在")
+		}
+		return nil, err
+	}
+	return judgeCase, nil
+}
+
+// UpdateChecker 设置提交点的检查脚本，检查脚本以输入、标准答案和选手输出的文件路径为参数，退出码为0表示通过
+func (s *JudgeService) UpdateChecker(op *Operator, pointID uint, req *UpdateCheckerRequest) (*model.SubmissionPoint, error) {
+	language := req.Language
+	if req.Checker == "" {
+		language = ""
+	} else if _, ok := judgeConfig().Languages[language]; !ok {
+		return nil, errors.New("评测语言未配置")
+	}
+
+	var after *model.SubmissionPoint
+	err := s.repos.Transaction(func(tx *repository.Repositories) error {
+		point, err := findAutoPoint(tx, pointID)
+		if err != nil {
+			return err
+		}
+		before := *point
+
+		if err := tx.Problems.UpdatePoint(point, map[string]interface{}{
+			"checker":          req.Checker,
+			"checker_language": language,
+		}); err != nil {
+			return err
+		}
+
+		if after, err = tx.Problems.FindPointByID(pointID); err != nil {
+			return err
+		}
+		return s.auditService.Record(tx.AuditLogs, op, AuditActionUpdate, AuditEntitySubmissionPoint, pointID, before, after)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return after, nil
+}
+
+// RejudgePoint 将提交点下的全部提交重新放入评测队列
+func (s *JudgeService) RejudgePoint(pointID uint) (*RejudgeResult, error) {
+	result := &RejudgeResult{}
+	err := s.repos.Transaction(func(tx *repository.Repositories) error {
+		if _, err := findAutoPoint(tx, pointID); err != nil {
+			return err
+		}
+
+		submissionIDs, err := tx.Submissions.IDsByPoint(pointID)
+		if err != nil {
+			return err
+		}
+		for _, submissionID := range submissionIDs {
+			if err := tx.Judge.ResetRun(submissionID); err != nil {
+				return err
+			}
+		}
+		result.Count = len(submissionIDs)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	wakeJudgeWorker()
+	return result, nil
+}
+
+// RejudgeSubmission 将提交重新放入评测队列
+func (s *JudgeService) RejudgeSubmission(submissionID uint) (*model.JudgeRun, error) {
+	var run *model.JudgeRun
+	err := s.repos.Transaction(func(tx *repository.Repositories) error {
+		submission, err := tx.Submissions.FindByID(submissionID)
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return errors.New("提交不存在")
+			}
+			return err
+		}
+		if _, err := findAutoPoint(tx, submission.SubmissionPointID); err != nil {
+			return err
+		}
+
+		if err := tx.Judge.ResetRun(submissionID); err != nil {
+			return err
+		}
+		run, err = tx.Judge.FindRun(submissionID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	wakeJudgeWorker()
+	return run, nil
+}
+
+// GetJudgeRun 获取提交的评测记录，只有提交者本人、所属队伍的成员或管理员可以查看
+func (s *JudgeService) GetJudgeRun(submissionID, userID uint, isAdmin bool) (*model.JudgeRun, error) {
+	submission, err := s.repos.Submissions.FindByID(submissionID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, errors.New("提交不存在")
+		}
+		return nil, err
+	}
+	if !isAdmin {
+		allowed, err := canViewSubmission(s.repos, submission, userID)
+		if err != nil {
+			return nil, err
+		}
+		if !allowed {
+			return nil, errors.New("无权限查看该提交")
+		}
+	}
+
+	run, err := s.repos.Judge.FindRun(submissionID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, errors.New("评测记录不存在")
+		}
+		return nil, err
+	}
+
+	run.Cases = []model.JudgeCaseResult{}
+	if run.Results != "" {
+		if err := json.Unmarshal([]byte(run.Results), &run.Cases); err != nil {
+			return nil, err
+		}
+	}
+	return run, nil
+}
+
+// StartWorker 检查配置并启动评测任务，在后台循环评测等待中的提交
+// 启动时先将上次中断的评测放回队列；未配置专用的运行用户或当前平台不支持沙箱时返回错误
+func (s *JudgeService) StartWorker() error {
+	cfg := judgeConfig()
+	if err := checkRunAsUser(cfg.RunAsUID, cfg.RunAsGID); err != nil {
+		return err
+	}
+	// 每个评测任务使用2个专用用户，分别运行选手程序和检查脚本，同时评测的提交之间也互相隔离
+	workers := make([]*judgeWorker, cfg.Workers)
+	for i := range workers {
+		program, err := newJudgeRunner(cfg, cfg.RunAsUID+2*i)
+		if err != nil {
+			return err
+		}
+		checker, err := newJudgeRunner(cfg, cfg.RunAsUID+2*i+1)
+		if err != nil {
+			return err
+		}
+		workers[i] = &judgeWorker{program: program, checker: checker}
+	}
+	if err := os.MkdirAll(cfg.GetWorkDir(), 0o755); err != nil {
+		return fmt.Errorf("创建评测目录失败: %w", err)
+	}
+
+	if n, err := s.repos.Judge.RequeueRunning(); err != nil {
+		log.Printf("恢复中断的评测失败: %v", err)
+	} else if n > 0 {
+		log.Printf("恢复了 %d 个中断的评测", n)
+	}
+
+	for _, worker := range workers {
+		go s.work(worker)
+	}
+	return nil
+}
+
+// judgeRunner 以一个专用用户运行程序的沙箱，uid为0时不切换用户
+type judgeRunner struct {
+	sb  *sandbox.Sandbox
+	uid int
+	gid int
+}
+
+// newJudgeRunner 创建以uid和配置的用户组运行程序的沙箱
+func newJudgeRunner(cfg config.JudgeConfig, uid int) (*judgeRunner, error) {
+	sb, err := sandbox.New(sandbox.Options{UID: uid, GID: cfg.RunAsGID, IsolateNetwork: cfg.IsolateNetwork})
+	if err != nil {
+		return nil, err
+	}
+	return &judgeRunner{sb: sb, uid: uid, gid: cfg.RunAsGID}, nil
+}
+
+// judgeWorker 一个评测任务使用的沙箱，选手程序和检查脚本以不同的用户运行，选手程序无法读取检查脚本和标准答案
+type judgeWorker struct {
+	program *judgeRunner
+	checker *judgeRunner
+}
+
+// checkRunAsUser 检查运行选手代码的用户，必须是专用的低权限用户，否则选手代码能读取测试数据、配置文件等服务可访问的文件
+// 切换用户需要服务以root运行
+func checkRunAsUser(uid, gid int) error {
+	if uid <= 0 || gid <= 0 {
+		return errors.New("run_as_uid和run_as_gid必须配置为专用的低权限用户")
+	}
+	if os.Geteuid() != 0 {
+		return errors.New("以run_as_uid运行选手代码需要以root运行服务")
+	}
+	return nil
+}
+
+// work 循环取出并评测等待中的提交，队列为空时等待唤醒或下一次轮询
+func (s *JudgeService) work(worker *judgeWorker) {
+	interval := time.Duration(judgeConfig().PollIntervalSeconds) * time.Second
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		run, err := s.repos.Judge.ClaimNextRun()
+		if err == nil {
+			// 队列中可能还有其他记录，唤醒空闲的评测任务
+			wakeJudgeWorker()
+			s.judge(worker, run)
+			continue
+		}
+		if !errors.Is(err, repository.ErrNotFound) {
+			log.Printf("获取待评测记录失败: %v", err)
+		}
+
+		select {
+		case <-ticker.C:
+		case <-judgeWakeup:
+		}
+	}
+}
+
+// judgeOutcome 一次评测的结果，failure不为空时表示评测失败
+type judgeOutcome struct {
+	verdict string
+	score   int
+	passed  int
+	message string
+	cases   []model.JudgeCaseResult
+	failure string
+}
+
+// judge 评测一条记录并写入结果
+func (s *JudgeService) judge(worker *judgeWorker, run *model.JudgeRun) {
+	submission, err := s.repos.Submissions.FindByID(run.SubmissionID, "Problem", "SubmissionPoint")
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			s.finish(run, nil, &judgeOutcome{failure: "提交不存在"})
+		} else {
+			log.Printf("加载提交失败: %v", err)
+			s.finish(run, nil, &judgeOutcome{failure: "加载提交失败"})
+		}
+		return
+	}
+
+	outcome, err := s.evaluate(worker, submission)
+	if err != nil {
+		log.Printf("评测提交 %d 失败: %v", submission.ID, err)
+		outcome = &judgeOutcome{failure: err.Error()}
+	}
+	s.finish(run, submission, outcome)
+}
+
+// evaluate 在临时目录中编译并运行提交的程序，逐个测试点比较输出
+func (s *JudgeService) evaluate(worker *judgeWorker, submission *model.Submission) (*judgeOutcome, error) {
+	cfg := judgeConfig()
+	point := submission.SubmissionPoint
+	if point.Type != SubmissionPointAuto {
+		return nil, errors.New("提交点不是自动评测类型")
+	}
+	language, ok := cfg.Languages[point.JudgeLanguage]
+	if !ok {
+		return nil, errors.New("评测语言未配置")
+	}
+	cases, err := s.repos.Judge.ListCases(point.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(cases) == 0 {
+		return nil, errors.New("提交点没有测试点")
+	}
+
+	base, err := os.MkdirTemp(cfg.GetWorkDir(), "run-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(base)
+	// 运行用户只能进入临时目录，不能列出其中的文件
+	if err := os.Chmod(base, 0o711); err != nil {
+		return nil, err
+	}
+	dir, err := makeJudgeDir(worker.program, base, "program")
+	if err != nil {
+		return nil, err
+	}
+
+	// 编译选手程序和检查脚本
+	if err := writeJudgeFile(worker.program, filepath.Join(dir, language.Source), []byte(submission.Content)); err != nil {
+		return nil, err
+	}
+	if result, err := compileJudgeSource(worker.program, cfg, dir, language); err != nil {
+		return nil, err
+	} else if result != nil {
+		return &judgeOutcome{
+			verdict: VerdictCompileError,
+			message: truncateJudgeMessage(string(result.Stderr) + string(result.Stdout)),
+			cases:   []model.JudgeCaseResult{},
+		}, nil
+	}
+	checker, err := prepareChecker(worker.checker, cfg, base, &point)
+	if err != nil {
+		return nil, err
+	}
+
+	limits := sandbox.Limits{
+		CPUTime:     time.Duration(cfg.DefaultTimeLimitMS) * time.Millisecond,
+		MemoryMB:    cfg.DefaultMemoryLimitMB,
+		OutputBytes: int64(cfg.MaxOutputKB) << 10,
+		Processes:   cfg.MaxProcesses,
+	}
+	if point.TimeLimitMS > 0 {
+		limits.CPUTime = time.Duration(point.TimeLimitMS) * time.Millisecond
+	}
+	if point.MemoryLimitMB > 0 {
+		limits.MemoryMB = point.MemoryLimitMB
+	}
+	// 运行时间留出余量，避免等待输入或睡眠的程序长期占用评测任务
+	limits.WallTime = limits.CPUTime*2 + time.Second
+
+	outcome := &judgeOutcome{verdict: VerdictAccepted, cases: make([]model.JudgeCaseResult, 0, len(cases))}
+	totalWeight, passedWeight := 0, 0
+	for i := range cases {
+		verdict, result, err := s.runCase(worker.program, dir, language, limits, checker, &cases[i])
+		if err != nil {
+			return nil, err
+		}
+		outcome.cases = append(outcome.cases, model.JudgeCaseResult{
+			Position: i + 1,
+			Verdict:  verdict,
+			TimeMS:   result.CPUTime.Milliseconds(),
+			MemoryKB: result.MemoryKB,
+		})
+
+		totalWeight += cases[i].Weight
+		if verdict == VerdictAccepted {
+			passedWeight += cases[i].Weight
+			outcome.passed++
+		} else if outcome.verdict == VerdictAccepted {
+			outcome.verdict = verdict
+			outcome.message = truncateJudgeMessage(string(result.Stderr))
+		}
+	}
+	outcome.score = point.MaxScore * passedWeight / totalWeight
+	return outcome, nil
+}
+
+// makeJudgeDir 在本次评测的临时目录base下创建只有运行用户能访问的子目录
+// 选手程序和检查脚本各用一个子目录，分属不同的用户，选手程序无法读取或改动检查脚本
+func makeJudgeDir(runner *judgeRunner, base, name string) (string, error) {
+	dir, err := filepath.Abs(filepath.Join(base, name))
+	if err != nil {
+		return "", err
+	}
+	if err := os.Mkdir(dir, 0o700); err != nil {
+		return "", err
+	}
+	if runner.uid > 0 {
+		if err := os.Chown(dir, runner.uid, runner.gid); err != nil {
+			return "", err
+		}
+	}
+	return dir, nil
+}
+
+// writeJudgeFile 写入只有运行用户能读写的文件
+func writeJudgeFile(runner *judgeRunner, path string, data []byte) error {
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return err
+	}
+	if runner.uid > 0 {
+		return os.Chown(path, runner.uid, runner.gid)
+	}
+	return nil
+}
+
+// compileJudgeSource 在dir中编译源代码，编译失败时返回编译的运行结果，无需编译或编译成功时返回nil
+func compileJudgeSource(runner *judgeRunner, cfg config.JudgeConfig, dir string, language config.JudgeLanguage) (*sandbox.Result, error) {
+	if len(language.Compile) == 0 {
+		return nil, nil
+	}
+
+	timeout := time.Duration(cfg.CompileTimeoutSeconds) * time.Second
+	result, err := runner.sb.Run(&sandbox.Command{
+		Args: language.Compile,
+		Dir:  dir,
+		Limits: sandbox.Limits{
+			CPUTime:     timeout,
+			WallTime:    timeout * 2,
+			OutputBytes: int64(cfg.MaxOutputKB) << 10,
+			Processes:   cfg.MaxProcesses,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if result.Status != sandbox.StatusOK {
+		return result, nil
+	}
+	return nil, nil
+}
+
+// judgeChecker 编译好的检查脚本
+type judgeChecker struct {
+	runner    *judgeRunner
+	dir       string
+	language  config.JudgeLanguage
+	timeout   time.Duration
+	processes int
+}
+
+// prepareChecker 在base下编译提交点的检查脚本，未设置时返回nil
+func prepareChecker(runner *judgeRunner, cfg config.JudgeConfig, base string, point *model.SubmissionPoint) (*judgeChecker, error) {
+	if point.Checker == "" {
+		return nil, nil
+	}
+	language, ok := cfg.Languages[point.CheckerLanguage]
+	if !ok {
+		return nil, errors.New("检查脚本的评测语言未配置")
+	}
+
+	checkerDir, err := makeJudgeDir(runner, base, "checker")
+	if err != nil {
+		return nil, err
+	}
+	if err := writeJudgeFile(runner, filepath.Join(checkerDir, language.Source), []byte(point.Checker)); err != nil {
+		return nil, err
+	}
+	if result, err := compileJudgeSource(runner, cfg, checkerDir, language); err != nil {
+		return nil, err
+	} else if result != nil {
+		return nil, fmt.Errorf("检查脚本编译失败: %s", truncateJudgeMessage(string(result.Stderr)))
+	}
+
+	return &judgeChecker{
+		runner:    runner,
+		dir:       checkerDir,
+		language:  language,
+		timeout:   time.Duration(cfg.CompileTimeoutSeconds) * time.Second,
+		processes: cfg.MaxProcesses,
+	}, nil
+}
+
+// runCase 运行一个测试点并判定结果
+func (s *JudgeService) runCase(runner *judgeRunner, dir string, language config.JudgeLanguage, limits sandbox.Limits, checker *judgeChecker, judgeCase *model.JudgeCase) (string, *sandbox.Result, error) {
+	input, err := s.store.Open(judgeCase.InputKey)
+	if err != nil {
+		return "", nil, fmt.Errorf("打开测试数据失败: %w", err)
+	}
+	defer input.Close()
+
+	result, err := runner.sb.Run(&sandbox.Command{
+		Args:   language.Run,
+		Dir:    dir,
+		Stdin:  input,
+		Limits: limits,
+	})
+	if err != nil {
+		return "", nil, err
+	}
+	switch result.Status {
+	case sandbox.StatusRuntimeError:
+		return VerdictRuntimeError, result, nil
+	case sandbox.StatusTimeLimit:
+		return VerdictTimeLimit, result, nil
+	case sandbox.StatusMemoryLimit:
+		return VerdictMemoryLimit, result, nil
+	case sandbox.StatusOutputLimit:
+		return VerdictOutputLimit, result, nil
+	}
+
+	expected, err := s.readCaseFile(judgeCase.OutputKey)
+	if err != nil {
+		return "", nil, err
+	}
+	if checker == nil {
+		if outputsMatch(expected, result.Stdout) {
+			return VerdictAccepted, result, nil
+		}
+		return VerdictWrongAnswer, result, nil
+	}
+
+	accepted, err := s.runChecker(checker, judgeCase, expected, result.Stdout)
+	if err != nil {
+		return "", nil, err
+	}
+	if accepted {
+		return VerdictAccepted, result, nil
+	}
+	return VerdictWrongAnswer, result, nil
+}
+
+// runChecker 在选手程序结束后把测试数据写入检查脚本的目录并运行检查脚本，选手程序的用户无法访问该目录
+func (s *JudgeService) runChecker(checker *judgeChecker, judgeCase *model.JudgeCase, expected, actual []byte) (bool, error) {
+	input, err := s.readCaseFile(judgeCase.InputKey)
+	if err != nil {
+		return false, err
+	}
+
+	files := map[string][]byte{"input.txt": input, "expected.txt": expected, "actual.txt": actual}
+	args := append([]string{}, checker.language.Run...)
+	for _, name := range []string{"input.txt", "expected.txt", "actual.txt"} {
+		path := filepath.Join(checker.dir, name)
+		if err := writeJudgeFile(checker.runner, path, files[name]); err != nil {
+			return false, err
+		}
+		defer os.Remove(path)
+		args = append(args, path)
+	}
+
+	result, err := checker.runner.sb.Run(&sandbox.Command{
+		Args: args,
+		Dir:  checker.dir,
+		Limits: sandbox.Limits{
+			CPUTime:   checker.timeout,
+			WallTime:  checker.timeout * 2,
+			Processes: checker.processes,
+		},
+	})
+	if err != nil {
+		return false, err
+	}
+	switch {
+	case result.Status == sandbox.StatusOK:
+		return true, nil
+	case result.Status == sandbox.StatusRuntimeError && result.ExitCode > 0:
+		return false, nil
+	default:
+		return false, fmt.Errorf("检查脚本运行失败: %s", strings.TrimSpace(string(result.Status)+" "+truncateJudgeMessage(string(result.Stderr))))
+	}
+}
+
+// readCaseFile 读取测试数据
+func (s *JudgeService) readCaseFile(key string) ([]byte, error) {
+	f, err := s.store.Open(key)
+	if err != nil {
+		return nil, fmt.Errorf("打开测试数据失败: %w", err)
+	}
+	defer f.Close()
+	return io.ReadAll(f)
+}
+
+// outputsMatch 比较输出与标准答案，忽略行尾空白、换行符差异和末尾空行
+func outputsMatch(expected, actual []byte) bool {
+	normalize := func(b []byte) []string {
+		lines := strings.Split(string(bytes.ReplaceAll(b, []byte("\r\n"), []byte("\n"))), "\n")
+		for i := range lines {
+			lines[i] = strings.TrimRight(lines[i], " \t\r")
+		}
+		for len(lines) > 0 && lines[len(lines)-1] == "" {
+			lines = lines[:len(lines)-1]
+		}
+		return lines
+	}
+
+	want, got := normalize(expected), normalize(actual)
+	if len(want) != len(got) {
+		return false
+	}
+	for i := range want {
+		if want[i] != got[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// truncateJudgeMessage 截断编译错误等提示信息，保留开头部分
+func truncateJudgeMessage(message string) string {
+	if len(message) <= judgeMessageLimit {
+		return message
+	}
+	return strings.ToValidUTF8(message[:judgeMessageLimit], "") + "\n..."
+}
+
+// finish 写入评测结果：评测完成时以系统评分记录得分，评测失败时删除之前的系统评分
+// 评测期间提交被重新提交或重新评测时丢弃本次结果
+func (s *JudgeService) finish(run *model.JudgeRun, submission *model.Submission, outcome *judgeOutcome) {
+	now := time.Now()
+	updates := map[string]interface{}{"finished_at": now}
+	if outcome.failure != "" {
+		updates["status"] = repository.JudgeStatusFailed
+		updates["message"] = outcome.failure
+	} else {
+		results, err := json.Marshal(outcome.cases)
+		if err != nil {
+			log.Printf("保存评测结果失败: %v", err)
+			return
+		}
+		updates["status"] = repository.JudgeStatusFinished
+		updates["verdict"] = outcome.verdict
+		updates["score"] = outcome.score
+		updates["passed_count"] = outcome.passed
+		updates["case_count"] = len(outcome.cases)
+		updates["message"] = outcome.message
+		updates["results"] = string(results)
+	}
+
+	var score *model.Score
+	var eventType string
+	err := s.repos.Transaction(func(tx *repository.Repositories) error {
+		current, err := tx.Judge.FinishRun(run, updates)
+		if err != nil || !current || submission == nil {
+			return err
+		}
+
+		existing, err := tx.Scores.FindBySubmissionAndReviewer(submission.ID, model.JudgeReviewerID)
+		if err != nil && !errors.Is(err, repository.ErrNotFound) {
+			return err
+		}
+		if outcome.failure != "" {
+			if existing == nil {
+				return nil
+			}
+			if err := tx.Scores.Delete(existing); err != nil {
+				return err
+			}
+			score, eventType = existing, EventScoreDeleted
+			return s.auditService.Record(tx.AuditLogs, judgeOperator, AuditActionDelete, AuditEntityScore, existing.ID, existing, nil)
+		}
+
+		isNew, err := tx.Scores.Upsert(&model.Score{
+			Score:        outcome.score,
+			Comment:      fmt.Sprintf("自动评测：通过%d/%d个测试点", outcome.passed, len(outcome.cases)),
+			UserID:       submission.UserID,
+			SubmissionID: submission.ID,
+			ReviewerID:   model.JudgeReviewerID,
+		})
+		if err != nil {
+			return err
+		}
+		if score, err = tx.Scores.FindBySubmissionAndReviewer(submission.ID, model.JudgeReviewerID, "User", "Submission"); err != nil {
+			return err
+		}
+
+		if isNew {
+			eventType = EventScoreCreated
+			return s.auditService.Record(tx.AuditLogs, judgeOperator, AuditActionCreate, AuditEntityScore, score.ID, nil, score)
+		}
+		eventType = EventScoreUpdated
+		return s.auditService.Record(tx.AuditLogs, judgeOperator, AuditActionUpdate, AuditEntityScore, score.ID, existing, score)
+	})
+	if err != nil {
+		log.Printf("保存评测结果失败: %v", err)
+		return
+	}
+	if score == nil {
+		return
+	}
+
+	switch eventType {
+	case EventScoreCreated:
+		s.scoreService.notifyScore(NotificationScoreCreated, score, submission)
+	case EventScoreUpdated:
+		s.scoreService.notifyScore(NotificationScoreUpdated, score, submission)
+	}
+	s.scoreService.publishEvent(eventType, score, submission.Problem.DirectionID)
+}
+
+// judgeConfig 获取自动评测配置，未配置的项使用默认值
+func judgeConfig() config.JudgeConfig {
+	cfg := config.AppConfig.Judge
+	if cfg.Workers <= 0 {
+		cfg.Workers = 1
+	}
+	if cfg.PollIntervalSeconds <= 0 {
+		cfg.PollIntervalSeconds = 5
+	}
+	if cfg.DefaultTimeLimitMS <= 0 {
+		cfg.DefaultTimeLimitMS = 1000
+	}
+	if cfg.DefaultMemoryLimitMB <= 0 {
+		cfg.DefaultMemoryLimitMB = 256
+	}
+	if cfg.CompileTimeoutSeconds <= 0 {
+		cfg.CompileTimeoutSeconds = 10
+	}
+	if cfg.MaxOutputKB <= 0 {
+		cfg.MaxOutputKB = 1024
+	}
+	if cfg.MaxProcesses <= 0 {
+		cfg.MaxProcesses = 128
+	}
+	return cfg
+}
+
+// wakeJudgeWorker 非阻塞地唤醒评测任务
+func wakeJudgeWorker() {
+	select {
+	case judgeWakeup <- struct{}{}:
+	default:
+	}
+}
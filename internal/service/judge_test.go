@@ -0,0 +1,92 @@
+package service
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/tksky1/glimgate/internal/model"
+	"github.com/tksky1/glimgate/pkg/config"
+	"github.com/tksky1/glimgate/pkg/storage"
+)
+
+func TestJudgeStartWorkerRequiresRunAsUser(t *testing.T) {
+	env := newTestEnv(t)
+	judge := NewJudgeService(env.repos, nil, env.scores, env.audit)
+
+	for _, cfg := range []config.JudgeConfig{
+		{Enabled: true},
+		{Enabled: true, RunAsUID: 1000},
+		{Enabled: true, RunAsGID: 1000},
+	} {
+		config.AppConfig.Judge = cfg
+		if err := judge.StartWorker(); err == nil || err.Error() != "run_as_uid和run_as_gid必须配置为专用的低权限用户" {
+			t.Errorf("uid=%d gid=%d时应拒绝启动，得到%v", cfg.RunAsUID, cfg.RunAsGID, err)
+		}
+	}
+}
+
+// TestJudgeIsolatesChecker 选手程序以独立的用户运行，无法读取检查脚本所在的目录
+func TestJudgeIsolatesChecker(t *testing.T) {
+	if os.Geteuid() != 0 {
+		t.Skip("切换用户需要以root运行测试")
+	}
+	env := newTestEnv(t)
+	store, err := storage.NewLocal(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	judge := NewJudgeService(env.repos, store, env.scores, env.audit)
+
+	// 运行用户需要能进入评测目录
+	workDir := t.TempDir()
+	for dir := workDir; dir != os.TempDir(); dir = filepath.Dir(dir) {
+		if err := os.Chmod(dir, 0o711); err != nil {
+			t.Fatal(err)
+		}
+	}
+	config.AppConfig.Judge = config.JudgeConfig{
+		WorkDir:  workDir,
+		RunAsUID: 64930,
+		RunAsGID: 64930,
+		Languages: map[string]config.JudgeLanguage{
+			"sh": {Source: "main.sh", Run: []string{"sh", "main.sh"}},
+		},
+	}
+	worker := &judgeWorker{}
+	if worker.program, err = newJudgeRunner(judgeConfig(), 64930); err != nil {
+		t.Fatal(err)
+	}
+	if worker.checker, err = newJudgeRunner(judgeConfig(), 64931); err != nil {
+		t.Fatal(err)
+	}
+
+	problem, _ := env.createProblem(t, "评测题")
+	point := model.SubmissionPoint{
+		ProblemID:       problem.ID,
+		Name:            "程序",
+		MaxScore:        100,
+		Type:            SubmissionPointAuto,
+		JudgeLanguage:   "sh",
+		Checker:         `[ "$(cat "$3")" = "$(cat "$2")" ]`,
+		CheckerLanguage: "sh",
+	}
+	env.create(t, &point)
+	for key, content := range map[string]string{"in": "1\n", "out": "denied\n"} {
+		if err := store.Put(key, strings.NewReader(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	env.create(t, &model.JudgeCase{SubmissionPointID: point.ID, Weight: 1, InputKey: "in", OutputKey: "out"})
+
+	// 能读到检查脚本时输出其内容，判为答案错误
+	submission := &model.Submission{Content: "cat ../checker/main.sh 2>/dev/null || echo denied", SubmissionPoint: point}
+	outcome, err := judge.evaluate(worker, submission)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if outcome.verdict != VerdictAccepted || outcome.score != 100 {
+		t.Errorf("选手程序不应能读取检查脚本，结果%s，得分%d", outcome.verdict, outcome.score)
+	}
+}
@@ -0,0 +1,39 @@
+package service
+
+import (
+	"fmt"
+
+	"github.com/tksky1/glimgate/internal/model"
+	"github.com/tksky1/glimgate/pkg/markdown"
+)
+
+// renderProblem 渲染题面，attachments/下的相对链接改写为按文件名下载附件的地址
+func renderProblem(problem *model.Problem) {
+	if problem == nil {
+		return
+	}
+	problem.DescriptionHTML = markdown.Render(problem.Description, fmt.Sprintf("/api/problems/%d/attachments/", problem.ID))
+}
+
+// renderProblems 渲染题目列表的题面
+func renderProblems(problems []model.Problem) {
+	for i := range problems {
+		renderProblem(&problems[i])
+	}
+}
+
+// renderHints 渲染提示内容，相对链接与题面一样指向题目附件
+func renderHints(hints []model.ProblemHint) {
+	for i := range hints {
+		hints[i].ContentHTML = markdown.Render(hints[i].Content, fmt.Sprintf("/api/problems/%d/attachments/", hints[i].ProblemID))
+	}
+}
+
+// renderDirection 渲染方向描述及其下题目的题面
+func renderDirection(direction *model.Direction) {
+	if direction == nil {
+		return
+	}
+	direction.DescriptionHTML = markdown.Render(direction.Description, "")
+	renderProblems(direction.Problems)
+}
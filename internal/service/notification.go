@@ -0,0 +1,355 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/tksky1/glimgate/internal/model"
+	"github.com/tksky1/glimgate/internal/repository"
+	"github.com/tksky1/glimgate/pkg/config"
+	"github.com/tksky1/glimgate/pkg/notify"
+	"github.com/tksky1/glimgate/pkg/utils"
+)
+
+// 通知类型
+const (
+	NotificationScoreCreated          = "score_created"
+	NotificationScoreUpdated          = "score_updated"
+	NotificationSubmissionCreated     = "submission_created"
+	NotificationDeadlineReminder      = "deadline_reminder"
+	NotificationClarificationAnswered = "clarification_answered"
+	NotificationProblemUpdated        = "problem_updated"
+	NotificationTeamInvite            = "team_invite"
+)
+
+// NotificationService 通知服务
+type NotificationService struct {
+	repos    *repository.Repositories
+	channels []notify.Channel
+}
+
+// UpdateNotificationSettingRequest 更新通知设置请求结构
+type UpdateNotificationSettingRequest struct {
+	Email      *bool   `json:"email" example:"true"`
+	Webhook    *bool   `json:"webhook" example:"false"`
+	WebhookURL *string `json:"webhook_url" example:"https://example.com/hook"`
+	OneBot     *bool   `json:"onebot" example:"true"`
+}
+
+// NotificationSettingResponse 通知设置响应结构
+type NotificationSettingResponse struct {
+	model.NotificationSetting
+	AvailableChannels []string `json:"available_channels" example:"email,onebot"`
+}
+
+// NewNotificationService 创建通知服务实例，按配置启用外部投递渠道
+func NewNotificationService(repos *repository.Repositories) *NotificationService {
+	cfg := config.AppConfig.Notification
+	timeout := time.Duration(cfg.TimeoutSeconds) * time.Second
+
+	var channels []notify.Channel
+	if cfg.Email.Enabled {
+		channels = append(channels, notify.NewEmailChannel(cfg.Email.APIURL, cfg.Email.APIKey, cfg.Email.From, timeout))
+	}
+	if cfg.Webhook.Enabled {
+		channels = append(channels, notify.NewWebhookChannel(timeout, cfg.Webhook.AllowPrivateHosts))
+	}
+	if cfg.OneBot.Enabled {
+		channels = append(channels, notify.NewOneBotChannel(cfg.OneBot.BaseURL, cfg.OneBot.AccessToken, timeout))
+	}
+
+	return &NotificationService{
+		repos:    repos,
+		channels: channels,
+	}
+}
+
+// Notify 向用户发送站内通知，并按用户设置异步投递到外部渠道
+func (s *NotificationService) Notify(userIDs []uint, notificationType, title, content string, relatedID uint) error {
+	notifications, err := s.createNotifications(s.repos.Notifications, userIDs, notificationType, title, content, relatedID)
+	if err != nil {
+		return err
+	}
+
+	s.dispatch(userIDs, notifications)
+	return nil
+}
+
+// createNotifications 通过repo写入站内通知，repo取自事务时外部渠道投递需在事务提交后调用dispatch
+func (s *NotificationService) createNotifications(repo repository.NotificationRepository, userIDs []uint, notificationType, title, content string, relatedID uint) ([]model.Notification, error) {
+	if len(userIDs) == 0 {
+		return nil, nil
+	}
+
+	notifications := make([]model.Notification, 0, len(userIDs))
+	for _, userID := range userIDs {
+		notifications = append(notifications, model.Notification{
+			UserID:    userID,
+			Type:      notificationType,
+			Title:     title,
+			Content:   content,
+			RelatedID: relatedID,
+		})
+	}
+	if err := repo.Create(notifications); err != nil {
+		return nil, err
+	}
+
+	return notifications, nil
+}
+
+// dispatch 异步向外部渠道投递已写入的通知
+func (s *NotificationService) dispatch(userIDs []uint, notifications []model.Notification) {
+	if len(notifications) == 0 || len(s.channels) == 0 {
+		return
+	}
+
+	first := notifications[0]
+	msg := notify.Message{
+		Type:      first.Type,
+		Title:     first.Title,
+		Content:   first.Content,
+		CreatedAt: first.CreatedAt,
+	}
+	go s.deliver(userIDs, msg)
+}
+
+// deliver 向外部渠道投递通知，失败只记录日志
+func (s *NotificationService) deliver(userIDs []uint, msg notify.Message) {
+	users, err := s.repos.Users.ListByIDs(userIDs)
+	if err != nil {
+		log.Printf("加载通知接收者失败: %v", err)
+		return
+	}
+
+	settings, err := s.repos.Notifications.ListSettings(userIDs)
+	if err != nil {
+		log.Printf("加载通知设置失败: %v", err)
+		return
+	}
+	settingMap := make(map[uint]model.NotificationSetting, len(settings))
+	for _, setting := range settings {
+		settingMap[setting.UserID] = setting
+	}
+
+	ctx := context.Background()
+	for _, user := range users {
+		setting, ok := settingMap[user.ID]
+		if !ok {
+			continue
+		}
+
+		recipient := notify.Recipient{
+			UserID:     user.ID,
+			Nickname:   user.Nickname,
+			Email:      user.Email,
+			QQ:         user.QQ,
+			WebhookURL: setting.WebhookURL,
+		}
+		for _, channel := range s.channels {
+			if !channelEnabled(setting, channel.Name()) {
+				continue
+			}
+			if err := channel.Send(ctx, recipient, msg); err != nil {
+				log.Printf("通过%s向用户%d投递通知失败: %v", channel.Name(), user.ID, err)
+			}
+		}
+	}
+}
+
+// channelEnabled 判断用户是否开启了指定渠道
+func channelEnabled(setting model.NotificationSetting, name string) bool {
+	switch name {
+	case notify.ChannelEmail:
+		return setting.Email
+	case notify.ChannelWebhook:
+		return setting.Webhook
+	case notify.ChannelOneBot:
+		return setting.OneBot
+	}
+	return false
+}
+
+// GetNotifications 获取用户的通知列表
+func (s *NotificationService) GetNotifications(userID uint, unreadOnly bool, page, pageSize int) ([]model.Notification, int64, error) {
+	offset := (page - 1) * pageSize
+	return s.repos.Notifications.List(userID, unreadOnly, offset, pageSize)
+}
+
+// GetUnreadCount 获取用户未读通知数
+func (s *NotificationService) GetUnreadCount(userID uint) (int64, error) {
+	return s.repos.Notifications.CountUnread(userID)
+}
+
+// MarkRead 将通知标记为已读
+func (s *NotificationService) MarkRead(notificationID, userID uint) error {
+	notification, err := s.repos.Notifications.FindByIDAndUser(notificationID, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return errors.New("通知不存在")
+		}
+		return err
+	}
+
+	if notification.ReadAt != nil {
+		return nil
+	}
+
+	return s.repos.Notifications.MarkRead(notification, time.Now())
+}
+
+// MarkAllRead 将用户全部通知标记为已读
+func (s *NotificationService) MarkAllRead(userID uint) error {
+	return s.repos.Notifications.MarkAllRead(userID, time.Now())
+}
+
+// GetSetting 获取用户通知设置，未设置时返回默认值
+func (s *NotificationService) GetSetting(userID uint) (*NotificationSettingResponse, error) {
+	setting, err := s.findSetting(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &NotificationSettingResponse{
+		NotificationSetting: *setting,
+		AvailableChannels:   s.availableChannels(),
+	}, nil
+}
+
+// UpdateSetting 更新用户通知设置
+func (s *NotificationService) UpdateSetting(userID uint, req *UpdateNotificationSettingRequest) (*NotificationSettingResponse, error) {
+	setting, err := s.findSetting(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Email != nil {
+		setting.Email = *req.Email
+	}
+	if req.Webhook != nil {
+		setting.Webhook = *req.Webhook
+	}
+	if req.WebhookURL != nil {
+		if *req.WebhookURL != "" {
+			if err := checkUserWebhookURL(*req.WebhookURL); err != nil {
+				return nil, err
+			}
+		}
+		setting.WebhookURL = *req.WebhookURL
+	}
+	if req.OneBot != nil {
+		setting.OneBot = *req.OneBot
+	}
+
+	if setting.Webhook && setting.WebhookURL == "" {
+		return nil, errors.New("开启Webhook通知需要设置Webhook地址")
+	}
+
+	if err := s.repos.Notifications.SaveSetting(setting); err != nil {
+		return nil, err
+	}
+
+	return &NotificationSettingResponse{
+		NotificationSetting: *setting,
+		AvailableChannels:   s.availableChannels(),
+	}, nil
+}
+
+// findSetting 获取用户通知设置，未设置时返回未保存的默认值
+func (s *NotificationService) findSetting(userID uint) (*model.NotificationSetting, error) {
+	setting, err := s.repos.Notifications.FindSetting(userID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return &model.NotificationSetting{UserID: userID}, nil
+	}
+	return setting, err
+}
+
+// checkUserWebhookURL 校验用户填写的Webhook地址，未允许内网地址时拒绝解析到内网地址的主机
+func checkUserWebhookURL(rawURL string) error {
+	if config.AppConfig.Notification.Webhook.AllowPrivateHosts {
+		return validateWebhookURL(rawURL)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	err := utils.CheckPublicURL(ctx, rawURL)
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, utils.ErrInvalidURL):
+		return errors.New("Webhook地址格式错误")
+	case errors.Is(err, utils.ErrInternalHost):
+		return errors.New("Webhook地址不能指向内网地址")
+	default:
+		return errors.New("无法解析Webhook地址的域名")
+	}
+}
+
+// availableChannels 获取系统已启用的外部渠道
+func (s *NotificationService) availableChannels() []string {
+	names := make([]string, 0, len(s.channels))
+	for _, channel := range s.channels {
+		names = append(names, channel.Name())
+	}
+	return names
+}
+
+// RunDeadlineReminder 定期检查即将截止的提交点并发送提醒，应在独立goroutine中运行
+func (s *NotificationService) RunDeadlineReminder(interval time.Duration) {
+	if config.AppConfig.Notification.ReminderHours <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.SendDeadlineReminders(); err != nil {
+			log.Printf("发送截止提醒失败: %v", err)
+		}
+		<-ticker.C
+	}
+}
+
+// SendDeadlineReminders 向已开始作答但尚未提交该提交点的用户发送截止提醒
+func (s *NotificationService) SendDeadlineReminders() error {
+	now := time.Now()
+	remindBefore := now.Add(time.Duration(config.AppConfig.Notification.ReminderHours) * time.Hour)
+
+	points, err := s.repos.Problems.ListReminderDuePoints(now, remindBefore)
+	if err != nil {
+		return err
+	}
+
+	for _, point := range points {
+		userIDs, err := s.repos.Submissions.UserIDsMissingPoint(point.ProblemID, point.ID)
+		if err != nil {
+			return err
+		}
+
+		title := "提交截止提醒"
+		content := fmt.Sprintf("题目《%s》的提交点「%s」将于 %s 截止，请尽快提交",
+			point.Problem.Title, point.Name, point.Deadline.Format("2006-01-02 15:04"))
+
+		// 通知与提醒标记同时提交，避免重复提醒或漏发
+		var notifications []model.Notification
+		err = s.repos.Transaction(func(tx *repository.Repositories) error {
+			var err error
+			notifications, err = s.createNotifications(tx.Notifications, userIDs, NotificationDeadlineReminder, title, content, point.ID)
+			if err != nil {
+				return err
+			}
+			return tx.Problems.UpdatePoint(&point, map[string]interface{}{"reminder_sent_at": now})
+		})
+		if err != nil {
+			return err
+		}
+
+		s.dispatch(userIDs, notifications)
+	}
+
+	return nil
+}
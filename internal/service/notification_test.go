@@ -0,0 +1,53 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/tksky1/glimgate/pkg/config"
+)
+
+func TestUpdateSettingWebhookURL(t *testing.T) {
+	env := newTestEnv(t)
+	user := env.createUser(t, "user", false)
+	enabled := true
+
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"ftp://example.com/hook", "Webhook地址格式错误"},
+		{"http://127.0.0.1:5700/send_private_msg", "Webhook地址不能指向内网地址"},
+		{"http://192.168.1.10/hook", "Webhook地址不能指向内网地址"},
+		{"http://[::1]/hook", "Webhook地址不能指向内网地址"},
+		{"http://localhost/hook", "Webhook地址不能指向内网地址"},
+		{"https://8.8.8.8/hook", ""},
+	}
+	for _, tt := range tests {
+		url := tt.url
+		_, err := env.notifications.UpdateSetting(user.ID, &UpdateNotificationSettingRequest{Webhook: &enabled, WebhookURL: &url})
+		if tt.want == "" {
+			if err != nil {
+				t.Errorf("%s: %v", tt.url, err)
+			}
+			continue
+		}
+		if err == nil || err.Error() != tt.want {
+			t.Errorf("%s应返回%q，得到%v", tt.url, tt.want, err)
+		}
+	}
+
+	setting, err := env.notifications.GetSetting(user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if setting.WebhookURL != "https://8.8.8.8/hook" {
+		t.Errorf("被拒绝的地址不应保存: %s", setting.WebhookURL)
+	}
+
+	// 内网部署可以允许内网地址
+	config.AppConfig.Notification.Webhook.AllowPrivateHosts = true
+	url := "http://192.168.1.10/hook"
+	if _, err := env.notifications.UpdateSetting(user.ID, &UpdateNotificationSettingRequest{WebhookURL: &url}); err != nil {
+		t.Errorf("允许内网地址时应保存成功: %v", err)
+	}
+}
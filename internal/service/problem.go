@@ -2,273 +2,536 @@ package service
 
 import (
 	"errors"
+	"time"
 
 	"github.com/tksky1/glimgate/internal/model"
-	"github.com/tksky1/glimgate/pkg/database"
-	"gorm.io/gorm"
+	"github.com/tksky1/glimgate/internal/repository"
+	"github.com/tksky1/glimgate/pkg/bundle"
+	"github.com/tksky1/glimgate/pkg/markdown"
+	"github.com/tksky1/glimgate/pkg/storage"
 )
 
 // ProblemService 题目服务
-type ProblemService struct{}
+type ProblemService struct {
+	repos               *repository.Repositories
+	store               storage.Storage
+	notificationService *NotificationService
+	auditService        *AuditService
+}
 
 // CreateProblemRequest 创建题目请求结构
 type CreateProblemRequest struct {
 	Title       string `json:"title" binding:"required" example:"实现一个简单的计算器"`
 	Description string `json:"description" binding:"required" example:"使用HTML、CSS、JavaScript实现一个基本的计算器功能"`
 	DirectionID uint   `json:"direction_id" binding:"required" example:"1"`
+	Slug        string `json:"slug" example:"calculator"`
+	// Difficulty 难度等级1-5，0表示未设置
+	Difficulty       int      `json:"difficulty" example:"3"`
+	EstimatedMinutes int      `json:"estimated_minutes" example:"120"`
+	Tags             []string `json:"tags" example:"算法,动态规划"`
+	// TeamSize 队伍人数上限2-20，0表示个人题目
+	TeamSize int `json:"team_size" example:"0"`
+	// Status 初始可见状态(draft/scheduled/published)，默认draft
+	Status    string     `json:"status" example:"draft"`
+	PublishAt *time.Time `json:"publish_at" example:"2024-09-01T09:00:00+08:00"`
 }
 
 // UpdateProblemRequest 更新题目请求结构
 type UpdateProblemRequest struct {
 	Title       string `json:"title" example:"实现一个简单的计算器"`
 	Description string `json:"description" example:"使用HTML、CSS、JavaScript实现一个基本的计算器功能"`
+	Slug        string `json:"slug" example:"calculator"`
+	// Difficulty、EstimatedMinutes为空时不修改，为0时清除
+	Difficulty       *int `json:"difficulty" example:"3"`
+	EstimatedMinutes *int `json:"estimated_minutes" example:"120"`
+	// Tags 为空时不修改，为空列表时清除全部标签
+	Tags *[]string `json:"tags" example:"算法,动态规划"`
+	// TeamSize 为空时不修改；已有提交后不能在个人题目和组队题目之间切换，改为0时解散全部队伍
+	TeamSize *int `json:"team_size" example:"3"`
+	// Significant 标题或题面有变化时标记为重要修改，通知已提交的选手
+	Significant bool   `json:"significant" example:"false"`
+	ChangeNote  string `json:"change_note" example:"修正了输入格式的说明"`
 }
 
 // CreateSubmissionPointRequest 创建提交点请求结构
 type CreateSubmissionPointRequest struct {
-	Name     string `json:"name" binding:"required" example:"源代码提交"`
-	MaxScore int    `json:"max_score" binding:"required,min=1" example:"100"`
+	Name     string     `json:"name" binding:"required" example:"源代码提交"`
+	MaxScore int        `json:"max_score" binding:"required,min=1" example:"100"`
+	Rubric   string     `json:"rubric" example:"功能完整60分，代码规范20分，文档20分"`
+	Deadline *time.Time `json:"deadline" example:"2024-10-01T23:59:59+08:00"`
+	// Type 提交点类型，manual人工评分（默认），auto自动评测，git代码仓库；自动评测的提交内容为源代码，代码仓库的提交内容为仓库地址
+	Type          string `json:"type" example:"auto"`
+	JudgeLanguage string `json:"judge_language" example:"python"`
+	// TimeLimitMS、MemoryLimitMB 为0时使用配置中的默认值
+	TimeLimitMS   int `json:"time_limit_ms" binding:"min=0" example:"1000"`
+	MemoryLimitMB int `json:"memory_limit_mb" binding:"min=0" example:"256"`
 }
 
 // UpdateSubmissionPointRequest 更新提交点请求结构
 type UpdateSubmissionPointRequest struct {
-	Name     string `json:"name" example:"源代码提交"`
-	MaxScore int    `json:"max_score" binding:"min=1" example:"100"`
+	Name     string     `json:"name" example:"源代码提交"`
+	MaxScore int        `json:"max_score" binding:"min=1" example:"100"`
+	Rubric   string     `json:"rubric" example:"功能完整60分，代码规范20分，文档20分"`
+	Deadline *time.Time `json:"deadline" example:"2024-10-01T23:59:59+08:00"`
+	// Type、JudgeLanguage 为空时不修改；TimeLimitMS、MemoryLimitMB为空时不修改，为0时使用配置中的默认值
+	Type          string `json:"type" example:"auto"`
+	JudgeLanguage string `json:"judge_language" example:"python"`
+	TimeLimitMS   *int   `json:"time_limit_ms" binding:"omitempty,min=0" example:"1000"`
+	MemoryLimitMB *int   `json:"memory_limit_mb" binding:"omitempty,min=0" example:"256"`
+	// Significant 设置有变化时标记为重要修改，通知已提交的选手
+	Significant bool   `json:"significant" example:"false"`
+	ChangeNote  string `json:"change_note" example:"截止时间延后一周"`
 }
 
 // NewProblemService 创建题目服务实例
-func NewProblemService() *ProblemService {
-	return &ProblemService{}
+func NewProblemService(repos *repository.Repositories, store storage.Storage, notificationService *NotificationService, auditService *AuditService) *ProblemService {
+	return &ProblemService{
+		repos:               repos,
+		store:               store,
+		notificationService: notificationService,
+		auditService:        auditService,
+	}
 }
 
-// CreateProblem 创建题目
-func (s *ProblemService) CreateProblem(req *CreateProblemRequest) (*model.Problem, error) {
-	db := database.GetDB()
-
-	// 检查方向是否存在
-	var direction model.Direction
-	if err := db.First(&direction, req.DirectionID).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("方向不存在")
-		}
+// CreateProblem 创建题目，未指定状态时创建为草稿，发布前选手不可见；题面为Markdown，包含不安全的HTML时拒绝
+func (s *ProblemService) CreateProblem(op *Operator, req *CreateProblemRequest) (*model.Problem, error) {
+	if err := markdown.Check(req.Description); err != nil {
 		return nil, err
 	}
-
-	// 创建题目
-	problem := model.Problem{
-		Title:       req.Title,
-		Description: req.Description,
-		DirectionID: req.DirectionID,
+	if err := checkProblemMeta(req.Difficulty, req.EstimatedMinutes); err != nil {
+		return nil, err
 	}
-
-	if err := db.Create(&problem).Error; err != nil {
+	if err := checkTeamSize(req.TeamSize); err != nil {
 		return nil, err
 	}
+	tags, err := normalizeProblemTags(req.Tags)
+	if err != nil {
+		return nil, err
+	}
+	problem := &model.Problem{
+		Title:            req.Title,
+		Description:      req.Description,
+		DirectionID:      req.DirectionID,
+		Slug:             req.Slug,
+		Difficulty:       req.Difficulty,
+		EstimatedMinutes: req.EstimatedMinutes,
+		TeamSize:         req.TeamSize,
+		Status:           ProblemStatusDraft,
+	}
+	if req.Status != "" && req.Status != ProblemStatusDraft {
+		if req.Status == ProblemStatusArchived {
+			return nil, errors.New("只有已发布的题目可以归档")
+		}
+		updates, err := problemStatusFields(problem, req.Status, req.PublishAt, time.Now())
+		if err != nil {
+			return nil, err
+		}
+		problem.Status = req.Status
+		if publishAt, ok := updates["publish_at"].(time.Time); ok {
+			problem.PublishAt = &publishAt
+		}
+		if publishedAt, ok := updates["published_at"].(time.Time); ok {
+			problem.PublishedAt = &publishedAt
+		}
+	}
+
+	err = s.repos.Transaction(func(tx *repository.Repositories) error {
+		// 检查方向是否存在
+		if _, err := tx.Directions.FindByID(req.DirectionID); err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return errors.New("方向不存在")
+			}
+			return err
+		}
+
+		if req.Slug != "" {
+			if err := checkProblemSlug(tx, req.DirectionID, req.Slug, 0); err != nil {
+				return err
+			}
+		}
+
+		// 创建题目
+		if err := tx.Problems.Create(problem); err != nil {
+			return err
+		}
+		if err := tx.Problems.ReplaceTags(problem.ID, tags); err != nil {
+			return err
+		}
+
+		if _, err := recordRevision(tx, op, problem.ID, false, ""); err != nil {
+			return err
+		}
 
-	// 加载关联数据
-	if err := db.Preload("Direction").First(&problem, problem.ID).Error; err != nil {
+		// 加载关联数据
+		var err error
+		problem, err = tx.Problems.FindByID(problem.ID, "Direction", "Tags")
+		if err != nil {
+			return err
+		}
+
+		return s.auditService.Record(tx.AuditLogs, op, AuditActionCreate, AuditEntityProblem, problem.ID, nil, problem)
+	})
+	if err != nil {
 		return nil, err
 	}
 
-	return &problem, nil
+	if problem.Status == ProblemStatusScheduled {
+		wakePublishScheduler()
+	}
+	renderProblem(problem)
+	return problem, nil
 }
 
-// GetProblems 获取题目列表
-func (s *ProblemService) GetProblems(directionID uint) ([]model.Problem, error) {
-	db := database.GetDB()
-
-	var problems []model.Problem
-	query := db.Preload("Direction").Preload("SubmissionPoints")
+// checkProblemSlug 检查题目标识的格式及在方向内是否已被其他题目使用
+// 标识只能包含小写字母、数字、-和_，以字母或数字开头，不超过100个字符
+func checkProblemSlug(tx *repository.Repositories, directionID uint, slug string, problemID uint) error {
+	if !bundle.ValidSlug(slug) {
+		return errors.New("题目标识格式错误")
+	}
+	existing, err := tx.Problems.FindBySlug(directionID, slug)
+	if err == nil && existing.ID != problemID {
+		return errors.New("题目标识已被使用")
+	}
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return err
+	}
+	return nil
+}
 
-	if directionID > 0 {
-		query = query.Where("direction_id = ?", directionID)
+// GetProblems 获取题目列表，包含所有可见状态，status不为空时只返回该状态的题目
+func (s *ProblemService) GetProblems(directionID uint, status string) ([]model.Problem, error) {
+	var statuses []string
+	if status != "" {
+		if !ValidProblemStatus(status) {
+			return nil, errors.New("题目状态无效")
+		}
+		statuses = append(statuses, status)
 	}
 
-	if err := query.Find(&problems).Error; err != nil {
+	problems, err := s.repos.Problems.List(directionID, statuses...)
+	if err != nil {
 		return nil, err
 	}
-
+	renderProblems(problems)
 	return problems, nil
 }
 
-// GetProblemByID 根据ID获取题目
+// GetProblemByID 根据ID获取题目，不检查可见状态，选手侧使用GetVisibleProblem
 func (s *ProblemService) GetProblemByID(problemID uint) (*model.Problem, error) {
-	db := database.GetDB()
-
-	var problem model.Problem
-	if err := db.Preload("Direction").Preload("SubmissionPoints").First(&problem, problemID).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
+	problem, err := s.repos.Problems.FindByID(problemID, "Direction", "SubmissionPoints", "Attachments", "Tags", "Prerequisites")
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
 			return nil, errors.New("题目不存在")
 		}
 		return nil, err
 	}
 
-	return &problem, nil
+	renderProblem(problem)
+	return problem, nil
 }
 
 // UpdateProblem 更新题目
-func (s *ProblemService) UpdateProblem(problemID uint, req *UpdateProblemRequest) (*model.Problem, error) {
-	db := database.GetDB()
-
-	var problem model.Problem
-	if err := db.First(&problem, problemID).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("题目不存在")
-		}
+func (s *ProblemService) UpdateProblem(op *Operator, problemID uint, req *UpdateProblemRequest) (*model.Problem, error) {
+	if err := checkRevisionNote(req.ChangeNote); err != nil {
 		return nil, err
 	}
 
-	// 更新字段
-	updates := make(map[string]interface{})
-	if req.Title != "" {
-		updates["title"] = req.Title
-	}
-	if req.Description != "" {
-		updates["description"] = req.Description
-	}
+	var after *model.Problem
+	var notice *revisionNotice
+	err := s.repos.Transaction(func(tx *repository.Repositories) error {
+		problem, err := tx.Problems.FindByID(problemID, "Tags")
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return errors.New("题目不存在")
+			}
+			return err
+		}
+		before := *problem
 
-	if len(updates) > 0 {
-		if err := db.Model(&problem).Updates(updates).Error; err != nil {
-			return nil, err
+		// 更新字段
+		updates := make(map[string]interface{})
+		if req.Title != "" {
+			updates["title"] = req.Title
+		}
+		if req.Description != "" {
+			if err := markdown.Check(req.Description); err != nil {
+				return err
+			}
+			updates["description"] = req.Description
+		}
+		if req.Slug != "" && req.Slug != problem.Slug {
+			if err := checkProblemSlug(tx, problem.DirectionID, req.Slug, problem.ID); err != nil {
+				return err
+			}
+			updates["slug"] = req.Slug
+		}
+		difficulty, estimatedMinutes := problem.Difficulty, problem.EstimatedMinutes
+		if req.Difficulty != nil {
+			difficulty = *req.Difficulty
+			updates["difficulty"] = difficulty
+		}
+		if req.EstimatedMinutes != nil {
+			estimatedMinutes = *req.EstimatedMinutes
+			updates["estimated_minutes"] = estimatedMinutes
+		}
+		if err := checkProblemMeta(difficulty, estimatedMinutes); err != nil {
+			return err
+		}
+		if req.TeamSize != nil && *req.TeamSize != problem.TeamSize {
+			if err := checkTeamSize(*req.TeamSize); err != nil {
+				return err
+			}
+			if err := checkTeamSizeChange(tx, problem, *req.TeamSize); err != nil {
+				return err
+			}
+			if *req.TeamSize == 0 {
+				if err := tx.Teams.DeleteByProblem(problem.ID); err != nil {
+					return err
+				}
+			}
+			updates["team_size"] = *req.TeamSize
 		}
-	}
 
-	// 重新加载包含关联数据的题目
-	if err := db.Preload("Direction").Preload("SubmissionPoints").First(&problem, problem.ID).Error; err != nil {
+		if len(updates) > 0 {
+			if err := tx.Problems.Update(problem, updates); err != nil {
+				return err
+			}
+		}
+		if req.Tags != nil {
+			tags, err := normalizeProblemTags(*req.Tags)
+			if err != nil {
+				return err
+			}
+			if err := tx.Problems.ReplaceTags(problem.ID, tags); err != nil {
+				return err
+			}
+		}
+		if notice, err = saveRevision(tx, op, problem.ID, req.Significant, req.ChangeNote); err != nil {
+			return err
+		}
+
+		// 重新加载包含关联数据的题目
+		after, err = tx.Problems.FindByID(problem.ID, "Direction", "SubmissionPoints", "Tags")
+		if err != nil {
+			return err
+		}
+
+		return s.auditService.Record(tx.AuditLogs, op, AuditActionUpdate, AuditEntityProblem, problem.ID, before, after)
+	})
+	if err != nil {
 		return nil, err
 	}
 
-	return &problem, nil
+	s.notifyRevision(notice)
+	renderProblem(after)
+	return after, nil
 }
 
 // DeleteProblem 删除题目
-func (s *ProblemService) DeleteProblem(problemID uint) error {
-	db := database.GetDB()
+func (s *ProblemService) DeleteProblem(op *Operator, problemID uint) error {
+	return s.repos.Transaction(func(tx *repository.Repositories) error {
+		problem, err := tx.Problems.FindByID(problemID)
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return errors.New("题目不存在")
+			}
+			return err
+		}
 
-	var problem model.Problem
-	if err := db.First(&problem, problemID).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return errors.New("题目不存在")
+		// 检查是否有提交记录
+		submissionCount, err := tx.Submissions.CountByProblem(problemID)
+		if err != nil {
+			return err
+		}
+		if submissionCount > 0 {
+			return errors.New("该题目已有提交记录，无法删除")
 		}
-		return err
-	}
 
-	// 检查是否有提交记录
-	var submissionCount int64
-	if err := db.Model(&model.Submission{}).Where("problem_id = ?", problemID).Count(&submissionCount).Error; err != nil {
-		return err
+		// 题目与提交点、附件一并删除，恢复时一并找回
+		if err := tx.Problems.Delete(problem); err != nil {
+			return err
+		}
+
+		return s.auditService.Record(tx.AuditLogs, op, AuditActionDelete, AuditEntityProblem, problem.ID, problem, nil)
+	})
+}
+
+// CreateSubmissionPoint 创建提交点
+func (s *ProblemService) CreateSubmissionPoint(op *Operator, problemID uint, req *CreateSubmissionPointRequest) (*model.SubmissionPoint, error) {
+	if req.Type == "" {
+		req.Type = SubmissionPointManual
 	}
-	if submissionCount > 0 {
-		return errors.New("该题目已有提交记录，无法删除")
+	if err := checkJudgeSettings(req.Type, req.JudgeLanguage); err != nil {
+		return nil, err
 	}
-
-	// 删除关联的提交点
-	if err := db.Where("problem_id = ?", problemID).Delete(&model.SubmissionPoint{}).Error; err != nil {
-		return err
+	submissionPoint := &model.SubmissionPoint{
+		Name:          req.Name,
+		MaxScore:      req.MaxScore,
+		ProblemID:     problemID,
+		Rubric:        req.Rubric,
+		Deadline:      req.Deadline,
+		Type:          req.Type,
+		JudgeLanguage: req.JudgeLanguage,
+		TimeLimitMS:   req.TimeLimitMS,
+		MemoryLimitMB: req.MemoryLimitMB,
 	}
 
-	return db.Delete(&problem).Error
-}
+	err := s.repos.Transaction(func(tx *repository.Repositories) error {
+		// 检查题目是否存在
+		if _, err := tx.Problems.FindByID(problemID); err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return errors.New("题目不存在")
+			}
+			return err
+		}
 
-// CreateSubmissionPoint 创建提交点
-func (s *ProblemService) CreateSubmissionPoint(problemID uint, req *CreateSubmissionPointRequest) (*model.SubmissionPoint, error) {
-	db := database.GetDB()
+		// 创建提交点
+		if err := tx.Problems.CreatePoint(submissionPoint); err != nil {
+			return err
+		}
+		if _, err := recordRevision(tx, op, problemID, false, ""); err != nil {
+			return err
+		}
 
-	// 检查题目是否存在
-	var problem model.Problem
-	if err := db.First(&problem, problemID).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("题目不存在")
+		// 加载关联数据
+		var err error
+		submissionPoint, err = tx.Problems.FindPointByID(submissionPoint.ID, "Problem")
+		if err != nil {
+			return err
 		}
+
+		return s.auditService.Record(tx.AuditLogs, op, AuditActionCreate, AuditEntitySubmissionPoint, submissionPoint.ID, nil, submissionPoint)
+	})
+	if err != nil {
 		return nil, err
 	}
 
-	// 创建提交点
-	submissionPoint := model.SubmissionPoint{
-		Name:      req.Name,
-		MaxScore:  req.MaxScore,
-		ProblemID: problemID,
-	}
+	return submissionPoint, nil
+}
 
-	if err := db.Create(&submissionPoint).Error; err != nil {
+// GetSubmissionPoints 获取提交点列表，题目不可见时视为不存在，userID未解锁题目时返回错误
+func (s *ProblemService) GetSubmissionPoints(problemID, userID uint) ([]model.SubmissionPoint, error) {
+	problem, err := findVisibleProblem(s.repos, problemID)
+	if err != nil {
 		return nil, err
 	}
-
-	// 加载关联数据
-	if err := db.Preload("Problem").First(&submissionPoint, submissionPoint.ID).Error; err != nil {
+	if err := checkProblemUnlocked(s.repos, userID, problem); err != nil {
 		return nil, err
 	}
-
-	return &submissionPoint, nil
+	return s.repos.Problems.ListPoints(problemID)
 }
 
-// GetSubmissionPoints 获取提交点列表
-func (s *ProblemService) GetSubmissionPoints(problemID uint) ([]model.SubmissionPoint, error) {
-	db := database.GetDB()
-
-	var submissionPoints []model.SubmissionPoint
-	if err := db.Preload("Problem").Where("problem_id = ?", problemID).Find(&submissionPoints).Error; err != nil {
+// UpdateSubmissionPoint 更新提交点
+func (s *ProblemService) UpdateSubmissionPoint(op *Operator, submissionPointID uint, req *UpdateSubmissionPointRequest) (*model.SubmissionPoint, error) {
+	if err := checkRevisionNote(req.ChangeNote); err != nil {
 		return nil, err
 	}
 
-	return submissionPoints, nil
-}
+	var after *model.SubmissionPoint
+	var notice *revisionNotice
+	err := s.repos.Transaction(func(tx *repository.Repositories) error {
+		submissionPoint, err := tx.Problems.FindPointByID(submissionPointID)
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return errors.New("提交点不存在")
+			}
+			return err
+		}
+		before := *submissionPoint
 
-// UpdateSubmissionPoint 更新提交点
-func (s *ProblemService) UpdateSubmissionPoint(submissionPointID uint, req *UpdateSubmissionPointRequest) (*model.SubmissionPoint, error) {
-	db := database.GetDB()
+		pointType, language := submissionPoint.Type, submissionPoint.JudgeLanguage
+		if req.Type != "" {
+			pointType = req.Type
+		}
+		if req.JudgeLanguage != "" {
+			language = req.JudgeLanguage
+		}
+		if err := checkJudgeSettings(pointType, language); err != nil {
+			return err
+		}
 
-	var submissionPoint model.SubmissionPoint
-	if err := db.First(&submissionPoint, submissionPointID).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("提交点不存在")
+		// 更新字段
+		updates := make(map[string]interface{})
+		if req.Name != "" {
+			updates["name"] = req.Name
+		}
+		if req.MaxScore > 0 {
+			updates["max_score"] = req.MaxScore
+		}
+		if req.Rubric != "" {
+			updates["rubric"] = req.Rubric
+		}
+		if req.Deadline != nil {
+			// 截止时间变更后重新发送提醒
+			updates["deadline"] = *req.Deadline
+			updates["reminder_sent_at"] = nil
+		}
+		if req.Type != "" {
+			updates["type"] = req.Type
+		}
+		if req.JudgeLanguage != "" {
+			updates["judge_language"] = req.JudgeLanguage
+		}
+		if req.TimeLimitMS != nil {
+			updates["time_limit_ms"] = *req.TimeLimitMS
+		}
+		if req.MemoryLimitMB != nil {
+			updates["memory_limit_mb"] = *req.MemoryLimitMB
 		}
-		return nil, err
-	}
 
-	// 更新字段
-	updates := make(map[string]interface{})
-	if req.Name != "" {
-		updates["name"] = req.Name
-	}
-	if req.MaxScore > 0 {
-		updates["max_score"] = req.MaxScore
-	}
+		if len(updates) > 0 {
+			if err := tx.Problems.UpdatePoint(submissionPoint, updates); err != nil {
+				return err
+			}
+		}
+		if notice, err = saveRevision(tx, op, submissionPoint.ProblemID, req.Significant, req.ChangeNote); err != nil {
+			return err
+		}
 
-	if len(updates) > 0 {
-		if err := db.Model(&submissionPoint).Updates(updates).Error; err != nil {
-			return nil, err
+		// 重新加载包含关联数据的提交点
+		after, err = tx.Problems.FindPointByID(submissionPoint.ID, "Problem")
+		if err != nil {
+			return err
 		}
-	}
 
-	// 重新加载包含关联数据的提交点
-	if err := db.Preload("Problem").First(&submissionPoint, submissionPoint.ID).Error; err != nil {
+		return s.auditService.Record(tx.AuditLogs, op, AuditActionUpdate, AuditEntitySubmissionPoint, submissionPoint.ID, before, after)
+	})
+	if err != nil {
 		return nil, err
 	}
 
-	return &submissionPoint, nil
+	s.notifyRevision(notice)
+
+	return after, nil
 }
 
 // DeleteSubmissionPoint 删除提交点
-func (s *ProblemService) DeleteSubmissionPoint(submissionPointID uint) error {
-	db := database.GetDB()
+func (s *ProblemService) DeleteSubmissionPoint(op *Operator, submissionPointID uint) error {
+	return s.repos.Transaction(func(tx *repository.Repositories) error {
+		submissionPoint, err := tx.Problems.FindPointByID(submissionPointID)
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return errors.New("提交点不存在")
+			}
+			return err
+		}
 
-	var submissionPoint model.SubmissionPoint
-	if err := db.First(&submissionPoint, submissionPointID).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return errors.New("提交点不存在")
+		// 检查是否有提交记录
+		submissionCount, err := tx.Submissions.CountByPoint(submissionPointID)
+		if err != nil {
+			return err
+		}
+		if submissionCount > 0 {
+			return errors.New("该提交点已有提交记录，无法删除")
 		}
-		return err
-	}
 
-	// 检查是否有提交记录
-	var submissionCount int64
-	if err := db.Model(&model.Submission{}).Where("submission_point_id = ?", submissionPointID).Count(&submissionCount).Error; err != nil {
-		return err
-	}
-	if submissionCount > 0 {
-		return errors.New("该提交点已有提交记录，无法删除")
-	}
+		if err := tx.Problems.DeletePoint(submissionPoint); err != nil {
+			return err
+		}
+		if _, err := recordRevision(tx, op, submissionPoint.ProblemID, false, ""); err != nil {
+			return err
+		}
 
-	return db.Delete(&submissionPoint).Error
-}
\ No newline at end of file
+		return s.auditService.Record(tx.AuditLogs, op, AuditActionDelete, AuditEntitySubmissionPoint, submissionPoint.ID, submissionPoint, nil)
+	})
+}
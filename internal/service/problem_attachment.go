@@ -0,0 +1,332 @@
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/tksky1/glimgate/internal/model"
+	"github.com/tksky1/glimgate/internal/repository"
+	"github.com/tksky1/glimgate/pkg/config"
+	"github.com/tksky1/glimgate/pkg/storage"
+	"github.com/tksky1/glimgate/pkg/utils"
+)
+
+// ErrAttachmentTooLarge 附件超过大小限制
+var ErrAttachmentTooLarge = errors.New("附件超过大小限制")
+
+// 签名下载链接的用途，包含在签名中
+const (
+	AttachmentScopeDownload = "download" // 下载可见题目的附件
+	AttachmentScopePreview  = "preview"  // 管理员在发布前预览附件，所属题目未发布时也可下载
+)
+
+// SignedURL 带签名的附件下载链接，持有链接即可下载，无需登录
+type SignedURL struct {
+	URL       string    `json:"url" example:"/api/attachments/1/download?expires=1727798399&signature=5d41402abc4b2a76b9719d911017c592"`
+	ExpiresAt time.Time `json:"expires_at" example:"2024-10-01T23:59:59+08:00"`
+}
+
+// UploadAttachment 上传题目附件，文件写入存储时计算SHA-256，同一题目下文件名不能重复
+func (s *ProblemService) UploadAttachment(op *Operator, problemID uint, filename, contentType string, r io.Reader) (*model.ProblemAttachment, error) {
+	filename, err := cleanAttachmentName(filename)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.repos.Problems.FindByID(problemID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, errors.New("题目不存在")
+		}
+		return nil, err
+	}
+	if err := checkAttachmentName(s.repos, problemID, filename); err != nil {
+		return nil, err
+	}
+
+	attachment, err := s.storeAttachment(problemID, filename, contentType, r)
+	if err != nil {
+		return nil, err
+	}
+	if op != nil && op.UserID > 0 {
+		attachment.UploaderID = &op.UserID
+	}
+
+	err = s.repos.Transaction(func(tx *repository.Repositories) error {
+		if err := checkAttachmentName(tx, problemID, filename); err != nil {
+			return err
+		}
+		if err := tx.Problems.CreateAttachment(attachment); err != nil {
+			return err
+		}
+		return s.auditService.Record(tx.AuditLogs, op, AuditActionCreate, AuditEntityProblemAttachment, attachment.ID, nil, attachment)
+	})
+	if err != nil {
+		s.removeStoredFile(attachment.StorageKey)
+		return nil, err
+	}
+
+	return attachment, nil
+}
+
+// storeAttachment 将文件写入存储并返回待保存的附件记录，超过大小限制时删除已写入的文件
+func (s *ProblemService) storeAttachment(problemID uint, filename, contentType string, r io.Reader) (*model.ProblemAttachment, error) {
+	suffix, err := utils.RandomHex(16)
+	if err != nil {
+		return nil, err
+	}
+	key := fmt.Sprintf("problems/%d/%s", problemID, suffix)
+
+	maxSize := int64(attachmentConfig().MaxUploadMB) << 20
+	hash := sha256.New()
+	counter := &countingWriter{}
+	limited := io.LimitReader(r, maxSize+1)
+	if err := s.store.Put(key, io.TeeReader(limited, io.MultiWriter(hash, counter))); err != nil {
+		return nil, err
+	}
+	if counter.n > maxSize {
+		s.removeStoredFile(key)
+		return nil, fmt.Errorf("%w(%dMB)", ErrAttachmentTooLarge, attachmentConfig().MaxUploadMB)
+	}
+
+	return &model.ProblemAttachment{
+		ProblemID:   problemID,
+		Filename:    filename,
+		ContentType: attachmentContentType(filename, contentType),
+		Size:        counter.n,
+		SHA256:      hex.EncodeToString(hash.Sum(nil)),
+		StorageKey:  key,
+	}, nil
+}
+
+// GetAttachments 获取题目的附件列表，题目不可见时视为不存在，userID未解锁题目时返回错误
+func (s *ProblemService) GetAttachments(problemID, userID uint) ([]model.ProblemAttachment, error) {
+	problem, err := findVisibleProblem(s.repos, problemID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkProblemUnlocked(s.repos, userID, problem); err != nil {
+		return nil, err
+	}
+	return s.repos.Problems.ListAttachments(problemID)
+}
+
+// DeleteAttachment 删除附件，文件保留在存储中，可从回收站恢复，回收站清理时一并删除文件
+func (s *ProblemService) DeleteAttachment(op *Operator, attachmentID uint) error {
+	return s.repos.Transaction(func(tx *repository.Repositories) error {
+		attachment, err := tx.Problems.FindAttachmentByID(attachmentID)
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return errors.New("附件不存在")
+			}
+			return err
+		}
+
+		if err := tx.Problems.DeleteAttachment(attachment); err != nil {
+			return err
+		}
+
+		return s.auditService.Record(tx.AuditLogs, op, AuditActionDelete, AuditEntityProblemAttachment, attachment.ID, attachment, nil)
+	})
+}
+
+// OpenAttachment 打开附件供下载，调用方负责关闭返回的文件
+// 题目不可见时附件同样不可下载，userID未解锁题目时返回错误；
+// scope为通过校验的签名链接的用途，为空表示未携带签名，签名链接生成时已检查过解锁条件，下载时不再检查
+func (s *ProblemService) OpenAttachment(attachmentID, userID uint, scope string) (*model.ProblemAttachment, storage.File, error) {
+	attachment, err := s.findVisibleAttachment(attachmentID, scope == AttachmentScopePreview)
+	if err != nil {
+		return nil, nil, err
+	}
+	if scope == "" {
+		if err := checkProblemUnlocked(s.repos, userID, attachment.Problem); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return s.openStoredAttachment(attachment)
+}
+
+// openStoredAttachment 从存储中打开附件文件
+func (s *ProblemService) openStoredAttachment(attachment *model.ProblemAttachment) (*model.ProblemAttachment, storage.File, error) {
+	file, err := s.store.Open(attachment.StorageKey)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotExist) {
+			log.Printf("附件 %d 的文件 %s 不存在", attachment.ID, attachment.StorageKey)
+			return nil, nil, errors.New("附件文件不存在")
+		}
+		return nil, nil, err
+	}
+	return attachment, file, nil
+}
+
+// OpenAttachmentByName 按文件名打开可见题目的附件，供题面中的相对链接使用，调用方负责关闭返回的文件
+// userID未解锁题目时返回错误
+func (s *ProblemService) OpenAttachmentByName(problemID, userID uint, filename string) (*model.ProblemAttachment, storage.File, error) {
+	problem, err := findVisibleProblem(s.repos, problemID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := checkProblemUnlocked(s.repos, userID, problem); err != nil {
+		return nil, nil, err
+	}
+	attachment, err := s.repos.Problems.FindAttachmentByName(problemID, filename)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, nil, errors.New("附件不存在")
+		}
+		return nil, nil, err
+	}
+	return s.openStoredAttachment(attachment)
+}
+
+// findVisibleAttachment 查找可下载的附件，所属题目已删除或未发布时视为附件不存在
+// preview为true时允许访问未发布题目的附件，题目已删除时仍视为不存在
+func (s *ProblemService) findVisibleAttachment(attachmentID uint, preview bool) (*model.ProblemAttachment, error) {
+	attachment, err := s.repos.Problems.FindAttachmentByID(attachmentID, "Problem")
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, errors.New("附件不存在")
+		}
+		return nil, err
+	}
+	if attachment.Problem == nil || (!preview && !problemVisible(attachment.Problem)) {
+		return nil, errors.New("附件不存在")
+	}
+	return attachment, nil
+}
+
+// SignAttachmentURL 生成附件的签名下载链接，有效期不超过配置的上限，expiresIn不大于0时使用上限
+// userID未解锁题目时返回错误；preview为true时可为未发布或未解锁题目的附件生成链接，供管理员发布前预览，
+// 只有题目未发布时生成预览用途的链接，其余链接在题目下线或删除后失效
+func (s *ProblemService) SignAttachmentURL(attachmentID, userID uint, expiresIn time.Duration, preview bool) (*SignedURL, error) {
+	attachment, err := s.findVisibleAttachment(attachmentID, preview)
+	if err != nil {
+		return nil, err
+	}
+	if !preview {
+		if err := checkProblemUnlocked(s.repos, userID, attachment.Problem); err != nil {
+			return nil, err
+		}
+	}
+
+	maxTTL := time.Duration(attachmentConfig().SignedURLTTLMinutes) * time.Minute
+	if expiresIn <= 0 || expiresIn > maxTTL {
+		expiresIn = maxTTL
+	}
+	expiresAt := time.Now().Add(expiresIn).Truncate(time.Second)
+	expires := expiresAt.Unix()
+
+	url := fmt.Sprintf("/api/attachments/%d/download?expires=%d", attachmentID, expires)
+	scope := AttachmentScopeDownload
+	if preview && !problemVisible(attachment.Problem) {
+		scope = AttachmentScopePreview
+		url += "&scope=" + scope
+	}
+	return &SignedURL{
+		URL:       url + "&signature=" + attachmentSignature(attachmentID, expires, scope),
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+// VerifyAttachmentSignature 校验签名下载链接的过期时间和签名，返回链接的用途，scope为空时为下载
+func (s *ProblemService) VerifyAttachmentSignature(attachmentID uint, expires int64, scope, signature string) (string, error) {
+	if time.Now().Unix() > expires {
+		return "", errors.New("下载链接已过期")
+	}
+	if scope == "" {
+		scope = AttachmentScopeDownload
+	}
+	if scope != AttachmentScopeDownload && scope != AttachmentScopePreview {
+		return "", errors.New("下载链接签名无效")
+	}
+	expected := attachmentSignature(attachmentID, expires, scope)
+	if !hmac.Equal([]byte(expected), []byte(strings.ToLower(signature))) {
+		return "", errors.New("下载链接签名无效")
+	}
+	return scope, nil
+}
+
+// removeStoredFile 删除存储中的文件，失败时只记录日志
+func (s *ProblemService) removeStoredFile(key string) {
+	if err := s.store.Delete(key); err != nil {
+		log.Printf("删除附件文件 %s 失败: %v", key, err)
+	}
+}
+
+// checkAttachmentName 检查题目下是否已有同名附件
+func checkAttachmentName(tx *repository.Repositories, problemID uint, filename string) error {
+	_, err := tx.Problems.FindAttachmentByName(problemID, filename)
+	if err == nil {
+		return errors.New("该题目已有同名附件")
+	}
+	if !errors.Is(err, repository.ErrNotFound) {
+		return err
+	}
+	return nil
+}
+
+// cleanAttachmentName 去掉文件名中的路径部分并检查长度
+func cleanAttachmentName(filename string) (string, error) {
+	filename = strings.TrimSpace(path.Base(strings.ReplaceAll(filename, "\\", "/")))
+	if filename == "" || filename == "." || filename == "/" || filename == ".." {
+		return "", errors.New("附件文件名无效")
+	}
+	if utf8.RuneCountInString(filename) > 255 {
+		return "", errors.New("附件文件名不能超过255个字符")
+	}
+	return filename, nil
+}
+
+// attachmentContentType 优先使用上传时声明的类型，未声明时按扩展名推断
+func attachmentContentType(filename, contentType string) string {
+	if contentType != "" && contentType != "application/octet-stream" && len(contentType) <= 100 {
+		return contentType
+	}
+	if byExt := mime.TypeByExtension(filepath.Ext(filename)); byExt != "" {
+		return byExt
+	}
+	return "application/octet-stream"
+}
+
+// attachmentSignature 计算附件下载链接的签名，签名包含链接的用途，下载链接不能改为预览使用
+func attachmentSignature(attachmentID uint, expires int64, scope string) string {
+	key := attachmentConfig().SigningKey
+	if key == "" {
+		key = config.AppConfig.JWT.Secret
+	}
+	data := "attachment:" + scope + ":" + strconv.FormatUint(uint64(attachmentID), 10) + ":" + strconv.FormatInt(expires, 10)
+	return utils.HMACSHA256([]byte(key), []byte(data))
+}
+
+// attachmentConfig 获取附件存储配置并填充默认值
+func attachmentConfig() config.StorageConfig {
+	cfg := config.AppConfig.Storage
+	if cfg.MaxUploadMB <= 0 {
+		cfg.MaxUploadMB = 50
+	}
+	if cfg.SignedURLTTLMinutes <= 0 {
+		cfg.SignedURLTTLMinutes = 60
+	}
+	return cfg
+}
+
+// countingWriter 统计写入的字节数
+type countingWriter struct {
+	n int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.n += int64(len(p))
+	return len(p), nil
+}
@@ -0,0 +1,105 @@
+package service
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/tksky1/glimgate/internal/model"
+	"github.com/tksky1/glimgate/pkg/storage"
+)
+
+// openSigned 按签名链接中的参数校验并打开附件，与下载接口的处理相同
+func openSigned(problems *ProblemService, attachmentID uint, signed *SignedURL) error {
+	u, err := url.Parse(signed.URL)
+	if err != nil {
+		return err
+	}
+	query := u.Query()
+	expires, err := strconv.ParseInt(query.Get("expires"), 10, 64)
+	if err != nil {
+		return err
+	}
+	scope, err := problems.VerifyAttachmentSignature(attachmentID, expires, query.Get("scope"), query.Get("signature"))
+	if err != nil {
+		return err
+	}
+	_, file, err := problems.OpenAttachment(attachmentID, 0, scope)
+	if err == nil {
+		file.Close()
+	}
+	return err
+}
+
+// TestSignedAttachmentScope 只有预览链接能下载未发布题目的附件，题目下线或删除后下载链接失效
+func TestSignedAttachmentScope(t *testing.T) {
+	env := newTestEnv(t)
+	store, err := storage.NewLocal(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	problems := NewProblemService(env.repos, store, env.notifications, env.audit)
+	user := env.createUser(t, "user", false)
+	problem, _ := env.createProblem(t, "附件题")
+	attachment, err := problems.UploadAttachment(env.op, problem.ID, "data.txt", "text/plain", strings.NewReader("数据"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	setStatus := func(status string) {
+		t.Helper()
+		if err := env.db.Model(&problem).Update("status", status).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// 已发布题目的链接，包括管理员生成的，都是下载用途，题目下线后失效
+	download, err := problems.SignAttachmentURL(attachment.ID, user.ID, 0, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	adminDownload, err := problems.SignAttachmentURL(attachment.ID, env.admin.ID, 0, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(adminDownload.URL, "scope=") {
+		t.Errorf("已发布题目的链接不应是预览用途: %s", adminDownload.URL)
+	}
+	if err := openSigned(problems, attachment.ID, download); err != nil {
+		t.Errorf("下载链接应可下载: %v", err)
+	}
+	setStatus(ProblemStatusDraft)
+	for _, signed := range []*SignedURL{download, adminDownload} {
+		if err := openSigned(problems, attachment.ID, signed); err == nil || err.Error() != "附件不存在" {
+			t.Errorf("题目下线后下载链接应失效，得到%v", err)
+		}
+	}
+	// 改成预览用途会使签名失效
+	forged := &SignedURL{URL: download.URL + "&scope=" + AttachmentScopePreview}
+	if err := openSigned(problems, attachment.ID, forged); err == nil || err.Error() != "下载链接签名无效" {
+		t.Errorf("修改用途后签名应无效，得到%v", err)
+	}
+
+	// 未发布题目只能由管理员生成预览链接
+	if _, err := problems.SignAttachmentURL(attachment.ID, user.ID, 0, false); err == nil || err.Error() != "附件不存在" {
+		t.Errorf("选手不应能为未发布题目生成链接，得到%v", err)
+	}
+	preview, err := problems.SignAttachmentURL(attachment.ID, env.admin.ID, 0, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(preview.URL, "scope="+AttachmentScopePreview) {
+		t.Errorf("未发布题目的链接应为预览用途: %s", preview.URL)
+	}
+	if err := openSigned(problems, attachment.ID, preview); err != nil {
+		t.Errorf("预览链接应可下载未发布题目的附件: %v", err)
+	}
+
+	// 题目删除后预览链接同样失效
+	if err := env.db.Delete(&model.Problem{}, problem.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := openSigned(problems, attachment.ID, preview); err == nil || err.Error() != "附件不存在" {
+		t.Errorf("题目删除后预览链接应失效，得到%v", err)
+	}
+}
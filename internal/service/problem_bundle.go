@@ -0,0 +1,576 @@
+package service
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tksky1/glimgate/internal/model"
+	"github.com/tksky1/glimgate/internal/repository"
+	"github.com/tksky1/glimgate/pkg/bundle"
+)
+
+// 题目包导入对题目的处理结果
+const (
+	BundleActionCreate    = "create"
+	BundleActionUpdate    = "update"
+	BundleActionUnchanged = "unchanged"
+)
+
+// 题目包差异项的变更类型
+const (
+	BundleChangeCreate = "create"
+	BundleChangeUpdate = "update"
+	BundleChangeDelete = "delete"
+)
+
+// bundleRevisionNote 导入题目包产生的修订记录的修改说明
+const bundleRevisionNote = "导入题目包"
+
+// ErrBundleConflict 题目包与现有数据冲突，无法导入
+var ErrBundleConflict = errors.New("题目包与现有数据冲突")
+
+// BundleChange 题目包与现有题目的一项差异
+type BundleChange struct {
+	Path   string `json:"path" example:"submission_points[源代码提交].max_score"`
+	Action string `json:"action" example:"update"`
+	Before string `json:"before,omitempty" example:"100"`
+	After  string `json:"after,omitempty" example:"80"`
+}
+
+// ImportBundleResult 题目包导入结果，DryRun为true时只计算差异不写入
+type ImportBundleResult struct {
+	DryRun    bool           `json:"dry_run" example:"false"`
+	Action    string         `json:"action" example:"update"`
+	ProblemID uint           `json:"problem_id,omitempty" example:"1"`
+	Slug      string         `json:"slug" example:"calculator"`
+	Changes   []BundleChange `json:"changes"`
+}
+
+// ImportBundle 导入题目包，按方向和标识匹配已有题目并只应用有差异的部分，重复导入同一题目包不会产生变更
+// 找不到标识相同的题目时，若方向内恰好有一个标题相同且未设置标识的题目，则沿用该题目并补上标识
+// 题目包中没有的提交点会被删除，已有提交的提交点不能删除；清单填写了attachments时附件按文件名和校验和同步
+func (s *ProblemService) ImportBundle(op *Operator, b *bundle.Bundle, dryRun bool) (*ImportBundleResult, error) {
+	manifest := &b.Manifest
+	if err := manifest.Validate(); err != nil {
+		return nil, err
+	}
+
+	// 事务失败时删除本次写入存储的附件文件
+	var storedKeys []string
+	result := &ImportBundleResult{DryRun: dryRun, Slug: manifest.Slug, Changes: []BundleChange{}}
+	err := s.repos.Transaction(func(tx *repository.Repositories) error {
+		direction, err := tx.Directions.FindByName(manifest.Direction)
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return errors.New("方向不存在")
+			}
+			return err
+		}
+
+		problem, err := findBundleProblem(tx, direction.ID, manifest)
+		if err != nil {
+			return err
+		}
+
+		if problem == nil {
+			attachmentPlans, attachmentChanges, err := diffBundleAttachments(nil, b)
+			if err != nil {
+				return err
+			}
+			result.Action = BundleActionCreate
+			result.Changes = append(result.Changes, BundleChange{Path: "problem", Action: BundleChangeCreate, After: manifest.Title})
+			for _, point := range manifest.SubmissionPoints {
+				result.Changes = append(result.Changes, BundleChange{Path: pointPath(point.Name), Action: BundleChangeCreate, After: strconv.Itoa(point.MaxScore)})
+			}
+			result.Changes = append(result.Changes, attachmentChanges...)
+			if dryRun {
+				return nil
+			}
+			problemID, err := s.createBundleProblem(tx, op, direction.ID, manifest)
+			result.ProblemID = problemID
+			if err != nil {
+				return err
+			}
+			if _, err := recordRevision(tx, op, problemID, false, bundleRevisionNote); err != nil {
+				return err
+			}
+			return s.applyBundleAttachments(tx, op, problemID, attachmentPlans, &storedKeys)
+		}
+
+		result.ProblemID = problem.ID
+		updates, problemChanges := diffBundleProblem(problem, manifest)
+		tagsChanged, tagChanges := diffBundleTags(problem.Tags, manifest.Tags)
+		problemChanges = append(problemChanges, tagChanges...)
+		pointPlans, pointChanges := diffBundlePoints(problem.SubmissionPoints, manifest.SubmissionPoints)
+		attachmentPlans, attachmentChanges, err := diffBundleAttachments(problem.Attachments, b)
+		if err != nil {
+			return err
+		}
+		result.Changes = append(append(append(result.Changes, problemChanges...), pointChanges...), attachmentChanges...)
+
+		// 删除提交点前检查是否已有提交，试运行时也需要报告
+		for _, plan := range pointPlans {
+			if plan.existing == nil || plan.manifest != nil {
+				continue
+			}
+			count, err := tx.Submissions.CountByPoint(plan.existing.ID)
+			if err != nil {
+				return err
+			}
+			if count > 0 {
+				return fmt.Errorf("%w: 提交点「%s」已有提交记录，不能通过导入删除", ErrBundleConflict, plan.existing.Name)
+			}
+		}
+		if _, ok := updates["team_size"]; ok {
+			if err := checkTeamSizeChange(tx, problem, manifest.TeamSize); err != nil {
+				return fmt.Errorf("%w: %v", ErrBundleConflict, err)
+			}
+		}
+
+		if len(result.Changes) == 0 {
+			result.Action = BundleActionUnchanged
+			return nil
+		}
+		result.Action = BundleActionUpdate
+		if dryRun {
+			return nil
+		}
+
+		if len(updates) > 0 || tagsChanged {
+			before := *problem
+			before.SubmissionPoints = nil
+			before.Attachments = nil
+			if len(updates) > 0 {
+				if err := tx.Problems.Update(problem, updates); err != nil {
+					return err
+				}
+			}
+			if _, ok := updates["team_size"]; ok && manifest.TeamSize == 0 {
+				if err := tx.Teams.DeleteByProblem(problem.ID); err != nil {
+					return err
+				}
+			}
+			if tagsChanged {
+				if err := tx.Problems.ReplaceTags(problem.ID, manifest.Tags); err != nil {
+					return err
+				}
+			}
+			after, err := tx.Problems.FindByID(problem.ID, "Direction", "Tags")
+			if err != nil {
+				return err
+			}
+			if err := s.auditService.Record(tx.AuditLogs, op, AuditActionUpdate, AuditEntityProblem, problem.ID, before, after); err != nil {
+				return err
+			}
+		}
+		if err := s.applyBundlePoints(tx, op, problem.ID, pointPlans); err != nil {
+			return err
+		}
+		if _, err := recordRevision(tx, op, problem.ID, false, bundleRevisionNote); err != nil {
+			return err
+		}
+		return s.applyBundleAttachments(tx, op, problem.ID, attachmentPlans, &storedKeys)
+	})
+	if err != nil {
+		for _, key := range storedKeys {
+			s.removeStoredFile(key)
+		}
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// findBundleProblem 查找题目包对应的已有题目，没有时返回nil
+func findBundleProblem(tx *repository.Repositories, directionID uint, manifest *bundle.Manifest) (*model.Problem, error) {
+	problem, err := tx.Problems.FindBySlug(directionID, manifest.Slug, "SubmissionPoints", "Attachments", "Tags")
+	if err == nil {
+		return problem, nil
+	}
+	if !errors.Is(err, repository.ErrNotFound) {
+		return nil, err
+	}
+
+	problems, err := tx.Problems.ListByTitle(directionID, manifest.Title, "SubmissionPoints", "Attachments", "Tags")
+	if err != nil {
+		return nil, err
+	}
+	var candidates []model.Problem
+	for _, p := range problems {
+		if p.Slug == "" {
+			candidates = append(candidates, p)
+		}
+	}
+	switch len(candidates) {
+	case 0:
+		return nil, nil
+	case 1:
+		return &candidates[0], nil
+	default:
+		return nil, fmt.Errorf("%w: 方向中有多个标题为「%s」且未设置标识的题目，请先为目标题目设置标识", ErrBundleConflict, manifest.Title)
+	}
+}
+
+// createBundleProblem 按题目包创建题目及其提交点，新题目为草稿，确认无误后再发布
+func (s *ProblemService) createBundleProblem(tx *repository.Repositories, op *Operator, directionID uint, manifest *bundle.Manifest) (uint, error) {
+	problem := &model.Problem{
+		Title:            manifest.Title,
+		Description:      manifest.Description,
+		DirectionID:      directionID,
+		Slug:             manifest.Slug,
+		Difficulty:       manifest.Difficulty,
+		EstimatedMinutes: manifest.EstimatedMinutes,
+		TeamSize:         manifest.TeamSize,
+		Status:           ProblemStatusDraft,
+	}
+	if err := tx.Problems.Create(problem); err != nil {
+		return 0, err
+	}
+	if err := tx.Problems.ReplaceTags(problem.ID, manifest.Tags); err != nil {
+		return 0, err
+	}
+	created, err := tx.Problems.FindByID(problem.ID, "Direction", "Tags")
+	if err != nil {
+		return 0, err
+	}
+	if err := s.auditService.Record(tx.AuditLogs, op, AuditActionCreate, AuditEntityProblem, problem.ID, nil, created); err != nil {
+		return 0, err
+	}
+
+	plans := make([]bundlePointPlan, len(manifest.SubmissionPoints))
+	for i := range manifest.SubmissionPoints {
+		plans[i] = bundlePointPlan{manifest: &manifest.SubmissionPoints[i]}
+	}
+	return problem.ID, s.applyBundlePoints(tx, op, problem.ID, plans)
+}
+
+// bundlePointPlan 提交点的导入计划：existing为空时创建，manifest为空时删除，两者都有时按updates更新
+type bundlePointPlan struct {
+	existing *model.SubmissionPoint
+	manifest *bundle.Point
+	updates  map[string]interface{}
+}
+
+// applyBundlePoints 执行提交点的导入计划并记录审计日志
+func (s *ProblemService) applyBundlePoints(tx *repository.Repositories, op *Operator, problemID uint, plans []bundlePointPlan) error {
+	for _, plan := range plans {
+		switch {
+		case plan.existing == nil:
+			point := &model.SubmissionPoint{
+				Name:      plan.manifest.Name,
+				MaxScore:  plan.manifest.MaxScore,
+				ProblemID: problemID,
+				Rubric:    plan.manifest.Rubric,
+				Deadline:  plan.manifest.Deadline,
+			}
+			if err := tx.Problems.CreatePoint(point); err != nil {
+				return err
+			}
+			if err := s.auditService.Record(tx.AuditLogs, op, AuditActionCreate, AuditEntitySubmissionPoint, point.ID, nil, point); err != nil {
+				return err
+			}
+
+		case plan.manifest == nil:
+			if err := tx.Problems.DeletePoint(plan.existing); err != nil {
+				return err
+			}
+			if err := s.auditService.Record(tx.AuditLogs, op, AuditActionDelete, AuditEntitySubmissionPoint, plan.existing.ID, plan.existing, nil); err != nil {
+				return err
+			}
+
+		case len(plan.updates) > 0:
+			before := *plan.existing
+			if err := tx.Problems.UpdatePoint(plan.existing, plan.updates); err != nil {
+				return err
+			}
+			after, err := tx.Problems.FindPointByID(plan.existing.ID)
+			if err != nil {
+				return err
+			}
+			if err := s.auditService.Record(tx.AuditLogs, op, AuditActionUpdate, AuditEntitySubmissionPoint, plan.existing.ID, before, after); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// diffBundleProblem 比较题目本身的字段，返回需要更新的字段和差异
+func diffBundleProblem(problem *model.Problem, manifest *bundle.Manifest) (map[string]interface{}, []BundleChange) {
+	updates := make(map[string]interface{})
+	var changes []BundleChange
+	compare := func(column, before, after string) {
+		if before != after {
+			updates[column] = after
+			changes = append(changes, BundleChange{Path: column, Action: BundleChangeUpdate, Before: before, After: after})
+		}
+	}
+	compare("slug", problem.Slug, manifest.Slug)
+	compare("title", problem.Title, manifest.Title)
+	compare("description", problem.Description, manifest.Description)
+	compareInt := func(column string, before, after int) {
+		if before != after {
+			updates[column] = after
+			changes = append(changes, BundleChange{Path: column, Action: BundleChangeUpdate, Before: strconv.Itoa(before), After: strconv.Itoa(after)})
+		}
+	}
+	compareInt("difficulty", problem.Difficulty, manifest.Difficulty)
+	compareInt("estimated_minutes", problem.EstimatedMinutes, manifest.EstimatedMinutes)
+	compareInt("team_size", problem.TeamSize, manifest.TeamSize)
+	return updates, changes
+}
+
+// diffBundleTags 比较题目标签，不考虑顺序
+func diffBundleTags(existing []model.ProblemTag, tags []string) (bool, []BundleChange) {
+	before := make([]string, len(existing))
+	for i, tag := range existing {
+		before[i] = tag.Name
+	}
+	after := append([]string(nil), tags...)
+	sort.Strings(before)
+	sort.Strings(after)
+
+	beforeText, afterText := strings.Join(before, ", "), strings.Join(after, ", ")
+	if beforeText == afterText {
+		return false, nil
+	}
+	return true, []BundleChange{{Path: "tags", Action: BundleChangeUpdate, Before: beforeText, After: afterText}}
+}
+
+// diffBundlePoints 按名称比较提交点，返回导入计划和差异；已有提交点按ID排序，新增的按题目包中的顺序排在后面
+func diffBundlePoints(existing []model.SubmissionPoint, points []bundle.Point) ([]bundlePointPlan, []BundleChange) {
+	sort.Slice(existing, func(i, j int) bool { return existing[i].ID < existing[j].ID })
+	byName := make(map[string]*bundle.Point, len(points))
+	for i := range points {
+		byName[points[i].Name] = &points[i]
+	}
+
+	var plans []bundlePointPlan
+	var changes []BundleChange
+	matched := make(map[string]bool)
+	for i := range existing {
+		point := &existing[i]
+		manifestPoint, ok := byName[point.Name]
+		if !ok || matched[point.Name] {
+			plans = append(plans, bundlePointPlan{existing: point})
+			changes = append(changes, BundleChange{Path: pointPath(point.Name), Action: BundleChangeDelete, Before: strconv.Itoa(point.MaxScore)})
+			continue
+		}
+		matched[point.Name] = true
+
+		plan := bundlePointPlan{existing: point, manifest: manifestPoint, updates: make(map[string]interface{})}
+		if point.MaxScore != manifestPoint.MaxScore {
+			plan.updates["max_score"] = manifestPoint.MaxScore
+			changes = append(changes, BundleChange{Path: pointPath(point.Name) + ".max_score", Action: BundleChangeUpdate,
+				Before: strconv.Itoa(point.MaxScore), After: strconv.Itoa(manifestPoint.MaxScore)})
+		}
+		if point.Rubric != manifestPoint.Rubric {
+			plan.updates["rubric"] = manifestPoint.Rubric
+			changes = append(changes, BundleChange{Path: pointPath(point.Name) + ".rubric", Action: BundleChangeUpdate,
+				Before: point.Rubric, After: manifestPoint.Rubric})
+		}
+		if !sameDeadline(point.Deadline, manifestPoint.Deadline) {
+			// 截止时间变更后重新发送提醒
+			plan.updates["deadline"] = manifestPoint.Deadline
+			plan.updates["reminder_sent_at"] = nil
+			changes = append(changes, BundleChange{Path: pointPath(point.Name) + ".deadline", Action: BundleChangeUpdate,
+				Before: formatDeadline(point.Deadline), After: formatDeadline(manifestPoint.Deadline)})
+		}
+		plans = append(plans, plan)
+	}
+
+	for i := range points {
+		if matched[points[i].Name] {
+			continue
+		}
+		plans = append(plans, bundlePointPlan{manifest: &points[i]})
+		changes = append(changes, BundleChange{Path: pointPath(points[i].Name), Action: BundleChangeCreate, After: strconv.Itoa(points[i].MaxScore)})
+	}
+	return plans, changes
+}
+
+// bundleAttachmentPlan 附件的导入计划：existing为空时创建，data为空时删除，两者都有时删除旧附件后重新创建
+type bundleAttachmentPlan struct {
+	existing *model.ProblemAttachment
+	name     string
+	data     []byte
+}
+
+// diffBundleAttachments 按文件名和校验和比较附件，清单未填写attachments时不改动已有附件
+// 清单中的附件未附带文件时，要求已有同名附件且校验和一致
+func diffBundleAttachments(existing []model.ProblemAttachment, b *bundle.Bundle) ([]bundleAttachmentPlan, []BundleChange, error) {
+	if b.Manifest.Attachments == nil {
+		return nil, nil, nil
+	}
+	sort.Slice(existing, func(i, j int) bool { return existing[i].ID < existing[j].ID })
+	byName := make(map[string]*model.ProblemAttachment, len(existing))
+	for i := range existing {
+		byName[existing[i].Filename] = &existing[i]
+	}
+
+	var plans []bundleAttachmentPlan
+	var changes []BundleChange
+	declared := make(map[string]bool)
+	for _, attachment := range b.Manifest.Attachments {
+		declared[attachment.Name] = true
+		old := byName[attachment.Name]
+		data, ok := b.Files[attachment.Name]
+		if !ok {
+			if old == nil || old.SHA256 != attachment.SHA256 {
+				return nil, nil, fmt.Errorf("%w: 附件「%s」未附带文件，且与已有附件不一致", ErrBundleConflict, attachment.Name)
+			}
+			continue
+		}
+
+		sum := bundle.Checksum(data)
+		switch {
+		case old == nil:
+			plans = append(plans, bundleAttachmentPlan{name: attachment.Name, data: data})
+			changes = append(changes, BundleChange{Path: attachmentPath(attachment.Name), Action: BundleChangeCreate, After: sum})
+		case old.SHA256 != sum:
+			plans = append(plans, bundleAttachmentPlan{existing: old, name: attachment.Name, data: data})
+			changes = append(changes, BundleChange{Path: attachmentPath(attachment.Name), Action: BundleChangeUpdate, Before: old.SHA256, After: sum})
+		}
+	}
+
+	for i := range existing {
+		if declared[existing[i].Filename] {
+			continue
+		}
+		plans = append(plans, bundleAttachmentPlan{existing: &existing[i]})
+		changes = append(changes, BundleChange{Path: attachmentPath(existing[i].Filename), Action: BundleChangeDelete, Before: existing[i].SHA256})
+	}
+	return plans, changes, nil
+}
+
+// applyBundleAttachments 执行附件的导入计划并记录审计日志，写入存储的文件key追加到storedKeys
+// 被替换或删除的附件进入回收站，文件在回收站清理时删除
+func (s *ProblemService) applyBundleAttachments(tx *repository.Repositories, op *Operator, problemID uint, plans []bundleAttachmentPlan, storedKeys *[]string) error {
+	for _, plan := range plans {
+		if plan.existing != nil {
+			if err := tx.Problems.DeleteAttachment(plan.existing); err != nil {
+				return err
+			}
+			if err := s.auditService.Record(tx.AuditLogs, op, AuditActionDelete, AuditEntityProblemAttachment, plan.existing.ID, plan.existing, nil); err != nil {
+				return err
+			}
+		}
+		if plan.data == nil {
+			continue
+		}
+
+		attachment, err := s.storeAttachment(problemID, plan.name, "", bytes.NewReader(plan.data))
+		if err != nil {
+			return err
+		}
+		*storedKeys = append(*storedKeys, attachment.StorageKey)
+		if op != nil && op.UserID > 0 {
+			attachment.UploaderID = &op.UserID
+		}
+		if err := tx.Problems.CreateAttachment(attachment); err != nil {
+			return err
+		}
+		if err := s.auditService.Record(tx.AuditLogs, op, AuditActionCreate, AuditEntityProblemAttachment, attachment.ID, nil, attachment); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ExportBundle 将题目导出为题目包，未设置标识的题目使用problem-<ID>作为标识，附件从存储中读取
+func (s *ProblemService) ExportBundle(problemID uint) (*bundle.Bundle, error) {
+	problem, err := s.repos.Problems.FindByID(problemID, "Direction", "SubmissionPoints", "Attachments", "Tags")
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, errors.New("题目不存在")
+		}
+		return nil, err
+	}
+
+	slug := problem.Slug
+	if slug == "" {
+		slug = fmt.Sprintf("problem-%d", problem.ID)
+	}
+	manifest := bundle.Manifest{
+		Version:          bundle.FormatVersion,
+		Slug:             slug,
+		Direction:        problem.Direction.Name,
+		Title:            problem.Title,
+		Description:      problem.Description,
+		Difficulty:       problem.Difficulty,
+		EstimatedMinutes: problem.EstimatedMinutes,
+		TeamSize:         problem.TeamSize,
+		SubmissionPoints: []bundle.Point{},
+	}
+	if len(problem.Tags) > 0 {
+		manifest.Tags = problemTagNames(problem)
+		sort.Strings(manifest.Tags)
+	}
+
+	points := problem.SubmissionPoints
+	sort.Slice(points, func(i, j int) bool { return points[i].ID < points[j].ID })
+	for _, point := range points {
+		manifest.SubmissionPoints = append(manifest.SubmissionPoints, bundle.Point{
+			Name:     point.Name,
+			MaxScore: point.MaxScore,
+			Deadline: point.Deadline,
+			Rubric:   point.Rubric,
+		})
+	}
+
+	files := make(map[string][]byte)
+	attachments := problem.Attachments
+	sort.Slice(attachments, func(i, j int) bool { return attachments[i].Filename < attachments[j].Filename })
+	for _, attachment := range attachments {
+		data, err := s.readStoredFile(attachment.StorageKey)
+		if err != nil {
+			return nil, fmt.Errorf("读取附件「%s」失败: %w", attachment.Filename, err)
+		}
+		manifest.Attachments = append(manifest.Attachments, bundle.Attachment{Name: attachment.Filename, SHA256: attachment.SHA256})
+		files[attachment.Filename] = data
+	}
+
+	return &bundle.Bundle{Manifest: manifest, Files: files}, nil
+}
+
+// readStoredFile 读取存储中的整个文件
+func (s *ProblemService) readStoredFile(key string) ([]byte, error) {
+	file, err := s.store.Open(key)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return io.ReadAll(file)
+}
+
+// pointPath 提交点在差异中的路径
+func pointPath(name string) string {
+	return fmt.Sprintf("submission_points[%s]", name)
+}
+
+// attachmentPath 附件在差异中的路径
+func attachmentPath(name string) string {
+	return fmt.Sprintf("attachments[%s]", name)
+}
+
+// sameDeadline 比较两个可能为空的截止时间
+func sameDeadline(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return a.Equal(*b)
+}
+
+// formatDeadline 格式化截止时间，为空时返回空字符串
+func formatDeadline(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
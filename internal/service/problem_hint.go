@@ -0,0 +1,301 @@
+package service
+
+import (
+	"errors"
+
+	"github.com/tksky1/glimgate/internal/model"
+	"github.com/tksky1/glimgate/internal/repository"
+	"github.com/tksky1/glimgate/pkg/markdown"
+)
+
+// maxHints 每道题目的提示数量上限
+const maxHints = 10
+
+// CreateHintRequest 创建提示请求结构
+type CreateHintRequest struct {
+	Content string `json:"content" binding:"required" example:"可以先用栈把中缀表达式转换为后缀表达式"`
+	Penalty int    `json:"penalty" example:"10"`
+	// Position 解锁顺序，未指定时排在最后
+	Position *int `json:"position" example:"1"`
+}
+
+// UpdateHintRequest 更新提示请求结构，未传的字段不修改；惩罚分只影响之后的解锁
+type UpdateHintRequest struct {
+	Content  string `json:"content" example:"可以先用栈把中缀表达式转换为后缀表达式"`
+	Penalty  *int   `json:"penalty" example:"10"`
+	Position *int   `json:"position" example:"1"`
+}
+
+// ProblemHintStat 提示及其解锁人数
+type ProblemHintStat struct {
+	model.ProblemHint
+	UnlockCount int64 `json:"unlock_count" example:"5"`
+}
+
+// CreateHint 为题目添加提示，提示内容为Markdown，包含不安全的HTML时拒绝
+func (s *ProblemService) CreateHint(op *Operator, problemID uint, req *CreateHintRequest) (*model.ProblemHint, error) {
+	if err := markdown.Check(req.Content); err != nil {
+		return nil, err
+	}
+	if err := checkHintPenalty(req.Penalty); err != nil {
+		return nil, err
+	}
+
+	hint := &model.ProblemHint{
+		ProblemID: problemID,
+		Content:   req.Content,
+		Penalty:   req.Penalty,
+	}
+	err := s.repos.Transaction(func(tx *repository.Repositories) error {
+		if _, err := tx.Problems.FindByID(problemID); err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return errors.New("题目不存在")
+			}
+			return err
+		}
+
+		hints, err := tx.Problems.ListHints(problemID)
+		if err != nil {
+			return err
+		}
+		if len(hints) >= maxHints {
+			return errors.New("提示不能超过10个")
+		}
+		switch {
+		case req.Position != nil && *req.Position < 0:
+			return errors.New("提示顺序不能为负数")
+		case req.Position != nil:
+			hint.Position = *req.Position
+		case len(hints) > 0:
+			hint.Position = hints[len(hints)-1].Position + 1
+		default:
+			hint.Position = 1
+		}
+
+		if err := tx.Problems.CreateHint(hint); err != nil {
+			return err
+		}
+		return s.auditService.Record(tx.AuditLogs, op, AuditActionCreate, AuditEntityProblemHint, hint.ID, nil, hint)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	hints := []model.ProblemHint{*hint}
+	renderHints(hints)
+	return &hints[0], nil
+}
+
+// UpdateHint 更新提示，已解锁记录的惩罚分在解锁时确定，修改惩罚分不影响已解锁的选手
+func (s *ProblemService) UpdateHint(op *Operator, hintID uint, req *UpdateHintRequest) (*model.ProblemHint, error) {
+	var after *model.ProblemHint
+	err := s.repos.Transaction(func(tx *repository.Repositories) error {
+		hint, err := tx.Problems.FindHintByID(hintID)
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return errors.New("提示不存在")
+			}
+			return err
+		}
+		before := *hint
+
+		updates := make(map[string]interface{})
+		if req.Content != "" {
+			if err := markdown.Check(req.Content); err != nil {
+				return err
+			}
+			updates["content"] = req.Content
+		}
+		if req.Penalty != nil {
+			if err := checkHintPenalty(*req.Penalty); err != nil {
+				return err
+			}
+			updates["penalty"] = *req.Penalty
+		}
+		if req.Position != nil {
+			if *req.Position < 0 {
+				return errors.New("提示顺序不能为负数")
+			}
+			updates["position"] = *req.Position
+		}
+		if len(updates) > 0 {
+			if err := tx.Problems.UpdateHint(hint, updates); err != nil {
+				return err
+			}
+		}
+
+		after, err = tx.Problems.FindHintByID(hintID)
+		if err != nil {
+			return err
+		}
+		return s.auditService.Record(tx.AuditLogs, op, AuditActionUpdate, AuditEntityProblemHint, hintID, before, after)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	hints := []model.ProblemHint{*after}
+	renderHints(hints)
+	return &hints[0], nil
+}
+
+// DeleteHint 删除提示，已有选手解锁的提示不能删除，以免已扣除的惩罚分失去依据
+func (s *ProblemService) DeleteHint(op *Operator, hintID uint) error {
+	return s.repos.Transaction(func(tx *repository.Repositories) error {
+		hint, err := tx.Problems.FindHintByID(hintID)
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return errors.New("提示不存在")
+			}
+			return err
+		}
+
+		counts, err := tx.Problems.HintUnlockCounts(hint.ProblemID)
+		if err != nil {
+			return err
+		}
+		if counts[hint.ID] > 0 {
+			return errors.New("提示已被选手解锁，无法删除")
+		}
+
+		if err := tx.Problems.DeleteHint(hint); err != nil {
+			return err
+		}
+		return s.auditService.Record(tx.AuditLogs, op, AuditActionDelete, AuditEntityProblemHint, hint.ID, hint, nil)
+	})
+}
+
+// GetAdminHints 获取题目的全部提示及各提示的解锁人数
+func (s *ProblemService) GetAdminHints(problemID uint) ([]ProblemHintStat, error) {
+	if _, err := s.repos.Problems.FindByID(problemID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, errors.New("题目不存在")
+		}
+		return nil, err
+	}
+
+	hints, err := s.repos.Problems.ListHints(problemID)
+	if err != nil {
+		return nil, err
+	}
+	counts, err := s.repos.Problems.HintUnlockCounts(problemID)
+	if err != nil {
+		return nil, err
+	}
+
+	renderHints(hints)
+	stats := make([]ProblemHintStat, len(hints))
+	for i, hint := range hints {
+		stats[i] = ProblemHintStat{ProblemHint: hint, UnlockCount: counts[hint.ID]}
+	}
+	return stats, nil
+}
+
+// GetHints 获取题目的提示列表，题目不可见或userID未解锁题目时返回错误；未解锁的提示只返回顺序和惩罚分
+func (s *ProblemService) GetHints(problemID, userID uint) ([]model.ProblemHint, error) {
+	problem, err := findVisibleProblem(s.repos, problemID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkProblemUnlocked(s.repos, userID, problem); err != nil {
+		return nil, err
+	}
+
+	hints, err := s.repos.Problems.ListHints(problemID)
+	if err != nil {
+		return nil, err
+	}
+	unlocks, err := s.repos.Problems.ListHintUnlocks(userID, problemID)
+	if err != nil {
+		return nil, err
+	}
+	unlocked := make(map[uint]bool, len(unlocks))
+	for _, unlock := range unlocks {
+		unlocked[unlock.HintID] = true
+	}
+
+	renderHints(hints)
+	for i := range hints {
+		if unlocked[hints[i].ID] {
+			hints[i].Unlocked = true
+			continue
+		}
+		hints[i].Content = ""
+		hints[i].ContentHTML = ""
+	}
+	return hints, nil
+}
+
+// UnlockHint 选手解锁提示并记录，需按顺序解锁，惩罚分从该题得分中扣除；重复解锁不会重复扣分
+func (s *ProblemService) UnlockHint(userID, problemID, hintID uint) (*model.ProblemHint, error) {
+	var hint *model.ProblemHint
+	err := s.repos.Transaction(func(tx *repository.Repositories) error {
+		problem, err := findVisibleProblem(tx, problemID)
+		if err != nil {
+			return err
+		}
+		if problem.Status == ProblemStatusArchived {
+			return errors.New("题目已归档，不能再解锁提示")
+		}
+		if err := checkProblemUnlocked(tx, userID, problem); err != nil {
+			return err
+		}
+
+		hints, err := tx.Problems.ListHints(problemID)
+		if err != nil {
+			return err
+		}
+		unlocks, err := tx.Problems.ListHintUnlocks(userID, problemID)
+		if err != nil {
+			return err
+		}
+		unlocked := make(map[uint]bool, len(unlocks))
+		for _, unlock := range unlocks {
+			unlocked[unlock.HintID] = true
+		}
+
+		index := -1
+		for i := range hints {
+			if hints[i].ID == hintID {
+				index = i
+				break
+			}
+		}
+		if index < 0 {
+			return errors.New("提示不存在")
+		}
+		hint = &hints[index]
+		if unlocked[hint.ID] {
+			return nil
+		}
+		for _, previous := range hints[:index] {
+			if !unlocked[previous.ID] {
+				return errors.New("请先解锁前面的提示")
+			}
+		}
+
+		_, err = tx.Problems.CreateHintUnlock(&model.HintUnlock{
+			UserID:    userID,
+			HintID:    hint.ID,
+			ProblemID: problemID,
+			Penalty:   hint.Penalty,
+		})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	hints := []model.ProblemHint{*hint}
+	renderHints(hints)
+	hints[0].Unlocked = true
+	return &hints[0], nil
+}
+
+// checkHintPenalty 检查提示惩罚分
+func checkHintPenalty(penalty int) error {
+	if penalty < 0 {
+		return errors.New("惩罚分不能为负数")
+	}
+	return nil
+}
@@ -0,0 +1,384 @@
+package service
+
+import (
+	"errors"
+
+	"github.com/tksky1/glimgate/internal/model"
+	"github.com/tksky1/glimgate/internal/repository"
+)
+
+// 解锁条件类型
+const (
+	PrerequisiteTypeSubmitted = "submitted"
+	PrerequisiteTypeScore     = "score"
+)
+
+// maxPrerequisites 每道题目的解锁条件数量上限
+const maxPrerequisites = 10
+
+// PrerequisiteRequest 解锁条件
+type PrerequisiteRequest struct {
+	RequiredProblemID uint `json:"required_problem_id" binding:"required" example:"1"`
+	// Type submitted在前置题目有提交即解锁，score在前置题目的总分不低于min_score时解锁
+	Type     string `json:"type" binding:"required" example:"score"`
+	MinScore int    `json:"min_score" example:"60"`
+}
+
+// UpdatePrerequisitesRequest 设置解锁条件请求结构，需满足全部条件才能解锁，空列表表示不需要解锁
+type UpdatePrerequisitesRequest struct {
+	Prerequisites []PrerequisiteRequest `json:"prerequisites"`
+}
+
+// ProblemUnlockStat 题目解锁情况统计
+type ProblemUnlockStat struct {
+	ProblemID      uint                        `json:"problem_id" example:"2"`
+	Title          string                      `json:"title" example:"实现一个简单的计算器"`
+	Status         string                      `json:"status" example:"published"`
+	Prerequisites  []model.ProblemPrerequisite `json:"prerequisites"`
+	UnlockedCount  int64                       `json:"unlocked_count" example:"12"`
+	CandidateCount int64                       `json:"candidate_count" example:"40"`
+}
+
+// UpdatePrerequisites 设置题目的解锁条件，替换已有的全部条件
+// 前置题目不能是题目自身，条件之间不能形成循环依赖
+func (s *ProblemService) UpdatePrerequisites(op *Operator, problemID uint, req *UpdatePrerequisitesRequest) (*model.Problem, error) {
+	if len(req.Prerequisites) > maxPrerequisites {
+		return nil, errors.New("解锁条件不能超过10个")
+	}
+
+	var after *model.Problem
+	err := s.repos.Transaction(func(tx *repository.Repositories) error {
+		problem, err := tx.Problems.FindByID(problemID, "Prerequisites")
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return errors.New("题目不存在")
+			}
+			return err
+		}
+		before := *problem
+
+		prerequisites := make([]model.ProblemPrerequisite, 0, len(req.Prerequisites))
+		seen := make(map[uint]bool, len(req.Prerequisites))
+		for _, item := range req.Prerequisites {
+			if item.RequiredProblemID == problemID {
+				return errors.New("不能以题目自身作为前置题目")
+			}
+			if seen[item.RequiredProblemID] {
+				return errors.New("前置题目重复")
+			}
+			seen[item.RequiredProblemID] = true
+
+			switch item.Type {
+			case PrerequisiteTypeSubmitted:
+				item.MinScore = 0
+			case PrerequisiteTypeScore:
+				if item.MinScore < 1 {
+					return errors.New("得分条件的最低分必须大于0")
+				}
+			default:
+				return errors.New("解锁条件类型无效")
+			}
+
+			if _, err := tx.Problems.FindByID(item.RequiredProblemID); err != nil {
+				if errors.Is(err, repository.ErrNotFound) {
+					return errors.New("前置题目不存在")
+				}
+				return err
+			}
+			prerequisites = append(prerequisites, model.ProblemPrerequisite{
+				RequiredProblemID: item.RequiredProblemID,
+				Type:              item.Type,
+				MinScore:          item.MinScore,
+			})
+		}
+
+		if err := checkPrerequisiteCycle(tx, problemID, prerequisites); err != nil {
+			return err
+		}
+		if err := tx.Problems.ReplacePrerequisites(problemID, prerequisites); err != nil {
+			return err
+		}
+
+		after, err = tx.Problems.FindByID(problemID, "Direction", "Tags", "Prerequisites")
+		if err != nil {
+			return err
+		}
+
+		return s.auditService.Record(tx.AuditLogs, op, AuditActionUpdate, AuditEntityProblem, problemID, before, after)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	renderProblem(after)
+	return after, nil
+}
+
+// checkPrerequisiteCycle 检查将题目的解锁条件替换为prerequisites后是否存在循环依赖
+func checkPrerequisiteCycle(tx *repository.Repositories, problemID uint, prerequisites []model.ProblemPrerequisite) error {
+	existing, err := tx.Problems.ListPrerequisites()
+	if err != nil {
+		return err
+	}
+	requires := make(map[uint][]uint)
+	for _, p := range existing {
+		if p.ProblemID != problemID {
+			requires[p.ProblemID] = append(requires[p.ProblemID], p.RequiredProblemID)
+		}
+	}
+	for _, p := range prerequisites {
+		requires[problemID] = append(requires[problemID], p.RequiredProblemID)
+	}
+
+	// 从前置题目出发沿解锁条件查找，能回到题目自身即存在循环
+	visited := make(map[uint]bool)
+	stack := append([]uint(nil), requires[problemID]...)
+	for len(stack) > 0 {
+		id := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if id == problemID {
+			return errors.New("解锁条件存在循环依赖")
+		}
+		if visited[id] {
+			continue
+		}
+		visited[id] = true
+		stack = append(stack, requires[id]...)
+	}
+	return nil
+}
+
+// unlockProgress 用户在各题目上的提交和得分，用于判断解锁条件
+type unlockProgress struct {
+	submitted map[uint]bool
+	scores    map[uint]int
+}
+
+// loadUnlockProgress 加载用户的提交和得分，userID为0（未登录）时视为没有任何提交
+func loadUnlockProgress(repos *repository.Repositories, userID uint) (*unlockProgress, error) {
+	progress := &unlockProgress{submitted: make(map[uint]bool), scores: make(map[uint]int)}
+	if userID == 0 {
+		return progress, nil
+	}
+
+	problemIDs, err := repos.Submissions.ProblemIDsByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range problemIDs {
+		progress.submitted[id] = true
+	}
+
+	totals, err := repos.Scores.ProblemTotalsByUser(userID, TeamScoreSplit())
+	if err != nil {
+		return nil, err
+	}
+	for _, total := range totals {
+		progress.scores[total.ProblemID] = total.Score
+	}
+	return progress, nil
+}
+
+// satisfied 是否满足一条解锁条件
+func (p *unlockProgress) satisfied(prerequisite *model.ProblemPrerequisite) bool {
+	switch prerequisite.Type {
+	case PrerequisiteTypeSubmitted:
+		return p.submitted[prerequisite.RequiredProblemID]
+	case PrerequisiteTypeScore:
+		return p.submitted[prerequisite.RequiredProblemID] && p.scores[prerequisite.RequiredProblemID] >= prerequisite.MinScore
+	}
+	return false
+}
+
+// applyProblemLocks 按用户的提交和得分判断题目是否解锁，填充解锁条件；未解锁的题目隐藏题面、提交点和附件
+// 需在渲染题面之后调用
+func applyProblemLocks(repos *repository.Repositories, userID uint, problems []model.Problem) error {
+	if len(problems) == 0 {
+		return nil
+	}
+	problemIDs := make([]uint, len(problems))
+	for i := range problems {
+		problemIDs[i] = problems[i].ID
+	}
+	prerequisites, err := repos.Problems.ListPrerequisites(problemIDs...)
+	if err != nil {
+		return err
+	}
+	byProblem := make(map[uint][]model.ProblemPrerequisite)
+	for _, p := range prerequisites {
+		byProblem[p.ProblemID] = append(byProblem[p.ProblemID], p)
+	}
+
+	var progress *unlockProgress
+	for i := range problems {
+		problem := &problems[i]
+		problem.Prerequisites = byProblem[problem.ID]
+		if len(problem.Prerequisites) == 0 {
+			continue
+		}
+		if progress == nil {
+			if progress, err = loadUnlockProgress(repos, userID); err != nil {
+				return err
+			}
+		}
+		for j := range problem.Prerequisites {
+			if !progress.satisfied(&problem.Prerequisites[j]) {
+				lockProblem(problem)
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// lockedProblemIDs 返回userID未解锁的全部题目ID，userID为0（未登录）时返回全部有解锁条件的题目
+func lockedProblemIDs(repos *repository.Repositories, userID uint) ([]uint, error) {
+	prerequisites, err := repos.Problems.ListPrerequisites()
+	if err != nil || len(prerequisites) == 0 {
+		return nil, err
+	}
+	progress, err := loadUnlockProgress(repos, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	locked := make(map[uint]bool)
+	var ids []uint
+	for i := range prerequisites {
+		p := &prerequisites[i]
+		if !locked[p.ProblemID] && !progress.satisfied(p) {
+			locked[p.ProblemID] = true
+			ids = append(ids, p.ProblemID)
+		}
+	}
+	return ids, nil
+}
+
+// lockProblem 将题目标记为未解锁并隐藏题面、提交点和附件，保留标题和解锁条件
+func lockProblem(problem *model.Problem) {
+	problem.Locked = true
+	problem.Description = ""
+	problem.DescriptionHTML = ""
+	problem.SubmissionPoints = nil
+	problem.Attachments = nil
+}
+
+// checkProblemUnlocked 检查用户是否已解锁题目
+func checkProblemUnlocked(repos *repository.Repositories, userID uint, problem *model.Problem) error {
+	problems := []model.Problem{{ID: problem.ID}}
+	if err := applyProblemLocks(repos, userID, problems); err != nil {
+		return err
+	}
+	if problems[0].Locked {
+		return errors.New("题目尚未解锁")
+	}
+	return nil
+}
+
+// GetUnlockStats 统计方向内各题目已解锁的选手人数，directionID为0时不限方向
+// 没有解锁条件的题目对全部选手解锁；已删除的用户和管理员不计入
+func (s *ProblemService) GetUnlockStats(directionID uint) ([]ProblemUnlockStat, error) {
+	problems, err := s.repos.Problems.List(directionID)
+	if err != nil {
+		return nil, err
+	}
+	candidates, err := s.repos.Users.CountCandidates()
+	if err != nil {
+		return nil, err
+	}
+	if err := applyProblemLocks(s.repos, 0, problems); err != nil {
+		return nil, err
+	}
+
+	checker := newUnlockChecker(s.repos)
+	stats := make([]ProblemUnlockStat, 0, len(problems))
+	for _, problem := range problems {
+		stat := ProblemUnlockStat{
+			ProblemID:      problem.ID,
+			Title:          problem.Title,
+			Status:         problem.Status,
+			Prerequisites:  problem.Prerequisites,
+			UnlockedCount:  candidates,
+			CandidateCount: candidates,
+		}
+		if stat.Prerequisites == nil {
+			stat.Prerequisites = []model.ProblemPrerequisite{}
+		}
+
+		unlocked, all, err := checker.unlockedCandidates(problem.Prerequisites)
+		if err != nil {
+			return nil, err
+		}
+		if !all {
+			stat.UnlockedCount = int64(len(unlocked))
+		}
+		stats = append(stats, stat)
+	}
+	return stats, nil
+}
+
+// unlockChecker 批量判断选手是否满足解锁条件，同一前置条件可能被多道题目引用，按条件缓存满足的选手
+type unlockChecker struct {
+	repos *repository.Repositories
+	cache map[unlockConditionKey]map[uint]bool
+}
+
+type unlockConditionKey struct {
+	problemID uint
+	typ       string
+	minScore  int
+}
+
+func newUnlockChecker(repos *repository.Repositories) *unlockChecker {
+	return &unlockChecker{repos: repos, cache: make(map[unlockConditionKey]map[uint]bool)}
+}
+
+// candidatesSatisfying 获取满足一条解锁条件的选手
+func (c *unlockChecker) candidatesSatisfying(p *model.ProblemPrerequisite) (map[uint]bool, error) {
+	key := unlockConditionKey{problemID: p.RequiredProblemID, typ: p.Type, minScore: p.MinScore}
+	if users, ok := c.cache[key]; ok {
+		return users, nil
+	}
+	var userIDs []uint
+	var err error
+	if p.Type == PrerequisiteTypeScore {
+		userIDs, err = c.repos.Scores.CandidateIDsWithMinScore(p.RequiredProblemID, p.MinScore, TeamScoreSplit())
+	} else {
+		userIDs, err = c.repos.Submissions.CandidateIDsByProblem(p.RequiredProblemID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	users := make(map[uint]bool, len(userIDs))
+	for _, id := range userIDs {
+		users[id] = true
+	}
+	c.cache[key] = users
+	return users, nil
+}
+
+// unlockedCandidates 获取满足全部解锁条件的选手；没有解锁条件时all为true，表示对全部选手解锁
+func (c *unlockChecker) unlockedCandidates(prerequisites []model.ProblemPrerequisite) (unlocked map[uint]bool, all bool, err error) {
+	if len(prerequisites) == 0 {
+		return nil, true, nil
+	}
+	for i := range prerequisites {
+		users, err := c.candidatesSatisfying(&prerequisites[i])
+		if err != nil {
+			return nil, false, err
+		}
+		if unlocked == nil {
+			unlocked = make(map[uint]bool, len(users))
+			for id := range users {
+				unlocked[id] = true
+			}
+			continue
+		}
+		for id := range unlocked {
+			if !users[id] {
+				delete(unlocked, id)
+			}
+		}
+	}
+	return unlocked, false, nil
+}
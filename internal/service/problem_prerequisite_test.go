@@ -0,0 +1,148 @@
+package service
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tksky1/glimgate/internal/model"
+	"github.com/tksky1/glimgate/pkg/storage"
+)
+
+// searchTitles 以选手身份搜索题目，返回命中的题目标题
+func searchTitles(t *testing.T, problems *ProblemService, keyword string, userID uint) []string {
+	t.Helper()
+	found, _, err := problems.SearchProblems(&SearchProblemsRequest{Keyword: keyword, UserID: userID}, true, 1, 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	titles := []string{}
+	for _, problem := range found {
+		titles = append(titles, problem.Title)
+	}
+	return titles
+}
+
+// TestLockedProblemContent 未解锁题目的附件、公开答疑和题面在各个入口都不可访问，满足条件后恢复
+func TestLockedProblemContent(t *testing.T) {
+	env := newTestEnv(t)
+	store, err := storage.NewLocal(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	problems := NewProblemService(env.repos, store, env.notifications, env.audit)
+	clarifications := NewClarificationService(env.db, env.directions, env.notifications)
+	user := env.createUser(t, "user", false)
+
+	first, point := env.createProblem(t, "第一关")
+	second, _ := env.createProblem(t, "第二关")
+	if err := env.db.Model(&second).Update("description", "题面中的秘密线索").Error; err != nil {
+		t.Fatal(err)
+	}
+	env.create(t, &model.ProblemPrerequisite{ProblemID: second.ID, RequiredProblemID: first.ID, Type: PrerequisiteTypeSubmitted})
+
+	attachment, err := problems.UploadAttachment(env.op, second.ID, "data.txt", "text/plain", strings.NewReader("隐藏数据"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	answeredAt := time.Now()
+	env.create(t, &model.Clarification{ProblemID: second.ID, AskerID: env.admin.ID, Question: "q", Answer: "答案提示了解法", AnswererID: &env.admin.ID, AnsweredAt: &answeredAt, IsPublic: true})
+
+	// 各入口的访问结果，nil表示成功
+	access := map[string]func(userID uint) error{
+		"按ID下载": func(userID uint) error {
+			_, file, err := problems.OpenAttachment(attachment.ID, userID, "")
+			if err == nil {
+				file.Close()
+			}
+			return err
+		},
+		"按文件名下载": func(userID uint) error {
+			_, file, err := problems.OpenAttachmentByName(second.ID, userID, "data.txt")
+			if err == nil {
+				file.Close()
+			}
+			return err
+		},
+		"生成签名链接": func(userID uint) error {
+			_, err := problems.SignAttachmentURL(attachment.ID, userID, 0, false)
+			return err
+		},
+		"公开答疑": func(userID uint) error {
+			_, err := clarifications.GetPublicClarifications(second.ID, userID)
+			return err
+		},
+	}
+
+	for name, open := range access {
+		for _, userID := range []uint{0, user.ID} {
+			if err := open(userID); err == nil || err.Error() != "题目尚未解锁" {
+				t.Errorf("%s: 用户%d未解锁时应返回题目尚未解锁，得到%v", name, userID, err)
+			}
+		}
+	}
+	if titles := searchTitles(t, problems, "秘密", user.ID); len(titles) != 0 {
+		t.Errorf("未解锁题目的题面不应参与搜索，命中%v", titles)
+	}
+	if titles := searchTitles(t, problems, "第二", user.ID); len(titles) != 1 || titles[0] != "第二关" {
+		t.Errorf("未解锁题目仍应能按标题搜索，命中%v", titles)
+	}
+
+	// 管理员预览和管理员搜索不受解锁条件限制
+	if _, err := problems.SignAttachmentURL(attachment.ID, env.admin.ID, 0, true); err != nil {
+		t.Errorf("管理员预览: %v", err)
+	}
+	found, _, err := problems.SearchProblems(&SearchProblemsRequest{Keyword: "秘密"}, false, 1, 20)
+	if err != nil || len(found) != 1 {
+		t.Errorf("管理员搜索应命中题面，得到%d个结果，%v", len(found), err)
+	}
+
+	env.create(t, &model.Submission{Content: "x", UserID: user.ID, ProblemID: first.ID, SubmissionPointID: point.ID})
+
+	for name, open := range access {
+		if err := open(user.ID); err != nil {
+			t.Errorf("%s: 解锁后应可访问，得到%v", name, err)
+		}
+		if err := open(0); err == nil || err.Error() != "题目尚未解锁" {
+			t.Errorf("%s: 未登录时仍应返回题目尚未解锁，得到%v", name, err)
+		}
+	}
+	_, file, err := problems.OpenAttachmentByName(second.ID, user.ID, "data.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	if content, _ := io.ReadAll(file); string(content) != "隐藏数据" {
+		t.Errorf("附件内容: %q", content)
+	}
+	if titles := searchTitles(t, problems, "秘密", user.ID); len(titles) != 1 || titles[0] != "第二关" {
+		t.Errorf("解锁后应能搜索题面，命中%v", titles)
+	}
+}
+
+// TestGetUnlockStats 同一前置题目的提交条件和得分条件分别统计
+func TestGetUnlockStats(t *testing.T) {
+	env := newTestEnv(t)
+	user := env.createUser(t, "user", false)
+	env.createUser(t, "idle", false)
+
+	first, point := env.createProblem(t, "第一关")
+	second, _ := env.createProblem(t, "第二关")
+	third, _ := env.createProblem(t, "第三关")
+	env.create(t, &model.ProblemPrerequisite{ProblemID: second.ID, RequiredProblemID: first.ID, Type: PrerequisiteTypeSubmitted})
+	env.create(t, &model.ProblemPrerequisite{ProblemID: third.ID, RequiredProblemID: first.ID, Type: PrerequisiteTypeScore, MinScore: 0})
+	env.create(t, &model.Submission{Content: "x", UserID: user.ID, ProblemID: first.ID, SubmissionPointID: point.ID})
+
+	stats, err := env.problems.GetUnlockStats(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 第一关没有解锁条件，对全部选手解锁；第三关要求得分，未评分的提交不满足
+	want := map[uint]int64{first.ID: 2, second.ID: 1, third.ID: 0}
+	for _, stat := range stats {
+		if stat.UnlockedCount != want[stat.ProblemID] {
+			t.Errorf("%s已解锁%d人，应为%d人", stat.Title, stat.UnlockedCount, want[stat.ProblemID])
+		}
+	}
+}
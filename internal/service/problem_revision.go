@@ -0,0 +1,293 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"unicode/utf8"
+
+	"github.com/tksky1/glimgate/internal/model"
+	"github.com/tksky1/glimgate/internal/repository"
+	"github.com/tksky1/glimgate/pkg/bundle"
+	"github.com/tksky1/glimgate/pkg/textdiff"
+)
+
+// maxRevisionNoteLen 修改说明的长度上限
+const maxRevisionNoteLen = 500
+
+// ProblemRevisionDiff 题目两个版本之间的差异
+type ProblemRevisionDiff struct {
+	ProblemID uint `json:"problem_id" example:"1"`
+	From      int  `json:"from" example:"1"`
+	To        int  `json:"to" example:"3"`
+	// Changes 标题和提交点设置的差异，题面有变化时包含一项description，具体差异见DescriptionDiff
+	Changes []BundleChange `json:"changes"`
+	// DescriptionDiff 题面的统一差异格式（unified diff），没有变化时为空
+	DescriptionDiff string `json:"description_diff" example:"--- v1\n+++ v3\n@@ -1 +1 @@\n-输入一行表达式\n+输入多行表达式，每行一个\n"`
+}
+
+// revisionNotice 重要修改需要通知的选手，在事务提交后发送
+type revisionNotice struct {
+	problem  *model.Problem
+	revision *model.ProblemRevision
+	userIDs  []uint
+}
+
+// checkRevisionNote 检查修改说明
+func checkRevisionNote(note string) error {
+	if utf8.RuneCountInString(note) > maxRevisionNoteLen {
+		return errors.New("修改说明不能超过500个字符")
+	}
+	return nil
+}
+
+// recordRevision 比较题目当前的标题、题面和提交点设置与最新版本，有变化时写入新版本并返回，没有变化时返回nil
+func recordRevision(tx *repository.Repositories, op *Operator, problemID uint, significant bool, note string) (*model.ProblemRevision, error) {
+	problem, err := tx.Problems.FindByID(problemID)
+	if err != nil {
+		return nil, err
+	}
+	points, err := tx.Problems.ListPoints(problemID)
+	if err != nil {
+		return nil, err
+	}
+	snapshot, err := marshalRevisionPoints(points)
+	if err != nil {
+		return nil, err
+	}
+
+	version := 1
+	latest, err := tx.Problems.LatestRevision(problemID)
+	switch {
+	case err == nil:
+		if latest.Title == problem.Title && latest.Description == problem.Description && latest.SubmissionPoints == snapshot {
+			return nil, nil
+		}
+		version = latest.Version + 1
+	case !errors.Is(err, repository.ErrNotFound):
+		return nil, err
+	}
+
+	revision := &model.ProblemRevision{
+		ProblemID:        problemID,
+		Version:          version,
+		Title:            problem.Title,
+		Description:      problem.Description,
+		SubmissionPoints: snapshot,
+		Significant:      significant,
+		Note:             note,
+		OperatorID:       op.UserID,
+		OperatorName:     op.Username,
+	}
+	if err := tx.Problems.CreateRevision(revision); err != nil {
+		return nil, err
+	}
+	return revision, nil
+}
+
+// saveRevision 记录修订；重要修改且题目对选手可见时更新题目的重要修改时间，并返回需要通知的已提交选手
+func saveRevision(tx *repository.Repositories, op *Operator, problemID uint, significant bool, note string) (*revisionNotice, error) {
+	revision, err := recordRevision(tx, op, problemID, significant, note)
+	if err != nil || revision == nil || !revision.Significant {
+		return nil, err
+	}
+
+	problem, err := tx.Problems.FindByID(problemID)
+	if err != nil {
+		return nil, err
+	}
+	if !problemVisible(problem) {
+		return nil, nil
+	}
+	if err := tx.Problems.Update(problem, map[string]interface{}{"statement_updated_at": revision.CreatedAt}); err != nil {
+		return nil, err
+	}
+
+	userIDs, err := tx.Submissions.CandidateIDsByProblem(problemID)
+	if err != nil {
+		return nil, err
+	}
+	return &revisionNotice{problem: problem, revision: revision, userIDs: userIDs}, nil
+}
+
+// notifyRevision 通知已提交的选手题目有重要修改，失败只记录日志
+func (s *ProblemService) notifyRevision(notice *revisionNotice) {
+	if notice == nil || len(notice.userIDs) == 0 {
+		return
+	}
+
+	title := fmt.Sprintf("题目《%s》已更新", notice.problem.Title)
+	content := fmt.Sprintf("题目《%s》的题面或提交要求有重要修改（版本%d），请重新阅读题目", notice.problem.Title, notice.revision.Version)
+	if notice.revision.Note != "" {
+		content += "。修改说明：" + notice.revision.Note
+	}
+
+	if err := s.notificationService.Notify(notice.userIDs, NotificationProblemUpdated, title, content, notice.problem.ID); err != nil {
+		log.Printf("发送题目更新通知失败: %v", err)
+	}
+}
+
+// marshalRevisionPoints 将提交点设置按ID顺序序列化为修订记录中的快照，截止时间统一为UTC以便比较
+func marshalRevisionPoints(points []model.SubmissionPoint) (string, error) {
+	sort.Slice(points, func(i, j int) bool { return points[i].ID < points[j].ID })
+	snapshot := make([]model.RevisionPoint, len(points))
+	for i, point := range points {
+		snapshot[i] = model.RevisionPoint{Name: point.Name, MaxScore: point.MaxScore, Rubric: point.Rubric}
+		if point.Deadline != nil {
+			deadline := point.Deadline.UTC()
+			snapshot[i].Deadline = &deadline
+		}
+	}
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// parseRevisionPoints 解析修订记录中的提交点快照
+func parseRevisionPoints(revision *model.ProblemRevision) error {
+	revision.Points = []model.RevisionPoint{}
+	if revision.SubmissionPoints == "" {
+		return nil
+	}
+	return json.Unmarshal([]byte(revision.SubmissionPoints), &revision.Points)
+}
+
+// GetRevisions 获取题目的修订记录，按版本从新到旧排列，不包含题面
+func (s *ProblemService) GetRevisions(problemID uint) ([]model.ProblemRevision, error) {
+	if _, err := s.repos.Problems.FindByID(problemID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, errors.New("题目不存在")
+		}
+		return nil, err
+	}
+
+	revisions, err := s.repos.Problems.ListRevisions(problemID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range revisions {
+		if err := parseRevisionPoints(&revisions[i]); err != nil {
+			return nil, err
+		}
+	}
+	return revisions, nil
+}
+
+// GetRevision 获取题目的指定版本
+func (s *ProblemService) GetRevision(problemID uint, version int) (*model.ProblemRevision, error) {
+	if _, err := s.repos.Problems.FindByID(problemID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, errors.New("题目不存在")
+		}
+		return nil, err
+	}
+	return s.findRevision(problemID, version)
+}
+
+// DiffRevisions 比较题目的两个版本，to为0时取最新版本，from为0时取to的上一个版本
+func (s *ProblemService) DiffRevisions(problemID uint, from, to int) (*ProblemRevisionDiff, error) {
+	if _, err := s.repos.Problems.FindByID(problemID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, errors.New("题目不存在")
+		}
+		return nil, err
+	}
+
+	var toRevision *model.ProblemRevision
+	var err error
+	if to == 0 {
+		toRevision, err = s.repos.Problems.LatestRevision(problemID)
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return nil, errors.New("版本不存在")
+			}
+			return nil, err
+		}
+		if err := parseRevisionPoints(toRevision); err != nil {
+			return nil, err
+		}
+	} else if toRevision, err = s.findRevision(problemID, to); err != nil {
+		return nil, err
+	}
+	if from == 0 {
+		from = toRevision.Version - 1
+	}
+	fromRevision, err := s.findRevision(problemID, from)
+	if err != nil {
+		return nil, err
+	}
+
+	return diffRevisions(fromRevision, toRevision), nil
+}
+
+// findRevision 查找并解析题目的指定版本
+func (s *ProblemService) findRevision(problemID uint, version int) (*model.ProblemRevision, error) {
+	revision, err := s.repos.Problems.FindRevision(problemID, version)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, errors.New("版本不存在")
+		}
+		return nil, err
+	}
+	if err := parseRevisionPoints(revision); err != nil {
+		return nil, err
+	}
+	return revision, nil
+}
+
+// diffRevisions 计算两个版本的差异，提交点按名称比较，与题目包导入的差异格式一致
+func diffRevisions(from, to *model.ProblemRevision) *ProblemRevisionDiff {
+	diff := &ProblemRevisionDiff{ProblemID: to.ProblemID, From: from.Version, To: to.Version, Changes: []BundleChange{}}
+	if from.Title != to.Title {
+		diff.Changes = append(diff.Changes, BundleChange{Path: "title", Action: BundleChangeUpdate, Before: from.Title, After: to.Title})
+	}
+	if from.Description != to.Description {
+		diff.Changes = append(diff.Changes, BundleChange{Path: "description", Action: BundleChangeUpdate})
+		diff.DescriptionDiff = textdiff.Unified(from.Description, to.Description,
+			fmt.Sprintf("v%d", from.Version), fmt.Sprintf("v%d", to.Version))
+	}
+
+	existing := make([]model.SubmissionPoint, len(from.Points))
+	for i, point := range from.Points {
+		existing[i] = model.SubmissionPoint{ID: uint(i + 1), Name: point.Name, MaxScore: point.MaxScore, Rubric: point.Rubric, Deadline: point.Deadline}
+	}
+	points := make([]bundle.Point, len(to.Points))
+	for i, point := range to.Points {
+		points[i] = bundle.Point{Name: point.Name, MaxScore: point.MaxScore, Rubric: point.Rubric, Deadline: point.Deadline}
+	}
+	_, pointChanges := diffBundlePoints(existing, points)
+	diff.Changes = append(diff.Changes, pointChanges...)
+	return diff
+}
+
+// markStatementUpdated 标记userID最后一次提交之后有重要修改的题目，未登录或未提交时不标记
+func markStatementUpdated(repos *repository.Repositories, userID uint, problems []model.Problem) error {
+	if userID == 0 {
+		return nil
+	}
+	var problemIDs []uint
+	for i := range problems {
+		if problems[i].StatementUpdatedAt != nil {
+			problemIDs = append(problemIDs, problems[i].ID)
+		}
+	}
+	if len(problemIDs) == 0 {
+		return nil
+	}
+
+	submittedAt, err := repos.Submissions.LastSubmittedAt(userID, problemIDs)
+	if err != nil {
+		return err
+	}
+	for i := range problems {
+		problem := &problems[i]
+		if last, ok := submittedAt[problem.ID]; ok && problem.StatementUpdatedAt != nil && last.Before(*problem.StatementUpdatedAt) {
+			problem.StatementUpdated = true
+		}
+	}
+	return nil
+}
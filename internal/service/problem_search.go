@@ -0,0 +1,155 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/tksky1/glimgate/internal/model"
+	"github.com/tksky1/glimgate/internal/repository"
+	"github.com/tksky1/glimgate/pkg/bundle"
+)
+
+// 题目搜索的排序字段
+const (
+	ProblemSortRelevance        = repository.ProblemSortRelevance
+	ProblemSortID               = "id"
+	ProblemSortTitle            = "title"
+	ProblemSortDifficulty       = "difficulty"
+	ProblemSortEstimatedMinutes = "estimated_minutes"
+	ProblemSortCreatedAt        = "created_at"
+	ProblemSortPublishedAt      = "published_at"
+)
+
+// maxSearchKeywordLen 搜索关键词的长度上限
+const maxSearchKeywordLen = 100
+
+// SearchProblemsRequest 题目搜索条件
+type SearchProblemsRequest struct {
+	Keyword       string   // 按空白分隔的关键词，每个都需出现在标题或描述中；选手侧未解锁的题目只匹配标题
+	DirectionID   uint     // 方向ID，0表示不限
+	Status        string   // 可见状态，选手侧为空时只搜索已发布的题目，管理员侧为空时不限
+	Tags          []string // 需同时包含的标签
+	MinDifficulty int      // 最低难度，0表示不限
+	MaxDifficulty int      // 最高难度，0表示不限
+	Sort          string   // 排序字段，为空时有关键词按相关度排序，否则按ID排序
+	Order         string   // asc或desc，默认asc，按相关度排序时忽略
+	UserID        uint     // 选手侧按该用户判断题目是否解锁，0表示未登录
+}
+
+// SearchProblems 搜索题目，visibleOnly为true时只搜索对选手可见的题目，且req.UserID未解锁的题目只按标题匹配关键词
+func (s *ProblemService) SearchProblems(req *SearchProblemsRequest, visibleOnly bool, page, pageSize int) ([]model.Problem, int64, error) {
+	filter := repository.ProblemSearchFilter{
+		DirectionID:   req.DirectionID,
+		Tags:          req.Tags,
+		MinDifficulty: req.MinDifficulty,
+		MaxDifficulty: req.MaxDifficulty,
+	}
+
+	switch {
+	case visibleOnly && req.Status == "":
+		filter.Statuses = []string{ProblemStatusPublished}
+	case visibleOnly && req.Status != ProblemStatusPublished && req.Status != ProblemStatusArchived:
+		return nil, 0, errors.New("题目状态无效")
+	case req.Status != "" && !ValidProblemStatus(req.Status):
+		return nil, 0, errors.New("题目状态无效")
+	case req.Status != "":
+		filter.Statuses = []string{req.Status}
+	}
+
+	if req.MinDifficulty < 0 || req.MaxDifficulty < 0 || req.MinDifficulty > bundle.MaxDifficulty || req.MaxDifficulty > bundle.MaxDifficulty ||
+		(req.MaxDifficulty > 0 && req.MinDifficulty > req.MaxDifficulty) {
+		return nil, 0, errors.New("难度范围无效")
+	}
+
+	if utf8.RuneCountInString(req.Keyword) > maxSearchKeywordLen {
+		return nil, 0, errors.New("搜索关键词过长")
+	}
+	filter.Keywords = strings.Fields(req.Keyword)
+	if visibleOnly && len(filter.Keywords) > 0 {
+		locked, err := lockedProblemIDs(s.repos, req.UserID)
+		if err != nil {
+			return nil, 0, err
+		}
+		filter.TitleOnlyIDs = locked
+	}
+
+	filter.Sort = req.Sort
+	if filter.Sort == "" && len(filter.Keywords) > 0 {
+		filter.Sort = ProblemSortRelevance
+	}
+	switch filter.Sort {
+	case "", ProblemSortRelevance, ProblemSortID, ProblemSortTitle, ProblemSortDifficulty,
+		ProblemSortEstimatedMinutes, ProblemSortCreatedAt, ProblemSortPublishedAt:
+	default:
+		return nil, 0, errors.New("排序字段无效")
+	}
+	switch req.Order {
+	case "", "asc":
+	case "desc":
+		filter.Desc = true
+	default:
+		return nil, 0, errors.New("排序方向无效")
+	}
+
+	problems, total, err := s.repos.Problems.Search(filter, (page-1)*pageSize, pageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+	renderProblems(problems)
+	if visibleOnly {
+		if err := applyCandidateView(s.repos, req.UserID, problems); err != nil {
+			return nil, 0, err
+		}
+	}
+	return problems, total, nil
+}
+
+// GetTags 获取标签及使用该标签的题目数量，visibleOnly为true时只统计已发布的题目
+func (s *ProblemService) GetTags(visibleOnly bool) ([]repository.TagCount, error) {
+	if visibleOnly {
+		return s.repos.Problems.ListTags(ProblemStatusPublished)
+	}
+	return s.repos.Problems.ListTags()
+}
+
+// checkProblemMeta 检查难度等级和预计用时
+func checkProblemMeta(difficulty, estimatedMinutes int) error {
+	if difficulty < 0 || difficulty > bundle.MaxDifficulty {
+		return errors.New("难度等级应为1到5")
+	}
+	if estimatedMinutes < 0 {
+		return errors.New("预计用时不能为负数")
+	}
+	return nil
+}
+
+// normalizeProblemTags 去除标签首尾空白，忽略空标签和重复标签，保持原有顺序
+func normalizeProblemTags(tags []string) ([]string, error) {
+	seen := make(map[string]bool, len(tags))
+	normalized := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag == "" || seen[tag] {
+			continue
+		}
+		if !bundle.ValidTag(tag) {
+			return nil, errors.New("标签不能包含逗号或超过50个字符")
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+	if len(normalized) > bundle.MaxTags {
+		return nil, errors.New("标签不能超过20个")
+	}
+	return normalized, nil
+}
+
+// problemTagNames 获取题目的标签名称
+func problemTagNames(problem *model.Problem) []string {
+	names := make([]string, len(problem.Tags))
+	for i, tag := range problem.Tags {
+		names[i] = tag.Name
+	}
+	return names
+}
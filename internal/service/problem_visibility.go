@@ -0,0 +1,289 @@
+package service
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"github.com/tksky1/glimgate/internal/model"
+	"github.com/tksky1/glimgate/internal/repository"
+)
+
+// 题目可见状态
+const (
+	ProblemStatusDraft     = "draft"
+	ProblemStatusScheduled = "scheduled"
+	ProblemStatusPublished = "published"
+	ProblemStatusArchived  = "archived"
+)
+
+// maxPublishSchedulerWait 定时发布任务的最长等待时间，glimgatectl等其他进程修改发布时间时无法唤醒任务，最迟在此时间后生效
+const maxPublishSchedulerWait = time.Minute
+
+// publishWakeup 定时发布时间变化时唤醒定时发布任务
+var publishWakeup = make(chan struct{}, 1)
+
+// schedulerOperator 定时发布任务在审计日志中的操作人
+var schedulerOperator = &Operator{Username: "scheduler"}
+
+// UpdateProblemStatusRequest 修改题目可见状态请求结构
+type UpdateProblemStatusRequest struct {
+	Status    string     `json:"status" binding:"required" example:"scheduled"`
+	PublishAt *time.Time `json:"publish_at" example:"2024-09-01T09:00:00+08:00"`
+}
+
+// ValidProblemStatus 检查题目状态是否合法
+func ValidProblemStatus(status string) bool {
+	switch status {
+	case ProblemStatusDraft, ProblemStatusScheduled, ProblemStatusPublished, ProblemStatusArchived:
+		return true
+	}
+	return false
+}
+
+// problemVisible 题目是否对选手可见，已归档的题目可见但不再接受提交和提问
+func problemVisible(problem *model.Problem) bool {
+	return problem.Status == ProblemStatusPublished || problem.Status == ProblemStatusArchived
+}
+
+// problemStatusFields 计算切换到目标状态时需要更新的字段
+func problemStatusFields(problem *model.Problem, status string, publishAt *time.Time, now time.Time) (map[string]interface{}, error) {
+	if !ValidProblemStatus(status) {
+		return nil, errors.New("题目状态无效")
+	}
+
+	updates := map[string]interface{}{"status": status}
+	switch status {
+	case ProblemStatusDraft:
+		updates["publish_at"] = nil
+	case ProblemStatusScheduled:
+		if publishAt == nil {
+			return nil, errors.New("定时发布需要指定发布时间")
+		}
+		if !publishAt.After(now) {
+			return nil, errors.New("定时发布时间必须晚于当前时间")
+		}
+		updates["publish_at"] = *publishAt
+	case ProblemStatusPublished:
+		updates["publish_at"] = nil
+		if problem.PublishedAt == nil {
+			updates["published_at"] = now
+		}
+	case ProblemStatusArchived:
+		if problem.Status != ProblemStatusPublished && problem.Status != ProblemStatusArchived {
+			return nil, errors.New("只有已发布的题目可以归档")
+		}
+		updates["publish_at"] = nil
+	}
+	return updates, nil
+}
+
+// GetVisibleProblems 获取对选手可见的题目列表，status为空时只返回已发布的题目
+// 按userID的提交和得分判断题目是否解锁，userID为0表示未登录
+func (s *ProblemService) GetVisibleProblems(directionID uint, status string, userID uint) ([]model.Problem, error) {
+	if status == "" {
+		status = ProblemStatusPublished
+	}
+	if status != ProblemStatusPublished && status != ProblemStatusArchived {
+		return nil, errors.New("题目状态无效")
+	}
+	problems, err := s.repos.Problems.List(directionID, status)
+	if err != nil {
+		return nil, err
+	}
+	renderProblems(problems)
+	if err := applyCandidateView(s.repos, userID, problems); err != nil {
+		return nil, err
+	}
+	return problems, nil
+}
+
+// GetVisibleProblem 获取对选手可见的题目详情，草稿和定时发布的题目视为不存在
+// userID未解锁题目时只返回标题和解锁条件
+func (s *ProblemService) GetVisibleProblem(problemID, userID uint) (*model.Problem, error) {
+	problem, err := s.GetProblemByID(problemID)
+	if err != nil {
+		return nil, err
+	}
+	if !problemVisible(problem) {
+		return nil, errors.New("题目不存在")
+	}
+
+	problems := []model.Problem{*problem}
+	if err := applyCandidateView(s.repos, userID, problems); err != nil {
+		return nil, err
+	}
+	return &problems[0], nil
+}
+
+// applyCandidateView 按用户处理选手看到的题目：判断是否解锁，并标记最后一次提交之后有重要修改的题目
+// 需在渲染题面之后调用
+func applyCandidateView(repos *repository.Repositories, userID uint, problems []model.Problem) error {
+	if err := applyProblemLocks(repos, userID, problems); err != nil {
+		return err
+	}
+	return markStatementUpdated(repos, userID, problems)
+}
+
+// findVisibleProblem 查找对选手可见的题目，不可见时视为不存在
+func findVisibleProblem(repos *repository.Repositories, problemID uint) (*model.Problem, error) {
+	problem, err := repos.Problems.FindByID(problemID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, errors.New("题目不存在")
+		}
+		return nil, err
+	}
+	if !problemVisible(problem) {
+		return nil, errors.New("题目不存在")
+	}
+	return problem, nil
+}
+
+// UpdateProblemStatus 修改题目可见状态
+// 定时发布需指定晚于当前的发布时间，到期后由定时发布任务自动发布；只有已发布的题目可以归档
+func (s *ProblemService) UpdateProblemStatus(op *Operator, problemID uint, req *UpdateProblemStatusRequest) (*model.Problem, error) {
+	var after *model.Problem
+	err := s.repos.Transaction(func(tx *repository.Repositories) error {
+		problem, err := tx.Problems.FindByID(problemID)
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return errors.New("题目不存在")
+			}
+			return err
+		}
+		before := *problem
+
+		updates, err := problemStatusFields(problem, req.Status, req.PublishAt, time.Now())
+		if err != nil {
+			return err
+		}
+		if err := tx.Problems.Update(problem, updates); err != nil {
+			return err
+		}
+
+		after, err = tx.Problems.FindByID(problem.ID, "Direction", "SubmissionPoints", "Tags")
+		if err != nil {
+			return err
+		}
+
+		return s.auditService.Record(tx.AuditLogs, op, AuditActionUpdate, AuditEntityProblem, problem.ID, before, after)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	wakePublishScheduler()
+	renderProblem(after)
+	return after, nil
+}
+
+// RunPublishScheduler 定时发布任务，启动时立即发布已到期的题目，之后等待到最近的发布时间
+// 发布时间保存在数据库中，服务重启后未发布的题目会在启动时补发
+func (s *ProblemService) RunPublishScheduler() {
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-timer.C:
+		case <-publishWakeup:
+			if !timer.Stop() {
+				select {
+				case <-timer.C:
+				default:
+				}
+			}
+		}
+
+		count, err := s.PublishDueProblems(time.Now())
+		if err != nil {
+			log.Printf("定时发布题目失败: %v", err)
+		}
+		if count > 0 {
+			log.Printf("定时发布题目 %d 道", count)
+		}
+
+		timer.Reset(s.nextPublishWait(err))
+	}
+}
+
+// nextPublishWait 计算距离最近一次定时发布的等待时间，不超过maxPublishSchedulerWait
+func (s *ProblemService) nextPublishWait(lastErr error) time.Duration {
+	if lastErr != nil {
+		return maxPublishSchedulerWait
+	}
+	next, err := s.repos.Problems.NextPublishAt(ProblemStatusScheduled)
+	if err != nil {
+		log.Printf("查询定时发布时间失败: %v", err)
+		return maxPublishSchedulerWait
+	}
+	if next == nil {
+		return maxPublishSchedulerWait
+	}
+	wait := time.Until(*next)
+	if wait < 0 {
+		wait = 0
+	}
+	if wait > maxPublishSchedulerWait {
+		wait = maxPublishSchedulerWait
+	}
+	return wait
+}
+
+// PublishDueProblems 发布所有定时发布时间不晚于now的题目，返回发布的数量
+// 每道题目在单独的事务中重新检查状态后发布，重复执行不会重复发布
+func (s *ProblemService) PublishDueProblems(now time.Time) (int, error) {
+	problems, err := s.repos.Problems.ListPublishDue(ProblemStatusScheduled, now)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, due := range problems {
+		published := false
+		err := s.repos.Transaction(func(tx *repository.Repositories) error {
+			problem, err := tx.Problems.FindByID(due.ID)
+			if err != nil {
+				if errors.Is(err, repository.ErrNotFound) {
+					return nil
+				}
+				return err
+			}
+			if problem.Status != ProblemStatusScheduled || problem.PublishAt == nil || problem.PublishAt.After(now) {
+				return nil
+			}
+			before := *problem
+
+			updates := map[string]interface{}{"status": ProblemStatusPublished, "publish_at": nil}
+			if problem.PublishedAt == nil {
+				updates["published_at"] = *problem.PublishAt
+			}
+			if err := tx.Problems.Update(problem, updates); err != nil {
+				return err
+			}
+
+			after, err := tx.Problems.FindByID(problem.ID)
+			if err != nil {
+				return err
+			}
+			published = true
+			return s.auditService.Record(tx.AuditLogs, schedulerOperator, AuditActionUpdate, AuditEntityProblem, problem.ID, before, after)
+		})
+		if err != nil {
+			return count, err
+		}
+		if published {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// wakePublishScheduler 唤醒定时发布任务重新计算等待时间，任务正忙时不阻塞
+func wakePublishScheduler() {
+	select {
+	case publishWakeup <- struct{}{}:
+	default:
+	}
+}
@@ -2,14 +2,20 @@ package service
 
 import (
 	"errors"
+	"fmt"
+	"log"
 
 	"github.com/tksky1/glimgate/internal/model"
-	"github.com/tksky1/glimgate/pkg/database"
-	"gorm.io/gorm"
+	"github.com/tksky1/glimgate/internal/repository"
 )
 
 // ScoreService 评分服务
-type ScoreService struct{}
+type ScoreService struct {
+	repos               *repository.Repositories
+	notificationService *NotificationService
+	webhookService      *WebhookService
+	auditService        *AuditService
+}
 
 // CreateScoreRequest 创建评分请求结构
 type CreateScoreRequest struct {
@@ -31,222 +37,316 @@ type RankingItem struct {
 	Score    int    `json:"score"`
 }
 
+// ProblemScore 用户在一道题目上的得分
+type ProblemScore struct {
+	ProblemID   uint   `json:"problem_id" example:"1"`
+	Title       string `json:"title" example:"实现一个简单的计算器"`
+	DirectionID uint   `json:"direction_id" example:"1"`
+	RawScore    int    `json:"raw_score" example:"90"`    // 全部评分者的评分之和，组队题目为队伍的评分之和
+	HintPenalty int    `json:"hint_penalty" example:"10"` // 解锁提示扣除的分数，组队题目为队伍的惩罚分
+	Score       int    `json:"score" example:"80"`        // 扣除提示惩罚分后计入排行榜的得分，不低于0
+}
+
 // NewScoreService 创建评分服务实例
-func NewScoreService() *ScoreService {
-	return &ScoreService{}
+func NewScoreService(repos *repository.Repositories, notificationService *NotificationService, webhookService *WebhookService, auditService *AuditService) *ScoreService {
+	return &ScoreService{
+		repos:               repos,
+		notificationService: notificationService,
+		webhookService:      webhookService,
+		auditService:        auditService,
+	}
 }
 
 // CreateScore 创建评分
-func (s *ScoreService) CreateScore(reviewerID uint, req *CreateScoreRequest) (*model.Score, error) {
-	db := database.GetDB()
-
-	// 获取提交信息
-	var submission model.Submission
-	if err := db.Preload("Problem").Preload("SubmissionPoint").First(&submission, req.SubmissionID).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("提交不存在")
+func (s *ScoreService) CreateScore(op *Operator, reviewerID uint, req *CreateScoreRequest) (*model.Score, error) {
+	var score *model.Score
+	var submission *model.Submission
+	isNew := false
+	err := s.repos.Transaction(func(tx *repository.Repositories) error {
+		// 获取提交信息
+		var err error
+		submission, err = tx.Submissions.FindByID(req.SubmissionID, "Problem", "SubmissionPoint")
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return errors.New("提交不存在")
+			}
+			return err
 		}
-		return nil, err
-	}
 
-	// 检查评分者是否为该方向的负责人
-	directionService := NewDirectionService()
-	isManager, err := directionService.CheckDirectionManager(submission.Problem.DirectionID, reviewerID)
-	if err != nil {
-		return nil, err
-	}
-	if !isManager {
-		return nil, errors.New("无权限评分该提交")
-	}
-
-	// 检查分数是否超过最大分值
-	if req.Score > submission.SubmissionPoint.MaxScore {
-		return nil, errors.New("评分不能超过最大分值")
-	}
+		// 检查评分者是否为该方向的负责人
+		isManager, err := tx.Directions.IsManager(submission.Problem.DirectionID, reviewerID)
+		if err != nil {
+			return err
+		}
+		if !isManager {
+			return errors.New("无权限评分该提交")
+		}
 
-	// 检查是否已有评分，如果有则更新，否则创建
-	var score model.Score
-	err = db.Where("submission_id = ? AND reviewer_id = ?", req.SubmissionID, reviewerID).First(&score).Error
+		// 检查分数是否超过最大分值
+		if req.Score > submission.SubmissionPoint.MaxScore {
+			return errors.New("评分不能超过最大分值")
+		}
 
-	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
-		return nil, err
-	}
+		// 变更前的评分，用于审计日志
+		var before interface{}
+		existing, err := tx.Scores.FindBySubmissionAndReviewer(req.SubmissionID, reviewerID)
+		if err == nil {
+			before = existing
+		} else if !errors.Is(err, repository.ErrNotFound) {
+			return err
+		}
 
-	if errors.Is(err, gorm.ErrRecordNotFound) {
-		// 创建新评分
-		score = model.Score{
+		// 按(提交, 评分者)创建或更新评分，并发重复评分只会保留一条记录
+		isNew, err = tx.Scores.Upsert(&model.Score{
 			Score:        req.Score,
 			Comment:      req.Comment,
 			UserID:       submission.UserID,
 			SubmissionID: req.SubmissionID,
 			ReviewerID:   reviewerID,
+		})
+		if err != nil {
+			return err
 		}
-		if err := db.Create(&score).Error; err != nil {
-			return nil, err
-		}
-	} else {
-		// 更新现有评分
-		updates := map[string]interface{}{
-			"score":   req.Score,
-			"comment": req.Comment,
+
+		// 加载关联数据
+		score, err = tx.Scores.FindBySubmissionAndReviewer(req.SubmissionID, reviewerID, "User", "Submission", "Reviewer")
+		if err != nil {
+			return err
 		}
-		if err := db.Model(&score).Updates(updates).Error; err != nil {
-			return nil, err
+
+		if isNew {
+			return s.auditService.Record(tx.AuditLogs, op, AuditActionCreate, AuditEntityScore, score.ID, nil, score)
 		}
+		return s.auditService.Record(tx.AuditLogs, op, AuditActionUpdate, AuditEntityScore, score.ID, before, score)
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	// 加载关联数据
-	if err := db.Preload("User").Preload("Submission").Preload("Reviewer").First(&score, score.ID).Error; err != nil {
-		return nil, err
+	if isNew {
+		s.notifyScore(NotificationScoreCreated, score, submission)
+		s.publishEvent(EventScoreCreated, score, submission.Problem.DirectionID)
+	} else {
+		s.notifyScore(NotificationScoreUpdated, score, submission)
+		s.publishEvent(EventScoreUpdated, score, submission.Problem.DirectionID)
 	}
 
-	return &score, nil
+	return score, nil
 }
 
-// GetScoresBySubmission 获取提交的评分列表
-func (s *ScoreService) GetScoresBySubmission(submissionID uint) ([]model.Score, error) {
-	db := database.GetDB()
-
-	var scores []model.Score
-	if err := db.Preload("User").Preload("Submission").Preload("Reviewer").Where("submission_id = ?", submissionID).Find(&scores).Error; err != nil {
-		return nil, err
+// notifyScore 通知被评分用户，组队提交通知全部队员，失败只记录日志
+func (s *ScoreService) notifyScore(notificationType string, score *model.Score, submission *model.Submission) {
+	title := "你的提交已被评分"
+	if notificationType == NotificationScoreUpdated {
+		title = "你的提交评分已更新"
+	}
+	content := fmt.Sprintf("题目《%s》的提交点「%s」获得 %d 分",
+		submission.Problem.Title, submission.SubmissionPoint.Name, score.Score)
+	if score.Comment != "" {
+		content += "，评语：" + score.Comment
 	}
 
-	return scores, nil
+	if err := s.notificationService.Notify(submissionRecipients(s.repos, submission), notificationType, title, content, submission.ID); err != nil {
+		log.Printf("发送评分通知失败: %v", err)
+	}
 }
 
-// GetScoresByUser 获取用户的评分列表
-func (s *ScoreService) GetScoresByUser(userID uint, problemID uint) ([]model.Score, error) {
-	db := database.GetDB()
-
-	query := db.Preload("User").Preload("Submission").Preload("Reviewer").Where("user_id = ?", userID)
-
-	if problemID > 0 {
-		// 需要通过submission表关联查询
-		query = query.Joins("JOIN submissions ON scores.submission_id = submissions.id").
-			Where("submissions.problem_id = ?", problemID)
+// publishEvent 发布评分相关的Webhook事件，失败只记录日志
+func (s *ScoreService) publishEvent(eventType string, score *model.Score, directionID uint) {
+	if err := s.webhookService.Publish(eventType, directionID, score); err != nil {
+		log.Printf("发布Webhook事件失败: %v", err)
 	}
+}
 
-	var scores []model.Score
-	if err := query.Find(&scores).Error; err != nil {
-		return nil, err
-	}
+// GetScoresBySubmission 获取提交的评分列表
+func (s *ScoreService) GetScoresBySubmission(submissionID uint) ([]model.Score, error) {
+	return s.repos.Scores.ListBySubmission(submissionID)
+}
 
-	return scores, nil
+// GetScoresByUser 获取用户的评分列表
+func (s *ScoreService) GetScoresByUser(userID uint, problemID uint) ([]model.Score, error) {
+	return s.repos.Scores.ListByUser(userID, problemID)
 }
 
 // GetScoresByReviewer 获取评分者的评分列表
 func (s *ScoreService) GetScoresByReviewer(reviewerID uint, problemID uint) ([]model.Score, error) {
-	db := database.GetDB()
+	return s.repos.Scores.ListByReviewer(reviewerID, problemID)
+}
 
-	query := db.Preload("User").Preload("Submission").Preload("Reviewer").Where("reviewer_id = ?", reviewerID)
+// GetProblemScores 按题目汇总用户的得分和提示惩罚分，directionID为0时不限方向
+// 只解锁了提示而尚未获得评分的题目也会列出，得分为0
+func (s *ScoreService) GetProblemScores(userID, directionID uint) ([]ProblemScore, error) {
+	totals, err := s.repos.Scores.ProblemTotalsByUser(userID, TeamScoreSplit())
+	if err != nil {
+		return nil, err
+	}
+	unlocks, err := s.repos.Problems.ListHintUnlocks(userID, 0)
+	if err != nil {
+		return nil, err
+	}
 
-	if problemID > 0 {
-		// 需要通过submission表关联查询
-		query = query.Joins("JOIN submissions ON scores.submission_id = submissions.id").
-			Where("submissions.problem_id = ?", problemID)
+	byProblem := make(map[uint]*ProblemScore)
+	var problemIDs []uint
+	entry := func(problemID uint) *ProblemScore {
+		if byProblem[problemID] == nil {
+			byProblem[problemID] = &ProblemScore{ProblemID: problemID}
+			problemIDs = append(problemIDs, problemID)
+		}
+		return byProblem[problemID]
+	}
+	scored := make(map[uint]bool, len(totals))
+	for _, total := range totals {
+		item := entry(total.ProblemID)
+		item.RawScore = total.RawScore
+		item.HintPenalty = total.Penalty
+		item.Score = total.Score
+		scored[total.ProblemID] = true
+	}
+	for _, unlock := range unlocks {
+		if item := entry(unlock.ProblemID); !scored[unlock.ProblemID] {
+			item.HintPenalty += unlock.Penalty
+		}
 	}
 
-	var scores []model.Score
-	if err := query.Find(&scores).Error; err != nil {
+	problems, err := s.repos.Problems.ListByIDs(problemIDs)
+	if err != nil {
 		return nil, err
 	}
-
+	scores := make([]ProblemScore, 0, len(problems))
+	for _, problem := range problems {
+		if directionID > 0 && problem.DirectionID != directionID {
+			continue
+		}
+		item := byProblem[problem.ID]
+		item.Title = problem.Title
+		item.DirectionID = problem.DirectionID
+		scores = append(scores, *item)
+	}
 	return scores, nil
 }
 
 // UpdateScore 更新评分
-func (s *ScoreService) UpdateScore(scoreID uint, reviewerID uint, req *UpdateScoreRequest) (*model.Score, error) {
-	db := database.GetDB()
+func (s *ScoreService) UpdateScore(op *Operator, scoreID uint, reviewerID uint, req *UpdateScoreRequest) (*model.Score, error) {
+	var score *model.Score
+	var submission *model.Submission
+	err := s.repos.Transaction(func(tx *repository.Repositories) error {
+		existing, err := tx.Scores.FindByIDAndReviewer(scoreID, reviewerID)
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return errors.New("评分不存在或无权限修改")
+			}
+			return err
+		}
+		before := *existing
 
-	var score model.Score
-	if err := db.Where("id = ? AND reviewer_id = ?", scoreID, reviewerID).First(&score).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("评分不存在或无权限修改")
+		// 获取提交点信息以检查最大分值
+		submission, err = tx.Submissions.FindByID(existing.SubmissionID, "Problem", "SubmissionPoint")
+		if err != nil {
+			return err
 		}
-		return nil, err
-	}
 
-	// 获取提交点信息以检查最大分值
-	var submission model.Submission
-	if err := db.Preload("SubmissionPoint").First(&submission, score.SubmissionID).Error; err != nil {
-		return nil, err
-	}
+		// 检查分数是否超过最大分值
+		if req.Score > submission.SubmissionPoint.MaxScore {
+			return errors.New("评分不能超过最大分值")
+		}
 
-	// 检查分数是否超过最大分值
-	if req.Score > submission.SubmissionPoint.MaxScore {
-		return nil, errors.New("评分不能超过最大分值")
-	}
+		// 更新评分
+		updates := map[string]interface{}{
+			"score":   req.Score,
+			"comment": req.Comment,
+		}
+		if err := tx.Scores.Update(existing, updates); err != nil {
+			return err
+		}
 
-	// 更新评分
-	updates := map[string]interface{}{
-		"score":   req.Score,
-		"comment": req.Comment,
-	}
-	if err := db.Model(&score).Updates(updates).Error; err != nil {
-		return nil, err
-	}
+		// 重新加载包含关联数据的评分
+		score, err = tx.Scores.FindByID(existing.ID, "User", "Submission", "Reviewer")
+		if err != nil {
+			return err
+		}
 
-	// 重新加载包含关联数据的评分
-	if err := db.Preload("User").Preload("Submission").Preload("Reviewer").First(&score, score.ID).Error; err != nil {
+		return s.auditService.Record(tx.AuditLogs, op, AuditActionUpdate, AuditEntityScore, score.ID, before, score)
+	})
+	if err != nil {
 		return nil, err
 	}
 
-	return &score, nil
+	s.notifyScore(NotificationScoreUpdated, score, submission)
+	s.publishEvent(EventScoreUpdated, score, submission.Problem.DirectionID)
+
+	return score, nil
 }
 
 // DeleteScore 删除评分
-func (s *ScoreService) DeleteScore(scoreID uint, reviewerID uint) error {
-	db := database.GetDB()
+func (s *ScoreService) DeleteScore(op *Operator, scoreID uint, reviewerID uint) error {
+	var score *model.Score
+	var submission *model.Submission
+	err := s.repos.Transaction(func(tx *repository.Repositories) error {
+		var err error
+		score, err = tx.Scores.FindByIDAndReviewer(scoreID, reviewerID)
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return errors.New("评分不存在或无权限删除")
+			}
+			return err
+		}
 
-	var score model.Score
-	if err := db.Where("id = ? AND reviewer_id = ?", scoreID, reviewerID).First(&score).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return errors.New("评分不存在或无权限删除")
+		submission, err = tx.Submissions.FindByID(score.SubmissionID, "Problem")
+		if err != nil {
+			return err
 		}
+
+		if err := tx.Scores.Delete(score); err != nil {
+			return err
+		}
+
+		return s.auditService.Record(tx.AuditLogs, op, AuditActionDelete, AuditEntityScore, score.ID, score, nil)
+	})
+	if err != nil {
 		return err
 	}
 
-	return db.Delete(&score).Error
+	s.publishEvent(EventScoreDeleted, score, submission.Problem.DirectionID)
+	return nil
 }
 
-// GetRanking 获取排行榜
-func (s *ScoreService) GetRanking(directionID uint, limit int) ([]RankingItem, error) {
-	db := database.GetDB()
-
-	var rankings []RankingItem
-
-	// 构建查询语句
-	query := `
-		SELECT 
-			u.id as user_id,
-			u.nickname,
-			COALESCE(SUM(s.score), 0) as score
-		FROM users u
-		LEFT JOIN scores s ON u.id = s.user_id
-		LEFT JOIN submissions sub ON s.submission_id = sub.id
-		LEFT JOIN problems p ON sub.problem_id = p.id
-	`
-
-	args := []interface{}{}
-	if directionID > 0 {
-		query += " WHERE p.direction_id = ?"
-		args = append(args, directionID)
+// countedScores 返回计入总分的评分：同一提交有人工评分时，自动评测的系统评分不计入
+func countedScores(scores []model.Score) []model.Score {
+	reviewed := false
+	for _, score := range scores {
+		if score.ReviewerID != model.JudgeReviewerID {
+			reviewed = true
+			break
+		}
+	}
+	if !reviewed {
+		return scores
 	}
 
-	query += `
-		GROUP BY u.id, u.nickname
-		ORDER BY score DESC
-	`
-
-	if limit > 0 {
-		query += " LIMIT ?"
-		args = append(args, limit)
+	counted := make([]model.Score, 0, len(scores))
+	for _, score := range scores {
+		if score.ReviewerID != model.JudgeReviewerID {
+			counted = append(counted, score)
+		}
 	}
+	return counted
+}
 
-	if err := db.Raw(query, args...).Scan(&rankings).Error; err != nil {
+// GetRanking 获取排行榜
+func (s *ScoreService) GetRanking(directionID uint, limit int) ([]RankingItem, error) {
+	rows, err := s.repos.Scores.Ranking(directionID, limit, TeamScoreSplit())
+	if err != nil {
 		return nil, err
 	}
 
+	rankings := make([]RankingItem, 0, len(rows))
+	for _, row := range rows {
+		rankings = append(rankings, RankingItem{
+			UserID:   row.UserID,
+			Nickname: row.Nickname,
+			Score:    row.Score,
+		})
+	}
+
 	return rankings, nil
-}
\ No newline at end of file
+}
@@ -0,0 +1,191 @@
+package service
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/tksky1/glimgate/internal/model"
+	"github.com/tksky1/glimgate/internal/repository"
+	"github.com/tksky1/glimgate/pkg/sheet"
+)
+
+// scoreSheetColumn 评分汇总表中某个提交点的评分列，每个给该提交点评过分的评分者一列
+type scoreSheetColumn struct {
+	point     model.SubmissionPoint
+	title     string
+	reviewers []model.User
+}
+
+// scoreSheetRow 评分汇总表中的一位候选人
+type scoreSheetRow struct {
+	user    model.User
+	total   int
+	penalty int
+	rank    int
+	scores  map[uint]map[uint]model.Score // 提交点ID -> 评分者ID -> 评分
+}
+
+// ResolveExportScope 解析评分导出范围
+func (s *ScoreService) ResolveExportScope(directionID, problemID uint) (*ExportScope, error) {
+	return resolveExportScope(s.repos, directionID, problemID)
+}
+
+// ExportScoreSheet 将导出范围内的评分汇总为宽表写入w
+// 每位有提交的候选人一行，按扣除提示惩罚分后的总分排名；每个提交点的每位评分者一列分数，另有一列汇总该提交点的评语
+// 自动评测的系统评分单独一列，有人工评分时不计入总分
+func (s *ScoreService) ExportScoreSheet(scope *ExportScope, format string, w io.Writer) error {
+	submissions, err := listExportSubmissions(s.repos, scope)
+	if err != nil {
+		return err
+	}
+	penalties, err := s.repos.Problems.HintPenalties(scope.problemIDs())
+	if err != nil {
+		return err
+	}
+
+	columns := buildScoreSheetColumns(scope, submissions)
+	rows := buildScoreSheetRows(submissions, penalties)
+
+	writer, err := sheet.NewWriter(w, format)
+	if err != nil {
+		return err
+	}
+
+	header := []string{"排名", "用户ID", "用户名", "昵称", "姓名", "学院", "学号", "总分", "提示扣分"}
+	for _, column := range columns {
+		for _, reviewer := range column.reviewers {
+			header = append(header, fmt.Sprintf("%s/%s", column.title, reviewer.Nickname))
+		}
+		header = append(header, column.title+"/评语")
+	}
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		record := []string{
+			strconv.Itoa(row.rank),
+			strconv.FormatUint(uint64(row.user.ID), 10),
+			row.user.Username,
+			row.user.Nickname,
+			row.user.RealName,
+			row.user.College,
+			row.user.StudentID,
+			strconv.Itoa(row.total),
+			strconv.Itoa(row.penalty),
+		}
+		for _, column := range columns {
+			pointScores := row.scores[column.point.ID]
+			var comments []string
+			for _, reviewer := range column.reviewers {
+				score, ok := pointScores[reviewer.ID]
+				if !ok {
+					record = append(record, "")
+					continue
+				}
+				record = append(record, strconv.Itoa(score.Score))
+				if score.Comment != "" {
+					comments = append(comments, fmt.Sprintf("%s：%s", reviewer.Nickname, score.Comment))
+				}
+			}
+			record = append(record, strings.Join(comments, "\n"))
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	return writer.Close()
+}
+
+// buildScoreSheetColumns 按题目和提交点顺序生成评分列，评分者按ID排序
+func buildScoreSheetColumns(scope *ExportScope, submissions []model.Submission) []scoreSheetColumn {
+	reviewersByPoint := make(map[uint]map[uint]model.User)
+	for _, submission := range submissions {
+		for _, score := range submission.Scores {
+			if reviewersByPoint[submission.SubmissionPointID] == nil {
+				reviewersByPoint[submission.SubmissionPointID] = make(map[uint]model.User)
+			}
+			reviewer := score.Reviewer
+			if score.ReviewerID == model.JudgeReviewerID {
+				reviewer = model.User{Nickname: judgeReviewerName}
+			}
+			reviewersByPoint[submission.SubmissionPointID][score.ReviewerID] = reviewer
+		}
+	}
+
+	var columns []scoreSheetColumn
+	for _, problem := range scope.problems {
+		for _, point := range problem.SubmissionPoints {
+			column := scoreSheetColumn{
+				point: point,
+				title: fmt.Sprintf("%s/%s(满分%d)", problem.Title, point.Name, point.MaxScore),
+			}
+			for _, reviewer := range reviewersByPoint[point.ID] {
+				column.reviewers = append(column.reviewers, reviewer)
+			}
+			sort.Slice(column.reviewers, func(i, j int) bool { return column.reviewers[i].ID < column.reviewers[j].ID })
+			columns = append(columns, column)
+		}
+	}
+	return columns
+}
+
+// buildScoreSheetRows 按候选人汇总评分并计算排名，总分相同的候选人名次相同
+// 每道题目的得分扣除该候选人在该题解锁提示的惩罚分，不低于0，与排行榜一致
+func buildScoreSheetRows(submissions []model.Submission, penalties []repository.HintPenalty) []scoreSheetRow {
+	type userProblem struct{ userID, problemID uint }
+	penaltyOf := make(map[userProblem]int, len(penalties))
+	for _, p := range penalties {
+		penaltyOf[userProblem{p.UserID, p.ProblemID}] = p.Penalty
+	}
+
+	var rows []scoreSheetRow
+	index := make(map[uint]int)
+	problemScores := make(map[userProblem]int)
+	for _, submission := range submissions {
+		i, ok := index[submission.UserID]
+		if !ok {
+			i = len(rows)
+			index[submission.UserID] = i
+			rows = append(rows, scoreSheetRow{user: submission.User, scores: make(map[uint]map[uint]model.Score)})
+		}
+
+		key := userProblem{submission.UserID, submission.ProblemID}
+		if _, ok := problemScores[key]; !ok {
+			problemScores[key] = 0
+			rows[i].penalty += penaltyOf[key]
+		}
+		pointScores := make(map[uint]model.Score)
+		for _, score := range submission.Scores {
+			pointScores[score.ReviewerID] = score
+		}
+		for _, score := range countedScores(submission.Scores) {
+			problemScores[key] += score.Score
+		}
+		rows[i].scores[submission.SubmissionPointID] = pointScores
+	}
+	for key, score := range problemScores {
+		if score -= penaltyOf[key]; score > 0 {
+			rows[index[key.userID]].total += score
+		}
+	}
+
+	sort.SliceStable(rows, func(i, j int) bool {
+		if rows[i].total != rows[j].total {
+			return rows[i].total > rows[j].total
+		}
+		return rows[i].user.ID < rows[j].user.ID
+	})
+	for i := range rows {
+		if i > 0 && rows[i].total == rows[i-1].total {
+			rows[i].rank = rows[i-1].rank
+		} else {
+			rows[i].rank = i + 1
+		}
+	}
+	return rows
+}
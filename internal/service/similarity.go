@@ -0,0 +1,349 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/tksky1/glimgate/internal/model"
+	"github.com/tksky1/glimgate/internal/repository"
+	"github.com/tksky1/glimgate/pkg/config"
+	"github.com/tksky1/glimgate/pkg/similarity"
+)
+
+// 相似度检测方法
+const (
+	SimilarityMethodCode       = "code"
+	SimilarityMethodText       = "text"
+	SimilarityMethodLink       = "link"
+	SimilarityMethodRepository = "repository"
+)
+
+// similarityMinFingerprints 指纹少于该数量的内容过短，不参与代码和文本比较，避免简短回答互相误报
+const similarityMinFingerprints = 10
+
+// similarityDetailAuthors 报告说明中最多列出的共同作者数
+const similarityDetailAuthors = 5
+
+// SimilarityService 相似度检测服务
+type SimilarityService struct {
+	repos *repository.Repositories
+}
+
+// SimilarityResult 相似度检测结果
+type SimilarityResult struct {
+	Submissions int     `json:"submissions" example:"42"` // 参与比较的提交数
+	Reports     int     `json:"reports" example:"15"`     // 保存的相似度报告数
+	Flagged     int     `json:"flagged" example:"4"`      // 标记为可疑的提交数
+	Threshold   float64 `json:"threshold" example:"0.8"`
+}
+
+// SimilarSubmission 可疑分组中的提交
+type SimilarSubmission struct {
+	ID       uint    `json:"id" example:"3"`
+	UserID   uint    `json:"user_id" example:"5"`
+	Nickname string  `json:"nickname" example:"小明"`
+	RealName string  `json:"real_name" example:"张三"`
+	Score    float64 `json:"similarity_score" example:"0.92"` // 该提交的最高相似度
+}
+
+// SimilarityCluster 相似度达到阈值的提交连成的分组
+type SimilarityCluster struct {
+	MaxScore    float64                  `json:"max_score" example:"0.92"`
+	Submissions []SimilarSubmission      `json:"submissions"`
+	Reports     []model.SimilarityReport `json:"reports"` // 分组内达到阈值的提交对，不含提交内容
+}
+
+// NewSimilarityService 创建相似度检测服务实例
+func NewSimilarityService(repos *repository.Repositories) *SimilarityService {
+	return &SimilarityService{repos: repos}
+}
+
+// similarityProfile 参与比较的提交特征
+type similarityProfile struct {
+	submissionID uint
+	link         string          // 规范化的链接或仓库地址
+	commit       string          // 代码仓库检查时实际检出的提交
+	authors      map[string]bool // 代码仓库提交历史中的作者邮箱
+	code         similarity.Fingerprint
+	text         similarity.Fingerprint
+}
+
+// Analyze 比较提交点下的全部提交，替换原有的相似度报告并更新各提交的可疑标记
+// 代码仓库提交点比较仓库地址、检出的提交和提交作者；内容只是一个链接时比较链接；其他内容按代码或文本指纹比较
+func (s *SimilarityService) Analyze(pointID uint) (*SimilarityResult, error) {
+	cfg := similarityConfig()
+	point, err := s.repos.Problems.FindPointByID(pointID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, errors.New("提交点不存在")
+		}
+		return nil, err
+	}
+	submissions, err := s.repos.Submissions.ListByPoint(pointID, "CheckRun")
+	if err != nil {
+		return nil, err
+	}
+
+	ignored := make(map[string]bool, len(cfg.IgnoredAuthors))
+	for _, email := range cfg.IgnoredAuthors {
+		ignored[strings.ToLower(email)] = true
+	}
+	profiles := make([]similarityProfile, len(submissions))
+	for i := range submissions {
+		profiles[i] = newSimilarityProfile(point, &submissions[i], ignored)
+	}
+
+	// 提交按ID排序，报告中SubmissionAID总是较小的一方
+	var reports []model.SimilarityReport
+	scores := make(map[uint]float64)
+	for i := range profiles {
+		for j := i + 1; j < len(profiles); j++ {
+			method, score, detail := compareProfiles(point, &profiles[i], &profiles[j])
+			score = math.Round(score*1000) / 1000
+			if score == 0 || score < cfg.MinScore {
+				continue
+			}
+			a, b := profiles[i].submissionID, profiles[j].submissionID
+			reports = append(reports, model.SimilarityReport{
+				SubmissionPointID: pointID,
+				SubmissionAID:     a,
+				SubmissionBID:     b,
+				Method:            method,
+				Score:             score,
+				Detail:            detail,
+			})
+			scores[a] = max(scores[a], score)
+			scores[b] = max(scores[b], score)
+		}
+	}
+
+	err = s.repos.Transaction(func(tx *repository.Repositories) error {
+		if err := tx.Similarity.ReplaceReports(pointID, reports); err != nil {
+			return err
+		}
+		return tx.Similarity.UpdateFlags(pointID, scores, cfg.Threshold)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := &SimilarityResult{Submissions: len(submissions), Reports: len(reports), Threshold: cfg.Threshold}
+	for _, score := range scores {
+		if score >= cfg.Threshold {
+			result.Flagged++
+		}
+	}
+	return result, nil
+}
+
+// newSimilarityProfile 提取提交的比较特征
+func newSimilarityProfile(point *model.SubmissionPoint, submission *model.Submission, ignoredAuthors map[string]bool) similarityProfile {
+	profile := similarityProfile{submissionID: submission.ID}
+	content := strings.TrimSpace(submission.Content)
+
+	if point.Type == SubmissionPointGit {
+		if repoURL, _, err := parseGitSubmission(content); err == nil {
+			profile.link = normalizeLink(repoURL)
+		}
+		if run := submission.CheckRun; run != nil && run.Status == repository.CheckStatusFinished {
+			profile.commit = run.Commit
+			profile.authors = make(map[string]bool)
+			for _, email := range strings.Fields(run.Authors) {
+				if !ignoredAuthors[email] {
+					profile.authors[email] = true
+				}
+			}
+		}
+		return profile
+	}
+
+	if isLink(content) {
+		profile.link = normalizeLink(content)
+		return profile
+	}
+	if point.Type == SubmissionPointAuto || similarity.LooksLikeCode(content) {
+		profile.code = similarity.Code(content)
+	}
+	profile.text = similarity.Text(content)
+	return profile
+}
+
+// compareProfiles 比较两个提交，返回使用的方法、相似度和说明
+func compareProfiles(point *model.SubmissionPoint, a, b *similarityProfile) (string, float64, string) {
+	if point.Type == SubmissionPointGit {
+		switch {
+		case a.link != "" && a.link == b.link:
+			return SimilarityMethodRepository, 1, "相同仓库地址"
+		case a.commit != "" && a.commit == b.commit:
+			return SimilarityMethodRepository, 1, "检出相同的提交 " + a.commit
+		}
+		var shared []string
+		for email := range a.authors {
+			if b.authors[email] {
+				shared = append(shared, email)
+			}
+		}
+		if len(shared) == 0 {
+			return SimilarityMethodRepository, 0, ""
+		}
+		sort.Strings(shared)
+		score := float64(len(shared)) / float64(len(a.authors)+len(b.authors)-len(shared))
+		detail := "共同提交作者: " + strings.Join(shared[:min(len(shared), similarityDetailAuthors)], ", ")
+		if len(shared) > similarityDetailAuthors {
+			detail += fmt.Sprintf(" 等%d人", len(shared))
+		}
+		return SimilarityMethodRepository, score, detail
+	}
+
+	if a.link != "" || b.link != "" {
+		if a.link == b.link {
+			return SimilarityMethodLink, 1, "相同链接"
+		}
+		return SimilarityMethodLink, 0, ""
+	}
+	if len(a.code) >= similarityMinFingerprints && len(b.code) >= similarityMinFingerprints {
+		score, shared := similarity.Jaccard(a.code, b.code)
+		return SimilarityMethodCode, score, fmt.Sprintf("共同代码指纹%d个", shared)
+	}
+	if len(a.text) >= similarityMinFingerprints && len(b.text) >= similarityMinFingerprints {
+		score, shared := similarity.Jaccard(a.text, b.text)
+		return SimilarityMethodText, score, fmt.Sprintf("共同文本片段%d个", shared)
+	}
+	return SimilarityMethodText, 0, ""
+}
+
+// isLink 判断内容是否只是一个http(s)链接
+func isLink(content string) bool {
+	if content == "" || strings.ContainsAny(content, " \t\r\n") {
+		return false
+	}
+	u, err := url.Parse(content)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// normalizeLink 规范化链接，忽略协议、大小写、www前缀、末尾的斜杠和.git后缀
+func normalizeLink(link string) string {
+	u, err := url.Parse(strings.ToLower(link))
+	if err != nil {
+		return link
+	}
+	normalized := strings.TrimPrefix(u.Host, "www.") + strings.TrimSuffix(strings.TrimRight(u.Path, "/"), ".git")
+	if u.RawQuery != "" {
+		normalized += "?" + u.RawQuery
+	}
+	return normalized
+}
+
+// GetClusters 获取提交点下相似度达到阈值的提交分组，按分组内的最高相似度从高到低排列
+// 阈值低于配置的min_score时，低于min_score的提交对没有报告，不会出现在分组中
+func (s *SimilarityService) GetClusters(pointID uint, threshold float64) ([]SimilarityCluster, error) {
+	if threshold <= 0 {
+		threshold = similarityConfig().Threshold
+	}
+	if _, err := s.repos.Problems.FindPointByID(pointID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, errors.New("提交点不存在")
+		}
+		return nil, err
+	}
+	reports, err := s.repos.Similarity.ListByPoint(pointID, threshold)
+	if err != nil {
+		return nil, err
+	}
+
+	// 按提交对合并分组
+	parent := make(map[uint]uint)
+	var find func(id uint) uint
+	find = func(id uint) uint {
+		if parent[id] == 0 || parent[id] == id {
+			parent[id] = id
+			return id
+		}
+		parent[id] = find(parent[id])
+		return parent[id]
+	}
+	submissions := make(map[uint]*model.Submission)
+	var valid []model.SimilarityReport
+	for _, report := range reports {
+		// 已删除的提交不再预加载，跳过相关的报告
+		if report.SubmissionA == nil || report.SubmissionB == nil {
+			continue
+		}
+		submissions[report.SubmissionAID] = report.SubmissionA
+		submissions[report.SubmissionBID] = report.SubmissionB
+		parent[find(report.SubmissionAID)] = find(report.SubmissionBID)
+		valid = append(valid, report)
+	}
+
+	clusters := make(map[uint]*SimilarityCluster)
+	var order []uint
+	for _, report := range valid {
+		root := find(report.SubmissionAID)
+		cluster, ok := clusters[root]
+		if !ok {
+			cluster = &SimilarityCluster{}
+			clusters[root] = cluster
+			order = append(order, root)
+		}
+		cluster.MaxScore = max(cluster.MaxScore, report.Score)
+		report.SubmissionA, report.SubmissionB = nil, nil
+		cluster.Reports = append(cluster.Reports, report)
+	}
+	for id, submission := range submissions {
+		cluster := clusters[find(id)]
+		cluster.Submissions = append(cluster.Submissions, SimilarSubmission{
+			ID:       submission.ID,
+			UserID:   submission.UserID,
+			Nickname: submission.User.Nickname,
+			RealName: submission.User.RealName,
+			Score:    submission.SimilarityScore,
+		})
+	}
+
+	// 报告已按相似度从高到低排列，分组按首次出现的顺序即为按最高相似度排序
+	result := make([]SimilarityCluster, 0, len(order))
+	for _, root := range order {
+		cluster := clusters[root]
+		sort.Slice(cluster.Submissions, func(i, j int) bool { return cluster.Submissions[i].ID < cluster.Submissions[j].ID })
+		result = append(result, *cluster)
+	}
+	return result, nil
+}
+
+// GetSubmissionReports 获取涉及某个提交的相似度报告及双方提交，按相似度从高到低排列
+func (s *SimilarityService) GetSubmissionReports(submissionID uint) ([]model.SimilarityReport, error) {
+	if _, err := s.repos.Submissions.FindByID(submissionID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, errors.New("提交不存在")
+		}
+		return nil, err
+	}
+	reports, err := s.repos.Similarity.ListBySubmission(submissionID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]model.SimilarityReport, 0, len(reports))
+	for _, report := range reports {
+		if report.SubmissionA != nil && report.SubmissionB != nil {
+			result = append(result, report)
+		}
+	}
+	return result, nil
+}
+
+// similarityConfig 获取相似度检测配置，未配置的项使用默认值
+func similarityConfig() config.SimilarityConfig {
+	cfg := config.AppConfig.Similarity
+	if cfg.Threshold <= 0 || cfg.Threshold > 1 {
+		cfg.Threshold = 0.8
+	}
+	if cfg.MinScore <= 0 || cfg.MinScore > cfg.Threshold {
+		cfg.MinScore = min(0.3, cfg.Threshold)
+	}
+	return cfg
+}
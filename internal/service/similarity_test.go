@@ -0,0 +1,123 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/tksky1/glimgate/internal/model"
+)
+
+const similarityCode = `func bubbleSort(nums []int) []int {
+	n := len(nums)
+	for i := 0; i < n; i++ {
+		swapped := false
+		for j := 0; j < n-i-1; j++ {
+			if nums[j] > nums[j+1] {
+				nums[j], nums[j+1] = nums[j+1], nums[j]
+				swapped = true
+			}
+		}
+		if !swapped {
+			break
+		}
+	}
+	return nums
+}`
+
+const similarityExtraCode = `func (s *server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	handler, ok := s.routes[strings.TrimSuffix(r.URL.Path, "/")]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	handler(w, r)
+}`
+
+// clusterIDs 返回各分组中的提交ID
+func clusterIDs(clusters []SimilarityCluster) [][]uint {
+	ids := [][]uint{}
+	for _, cluster := range clusters {
+		var group []uint
+		for _, submission := range cluster.Submissions {
+			group = append(group, submission.ID)
+		}
+		ids = append(ids, group)
+	}
+	return ids
+}
+
+func TestSimilarityClusters(t *testing.T) {
+	env := newTestEnv(t)
+	similarities := NewSimilarityService(env.repos)
+	_, point := env.createProblem(t, "相似度")
+
+	contents := []string{
+		"https://github.com/user/project",
+		"https://www.GitHub.com/user/project.git/",
+		"http://github.com/user/project",
+		similarityCode,
+		similarityCode + "\n\n" + similarityExtraCode,
+		"本题可以用哈希表记录每个数字出现的位置，遍历一次即可找到答案，空间换时间。",
+	}
+	ids := make([]uint, len(contents))
+	for i, content := range contents {
+		user := env.createUser(t, "user"+string(rune('a'+i)), false)
+		submission := model.Submission{Content: content, UserID: user.ID, ProblemID: point.ProblemID, SubmissionPointID: point.ID}
+		env.create(t, &submission)
+		ids[i] = submission.ID
+	}
+
+	result, err := similarities.Analyze(point.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 链接两两相同共3对，代码部分抄袭1对
+	if result.Submissions != 6 || result.Reports != 4 || result.Flagged != 3 {
+		t.Errorf("检测结果: %+v", result)
+	}
+
+	// 默认阈值下只有相同链接的提交成组，组内三对报告经合并连成一组
+	clusters, err := similarities.GetClusters(point.ID, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := clusterIDs(clusters); !reflect.DeepEqual(got, [][]uint{ids[0:3]}) {
+		t.Fatalf("默认阈值下的分组为%v", got)
+	}
+	if cluster := clusters[0]; cluster.MaxScore != 1 || len(cluster.Reports) != 3 || cluster.Submissions[0].Nickname != "usera" || cluster.Submissions[0].Score != 1 {
+		t.Errorf("分组内容: %+v", cluster)
+	}
+	for _, report := range clusters[0].Reports {
+		if report.Method != SimilarityMethodLink || report.SubmissionA != nil || report.SubmissionB != nil {
+			t.Errorf("报告应为相同链接且不含提交内容: %+v", report)
+		}
+	}
+
+	// 降低阈值后代码相似的提交另成一组，按最高相似度排在后面
+	clusters, err = similarities.GetClusters(point.ID, 0.4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := clusterIDs(clusters); !reflect.DeepEqual(got, [][]uint{ids[0:3], ids[3:5]}) {
+		t.Fatalf("阈值0.4下的分组为%v", got)
+	}
+	if report := clusters[1].Reports[0]; report.Method != SimilarityMethodCode || report.Score >= 0.8 || clusters[1].MaxScore != report.Score {
+		t.Errorf("代码相似的报告: %+v", report)
+	}
+
+	// 已删除的提交不再出现在分组中
+	if err := env.db.Delete(&model.Submission{}, ids[1]).Error; err != nil {
+		t.Fatal(err)
+	}
+	clusters, err = similarities.GetClusters(point.ID, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := clusterIDs(clusters); !reflect.DeepEqual(got, [][]uint{{ids[0], ids[2]}}) {
+		t.Errorf("删除提交后的分组为%v", got)
+	}
+
+	if _, err := similarities.GetClusters(point.ID+100, 0); err == nil || err.Error() != "提交点不存在" {
+		t.Errorf("提交点不存在时应返回错误，得到%v", err)
+	}
+}
@@ -2,14 +2,19 @@ package service
 
 import (
 	"errors"
+	"fmt"
+	"log"
 
 	"github.com/tksky1/glimgate/internal/model"
-	"github.com/tksky1/glimgate/pkg/database"
-	"gorm.io/gorm"
+	"github.com/tksky1/glimgate/internal/repository"
 )
 
 // SubmissionService 提交服务
-type SubmissionService struct{}
+type SubmissionService struct {
+	repos               *repository.Repositories
+	notificationService *NotificationService
+	webhookService      *WebhookService
+}
 
 // CreateSubmissionRequest 创建提交请求结构
 type CreateSubmissionRequest struct {
@@ -25,79 +30,138 @@ type SubmissionResponse struct {
 }
 
 // NewSubmissionService 创建提交服务实例
-func NewSubmissionService() *SubmissionService {
-	return &SubmissionService{}
+func NewSubmissionService(repos *repository.Repositories, notificationService *NotificationService, webhookService *WebhookService) *SubmissionService {
+	return &SubmissionService{
+		repos:               repos,
+		notificationService: notificationService,
+		webhookService:      webhookService,
+	}
 }
 
 // CreateSubmission 创建提交
 func (s *SubmissionService) CreateSubmission(userID uint, req *CreateSubmissionRequest) (*model.Submission, error) {
-	db := database.GetDB()
-
-	// 检查题目是否存在
-	var problem model.Problem
-	if err := db.First(&problem, req.ProblemID).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("题目不存在")
+	var submission *model.Submission
+	isNew, judged, checked := false, false, false
+	err := s.repos.Transaction(func(tx *repository.Repositories) error {
+		// 检查题目是否存在且已发布，已归档的题目不再接受提交
+		problem, err := findVisibleProblem(tx, req.ProblemID)
+		if err != nil {
+			return err
 		}
-		return nil, err
-	}
-
-	// 检查提交点是否存在且属于该题目
-	var submissionPoint model.SubmissionPoint
-	if err := db.Where("id = ? AND problem_id = ?", req.SubmissionPointID, req.ProblemID).First(&submissionPoint).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("提交点不存在或不属于该题目")
+		if problem.Status == ProblemStatusArchived {
+			return errors.New("题目已归档，不再接受提交")
+		}
+		if err := checkProblemUnlocked(tx, userID, problem); err != nil {
+			return err
 		}
-		return nil, err
-	}
 
-	// 检查是否已有提交，如果有则更新，否则创建
-	var submission model.Submission
-	err := db.Where("user_id = ? AND problem_id = ? AND submission_point_id = ?", 
-		userID, req.ProblemID, req.SubmissionPointID).First(&submission).Error
+		// 检查提交点是否存在且属于该题目
+		point, err := tx.Problems.FindPointInProblem(req.ProblemID, req.SubmissionPointID)
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return errors.New("提交点不存在或不属于该题目")
+			}
+			return err
+		}
+		if point.Type == SubmissionPointGit {
+			if _, _, err := parseGitSubmission(req.Content); err != nil {
+				return err
+			}
+		}
 
-	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
-		return nil, err
-	}
+		// 组队题目以队伍为单位提交，队员提交的是同一份
+		var teamID *uint
+		if problem.TeamSize > 0 {
+			team, err := tx.Teams.FindByUserAndProblem(userID, req.ProblemID)
+			if err != nil {
+				if errors.Is(err, repository.ErrNotFound) {
+					return errors.New("该题目需要组队提交，请先创建或加入队伍")
+				}
+				return err
+			}
+			// 锁定队伍，与入队和退队依次执行，保证有提交后成员不再变化
+			if err := tx.Teams.Lock(team.ID); err != nil {
+				return err
+			}
+			teamID = &team.ID
+		}
 
-	if errors.Is(err, gorm.ErrRecordNotFound) {
-		// 创建新提交
-		submission = model.Submission{
+		// 按(用户, 提交点)或(队伍, 提交点)创建或更新提交，并发重复提交只会保留一条记录
+		isNew, err = tx.Submissions.Upsert(&model.Submission{
 			Content:           req.Content,
 			UserID:            userID,
+			TeamID:            teamID,
 			ProblemID:         req.ProblemID,
 			SubmissionPointID: req.SubmissionPointID,
+		})
+		if err != nil {
+			return err
 		}
-		if err := db.Create(&submission).Error; err != nil {
-			return nil, err
+
+		// 加载关联数据
+		submission, err = tx.Submissions.FindByUserAndPoint(userID, req.SubmissionPointID, "User", "Problem", "SubmissionPoint", "Team")
+		if err != nil {
+			return err
 		}
-	} else {
-		// 更新现有提交
-		if err := db.Model(&submission).Update("content", req.Content).Error; err != nil {
-			return nil, err
+
+		// 自动评测和代码仓库提交点每次提交都重新评测或检查
+		switch point.Type {
+		case SubmissionPointAuto:
+			judged = true
+			return tx.Judge.ResetRun(submission.ID)
+		case SubmissionPointGit:
+			checked = true
+			return tx.Checks.ResetRun(submission.ID)
 		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	// 加载关联数据
-	if err := db.Preload("User").Preload("Problem").Preload("SubmissionPoint").First(&submission, submission.ID).Error; err != nil {
-		return nil, err
+	if judged {
+		wakeJudgeWorker()
+	}
+	if checked {
+		wakeCheckWorker()
+	}
+	if isNew {
+		s.notifyManagers(submission)
+		s.publishEvent(EventSubmissionCreated, submission)
+	} else {
+		s.publishEvent(EventSubmissionUpdated, submission)
 	}
 
-	return &submission, nil
+	return submission, nil
 }
 
-// GetUserSubmissions 获取用户提交列表
-func (s *SubmissionService) GetUserSubmissions(userID uint, problemID uint) ([]SubmissionResponse, error) {
-	db := database.GetDB()
+// notifyManagers 通知题目所属方向的负责人有新提交，失败只记录日志
+func (s *SubmissionService) notifyManagers(submission *model.Submission) {
+	managerIDs, err := s.repos.Directions.ManagerIDs(submission.Problem.DirectionID)
+	if err != nil {
+		log.Printf("加载方向负责人失败: %v", err)
+		return
+	}
 
-	var submissions []model.Submission
-	query := db.Preload("User").Preload("Problem").Preload("SubmissionPoint").Preload("Scores").Where("user_id = ?", userID)
+	title := "收到新的提交"
+	content := fmt.Sprintf("%s 提交了题目《%s》的提交点「%s」",
+		submission.User.Nickname, submission.Problem.Title, submission.SubmissionPoint.Name)
+	if err := s.notificationService.Notify(managerIDs, NotificationSubmissionCreated, title, content, submission.ID); err != nil {
+		log.Printf("发送提交通知失败: %v", err)
+	}
+}
 
-	if problemID > 0 {
-		query = query.Where("problem_id = ?", problemID)
+// publishEvent 发布提交相关的Webhook事件，失败只记录日志
+func (s *SubmissionService) publishEvent(eventType string, submission *model.Submission) {
+	if err := s.webhookService.Publish(eventType, submission.Problem.DirectionID, submission); err != nil {
+		log.Printf("发布Webhook事件失败: %v", err)
 	}
+}
 
-	if err := query.Find(&submissions).Error; err != nil {
+// GetUserSubmissions 获取用户提交列表
+func (s *SubmissionService) GetUserSubmissions(userID uint, problemID uint) ([]SubmissionResponse, error) {
+	submissions, err := s.repos.Submissions.ListByUser(userID, problemID)
+	if err != nil {
 		return nil, err
 	}
 
@@ -105,7 +169,7 @@ func (s *SubmissionService) GetUserSubmissions(userID uint, problemID uint) ([]S
 	var result []SubmissionResponse
 	for _, submission := range submissions {
 		totalScore := 0
-		for _, score := range submission.Scores {
+		for _, score := range countedScores(submission.Scores) {
 			totalScore += score.Score
 		}
 		result = append(result, SubmissionResponse{
@@ -119,26 +183,30 @@ func (s *SubmissionService) GetUserSubmissions(userID uint, problemID uint) ([]S
 
 // GetSubmissionByID 根据ID获取提交
 func (s *SubmissionService) GetSubmissionByID(submissionID uint) (*model.Submission, error) {
-	db := database.GetDB()
-
-	var submission model.Submission
-	if err := db.Preload("User").Preload("Problem").Preload("SubmissionPoint").Preload("Scores").First(&submission, submissionID).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
+	submission, err := s.repos.Submissions.FindByID(submissionID, "User", "Problem", "SubmissionPoint", "Scores", "Team")
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
 			return nil, errors.New("提交不存在")
 		}
 		return nil, err
 	}
 
-	return &submission, nil
+	return submission, nil
+}
+
+// CanViewSubmission 判断用户能否查看提交：管理员、提交者本人或提交所属队伍的成员
+func (s *SubmissionService) CanViewSubmission(submission *model.Submission, userID uint, isAdmin bool) (bool, error) {
+	if isAdmin {
+		return true, nil
+	}
+	return canViewSubmission(s.repos, submission, userID)
 }
 
 // GetSubmissionsForReview 获取待评分的提交列表（管理员用）
 func (s *SubmissionService) GetSubmissionsForReview(reviewerID uint, problemID uint) ([]model.Submission, error) {
-	db := database.GetDB()
-
 	// 首先获取该管理员负责的方向
-	var directionIDs []uint
-	if err := db.Table("direction_managers").Where("user_id = ?", reviewerID).Pluck("direction_id", &directionIDs).Error; err != nil {
+	directionIDs, err := s.repos.Directions.ManagedDirectionIDs(reviewerID)
+	if err != nil {
 		return nil, err
 	}
 
@@ -146,16 +214,14 @@ func (s *SubmissionService) GetSubmissionsForReview(reviewerID uint, problemID u
 		return []model.Submission{}, nil
 	}
 
-	// 构建查询
-	query := db.Preload("User").Preload("Problem").Preload("SubmissionPoint").Preload("Scores")
-
+	var problemIDs []uint
 	if problemID > 0 {
 		// 检查题目是否属于该管理员负责的方向
-		var problem model.Problem
-		if err := db.First(&problem, problemID).Error; err != nil {
+		problem, err := s.repos.Problems.FindByID(problemID)
+		if err != nil {
 			return nil, err
 		}
-		
+
 		found := false
 		for _, dirID := range directionIDs {
 			if problem.DirectionID == dirID {
@@ -166,44 +232,57 @@ func (s *SubmissionService) GetSubmissionsForReview(reviewerID uint, problemID u
 		if !found {
 			return []model.Submission{}, nil
 		}
-		
-		query = query.Where("problem_id = ?", problemID)
+
+		problemIDs = []uint{problemID}
 	} else {
 		// 获取所有负责方向下的题目
-		var problemIDs []uint
-		if err := db.Model(&model.Problem{}).Where("direction_id IN ?", directionIDs).Pluck("id", &problemIDs).Error; err != nil {
+		problemIDs, err = s.repos.Problems.IDsByDirections(directionIDs)
+		if err != nil {
 			return nil, err
 		}
 		if len(problemIDs) == 0 {
 			return []model.Submission{}, nil
 		}
-		query = query.Where("problem_id IN ?", problemIDs)
 	}
 
-	var submissions []model.Submission
-	if err := query.Find(&submissions).Error; err != nil {
+	submissions, err := s.repos.Submissions.ListByProblems(problemIDs)
+	if err != nil {
 		return nil, err
 	}
-
+	for i := range submissions {
+		if submissions[i].CheckRun != nil {
+			if err := parseCheckSteps(submissions[i].CheckRun); err != nil {
+				return nil, err
+			}
+		}
+		submissions[i].Similarity = &model.SimilarityFlag{
+			Score:   submissions[i].SimilarityScore,
+			Flagged: submissions[i].SimilarityFlagged,
+		}
+	}
 	return submissions, nil
 }
 
 // DeleteSubmission 删除提交
 func (s *SubmissionService) DeleteSubmission(submissionID uint, userID uint) error {
-	db := database.GetDB()
-
-	var submission model.Submission
-	if err := db.Where("id = ? AND user_id = ?", submissionID, userID).First(&submission).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return errors.New("提交不存在或无权限删除")
+	var submission *model.Submission
+	err := s.repos.Transaction(func(tx *repository.Repositories) error {
+		var err error
+		submission, err = tx.Submissions.FindByIDAndUser(submissionID, userID, "Problem")
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return errors.New("提交不存在或无权限删除")
+			}
+			return err
 		}
-		return err
-	}
 
-	// 删除相关评分
-	if err := db.Where("submission_id = ?", submissionID).Delete(&model.Score{}).Error; err != nil {
+		// 提交与相关评分一并删除，恢复时一并找回
+		return tx.Submissions.Delete(submission)
+	})
+	if err != nil {
 		return err
 	}
 
-	return db.Delete(&submission).Error
-}
\ No newline at end of file
+	s.publishEvent(EventSubmissionDeleted, submission)
+	return nil
+}
@@ -0,0 +1,86 @@
+package service
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/tksky1/glimgate/internal/model"
+)
+
+// archiveNameReplacer 替换压缩包路径中不能出现在文件名里的字符
+var archiveNameReplacer = strings.NewReplacer(
+	"/", "_", "\\", "_", ":", "_", "*", "_", "?", "_",
+	"\"", "_", "<", "_", ">", "_", "|", "_", "\n", " ", "\r", " ", "\t", " ",
+)
+
+// ResolveExportScope 解析提交打包范围
+func (s *SubmissionService) ResolveExportScope(directionID, problemID uint) (*ExportScope, error) {
+	return resolveExportScope(s.repos, directionID, problemID)
+}
+
+// ExportSubmissionArchive 将导出范围内的全部提交按候选人打包为ZIP写入w
+// 目录结构为 学号_姓名/题目/提交点.txt，文件修改时间为提交的最后更新时间
+func (s *SubmissionService) ExportSubmissionArchive(scope *ExportScope, w io.Writer) error {
+	submissions, err := listExportSubmissions(s.repos, scope)
+	if err != nil {
+		return err
+	}
+
+	zw := zip.NewWriter(w)
+	userDirs := make(map[uint]string)
+	usedNames := make(map[string]bool)
+	for _, submission := range submissions {
+		dir, ok := userDirs[submission.UserID]
+		if !ok {
+			user := submission.User
+			dir = uniqueArchiveName(usedNames, sanitizeArchiveName(fmt.Sprintf("%s_%s", user.StudentID, user.RealName)), "", user.Username)
+			userDirs[submission.UserID] = dir
+		}
+
+		name := uniqueArchiveName(usedNames, fmt.Sprintf("%s/%s/%s", dir,
+			sanitizeArchiveName(submission.Problem.Title), sanitizeArchiveName(submission.SubmissionPoint.Name)),
+			".txt", fmt.Sprint(submission.ID))
+		if err := writeArchiveFile(zw, name, submission); err != nil {
+			return err
+		}
+	}
+
+	return zw.Close()
+}
+
+// writeArchiveFile 向压缩包写入一条提交的内容
+func writeArchiveFile(zw *zip.Writer, name string, submission model.Submission) error {
+	header := &zip.FileHeader{
+		Name:     name,
+		Method:   zip.Deflate,
+		Modified: submission.UpdatedAt,
+	}
+	f, err := zw.CreateHeader(header)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(f, submission.Content)
+	return err
+}
+
+// sanitizeArchiveName 清理压缩包中的单级路径名
+func sanitizeArchiveName(name string) string {
+	name = strings.TrimSpace(archiveNameReplacer.Replace(name))
+	name = strings.Trim(name, ".")
+	if name == "" {
+		return "_"
+	}
+	return name
+}
+
+// uniqueArchiveName 拼接名称和扩展名，已被占用时在扩展名之前追加后缀区分
+func uniqueArchiveName(used map[string]bool, base, ext, suffix string) string {
+	name := base + ext
+	if used[name] {
+		name = fmt.Sprintf("%s_%s%s", base, suffix, ext)
+	}
+	used[name] = true
+	return name
+}
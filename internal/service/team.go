@@ -0,0 +1,587 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/tksky1/glimgate/internal/model"
+	"github.com/tksky1/glimgate/internal/repository"
+	"github.com/tksky1/glimgate/pkg/bundle"
+	"github.com/tksky1/glimgate/pkg/config"
+)
+
+// maxTeamNameLen 队伍名称的最大字符数
+const maxTeamNameLen = 50
+
+// TeamService 组队服务
+type TeamService struct {
+	repos               *repository.Repositories
+	notificationService *NotificationService
+}
+
+// CreateTeamRequest 创建队伍请求结构
+type CreateTeamRequest struct {
+	Name string `json:"name" binding:"required" example:"第一小队"`
+}
+
+// InviteTeamMemberRequest 邀请队员请求结构
+type InviteTeamMemberRequest struct {
+	Username string `json:"username" binding:"required" example:"user456"`
+}
+
+// TeamMemberInfo 队伍成员信息，只包含公开资料
+type TeamMemberInfo struct {
+	UserID   uint      `json:"user_id" example:"5"`
+	Username string    `json:"username" example:"user123"`
+	Nickname string    `json:"nickname" example:"小明"`
+	JoinedAt time.Time `json:"joined_at"`
+}
+
+// TeamInviteInfo 队伍发出的待处理邀请
+type TeamInviteInfo struct {
+	ID        uint      `json:"id" example:"3"`
+	UserID    uint      `json:"user_id" example:"6"`
+	Username  string    `json:"username" example:"user456"`
+	Nickname  string    `json:"nickname" example:"小红"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TeamResponse 队伍响应结构
+type TeamResponse struct {
+	ID        uint             `json:"id" example:"2"`
+	ProblemID uint             `json:"problem_id" example:"1"`
+	Name      string           `json:"name" example:"第一小队"`
+	LeaderID  uint             `json:"leader_id" example:"5"`
+	MaxSize   int              `json:"max_size" example:"3"` // 题目的队伍人数上限
+	CreatedAt time.Time        `json:"created_at"`
+	Members   []TeamMemberInfo `json:"members"`
+	Invites   []TeamInviteInfo `json:"invites"`
+}
+
+// MyTeamInvite 用户收到的入队邀请
+type MyTeamInvite struct {
+	ID              uint      `json:"id" example:"3"`
+	TeamID          uint      `json:"team_id" example:"2"`
+	TeamName        string    `json:"team_name" example:"第一小队"`
+	ProblemID       uint      `json:"problem_id" example:"1"`
+	ProblemTitle    string    `json:"problem_title" example:"实现一个计算器"`
+	InviterNickname string    `json:"inviter_nickname" example:"小明"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+// NewTeamService 创建组队服务实例
+func NewTeamService(repos *repository.Repositories, notificationService *NotificationService) *TeamService {
+	return &TeamService{
+		repos:               repos,
+		notificationService: notificationService,
+	}
+}
+
+// TeamScoreSplit 队伍得分计入队员的方式，配置无效时每位队员计全部得分
+func TeamScoreSplit() string {
+	if config.AppConfig.Teams.ScoreSplit == repository.TeamScoreSplitEqual {
+		return repository.TeamScoreSplitEqual
+	}
+	return repository.TeamScoreSplitFull
+}
+
+// canViewSubmission 判断用户能否查看提交：提交者本人或提交所属队伍的成员
+func canViewSubmission(repos *repository.Repositories, submission *model.Submission, userID uint) (bool, error) {
+	if submission.UserID == userID {
+		return true, nil
+	}
+	if submission.TeamID == nil {
+		return false, nil
+	}
+	return repos.Teams.IsMember(*submission.TeamID, userID)
+}
+
+// submissionRecipients 提交相关通知的接收者，组队提交为当前全部队员，加载失败时只通知提交者
+func submissionRecipients(repos *repository.Repositories, submission *model.Submission) []uint {
+	if submission.TeamID == nil {
+		return []uint{submission.UserID}
+	}
+	userIDs, err := repos.Teams.MemberIDs(*submission.TeamID)
+	if err != nil || len(userIDs) == 0 {
+		if err != nil {
+			log.Printf("加载队伍成员失败: %v", err)
+		}
+		return []uint{submission.UserID}
+	}
+	return userIDs
+}
+
+// checkTeamSize 检查队伍人数上限
+func checkTeamSize(size int) error {
+	if !bundle.ValidTeamSize(size) {
+		return errors.New("队伍人数上限应为2到20，0表示个人题目")
+	}
+	return nil
+}
+
+// checkTeamSizeChange 检查能否修改题目的队伍人数上限
+// 已有提交（包括已删除的提交）后不能在个人题目和组队题目之间切换，上限不能低于已有队伍的人数
+func checkTeamSizeChange(repos *repository.Repositories, problem *model.Problem, size int) error {
+	if (size == 0) != (problem.TeamSize == 0) {
+		count, err := repos.Submissions.CountAllByProblem(problem.ID)
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			return errors.New("题目已有提交，不能在个人题目和组队题目之间切换")
+		}
+		return nil
+	}
+	if size < problem.TeamSize {
+		maxCount, err := repos.Teams.MaxMemberCount(problem.ID)
+		if err != nil {
+			return err
+		}
+		if maxCount > int64(size) {
+			return errors.New("已有队伍人数超过新的人数上限")
+		}
+	}
+	return nil
+}
+
+// findTeamProblem 获取可以组队的题目：已发布、未归档、已解锁且设置了队伍人数上限
+func findTeamProblem(repos *repository.Repositories, userID, problemID uint) (*model.Problem, error) {
+	problem, err := findVisibleProblem(repos, problemID)
+	if err != nil {
+		return nil, err
+	}
+	if problem.TeamSize == 0 {
+		return nil, errors.New("该题目不是组队题目")
+	}
+	if problem.Status == ProblemStatusArchived {
+		return nil, errors.New("题目已归档，不能再组队")
+	}
+	if err := checkProblemUnlocked(repos, userID, problem); err != nil {
+		return nil, err
+	}
+	return problem, nil
+}
+
+// checkNotInTeam 检查用户在题目中尚未加入队伍
+func checkNotInTeam(repos *repository.Repositories, userID, problemID uint, message string) error {
+	_, err := repos.Teams.FindByUserAndProblem(userID, problemID)
+	if err == nil {
+		return errors.New(message)
+	}
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil
+	}
+	return err
+}
+
+// checkTeamNotFull 检查队伍人数未达到题目的上限，需先用lockTeam锁定队伍，避免并发入队超过上限
+func checkTeamNotFull(repos *repository.Repositories, team *model.Team, problem *model.Problem) error {
+	memberIDs, err := repos.Teams.MemberIDs(team.ID)
+	if err != nil {
+		return err
+	}
+	if len(memberIDs) >= problem.TeamSize {
+		return errors.New("队伍人数已满")
+	}
+	return nil
+}
+
+// lockTeam 在事务中锁定并获取队伍，同一队伍的成员变动和提交依次执行，人数上限和提交后成员固定的检查在并发时仍然有效
+// 需作为事务中的第一条语句执行，MySQL的可重复读隔离级别下之后的查询才能读到其他事务已提交的成员和提交
+func lockTeam(tx *repository.Repositories, teamID uint) (*model.Team, error) {
+	if err := tx.Teams.Lock(teamID); err != nil {
+		return nil, err
+	}
+	return findTeam(tx, teamID)
+}
+
+// findTeam 获取队伍，不存在时返回“队伍不存在”
+func findTeam(repos *repository.Repositories, teamID uint) (*model.Team, error) {
+	team, err := repos.Teams.FindByID(teamID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, errors.New("队伍不存在")
+		}
+		return nil, err
+	}
+	return team, nil
+}
+
+// CreateTeam 在组队题目中创建队伍，创建者成为队长，并清除其在该题目收到的其他邀请
+func (s *TeamService) CreateTeam(userID, problemID uint, req *CreateTeamRequest) (*TeamResponse, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" || utf8.RuneCountInString(name) > maxTeamNameLen {
+		return nil, errors.New("队伍名称长度应为1-50个字符")
+	}
+
+	var teamID uint
+	err := s.repos.Transaction(func(tx *repository.Repositories) error {
+		if _, err := findTeamProblem(tx, userID, problemID); err != nil {
+			return err
+		}
+		if err := checkNotInTeam(tx, userID, problemID, "你已加入该题目的队伍"); err != nil {
+			return err
+		}
+		exists, err := tx.Teams.NameExists(problemID, name)
+		if err != nil {
+			return err
+		}
+		if exists {
+			return errors.New("队伍名称已被使用")
+		}
+
+		team := &model.Team{ProblemID: problemID, Name: name, LeaderID: userID}
+		if err := tx.Teams.Create(team); err != nil {
+			return err
+		}
+		teamID = team.ID
+		if err := tx.Teams.AddMember(&model.TeamMember{TeamID: team.ID, ProblemID: problemID, UserID: userID}); err != nil {
+			return err
+		}
+		return tx.Teams.DeleteInvitesByUserAndProblem(userID, problemID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.buildTeamResponse(teamID)
+}
+
+// GetMyTeam 获取用户在题目中加入的队伍及待处理的邀请
+func (s *TeamService) GetMyTeam(userID, problemID uint) (*TeamResponse, error) {
+	if _, err := findVisibleProblem(s.repos, problemID); err != nil {
+		return nil, err
+	}
+	team, err := s.repos.Teams.FindByUserAndProblem(userID, problemID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, errors.New("队伍不存在")
+		}
+		return nil, err
+	}
+	return s.buildTeamResponse(team.ID)
+}
+
+// Invite 队长按用户名邀请队员，被邀请的用户在该题目中不能已有队伍，队伍有提交后不能再邀请
+func (s *TeamService) Invite(userID, teamID uint, req *InviteTeamMemberRequest) (*TeamInviteInfo, error) {
+	var invite *model.TeamInvite
+	var team *model.Team
+	var problem *model.Problem
+	var invitee *model.User
+	err := s.repos.Transaction(func(tx *repository.Repositories) error {
+		var err error
+		team, err = lockTeam(tx, teamID)
+		if err != nil {
+			return err
+		}
+		if team.LeaderID != userID {
+			return errors.New("只有队长可以邀请队员")
+		}
+		if err := checkTeamWithoutSubmissions(tx, team.ID); err != nil {
+			return err
+		}
+		problem, err = findTeamProblem(tx, userID, team.ProblemID)
+		if err != nil {
+			return err
+		}
+
+		invitee, err = tx.Users.FindByUsername(strings.TrimSpace(req.Username))
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return errors.New("用户不存在")
+			}
+			return err
+		}
+		if err := checkNotInTeam(tx, invitee.ID, team.ProblemID, "该用户已加入该题目的队伍"); err != nil {
+			return err
+		}
+		if err := checkTeamNotFull(tx, team, problem); err != nil {
+			return err
+		}
+		if _, err := tx.Teams.FindInviteByTeamAndUser(team.ID, invitee.ID); err == nil {
+			return errors.New("已邀请过该用户")
+		} else if !errors.Is(err, repository.ErrNotFound) {
+			return err
+		}
+
+		invite = &model.TeamInvite{TeamID: team.ID, UserID: invitee.ID, InviterID: userID}
+		return tx.Teams.CreateInvite(invite)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	title := "收到组队邀请"
+	content := fmt.Sprintf("你被邀请加入题目《%s》的队伍「%s」", problem.Title, team.Name)
+	if err := s.notificationService.Notify([]uint{invitee.ID}, NotificationTeamInvite, title, content, invite.ID); err != nil {
+		log.Printf("发送组队邀请通知失败: %v", err)
+	}
+
+	return &TeamInviteInfo{
+		ID:        invite.ID,
+		UserID:    invitee.ID,
+		Username:  invitee.Username,
+		Nickname:  invitee.Nickname,
+		CreatedAt: invite.CreatedAt,
+	}, nil
+}
+
+// GetMyInvites 获取用户收到的待处理邀请
+func (s *TeamService) GetMyInvites(userID uint) ([]MyTeamInvite, error) {
+	invites, err := s.repos.Teams.ListInvitesByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]MyTeamInvite, 0, len(invites))
+	titles := make(map[uint]string)
+	for _, invite := range invites {
+		problemID := invite.Team.ProblemID
+		if _, ok := titles[problemID]; !ok {
+			problem, err := s.repos.Problems.FindByID(problemID)
+			if err != nil && !errors.Is(err, repository.ErrNotFound) {
+				return nil, err
+			}
+			// 题目已删除或下线后邀请不再展示
+			if err != nil || !problemVisible(problem) {
+				titles[problemID] = ""
+				continue
+			}
+			titles[problemID] = problem.Title
+		}
+		if titles[problemID] == "" {
+			continue
+		}
+		result = append(result, MyTeamInvite{
+			ID:              invite.ID,
+			TeamID:          invite.TeamID,
+			TeamName:        invite.Team.Name,
+			ProblemID:       problemID,
+			ProblemTitle:    titles[problemID],
+			InviterNickname: invite.Inviter.Nickname,
+			CreatedAt:       invite.CreatedAt,
+		})
+	}
+	return result, nil
+}
+
+// AcceptInvite 接受邀请加入队伍，并清除用户在该题目收到的其他邀请，队伍有提交后不能再加入
+func (s *TeamService) AcceptInvite(userID, inviteID uint) (*TeamResponse, error) {
+	invite, err := s.repos.Teams.FindInvite(inviteID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, errors.New("邀请不存在")
+		}
+		return nil, err
+	}
+	teamID := invite.TeamID
+
+	err = s.repos.Transaction(func(tx *repository.Repositories) error {
+		team, err := lockTeam(tx, teamID)
+		if err != nil {
+			return err
+		}
+		// 锁定后重新读取，邀请可能已被撤回
+		invite, err := tx.Teams.FindInvite(inviteID)
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return errors.New("邀请不存在")
+			}
+			return err
+		}
+		if invite.UserID != userID || invite.TeamID != team.ID {
+			return errors.New("邀请不存在")
+		}
+
+		problem, err := findTeamProblem(tx, userID, team.ProblemID)
+		if err != nil {
+			return err
+		}
+		if err := checkTeamWithoutSubmissions(tx, team.ID); err != nil {
+			return err
+		}
+		if err := checkNotInTeam(tx, userID, team.ProblemID, "你已加入该题目的队伍"); err != nil {
+			return err
+		}
+		if err := checkTeamNotFull(tx, team, problem); err != nil {
+			return err
+		}
+
+		if err := tx.Teams.AddMember(&model.TeamMember{TeamID: team.ID, ProblemID: team.ProblemID, UserID: userID}); err != nil {
+			return err
+		}
+		return tx.Teams.DeleteInvitesByUserAndProblem(userID, team.ProblemID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.buildTeamResponse(teamID)
+}
+
+// DeleteInvite 被邀请的用户拒绝邀请，或队长撤回邀请
+func (s *TeamService) DeleteInvite(userID, inviteID uint) error {
+	return s.repos.Transaction(func(tx *repository.Repositories) error {
+		invite, err := tx.Teams.FindInvite(inviteID, "Team")
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return errors.New("邀请不存在")
+			}
+			return err
+		}
+		if invite.UserID != userID && invite.Team.LeaderID != userID {
+			return errors.New("邀请不存在")
+		}
+		return tx.Teams.DeleteInvite(invite)
+	})
+}
+
+// LeaveTeam 退出队伍，队伍有提交后不能退出
+// 队长退出时由最早加入的队员接任，最后一名队员退出时解散队伍
+func (s *TeamService) LeaveTeam(userID, teamID uint) error {
+	return s.repos.Transaction(func(tx *repository.Repositories) error {
+		team, err := lockTeam(tx, teamID)
+		if err != nil {
+			return err
+		}
+		isMember, err := tx.Teams.IsMember(team.ID, userID)
+		if err != nil {
+			return err
+		}
+		if !isMember {
+			return errors.New("队伍不存在")
+		}
+		if err := checkTeamWithoutSubmissions(tx, team.ID); err != nil {
+			return err
+		}
+
+		if err := tx.Teams.RemoveMember(team.ID, userID); err != nil {
+			return err
+		}
+		memberIDs, err := tx.Teams.MemberIDs(team.ID)
+		if err != nil {
+			return err
+		}
+		if len(memberIDs) == 0 {
+			return tx.Teams.Delete(team)
+		}
+		if team.LeaderID == userID {
+			return tx.Teams.Update(team, map[string]interface{}{"leader_id": memberIDs[0]})
+		}
+		return nil
+	})
+}
+
+// RemoveMember 队长移除队员，队伍有提交后不能移除
+func (s *TeamService) RemoveMember(userID, teamID, memberID uint) error {
+	return s.repos.Transaction(func(tx *repository.Repositories) error {
+		team, err := lockTeam(tx, teamID)
+		if err != nil {
+			return err
+		}
+		if team.LeaderID != userID {
+			return errors.New("只有队长可以移除队员")
+		}
+		if memberID == userID {
+			return errors.New("队长不能移除自己，请退出队伍")
+		}
+		isMember, err := tx.Teams.IsMember(team.ID, memberID)
+		if err != nil {
+			return err
+		}
+		if !isMember {
+			return errors.New("该用户不是队伍成员")
+		}
+		if err := checkTeamWithoutSubmissions(tx, team.ID); err != nil {
+			return err
+		}
+		return tx.Teams.RemoveMember(team.ID, memberID)
+	})
+}
+
+// checkTeamWithoutSubmissions 检查队伍没有提交，提交后成员固定以保证得分归属不变
+func checkTeamWithoutSubmissions(repos *repository.Repositories, teamID uint) error {
+	hasSubmissions, err := repos.Teams.HasSubmissions(teamID)
+	if err != nil {
+		return err
+	}
+	if hasSubmissions {
+		return errors.New("队伍已有提交，成员不能再变动")
+	}
+	return nil
+}
+
+// ListTeams 获取题目下的全部队伍（管理员用）
+func (s *TeamService) ListTeams(problemID uint) ([]TeamResponse, error) {
+	problem, err := s.repos.Problems.FindByID(problemID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, errors.New("题目不存在")
+		}
+		return nil, err
+	}
+	teams, err := s.repos.Teams.ListByProblem(problemID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]TeamResponse, 0, len(teams))
+	for _, team := range teams {
+		result = append(result, newTeamResponse(&team, problem, team.Members, nil))
+	}
+	return result, nil
+}
+
+// buildTeamResponse 加载队伍的成员和待处理邀请并组装响应
+func (s *TeamService) buildTeamResponse(teamID uint) (*TeamResponse, error) {
+	team, err := s.repos.Teams.FindByID(teamID, "Invites.User")
+	if err != nil {
+		return nil, err
+	}
+	problem, err := s.repos.Problems.FindByID(team.ProblemID)
+	if err != nil {
+		return nil, err
+	}
+	members, err := s.repos.Teams.ListMembers(teamID)
+	if err != nil {
+		return nil, err
+	}
+	resp := newTeamResponse(team, problem, members, team.Invites)
+	return &resp, nil
+}
+
+// newTeamResponse 组装队伍响应，成员只包含公开资料
+func newTeamResponse(team *model.Team, problem *model.Problem, members []model.TeamMember, invites []model.TeamInvite) TeamResponse {
+	resp := TeamResponse{
+		ID:        team.ID,
+		ProblemID: team.ProblemID,
+		Name:      team.Name,
+		LeaderID:  team.LeaderID,
+		MaxSize:   problem.TeamSize,
+		CreatedAt: team.CreatedAt,
+		Members:   make([]TeamMemberInfo, 0, len(members)),
+		Invites:   make([]TeamInviteInfo, 0, len(invites)),
+	}
+	for _, member := range members {
+		resp.Members = append(resp.Members, TeamMemberInfo{
+			UserID:   member.UserID,
+			Username: member.User.Username,
+			Nickname: member.User.Nickname,
+			JoinedAt: member.CreatedAt,
+		})
+	}
+	for _, invite := range invites {
+		resp.Invites = append(resp.Invites, TeamInviteInfo{
+			ID:        invite.ID,
+			UserID:    invite.UserID,
+			Username:  invite.User.Username,
+			Nickname:  invite.User.Nickname,
+			CreatedAt: invite.CreatedAt,
+		})
+	}
+	return resp
+}
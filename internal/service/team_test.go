@@ -0,0 +1,97 @@
+package service
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/tksky1/glimgate/internal/model"
+)
+
+// createTeamProblem 创建组队题目及其提交点
+func (env *testEnv) createTeamProblem(t *testing.T, size int) (model.Problem, model.SubmissionPoint) {
+	t.Helper()
+	problem, point := env.createProblem(t, "组队题")
+	if err := env.db.Model(&problem).Update("team_size", size).Error; err != nil {
+		t.Fatal(err)
+	}
+	problem.TeamSize = size
+	return problem, point
+}
+
+func TestTeamMembersFrozenAfterSubmission(t *testing.T) {
+	env := newTestEnv(t)
+	teams := NewTeamService(env.repos, env.notifications)
+	problem, point := env.createTeamProblem(t, 4)
+	leader := env.createUser(t, "leader", false)
+	invited := env.createUser(t, "invited", false)
+	late := env.createUser(t, "late", false)
+
+	team, err := teams.CreateTeam(leader.ID, problem.ID, &CreateTeamRequest{Name: "第一小队"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	invite, err := teams.Invite(leader.ID, team.ID, &InviteTeamMemberRequest{Username: invited.Username})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := env.submissions.CreateSubmission(leader.ID, &CreateSubmissionRequest{Content: "x", ProblemID: problem.ID, SubmissionPointID: point.ID}); err != nil {
+		t.Fatal(err)
+	}
+
+	// 提交后邀请和接受提交前发出的邀请都会改变得分归属，与退出一样拒绝
+	if _, err := teams.Invite(leader.ID, team.ID, &InviteTeamMemberRequest{Username: late.Username}); err == nil || err.Error() != "队伍已有提交，成员不能再变动" {
+		t.Errorf("提交后邀请应被拒绝，得到%v", err)
+	}
+	if _, err := teams.AcceptInvite(invited.ID, invite.ID); err == nil || err.Error() != "队伍已有提交，成员不能再变动" {
+		t.Errorf("提交后接受邀请应被拒绝，得到%v", err)
+	}
+	if err := teams.LeaveTeam(leader.ID, team.ID); err == nil || err.Error() != "队伍已有提交，成员不能再变动" {
+		t.Errorf("提交后退出应被拒绝，得到%v", err)
+	}
+	if n := env.count(t, &model.TeamMember{}, false, "team_id = ?", team.ID); n != 1 {
+		t.Errorf("队伍应只有队长1人，得到%d人", n)
+	}
+}
+
+func TestAcceptInviteConcurrent(t *testing.T) {
+	env := newTestEnv(t)
+	teams := NewTeamService(env.repos, env.notifications)
+	problem, _ := env.createTeamProblem(t, 3)
+	leader := env.createUser(t, "leader", false)
+
+	team, err := teams.CreateTeam(leader.ID, problem.ID, &CreateTeamRequest{Name: "第一小队"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	users := make([]model.User, concurrentRequests)
+	invites := make([]uint, concurrentRequests)
+	for i := range users {
+		users[i] = env.createUser(t, fmt.Sprint("user", i), false)
+		invite, err := teams.Invite(leader.ID, team.ID, &InviteTeamMemberRequest{Username: users[i].Username})
+		if err != nil {
+			t.Fatal(err)
+		}
+		invites[i] = invite.ID
+	}
+
+	errs := runConcurrently(concurrentRequests, func(i int) error {
+		_, err := teams.AcceptInvite(users[i].ID, invites[i])
+		return err
+	})
+	accepted := 0
+	for i, err := range errs {
+		switch {
+		case err == nil:
+			accepted++
+		case err.Error() != "队伍人数已满":
+			t.Errorf("第%d个邀请: %v", i, err)
+		}
+	}
+	// 队长之外只能再加入2人
+	if accepted != 2 {
+		t.Errorf("应有2人加入，实际%d人", accepted)
+	}
+	if n := env.count(t, &model.TeamMember{}, false, "team_id = ?", team.ID); n != 3 {
+		t.Errorf("队伍人数应为上限3人，得到%d人", n)
+	}
+}
@@ -0,0 +1,127 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/tksky1/glimgate/internal/model"
+)
+
+// 级联操作中途失败时整个事务回滚，不留下孤立或半删除的记录
+
+func TestDeleteSubmissionRollsBack(t *testing.T) {
+	env := newTestEnv(t)
+	user := env.createUser(t, "user", false)
+	reviewer := env.createUser(t, "reviewer", false)
+	problem, point := env.createProblem(t, "题目")
+	sub := model.Submission{Content: "x", UserID: user.ID, ProblemID: problem.ID, SubmissionPointID: point.ID}
+	env.create(t, &sub)
+	env.create(t, &model.Score{Score: 60, UserID: user.ID, SubmissionID: sub.ID, ReviewerID: env.admin.ID})
+	env.create(t, &model.Score{Score: 70, UserID: user.ID, SubmissionID: sub.ID, ReviewerID: reviewer.ID})
+
+	t.Run("删除提交失败", func(t *testing.T) {
+		// 评分已软删除后删除提交失败
+		failOn(t, env.db, "delete", "submissions")
+		err := env.submissions.DeleteSubmission(sub.ID, user.ID)
+		if !errors.Is(err, errInjected) {
+			t.Fatalf("应返回注入的错误，得到%v", err)
+		}
+		if n := env.count(t, &model.Submission{}, false, "id = ?", sub.ID); n != 1 {
+			t.Errorf("提交应保留，得到%d条", n)
+		}
+		if n := env.count(t, &model.Score{}, false, "submission_id = ?", sub.ID); n != 2 {
+			t.Errorf("评分应随事务回滚恢复，得到%d条", n)
+		}
+	})
+
+	t.Run("删除后恢复", func(t *testing.T) {
+		if err := env.submissions.DeleteSubmission(sub.ID, user.ID); err != nil {
+			t.Fatal(err)
+		}
+		if n := env.count(t, &model.Score{}, false, "submission_id = ?", sub.ID); n != 0 {
+			t.Errorf("评分应与提交一并删除，剩余%d条", n)
+		}
+		if _, err := env.trash.RestoreItem(env.op, "submission", sub.ID); err != nil {
+			t.Fatal(err)
+		}
+		if n := env.count(t, &model.Score{}, false, "submission_id = ?", sub.ID); n != 2 {
+			t.Errorf("评分应与提交一并恢复，得到%d条", n)
+		}
+	})
+}
+
+func TestCreateDirectionRollsBack(t *testing.T) {
+	env := newTestEnv(t)
+	manager := env.createUser(t, "manager", false)
+	req := &CreateDirectionRequest{Name: "后端", ManagerIDs: []uint{manager.ID}}
+
+	for _, table := range []string{"direction_managers", "audit_logs"} {
+		t.Run(table, func(t *testing.T) {
+			failOn(t, env.db, "create", table)
+			_, err := env.directions.CreateDirection(env.op, req)
+			if !errors.Is(err, errInjected) {
+				t.Fatalf("应返回注入的错误，得到%v", err)
+			}
+			if n := env.count(t, &model.Direction{}, true, "name = ?", "后端"); n != 0 {
+				t.Errorf("方向应随事务回滚，得到%d条", n)
+			}
+			var managers int64
+			env.db.Table("direction_managers").Count(&managers)
+			if managers != 0 {
+				t.Errorf("不应留下负责人关联，得到%d条", managers)
+			}
+		})
+	}
+
+	direction, err := env.directions.CreateDirection(env.op, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(direction.Managers) != 1 || direction.Managers[0].ID != manager.ID {
+		t.Fatalf("负责人: %+v", direction.Managers)
+	}
+	if n := env.count(t, &model.AuditLog{}, false, "1 = 1"); n != 1 {
+		t.Errorf("应记录1条审计日志，得到%d条", n)
+	}
+}
+
+func TestDeleteProblemRollsBack(t *testing.T) {
+	env := newTestEnv(t)
+	problem, point := env.createProblem(t, "题目")
+	env.create(t, &model.SubmissionPoint{ProblemID: problem.ID, Name: "第二个提交点", MaxScore: 50, Type: "manual"})
+	env.create(t, &model.ProblemAttachment{ProblemID: problem.ID, Filename: "a.zip", SHA256: "x", StorageKey: "k"})
+
+	// 依次在删除附件、删除题目和记录审计日志时失败，提交点和附件都不应被删除
+	for _, step := range []struct{ operation, table string }{
+		{"delete", "problem_attachments"},
+		{"delete", "problems"},
+		{"create", "audit_logs"},
+	} {
+		t.Run(step.table, func(t *testing.T) {
+			failOn(t, env.db, step.operation, step.table)
+			err := env.problems.DeleteProblem(env.op, problem.ID)
+			if !errors.Is(err, errInjected) {
+				t.Fatalf("应返回注入的错误，得到%v", err)
+			}
+			if n := env.count(t, &model.Problem{}, false, "id = ?", problem.ID); n != 1 {
+				t.Errorf("题目应保留，得到%d条", n)
+			}
+			if n := env.count(t, &model.SubmissionPoint{}, false, "problem_id = ?", problem.ID); n != 2 {
+				t.Errorf("提交点应随事务回滚恢复，得到%d条", n)
+			}
+			if n := env.count(t, &model.ProblemAttachment{}, false, "problem_id = ?", problem.ID); n != 1 {
+				t.Errorf("附件应随事务回滚恢复，得到%d条", n)
+			}
+		})
+	}
+
+	if err := env.problems.DeleteProblem(env.op, problem.ID); err != nil {
+		t.Fatal(err)
+	}
+	if n := env.count(t, &model.SubmissionPoint{}, false, "problem_id = ?", problem.ID); n != 0 {
+		t.Errorf("提交点应与题目一并删除，剩余%d条", n)
+	}
+	if n := env.count(t, &model.SubmissionPoint{}, true, "id = ?", point.ID); n != 1 {
+		t.Errorf("提交点应为软删除，可从回收站恢复")
+	}
+}
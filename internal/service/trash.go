@@ -0,0 +1,395 @@
+package service
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"github.com/tksky1/glimgate/internal/model"
+	"github.com/tksky1/glimgate/internal/repository"
+	"github.com/tksky1/glimgate/pkg/config"
+	"github.com/tksky1/glimgate/pkg/storage"
+)
+
+// TrashEntityTypes 回收站支持的对象类型
+var TrashEntityTypes = []string{
+	AuditEntityUser,
+	AuditEntityDirection,
+	AuditEntityProblem,
+	AuditEntitySubmissionPoint,
+	AuditEntitySubmission,
+	AuditEntityScore,
+	AuditEntityProblemAttachment,
+}
+
+// TrashItem 回收站记录
+type TrashItem struct {
+	ID        uint        `json:"id" example:"1"`
+	DeletedAt time.Time   `json:"deleted_at"`
+	Data      interface{} `json:"data"`
+}
+
+// PurgeResult 彻底删除结果，键为对象类型，值为删除数量
+type PurgeResult map[string]int64
+
+// TrashService 回收站服务
+type TrashService struct {
+	repos        *repository.Repositories
+	store        storage.Storage
+	auditService *AuditService
+}
+
+// NewTrashService 创建回收站服务实例，彻底删除附件时同时删除store中的文件
+func NewTrashService(repos *repository.Repositories, store storage.Storage, auditService *AuditService) *TrashService {
+	return &TrashService{
+		repos:        repos,
+		store:        store,
+		auditService: auditService,
+	}
+}
+
+// GetDeletedItems 分页获取指定类型的已删除记录
+func (s *TrashService) GetDeletedItems(entityType string, page, pageSize int) ([]TrashItem, int64, error) {
+	trash := s.repos.Trash
+
+	switch entityType {
+	case AuditEntityUser:
+		return listDeleted(trash, page, pageSize, func(v *model.User) (uint, time.Time) { return v.ID, v.DeletedAt.Time })
+	case AuditEntityDirection:
+		return listDeleted(trash, page, pageSize, func(v *model.Direction) (uint, time.Time) { return v.ID, v.DeletedAt.Time }, "Managers")
+	case AuditEntityProblem:
+		return listDeleted(trash, page, pageSize, func(v *model.Problem) (uint, time.Time) { return v.ID, v.DeletedAt.Time })
+	case AuditEntitySubmissionPoint:
+		return listDeleted(trash, page, pageSize, func(v *model.SubmissionPoint) (uint, time.Time) { return v.ID, v.DeletedAt.Time })
+	case AuditEntitySubmission:
+		return listDeleted(trash, page, pageSize, func(v *model.Submission) (uint, time.Time) { return v.ID, v.DeletedAt.Time })
+	case AuditEntityScore:
+		return listDeleted(trash, page, pageSize, func(v *model.Score) (uint, time.Time) { return v.ID, v.DeletedAt.Time })
+	case AuditEntityProblemAttachment:
+		return listDeleted(trash, page, pageSize, func(v *model.ProblemAttachment) (uint, time.Time) { return v.ID, v.DeletedAt.Time })
+	default:
+		return nil, 0, errors.New("不支持的回收站类型")
+	}
+}
+
+// listDeleted 查询已软删除的记录并转换为回收站记录
+func listDeleted[T any](trash repository.TrashRepository, page, pageSize int, key func(*T) (uint, time.Time), preloads ...string) ([]TrashItem, int64, error) {
+	var records []T
+	offset := (page - 1) * pageSize
+	total, err := trash.ListDeleted(&records, offset, pageSize, preloads...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	items := make([]TrashItem, 0, len(records))
+	for i := range records {
+		id, deletedAt := key(&records[i])
+		items = append(items, TrashItem{
+			ID:        id,
+			DeletedAt: deletedAt,
+			Data:      records[i],
+		})
+	}
+
+	return items, total, nil
+}
+
+// RestoreItem 恢复已删除的记录，连同与其一起被删除的下级记录
+func (s *TrashService) RestoreItem(op *Operator, entityType string, id uint) (interface{}, error) {
+	var restored interface{}
+	err := s.repos.Transaction(func(tx *repository.Repositories) error {
+		var err error
+		switch entityType {
+		case AuditEntityUser:
+			restored, err = s.restoreUser(tx, id)
+		case AuditEntityDirection:
+			restored, err = s.restoreDirection(tx, id)
+		case AuditEntityProblem:
+			restored, err = s.restoreProblem(tx, id)
+		case AuditEntitySubmissionPoint:
+			restored, err = s.restoreSubmissionPoint(tx, id)
+		case AuditEntitySubmission:
+			restored, err = s.restoreSubmission(tx, id)
+		case AuditEntityScore:
+			restored, err = s.restoreScore(tx, id)
+		case AuditEntityProblemAttachment:
+			restored, err = s.restoreProblemAttachment(tx, id)
+		default:
+			err = errors.New("不支持的回收站类型")
+		}
+		if err != nil {
+			return err
+		}
+		return s.auditService.Record(tx.AuditLogs, op, AuditActionRestore, entityType, id, nil, restored)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return restored, nil
+}
+
+// findDeleted 查找已删除的记录
+func findDeleted(tx *repository.Repositories, record interface{}, id uint) error {
+	if err := tx.Trash.FindDeleted(record, id); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return errors.New("回收站记录不存在")
+		}
+		return err
+	}
+	return nil
+}
+
+// requireParent 检查上级记录未被删除，parentErr为查询上级记录的结果
+func requireParent(parentErr error, msg string) error {
+	if errors.Is(parentErr, repository.ErrNotFound) {
+		return errors.New(msg)
+	}
+	return parentErr
+}
+
+// rejectConflict 检查恢复位置未被新记录占用，conflictErr为查询新记录的结果
+func rejectConflict(conflictErr error, msg string) error {
+	if conflictErr == nil {
+		return errors.New(msg)
+	}
+	if errors.Is(conflictErr, repository.ErrNotFound) {
+		return nil
+	}
+	return conflictErr
+}
+
+// restoreUser 恢复用户
+func (s *TrashService) restoreUser(tx *repository.Repositories, id uint) (interface{}, error) {
+	if err := findDeleted(tx, &model.User{}, id); err != nil {
+		return nil, err
+	}
+	if err := tx.Trash.Restore(&model.User{}, id); err != nil {
+		return nil, err
+	}
+	return tx.Users.FindByID(id)
+}
+
+// restoreDirection 恢复方向，负责人关联在删除时保留
+func (s *TrashService) restoreDirection(tx *repository.Repositories, id uint) (interface{}, error) {
+	if err := findDeleted(tx, &model.Direction{}, id); err != nil {
+		return nil, err
+	}
+	if err := tx.Trash.Restore(&model.Direction{}, id); err != nil {
+		return nil, err
+	}
+	return tx.Directions.FindByID(id, "Managers")
+}
+
+// restoreProblem 恢复题目及一同删除的提交点和附件
+func (s *TrashService) restoreProblem(tx *repository.Repositories, id uint) (interface{}, error) {
+	var problem model.Problem
+	if err := findDeleted(tx, &problem, id); err != nil {
+		return nil, err
+	}
+
+	_, err := tx.Directions.FindByID(problem.DirectionID)
+	if err := requireParent(err, "所属方向已删除，请先恢复方向"); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Trash.RestoreProblem(&problem); err != nil {
+		return nil, err
+	}
+	return tx.Problems.FindByID(id, "SubmissionPoints", "Attachments")
+}
+
+// restoreSubmissionPoint 恢复提交点
+func (s *TrashService) restoreSubmissionPoint(tx *repository.Repositories, id uint) (interface{}, error) {
+	var submissionPoint model.SubmissionPoint
+	if err := findDeleted(tx, &submissionPoint, id); err != nil {
+		return nil, err
+	}
+
+	_, err := tx.Problems.FindByID(submissionPoint.ProblemID)
+	if err := requireParent(err, "所属题目已删除，请先恢复题目"); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Trash.Restore(&model.SubmissionPoint{}, id); err != nil {
+		return nil, err
+	}
+	return tx.Problems.FindPointByID(id)
+}
+
+// restoreSubmission 恢复提交及一同删除的评分
+func (s *TrashService) restoreSubmission(tx *repository.Repositories, id uint) (interface{}, error) {
+	var submission model.Submission
+	if err := findDeleted(tx, &submission, id); err != nil {
+		return nil, err
+	}
+
+	_, err := tx.Problems.FindPointInProblem(submission.ProblemID, submission.SubmissionPointID)
+	if err := requireParent(err, "所属提交点已删除，请先恢复提交点"); err != nil {
+		return nil, err
+	}
+
+	taken, err := tx.Submissions.ExistsAtPoint(&submission)
+	if err != nil {
+		return nil, err
+	}
+	if taken {
+		return nil, errors.New("该提交点已有新的提交，无法恢复")
+	}
+
+	if err := tx.Trash.RestoreSubmission(&submission); err != nil {
+		return nil, err
+	}
+	return tx.Submissions.FindByID(id, "Scores")
+}
+
+// restoreScore 恢复评分
+func (s *TrashService) restoreScore(tx *repository.Repositories, id uint) (interface{}, error) {
+	var score model.Score
+	if err := findDeleted(tx, &score, id); err != nil {
+		return nil, err
+	}
+
+	_, err := tx.Submissions.FindByID(score.SubmissionID)
+	if err := requireParent(err, "所属提交已删除，请先恢复提交"); err != nil {
+		return nil, err
+	}
+
+	_, err = tx.Scores.FindBySubmissionAndReviewer(score.SubmissionID, score.ReviewerID)
+	if err := rejectConflict(err, "该评审已重新评分，无法恢复"); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Trash.Restore(&model.Score{}, id); err != nil {
+		return nil, err
+	}
+	return tx.Scores.FindByID(id)
+}
+
+// restoreProblemAttachment 恢复题目附件，期间上传了同名附件时无法恢复
+func (s *TrashService) restoreProblemAttachment(tx *repository.Repositories, id uint) (interface{}, error) {
+	var attachment model.ProblemAttachment
+	if err := findDeleted(tx, &attachment, id); err != nil {
+		return nil, err
+	}
+
+	_, err := tx.Problems.FindByID(attachment.ProblemID)
+	if err := requireParent(err, "所属题目已删除，请先恢复题目"); err != nil {
+		return nil, err
+	}
+
+	_, err = tx.Problems.FindAttachmentByName(attachment.ProblemID, attachment.Filename)
+	if err := rejectConflict(err, "该题目已有同名附件，无法恢复"); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Trash.Restore(&model.ProblemAttachment{}, id); err != nil {
+		return nil, err
+	}
+	return tx.Problems.FindAttachmentByID(id)
+}
+
+// RunPurge 定期彻底删除超过保留期的记录
+func (s *TrashService) RunPurge() {
+	trashConfig := config.AppConfig.Trash
+	if trashConfig.RetentionDays <= 0 {
+		return
+	}
+
+	interval := time.Duration(trashConfig.PurgeIntervalMinutes) * time.Minute
+	if interval <= 0 {
+		interval = time.Hour
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		cutoff := time.Now().AddDate(0, 0, -trashConfig.RetentionDays)
+		result, err := s.PurgeDeletedBefore(cutoff)
+		if err != nil {
+			log.Printf("清理回收站失败: %v", err)
+		}
+		for entityType, count := range result {
+			if count > 0 {
+				log.Printf("回收站清理: %s %d 条", entityType, count)
+			}
+		}
+		<-ticker.C
+	}
+}
+
+// PurgeDeletedBefore 彻底删除在cutoff之前删除的记录，仍被其他记录引用的暂不删除
+// 记录在同一事务中删除，事务提交后再删除附件和测试点在存储中的文件，文件删除失败只记录日志
+func (s *TrashService) PurgeDeletedBefore(cutoff time.Time) (PurgeResult, error) {
+	result := PurgeResult{}
+	var keys []string
+
+	// 按依赖顺序从下往上删除
+	err := s.repos.Transaction(func(tx *repository.Repositories) error {
+		count, err := tx.Trash.PurgeScores(cutoff)
+		if err != nil {
+			return err
+		}
+		result[AuditEntityScore] = count
+
+		if count, err = tx.Trash.PurgeSubmissions(cutoff); err != nil {
+			return err
+		}
+		result[AuditEntitySubmission] = count
+
+		cases, err := tx.Trash.ListPurgeableJudgeCases(cutoff)
+		if err != nil {
+			return err
+		}
+		for _, judgeCase := range cases {
+			keys = append(keys, judgeCase.InputKey, judgeCase.OutputKey)
+		}
+		if count, err = tx.Trash.PurgeSubmissionPoints(cutoff); err != nil {
+			return err
+		}
+		result[AuditEntitySubmissionPoint] = count
+
+		attachments, err := tx.Trash.ListPurgeableAttachments(cutoff)
+		if err != nil {
+			return err
+		}
+		ids := make([]uint, 0, len(attachments))
+		for _, attachment := range attachments {
+			ids = append(ids, attachment.ID)
+			keys = append(keys, attachment.StorageKey)
+		}
+		if count, err = tx.Trash.PurgeAttachments(ids); err != nil {
+			return err
+		}
+		result[AuditEntityProblemAttachment] = count
+
+		if count, err = tx.Trash.PurgeProblems(cutoff); err != nil {
+			return err
+		}
+		result[AuditEntityProblem] = count
+
+		if count, err = tx.Trash.PurgeDirections(cutoff); err != nil {
+			return err
+		}
+		result[AuditEntityDirection] = count
+
+		if count, err = tx.Trash.PurgeUsers(cutoff); err != nil {
+			return err
+		}
+		result[AuditEntityUser] = count
+		return nil
+	})
+	if err != nil {
+		return PurgeResult{}, err
+	}
+
+	for _, key := range keys {
+		if key == "" {
+			continue
+		}
+		if err := s.store.Delete(key); err != nil {
+			log.Printf("删除文件 %s 失败: %v", key, err)
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,81 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tksky1/glimgate/internal/model"
+	"github.com/tksky1/glimgate/pkg/storage"
+)
+
+// TestPurgeDeletesStoredFiles 彻底删除题目时一并删除附件和测试点的文件，事务回滚时文件保留
+func TestPurgeDeletesStoredFiles(t *testing.T) {
+	env := newTestEnv(t)
+	store, err := storage.NewLocal(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	trash := NewTrashService(env.repos, store, env.audit)
+	problems := NewProblemService(env.repos, store, env.notifications, env.audit)
+
+	problem, point := env.createProblem(t, "过期题")
+	attachment, err := problems.UploadAttachment(env.op, problem.ID, "data.txt", "text/plain", strings.NewReader("数据"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	judgeCase := model.JudgeCase{SubmissionPointID: point.ID, InputKey: "judge/1.in", OutputKey: "judge/1.out"}
+	for _, key := range []string{judgeCase.InputKey, judgeCase.OutputKey} {
+		if err := store.Put(key, strings.NewReader("1")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	env.create(t, &judgeCase)
+	if err := problems.DeleteProblem(env.op, problem.ID); err != nil {
+		t.Fatal(err)
+	}
+	keys := []string{attachment.StorageKey, judgeCase.InputKey, judgeCase.OutputKey}
+	stored := func(key string) bool {
+		file, err := store.Open(key)
+		if err != nil {
+			return false
+		}
+		file.Close()
+		return true
+	}
+
+	cutoff := time.Now().Add(time.Second)
+	t.Run("回滚", func(t *testing.T) {
+		failOn(t, env.db, "delete", "problems")
+		if _, err := trash.PurgeDeletedBefore(cutoff); !errors.Is(err, errInjected) {
+			t.Fatalf("应返回注入的错误，得到%v", err)
+		}
+		if n := env.count(t, &model.JudgeCase{}, false, "id = ?", judgeCase.ID); n != 1 {
+			t.Errorf("测试点应随事务回滚保留，得到%d条", n)
+		}
+		for _, key := range keys {
+			if !stored(key) {
+				t.Errorf("事务回滚后文件%s不应删除", key)
+			}
+		}
+	})
+
+	t.Run("彻底删除", func(t *testing.T) {
+		result, err := trash.PurgeDeletedBefore(cutoff)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result[AuditEntityProblem] != 1 || result[AuditEntitySubmissionPoint] != 1 || result[AuditEntityProblemAttachment] != 1 {
+			t.Errorf("删除数量不符: %v", result)
+		}
+		if n := env.count(t, &model.JudgeCase{}, false, "id = ?", judgeCase.ID); n != 0 {
+			t.Errorf("测试点应随提交点删除，剩余%d条", n)
+		}
+		for _, key := range keys {
+			if stored(key) {
+				t.Errorf("文件%s应已删除", key)
+			}
+		}
+	})
+}
@@ -0,0 +1,177 @@
+package service
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/tksky1/glimgate/internal/model"
+)
+
+// 同一(用户, 提交点)的并发提交和同一(提交, 评分者)的并发评分只产生一条记录，已删除的记录恢复后视为新记录
+
+const concurrentRequests = 8
+
+// runConcurrently 同时发起n次调用，返回各次调用的错误
+func runConcurrently(n int, fn func(i int) error) []error {
+	errs := make([]error, n)
+	start := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			<-start
+			errs[i] = fn(i)
+		}(i)
+	}
+	close(start)
+	wg.Wait()
+	return errs
+}
+
+// addManager 将用户设为题目所属方向的负责人
+func (env *testEnv) addManager(t *testing.T, problem model.Problem, user model.User) {
+	t.Helper()
+	if err := env.db.Model(&model.Direction{ID: problem.DirectionID}).Association("Managers").Append(&user); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCreateSubmissionConcurrent(t *testing.T) {
+	env := newTestEnv(t)
+	user := env.createUser(t, "user", false)
+	problem, point := env.createProblem(t, "题目")
+	env.addManager(t, problem, env.admin)
+
+	ids := make([]uint, concurrentRequests)
+	errs := runConcurrently(concurrentRequests, func(i int) error {
+		sub, err := env.submissions.CreateSubmission(user.ID, &CreateSubmissionRequest{
+			Content:           fmt.Sprint("第", i, "次提交"),
+			ProblemID:         problem.ID,
+			SubmissionPointID: point.ID,
+		})
+		if err == nil {
+			ids[i] = sub.ID
+		}
+		return err
+	})
+	for i, err := range errs {
+		if err != nil {
+			t.Fatalf("第%d次提交失败: %v", i, err)
+		}
+		if ids[i] != ids[0] {
+			t.Errorf("并发提交应返回同一条提交，得到%d和%d", ids[0], ids[i])
+		}
+	}
+
+	if n := env.count(t, &model.Submission{}, true, "user_id = ? AND submission_point_id = ?", user.ID, point.ID); n != 1 {
+		t.Fatalf("应只有1条提交，得到%d条", n)
+	}
+	// 只有第一次写入视为新提交，负责人只收到一条通知
+	if n := env.count(t, &model.Notification{}, false, "user_id = ? AND type = ?", env.admin.ID, NotificationSubmissionCreated); n != 1 {
+		t.Errorf("负责人应收到1条新提交通知，得到%d条", n)
+	}
+}
+
+func TestCreateSubmissionRestoresDeleted(t *testing.T) {
+	env := newTestEnv(t)
+	user := env.createUser(t, "user", false)
+	problem, point := env.createProblem(t, "题目")
+	env.addManager(t, problem, env.admin)
+
+	first, err := env.submissions.CreateSubmission(user.ID, &CreateSubmissionRequest{Content: "v1", ProblemID: problem.ID, SubmissionPointID: point.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := env.scores.CreateScore(env.op, env.admin.ID, &CreateScoreRequest{Score: 80, SubmissionID: first.ID}); err != nil {
+		t.Fatal(err)
+	}
+	if err := env.submissions.DeleteSubmission(first.ID, user.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	second, err := env.submissions.CreateSubmission(user.ID, &CreateSubmissionRequest{Content: "v2", ProblemID: problem.ID, SubmissionPointID: point.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second.ID != first.ID || second.Content != "v2" {
+		t.Fatalf("应恢复已删除的提交并更新内容: %+v", second)
+	}
+	if n := env.count(t, &model.Submission{}, true, "user_id = ?", user.ID); n != 1 {
+		t.Errorf("应只有1条提交，得到%d条", n)
+	}
+	// 恢复的提交视为新提交，原评分仍留在回收站中
+	if n := env.count(t, &model.Notification{}, false, "user_id = ? AND type = ?", env.admin.ID, NotificationSubmissionCreated); n != 2 {
+		t.Errorf("恢复的提交应通知负责人，得到%d条通知", n)
+	}
+	if n := env.count(t, &model.Score{}, false, "submission_id = ?", first.ID); n != 0 {
+		t.Errorf("原评分不应随提交恢复，得到%d条", n)
+	}
+}
+
+func TestCreateScoreConcurrent(t *testing.T) {
+	env := newTestEnv(t)
+	user := env.createUser(t, "user", false)
+	problem, point := env.createProblem(t, "题目")
+	env.addManager(t, problem, env.admin)
+	sub := model.Submission{Content: "x", UserID: user.ID, ProblemID: problem.ID, SubmissionPointID: point.ID}
+	env.create(t, &sub)
+
+	errs := runConcurrently(concurrentRequests, func(i int) error {
+		_, err := env.scores.CreateScore(env.op, env.admin.ID, &CreateScoreRequest{Score: 50 + i, SubmissionID: sub.ID})
+		return err
+	})
+	for i, err := range errs {
+		if err != nil {
+			t.Fatalf("第%d次评分失败: %v", i, err)
+		}
+	}
+
+	if n := env.count(t, &model.Score{}, true, "submission_id = ? AND reviewer_id = ?", sub.ID, env.admin.ID); n != 1 {
+		t.Fatalf("应只有1条评分，得到%d条", n)
+	}
+	if n := env.count(t, &model.AuditLog{}, false, "entity_type = ? AND action = ?", AuditEntityScore, AuditActionCreate); n != 1 {
+		t.Errorf("应只记录1次创建评分，得到%d次", n)
+	}
+	if n := env.count(t, &model.AuditLog{}, false, "entity_type = ? AND action = ?", AuditEntityScore, AuditActionUpdate); n != concurrentRequests-1 {
+		t.Errorf("其余评分应记录为更新，得到%d次", n)
+	}
+	if n := env.count(t, &model.Notification{}, false, "user_id = ? AND type = ?", user.ID, NotificationScoreCreated); n != 1 {
+		t.Errorf("选手应收到1条新评分通知，得到%d条", n)
+	}
+}
+
+func TestCreateScoreRestoresDeleted(t *testing.T) {
+	env := newTestEnv(t)
+	user := env.createUser(t, "user", false)
+	problem, point := env.createProblem(t, "题目")
+	env.addManager(t, problem, env.admin)
+	sub := model.Submission{Content: "x", UserID: user.ID, ProblemID: problem.ID, SubmissionPointID: point.ID}
+	env.create(t, &sub)
+
+	first, err := env.scores.CreateScore(env.op, env.admin.ID, &CreateScoreRequest{Score: 60, Comment: "旧评语", SubmissionID: sub.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := env.scores.DeleteScore(env.op, first.ID, env.admin.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	second, err := env.scores.CreateScore(env.op, env.admin.ID, &CreateScoreRequest{Score: 90, Comment: "新评语", SubmissionID: sub.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second.ID != first.ID || second.Score != 90 || second.Comment != "新评语" {
+		t.Fatalf("应恢复已删除的评分并覆盖分数和评语: %+v", second)
+	}
+	if n := env.count(t, &model.Score{}, true, "submission_id = ?", sub.ID); n != 1 {
+		t.Errorf("应只有1条评分，得到%d条", n)
+	}
+	if n := env.count(t, &model.AuditLog{}, false, "entity_type = ? AND action = ?", AuditEntityScore, AuditActionCreate); n != 2 {
+		t.Errorf("恢复的评分应记录为创建，得到%d次创建", n)
+	}
+	if n := env.count(t, &model.Notification{}, false, "user_id = ? AND type = ?", user.ID, NotificationScoreCreated); n != 2 {
+		t.Errorf("恢复的评分应通知选手，得到%d条通知", n)
+	}
+}
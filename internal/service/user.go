@@ -2,16 +2,20 @@ package service
 
 import (
 	"errors"
+	"log"
 
 	"github.com/tksky1/glimgate/internal/model"
-	"github.com/tksky1/glimgate/pkg/database"
+	"github.com/tksky1/glimgate/internal/repository"
 	"github.com/tksky1/glimgate/pkg/jwt"
 	"github.com/tksky1/glimgate/pkg/utils"
-	"gorm.io/gorm"
 )
 
 // UserService 用户服务
-type UserService struct{}
+type UserService struct {
+	repos          *repository.Repositories
+	webhookService *WebhookService
+	auditService   *AuditService
+}
 
 // RegisterRequest 注册请求结构
 type RegisterRequest struct {
@@ -49,17 +53,18 @@ type UpdateUserRequest struct {
 }
 
 // NewUserService 创建用户服务实例
-func NewUserService() *UserService {
-	return &UserService{}
+func NewUserService(repos *repository.Repositories, webhookService *WebhookService, auditService *AuditService) *UserService {
+	return &UserService{
+		repos:          repos,
+		webhookService: webhookService,
+		auditService:   auditService,
+	}
 }
 
 // Register 用户注册
 func (s *UserService) Register(req *RegisterRequest) (*model.User, error) {
-	db := database.GetDB()
-
 	// 检查用户名是否已存在
-	var existingUser model.User
-	if err := db.Where("username = ?", req.Username).First(&existingUser).Error; err == nil {
+	if _, err := s.repos.Users.FindByUsername(req.Username); err == nil {
 		return nil, errors.New("用户名已存在")
 	}
 
@@ -82,21 +87,23 @@ func (s *UserService) Register(req *RegisterRequest) (*model.User, error) {
 		IsAdmin:   false,
 	}
 
-	if err := db.Create(&user).Error; err != nil {
+	if err := s.repos.Users.Create(&user); err != nil {
 		return nil, err
 	}
 
+	if err := s.webhookService.Publish(EventUserRegistered, 0, &user); err != nil {
+		log.Printf("发布Webhook事件失败: %v", err)
+	}
+
 	return &user, nil
 }
 
 // Login 用户登录
 func (s *UserService) Login(req *LoginRequest) (*LoginResponse, error) {
-	db := database.GetDB()
-
 	// 查找用户
-	var user model.User
-	if err := db.Where("username = ?", req.Username).First(&user).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
+	user, err := s.repos.Users.FindByUsername(req.Username)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
 			return nil, errors.New("用户不存在")
 		}
 		return nil, err
@@ -115,100 +122,96 @@ func (s *UserService) Login(req *LoginRequest) (*LoginResponse, error) {
 
 	return &LoginResponse{
 		Token: token,
-		User:  user,
+		User:  *user,
 	}, nil
 }
 
 // GetUserByID 根据ID获取用户
 func (s *UserService) GetUserByID(userID uint) (*model.User, error) {
-	db := database.GetDB()
-
-	var user model.User
-	if err := db.First(&user, userID).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
+	user, err := s.repos.Users.FindByID(userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
 			return nil, errors.New("用户不存在")
 		}
 		return nil, err
 	}
 
-	return &user, nil
+	return user, nil
 }
 
 // GetUsers 获取用户列表
 func (s *UserService) GetUsers(page, pageSize int) ([]model.User, int64, error) {
-	db := database.GetDB()
-
-	var users []model.User
-	var total int64
-
-	// 获取总数
-	if err := db.Model(&model.User{}).Count(&total).Error; err != nil {
-		return nil, 0, err
-	}
-
-	// 分页查询
 	offset := (page - 1) * pageSize
-	if err := db.Offset(offset).Limit(pageSize).Find(&users).Error; err != nil {
-		return nil, 0, err
-	}
-
-	return users, total, nil
+	return s.repos.Users.List(offset, pageSize)
 }
 
 // UpdateUser 更新用户信息
-func (s *UserService) UpdateUser(userID uint, req *UpdateUserRequest) (*model.User, error) {
-	db := database.GetDB()
+func (s *UserService) UpdateUser(op *Operator, userID uint, req *UpdateUserRequest) (*model.User, error) {
+	var user *model.User
+	err := s.repos.Transaction(func(tx *repository.Repositories) error {
+		var err error
+		user, err = tx.Users.FindByID(userID)
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return errors.New("用户不存在")
+			}
+			return err
+		}
 
-	var user model.User
-	if err := db.First(&user, userID).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("用户不存在")
+		before := *user
+
+		// 更新字段
+		updates := make(map[string]interface{})
+		if req.Nickname != "" {
+			updates["nickname"] = req.Nickname
+		}
+		if req.RealName != "" {
+			updates["real_name"] = req.RealName
+		}
+		if req.College != "" {
+			updates["college"] = req.College
+		}
+		if req.StudentID != "" {
+			updates["student_id"] = req.StudentID
+		}
+		if req.QQ != "" {
+			updates["qq"] = req.QQ
+		}
+		if req.Email != "" {
+			updates["email"] = req.Email
+		}
+		if req.IsAdmin != nil {
+			updates["is_admin"] = *req.IsAdmin
 		}
-		return nil, err
-	}
 
-	// 更新字段
-	updates := make(map[string]interface{})
-	if req.Nickname != "" {
-		updates["nickname"] = req.Nickname
-	}
-	if req.RealName != "" {
-		updates["real_name"] = req.RealName
-	}
-	if req.College != "" {
-		updates["college"] = req.College
-	}
-	if req.StudentID != "" {
-		updates["student_id"] = req.StudentID
-	}
-	if req.QQ != "" {
-		updates["qq"] = req.QQ
-	}
-	if req.Email != "" {
-		updates["email"] = req.Email
-	}
-	if req.IsAdmin != nil {
-		updates["is_admin"] = *req.IsAdmin
-	}
+		if err := tx.Users.Update(user, updates); err != nil {
+			return err
+		}
 
-	if err := db.Model(&user).Updates(updates).Error; err != nil {
+		return s.auditService.Record(tx.AuditLogs, op, AuditActionUpdate, AuditEntityUser, user.ID, before, user)
+	})
+	if err != nil {
 		return nil, err
 	}
 
-	return &user, nil
+	return user, nil
 }
 
 // DeleteUser 删除用户
-func (s *UserService) DeleteUser(userID uint) error {
-	db := database.GetDB()
+func (s *UserService) DeleteUser(op *Operator, userID uint) error {
+	return s.repos.Transaction(func(tx *repository.Repositories) error {
+		user, err := tx.Users.FindByID(userID)
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return errors.New("用户不存在")
+			}
+			return err
+		}
 
-	var user model.User
-	if err := db.First(&user, userID).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return errors.New("用户不存在")
+		if err := tx.Users.Delete(user); err != nil {
+			return err
 		}
-		return err
-	}
 
-	return db.Delete(&user).Error
-}
\ No newline at end of file
+		return s.auditService.Record(tx.AuditLogs, op, AuditActionDelete, AuditEntityUser, user.ID, user, nil)
+	})
+}
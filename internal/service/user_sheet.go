@@ -0,0 +1,364 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/mail"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/tksky1/glimgate/internal/model"
+	"github.com/tksky1/glimgate/internal/repository"
+	"github.com/tksky1/glimgate/pkg/sheet"
+	"github.com/tksky1/glimgate/pkg/utils"
+)
+
+// MaxImportUsers 单次导入的最大用户数
+const MaxImportUsers = 2000
+
+// importPasswordLength 未提供密码时生成的初始密码长度
+const importPasswordLength = 10
+
+// userColumn 用户表格的列，导入时表头可以使用Key或Title
+type userColumn struct {
+	Key      string
+	Title    string
+	Required bool
+	MaxLen   int
+}
+
+// userImportColumns 导入用户表格的列，与model.User的字段长度一致
+var userImportColumns = []userColumn{
+	{Key: "username", Title: "用户名", Required: true, MaxLen: 50},
+	{Key: "password", Title: "密码", MaxLen: 72},
+	{Key: "nickname", Title: "昵称", Required: true, MaxLen: 50},
+	{Key: "real_name", Title: "姓名", Required: true, MaxLen: 50},
+	{Key: "college", Title: "学院", Required: true, MaxLen: 100},
+	{Key: "student_id", Title: "学号", Required: true, MaxLen: 20},
+	{Key: "qq", Title: "QQ", MaxLen: 20},
+	{Key: "email", Title: "邮箱", MaxLen: 100},
+}
+
+// ImportUserRowError 导入表格中某一行的校验错误
+type ImportUserRowError struct {
+	Row     int    `json:"row" example:"3"`
+	Field   string `json:"field" example:"student_id"`
+	Message string `json:"message" example:"学号与第2行重复"`
+}
+
+// ImportedUser 导入（或试运行时将要导入）的用户，只包含通过校验的行
+type ImportedUser struct {
+	Row             int    `json:"row" example:"2"`
+	ID              uint   `json:"id,omitempty" example:"10"`
+	Username        string `json:"username" example:"2024001"`
+	RealName        string `json:"real_name" example:"张三"`
+	StudentID       string `json:"student_id" example:"2024001"`
+	InitialPassword string `json:"initial_password,omitempty" example:"a8Kd3mPq2x"`
+}
+
+// ImportUsersResult 用户导入结果，Errors不为空时不会导入任何用户
+type ImportUsersResult struct {
+	DryRun   bool                 `json:"dry_run" example:"true"`
+	Imported bool                 `json:"imported" example:"false"`
+	Total    int                  `json:"total" example:"120"`
+	Users    []ImportedUser       `json:"users"`
+	Errors   []ImportUserRowError `json:"errors"`
+}
+
+// UserExportFilter 用户导出筛选条件
+type UserExportFilter struct {
+	DirectionID uint
+	// Stage 只导出处于该招新阶段的选手，见repository.UserStageRegistered等
+	Stage    string
+	MinScore *int
+	MaxScore *int
+}
+
+// importUserRow 通过校验的导入行
+type importUserRow struct {
+	row    int
+	values map[string]string
+}
+
+// ImportUsers 从表格行导入用户，第一行为表头
+// 任意一行校验失败或dryRun为true时只返回校验结果，不写入数据库；未提供密码的用户生成随机初始密码并在结果中返回
+func (s *UserService) ImportUsers(op *Operator, rows [][]string, dryRun bool) (*ImportUsersResult, error) {
+	result := &ImportUsersResult{DryRun: dryRun, Users: []ImportedUser{}, Errors: []ImportUserRowError{}}
+
+	if len(rows) == 0 {
+		result.Errors = append(result.Errors, ImportUserRowError{Row: 1, Message: "导入文件为空"})
+		return result, nil
+	}
+
+	// 解析表头
+	columnIndex := make(map[string]int)
+	for i, cell := range rows[0] {
+		name := strings.ToLower(strings.TrimSpace(cell))
+		for _, column := range userImportColumns {
+			if name == column.Key || name == strings.ToLower(column.Title) {
+				columnIndex[column.Key] = i
+			}
+		}
+	}
+	for _, column := range userImportColumns {
+		if _, ok := columnIndex[column.Key]; column.Required && !ok {
+			result.Errors = append(result.Errors, ImportUserRowError{Row: 1, Field: column.Key, Message: fmt.Sprintf("缺少必需的列: %s(%s)", column.Key, column.Title)})
+		}
+	}
+	if len(result.Errors) > 0 {
+		return result, nil
+	}
+
+	// 逐行校验，跳过空行
+	var valid []importUserRow
+	usernameRows := make(map[string]int)
+	studentIDRows := make(map[string]int)
+	for i, cells := range rows[1:] {
+		rowNum := i + 2
+		values := make(map[string]string)
+		blank := true
+		for key, index := range columnIndex {
+			if index < len(cells) {
+				values[key] = strings.TrimSpace(cells[index])
+				if values[key] != "" {
+					blank = false
+				}
+			}
+		}
+		if blank {
+			continue
+		}
+
+		result.Total++
+		if result.Total > MaxImportUsers {
+			result.Errors = append(result.Errors, ImportUserRowError{Row: rowNum, Message: fmt.Sprintf("单次最多导入%d个用户", MaxImportUsers)})
+			return result, nil
+		}
+
+		rowErrors := validateImportRow(rowNum, values)
+		if prev, ok := usernameRows[values["username"]]; ok && values["username"] != "" {
+			rowErrors = append(rowErrors, ImportUserRowError{Row: rowNum, Field: "username", Message: fmt.Sprintf("用户名与第%d行重复", prev)})
+		} else {
+			usernameRows[values["username"]] = rowNum
+		}
+		if prev, ok := studentIDRows[values["student_id"]]; ok && values["student_id"] != "" {
+			rowErrors = append(rowErrors, ImportUserRowError{Row: rowNum, Field: "student_id", Message: fmt.Sprintf("学号与第%d行重复", prev)})
+		} else {
+			studentIDRows[values["student_id"]] = rowNum
+		}
+
+		if len(rowErrors) > 0 {
+			result.Errors = append(result.Errors, rowErrors...)
+			continue
+		}
+		valid = append(valid, importUserRow{row: rowNum, values: values})
+	}
+
+	// 与已有用户比对用户名和学号
+	usernames := make([]string, 0, len(valid))
+	studentIDs := make([]string, 0, len(valid))
+	for _, row := range valid {
+		usernames = append(usernames, row.values["username"])
+		studentIDs = append(studentIDs, row.values["student_id"])
+	}
+	existingUsers, err := s.repos.Users.ListByUsernames(usernames)
+	if err != nil {
+		return nil, err
+	}
+	takenUsernames := make(map[string]bool)
+	for _, user := range existingUsers {
+		takenUsernames[user.Username] = true
+	}
+	existingStudents, err := s.repos.Users.ListByStudentIDs(studentIDs)
+	if err != nil {
+		return nil, err
+	}
+	takenStudentIDs := make(map[string]string)
+	for _, user := range existingStudents {
+		takenStudentIDs[user.StudentID] = user.Username
+	}
+
+	for _, row := range valid {
+		conflict := false
+		if takenUsernames[row.values["username"]] {
+			result.Errors = append(result.Errors, ImportUserRowError{Row: row.row, Field: "username", Message: "用户名已存在"})
+			conflict = true
+		}
+		if owner, ok := takenStudentIDs[row.values["student_id"]]; ok {
+			result.Errors = append(result.Errors, ImportUserRowError{Row: row.row, Field: "student_id", Message: fmt.Sprintf("学号已被用户 %s 使用", owner)})
+			conflict = true
+		}
+		if conflict {
+			continue
+		}
+		result.Users = append(result.Users, ImportedUser{
+			Row:       row.row,
+			Username:  row.values["username"],
+			RealName:  row.values["real_name"],
+			StudentID: row.values["student_id"],
+		})
+	}
+
+	if dryRun || len(result.Errors) > 0 || len(valid) == 0 {
+		return result, nil
+	}
+
+	users, err := buildImportUsers(valid, result.Users)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.repos.Transaction(func(tx *repository.Repositories) error {
+		for i := range users {
+			if err := tx.Users.Create(&users[i]); err != nil {
+				return err
+			}
+			if err := s.auditService.Record(tx.AuditLogs, op, AuditActionCreate, AuditEntityUser, users[i].ID, nil, &users[i]); err != nil {
+				return err
+			}
+			result.Users[i].ID = users[i].ID
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	result.Imported = true
+
+	for i := range users {
+		if err := s.webhookService.Publish(EventUserRegistered, 0, &users[i]); err != nil {
+			log.Printf("发布Webhook事件失败: %v", err)
+		}
+	}
+
+	return result, nil
+}
+
+// validateImportRow 校验导入行的必填项、长度和格式
+func validateImportRow(rowNum int, values map[string]string) []ImportUserRowError {
+	var rowErrors []ImportUserRowError
+	for _, column := range userImportColumns {
+		value := values[column.Key]
+		if column.Required && value == "" {
+			rowErrors = append(rowErrors, ImportUserRowError{Row: rowNum, Field: column.Key, Message: column.Title + "不能为空"})
+			continue
+		}
+		if utf8.RuneCountInString(value) > column.MaxLen {
+			rowErrors = append(rowErrors, ImportUserRowError{Row: rowNum, Field: column.Key, Message: fmt.Sprintf("%s不能超过%d个字符", column.Title, column.MaxLen)})
+		}
+	}
+
+	if password := values["password"]; password != "" && len(password) < 6 {
+		rowErrors = append(rowErrors, ImportUserRowError{Row: rowNum, Field: "password", Message: "密码长度不能少于6位"})
+	}
+	if email := values["email"]; email != "" {
+		if addr, err := mail.ParseAddress(email); err != nil || addr.Address != email {
+			rowErrors = append(rowErrors, ImportUserRowError{Row: rowNum, Field: "email", Message: "邮箱格式不正确"})
+		}
+	}
+
+	return rowErrors
+}
+
+// buildImportUsers 构造待创建的用户，并行计算密码哈希；未提供密码时生成初始密码并写入imported
+func buildImportUsers(rows []importUserRow, imported []ImportedUser) ([]model.User, error) {
+	users := make([]model.User, len(rows))
+	errs := make([]error, len(rows))
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, runtime.NumCPU())
+	for i, row := range rows {
+		password := row.values["password"]
+		if password == "" {
+			generated, err := utils.GeneratePassword(importPasswordLength)
+			if err != nil {
+				return nil, err
+			}
+			password = generated
+			imported[i].InitialPassword = generated
+		}
+
+		users[i] = model.User{
+			Username:  row.values["username"],
+			Nickname:  row.values["nickname"],
+			RealName:  row.values["real_name"],
+			College:   row.values["college"],
+			StudentID: row.values["student_id"],
+			QQ:        row.values["qq"],
+			Email:     row.values["email"],
+		}
+
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, password string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			users[i].Password, errs[i] = utils.HashPassword(password)
+		}(i, password)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return users, nil
+}
+
+// ExportUsers 按条件将用户名单及总分以表格格式写入w，查询失败时不写入任何内容
+func (s *UserService) ExportUsers(filter *UserExportFilter, format string, w io.Writer) error {
+	switch filter.Stage {
+	case "", repository.UserStageRegistered, repository.UserStageSubmitted, repository.UserStageScored:
+	default:
+		return errors.New("阶段无效")
+	}
+	rows, err := s.repos.Users.ListWithScores(repository.UserScoreFilter{
+		DirectionID: filter.DirectionID,
+		Stage:       filter.Stage,
+		MinScore:    filter.MinScore,
+		MaxScore:    filter.MaxScore,
+	})
+	if err != nil {
+		return err
+	}
+
+	writer, err := sheet.NewWriter(w, format)
+	if err != nil {
+		return err
+	}
+
+	header := []string{"ID", "用户名", "昵称", "姓名", "学院", "学号", "QQ", "邮箱", "管理员", "总分", "注册时间"}
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+	for _, row := range rows {
+		isAdmin := "否"
+		if row.IsAdmin {
+			isAdmin = "是"
+		}
+		record := []string{
+			strconv.FormatUint(uint64(row.ID), 10),
+			row.Username,
+			row.Nickname,
+			row.RealName,
+			row.College,
+			row.StudentID,
+			row.QQ,
+			row.Email,
+			isAdmin,
+			strconv.Itoa(row.TotalScore),
+			row.CreatedAt.Format("2006-01-02 15:04:05"),
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	return writer.Close()
+}
@@ -0,0 +1,82 @@
+package service
+
+import (
+	"bytes"
+	"encoding/csv"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/tksky1/glimgate/internal/model"
+	"github.com/tksky1/glimgate/internal/repository"
+	"github.com/tksky1/glimgate/pkg/sheet"
+)
+
+// exportUsernames 按筛选条件导出CSV并返回其中的用户名
+func exportUsernames(t *testing.T, users *UserService, filter *UserExportFilter) []string {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := users.ExportUsers(filter, sheet.FormatCSV, &buf); err != nil {
+		t.Fatal(err)
+	}
+	records, err := csv.NewReader(strings.NewReader(strings.TrimPrefix(buf.String(), "\ufeff"))).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := []string{}
+	for _, record := range records[1:] {
+		names = append(names, record[1])
+	}
+	return names
+}
+
+func TestExportUsersByStage(t *testing.T) {
+	env := newTestEnv(t)
+	users := NewUserService(env.repos, env.webhooks, env.audit)
+	submitted := env.createUser(t, "submitted", false)
+	scored := env.createUser(t, "scored", false)
+	env.createUser(t, "idle", false)
+
+	first, point := env.createProblem(t, "第一题")
+	env.create(t, &model.Submission{Content: "x", UserID: submitted.ID, ProblemID: first.ID, SubmissionPointID: point.ID})
+	sub := model.Submission{Content: "x", UserID: scored.ID, ProblemID: first.ID, SubmissionPointID: point.ID}
+	env.create(t, &sub)
+	env.create(t, &model.Score{Score: 70, UserID: scored.ID, SubmissionID: sub.ID, ReviewerID: env.admin.ID})
+	// 已评分的选手在另一个方向只有未评分的提交
+	second, secondPoint := env.createProblem(t, "第二题")
+	env.create(t, &model.Submission{Content: "x", UserID: scored.ID, ProblemID: second.ID, SubmissionPointID: secondPoint.ID})
+
+	tests := []struct {
+		name   string
+		filter UserExportFilter
+		want   []string
+	}{
+		{"不筛选", UserExportFilter{}, []string{"admin", "submitted", "scored", "idle"}},
+		{"未提交", UserExportFilter{Stage: repository.UserStageRegistered}, []string{"idle"}},
+		{"已提交未评分", UserExportFilter{Stage: repository.UserStageSubmitted}, []string{"submitted"}},
+		{"已评分", UserExportFilter{Stage: repository.UserStageScored}, []string{"scored"}},
+		{"按方向划分", UserExportFilter{DirectionID: second.DirectionID, Stage: repository.UserStageSubmitted}, []string{"scored"}},
+		{"与总分范围组合", UserExportFilter{Stage: repository.UserStageScored, MaxScore: intPtr(10)}, []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter := tt.filter
+			if got := exportUsernames(t, users, &filter); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("导出%v，应为%v", got, tt.want)
+			}
+		})
+	}
+
+	var buf bytes.Buffer
+	err := users.ExportUsers(&UserExportFilter{Stage: "accepted"}, sheet.FormatCSV, &buf)
+	if err == nil || err.Error() != "阶段无效" {
+		t.Fatalf("阶段无效时应返回错误，得到%v", err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("出错时不应写入内容: %q", buf.String())
+	}
+}
+
+func intPtr(v int) *int {
+	return &v
+}
@@ -0,0 +1,483 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tksky1/glimgate/internal/model"
+	"github.com/tksky1/glimgate/internal/repository"
+	"github.com/tksky1/glimgate/pkg/config"
+	"github.com/tksky1/glimgate/pkg/utils"
+)
+
+// Webhook事件类型
+const (
+	EventSubmissionCreated = "submission.created"
+	EventSubmissionUpdated = "submission.updated"
+	EventSubmissionDeleted = "submission.deleted"
+	EventScoreCreated      = "score.created"
+	EventScoreUpdated      = "score.updated"
+	EventScoreDeleted      = "score.deleted"
+	EventUserRegistered    = "user.registered"
+)
+
+// WebhookEventTypes 支持订阅的全部事件类型
+var WebhookEventTypes = []string{
+	EventSubmissionCreated,
+	EventSubmissionUpdated,
+	EventSubmissionDeleted,
+	EventScoreCreated,
+	EventScoreUpdated,
+	EventScoreDeleted,
+	EventUserRegistered,
+}
+
+// Webhook投递状态
+const (
+	DeliveryPending   = repository.WebhookDeliveryPending
+	DeliverySucceeded = repository.WebhookDeliverySucceeded
+	DeliveryFailed    = repository.WebhookDeliveryFailed
+)
+
+// webhookWakeup 有新的待投递记录时唤醒投递任务
+var webhookWakeup = make(chan struct{}, 1)
+
+// WebhookService Webhook服务
+type WebhookService struct {
+	repos *repository.Repositories
+}
+
+// CreateWebhookRequest 创建Webhook订阅请求结构
+type CreateWebhookRequest struct {
+	Name        string   `json:"name" binding:"required" example:"招新群机器人"`
+	URL         string   `json:"url" binding:"required" example:"https://bot.example.com/glimgate"`
+	Secret      string   `json:"secret" example:""`
+	EventTypes  []string `json:"event_types" example:"submission.created,score.created"`
+	DirectionID uint     `json:"direction_id" example:"0"`
+	Enabled     *bool    `json:"enabled" example:"true"`
+}
+
+// UpdateWebhookRequest 更新Webhook订阅请求结构
+type UpdateWebhookRequest struct {
+	Name        string   `json:"name" example:"招新群机器人"`
+	URL         string   `json:"url" example:"https://bot.example.com/glimgate"`
+	Secret      string   `json:"secret" example:""`
+	EventTypes  []string `json:"event_types" example:"submission.created,score.created"`
+	DirectionID *uint    `json:"direction_id" example:"0"`
+	Enabled     *bool    `json:"enabled" example:"true"`
+}
+
+// WebhookEvent Webhook事件请求体
+type WebhookEvent struct {
+	ID        string      `json:"id"`
+	Event     string      `json:"event"`
+	CreatedAt time.Time   `json:"created_at"`
+	Data      interface{} `json:"data"`
+}
+
+// NewWebhookService 创建Webhook服务实例
+func NewWebhookService(repos *repository.Repositories) *WebhookService {
+	return &WebhookService{repos: repos}
+}
+
+// CreateWebhook 创建Webhook订阅
+func (s *WebhookService) CreateWebhook(req *CreateWebhookRequest) (*model.WebhookSubscription, error) {
+	if err := validateWebhookURL(req.URL); err != nil {
+		return nil, err
+	}
+	eventTypes, err := normalizeEventTypes(req.EventTypes)
+	if err != nil {
+		return nil, err
+	}
+
+	secret := req.Secret
+	if secret == "" {
+		if secret, err = utils.RandomHex(32); err != nil {
+			return nil, err
+		}
+	}
+
+	subscription := model.WebhookSubscription{
+		Name:        req.Name,
+		URL:         req.URL,
+		Secret:      secret,
+		EventTypes:  eventTypes,
+		DirectionID: req.DirectionID,
+		Enabled:     req.Enabled == nil || *req.Enabled,
+	}
+	if err := s.repos.Webhooks.CreateSubscription(&subscription); err != nil {
+		return nil, err
+	}
+
+	return &subscription, nil
+}
+
+// GetWebhooks 获取Webhook订阅列表
+func (s *WebhookService) GetWebhooks() ([]model.WebhookSubscription, error) {
+	return s.repos.Webhooks.ListSubscriptions(false)
+}
+
+// GetWebhookByID 根据ID获取Webhook订阅
+func (s *WebhookService) GetWebhookByID(subscriptionID uint) (*model.WebhookSubscription, error) {
+	subscription, err := s.repos.Webhooks.FindSubscription(subscriptionID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, errors.New("Webhook不存在")
+		}
+		return nil, err
+	}
+
+	return subscription, nil
+}
+
+// UpdateWebhook 更新Webhook订阅，重新启用时清空失败计数
+func (s *WebhookService) UpdateWebhook(subscriptionID uint, req *UpdateWebhookRequest) (*model.WebhookSubscription, error) {
+	subscription, err := s.GetWebhookByID(subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	updates := make(map[string]interface{})
+	if req.Name != "" {
+		updates["name"] = req.Name
+	}
+	if req.URL != "" {
+		if err := validateWebhookURL(req.URL); err != nil {
+			return nil, err
+		}
+		updates["url"] = req.URL
+	}
+	if req.Secret != "" {
+		updates["secret"] = req.Secret
+	}
+	if req.EventTypes != nil {
+		eventTypes, err := normalizeEventTypes(req.EventTypes)
+		if err != nil {
+			return nil, err
+		}
+		updates["event_types"] = eventTypes
+	}
+	if req.DirectionID != nil {
+		updates["direction_id"] = *req.DirectionID
+	}
+	if req.Enabled != nil {
+		updates["enabled"] = *req.Enabled
+		if *req.Enabled {
+			updates["consecutive_failures"] = 0
+			updates["disabled_reason"] = ""
+		}
+	}
+
+	if len(updates) > 0 {
+		if err := s.repos.Webhooks.UpdateSubscription(subscription, updates); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.GetWebhookByID(subscriptionID)
+}
+
+// DeleteWebhook 删除Webhook订阅
+func (s *WebhookService) DeleteWebhook(subscriptionID uint) error {
+	subscription, err := s.GetWebhookByID(subscriptionID)
+	if err != nil {
+		return err
+	}
+
+	return s.repos.Webhooks.DeleteSubscription(subscription)
+}
+
+// GetDeliveries 获取订阅的投递记录
+func (s *WebhookService) GetDeliveries(subscriptionID uint, status string, page, pageSize int) ([]model.WebhookDelivery, int64, error) {
+	offset := (page - 1) * pageSize
+	return s.repos.Webhooks.ListDeliveries(subscriptionID, status, offset, pageSize)
+}
+
+// Redeliver 以相同的事件内容重新投递
+func (s *WebhookService) Redeliver(deliveryID uint) (*model.WebhookDelivery, error) {
+	original, err := s.repos.Webhooks.FindDelivery(deliveryID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, errors.New("投递记录不存在")
+		}
+		return nil, err
+	}
+
+	now := time.Now()
+	delivery := model.WebhookDelivery{
+		SubscriptionID: original.SubscriptionID,
+		EventID:        original.EventID,
+		EventType:      original.EventType,
+		Payload:        original.Payload,
+		Status:         DeliveryPending,
+		NextAttemptAt:  &now,
+	}
+	if err := s.repos.Webhooks.CreateDelivery(&delivery); err != nil {
+		return nil, err
+	}
+
+	wakeWebhookWorker()
+	return &delivery, nil
+}
+
+// Publish 将事件写入所有匹配订阅的发件箱，directionID为0表示与方向无关的事件
+func (s *WebhookService) Publish(eventType string, directionID uint, data interface{}) error {
+	subscriptions, err := s.repos.Webhooks.ListSubscriptions(true)
+	if err != nil {
+		return err
+	}
+
+	eventID, err := utils.RandomHex(16)
+	if err != nil {
+		return err
+	}
+	payload, err := json.Marshal(WebhookEvent{
+		ID:        eventID,
+		Event:     eventType,
+		CreatedAt: time.Now(),
+		Data:      data,
+	})
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	var deliveries []model.WebhookDelivery
+	for _, subscription := range subscriptions {
+		if !subscriptionMatches(subscription, eventType, directionID) {
+			continue
+		}
+		deliveries = append(deliveries, model.WebhookDelivery{
+			SubscriptionID: subscription.ID,
+			EventID:        eventID,
+			EventType:      eventType,
+			Payload:        string(payload),
+			Status:         DeliveryPending,
+			NextAttemptAt:  &now,
+		})
+	}
+	if len(deliveries) == 0 {
+		return nil
+	}
+
+	if err := s.repos.Webhooks.CreateDeliveries(deliveries); err != nil {
+		return err
+	}
+
+	wakeWebhookWorker()
+	return nil
+}
+
+// RunDeliveryWorker 循环投递发件箱中到期的记录，应在独立goroutine中运行
+func (s *WebhookService) RunDeliveryWorker() {
+	interval := time.Duration(webhookConfig().PollIntervalSeconds) * time.Second
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.ProcessDueDeliveries(); err != nil {
+			log.Printf("投递Webhook失败: %v", err)
+		}
+		select {
+		case <-ticker.C:
+		case <-webhookWakeup:
+		}
+	}
+}
+
+// ProcessDueDeliveries 投递所有已到重试时间的记录
+func (s *WebhookService) ProcessDueDeliveries() error {
+	deliveries, err := s.repos.Webhooks.ListDueDeliveries(time.Now(), 100)
+	if err != nil {
+		return err
+	}
+
+	for i := range deliveries {
+		if err := s.attempt(&deliveries[i]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// attempt 执行一次投递并按结果更新投递记录和订阅状态
+func (s *WebhookService) attempt(delivery *model.WebhookDelivery) error {
+	cfg := webhookConfig()
+
+	subscription, err := s.repos.Webhooks.FindSubscription(delivery.SubscriptionID)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	statusCode, sendErr := sendWebhook(subscription, delivery, cfg.TimeoutSeconds)
+
+	updates := map[string]interface{}{
+		"attempts":        delivery.Attempts + 1,
+		"last_attempt_at": now,
+		"response_code":   statusCode,
+	}
+	if sendErr == nil {
+		updates["status"] = DeliverySucceeded
+		updates["last_error"] = ""
+		updates["next_attempt_at"] = nil
+		return s.repos.Transaction(func(tx *repository.Repositories) error {
+			if err := tx.Webhooks.UpdateDelivery(delivery, updates); err != nil {
+				return err
+			}
+			return tx.Webhooks.UpdateSubscription(subscription, map[string]interface{}{"consecutive_failures": 0})
+		})
+	}
+
+	updates["last_error"] = sendErr.Error()
+	if delivery.Attempts+1 >= cfg.MaxAttempts {
+		updates["status"] = DeliveryFailed
+		updates["next_attempt_at"] = nil
+	} else {
+		updates["next_attempt_at"] = now.Add(webhookBackoff(delivery.Attempts+1, cfg))
+	}
+
+	// 连续失败达到阈值后自动停用订阅
+	failures := subscription.ConsecutiveFailures + 1
+	subscriptionUpdates := map[string]interface{}{"consecutive_failures": failures}
+	if failures >= cfg.DisableAfterFailures {
+		subscriptionUpdates["enabled"] = false
+		subscriptionUpdates["disabled_reason"] = fmt.Sprintf("连续投递失败%d次，已自动停用", failures)
+		log.Printf("Webhook订阅%d连续投递失败%d次，已自动停用", subscription.ID, failures)
+	}
+
+	return s.repos.Transaction(func(tx *repository.Repositories) error {
+		if err := tx.Webhooks.UpdateDelivery(delivery, updates); err != nil {
+			return err
+		}
+		return tx.Webhooks.UpdateSubscription(subscription, subscriptionUpdates)
+	})
+}
+
+// sendWebhook 发送带签名的Webhook请求，返回HTTP状态码
+func sendWebhook(subscription *model.WebhookSubscription, delivery *model.WebhookDelivery, timeoutSeconds int) (int, error) {
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	signature := utils.HMACSHA256([]byte(subscription.Secret), []byte(timestamp+"."+delivery.Payload))
+
+	req, err := http.NewRequest(http.MethodPost, subscription.URL, bytes.NewReader([]byte(delivery.Payload)))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "GlimGate-Webhook")
+	req.Header.Set("X-GlimGate-Event", delivery.EventType)
+	req.Header.Set("X-GlimGate-Delivery", strconv.FormatUint(uint64(delivery.ID), 10))
+	req.Header.Set("X-GlimGate-Timestamp", timestamp)
+	req.Header.Set("X-GlimGate-Signature", "sha256="+signature)
+
+	client := &http.Client{Timeout: time.Duration(timeoutSeconds) * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return resp.StatusCode, fmt.Errorf("HTTP %d: %s", resp.StatusCode, string(body))
+	}
+
+	return resp.StatusCode, nil
+}
+
+// webhookBackoff 计算第attempts次失败后的重试间隔
+func webhookBackoff(attempts int, cfg config.WebhookDeliveryConfig) time.Duration {
+	backoff := time.Duration(cfg.BaseBackoffSeconds) * time.Second
+	maxBackoff := time.Duration(cfg.MaxBackoffSeconds) * time.Second
+	for i := 1; i < attempts && backoff < maxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > maxBackoff {
+		backoff = maxBackoff
+	}
+	return backoff
+}
+
+// webhookConfig 获取投递配置，未配置的项使用默认值
+func webhookConfig() config.WebhookDeliveryConfig {
+	cfg := config.AppConfig.WebhookDelivery
+	if cfg.TimeoutSeconds <= 0 {
+		cfg.TimeoutSeconds = 10
+	}
+	if cfg.PollIntervalSeconds <= 0 {
+		cfg.PollIntervalSeconds = 5
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 8
+	}
+	if cfg.BaseBackoffSeconds <= 0 {
+		cfg.BaseBackoffSeconds = 30
+	}
+	if cfg.MaxBackoffSeconds <= 0 {
+		cfg.MaxBackoffSeconds = 3600
+	}
+	if cfg.DisableAfterFailures <= 0 {
+		cfg.DisableAfterFailures = 20
+	}
+	return cfg
+}
+
+// wakeWebhookWorker 非阻塞地唤醒投递任务
+func wakeWebhookWorker() {
+	select {
+	case webhookWakeup <- struct{}{}:
+	default:
+	}
+}
+
+// subscriptionMatches 判断订阅是否关注该事件
+func subscriptionMatches(subscription model.WebhookSubscription, eventType string, directionID uint) bool {
+	if subscription.DirectionID > 0 && subscription.DirectionID != directionID {
+		return false
+	}
+	if subscription.EventTypes == "" {
+		return true
+	}
+	for _, t := range strings.Split(subscription.EventTypes, ",") {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeEventTypes 校验事件类型并拼接为逗号分隔字符串，空列表表示订阅全部事件
+func normalizeEventTypes(eventTypes []string) (string, error) {
+	for _, t := range eventTypes {
+		valid := false
+		for _, known := range WebhookEventTypes {
+			if t == known {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return "", errors.New("不支持的事件类型")
+		}
+	}
+	return strings.Join(eventTypes, ","), nil
+}
+
+// validateWebhookURL 校验Webhook地址
+func validateWebhookURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.New("Webhook地址格式错误")
+	}
+	return nil
+}
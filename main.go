@@ -3,15 +3,22 @@ package main
 import (
 	"fmt"
 	"log"
+	"os"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 	_ "github.com/tksky1/glimgate/docs" // 添加这行
+	"github.com/tksky1/glimgate/internal/api"
+	"github.com/tksky1/glimgate/internal/cli"
 	"github.com/tksky1/glimgate/internal/middleware"
+	"github.com/tksky1/glimgate/internal/repository"
 	"github.com/tksky1/glimgate/internal/router"
+	"github.com/tksky1/glimgate/internal/service"
 	"github.com/tksky1/glimgate/pkg/config"
 	"github.com/tksky1/glimgate/pkg/database"
+	"github.com/tksky1/glimgate/pkg/storage"
 )
 
 // @title GlimGate API
@@ -40,10 +47,77 @@ func main() {
 	}
 
 	// 初始化数据库
-	if err := database.InitDB(); err != nil {
+	db, err := database.InitDB()
+	if err != nil {
 		log.Fatalf("初始化数据库失败: %v", err)
 	}
 
+	// 初始化附件存储
+	store, err := storage.InitStorage()
+	if err != nil {
+		log.Fatalf("初始化附件存储失败: %v", err)
+	}
+
+	// 子命令：glimgate migrate up|down [steps]|status，其他管理命令见glimgatectl
+	if len(os.Args) > 1 {
+		if os.Args[1] != "migrate" {
+			log.Fatalf("未知的子命令: %s", os.Args[1])
+		}
+		if err := cli.New(db, store, os.Stdout).Migrate(os.Args[2:]); err != nil {
+			log.Fatalf("数据库迁移失败: %v", err)
+		}
+		return
+	}
+
+	// 数据库结构落后时拒绝启动
+	if err := database.CheckSchema(db); err != nil {
+		log.Fatalf("检查数据库结构失败: %v", err)
+	}
+
+	// 组装数据访问层与服务
+	repos := repository.NewRepositories(db)
+
+	auditService := service.NewAuditService(repos)
+	notificationService := service.NewNotificationService(repos)
+	webhookService := service.NewWebhookService(repos)
+	userService := service.NewUserService(repos, webhookService, auditService)
+	directionService := service.NewDirectionService(repos, auditService)
+	problemService := service.NewProblemService(repos, store, notificationService, auditService)
+	submissionService := service.NewSubmissionService(repos, notificationService, webhookService)
+	scoreService := service.NewScoreService(repos, notificationService, webhookService, auditService)
+	clarificationService := service.NewClarificationService(db, directionService, notificationService)
+	trashService := service.NewTrashService(repos, store, auditService)
+	judgeService := service.NewJudgeService(repos, store, scoreService, auditService)
+	checkService := service.NewCheckService(repos, auditService)
+	similarityService := service.NewSimilarityService(repos)
+	teamService := service.NewTeamService(repos, notificationService)
+
+	// 启动截止提醒任务
+	go notificationService.RunDeadlineReminder(10 * time.Minute)
+
+	// 启动Webhook投递任务
+	go webhookService.RunDeliveryWorker()
+
+	// 启动题目定时发布任务
+	go problemService.RunPublishScheduler()
+
+	// 启动回收站清理任务
+	go trashService.RunPurge()
+
+	// 启动自动评测任务
+	if config.AppConfig.Judge.Enabled {
+		if err := judgeService.StartWorker(); err != nil {
+			log.Fatalf("启动自动评测失败: %v", err)
+		}
+	}
+
+	// 启动代码仓库检查任务
+	if config.AppConfig.Checks.Enabled {
+		if err := checkService.StartWorker(); err != nil {
+			log.Fatalf("启动代码仓库检查失败: %v", err)
+		}
+	}
+
 	// 设置Gin模式
 	gin.SetMode(config.AppConfig.Server.Mode)
 
@@ -51,12 +125,28 @@ func main() {
 	r := gin.Default()
 
 	// 添加中间件
+	r.Use(middleware.RequestIDMiddleware())
 	r.Use(middleware.CORSMiddleware())
 	r.SetTrustedProxies(nil)
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
 	// 设置路由
-	router.SetupRoutes(r)
+	router.SetupRoutes(r, &router.Handlers{
+		User:          api.NewUserAPI(userService),
+		Direction:     api.NewDirectionAPI(directionService),
+		Problem:       api.NewProblemAPI(problemService, directionService),
+		Submission:    api.NewSubmissionAPI(submissionService),
+		Score:         api.NewScoreAPI(scoreService),
+		Clarification: api.NewClarificationAPI(clarificationService),
+		Notification:  api.NewNotificationAPI(notificationService),
+		Webhook:       api.NewWebhookAPI(webhookService),
+		Audit:         api.NewAuditAPI(auditService),
+		Trash:         api.NewTrashAPI(trashService),
+		Judge:         api.NewJudgeAPI(judgeService),
+		Check:         api.NewCheckAPI(checkService),
+		Similarity:    api.NewSimilarityAPI(similarityService),
+		Team:          api.NewTeamAPI(teamService),
+	})
 
 	// 启动服务器
 	addr := fmt.Sprintf("0.0.0.0:%d", config.AppConfig.Server.Port)
@@ -0,0 +1,474 @@
+package bundle
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/tksky1/glimgate/pkg/markdown"
+	"gopkg.in/yaml.v3"
+)
+
+// ManifestFile 题目包中清单文件的文件名
+const ManifestFile = "problem.yaml"
+
+// FormatVersion 当前的题目包格式版本
+const FormatVersion = 1
+
+// AttachmentDir 题目包中存放附件文件的目录，与清单位于同一目录
+const AttachmentDir = "attachments"
+
+// MaxAttachmentsSize 题目包中附件解压后的总大小上限
+const MaxAttachmentsSize = 200 << 20
+
+// MaxDifficulty 题目难度等级上限，难度为1到MaxDifficulty，0表示未设置
+const MaxDifficulty = 5
+
+// MaxTags 每道题目的标签数量上限
+const MaxTags = 20
+
+// MaxTeamSize 组队题目的队伍人数上限，队伍人数为2到MaxTeamSize，0表示个人题目
+const MaxTeamSize = 20
+
+// ErrInvalid 题目包格式错误，具体原因附在错误信息中
+var ErrInvalid = errors.New("题目包格式错误")
+
+// sha256Pattern 十六进制小写的SHA-256校验和
+var sha256Pattern = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+// slugPattern 题目标识只能包含小写字母、数字、短横线和下划线，以字母或数字开头
+var slugPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,99}$`)
+
+// Manifest 题目包清单，对应problem.yaml
+type Manifest struct {
+	Version          int    `yaml:"version"`
+	Slug             string `yaml:"slug"`
+	Direction        string `yaml:"direction"`
+	Title            string `yaml:"title"`
+	Description      string `yaml:"description"`
+	Difficulty       int    `yaml:"difficulty,omitempty"`        // 难度等级1-5，不填表示未设置
+	EstimatedMinutes int    `yaml:"estimated_minutes,omitempty"` // 预计用时（分钟），不填表示未设置
+	TeamSize         int    `yaml:"team_size,omitempty"`         // 组队题目的队伍人数上限2-20，不填表示个人题目
+	// Tags 题目标签，导入时同步为列表中的标签
+	Tags             []string `yaml:"tags,omitempty"`
+	SubmissionPoints []Point  `yaml:"submission_points"`
+	// Attachments 题目附件，文件位于attachments目录；未填写时导入不改动已有附件，填写（包括空列表）时同步为列表中的附件
+	Attachments []Attachment `yaml:"attachments,omitempty"`
+}
+
+// Point 题目包中的提交点，同一题目内按名称区分
+type Point struct {
+	Name     string     `yaml:"name"`
+	MaxScore int        `yaml:"max_score"`
+	Deadline *time.Time `yaml:"deadline,omitempty"`
+	Rubric   string     `yaml:"rubric,omitempty"`
+}
+
+// Attachment 题目包中的附件，同一题目内按文件名区分
+// SHA256可选；填写时题目包中可以不带该文件，导入时要求已有同名附件的校验和一致
+type Attachment struct {
+	Name   string `yaml:"name"`
+	SHA256 string `yaml:"sha256,omitempty"`
+}
+
+// Bundle 题目包，Files为attachments目录中的文件内容，键为文件名
+type Bundle struct {
+	Manifest Manifest
+	Files    map[string][]byte
+}
+
+// Checksum 计算文件内容的SHA-256校验和
+func Checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// validAttachmentName 附件文件名不能包含路径
+func validAttachmentName(name string) bool {
+	return name != "" && name != "." && name != ".." && !strings.ContainsAny(name, "/\\") &&
+		strings.TrimSpace(name) == name && utf8.RuneCountInString(name) <= 255
+}
+
+// checkFiles 检查附件文件与清单是否一致：文件都已在清单中声明，填写了校验和的文件内容与之相符
+func (b *Bundle) checkFiles() error {
+	declared := make(map[string]string, len(b.Manifest.Attachments))
+	for _, attachment := range b.Manifest.Attachments {
+		declared[attachment.Name] = attachment.SHA256
+	}
+	for name, data := range b.Files {
+		sum, ok := declared[name]
+		if !ok {
+			return invalid("%s/%s未在%s的attachments中声明", AttachmentDir, name, ManifestFile)
+		}
+		if sum != "" && sum != Checksum(data) {
+			return invalid("附件「%s」的校验和与sha256不一致", name)
+		}
+	}
+	for _, attachment := range b.Manifest.Attachments {
+		if _, ok := b.Files[attachment.Name]; !ok && attachment.SHA256 == "" {
+			return invalid("缺少附件文件%s/%s，未附带文件时必须填写sha256", AttachmentDir, attachment.Name)
+		}
+	}
+	return nil
+}
+
+// ValidSlug 检查题目标识格式
+func ValidSlug(slug string) bool {
+	return slugPattern.MatchString(slug)
+}
+
+// ValidTeamSize 检查队伍人数上限：0表示个人题目，组队题目为2到MaxTeamSize
+func ValidTeamSize(size int) bool {
+	return size == 0 || (size >= 2 && size <= MaxTeamSize)
+}
+
+// ValidTag 检查标签格式：不能为空、首尾不能有空白、不能包含逗号，不超过50个字符
+func ValidTag(tag string) bool {
+	return tag != "" && strings.TrimSpace(tag) == tag && !strings.Contains(tag, ",") && utf8.RuneCountInString(tag) <= 50
+}
+
+// invalid 构造题目包格式错误
+func invalid(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %s", ErrInvalid, fmt.Sprintf(format, args...))
+}
+
+// Validate 校验清单的版本、必填项、长度和提交点名称唯一性
+func (m *Manifest) Validate() error {
+	if m.Version != FormatVersion {
+		return invalid("不支持的版本 %d，当前版本为 %d", m.Version, FormatVersion)
+	}
+	if !ValidSlug(m.Slug) {
+		return invalid("slug只能包含小写字母、数字、-和_，以字母或数字开头，不超过100个字符")
+	}
+	if strings.TrimSpace(m.Direction) == "" {
+		return invalid("direction不能为空")
+	}
+	if strings.TrimSpace(m.Title) == "" {
+		return invalid("title不能为空")
+	}
+	if utf8.RuneCountInString(m.Title) > 200 {
+		return invalid("title不能超过200个字符")
+	}
+	if strings.TrimSpace(m.Description) == "" {
+		return invalid("description不能为空")
+	}
+	if err := markdown.Check(m.Description); err != nil {
+		return invalid("description%v", err)
+	}
+	if m.Difficulty < 0 || m.Difficulty > MaxDifficulty {
+		return invalid("difficulty应为1到%d，不填表示未设置", MaxDifficulty)
+	}
+	if m.EstimatedMinutes < 0 {
+		return invalid("estimated_minutes不能为负数")
+	}
+	if !ValidTeamSize(m.TeamSize) {
+		return invalid("team_size应为2到%d，不填表示个人题目", MaxTeamSize)
+	}
+	if len(m.Tags) > MaxTags {
+		return invalid("tags不能超过%d个", MaxTags)
+	}
+	tags := make(map[string]bool)
+	for i, tag := range m.Tags {
+		if !ValidTag(tag) {
+			return invalid("第%d个标签无效，不能为空、包含逗号或超过50个字符", i+1)
+		}
+		if tags[tag] {
+			return invalid("标签「%s」重复", tag)
+		}
+		tags[tag] = true
+	}
+
+	names := make(map[string]bool)
+	for i, point := range m.SubmissionPoints {
+		if strings.TrimSpace(point.Name) == "" {
+			return invalid("第%d个提交点的name不能为空", i+1)
+		}
+		if utf8.RuneCountInString(point.Name) > 100 {
+			return invalid("提交点「%s」的name不能超过100个字符", point.Name)
+		}
+		if names[point.Name] {
+			return invalid("提交点「%s」重复", point.Name)
+		}
+		names[point.Name] = true
+		if point.MaxScore < 1 {
+			return invalid("提交点「%s」的max_score必须大于0", point.Name)
+		}
+	}
+
+	attachments := make(map[string]bool)
+	for i, attachment := range m.Attachments {
+		if !validAttachmentName(attachment.Name) {
+			return invalid("第%d个附件的name无效，不能为空、包含路径或超过255个字符", i+1)
+		}
+		if attachments[attachment.Name] {
+			return invalid("附件「%s」重复", attachment.Name)
+		}
+		attachments[attachment.Name] = true
+		if attachment.SHA256 != "" && !sha256Pattern.MatchString(attachment.SHA256) {
+			return invalid("附件「%s」的sha256应为64位小写十六进制", attachment.Name)
+		}
+	}
+	return nil
+}
+
+// ParseManifest 解析并校验清单，不允许出现未知字段
+func ParseManifest(data []byte) (*Manifest, error) {
+	var manifest Manifest
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&manifest); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, invalid("%s为空", ManifestFile)
+		}
+		return nil, invalid("解析%s失败: %v", ManifestFile, err)
+	}
+	if err := manifest.Validate(); err != nil {
+		return nil, err
+	}
+	return &manifest, nil
+}
+
+// MarshalManifest 将清单序列化为YAML
+func MarshalManifest(m *Manifest) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(m); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// ReadFile 根据文件名读取题目包，支持ZIP压缩包或单独的problem.yaml
+func ReadFile(filename string, data []byte) (*Bundle, error) {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".zip":
+		return readZip(data)
+	case ".yaml", ".yml":
+		manifest, err := ParseManifest(data)
+		if err != nil {
+			return nil, err
+		}
+		b := &Bundle{Manifest: *manifest}
+		if err := b.checkFiles(); err != nil {
+			return nil, err
+		}
+		return b, nil
+	default:
+		return nil, invalid("不支持的文件格式，仅支持zip、yaml和yml")
+	}
+}
+
+// readZip 读取ZIP格式的题目包，清单可以位于根目录或唯一的顶层目录中，附件位于清单同级的attachments目录
+func readZip(data []byte) (*Bundle, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, invalid("解析zip文件失败: %v", err)
+	}
+
+	var manifestFile *zip.File
+	for _, f := range zr.File {
+		name := strings.TrimPrefix(path.Clean(f.Name), "/")
+		if name == ManifestFile || (path.Base(name) == ManifestFile && strings.Count(name, "/") == 1) {
+			if manifestFile != nil {
+				return nil, invalid("压缩包中有多个%s", ManifestFile)
+			}
+			manifestFile = f
+		}
+	}
+	if manifestFile == nil {
+		return nil, invalid("压缩包中缺少%s", ManifestFile)
+	}
+
+	content, err := readZipFile(manifestFile, -1)
+	if err != nil {
+		return nil, invalid("读取%s失败: %v", ManifestFile, err)
+	}
+
+	manifest, err := ParseManifest(content)
+	if err != nil {
+		return nil, err
+	}
+	b := &Bundle{Manifest: *manifest, Files: make(map[string][]byte)}
+
+	// 读取清单同级attachments目录中的文件
+	prefix := path.Join(path.Dir(strings.TrimPrefix(path.Clean(manifestFile.Name), "/")), AttachmentDir) + "/"
+	prefix = strings.TrimPrefix(prefix, "./")
+	var total int64
+	for _, f := range zr.File {
+		name := strings.TrimPrefix(path.Clean(f.Name), "/")
+		if f.FileInfo().IsDir() || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		filename := strings.TrimPrefix(name, prefix)
+		if strings.Contains(filename, "/") {
+			return nil, invalid("%s目录中不能包含子目录: %s", AttachmentDir, filename)
+		}
+		fileData, err := readZipFile(f, MaxAttachmentsSize-total)
+		if err != nil {
+			return nil, invalid("读取附件%s失败: %v", filename, err)
+		}
+		total += int64(len(fileData))
+		b.Files[filename] = fileData
+	}
+
+	if err := b.checkFiles(); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// readZipFile 读取压缩包中的文件，limit不小于0时限制解压后的大小
+func readZipFile(f *zip.File, limit int64) ([]byte, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	if limit < 0 {
+		return io.ReadAll(rc)
+	}
+	data, err := io.ReadAll(io.LimitReader(rc, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > limit {
+		return nil, fmt.Errorf("附件总大小超过%dMB", MaxAttachmentsSize>>20)
+	}
+	return data, nil
+}
+
+// ReadPath 从目录、ZIP压缩包或problem.yaml读取题目包，供命令行使用
+// 读取目录时同时读取其中attachments目录下的附件文件
+func ReadPath(p string) (*Bundle, error) {
+	info, err := os.Stat(p)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return nil, err
+		}
+		return ReadFile(p, data)
+	}
+
+	data, err := os.ReadFile(filepath.Join(p, ManifestFile))
+	if err != nil {
+		return nil, err
+	}
+	manifest, err := ParseManifest(data)
+	if err != nil {
+		return nil, err
+	}
+	b := &Bundle{Manifest: *manifest, Files: make(map[string][]byte)}
+
+	entries, err := os.ReadDir(filepath.Join(p, AttachmentDir))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	var total int64
+	for _, entry := range entries {
+		if entry.IsDir() {
+			return nil, invalid("%s目录中不能包含子目录: %s", AttachmentDir, entry.Name())
+		}
+		fileData, err := os.ReadFile(filepath.Join(p, AttachmentDir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		total += int64(len(fileData))
+		if total > MaxAttachmentsSize {
+			return nil, invalid("附件总大小超过%dMB", MaxAttachmentsSize>>20)
+		}
+		b.Files[entry.Name()] = fileData
+	}
+
+	if err := b.checkFiles(); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// WriteZip 将题目包写为ZIP压缩包，清单位于根目录，附件位于attachments目录
+func WriteZip(w io.Writer, b *Bundle) error {
+	data, err := MarshalManifest(&b.Manifest)
+	if err != nil {
+		return err
+	}
+
+	zw := zip.NewWriter(w)
+	now := time.Now()
+	if err := writeZipFile(zw, ManifestFile, data, now); err != nil {
+		return err
+	}
+	for _, attachment := range b.Manifest.Attachments {
+		fileData, ok := b.Files[attachment.Name]
+		if !ok {
+			continue
+		}
+		if err := writeZipFile(zw, AttachmentDir+"/"+attachment.Name, fileData, now); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+// writeZipFile 向压缩包写入一个文件
+func writeZipFile(zw *zip.Writer, name string, data []byte, modified time.Time) error {
+	f, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: modified})
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	return err
+}
+
+// WriteDir 将题目包写入目录，目录不存在时自动创建
+// 已有的清单会被覆盖，attachments目录会被清空后重新写入，以免残留的旧附件导致重新导入失败
+func WriteDir(dir string, b *Bundle) error {
+	data, err := MarshalManifest(&b.Manifest)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(dir, ManifestFile), data, 0o644); err != nil {
+		return err
+	}
+
+	attachmentDir := filepath.Join(dir, AttachmentDir)
+	if err := os.RemoveAll(attachmentDir); err != nil {
+		return err
+	}
+	if len(b.Files) == 0 {
+		return nil
+	}
+	if err := os.MkdirAll(attachmentDir, 0o755); err != nil {
+		return err
+	}
+	for name, fileData := range b.Files {
+		if !validAttachmentName(name) {
+			return invalid("附件文件名无效: %s", name)
+		}
+		if err := os.WriteFile(filepath.Join(attachmentDir, name), fileData, 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -13,6 +13,15 @@ type Config struct {
 	Database DatabaseConfig `yaml:"database"`
 	JWT      JWTConfig      `yaml:"jwt"`
 	CORS     CORSConfig     `yaml:"cors"`
+
+	Notification    NotificationConfig    `yaml:"notification"`
+	WebhookDelivery WebhookDeliveryConfig `yaml:"webhook_delivery"`
+	Trash           TrashConfig           `yaml:"trash"`
+	Storage         StorageConfig         `yaml:"storage"`
+	Judge           JudgeConfig           `yaml:"judge"`
+	Checks          ChecksConfig          `yaml:"checks"`
+	Similarity      SimilarityConfig      `yaml:"similarity"`
+	Teams           TeamsConfig           `yaml:"teams"`
 }
 
 // ServerConfig 服务器配置
@@ -21,8 +30,17 @@ type ServerConfig struct {
 	Mode string `yaml:"mode"`
 }
 
+// 支持的数据库类型
+const (
+	DriverMySQL    = "mysql"
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+)
+
 // DatabaseConfig 数据库配置
 type DatabaseConfig struct {
+	// Driver 数据库类型(mysql/postgres/sqlite)，默认mysql
+	Driver    string `yaml:"driver"`
 	Host      string `yaml:"host"`
 	Port      int    `yaml:"port"`
 	Username  string `yaml:"username"`
@@ -31,6 +49,10 @@ type DatabaseConfig struct {
 	Charset   string `yaml:"charset"`
 	ParseTime bool   `yaml:"parse_time"`
 	Loc       string `yaml:"loc"`
+	// SSLMode PostgreSQL的sslmode，默认disable
+	SSLMode string `yaml:"sslmode"`
+	// Path SQLite数据库文件路径
+	Path string `yaml:"path"`
 }
 
 // JWTConfig JWT配置
@@ -46,6 +68,158 @@ type CORSConfig struct {
 	AllowHeaders []string `yaml:"allow_headers"`
 }
 
+// NotificationConfig 通知配置
+type NotificationConfig struct {
+	TimeoutSeconds int `yaml:"timeout_seconds"`
+	// ReminderHours 提交点截止前多少小时发送提醒，0表示不提醒
+	ReminderHours int `yaml:"reminder_hours"`
+
+	Email   EmailConfig   `yaml:"email"`
+	Webhook WebhookConfig `yaml:"webhook"`
+	OneBot  OneBotConfig  `yaml:"onebot"`
+}
+
+// EmailConfig 邮件网关配置
+type EmailConfig struct {
+	Enabled bool   `yaml:"enabled"`
+	APIURL  string `yaml:"api_url"`
+	APIKey  string `yaml:"api_key"`
+	From    string `yaml:"from"`
+}
+
+// WebhookConfig 用户Webhook通知配置
+type WebhookConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// AllowPrivateHosts 允许用户把Webhook地址设为内网地址，仅在内网部署且信任全部用户时开启
+	AllowPrivateHosts bool `yaml:"allow_private_hosts"`
+}
+
+// OneBotConfig QQ机器人(OneBot v11 HTTP)配置
+type OneBotConfig struct {
+	Enabled     bool   `yaml:"enabled"`
+	BaseURL     string `yaml:"base_url"`
+	AccessToken string `yaml:"access_token"`
+}
+
+// WebhookDeliveryConfig 外发Webhook投递配置
+type WebhookDeliveryConfig struct {
+	TimeoutSeconds       int `yaml:"timeout_seconds"`
+	PollIntervalSeconds  int `yaml:"poll_interval_seconds"`
+	MaxAttempts          int `yaml:"max_attempts"`
+	BaseBackoffSeconds   int `yaml:"base_backoff_seconds"`
+	MaxBackoffSeconds    int `yaml:"max_backoff_seconds"`
+	DisableAfterFailures int `yaml:"disable_after_failures"`
+}
+
+// TrashConfig 回收站配置
+type TrashConfig struct {
+	// RetentionDays 软删除记录保留天数，超过后被彻底删除，0表示永久保留
+	RetentionDays        int `yaml:"retention_days"`
+	PurgeIntervalMinutes int `yaml:"purge_interval_minutes"`
+}
+
+// 支持的文件存储类型
+const (
+	StorageLocal = "local"
+)
+
+// StorageConfig 附件存储配置
+type StorageConfig struct {
+	// Driver 存储类型，目前仅支持local，默认local
+	Driver string `yaml:"driver"`
+	// LocalPath 本地存储根目录，默认data/uploads
+	LocalPath string `yaml:"local_path"`
+	// MaxUploadMB 单个附件大小上限(MB)
+	MaxUploadMB int `yaml:"max_upload_mb"`
+	// SigningKey 下载链接签名密钥，为空时使用jwt.secret
+	SigningKey string `yaml:"signing_key"`
+	// SignedURLTTLMinutes 签名下载链接的最长有效期(分钟)
+	SignedURLTTLMinutes int `yaml:"signed_url_ttl_minutes"`
+}
+
+// JudgeConfig 自动评测配置
+type JudgeConfig struct {
+	// Enabled 是否启动评测任务，关闭时自动评测提交点的提交保持等待评测
+	Enabled             bool `yaml:"enabled"`
+	Workers             int  `yaml:"workers"`
+	PollIntervalSeconds int  `yaml:"poll_interval_seconds"`
+	// WorkDir 评测时存放源代码和编译产物的临时目录，默认data/judge
+	WorkDir string `yaml:"work_dir"`
+	// RunAsUID、RunAsGID 运行选手程序的专用低权限用户，启用评测时必须配置且服务需以root运行
+	// 第i个评测任务(从0开始)以RunAsUID+2i运行选手程序、RunAsUID+2i+1运行检查脚本，
+	// 共占用从RunAsUID开始的2×Workers个UID，这些UID不能分配给其他用户或服务
+	RunAsUID int `yaml:"run_as_uid"`
+	RunAsGID int `yaml:"run_as_gid"`
+	// IsolateNetwork 在独立的网络命名空间中运行选手程序
+	IsolateNetwork bool `yaml:"isolate_network"`
+	// DefaultTimeLimitMS、DefaultMemoryLimitMB 提交点未设置时使用的限制
+	DefaultTimeLimitMS   int `yaml:"default_time_limit_ms"`
+	DefaultMemoryLimitMB int `yaml:"default_memory_limit_mb"`
+	// CompileTimeoutSeconds 编译和运行检查脚本的时间限制
+	CompileTimeoutSeconds int `yaml:"compile_timeout_seconds"`
+	// MaxOutputKB 每个测试点的输出上限
+	MaxOutputKB int `yaml:"max_output_kb"`
+	// MaxProcesses 选手程序、编译和检查脚本的进程数上限，线程也计入，默认128；JVM等多线程运行时设置过小会无法启动
+	MaxProcesses int `yaml:"max_processes"`
+	// Languages 评测语言，键为提交点和检查脚本使用的语言名称
+	Languages map[string]JudgeLanguage `yaml:"languages"`
+}
+
+// JudgeLanguage 评测语言，命令在源代码所在目录中执行
+type JudgeLanguage struct {
+	// Source 源代码文件名
+	Source string `yaml:"source"`
+	// Compile 编译命令，为空时不编译
+	Compile []string `yaml:"compile"`
+	// Run 运行命令，检查脚本运行时在末尾追加输入、标准答案和选手输出的文件路径
+	Run []string `yaml:"run"`
+}
+
+// ChecksConfig 代码仓库检查配置
+type ChecksConfig struct {
+	// Enabled 是否启动检查任务，关闭时代码仓库提交点的提交保持等待检查
+	Enabled             bool `yaml:"enabled"`
+	Workers             int  `yaml:"workers"`
+	PollIntervalSeconds int  `yaml:"poll_interval_seconds"`
+	// WorkDir 克隆仓库和运行检查命令的临时目录，默认data/checks
+	WorkDir string `yaml:"work_dir"`
+	// RunAsUID、RunAsGID 克隆仓库和运行检查命令的专用低权限用户，启用检查时必须配置且服务需以root运行
+	// 第i个检查任务(从0开始)以RunAsUID+i运行，共占用从RunAsUID开始的Workers个UID，不能与评测使用的UID重叠
+	RunAsUID int `yaml:"run_as_uid"`
+	RunAsGID int `yaml:"run_as_gid"`
+	// IsolateNetwork 在独立的网络命名空间中运行检查命令，克隆仓库不受影响
+	IsolateNetwork bool `yaml:"isolate_network"`
+	// AllowPrivateHosts 允许仓库地址指向内网地址，仅在使用内网代码托管服务且信任全部用户时开启
+	AllowPrivateHosts bool `yaml:"allow_private_hosts"`
+	// CloneTimeoutSeconds 克隆仓库的时间限制，MaxRepoMB 克隆时单个文件和克隆后仓库目录的大小上限
+	CloneTimeoutSeconds int `yaml:"clone_timeout_seconds"`
+	MaxRepoMB           int `yaml:"max_repo_mb"`
+	// DefaultStepTimeoutSeconds 检查步骤未设置时的运行时间限制
+	DefaultStepTimeoutSeconds int `yaml:"default_step_timeout_seconds"`
+	// MemoryLimitMB 检查命令的内存上限，0表示不限制；JVM、Node.js等运行时预留的地址空间较大，设置过小会无法启动
+	MemoryLimitMB int `yaml:"memory_limit_mb"`
+	// MaxLogKB 每个步骤保留的日志大小，超出部分丢弃
+	MaxLogKB int `yaml:"max_log_kb"`
+	// MaxProcesses 克隆仓库和检查命令的进程数上限，线程也计入，默认1024
+	MaxProcesses int `yaml:"max_processes"`
+}
+
+// SimilarityConfig 相似度检测配置
+type SimilarityConfig struct {
+	// Threshold 相似度达到该值的提交标记为可疑，默认0.8
+	Threshold float64 `yaml:"threshold"`
+	// MinScore 保存相似度报告的最低相似度，低于该值的提交对不保存，默认0.3
+	MinScore float64 `yaml:"min_score"`
+	// IgnoredAuthors 比较代码仓库的提交作者时忽略的邮箱，如平台和机器人的公共邮箱
+	IgnoredAuthors []string `yaml:"ignored_authors"`
+}
+
+// TeamsConfig 组队提交配置
+type TeamsConfig struct {
+	// ScoreSplit 队伍得分在排行榜中计入队员的方式：full每位队员计全部得分（默认），equal由队员平分（向下取整）
+	ScoreSplit string `yaml:"score_split"`
+}
+
 var AppConfig *Config
 
 // LoadConfig 加载配置文件
@@ -63,8 +237,65 @@ func LoadConfig(configPath string) error {
 	return nil
 }
 
+// GetDriver 获取数据库类型，未配置时为mysql
+func (c *DatabaseConfig) GetDriver() string {
+	if c.Driver == "" {
+		return DriverMySQL
+	}
+	return c.Driver
+}
+
 // GetDSN 获取数据库连接字符串
 func (c *DatabaseConfig) GetDSN() string {
-	return fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?charset=%s&parseTime=%t&loc=%s",
-		c.Username, c.Password, c.Host, c.Port, c.DBName, c.Charset, c.ParseTime, c.Loc)
-}
\ No newline at end of file
+	switch c.GetDriver() {
+	case DriverPostgres:
+		sslMode := c.SSLMode
+		if sslMode == "" {
+			sslMode = "disable"
+		}
+		return fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
+			c.Host, c.Port, c.Username, c.Password, c.DBName, sslMode)
+	case DriverSQLite:
+		path := c.Path
+		if path == "" {
+			path = "glimgate.db"
+		}
+		// 开启外键约束；WAL模式下读写互不阻塞，写事务立即加锁并在锁冲突时等待，避免并发写入时直接失败
+		return fmt.Sprintf("file:%s?_pragma=foreign_keys(1)&_pragma=journal_mode(WAL)&_pragma=busy_timeout(5000)&_txlock=immediate", path)
+	default:
+		return fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?charset=%s&parseTime=%t&loc=%s",
+			c.Username, c.Password, c.Host, c.Port, c.DBName, c.Charset, c.ParseTime, c.Loc)
+	}
+}
+
+// GetDriver 获取存储类型，未配置时为local
+func (c *StorageConfig) GetDriver() string {
+	if c.Driver == "" {
+		return StorageLocal
+	}
+	return c.Driver
+}
+
+// GetLocalPath 获取本地存储根目录，未配置时为data/uploads
+func (c *StorageConfig) GetLocalPath() string {
+	if c.LocalPath == "" {
+		return "data/uploads"
+	}
+	return c.LocalPath
+}
+
+// GetWorkDir 获取评测临时目录，未配置时为data/judge
+func (c *JudgeConfig) GetWorkDir() string {
+	if c.WorkDir == "" {
+		return "data/judge"
+	}
+	return c.WorkDir
+}
+
+// GetWorkDir 获取检查临时目录，未配置时为data/checks
+func (c *ChecksConfig) GetWorkDir() string {
+	if c.WorkDir == "" {
+		return "data/checks"
+	}
+	return c.WorkDir
+}
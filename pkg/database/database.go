@@ -4,49 +4,45 @@ import (
 	"fmt"
 	"log"
 
-	"github.com/tksky1/glimgate/internal/model"
+	"github.com/glebarez/sqlite"
 	"github.com/tksky1/glimgate/pkg/config"
 	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
 )
 
-var DB *gorm.DB
+// InitDB 初始化数据库连接，返回的连接由调用方注入到各层
+// 不会修改表结构，表结构由版本化迁移维护，见MigrateUp
+func InitDB() (*gorm.DB, error) {
+	dialector, err := newDialector(&config.AppConfig.Database)
+	if err != nil {
+		return nil, err
+	}
 
-// InitDB 初始化数据库连接
-func InitDB() error {
-	dsn := config.AppConfig.Database.GetDSN()
-	
-	var err error
-	DB, err = gorm.Open(mysql.Open(dsn), &gorm.Config{
+	db, err := gorm.Open(dialector, &gorm.Config{
 		Logger: logger.Default.LogMode(logger.Info),
 	})
 	if err != nil {
-		return fmt.Errorf("连接数据库失败: %w", err)
-	}
-
-	// 自动迁移数据表
-	if err := autoMigrate(); err != nil {
-		return fmt.Errorf("数据表迁移失败: %w", err)
+		return nil, fmt.Errorf("连接数据库失败: %w", err)
 	}
 
-	log.Println("数据库连接成功")
-	return nil
+	log.Printf("数据库连接成功(%s)", config.AppConfig.Database.GetDriver())
+	return db, nil
 }
 
-// autoMigrate 自动迁移数据表
-func autoMigrate() error {
-	return DB.AutoMigrate(
-		&model.User{},
-		&model.Direction{},
-		&model.Problem{},
-		&model.SubmissionPoint{},
-		&model.Submission{},
-		&model.Score{},
-	)
+// newDialector 根据配置的数据库类型创建GORM方言
+func newDialector(cfg *config.DatabaseConfig) (gorm.Dialector, error) {
+	dsn := cfg.GetDSN()
+
+	switch cfg.GetDriver() {
+	case config.DriverMySQL:
+		return mysql.Open(dsn), nil
+	case config.DriverPostgres:
+		return postgres.Open(dsn), nil
+	case config.DriverSQLite:
+		return sqlite.Open(dsn), nil
+	default:
+		return nil, fmt.Errorf("不支持的数据库类型: %s", cfg.Driver)
+	}
 }
-
-// GetDB 获取数据库实例
-func GetDB() *gorm.DB {
-	return DB
-}
\ No newline at end of file
@@ -0,0 +1,41 @@
+// Package dbtest 为测试提供已执行全部迁移的临时SQLite数据库
+package dbtest
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"github.com/tksky1/glimgate/pkg/config"
+	"github.com/tksky1/glimgate/pkg/database"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// New 在测试的临时目录中创建SQLite数据库并执行全部迁移，测试结束时关闭连接
+// 使用与生产环境相同的连接参数（外键约束、WAL、写事务立即加锁），并发测试不会因锁冲突直接失败
+func New(t testing.TB) *gorm.DB {
+	t.Helper()
+
+	cfg := config.DatabaseConfig{
+		Driver: config.DriverSQLite,
+		Path:   filepath.Join(t.TempDir(), "test.db"),
+	}
+	db, err := gorm.Open(sqlite.Open(cfg.GetDSN()), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("打开测试数据库失败: %v", err)
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("获取数据库连接失败: %v", err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+
+	if _, err := database.MigrateUp(db); err != nil {
+		t.Fatalf("执行迁移失败: %v", err)
+	}
+	return db
+}
@@ -0,0 +1,189 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Migration 一次版本化的数据库结构变更，Up与Down需互为逆操作
+type Migration struct {
+	Version     uint
+	Description string
+	Up          func(tx *gorm.DB) error
+	Down        func(tx *gorm.DB) error
+}
+
+// SchemaMigration 已执行的迁移记录
+type SchemaMigration struct {
+	Version     uint      `gorm:"primarykey;autoIncrement:false"`
+	Description string    `gorm:"size:200;not null"`
+	AppliedAt   time.Time `gorm:"not null"`
+}
+
+// TableName 指定表名
+func (SchemaMigration) TableName() string {
+	return "migrations"
+}
+
+// MigrationStatus 迁移执行状态
+type MigrationStatus struct {
+	Version     uint
+	Description string
+	AppliedAt   *time.Time
+}
+
+// ErrSchemaOutdated 数据库结构版本落后于程序
+var ErrSchemaOutdated = errors.New("数据库结构版本落后，请先执行 glimgate migrate up")
+
+// sortedMigrations 按版本号升序返回迁移列表
+func sortedMigrations() []Migration {
+	sorted := make([]Migration, len(migrations))
+	copy(sorted, migrations)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Version < sorted[j].Version })
+	return sorted
+}
+
+// ensureMigrationTable 迁移记录表不存在时创建
+func ensureMigrationTable(db *gorm.DB) error {
+	if err := db.AutoMigrate(&SchemaMigration{}); err != nil {
+		return fmt.Errorf("创建迁移记录表失败: %w", err)
+	}
+	return nil
+}
+
+// appliedVersions 读取已执行的迁移记录，迁移记录表不存在时视为尚未执行任何迁移
+func appliedVersions(db *gorm.DB) (map[uint]SchemaMigration, error) {
+	if !db.Migrator().HasTable(&SchemaMigration{}) {
+		return map[uint]SchemaMigration{}, nil
+	}
+
+	var records []SchemaMigration
+	if err := db.Find(&records).Error; err != nil {
+		return nil, err
+	}
+
+	applied := make(map[uint]SchemaMigration, len(records))
+	for _, record := range records {
+		applied[record.Version] = record
+	}
+	return applied, nil
+}
+
+// MigrateUp 依次执行所有未执行的迁移，返回本次执行的迁移
+// 每个迁移在事务中执行，但MySQL的DDL会隐式提交，迁移中途失败时已完成的结构变更会保留而迁移记录未写入；
+// 迁移的每一步都可重复执行，修复失败原因后再次执行即可完成剩余的步骤
+func MigrateUp(db *gorm.DB) ([]Migration, error) {
+	if err := ensureMigrationTable(db); err != nil {
+		return nil, err
+	}
+
+	applied, err := appliedVersions(db)
+	if err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+	for _, m := range sortedMigrations() {
+		if _, ok := applied[m.Version]; ok {
+			continue
+		}
+
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := m.Up(tx); err != nil {
+				return err
+			}
+			return tx.Create(&SchemaMigration{
+				Version:     m.Version,
+				Description: m.Description,
+				AppliedAt:   time.Now(),
+			}).Error
+		})
+		if err != nil {
+			return done, fmt.Errorf("执行迁移 %d(%s) 失败: %w", m.Version, m.Description, err)
+		}
+		done = append(done, m)
+	}
+
+	return done, nil
+}
+
+// MigrateDown 按版本号从高到低回滚steps个已执行的迁移，返回本次回滚的迁移
+func MigrateDown(db *gorm.DB, steps int) ([]Migration, error) {
+	if err := ensureMigrationTable(db); err != nil {
+		return nil, err
+	}
+
+	applied, err := appliedVersions(db)
+	if err != nil {
+		return nil, err
+	}
+
+	sorted := sortedMigrations()
+	var done []Migration
+	for i := len(sorted) - 1; i >= 0 && len(done) < steps; i-- {
+		m := sorted[i]
+		if _, ok := applied[m.Version]; !ok {
+			continue
+		}
+
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := m.Down(tx); err != nil {
+				return err
+			}
+			return tx.Delete(&SchemaMigration{}, m.Version).Error
+		})
+		if err != nil {
+			return done, fmt.Errorf("回滚迁移 %d(%s) 失败: %w", m.Version, m.Description, err)
+		}
+		done = append(done, m)
+	}
+
+	return done, nil
+}
+
+// GetMigrationStatus 获取所有迁移的执行状态，按版本号升序
+func GetMigrationStatus(db *gorm.DB) ([]MigrationStatus, error) {
+	applied, err := appliedVersions(db)
+	if err != nil {
+		return nil, err
+	}
+
+	var statuses []MigrationStatus
+	for _, m := range sortedMigrations() {
+		status := MigrationStatus{
+			Version:     m.Version,
+			Description: m.Description,
+		}
+		if record, ok := applied[m.Version]; ok {
+			appliedAt := record.AppliedAt
+			status.AppliedAt = &appliedAt
+		}
+		statuses = append(statuses, status)
+	}
+
+	return statuses, nil
+}
+
+// CheckSchema 检查数据库结构是否为最新版本，存在未执行的迁移时返回ErrSchemaOutdated
+func CheckSchema(db *gorm.DB) error {
+	statuses, err := GetMigrationStatus(db)
+	if err != nil {
+		return err
+	}
+
+	var pending []uint
+	for _, status := range statuses {
+		if status.AppliedAt == nil {
+			pending = append(pending, status.Version)
+		}
+	}
+	if len(pending) > 0 {
+		return fmt.Errorf("%w，待执行的迁移版本: %v", ErrSchemaOutdated, pending)
+	}
+
+	return nil
+}
@@ -0,0 +1,156 @@
+package database
+
+import (
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"github.com/tksky1/glimgate/pkg/config"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func openTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	cfg := config.DatabaseConfig{Driver: config.DriverSQLite, Path: filepath.Join(t.TempDir(), "test.db")}
+	db, err := gorm.Open(sqlite.Open(cfg.GetDSN()), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+	return db
+}
+
+// schemaSnapshot 读取全部表和索引的定义，用于比较迁移前后的表结构；GORM生成外键约束的顺序不固定，比较前排序
+func schemaSnapshot(t *testing.T, db *gorm.DB) map[string]string {
+	t.Helper()
+	var rows []struct {
+		Name string
+		SQL  string
+	}
+	if err := db.Raw("SELECT name, COALESCE(sql, '') AS sql FROM sqlite_master WHERE name <> 'migrations' AND name NOT LIKE 'sqlite_%'").Scan(&rows).Error; err != nil {
+		t.Fatal(err)
+	}
+	schema := make(map[string]string, len(rows))
+	for _, row := range rows {
+		parts := strings.Split(strings.TrimSuffix(row.SQL, ")"), ",CONSTRAINT ")
+		sort.Strings(parts[1:])
+		schema[row.Name] = strings.Join(parts, ",CONSTRAINT ")
+	}
+	return schema
+}
+
+func TestMigrateUpAndDown(t *testing.T) {
+	db := openTestDB(t)
+
+	done, err := MigrateUp(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(done) != len(migrations) {
+		t.Fatalf("应执行%d个迁移，执行了%d个", len(migrations), len(done))
+	}
+	if err := CheckSchema(db); err != nil {
+		t.Fatal(err)
+	}
+	latest := schemaSnapshot(t, db)
+
+	// 再次执行不做任何变更
+	if done, err := MigrateUp(db); err != nil || len(done) != 0 {
+		t.Fatalf("重复执行迁移: %v %v", done, err)
+	}
+
+	done, err = MigrateDown(db, len(migrations))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(done) != len(migrations) {
+		t.Fatalf("应回滚%d个迁移，回滚了%d个", len(migrations), len(done))
+	}
+	if schema := schemaSnapshot(t, db); len(schema) != 0 {
+		t.Fatalf("全部回滚后应没有业务表，剩余: %v", schema)
+	}
+
+	if _, err := MigrateUp(db); err != nil {
+		t.Fatal(err)
+	}
+	if schema := schemaSnapshot(t, db); !reflect.DeepEqual(schema, latest) {
+		t.Fatalf("回滚后重新迁移的表结构与首次迁移不一致")
+	}
+}
+
+// TestMigrationsRerunnable 模拟迁移中途失败后重新执行：每个迁移的Up和Down在已完成后再次执行都应成功且不改变表结构
+func TestMigrationsRerunnable(t *testing.T) {
+	db := openTestDB(t)
+	if err := ensureMigrationTable(db); err != nil {
+		t.Fatal(err)
+	}
+
+	sorted := sortedMigrations()
+	for _, m := range sorted {
+		if err := m.Up(db); err != nil {
+			t.Fatalf("执行迁移%d失败: %v", m.Version, err)
+		}
+		schema := schemaSnapshot(t, db)
+		if err := m.Up(db); err != nil {
+			t.Fatalf("重新执行迁移%d失败: %v", m.Version, err)
+		}
+		if !reflect.DeepEqual(schemaSnapshot(t, db), schema) {
+			t.Fatalf("重新执行迁移%d改变了表结构", m.Version)
+		}
+	}
+
+	for i := len(sorted) - 1; i >= 0; i-- {
+		m := sorted[i]
+		if err := m.Down(db); err != nil {
+			t.Fatalf("回滚迁移%d失败: %v", m.Version, err)
+		}
+		schema := schemaSnapshot(t, db)
+		if err := m.Down(db); err != nil {
+			t.Fatalf("重新回滚迁移%d失败: %v", m.Version, err)
+		}
+		if !reflect.DeepEqual(schemaSnapshot(t, db), schema) {
+			t.Fatalf("重新回滚迁移%d改变了表结构", m.Version)
+		}
+	}
+}
+
+// TestProblemRevisionsRerunnable 版本9重新执行时不会为已有修订记录的题目重复写入版本1
+func TestProblemRevisionsRerunnable(t *testing.T) {
+	db := openTestDB(t)
+	if _, err := MigrateUp(db); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Exec("INSERT INTO directions (name, created_at, updated_at) VALUES ('d', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)").Error; err != nil {
+		t.Fatal(err)
+	}
+	for _, title := range []string{"a", "b"} {
+		err := db.Exec("INSERT INTO problems (title, description, direction_id, created_at, updated_at) VALUES (?, 'd', 1, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)", title).Error
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// 只有第一道题目写入了版本1时失败
+	if err := db.Exec("INSERT INTO problem_revisions (problem_id, version, title, created_at) VALUES (1, 1, 'a', CURRENT_TIMESTAMP)").Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := upProblemRevisions(db); err != nil {
+		t.Fatal(err)
+	}
+
+	var counts []int64
+	if err := db.Table("problem_revisions").Select("COUNT(*)").Group("problem_id").Order("problem_id").Pluck("COUNT(*)", &counts).Error; err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(counts, []int64{1, 1}) {
+		t.Fatalf("每道题目应只有1条修订记录，得到%v", counts)
+	}
+}
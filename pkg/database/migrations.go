@@ -0,0 +1,1146 @@
+package database
+
+import (
+	"encoding/json"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// migrations 全部迁移，新增迁移追加到末尾，已发布的迁移不可修改
+// 迁移中使用当时的表结构快照而非internal/model中的模型，避免模型后续变更影响历史迁移
+var migrations = []Migration{
+	{
+		Version:     1,
+		Description: "初始表结构",
+		Up:          upInitialSchema,
+		Down:        downInitialSchema,
+	},
+	{
+		Version:     2,
+		Description: "提交与评分的组合唯一索引",
+		Up:          upUniqueSubmissionsAndScores,
+		Down:        downUniqueSubmissionsAndScores,
+	},
+	{
+		Version:     3,
+		Description: "题目包标识与提交点评分标准",
+		Up:          upProblemBundle,
+		Down:        downProblemBundle,
+	},
+	{
+		Version:     4,
+		Description: "题目附件",
+		Up:          upProblemAttachments,
+		Down:        downProblemAttachments,
+	},
+	{
+		Version:     5,
+		Description: "题目可见状态与定时发布",
+		Up:          upProblemVisibility,
+		Down:        downProblemVisibility,
+	},
+	{
+		Version:     6,
+		Description: "题目难度、标签与全文索引",
+		Up:          upProblemSearch,
+		Down:        downProblemSearch,
+	},
+	{
+		Version:     7,
+		Description: "题目解锁条件",
+		Up:          upProblemPrerequisites,
+		Down:        downProblemPrerequisites,
+	},
+	{
+		Version:     8,
+		Description: "题目提示与解锁记录",
+		Up:          upProblemHints,
+		Down:        downProblemHints,
+	},
+	{
+		Version:     9,
+		Description: "题目修订记录",
+		Up:          upProblemRevisions,
+		Down:        downProblemRevisions,
+	},
+	{
+		Version:     10,
+		Description: "自动评测",
+		Up:          upJudge,
+		Down:        downJudge,
+	},
+	{
+		Version:     11,
+		Description: "代码仓库检查",
+		Up:          upChecks,
+		Down:        downChecks,
+	},
+	{
+		Version:     12,
+		Description: "相似度检测",
+		Up:          upSimilarity,
+		Down:        downSimilarity,
+	},
+	{
+		Version:     13,
+		Description: "组队提交",
+		Up:          upTeams,
+		Down:        downTeams,
+	},
+}
+
+// 迁移中的每一步都可重复执行：已存在的表、列、索引和约束跳过创建，不存在的跳过删除。
+// MySQL的DDL会隐式提交事务，迁移中途失败时已执行的结构变更不会回滚，迁移记录也不会写入，
+// 修复问题后重新执行会从该迁移的第一步开始，已完成的步骤需要安全跳过
+
+// createTables 创建表，已存在的表跳过
+func createTables(tx *gorm.DB, models ...interface{}) error {
+	for _, model := range models {
+		if tx.Migrator().HasTable(model) {
+			continue
+		}
+		if err := tx.Migrator().CreateTable(model); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addColumns 按字段名新增列，已存在的列跳过
+func addColumns(tx *gorm.DB, model interface{}, fields ...string) error {
+	for _, field := range fields {
+		if tx.Migrator().HasColumn(model, field) {
+			continue
+		}
+		if err := tx.Migrator().AddColumn(model, field); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dropColumns 删除列，不存在的列跳过；SQLite驱动的DropColumn通过重建表实现，开启外键约束时被其他表引用的表无法重建，
+// 因此直接使用三种数据库都支持的ALTER TABLE ... DROP COLUMN（SQLite 3.35起支持）
+func dropColumns(tx *gorm.DB, table string, columns ...string) error {
+	for _, column := range columns {
+		if !tx.Migrator().HasColumn(table, column) {
+			continue
+		}
+		if err := tx.Exec("ALTER TABLE ? DROP COLUMN ?", clause.Table{Name: table}, clause.Column{Name: column}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// createIndexes 按字段名或索引名创建索引，已存在的索引跳过
+func createIndexes(tx *gorm.DB, model interface{}, names ...string) error {
+	for _, name := range names {
+		if tx.Migrator().HasIndex(model, name) {
+			continue
+		}
+		if err := tx.Migrator().CreateIndex(model, name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dropIndexes 按字段名或索引名删除索引，不存在的索引跳过
+func dropIndexes(tx *gorm.DB, model interface{}, names ...string) error {
+	for _, name := range names {
+		if !tx.Migrator().HasIndex(model, name) {
+			continue
+		}
+		if err := tx.Migrator().DropIndex(model, name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// 版本1的表结构快照，与引入版本化迁移前AutoMigrate创建的表结构一致，已有数据库执行时不做改动
+
+type initialUser struct {
+	ID        uint `gorm:"primarykey"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+
+	Username  string `gorm:"uniqueIndex;size:50;not null"`
+	Password  string `gorm:"size:255;not null"`
+	Nickname  string `gorm:"size:50;not null"`
+	RealName  string `gorm:"size:50;not null"`
+	College   string `gorm:"size:100;not null"`
+	StudentID string `gorm:"size:20;not null"`
+	QQ        string `gorm:"size:20"`
+	Email     string `gorm:"size:100"`
+	IsAdmin   bool   `gorm:"default:false"`
+}
+
+type initialDirection struct {
+	ID        uint `gorm:"primarykey"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+
+	Name        string `gorm:"size:100;not null"`
+	Description string `gorm:"type:text"`
+
+	Problems []initialProblem `gorm:"foreignKey:DirectionID"`
+}
+
+// initialDirectionManager 方向负责人关联表，显式定义以保证约束名与多对多关联自动创建的一致
+type initialDirectionManager struct {
+	DirectionID uint `gorm:"primaryKey"`
+	UserID      uint `gorm:"primaryKey"`
+
+	Direction initialDirection
+	User      initialUser
+}
+
+type initialProblem struct {
+	ID        uint `gorm:"primarykey"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+
+	Title       string `gorm:"size:200;not null"`
+	Description string `gorm:"type:text"`
+	DirectionID uint
+
+	Direction        initialDirection
+	SubmissionPoints []initialSubmissionPoint `gorm:"foreignKey:ProblemID"`
+	Submissions      []initialSubmission      `gorm:"foreignKey:ProblemID"`
+}
+
+type initialSubmissionPoint struct {
+	ID        uint `gorm:"primarykey"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+
+	Name      string `gorm:"size:100;not null"`
+	MaxScore  int    `gorm:"not null"`
+	ProblemID uint
+
+	Deadline       *time.Time
+	ReminderSentAt *time.Time
+
+	Problem     initialProblem
+	Submissions []initialSubmission `gorm:"foreignKey:SubmissionPointID"`
+}
+
+type initialSubmission struct {
+	ID        uint `gorm:"primarykey"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+
+	Content           string `gorm:"type:text"`
+	UserID            uint
+	ProblemID         uint
+	SubmissionPointID uint
+
+	User            initialUser
+	Problem         initialProblem
+	SubmissionPoint initialSubmissionPoint
+	Scores          []initialScore `gorm:"foreignKey:SubmissionID"`
+}
+
+type initialScore struct {
+	ID        uint `gorm:"primarykey"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+
+	Score        int    `gorm:"not null"`
+	Comment      string `gorm:"type:text"`
+	UserID       uint
+	SubmissionID uint
+	ReviewerID   uint
+
+	User       initialUser
+	Submission initialSubmission
+	Reviewer   initialUser `gorm:"foreignKey:ReviewerID"`
+}
+
+type initialClarification struct {
+	ID        uint `gorm:"primarykey"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+
+	ProblemID   uint   `gorm:"index;not null"`
+	AskerID     uint   `gorm:"index;not null"`
+	Question    string `gorm:"type:text;not null"`
+	Answer      string `gorm:"type:text"`
+	AnswererID  *uint
+	AnsweredAt  *time.Time
+	IsPublic    bool `gorm:"default:false"`
+	ReplyUnread bool `gorm:"default:false"`
+
+	Problem  initialProblem
+	Asker    initialUser  `gorm:"foreignKey:AskerID"`
+	Answerer *initialUser `gorm:"foreignKey:AnswererID"`
+}
+
+type initialNotification struct {
+	ID        uint `gorm:"primarykey"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+
+	UserID    uint   `gorm:"index;not null"`
+	Type      string `gorm:"size:50;not null"`
+	Title     string `gorm:"size:200;not null"`
+	Content   string `gorm:"type:text"`
+	RelatedID uint
+	ReadAt    *time.Time
+}
+
+type initialNotificationSetting struct {
+	ID        uint `gorm:"primarykey"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+
+	UserID     uint   `gorm:"uniqueIndex;not null"`
+	Email      bool   `gorm:"default:false"`
+	Webhook    bool   `gorm:"default:false"`
+	WebhookURL string `gorm:"size:500"`
+	OneBot     bool   `gorm:"default:false"`
+}
+
+type initialWebhookSubscription struct {
+	ID        uint `gorm:"primarykey"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+
+	Name        string `gorm:"size:100;not null"`
+	URL         string `gorm:"size:500;not null"`
+	Secret      string `gorm:"size:100;not null"`
+	EventTypes  string `gorm:"size:500"`
+	DirectionID uint   `gorm:"index"`
+	Enabled     bool   `gorm:"not null"`
+
+	ConsecutiveFailures int    `gorm:"not null;default:0"`
+	DisabledReason      string `gorm:"size:255"`
+}
+
+type initialWebhookDelivery struct {
+	ID        uint `gorm:"primarykey"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+
+	SubscriptionID uint       `gorm:"index;not null"`
+	EventID        string     `gorm:"size:64;index;not null"`
+	EventType      string     `gorm:"size:50;not null"`
+	Payload        string     `gorm:"type:text;not null"`
+	Status         string     `gorm:"size:20;index;not null"`
+	Attempts       int        `gorm:"not null;default:0"`
+	NextAttemptAt  *time.Time `gorm:"index"`
+	LastAttemptAt  *time.Time
+	ResponseCode   int
+	LastError      string `gorm:"type:text"`
+}
+
+type initialAuditLog struct {
+	ID        uint      `gorm:"primarykey"`
+	CreatedAt time.Time `gorm:"index"`
+
+	OperatorID   uint   `gorm:"index;not null"`
+	OperatorName string `gorm:"size:50"`
+	Action       string `gorm:"size:20;index;not null"`
+	EntityType   string `gorm:"size:50;index:idx_audit_entity;not null"`
+	EntityID     uint   `gorm:"index:idx_audit_entity"`
+	Before       string `gorm:"type:text"`
+	After        string `gorm:"type:text"`
+	IP           string `gorm:"size:64"`
+	RequestID    string `gorm:"size:64;index"`
+}
+
+func (initialUser) TableName() string                { return "users" }
+func (initialDirection) TableName() string           { return "directions" }
+func (initialDirectionManager) TableName() string    { return "direction_managers" }
+func (initialProblem) TableName() string             { return "problems" }
+func (initialSubmissionPoint) TableName() string     { return "submission_points" }
+func (initialSubmission) TableName() string          { return "submissions" }
+func (initialScore) TableName() string               { return "scores" }
+func (initialClarification) TableName() string       { return "clarifications" }
+func (initialNotification) TableName() string        { return "notifications" }
+func (initialNotificationSetting) TableName() string { return "notification_settings" }
+func (initialWebhookSubscription) TableName() string { return "webhook_subscriptions" }
+func (initialWebhookDelivery) TableName() string     { return "webhook_deliveries" }
+func (initialAuditLog) TableName() string            { return "audit_logs" }
+
+// initialTables 版本1的全部表，按依赖顺序排列
+func initialTables() []interface{} {
+	return []interface{}{
+		&initialUser{},
+		&initialDirection{},
+		&initialDirectionManager{},
+		&initialProblem{},
+		&initialSubmissionPoint{},
+		&initialSubmission{},
+		&initialScore{},
+		&initialClarification{},
+		&initialNotification{},
+		&initialNotificationSetting{},
+		&initialWebhookSubscription{},
+		&initialWebhookDelivery{},
+		&initialAuditLog{},
+	}
+}
+
+// upInitialSchema 创建初始表结构；表已存在时只补齐缺失的列和索引，便于从AutoMigrate创建的数据库平滑接入
+func upInitialSchema(tx *gorm.DB) error {
+	return tx.AutoMigrate(initialTables()...)
+}
+
+// downInitialSchema 删除全部业务表
+func downInitialSchema(tx *gorm.DB) error {
+	tables := initialTables()
+	for i := len(tables) - 1; i >= 0; i-- {
+		if err := tx.Migrator().DropTable(tables[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// 版本2：每个用户在每个提交点只有一条提交，每个评分者对每个提交只有一条评分
+
+type uniqueSubmission struct {
+	ID                uint
+	UserID            uint `gorm:"uniqueIndex:idx_submissions_user_point"`
+	SubmissionPointID uint `gorm:"uniqueIndex:idx_submissions_user_point"`
+	DeletedAt         gorm.DeletedAt
+}
+
+type uniqueScore struct {
+	ID           uint
+	SubmissionID uint `gorm:"uniqueIndex:idx_scores_submission_reviewer"`
+	ReviewerID   uint `gorm:"uniqueIndex:idx_scores_submission_reviewer"`
+	DeletedAt    gorm.DeletedAt
+}
+
+func (uniqueSubmission) TableName() string { return "submissions" }
+func (uniqueScore) TableName() string      { return "scores" }
+
+// upUniqueSubmissionsAndScores 合并并发请求产生的重复提交和评分，再创建唯一索引
+// 重复记录中优先保留未删除的最新一条；被合并提交下的评分转移到保留的提交上
+func upUniqueSubmissionsAndScores(tx *gorm.DB) error {
+	var submissions []uniqueSubmission
+	if err := tx.Unscoped().Order("id").Find(&submissions).Error; err != nil {
+		return err
+	}
+	submissionKeys := make([]duplicateKey, len(submissions))
+	for i, sub := range submissions {
+		submissionKeys[i] = duplicateKey{ID: sub.ID, Key: [2]uint{sub.UserID, sub.SubmissionPointID}, Deleted: sub.DeletedAt.Valid}
+	}
+	for keep, removed := range findDuplicates(submissionKeys) {
+		if err := tx.Model(&uniqueScore{}).Unscoped().Where("submission_id IN ?", removed).Update("submission_id", keep).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Delete(&uniqueSubmission{}, removed).Error; err != nil {
+			return err
+		}
+	}
+
+	var scores []uniqueScore
+	if err := tx.Unscoped().Order("id").Find(&scores).Error; err != nil {
+		return err
+	}
+	scoreKeys := make([]duplicateKey, len(scores))
+	for i, score := range scores {
+		scoreKeys[i] = duplicateKey{ID: score.ID, Key: [2]uint{score.SubmissionID, score.ReviewerID}, Deleted: score.DeletedAt.Valid}
+	}
+	for _, removed := range findDuplicates(scoreKeys) {
+		if err := tx.Unscoped().Delete(&uniqueScore{}, removed).Error; err != nil {
+			return err
+		}
+	}
+
+	if err := createIndexes(tx, &uniqueSubmission{}, "idx_submissions_user_point"); err != nil {
+		return err
+	}
+	return createIndexes(tx, &uniqueScore{}, "idx_scores_submission_reviewer")
+}
+
+// downUniqueSubmissionsAndScores 删除唯一索引，已合并的重复记录不会还原
+func downUniqueSubmissionsAndScores(tx *gorm.DB) error {
+	if err := dropIndexes(tx, &uniqueScore{}, "idx_scores_submission_reviewer"); err != nil {
+		return err
+	}
+	return dropIndexes(tx, &uniqueSubmission{}, "idx_submissions_user_point")
+}
+
+// duplicateKey 去重用的记录标识
+type duplicateKey struct {
+	ID      uint
+	Key     [2]uint
+	Deleted bool
+}
+
+// findDuplicates 按Key分组找出重复记录，返回保留的ID到需删除ID列表的映射
+// rows需按ID升序排列，每组优先保留未删除的记录中ID最大的一条
+func findDuplicates(rows []duplicateKey) map[uint][]uint {
+	keep := make(map[[2]uint]duplicateKey)
+	var order [][2]uint
+	for _, row := range rows {
+		current, ok := keep[row.Key]
+		if !ok {
+			order = append(order, row.Key)
+		}
+		if !ok || !row.Deleted || current.Deleted {
+			keep[row.Key] = row
+		}
+	}
+
+	duplicates := make(map[uint][]uint)
+	for _, row := range rows {
+		kept := keep[row.Key]
+		if row.ID != kept.ID {
+			duplicates[kept.ID] = append(duplicates[kept.ID], row.ID)
+		}
+	}
+	return duplicates
+}
+
+// 版本3：题目的题目包标识和提交点的评分标准
+
+type bundleProblem struct {
+	Slug string `gorm:"size:100;not null;default:'';index"`
+}
+
+type bundleSubmissionPoint struct {
+	Rubric string `gorm:"type:text"`
+}
+
+func (bundleProblem) TableName() string         { return "problems" }
+func (bundleSubmissionPoint) TableName() string { return "submission_points" }
+
+// upProblemBundle 新增problems.slug及其索引、submission_points.rubric，已有记录的标识为空
+func upProblemBundle(tx *gorm.DB) error {
+	if err := addColumns(tx, &bundleProblem{}, "Slug"); err != nil {
+		return err
+	}
+	if err := createIndexes(tx, &bundleProblem{}, "Slug"); err != nil {
+		return err
+	}
+	return addColumns(tx, &bundleSubmissionPoint{}, "Rubric")
+}
+
+// downProblemBundle 删除新增的列，题目标识和评分标准会丢失
+func downProblemBundle(tx *gorm.DB) error {
+	if err := dropIndexes(tx, &bundleProblem{}, "Slug"); err != nil {
+		return err
+	}
+	if err := dropColumns(tx, "submission_points", "rubric"); err != nil {
+		return err
+	}
+	return dropColumns(tx, "problems", "slug")
+}
+
+// 版本4：题目附件
+
+type attachmentProblemAttachment struct {
+	ID        uint `gorm:"primarykey"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+
+	ProblemID   uint   `gorm:"not null;index"`
+	Filename    string `gorm:"size:255;not null"`
+	ContentType string `gorm:"size:100"`
+	Size        int64
+	SHA256      string `gorm:"column:sha256;size:64;not null"`
+	StorageKey  string `gorm:"size:255;not null"`
+	UploaderID  *uint
+
+	Problem initialProblem
+}
+
+func (attachmentProblemAttachment) TableName() string { return "problem_attachments" }
+
+// upProblemAttachments 新建problem_attachments表
+func upProblemAttachments(tx *gorm.DB) error {
+	return createTables(tx, &attachmentProblemAttachment{})
+}
+
+// downProblemAttachments 删除problem_attachments表，存储中的附件文件需手动清理
+func downProblemAttachments(tx *gorm.DB) error {
+	return tx.Migrator().DropTable(&attachmentProblemAttachment{})
+}
+
+// 版本5：题目可见状态与定时发布
+
+type visibilityProblem struct {
+	Status      string `gorm:"size:20;not null;default:'published';index"`
+	PublishAt   *time.Time
+	PublishedAt *time.Time
+}
+
+func (visibilityProblem) TableName() string { return "problems" }
+
+// upProblemVisibility 新增problems.status、publish_at、published_at，已有题目视为已发布，发布时间取创建时间
+func upProblemVisibility(tx *gorm.DB) error {
+	if err := addColumns(tx, &visibilityProblem{}, "Status", "PublishAt", "PublishedAt"); err != nil {
+		return err
+	}
+	if err := createIndexes(tx, &visibilityProblem{}, "Status"); err != nil {
+		return err
+	}
+	return tx.Exec("UPDATE problems SET published_at = created_at WHERE published_at IS NULL").Error
+}
+
+// downProblemVisibility 删除新增的列，草稿和定时发布的题目回滚后对选手可见
+func downProblemVisibility(tx *gorm.DB) error {
+	if err := dropIndexes(tx, &visibilityProblem{}, "Status"); err != nil {
+		return err
+	}
+	return dropColumns(tx, "problems", "published_at", "publish_at", "status")
+}
+
+// 版本6：题目难度、标签与全文索引
+
+type searchProblem struct {
+	Difficulty       int `gorm:"not null;default:0;index"`
+	EstimatedMinutes int `gorm:"not null;default:0"`
+}
+
+func (searchProblem) TableName() string { return "problems" }
+
+type searchProblemTag struct {
+	ProblemID uint   `gorm:"primaryKey"`
+	Name      string `gorm:"primaryKey;size:50;index"`
+
+	Problem initialProblem `gorm:"constraint:OnDelete:CASCADE"`
+}
+
+func (searchProblemTag) TableName() string { return "problem_tags" }
+
+// problemFullTextIndex MySQL中题目标题和描述的全文索引，其他数据库没有该索引，搜索时退化为LIKE匹配
+const problemFullTextIndex = "idx_problems_fulltext"
+
+// upProblemSearch 新增problems.difficulty、estimated_minutes和problem_tags表，已有题目的难度和预计用时为未设置
+// MySQL另外建立使用ngram分词的全文索引以支持中文检索
+func upProblemSearch(tx *gorm.DB) error {
+	if err := addColumns(tx, &searchProblem{}, "Difficulty", "EstimatedMinutes"); err != nil {
+		return err
+	}
+	if err := createIndexes(tx, &searchProblem{}, "Difficulty"); err != nil {
+		return err
+	}
+	if err := createTables(tx, &searchProblemTag{}); err != nil {
+		return err
+	}
+	if tx.Dialector.Name() == "mysql" && !tx.Migrator().HasIndex("problems", problemFullTextIndex) {
+		return tx.Exec("CREATE FULLTEXT INDEX ? ON problems (title, description) WITH PARSER ngram", clause.Column{Name: problemFullTextIndex}).Error
+	}
+	return nil
+}
+
+// downProblemSearch 删除全文索引、problem_tags表和新增的列，题目标签、难度和预计用时会丢失
+func downProblemSearch(tx *gorm.DB) error {
+	if tx.Dialector.Name() == "mysql" && tx.Migrator().HasIndex("problems", problemFullTextIndex) {
+		if err := tx.Exec("DROP INDEX ? ON problems", clause.Column{Name: problemFullTextIndex}).Error; err != nil {
+			return err
+		}
+	}
+	if err := tx.Migrator().DropTable(&searchProblemTag{}); err != nil {
+		return err
+	}
+	if err := dropIndexes(tx, &searchProblem{}, "Difficulty"); err != nil {
+		return err
+	}
+	return dropColumns(tx, "problems", "estimated_minutes", "difficulty")
+}
+
+// 版本7：题目解锁条件
+
+type prerequisiteProblemPrerequisite struct {
+	ID        uint `gorm:"primarykey"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+
+	ProblemID         uint   `gorm:"not null;uniqueIndex:idx_prerequisites_problem_required"`
+	RequiredProblemID uint   `gorm:"not null;uniqueIndex:idx_prerequisites_problem_required;index"`
+	Type              string `gorm:"size:20;not null"`
+	MinScore          int    `gorm:"not null;default:0"`
+
+	Problem         initialProblem `gorm:"constraint:OnDelete:CASCADE"`
+	RequiredProblem initialProblem `gorm:"foreignKey:RequiredProblemID;constraint:OnDelete:CASCADE"`
+}
+
+func (prerequisiteProblemPrerequisite) TableName() string { return "problem_prerequisites" }
+
+// upProblemPrerequisites 新建problem_prerequisites表，彻底删除题目时一并删除相关的解锁条件
+func upProblemPrerequisites(tx *gorm.DB) error {
+	return createTables(tx, &prerequisiteProblemPrerequisite{})
+}
+
+// downProblemPrerequisites 删除problem_prerequisites表，全部题目随之不再需要解锁
+func downProblemPrerequisites(tx *gorm.DB) error {
+	return tx.Migrator().DropTable(&prerequisiteProblemPrerequisite{})
+}
+
+// 版本8：题目提示与解锁记录
+
+type hintProblemHint struct {
+	ID        uint `gorm:"primarykey"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+
+	ProblemID uint   `gorm:"not null;index"`
+	Position  int    `gorm:"not null;default:0"`
+	Content   string `gorm:"type:text;not null"`
+	Penalty   int    `gorm:"not null;default:0"`
+
+	Problem initialProblem `gorm:"constraint:OnDelete:CASCADE"`
+}
+
+func (hintProblemHint) TableName() string { return "problem_hints" }
+
+type hintHintUnlock struct {
+	ID        uint `gorm:"primarykey"`
+	CreatedAt time.Time
+
+	UserID    uint `gorm:"not null;uniqueIndex:idx_hint_unlocks_user_hint"`
+	HintID    uint `gorm:"not null;uniqueIndex:idx_hint_unlocks_user_hint;index"`
+	ProblemID uint `gorm:"not null;index"`
+	Penalty   int  `gorm:"not null"`
+
+	User    initialUser     `gorm:"constraint:OnDelete:CASCADE"`
+	Hint    hintProblemHint `gorm:"constraint:OnDelete:CASCADE"`
+	Problem initialProblem  `gorm:"constraint:OnDelete:CASCADE"`
+}
+
+func (hintHintUnlock) TableName() string { return "hint_unlocks" }
+
+// upProblemHints 新建problem_hints和hint_unlocks表，彻底删除题目或用户时一并删除相关的提示和解锁记录
+func upProblemHints(tx *gorm.DB) error {
+	return createTables(tx, &hintProblemHint{}, &hintHintUnlock{})
+}
+
+// downProblemHints 删除hint_unlocks和problem_hints表，已扣除的提示惩罚分随之恢复
+func downProblemHints(tx *gorm.DB) error {
+	return tx.Migrator().DropTable(&hintHintUnlock{}, &hintProblemHint{})
+}
+
+// 版本9：题目修订记录
+
+type revisionProblem struct {
+	StatementUpdatedAt *time.Time
+}
+
+func (revisionProblem) TableName() string { return "problems" }
+
+type revisionProblemRevision struct {
+	ID        uint `gorm:"primarykey"`
+	CreatedAt time.Time
+
+	ProblemID        uint   `gorm:"not null;uniqueIndex:idx_problem_revisions_problem_version"`
+	Version          int    `gorm:"not null;uniqueIndex:idx_problem_revisions_problem_version"`
+	Title            string `gorm:"size:200;not null"`
+	Description      string `gorm:"type:text"`
+	SubmissionPoints string `gorm:"type:text"`
+	Significant      bool   `gorm:"not null;default:false"`
+	Note             string `gorm:"size:500"`
+	OperatorID       uint
+	OperatorName     string `gorm:"size:50"`
+
+	Problem initialProblem `gorm:"constraint:OnDelete:CASCADE"`
+}
+
+func (revisionProblemRevision) TableName() string { return "problem_revisions" }
+
+// revisionPoint 修订记录中提交点设置的JSON格式，截止时间统一为UTC
+type revisionPoint struct {
+	Name     string     `json:"name"`
+	MaxScore int        `json:"max_score"`
+	Rubric   string     `json:"rubric,omitempty"`
+	Deadline *time.Time `json:"deadline,omitempty"`
+}
+
+// upProblemRevisions 新增problems.statement_updated_at和problem_revisions表，
+// 并以每道题目（含回收站中的题目）当前的标题、题面和提交点作为版本1
+func upProblemRevisions(tx *gorm.DB) error {
+	if err := addColumns(tx, &revisionProblem{}, "StatementUpdatedAt"); err != nil {
+		return err
+	}
+	if err := createTables(tx, &revisionProblemRevision{}); err != nil {
+		return err
+	}
+
+	var problems []struct {
+		ID          uint
+		Title       string
+		Description string
+	}
+	// 跳过已有修订记录的题目，中途失败后重新执行时不会重复写入
+	err := tx.Table("problems").Select("id, title, description").
+		Where("NOT EXISTS (SELECT 1 FROM problem_revisions r WHERE r.problem_id = problems.id)").
+		Order("id").Scan(&problems).Error
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	for _, problem := range problems {
+		var points []struct {
+			Name     string
+			MaxScore int
+			Rubric   string
+			Deadline *time.Time
+		}
+		err := tx.Table("submission_points").Select("name, max_score, rubric, deadline").
+			Where("problem_id = ? AND deleted_at IS NULL", problem.ID).Order("id").Scan(&points).Error
+		if err != nil {
+			return err
+		}
+		snapshot := make([]revisionPoint, len(points))
+		for i, point := range points {
+			snapshot[i] = revisionPoint{Name: point.Name, MaxScore: point.MaxScore, Rubric: point.Rubric}
+			if point.Deadline != nil {
+				deadline := point.Deadline.UTC()
+				snapshot[i].Deadline = &deadline
+			}
+		}
+		data, err := json.Marshal(snapshot)
+		if err != nil {
+			return err
+		}
+
+		revision := revisionProblemRevision{
+			CreatedAt:        now,
+			ProblemID:        problem.ID,
+			Version:          1,
+			Title:            problem.Title,
+			Description:      problem.Description,
+			SubmissionPoints: string(data),
+			Note:             "初始版本",
+		}
+		if err := tx.Omit("Problem").Create(&revision).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// downProblemRevisions 删除problem_revisions表和problems.statement_updated_at，修订记录和更新标记会丢失
+func downProblemRevisions(tx *gorm.DB) error {
+	if err := tx.Migrator().DropTable(&revisionProblemRevision{}); err != nil {
+		return err
+	}
+	return dropColumns(tx, "problems", "statement_updated_at")
+}
+
+// 版本10：自动评测
+
+type judgeSubmissionPoint struct {
+	Type            string `gorm:"size:20;not null;default:'manual'"`
+	JudgeLanguage   string `gorm:"size:20"`
+	TimeLimitMS     int    `gorm:"not null;default:0"`
+	MemoryLimitMB   int    `gorm:"not null;default:0"`
+	Checker         string `gorm:"type:text"`
+	CheckerLanguage string `gorm:"size:20"`
+}
+
+func (judgeSubmissionPoint) TableName() string { return "submission_points" }
+
+type judgeJudgeCase struct {
+	ID        uint `gorm:"primarykey"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+
+	SubmissionPointID uint `gorm:"not null;index"`
+	Position          int  `gorm:"not null;default:0"`
+	Weight            int  `gorm:"not null;default:1"`
+	InputSize         int64
+	OutputSize        int64
+	InputKey          string `gorm:"size:255;not null"`
+	OutputKey         string `gorm:"size:255;not null"`
+
+	SubmissionPoint initialSubmissionPoint `gorm:"constraint:OnDelete:CASCADE"`
+}
+
+func (judgeJudgeCase) TableName() string { return "judge_cases" }
+
+type judgeJudgeRun struct {
+	ID        uint `gorm:"primarykey"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+
+	SubmissionID uint   `gorm:"not null;uniqueIndex"`
+	Status       string `gorm:"size:20;not null;index"`
+	Attempt      int    `gorm:"not null;default:0"`
+	Verdict      string `gorm:"size:30"`
+	Score        int
+	PassedCount  int
+	CaseCount    int
+	Message      string `gorm:"type:text"`
+	Results      string `gorm:"type:text"`
+	StartedAt    *time.Time
+	FinishedAt   *time.Time
+
+	Submission initialSubmission `gorm:"constraint:OnDelete:CASCADE"`
+}
+
+func (judgeJudgeRun) TableName() string { return "judge_runs" }
+
+// judgeScore scores表上的索引，SQLite修改约束时会重建表，需要补回索引
+type judgeScore struct {
+	SubmissionID uint           `gorm:"uniqueIndex:idx_scores_submission_reviewer"`
+	ReviewerID   uint           `gorm:"uniqueIndex:idx_scores_submission_reviewer"`
+	DeletedAt    gorm.DeletedAt `gorm:"index"`
+}
+
+func (judgeScore) TableName() string { return "scores" }
+
+// upJudge 为提交点新增评测设置，新建judge_cases和judge_runs表；
+// 自动评测以评分者ID 0写入系统评分，因此删除scores.reviewer_id上的外键
+func upJudge(tx *gorm.DB) error {
+	if err := addColumns(tx, &judgeSubmissionPoint{}, "Type", "JudgeLanguage", "TimeLimitMS", "MemoryLimitMB", "Checker", "CheckerLanguage"); err != nil {
+		return err
+	}
+	if err := createTables(tx, &judgeJudgeCase{}, &judgeJudgeRun{}); err != nil {
+		return err
+	}
+	if tx.Migrator().HasConstraint(&initialScore{}, "Reviewer") {
+		if err := tx.Migrator().DropConstraint(&initialScore{}, "Reviewer"); err != nil {
+			return err
+		}
+	}
+	return restoreScoreIndexes(tx)
+}
+
+// downJudge 删除系统评分后恢复scores.reviewer_id上的外键，再删除评测相关的表和字段；
+// 测试点文件保留在存储中，需要手动清理
+func downJudge(tx *gorm.DB) error {
+	if err := tx.Unscoped().Where("reviewer_id = ?", 0).Delete(&initialScore{}).Error; err != nil {
+		return err
+	}
+	if !tx.Migrator().HasConstraint(&initialScore{}, "Reviewer") {
+		if err := tx.Migrator().CreateConstraint(&initialScore{}, "Reviewer"); err != nil {
+			return err
+		}
+	}
+	if err := restoreScoreIndexes(tx); err != nil {
+		return err
+	}
+	if err := tx.Migrator().DropTable(&judgeJudgeRun{}, &judgeJudgeCase{}); err != nil {
+		return err
+	}
+	return dropColumns(tx, "submission_points", "type", "judge_language", "time_limit_ms", "memory_limit_mb", "checker", "checker_language")
+}
+
+// restoreScoreIndexes 补回scores表上缺失的索引，只有SQLite重建表后会缺失
+func restoreScoreIndexes(tx *gorm.DB) error {
+	return createIndexes(tx, &judgeScore{}, "idx_scores_submission_reviewer", "idx_scores_deleted_at")
+}
+
+// 版本11：代码仓库检查
+
+type checksCheckStep struct {
+	ID        uint `gorm:"primarykey"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+
+	SubmissionPointID uint   `gorm:"not null;index"`
+	Position          int    `gorm:"not null;default:0"`
+	Name              string `gorm:"size:100;not null"`
+	Kind              string `gorm:"size:20;not null"`
+	Path              string `gorm:"size:255"`
+	Command           string `gorm:"type:text"`
+	TimeoutSeconds    int    `gorm:"not null;default:0"`
+	Points            int    `gorm:"not null;default:0"`
+	Criterion         string `gorm:"size:200"`
+
+	SubmissionPoint initialSubmissionPoint `gorm:"constraint:OnDelete:CASCADE"`
+}
+
+func (checksCheckStep) TableName() string { return "check_steps" }
+
+type checksCheckRun struct {
+	ID        uint `gorm:"primarykey"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+
+	SubmissionID     uint   `gorm:"not null;uniqueIndex"`
+	Status           string `gorm:"size:20;not null;index"`
+	Attempt          int    `gorm:"not null;default:0"`
+	Commit           string `gorm:"size:64"`
+	PassedCount      int
+	StepCount        int
+	SuggestedScore   int
+	SuggestedComment string `gorm:"type:text"`
+	Message          string `gorm:"type:text"`
+	Results          string `gorm:"type:text"`
+	StartedAt        *time.Time
+	FinishedAt       *time.Time
+
+	Submission initialSubmission `gorm:"constraint:OnDelete:CASCADE"`
+}
+
+func (checksCheckRun) TableName() string { return "check_runs" }
+
+// upChecks 新建check_steps和check_runs表，代码仓库提交点沿用submission_points.type列
+func upChecks(tx *gorm.DB) error {
+	return createTables(tx, &checksCheckStep{}, &checksCheckRun{})
+}
+
+// downChecks 删除检查相关的表，代码仓库提交点改回人工评分
+func downChecks(tx *gorm.DB) error {
+	if err := tx.Migrator().DropTable(&checksCheckRun{}, &checksCheckStep{}); err != nil {
+		return err
+	}
+	return tx.Table("submission_points").Where("type = ?", "git").Update("type", "manual").Error
+}
+
+// 版本12：相似度检测
+
+type similaritySubmission struct {
+	SimilarityScore   float64 `gorm:"not null;default:0"`
+	SimilarityFlagged bool    `gorm:"not null;default:false"`
+}
+
+func (similaritySubmission) TableName() string { return "submissions" }
+
+type similarityCheckRun struct {
+	Authors string `gorm:"type:text"`
+}
+
+func (similarityCheckRun) TableName() string { return "check_runs" }
+
+type similaritySimilarityReport struct {
+	ID        uint `gorm:"primarykey"`
+	CreatedAt time.Time
+
+	SubmissionPointID uint    `gorm:"not null;index"`
+	SubmissionAID     uint    `gorm:"not null;uniqueIndex:idx_similarity_reports_pair"`
+	SubmissionBID     uint    `gorm:"not null;uniqueIndex:idx_similarity_reports_pair;index"`
+	Method            string  `gorm:"size:20;not null"`
+	Score             float64 `gorm:"not null;index"`
+	Detail            string  `gorm:"size:500"`
+
+	SubmissionPoint initialSubmissionPoint `gorm:"constraint:OnDelete:CASCADE"`
+	SubmissionA     initialSubmission      `gorm:"foreignKey:SubmissionAID;constraint:OnDelete:CASCADE"`
+	SubmissionB     initialSubmission      `gorm:"foreignKey:SubmissionBID;constraint:OnDelete:CASCADE"`
+}
+
+func (similaritySimilarityReport) TableName() string { return "similarity_reports" }
+
+// upSimilarity 为提交新增相似度和可疑标记，为检查记录新增提交作者，新建similarity_reports表
+func upSimilarity(tx *gorm.DB) error {
+	if err := addColumns(tx, &similaritySubmission{}, "SimilarityScore", "SimilarityFlagged"); err != nil {
+		return err
+	}
+	if err := addColumns(tx, &similarityCheckRun{}, "Authors"); err != nil {
+		return err
+	}
+	return createTables(tx, &similaritySimilarityReport{})
+}
+
+// downSimilarity 删除similarity_reports表及相似度相关的字段
+func downSimilarity(tx *gorm.DB) error {
+	if err := tx.Migrator().DropTable(&similaritySimilarityReport{}); err != nil {
+		return err
+	}
+	if err := dropColumns(tx, "check_runs", "authors"); err != nil {
+		return err
+	}
+	return dropColumns(tx, "submissions", "similarity_score", "similarity_flagged")
+}
+
+// 版本13：组队提交
+
+type teamsProblem struct {
+	TeamSize int `gorm:"not null;default:0"`
+}
+
+func (teamsProblem) TableName() string { return "problems" }
+
+type teamsSubmission struct {
+	SubmissionPointID uint  `gorm:"uniqueIndex:idx_submissions_team_point"`
+	TeamID            *uint `gorm:"uniqueIndex:idx_submissions_team_point"`
+}
+
+func (teamsSubmission) TableName() string { return "submissions" }
+
+type teamsTeam struct {
+	ID        uint `gorm:"primarykey"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+
+	ProblemID uint   `gorm:"not null;uniqueIndex:idx_teams_problem_name"`
+	Name      string `gorm:"size:50;not null;uniqueIndex:idx_teams_problem_name"`
+	LeaderID  uint   `gorm:"not null;index"`
+
+	Problem initialProblem `gorm:"constraint:OnDelete:CASCADE"`
+	Leader  initialUser    `gorm:"foreignKey:LeaderID"`
+}
+
+func (teamsTeam) TableName() string { return "teams" }
+
+type teamsTeamMember struct {
+	ID        uint `gorm:"primarykey"`
+	CreatedAt time.Time
+
+	TeamID    uint `gorm:"not null;index"`
+	ProblemID uint `gorm:"not null;uniqueIndex:idx_team_members_problem_user"`
+	UserID    uint `gorm:"not null;uniqueIndex:idx_team_members_problem_user;index"`
+
+	Team teamsTeam   `gorm:"constraint:OnDelete:CASCADE"`
+	User initialUser `gorm:"constraint:OnDelete:CASCADE"`
+}
+
+func (teamsTeamMember) TableName() string { return "team_members" }
+
+type teamsTeamInvite struct {
+	ID        uint `gorm:"primarykey"`
+	CreatedAt time.Time
+
+	TeamID    uint `gorm:"not null;uniqueIndex:idx_team_invites_team_user"`
+	UserID    uint `gorm:"not null;uniqueIndex:idx_team_invites_team_user;index"`
+	InviterID uint `gorm:"not null"`
+
+	Team    teamsTeam   `gorm:"constraint:OnDelete:CASCADE"`
+	User    initialUser `gorm:"constraint:OnDelete:CASCADE"`
+	Inviter initialUser `gorm:"foreignKey:InviterID;constraint:OnDelete:CASCADE"`
+}
+
+func (teamsTeamInvite) TableName() string { return "team_invites" }
+
+// upTeams 为题目新增队伍人数上限，为提交新增所属队伍，新建teams、team_members和team_invites表；
+// submissions.team_id不建外键（SQLite添加外键需要重建被引用的submissions表），队伍有提交后不能解散
+func upTeams(tx *gorm.DB) error {
+	if err := addColumns(tx, &teamsProblem{}, "TeamSize"); err != nil {
+		return err
+	}
+	if err := addColumns(tx, &teamsSubmission{}, "TeamID"); err != nil {
+		return err
+	}
+	if err := createIndexes(tx, &teamsSubmission{}, "idx_submissions_team_point"); err != nil {
+		return err
+	}
+	return createTables(tx, &teamsTeam{}, &teamsTeamMember{}, &teamsTeamInvite{})
+}
+
+// downTeams 删除队伍相关的表和字段；组队提交保留为首次提交的队员的个人提交
+func downTeams(tx *gorm.DB) error {
+	if err := tx.Migrator().DropTable(&teamsTeamInvite{}, &teamsTeamMember{}, &teamsTeam{}); err != nil {
+		return err
+	}
+	if err := dropIndexes(tx, &teamsSubmission{}, "idx_submissions_team_point"); err != nil {
+		return err
+	}
+	if err := dropColumns(tx, "submissions", "team_id"); err != nil {
+		return err
+	}
+	return dropColumns(tx, "problems", "team_size")
+}
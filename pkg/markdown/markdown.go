@@ -0,0 +1,221 @@
+// Package markdown 渲染方向和题目描述中的Markdown，输出经过清理的HTML
+package markdown
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/renderer/html"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+	nethtml "golang.org/x/net/html"
+)
+
+// ErrUnsafe 描述中包含不安全的HTML或链接
+var ErrUnsafe = errors.New("描述包含不安全的内容")
+
+// AttachmentDir 描述中以该目录开头的相对链接指向题目附件，与题目包中附件的位置一致
+const AttachmentDir = "attachments"
+
+// dangerousElements 写入时拒绝的HTML元素，其余不在白名单中的元素在渲染时去除
+var dangerousElements = map[string]bool{
+	"script": true, "style": true, "iframe": true, "frame": true, "frameset": true,
+	"object": true, "embed": true, "applet": true, "base": true, "link": true, "meta": true,
+	"form": true, "input": true, "button": true, "textarea": true, "select": true,
+	"svg": true, "math": true, "template": true, "noscript": true,
+}
+
+// urlAttributes 需要检查协议的HTML属性
+var urlAttributes = map[string]bool{
+	"href": true, "src": true, "action": true, "formaction": true, "background": true,
+	"poster": true, "cite": true, "xlink:href": true,
+}
+
+var attachmentBaseKey = parser.NewContextKey()
+
+var md = goldmark.New(
+	goldmark.WithExtensions(extension.GFM),
+	goldmark.WithParserOptions(
+		parser.WithBlockParsers(util.Prioritized(&mathBlockParser{}, 650)),
+		parser.WithASTTransformers(
+			util.Prioritized(&mathCodeTransformer{}, 100),
+			util.Prioritized(&attachmentLinkTransformer{}, 200),
+		),
+	),
+	goldmark.WithRendererOptions(
+		// 原始HTML先原样输出，再统一由policy清理
+		html.WithUnsafe(),
+		renderer.WithNodeRenderers(util.Prioritized(&mathRenderer{}, 100)),
+	),
+)
+
+var policy = newPolicy()
+
+// newPolicy 在UGC白名单的基础上允许代码高亮、公式和任务列表使用的属性
+func newPolicy() *bluemonday.Policy {
+	p := bluemonday.UGCPolicy()
+	p.AllowAttrs("class").Matching(regexp.MustCompile(`^language-[\w+#.-]+$`)).OnElements("code")
+	p.AllowAttrs("class").Matching(regexp.MustCompile(`^math math-display$`)).OnElements("div")
+	p.AllowAttrs("type").Matching(regexp.MustCompile(`^checkbox$`)).OnElements("input")
+	p.AllowAttrs("checked", "disabled").OnElements("input")
+	return p
+}
+
+// Render 将Markdown渲染为清理后的HTML
+// attachmentBase不为空时，attachments/<文件名>形式的相对链接和图片改写为attachmentBase+文件名
+func Render(source, attachmentBase string) string {
+	if source == "" {
+		return ""
+	}
+	ctx := parser.NewContext()
+	ctx.Set(attachmentBaseKey, attachmentBase)
+
+	var buf bytes.Buffer
+	if err := md.Convert([]byte(source), &buf, parser.WithContext(ctx)); err != nil {
+		return policy.Sanitize(string(util.EscapeHTML([]byte(source))))
+	}
+	return policy.Sanitize(buf.String())
+}
+
+// Check 检查Markdown中的原始HTML和链接，包含脚本、事件属性、内嵌框架或危险协议时返回ErrUnsafe
+// 白名单外的其他元素不会被拒绝，渲染时直接去除
+func Check(source string) error {
+	src := []byte(source)
+	doc := md.Parser().Parse(text.NewReader(src))
+
+	return ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch node := n.(type) {
+		case *ast.HTMLBlock:
+			var raw bytes.Buffer
+			lines := node.Lines()
+			for i := 0; i < lines.Len(); i++ {
+				segment := lines.At(i)
+				raw.Write(segment.Value(src))
+			}
+			if node.HasClosure() {
+				closure := node.ClosureLine
+				raw.Write(closure.Value(src))
+			}
+			return ast.WalkContinue, checkHTML(raw.Bytes())
+		case *ast.RawHTML:
+			var raw bytes.Buffer
+			for i := 0; i < node.Segments.Len(); i++ {
+				segment := node.Segments.At(i)
+				raw.Write(segment.Value(src))
+			}
+			return ast.WalkContinue, checkHTML(raw.Bytes())
+		case *ast.Link:
+			return ast.WalkContinue, checkURL(string(node.Destination))
+		case *ast.Image:
+			return ast.WalkContinue, checkURL(string(node.Destination))
+		case *ast.AutoLink:
+			return ast.WalkContinue, checkURL(string(node.URL(src)))
+		}
+		return ast.WalkContinue, nil
+	})
+}
+
+// checkHTML 检查一段原始HTML中的元素和属性
+func checkHTML(raw []byte) error {
+	tokenizer := nethtml.NewTokenizer(bytes.NewReader(raw))
+	for {
+		switch tokenizer.Next() {
+		case nethtml.ErrorToken:
+			return nil
+		case nethtml.StartTagToken, nethtml.SelfClosingTagToken:
+			token := tokenizer.Token()
+			if dangerousElements[token.Data] {
+				return fmt.Errorf("%w: 不允许使用<%s>", ErrUnsafe, token.Data)
+			}
+			for _, attr := range token.Attr {
+				name := strings.ToLower(attr.Key)
+				if strings.HasPrefix(name, "on") || name == "style" || name == "srcdoc" {
+					return fmt.Errorf("%w: 不允许使用%s属性", ErrUnsafe, name)
+				}
+				if urlAttributes[name] {
+					if err := checkURL(attr.Val); err != nil {
+						return err
+					}
+				}
+			}
+		}
+	}
+}
+
+// checkURL 只允许http、https、mailto协议和相对链接
+func checkURL(raw string) error {
+	// 浏览器解析协议时会忽略控制字符和空白
+	cleaned := strings.Map(func(r rune) rune {
+		if r <= ' ' || r == 0x7f {
+			return -1
+		}
+		return r
+	}, raw)
+	u, err := url.Parse(cleaned)
+	if err != nil {
+		return fmt.Errorf("%w: 链接格式错误", ErrUnsafe)
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "", "http", "https", "mailto":
+		return nil
+	}
+	return fmt.Errorf("%w: 不允许使用%s:链接", ErrUnsafe, strings.ToLower(u.Scheme))
+}
+
+// AttachmentName 解析指向题目附件的相对链接，返回附件文件名
+func AttachmentName(destination string) (string, bool) {
+	u, err := url.Parse(destination)
+	if err != nil || u.Scheme != "" || u.Host != "" || strings.HasPrefix(u.Path, "/") {
+		return "", false
+	}
+	cleaned := path.Clean(u.Path)
+	dir, name := path.Split(cleaned)
+	if dir != AttachmentDir+"/" || name == "" {
+		return "", false
+	}
+	return name, true
+}
+
+// attachmentLinkTransformer 将指向题目附件的相对链接改写为下载地址
+type attachmentLinkTransformer struct{}
+
+func (t *attachmentLinkTransformer) Transform(doc *ast.Document, reader text.Reader, pc parser.Context) {
+	base, _ := pc.Get(attachmentBaseKey).(string)
+	if base == "" {
+		return
+	}
+
+	rewrite := func(destination []byte) []byte {
+		name, ok := AttachmentName(string(destination))
+		if !ok {
+			return destination
+		}
+		return []byte(base + url.PathEscape(name))
+	}
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch node := n.(type) {
+		case *ast.Link:
+			node.Destination = rewrite(node.Destination)
+		case *ast.Image:
+			node.Destination = rewrite(node.Destination)
+		}
+		return ast.WalkContinue, nil
+	})
+}
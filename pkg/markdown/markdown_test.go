@@ -0,0 +1,145 @@
+package markdown
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		unsafe bool
+	}{
+		{"普通文本", "# 标题\n\n**加粗** 和 `代码`", false},
+		{"http链接", "[官网](https://example.com)", false},
+		{"mailto链接", "<mailto:admin@example.com>", false},
+		{"相对链接", "[附件](attachments/data.zip)", false},
+		{"白名单内的HTML块", "<details>\n<summary>提示</summary>\n\n内容\n</details>", false},
+		{"白名单外的元素只在渲染时去除", "<marquee>滚动</marquee>", false},
+
+		{"HTML块中的script", "<script>alert(1)</script>", true},
+		{"行内script", "文本 <script>alert(1)</script>", true},
+		{"HTML块中的iframe", "<iframe src=\"https://example.com\"></iframe>", true},
+		{"svg", "<svg><a href=\"https://example.com\">x</a></svg>", true},
+		{"HTML块中的事件属性", "<div onclick=\"alert(1)\">点击</div>", true},
+		{"行内事件属性", "文本 <img src=\"x.png\" onerror=\"alert(1)\">", true},
+		{"大写事件属性", "文本 <img src=\"x.png\" ONERROR=\"alert(1)\">", true},
+		{"style属性", "<p style=\"position:fixed\">x</p>", true},
+		{"srcdoc属性", "<div srcdoc=\"&lt;script&gt;alert(1)&lt;/script&gt;\">x</div>", true},
+		{"行内srcdoc属性", "文本 <span srcdoc=\"x\">y</span>", true},
+
+		{"javascript链接", "[点击](javascript:alert(1))", true},
+		{"大写javascript链接", "[点击](JavaScript:alert(1))", true},
+		{"链接中的制表符", "[点击](<java\tscript:alert(1)>)", true},
+		{"链接中的控制字符", "[点击](<java\x01script:alert(1)>)", true},
+		{"属性中的实体编码制表符", "<a href=\"java&#x09;script:alert(1)\">x</a>", true},
+		{"属性前导空白", "文本 <a href=\" \x0bjavascript:alert(1)\">x</a>", true},
+		{"data图片", "![图](data:image/svg+xml;base64,PHN2Zz4=)", true},
+		{"vbscript自动链接", "<vbscript:msgbox(1)>", true},
+		{"HTML块中的javascript链接", "<a href=\"javascript:alert(1)\">x</a>", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Check(tt.source)
+			if tt.unsafe && !errors.Is(err, ErrUnsafe) {
+				t.Errorf("应返回ErrUnsafe，得到%v", err)
+			}
+			if !tt.unsafe && err != nil {
+				t.Errorf("不应返回错误，得到%v", err)
+			}
+		})
+	}
+}
+
+func TestRenderSanitize(t *testing.T) {
+	tests := []struct {
+		name    string
+		source  string
+		want    []string
+		notWant []string
+	}{
+		{"白名单外的元素", "<marquee>滚动</marquee>", []string{"滚动"}, []string{"<marquee"}},
+		{"script", "<script>alert(1)</script>\n\n正文", []string{"正文"}, []string{"<script", "alert(1)"}},
+		{"行内script", "正文 <script>alert(1)</script>", []string{"正文"}, []string{"<script"}},
+		{"事件属性", "<p onclick=\"alert(1)\">段落</p>", []string{"<p>段落</p>"}, []string{"onclick"}},
+		{"行内事件属性", "文本 <img src=\"x.png\" onerror=\"alert(1)\">", []string{`<img src="x.png"`}, []string{"onerror"}},
+		{"srcdoc属性", "<iframe srcdoc=\"&lt;script&gt;\"></iframe>", nil, []string{"iframe", "srcdoc"}},
+		{"javascript链接", "[点击](javascript:alert(1))", []string{"点击"}, []string{"javascript"}},
+		{"链接中的控制字符", "<a href=\"java&#x09;script:alert(1)\">x</a>", nil, []string{"script:"}},
+		{"style属性", "<p style=\"position:fixed\">x</p>", []string{"<p>x</p>"}, []string{"style"}},
+		{"表单", "<form action=\"/api/auth/logout\"><button>退出</button></form>", nil, []string{"<form", "<button"}},
+		{"代码高亮", "```go\nfmt.Println()\n```", []string{`<code class="language-go">`}, nil},
+		{"公式块", "$$\na<b\n$$", []string{`<div class="math math-display">a&lt;b`}, nil},
+		{"任务列表", "- [x] 完成", []string{`<input checked="" disabled="" type="checkbox"`}, nil},
+		{"外部链接", "[官网](https://example.com)", []string{`href="https://example.com"`, `rel="nofollow"`}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Render(tt.source, "")
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("输出应包含%q，得到%q", want, got)
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(got, notWant) {
+					t.Errorf("输出不应包含%q，得到%q", notWant, got)
+				}
+			}
+		})
+	}
+}
+
+func TestRenderAttachmentLinks(t *testing.T) {
+	const base = "/api/problems/1/attachments/files/"
+	tests := []struct {
+		name   string
+		source string
+		base   string
+		want   string
+	}{
+		{"链接", "[数据](attachments/data.zip)", base, `href="/api/problems/1/attachments/files/data.zip"`},
+		{"图片", "![图](attachments/a.png)", base, `src="/api/problems/1/attachments/files/a.png"`},
+		{"文件名转义", "[数据](attachments/%E6%95%B0%E6%8D%AE%3F.txt)", base, `href="/api/problems/1/attachments/files/%E6%95%B0%E6%8D%AE%3F.txt"`},
+		{"规范化路径", "[数据](./attachments/data.zip)", base, `href="/api/problems/1/attachments/files/data.zip"`},
+		{"其他目录不改写", "[数据](files/data.zip)", base, `href="files/data.zip"`},
+		{"越出附件目录不改写", "[数据](attachments/../config.yaml)", base, `href="attachments/../config.yaml"`},
+		{"子目录不改写", "[数据](attachments/sub/data.zip)", base, `href="attachments/sub/data.zip"`},
+		{"绝对路径不改写", "[数据](/attachments/data.zip)", base, `href="/attachments/data.zip"`},
+		{"外部链接不改写", "[数据](https://example.com/attachments/data.zip)", base, `href="https://example.com/attachments/data.zip"`},
+		{"未指定下载地址", "[数据](attachments/data.zip)", "", `href="attachments/data.zip"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Render(tt.source, tt.base); !strings.Contains(got, tt.want) {
+				t.Errorf("输出应包含%q，得到%q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestAttachmentName(t *testing.T) {
+	tests := []struct {
+		destination string
+		name        string
+		ok          bool
+	}{
+		{"attachments/data.zip", "data.zip", true},
+		{"./attachments/data.zip", "data.zip", true},
+		{"attachments/%E6%95%B0%E6%8D%AE.txt", "数据.txt", true},
+		{"attachments/", "", false},
+		{"attachments/sub/data.zip", "", false},
+		{"attachments/../data.zip", "", false},
+		{"/attachments/data.zip", "", false},
+		{"https://example.com/attachments/data.zip", "", false},
+		{"//example.com/attachments/data.zip", "", false},
+	}
+	for _, tt := range tests {
+		name, ok := AttachmentName(tt.destination)
+		if name != tt.name || ok != tt.ok {
+			t.Errorf("AttachmentName(%q) = %q, %v，应为%q, %v", tt.destination, name, ok, tt.name, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+)
+
+// EmailChannel 通过HTTP邮件网关投递邮件
+type EmailChannel struct {
+	apiURL string
+	apiKey string
+	from   string
+	client *http.Client
+}
+
+// emailPayload 邮件网关请求体
+type emailPayload struct {
+	From    string `json:"from"`
+	To      string `json:"to"`
+	Subject string `json:"subject"`
+	Text    string `json:"text"`
+}
+
+// NewEmailChannel 创建邮件渠道
+func NewEmailChannel(apiURL, apiKey, from string, timeout time.Duration) *EmailChannel {
+	return &EmailChannel{
+		apiURL: apiURL,
+		apiKey: apiKey,
+		from:   from,
+		client: newHTTPClient(timeout),
+	}
+}
+
+// Name 渠道名称
+func (c *EmailChannel) Name() string {
+	return ChannelEmail
+}
+
+// Send 发送邮件
+func (c *EmailChannel) Send(ctx context.Context, to Recipient, msg Message) error {
+	if to.Email == "" {
+		return errors.New("用户未设置邮箱")
+	}
+
+	headers := map[string]string{}
+	if c.apiKey != "" {
+		headers["Authorization"] = "Bearer " + c.apiKey
+	}
+
+	return postJSON(ctx, c.client, c.apiURL, headers, emailPayload{
+		From:    c.from,
+		To:      to.Email,
+		Subject: msg.Title,
+		Text:    msg.Content,
+	})
+}
@@ -0,0 +1,84 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// 渠道名称
+const (
+	ChannelEmail   = "email"
+	ChannelWebhook = "webhook"
+	ChannelOneBot  = "onebot"
+)
+
+// Message 通知消息
+type Message struct {
+	Type      string    `json:"type"`
+	Title     string    `json:"title"`
+	Content   string    `json:"content"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Recipient 通知接收者
+type Recipient struct {
+	UserID     uint
+	Nickname   string
+	Email      string
+	QQ         string
+	WebhookURL string
+}
+
+// Channel 通知投递渠道
+type Channel interface {
+	// Name 渠道名称
+	Name() string
+	// Send 向接收者投递消息，接收者缺少该渠道所需信息时返回错误
+	Send(ctx context.Context, to Recipient, msg Message) error
+}
+
+// defaultTimeout 渠道请求默认超时时间
+const defaultTimeout = 10 * time.Second
+
+// postJSON 以JSON格式POST请求并检查响应状态码
+func postJSON(ctx context.Context, client *http.Client, url string, headers map[string]string, body interface{}) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("请求 %s 失败: HTTP %d %s", url, resp.StatusCode, string(respBody))
+	}
+
+	return nil
+}
+
+// newHTTPClient 创建带超时的HTTP客户端
+func newHTTPClient(timeout time.Duration) *http.Client {
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	return &http.Client{Timeout: timeout}
+}
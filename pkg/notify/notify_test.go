@@ -0,0 +1,179 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tksky1/glimgate/pkg/utils"
+)
+
+// capturedRequest 测试服务器收到的请求
+type capturedRequest struct {
+	path          string
+	contentType   string
+	authorization string
+	body          map[string]interface{}
+}
+
+// newTestServer 创建记录请求并返回status的测试服务器
+func newTestServer(t *testing.T, status int) (*httptest.Server, <-chan capturedRequest) {
+	t.Helper()
+	requests := make(chan capturedRequest, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := capturedRequest{
+			path:          r.URL.Path,
+			contentType:   r.Header.Get("Content-Type"),
+			authorization: r.Header.Get("Authorization"),
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req.body); err != nil {
+			t.Errorf("解析请求体失败: %v", err)
+		}
+		requests <- req
+		w.WriteHeader(status)
+		w.Write([]byte("gateway says no"))
+	}))
+	t.Cleanup(server.Close)
+	return server, requests
+}
+
+var testMessage = Message{
+	Type:      "score_created",
+	Title:     "新评分",
+	Content:   "你的提交获得了85分",
+	CreatedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+}
+
+func TestEmailChannel(t *testing.T) {
+	server, requests := newTestServer(t, http.StatusOK)
+	channel := NewEmailChannel(server.URL+"/api/send", "secret", "noreply@example.com", time.Second)
+
+	if err := channel.Send(context.Background(), Recipient{Email: "user@example.com"}, testMessage); err != nil {
+		t.Fatal(err)
+	}
+	req := <-requests
+	if req.path != "/api/send" || req.contentType != "application/json" || req.authorization != "Bearer secret" {
+		t.Errorf("请求: %+v", req)
+	}
+	want := map[string]interface{}{"from": "noreply@example.com", "to": "user@example.com", "subject": "新评分", "text": "你的提交获得了85分"}
+	for k, v := range want {
+		if req.body[k] != v {
+			t.Errorf("%s为%v，应为%v", k, req.body[k], v)
+		}
+	}
+
+	if err := channel.Send(context.Background(), Recipient{}, testMessage); err == nil || err.Error() != "用户未设置邮箱" {
+		t.Errorf("未设置邮箱时应返回错误，得到%v", err)
+	}
+}
+
+func TestEmailChannelWithoutAPIKey(t *testing.T) {
+	server, requests := newTestServer(t, http.StatusOK)
+	channel := NewEmailChannel(server.URL, "", "noreply@example.com", time.Second)
+
+	if err := channel.Send(context.Background(), Recipient{Email: "user@example.com"}, testMessage); err != nil {
+		t.Fatal(err)
+	}
+	if req := <-requests; req.authorization != "" {
+		t.Errorf("未配置密钥时不应发送Authorization: %q", req.authorization)
+	}
+}
+
+func TestChannelHTTPError(t *testing.T) {
+	server, _ := newTestServer(t, http.StatusBadGateway)
+	channel := NewEmailChannel(server.URL, "", "noreply@example.com", time.Second)
+
+	err := channel.Send(context.Background(), Recipient{Email: "user@example.com"}, testMessage)
+	if err == nil || !strings.Contains(err.Error(), "HTTP 502") || !strings.Contains(err.Error(), "gateway says no") {
+		t.Fatalf("非2xx响应应返回包含状态码和响应内容的错误，得到%v", err)
+	}
+}
+
+func TestChannelTimeout(t *testing.T) {
+	block := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-block
+	}))
+	t.Cleanup(server.Close)
+	t.Cleanup(func() { close(block) })
+	channel := NewOneBotChannel(server.URL, "", 50*time.Millisecond)
+
+	start := time.Now()
+	if err := channel.Send(context.Background(), Recipient{QQ: "10001"}, testMessage); err == nil {
+		t.Fatal("请求超时应返回错误")
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("超时未生效，耗时%v", elapsed)
+	}
+}
+
+func TestWebhookChannel(t *testing.T) {
+	server, requests := newTestServer(t, http.StatusNoContent)
+	channel := NewWebhookChannel(time.Second, true)
+
+	if err := channel.Send(context.Background(), Recipient{UserID: 7, WebhookURL: server.URL + "/hook"}, testMessage); err != nil {
+		t.Fatal(err)
+	}
+	req := <-requests
+	if req.path != "/hook" || req.authorization != "" {
+		t.Errorf("请求: %+v", req)
+	}
+	if req.body["user_id"] != float64(7) || req.body["type"] != "score_created" || req.body["title"] != "新评分" ||
+		req.body["content"] != "你的提交获得了85分" || req.body["created_at"] != "2024-01-01T00:00:00Z" {
+		t.Errorf("请求体: %v", req.body)
+	}
+
+	if err := channel.Send(context.Background(), Recipient{UserID: 7}, testMessage); err == nil || err.Error() != "用户未设置Webhook地址" {
+		t.Errorf("未设置地址时应返回错误，得到%v", err)
+	}
+}
+
+func TestWebhookChannelRejectsInternalHosts(t *testing.T) {
+	server, requests := newTestServer(t, http.StatusOK)
+	channel := NewWebhookChannel(time.Second, false)
+
+	// 测试服务器监听在127.0.0.1，默认拒绝连接
+	err := channel.Send(context.Background(), Recipient{UserID: 7, WebhookURL: server.URL}, testMessage)
+	if !errors.Is(err, utils.ErrInternalHost) {
+		t.Fatalf("应拒绝连接内网地址，得到%v", err)
+	}
+
+	select {
+	case req := <-requests:
+		t.Fatalf("内网服务不应收到请求: %+v", req)
+	default:
+	}
+}
+
+func TestOneBotChannel(t *testing.T) {
+	server, requests := newTestServer(t, http.StatusOK)
+	channel := NewOneBotChannel(server.URL+"/", "token", time.Second)
+
+	if err := channel.Send(context.Background(), Recipient{QQ: "123456789"}, testMessage); err != nil {
+		t.Fatal(err)
+	}
+	req := <-requests
+	if req.path != "/send_private_msg" || req.authorization != "Bearer token" {
+		t.Errorf("请求: %+v", req)
+	}
+	if req.body["user_id"] != float64(123456789) || req.body["message"] != "新评分\n你的提交获得了85分" {
+		t.Errorf("请求体: %v", req.body)
+	}
+
+	for _, tt := range []struct {
+		qq   string
+		want string
+	}{
+		{"", "用户未设置QQ号"},
+		{"qq123", "QQ号格式错误"},
+	} {
+		if err := channel.Send(context.Background(), Recipient{QQ: tt.qq}, testMessage); err == nil || err.Error() != tt.want {
+			t.Errorf("QQ号%q应返回%q，得到%v", tt.qq, tt.want, err)
+		}
+	}
+}
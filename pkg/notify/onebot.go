@@ -0,0 +1,58 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// OneBotChannel 通过OneBot v11 HTTP API发送QQ私聊消息
+type OneBotChannel struct {
+	baseURL     string
+	accessToken string
+	client      *http.Client
+}
+
+// oneBotPrivateMsg send_private_msg请求体
+type oneBotPrivateMsg struct {
+	UserID  int64  `json:"user_id"`
+	Message string `json:"message"`
+}
+
+// NewOneBotChannel 创建QQ机器人渠道
+func NewOneBotChannel(baseURL, accessToken string, timeout time.Duration) *OneBotChannel {
+	return &OneBotChannel{
+		baseURL:     strings.TrimRight(baseURL, "/"),
+		accessToken: accessToken,
+		client:      newHTTPClient(timeout),
+	}
+}
+
+// Name 渠道名称
+func (c *OneBotChannel) Name() string {
+	return ChannelOneBot
+}
+
+// Send 发送QQ私聊消息
+func (c *OneBotChannel) Send(ctx context.Context, to Recipient, msg Message) error {
+	if to.QQ == "" {
+		return errors.New("用户未设置QQ号")
+	}
+	qq, err := strconv.ParseInt(to.QQ, 10, 64)
+	if err != nil {
+		return errors.New("QQ号格式错误")
+	}
+
+	headers := map[string]string{}
+	if c.accessToken != "" {
+		headers["Authorization"] = "Bearer " + c.accessToken
+	}
+
+	return postJSON(ctx, c.client, c.baseURL+"/send_private_msg", headers, oneBotPrivateMsg{
+		UserID:  qq,
+		Message: msg.Title + "\n" + msg.Content,
+	})
+}
@@ -0,0 +1,56 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/tksky1/glimgate/pkg/utils"
+)
+
+// WebhookChannel 向用户配置的地址推送通用Webhook
+type WebhookChannel struct {
+	client *http.Client
+}
+
+// webhookPayload Webhook请求体
+type webhookPayload struct {
+	UserID uint `json:"user_id"`
+	Message
+}
+
+// NewWebhookChannel 创建Webhook渠道
+// 地址由用户填写，allowPrivateHosts为false时拒绝连接内网地址，避免用户借此访问服务器所在内网的服务
+func NewWebhookChannel(timeout time.Duration, allowPrivateHosts bool) *WebhookChannel {
+	client := newHTTPClient(timeout)
+	if !allowPrivateHosts {
+		dialer := &net.Dialer{Timeout: client.Timeout, Control: utils.PublicDialControl}
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		// 不经过代理，保证检查的是实际连接的地址
+		transport.Proxy = nil
+		transport.DialContext = dialer.DialContext
+		client.Transport = transport
+	}
+	return &WebhookChannel{
+		client: client,
+	}
+}
+
+// Name 渠道名称
+func (c *WebhookChannel) Name() string {
+	return ChannelWebhook
+}
+
+// Send 推送Webhook
+func (c *WebhookChannel) Send(ctx context.Context, to Recipient, msg Message) error {
+	if to.WebhookURL == "" {
+		return errors.New("用户未设置Webhook地址")
+	}
+
+	return postJSON(ctx, c.client, to.WebhookURL, nil, webhookPayload{
+		UserID:  to.UserID,
+		Message: msg,
+	})
+}
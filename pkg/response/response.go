@@ -19,17 +19,31 @@ const (
 	CodeError   = 1
 
 	// 用户相关错误码
-	CodeUserNotFound     = 1001
-	CodeUserExists       = 1002
-	CodeInvalidPassword  = 1003
-	CodeUnauthorized     = 1004
-	CodeForbidden        = 1005
-	CodeInvalidToken     = 1006
+	CodeUserNotFound    = 1001
+	CodeUserExists      = 1002
+	CodeInvalidPassword = 1003
+	CodeUnauthorized    = 1004
+	CodeForbidden       = 1005
+	CodeInvalidToken    = 1006
 
 	// 题目相关错误码
-	CodeDirectionNotFound = 2001
-	CodeProblemNotFound   = 2002
-	CodeSubmissionNotFound = 2003
+	CodeDirectionNotFound     = 2001
+	CodeProblemNotFound       = 2002
+	CodeSubmissionNotFound    = 2003
+	CodeClarificationNotFound = 2004
+	CodeNotificationNotFound  = 2005
+	CodeWebhookNotFound       = 2006
+	CodeDeliveryNotFound      = 2007
+	CodeTrashItemNotFound     = 2008
+	CodeAttachmentNotFound    = 2009
+	CodeHintNotFound          = 2010
+	CodeRevisionNotFound      = 2011
+	CodeJudgeCaseNotFound     = 2012
+	CodeJudgeRunNotFound      = 2013
+	CodeCheckStepNotFound     = 2014
+	CodeCheckRunNotFound      = 2015
+	CodeTeamNotFound          = 2016
+	CodeTeamInviteNotFound    = 2017
 
 	// 参数错误码
 	CodeInvalidParams = 3001
@@ -45,16 +59,30 @@ var codeMsg = map[int]string{
 	CodeSuccess: "success",
 	CodeError:   "error",
 
-	CodeUserNotFound:     "用户不存在",
-	CodeUserExists:       "用户已存在",
-	CodeInvalidPassword:  "密码错误",
-	CodeUnauthorized:     "未授权",
-	CodeForbidden:        "权限不足",
-	CodeInvalidToken:     "无效的token",
-
-	CodeDirectionNotFound:  "方向不存在",
-	CodeProblemNotFound:    "题目不存在",
-	CodeSubmissionNotFound: "提交不存在",
+	CodeUserNotFound:    "用户不存在",
+	CodeUserExists:      "用户已存在",
+	CodeInvalidPassword: "密码错误",
+	CodeUnauthorized:    "未授权",
+	CodeForbidden:       "权限不足",
+	CodeInvalidToken:    "无效的token",
+
+	CodeDirectionNotFound:     "方向不存在",
+	CodeProblemNotFound:       "题目不存在",
+	CodeSubmissionNotFound:    "提交不存在",
+	CodeClarificationNotFound: "答疑不存在",
+	CodeNotificationNotFound:  "通知不存在",
+	CodeWebhookNotFound:       "Webhook不存在",
+	CodeDeliveryNotFound:      "投递记录不存在",
+	CodeTrashItemNotFound:     "回收站记录不存在",
+	CodeAttachmentNotFound:    "附件不存在",
+	CodeHintNotFound:          "提示不存在",
+	CodeRevisionNotFound:      "版本不存在",
+	CodeJudgeCaseNotFound:     "测试点不存在",
+	CodeJudgeRunNotFound:      "评测记录不存在",
+	CodeCheckStepNotFound:     "检查步骤不存在",
+	CodeCheckRunNotFound:      "检查报告不存在",
+	CodeTeamNotFound:          "队伍不存在",
+	CodeTeamInviteNotFound:    "邀请不存在",
 
 	CodeInvalidParams: "参数错误",
 	CodeBindError:     "参数绑定失败",
@@ -110,4 +138,4 @@ func Forbidden(c *gin.Context) {
 		Code: CodeForbidden,
 		Msg:  GetMsg(CodeForbidden),
 	})
-}
\ No newline at end of file
+}
@@ -0,0 +1,227 @@
+// Package sandbox 在受限的本地进程中运行不受信任的程序，限制CPU时间、内存、运行时间和输出大小
+// 限制通过/bin/sh的ulimit设置，进程在独立的进程组和PID命名空间中运行，结束时命名空间中的进程全部结束；目前仅支持Linux，
+// 非root运行时需要系统允许创建用户命名空间
+//
+// 虚拟内存上限为内存限制的2倍，为解释器和运行时预留的地址空间留出余量，包括子进程在内的实际占用超过内存限制时
+// 结束所有进程并判为超出内存限制；超过虚拟内存上限时内存分配失败，通常表现为运行错误
+package sandbox
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"time"
+)
+
+// ErrUnsupported 当前平台不支持沙箱
+var ErrUnsupported = errors.New("沙箱仅支持Linux")
+
+// Status 运行结果
+type Status string
+
+const (
+	StatusOK           Status = "ok"            // 正常退出，退出码为0
+	StatusRuntimeError Status = "runtime_error" // 退出码非0或被信号结束
+	StatusTimeLimit    Status = "time_limit"    // 超过CPU时间或运行时间
+	StatusMemoryLimit  Status = "memory_limit"  // 内存峰值超过限制
+	StatusOutputLimit  Status = "output_limit"  // 标准输出超过限制
+)
+
+// Limits 资源限制，零值表示不限制
+type Limits struct {
+	CPUTime     time.Duration // CPU时间，按秒向上取整交给ulimit
+	WallTime    time.Duration // 运行时间，超过后结束整个进程组
+	MemoryMB    int           // 内存上限
+	OutputBytes int64         // 标准输出上限，超过后结束整个进程组，见Command.TruncateOutput
+	// Processes 进程数上限，线程也计入；按运行用户统计，只在切换到专用用户或非root运行时生效
+	Processes int
+	// FileSizeMB 单个文件的大小上限，超过后写入失败
+	FileSizeMB int
+}
+
+// Options 进程隔离选项
+type Options struct {
+	// UID、GID 以指定用户运行，0表示不切换，需要以root运行服务；
+	// 同时运行的沙箱应使用不同的UID，否则可以互相访问文件、向对方的进程发送信号，进程数限制也会合并计算
+	UID int
+	GID int
+	// IsolateNetwork 在独立的网络命名空间中运行，进程无法访问网络
+	IsolateNetwork bool
+}
+
+// Command 待运行的命令
+type Command struct {
+	Args   []string  // 程序及参数
+	Dir    string    // 工作目录
+	Env    []string  // 环境变量，为空时只设置PATH
+	Stdin  io.Reader // 标准输入，为nil时为空
+	Limits Limits
+	// TruncateOutput 标准输出超过限制时丢弃超出的部分而不结束进程，用于只需保留日志开头的命令
+	TruncateOutput bool
+}
+
+// Result 运行结果
+type Result struct {
+	Status   Status
+	ExitCode int // 被信号结束时为-1
+	Stdout   []byte
+	Stderr   []byte // 最多保留stderrLimit字节
+	CPUTime  time.Duration
+	WallTime time.Duration
+	MemoryKB int64 // 包括子进程在内的内存峰值，按固定间隔采样，运行时间极短的程序可能为0
+}
+
+// stderrLimit 标准错误保留的字节数，用于编译错误等提示
+const stderrLimit = 64 << 10
+
+// defaultPath 未指定环境变量时使用的PATH
+const defaultPath = "PATH=/usr/local/bin:/usr/bin:/bin"
+
+// Sandbox 按相同的隔离选项运行命令
+type Sandbox struct {
+	opts Options
+}
+
+// New 创建沙箱，当前平台不支持时返回ErrUnsupported
+func New(opts Options) (*Sandbox, error) {
+	if !supported {
+		return nil, ErrUnsupported
+	}
+	return &Sandbox{opts: opts}, nil
+}
+
+// Run 运行命令直到结束或超过限制，命令无法启动时返回错误
+func (s *Sandbox) Run(c *Command) (*Result, error) {
+	if len(c.Args) == 0 {
+		return nil, errors.New("命令为空")
+	}
+
+	// 由sh设置资源限制后exec目标程序，资源统计和退出状态均属于目标程序
+	script := "exec \"$@\""
+	if c.Limits.MemoryMB > 0 {
+		script = fmt.Sprintf("ulimit -v %d && %s", c.Limits.MemoryMB<<11, script)
+	}
+	if c.Limits.FileSizeMB > 0 {
+		// ulimit -f以512字节为单位
+		script = fmt.Sprintf("ulimit -f %d && %s", c.Limits.FileSizeMB<<11, script)
+	}
+	if c.Limits.Processes > 0 {
+		// bash用-u设置进程数，dash用-p
+		script = fmt.Sprintf("{ ulimit -u %d || ulimit -p %d; } 2>/dev/null && %s", c.Limits.Processes, c.Limits.Processes, script)
+	}
+	if c.Limits.CPUTime > 0 {
+		// 软限制到达时发送SIGXCPU，硬限制多留1秒，避免两者相同时直接以SIGKILL结束而无法判为超时；
+		// 目标程序作为PID命名空间的init会忽略SIGXCPU，这时由硬限制结束，按CPU时间判为超时
+		seconds := int64((c.Limits.CPUTime + time.Second - 1) / time.Second)
+		script = fmt.Sprintf("ulimit -S -t %d && ulimit -H -t %d && %s", seconds, seconds+1, script)
+	}
+	cmd := exec.Command("/bin/sh", append([]string{"-c", script, "sandbox"}, c.Args...)...)
+	cmd.Dir = c.Dir
+	cmd.Env = c.Env
+	if len(cmd.Env) == 0 {
+		cmd.Env = []string{defaultPath}
+	}
+	cmd.Stdin = c.Stdin
+	cmd.SysProcAttr = sysProcAttr(s.opts)
+
+	stdout := newLimitedBuffer(c.Limits.OutputBytes, c.TruncateOutput)
+	stderr := newLimitedBuffer(stderrLimit, true)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	start := time.Now()
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	memoryExceeded := make(chan struct{})
+	peakMemory := watchMemory(cmd.Process.Pid, int64(c.Limits.MemoryMB)<<10, func() { close(memoryExceeded) })
+
+	// 超时、输出或内存超限时结束整个进程组，避免子进程残留
+	done := make(chan struct{})
+	timedOut := make(chan struct{})
+	go func() {
+		var timeout <-chan time.Time
+		if c.Limits.WallTime > 0 {
+			timer := time.NewTimer(c.Limits.WallTime)
+			defer timer.Stop()
+			timeout = timer.C
+		}
+		select {
+		case <-done:
+		case <-timeout:
+			close(timedOut)
+			killGroup(cmd.Process.Pid)
+		case <-stdout.exceeded:
+			killGroup(cmd.Process.Pid)
+		case <-memoryExceeded:
+			killGroup(cmd.Process.Pid)
+		}
+	}()
+	waitErr := cmd.Wait()
+	close(done)
+	memoryKB := peakMemory()
+	// 目标程序退出时内核已结束命名空间中的其他进程，这里再结束一次进程组作为兜底
+	killGroup(cmd.Process.Pid)
+
+	result := &Result{
+		Stdout:   stdout.buf.Bytes(),
+		Stderr:   stderr.buf.Bytes(),
+		WallTime: time.Since(start),
+		ExitCode: cmd.ProcessState.ExitCode(),
+		CPUTime:  cmd.ProcessState.UserTime() + cmd.ProcessState.SystemTime(),
+		MemoryKB: memoryKB,
+	}
+	var exitErr *exec.ExitError
+	if waitErr != nil && !errors.As(waitErr, &exitErr) {
+		return nil, waitErr
+	}
+
+	select {
+	case <-timedOut:
+		result.Status = StatusTimeLimit
+		return result, nil
+	default:
+	}
+	switch {
+	case stdout.over:
+		result.Status = StatusOutputLimit
+	case c.Limits.CPUTime > 0 && (result.CPUTime > c.Limits.CPUTime || cpuLimitSignaled(cmd.ProcessState)):
+		result.Status = StatusTimeLimit
+	case c.Limits.MemoryMB > 0 && result.MemoryKB > int64(c.Limits.MemoryMB)<<10:
+		result.Status = StatusMemoryLimit
+	case result.ExitCode != 0:
+		result.Status = StatusRuntimeError
+	default:
+		result.Status = StatusOK
+	}
+	return result, nil
+}
+
+// limitedBuffer 最多保存limit字节的缓冲区，limit为0时不限制
+// truncate为true时丢弃超出的部分，否则标记超限并通知调用方结束进程
+type limitedBuffer struct {
+	buf      bytes.Buffer
+	limit    int64
+	truncate bool
+	over     bool
+	exceeded chan struct{} // 超限时关闭
+}
+
+func newLimitedBuffer(limit int64, truncate bool) *limitedBuffer {
+	return &limitedBuffer{limit: limit, truncate: truncate, exceeded: make(chan struct{})}
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	n := len(p)
+	if b.limit > 0 && int64(b.buf.Len()+len(p)) > b.limit {
+		p = p[:b.limit-int64(b.buf.Len())]
+		if !b.truncate && !b.over {
+			b.over = true
+			close(b.exceeded)
+		}
+	}
+	b.buf.Write(p)
+	return n, nil
+}
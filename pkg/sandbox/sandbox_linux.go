@@ -0,0 +1,161 @@
+package sandbox
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const supported = true
+
+// memoryPollInterval 采样内存峰值的间隔
+const memoryPollInterval = 5 * time.Millisecond
+
+// memberScanTicks 每隔多少次采样重新查找沙箱中的进程，查找需要遍历/proc，开销较大
+const memberScanTicks = 4
+
+// sysProcAttr 在新的进程组和PID命名空间中运行，服务退出时一并结束；需要时切换用户并隔离网络
+// 目标程序是PID命名空间中的第一个进程(init)，它退出时内核结束命名空间中的所有进程，
+// 用setsid脱离进程组的子进程也无法在运行结束后残留
+func sysProcAttr(opts Options) *syscall.SysProcAttr {
+	attr := &syscall.SysProcAttr{Setpgid: true, Pdeathsig: syscall.SIGKILL, Cloneflags: syscall.CLONE_NEWPID}
+	if opts.UID > 0 {
+		attr.Credential = &syscall.Credential{Uid: uint32(opts.UID), Gid: uint32(opts.GID)}
+	}
+	if opts.IsolateNetwork {
+		attr.Cloneflags |= syscall.CLONE_NEWNET
+	}
+	// 非root运行时借助用户命名空间创建其他命名空间，命名空间内的用户映射为当前用户
+	if uid := os.Geteuid(); uid != 0 {
+		gid := os.Getegid()
+		attr.Cloneflags |= syscall.CLONE_NEWUSER
+		attr.UidMappings = []syscall.SysProcIDMap{{ContainerID: uid, HostID: uid, Size: 1}}
+		attr.GidMappings = []syscall.SysProcIDMap{{ContainerID: gid, HostID: gid, Size: 1}}
+	}
+	return attr
+}
+
+// killGroup 结束整个进程组
+func killGroup(pid int) {
+	_ = syscall.Kill(-pid, syscall.SIGKILL)
+}
+
+// cpuLimitSignaled 进程是否因超过CPU时间限制被结束
+func cpuLimitSignaled(state *os.ProcessState) bool {
+	status, ok := state.Sys().(syscall.WaitStatus)
+	return ok && status.Signaled() && status.Signal() == syscall.SIGXCPU
+}
+
+// watchMemory 定期采样沙箱中所有进程的内存占用，返回的函数停止采样并返回观察到的峰值(KB)；
+// limitKB大于0时，峰值超过limitKB后调用一次exceeded
+//
+// 目标程序是PID命名空间的init，命名空间中的其他进程都是它的子孙，每隔memberScanTicks次采样重新查找一次；
+// 只有目标程序一个进程时取其VmHWM，有多个进程时取各进程Pss之和，避免fork后共享的内存被重复计算。
+// rusage中的峰值包含fork出子进程时服务自身占用的内存，不能反映程序的实际占用
+func watchMemory(pid int, limitKB int64, exceeded func()) func() int64 {
+	ns, _ := os.Readlink(fmt.Sprintf("/proc/%d/ns/pid", pid))
+	var (
+		mu      sync.Mutex
+		peak    int64
+		members = []int{pid}
+		ticks   int
+	)
+	sample := func() {
+		if ns != "" && ticks%memberScanTicks == 0 {
+			members = namespaceMembers(pid, ns)
+		}
+		ticks++
+
+		var usage int64
+		if len(members) == 1 {
+			usage = readProcKB(fmt.Sprintf("/proc/%d/status", pid), "VmHWM:")
+		} else {
+			for _, member := range members {
+				usage += readProcKB(fmt.Sprintf("/proc/%d/smaps_rollup", member), "Pss:")
+			}
+		}
+		mu.Lock()
+		over := limitKB > 0 && peak <= limitKB && usage > limitKB
+		peak = max(peak, usage)
+		mu.Unlock()
+		if over {
+			exceeded()
+		}
+	}
+
+	stop := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(memoryPollInterval)
+		defer ticker.Stop()
+		for {
+			sample()
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	return func() int64 {
+		close(stop)
+		<-stopped
+		mu.Lock()
+		defer mu.Unlock()
+		return peak
+	}
+}
+
+// namespaceMembers 返回PID命名空间ns中的所有进程，init进程pid排在最前
+func namespaceMembers(pid int, ns string) []int {
+	members := []int{pid}
+	names, err := readDirNames("/proc")
+	if err != nil {
+		return members
+	}
+	for _, name := range names {
+		member, err := strconv.Atoi(name)
+		if err != nil || member == pid {
+			continue
+		}
+		if link, _ := os.Readlink("/proc/" + name + "/ns/pid"); link == ns {
+			members = append(members, member)
+		}
+	}
+	return members
+}
+
+// readDirNames 读取目录中的文件名，不排序
+func readDirNames(dir string) ([]string, error) {
+	f, err := os.Open(dir)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return f.Readdirnames(-1)
+}
+
+// readProcKB 读取/proc下status、smaps_rollup等文件中以key开头的行的值(KB)，进程已结束时返回0
+func readProcKB(path, key string) int64 {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if value, ok := strings.CutPrefix(scanner.Text(), key); ok {
+			kb, _ := strconv.ParseInt(strings.TrimSuffix(strings.TrimSpace(value), " kB"), 10, 64)
+			return kb
+		}
+	}
+	return 0
+}
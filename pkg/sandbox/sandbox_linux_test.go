@@ -0,0 +1,270 @@
+package sandbox
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// run 用默认选项运行命令，命令无法启动时测试失败
+func run(t *testing.T, c *Command) *Result {
+	t.Helper()
+	sb, err := New(Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := sb.Run(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return result
+}
+
+// findProcesses 返回命令行参数中包含marker的进程，僵尸进程视为已结束
+func findProcesses(marker string) []int {
+	var pids []int
+	entries, _ := os.ReadDir("/proc")
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil {
+			continue
+		}
+		cmdline, err := os.ReadFile(fmt.Sprintf("/proc/%d/cmdline", pid))
+		if err != nil || !bytes.Contains(cmdline, []byte(marker)) {
+			continue
+		}
+		stat, err := os.ReadFile(fmt.Sprintf("/proc/%d/stat", pid))
+		if err != nil {
+			continue
+		}
+		// 状态字段位于以括号包围的进程名之后
+		if fields := strings.Fields(string(stat[bytes.LastIndexByte(stat, ')')+1:])); len(fields) > 0 && fields[0] != "Z" {
+			pids = append(pids, pid)
+		}
+	}
+	return pids
+}
+
+// waitExited 等待命令行参数中包含marker的进程全部结束，超时后返回仍在运行的进程
+func waitExited(marker string) []int {
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		pids := findProcesses(marker)
+		if len(pids) == 0 || time.Now().After(deadline) {
+			return pids
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestRunOK(t *testing.T) {
+	result := run(t, &Command{
+		Args:  []string{"sh", "-c", "read line; echo \"$line\" world; echo err >&2"},
+		Stdin: strings.NewReader("hello\n"),
+	})
+	if result.Status != StatusOK || result.ExitCode != 0 {
+		t.Fatalf("状态%s，退出码%d", result.Status, result.ExitCode)
+	}
+	if string(result.Stdout) != "hello world\n" || string(result.Stderr) != "err\n" {
+		t.Errorf("标准输出%q，标准错误%q", result.Stdout, result.Stderr)
+	}
+}
+
+func TestRunRuntimeError(t *testing.T) {
+	result := run(t, &Command{Args: []string{"sh", "-c", "exit 3"}})
+	if result.Status != StatusRuntimeError || result.ExitCode != 3 {
+		t.Errorf("状态%s，退出码%d", result.Status, result.ExitCode)
+	}
+}
+
+func TestCPUTimeLimit(t *testing.T) {
+	result := run(t, &Command{
+		Args:   []string{"sh", "-c", "while :; do :; done"},
+		Limits: Limits{CPUTime: time.Second},
+	})
+	if result.Status != StatusTimeLimit {
+		t.Fatalf("死循环应超出时间限制，得到%s", result.Status)
+	}
+	if result.WallTime > 5*time.Second {
+		t.Errorf("超过CPU时间后未及时结束，运行了%v", result.WallTime)
+	}
+}
+
+func TestWallTimeLimit(t *testing.T) {
+	result := run(t, &Command{
+		Args:   []string{"sleep", "30"},
+		Limits: Limits{CPUTime: time.Second, WallTime: 200 * time.Millisecond},
+	})
+	if result.Status != StatusTimeLimit || result.ExitCode != -1 {
+		t.Fatalf("状态%s，退出码%d", result.Status, result.ExitCode)
+	}
+	if result.WallTime > 5*time.Second {
+		t.Errorf("超过运行时间后未及时结束，运行了%v", result.WallTime)
+	}
+}
+
+func TestMemoryLimit(t *testing.T) {
+	// dd的缓冲区大小等于bs，读取/dev/zero时全部写入，实际占用约40MB，不超过2倍的虚拟内存上限
+	args := []string{"dd", "if=/dev/zero", "of=/dev/null", "bs=40M", "count=20"}
+
+	result := run(t, &Command{Args: args, Limits: Limits{MemoryMB: 32}})
+	if result.Status != StatusMemoryLimit {
+		t.Fatalf("应超出内存限制，得到%s，内存峰值%dKB，标准错误%q", result.Status, result.MemoryKB, result.Stderr)
+	}
+	if result.MemoryKB <= 32<<10 {
+		t.Errorf("内存峰值%dKB应超过限制", result.MemoryKB)
+	}
+
+	// 超过虚拟内存上限时分配失败
+	result = run(t, &Command{Args: args, Limits: Limits{MemoryMB: 8}})
+	if result.Status != StatusRuntimeError {
+		t.Errorf("超过虚拟内存上限时应为运行错误，得到%s", result.Status)
+	}
+
+	result = run(t, &Command{Args: args, Limits: Limits{MemoryMB: 128}})
+	if result.Status != StatusOK {
+		t.Errorf("未超出内存限制时应正常结束，得到%s，标准错误%q", result.Status, result.Stderr)
+	}
+}
+
+func TestOutputLimit(t *testing.T) {
+	result := run(t, &Command{
+		Args:   []string{"yes"},
+		Limits: Limits{OutputBytes: 1024, WallTime: 10 * time.Second},
+	})
+	if result.Status != StatusOutputLimit {
+		t.Fatalf("应超出输出限制，得到%s", result.Status)
+	}
+	if len(result.Stdout) != 1024 {
+		t.Errorf("应保留1024字节输出，保留了%d字节", len(result.Stdout))
+	}
+	if result.WallTime > 5*time.Second {
+		t.Errorf("输出超限后未及时结束，运行了%v", result.WallTime)
+	}
+
+	// 截断输出时继续运行到结束
+	result = run(t, &Command{
+		Args:           []string{"head", "-c", "5000", "/dev/zero"},
+		Limits:         Limits{OutputBytes: 1024},
+		TruncateOutput: true,
+	})
+	if result.Status != StatusOK || len(result.Stdout) != 1024 {
+		t.Errorf("状态%s，保留了%d字节", result.Status, len(result.Stdout))
+	}
+}
+
+func TestKillProcessGroup(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		limits Limits
+		status Status
+	}{
+		// 超时后结束所有进程，包括仍在运行的子进程
+		{"超时", "sleep %s & wait", Limits{WallTime: 200 * time.Millisecond}, StatusTimeLimit},
+		// 程序正常退出后结束留在后台的子进程
+		{"正常退出", "sleep %s >/dev/null 2>&1 &", Limits{}, StatusOK},
+		// 用setsid脱离进程组的子进程也随程序结束
+		{"脱离进程组", "setsid sleep %s >/dev/null 2>&1 &", Limits{}, StatusOK},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 以不常见的时长作为标记，在/proc中查找残留的子进程
+			marker := fmt.Sprintf("30.%d%d", os.Getpid(), i)
+			result := run(t, &Command{Args: []string{"sh", "-c", fmt.Sprintf(tt.script, marker)}, Limits: tt.limits})
+			if result.Status != tt.status {
+				t.Fatalf("状态%s，应为%s，标准错误%q", result.Status, tt.status, result.Stderr)
+			}
+			if pids := waitExited(marker); len(pids) > 0 {
+				t.Errorf("子进程%v仍在运行", pids)
+			}
+		})
+	}
+}
+
+func TestMemoryLimitIncludesChildren(t *testing.T) {
+	// 4个子进程各占用约24MB，单个进程都不超过限制，合计超过
+	script := "for i in 1 2 3 4; do dd if=/dev/zero of=/dev/null bs=24M count=1000 & done; wait"
+	result := run(t, &Command{
+		Args:   []string{"sh", "-c", script},
+		Limits: Limits{MemoryMB: 64, WallTime: 30 * time.Second},
+	})
+	if result.Status != StatusMemoryLimit {
+		t.Fatalf("子进程合计应超出内存限制，得到%s，内存峰值%dKB，标准错误%q", result.Status, result.MemoryKB, result.Stderr)
+	}
+	if result.WallTime > 10*time.Second {
+		t.Errorf("超出内存限制后未及时结束，运行了%v", result.WallTime)
+	}
+}
+
+func TestProcessLimit(t *testing.T) {
+	if os.Geteuid() != 0 {
+		t.Skip("切换用户需要以root运行测试")
+	}
+	// 进程数按用户统计，使用不常见的UID避免与系统中的其他进程合并计算
+	sb, err := New(Options{UID: 64917, GID: 64917})
+	if err != nil {
+		t.Fatal(err)
+	}
+	script := "i=0; while [ $i -lt 20 ]; do sleep 1 & i=$((i+1)); done; wait"
+	result, err := sb.Run(&Command{
+		Args:   []string{"sh", "-c", script},
+		Limits: Limits{Processes: 5, WallTime: 10 * time.Second},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(result.Stderr, []byte("fork")) {
+		t.Errorf("超过进程数上限后应无法创建进程，状态%s，标准错误%q", result.Status, result.Stderr)
+	}
+}
+
+func TestIsolateNetwork(t *testing.T) {
+	hostNS, err := os.Readlink("/proc/self/ns/net")
+	if err != nil {
+		t.Skipf("无法读取网络命名空间: %v", err)
+	}
+	sb, err := New(Options{IsolateNetwork: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := sb.Run(&Command{Args: []string{"sh", "-c", "readlink /proc/self/ns/net && cat /proc/self/net/dev"}})
+	if err != nil {
+		t.Skipf("当前环境不支持创建网络命名空间: %v", err)
+	}
+	if result.Status != StatusOK {
+		t.Fatalf("状态%s，标准错误%q", result.Status, result.Stderr)
+	}
+
+	ns, devices, _ := strings.Cut(string(result.Stdout), "\n")
+	if ns == hostNS {
+		t.Fatalf("进程应在独立的网络命名空间中运行，与服务同为%s", ns)
+	}
+	// 新的网络命名空间中只有回环网卡
+	for _, line := range strings.Split(devices, "\n")[2:] {
+		if name, _, ok := strings.Cut(strings.TrimSpace(line), ":"); ok && name != "lo" {
+			t.Errorf("独立的网络命名空间中不应有网卡%s", name)
+		}
+	}
+}
+
+func TestRunAsUser(t *testing.T) {
+	if os.Geteuid() != 0 {
+		t.Skip("切换用户需要以root运行测试")
+	}
+	sb, err := New(Options{UID: 65534, GID: 65534})
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := sb.Run(&Command{Args: []string{"id", "-u"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.TrimSpace(string(result.Stdout)) != "65534" {
+		t.Errorf("应以用户65534运行，得到%q", result.Stdout)
+	}
+}
@@ -0,0 +1,18 @@
+//go:build !linux
+
+package sandbox
+
+import (
+	"os"
+	"syscall"
+)
+
+const supported = false
+
+func sysProcAttr(Options) *syscall.SysProcAttr { return nil }
+
+func killGroup(int) {}
+
+func cpuLimitSignaled(*os.ProcessState) bool { return false }
+
+func watchMemory(int, int64, func()) func() int64 { return func() int64 { return 0 } }
@@ -0,0 +1,206 @@
+// Package similarity 计算文本和代码的指纹及相似度，用于发现相互抄袭的提交
+//
+// 代码先按词法单元归一化（去掉空白和注释，标识符、数字、字符串分别替换为同一记号，保留关键字和符号），
+// 再对连续codeK个词法单元的哈希做winnowing选取指纹，改变量名、调整格式和注释不影响结果；
+// 文本按连续textK个词（中文按单字）取shingle作为指纹。相似度为两份指纹集合的Jaccard系数
+package similarity
+
+import (
+	"hash/fnv"
+	"strings"
+	"unicode"
+)
+
+const (
+	codeK      = 5 // 代码指纹覆盖的词法单元数
+	codeWindow = 4 // winnowing窗口大小，长度不小于codeK+codeWindow-1的相同片段一定能被发现
+	textK      = 5 // 文本shingle包含的词数
+)
+
+// Fingerprint 指纹集合
+type Fingerprint map[uint64]struct{}
+
+// codeKeywords 常见语言的关键字，归一化时保留原文，其他标识符统一替换
+var codeKeywords = make(map[string]bool)
+
+func init() {
+	for _, word := range strings.Fields(`
+		break case chan const continue default defer else fallthrough for func go goto if import interface map package range return select struct switch type var
+		and as assert async await class def del elif except finally from global in is lambda nonlocal not or pass raise try while with yield None True False
+		auto bool char catch delete do double enum explicit extern float friend inline int long namespace new operator private protected public
+		register short signed sizeof static template this throw typedef typename union unsigned using virtual void volatile include define
+		abstract boolean byte extends final implements instanceof native super synchronized throws transient
+		function let of typeof undefined null true false export`) {
+		codeKeywords[word] = true
+	}
+}
+
+// Code 计算代码的winnowing指纹，词法单元少于codeK个时返回空集合
+func Code(source string) Fingerprint {
+	tokens := codeTokens(source)
+	if len(tokens) < codeK {
+		return Fingerprint{}
+	}
+	hashes := make([]uint64, 0, len(tokens)-codeK+1)
+	for i := 0; i+codeK <= len(tokens); i++ {
+		hashes = append(hashes, hashStrings(tokens[i:i+codeK]))
+	}
+	return winnow(hashes, codeWindow)
+}
+
+// Text 计算文本的shingle指纹，词数少于textK个时返回空集合
+func Text(text string) Fingerprint {
+	words := textWords(text)
+	fp := Fingerprint{}
+	for i := 0; i+textK <= len(words); i++ {
+		fp[hashStrings(words[i:i+textK])] = struct{}{}
+	}
+	return fp
+}
+
+// Jaccard 返回两份指纹的Jaccard系数和共同的指纹数，任一为空时相似度为0
+func Jaccard(a, b Fingerprint) (float64, int) {
+	if len(a) == 0 || len(b) == 0 {
+		return 0, 0
+	}
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+	shared := 0
+	for h := range a {
+		if _, ok := b[h]; ok {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(a)+len(b)-shared), shared
+}
+
+// LooksLikeCode 粗略判断文本是否为代码：至少两行，且括号、分号等符号在非空白字符中的占比较高
+func LooksLikeCode(text string) bool {
+	if strings.Count(strings.TrimSpace(text), "\n") < 1 {
+		return false
+	}
+	symbols, total := 0, 0
+	for _, r := range text {
+		if unicode.IsSpace(r) {
+			continue
+		}
+		total++
+		if strings.ContainsRune("{}()[];=<>", r) {
+			symbols++
+		}
+	}
+	return total > 0 && symbols*20 >= total
+}
+
+// codeTokens 将代码切分为归一化的词法单元
+func codeTokens(source string) []string {
+	src := []rune(source)
+	var tokens []string
+	for i := 0; i < len(src); {
+		r := src[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '/' && i+1 < len(src) && src[i+1] == '/', r == '#':
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+		case r == '/' && i+1 < len(src) && src[i+1] == '*':
+			i += 2
+			for i+1 < len(src) && !(src[i] == '*' && src[i+1] == '/') {
+				i++
+			}
+			i += 2
+		case r == '"' || r == '\'' || r == '`':
+			i++
+			for i < len(src) && src[i] != r {
+				if src[i] == '\\' {
+					i++
+				}
+				i++
+			}
+			i++
+			tokens = append(tokens, "S")
+		case r == '_' || unicode.IsLetter(r):
+			start := i
+			for i < len(src) && (src[i] == '_' || unicode.IsLetter(src[i]) || unicode.IsDigit(src[i])) {
+				i++
+			}
+			if word := string(src[start:i]); codeKeywords[word] {
+				tokens = append(tokens, word)
+			} else {
+				tokens = append(tokens, "I")
+			}
+		case unicode.IsDigit(r):
+			for i < len(src) && (src[i] == '.' || src[i] == '_' || unicode.IsLetter(src[i]) || unicode.IsDigit(src[i])) {
+				i++
+			}
+			tokens = append(tokens, "N")
+		default:
+			tokens = append(tokens, string(r))
+			i++
+		}
+	}
+	return tokens
+}
+
+// textWords 将文本切分为小写的词，中日韩文字每个字作为一个词，标点忽略
+func textWords(text string) []string {
+	var words []string
+	var word []rune
+	flush := func() {
+		if len(word) > 0 {
+			words = append(words, string(word))
+			word = word[:0]
+		}
+	}
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case unicode.Is(unicode.Han, r) || unicode.Is(unicode.Hiragana, r) || unicode.Is(unicode.Katakana, r) || unicode.Is(unicode.Hangul, r):
+			flush()
+			words = append(words, string(r))
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			word = append(word, r)
+		default:
+			flush()
+		}
+	}
+	flush()
+	return words
+}
+
+// winnow 在每个长度为window的窗口中选取最小的哈希（相同时取最右边的），相邻窗口选中同一位置时只记录一次
+func winnow(hashes []uint64, window int) Fingerprint {
+	fp := Fingerprint{}
+	if len(hashes) <= window {
+		for _, h := range hashes {
+			fp[h] = struct{}{}
+		}
+		return fp
+	}
+	selected := -1
+	for start := 0; start+window <= len(hashes); start++ {
+		smallest := start
+		for i := start + 1; i < start+window; i++ {
+			if hashes[i] <= hashes[smallest] {
+				smallest = i
+			}
+		}
+		if smallest != selected {
+			fp[hashes[smallest]] = struct{}{}
+			selected = smallest
+		}
+	}
+	return fp
+}
+
+// hashStrings 计算一组字符串的64位FNV-1a哈希
+func hashStrings(parts []string) uint64 {
+	h := fnv.New64a()
+	for _, part := range parts {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return h.Sum64()
+}
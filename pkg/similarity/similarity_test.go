@@ -0,0 +1,153 @@
+package similarity
+
+import (
+	"strings"
+	"testing"
+)
+
+const original = `package main
+
+import "fmt"
+
+// bubbleSort 冒泡排序
+func bubbleSort(nums []int) []int {
+	n := len(nums)
+	for i := 0; i < n; i++ {
+		swapped := false
+		for j := 0; j < n-i-1; j++ {
+			if nums[j] > nums[j+1] {
+				nums[j], nums[j+1] = nums[j+1], nums[j]
+				swapped = true
+			}
+		}
+		if !swapped {
+			break
+		}
+	}
+	return nums
+}
+
+func main() {
+	data := []int{5, 2, 9, 1, 7}
+	fmt.Println(bubbleSort(data))
+}
+`
+
+// renamed 与original相同，只修改了标识符、字面量、注释和格式
+const renamed = `package main
+import "fmt"
+/* 排序 */
+func sortNumbers(arr []int) []int { length := len(arr)
+  for a := 0; a < length; a++ { changed := false
+    for b := 0; b < length-a-1; b++ {
+      if arr[b] > arr[b+1] { arr[b], arr[b+1] = arr[b+1], arr[b]; changed = true }
+    }
+    if !changed { break } // 已有序
+  }
+  return arr
+}
+func main() { values := []int{3, 8, 4, 6, 0, 11}; fmt.Println(sortNumbers(values)) }
+`
+
+const unrelated = `package main
+
+import (
+	"net/http"
+	"strings"
+)
+
+type server struct {
+	routes map[string]http.HandlerFunc
+}
+
+func (s *server) handle(path string, handler http.HandlerFunc) {
+	s.routes[strings.TrimSuffix(path, "/")] = handler
+}
+
+func (s *server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	handler, ok := s.routes[strings.TrimSuffix(r.URL.Path, "/")]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	handler(w, r)
+}
+`
+
+func TestCodeSimilarity(t *testing.T) {
+	base := Code(original)
+	if len(base) < 10 {
+		t.Fatalf("指纹过少: %d", len(base))
+	}
+
+	if score, _ := Jaccard(base, Code(original)); score != 1 {
+		t.Errorf("相同代码的相似度应为1，得到%.3f", score)
+	}
+	if score, shared := Jaccard(base, Code(renamed)); score < 0.8 {
+		t.Errorf("改名和调整格式后相似度应不低于0.8，得到%.3f（共同指纹%d个）", score, shared)
+	}
+	if score, _ := Jaccard(base, Code(unrelated)); score > 0.2 {
+		t.Errorf("无关代码的相似度应低于0.2，得到%.3f", score)
+	}
+
+	// 原样抄袭后追加无关代码，共同部分仍能被发现
+	if score, _ := Jaccard(base, Code(original+unrelated)); score < 0.3 {
+		t.Errorf("包含完整抄袭片段时相似度过低: %.3f", score)
+	}
+}
+
+func TestCodeTokens(t *testing.T) {
+	tokens := codeTokens("for i := 0; i < n; i++ { s += \"a\\\"b\" } // 注释\n/* 块\n注释 */ # 井号注释\nreturn x1")
+	want := "for I : = N ; I < I ; I + + { I + = S } return I"
+	if got := strings.Join(tokens, " "); got != want {
+		t.Errorf("词法单元为%q，应为%q", got, want)
+	}
+
+	if fp := Code("x = 1"); len(fp) != 0 {
+		t.Errorf("过短的代码应返回空指纹，得到%d个", len(fp))
+	}
+}
+
+func TestTextSimilarity(t *testing.T) {
+	answer := "我认为这道题的关键在于先对数组排序，然后使用双指针从两端向中间移动，时间复杂度为O(n log n)。"
+	copied := "我认为，这道题的关键在于：先对数组排序；然后使用双指针从两端向中间移动！时间复杂度为O(N LOG N)"
+	other := "本题可以用哈希表记录每个数字出现的位置，遍历一次即可找到答案，空间换时间。"
+
+	if score, _ := Jaccard(Text(answer), Text(copied)); score != 1 {
+		t.Errorf("只改标点和大小写的文本相似度应为1，得到%.3f", score)
+	}
+	if score, _ := Jaccard(Text(answer), Text(other)); score > 0.1 {
+		t.Errorf("无关文本的相似度应接近0，得到%.3f", score)
+	}
+	if words := textWords("Hello, 世界 foo_bar"); strings.Join(words, "|") != "hello|世|界|foo|bar" {
+		t.Errorf("分词结果%q", words)
+	}
+}
+
+func TestJaccard(t *testing.T) {
+	a := Fingerprint{1: {}, 2: {}, 3: {}}
+	b := Fingerprint{2: {}, 3: {}, 4: {}, 5: {}}
+	if score, shared := Jaccard(a, b); score != 0.4 || shared != 2 {
+		t.Errorf("相似度%.3f，共同指纹%d个", score, shared)
+	}
+	if score, shared := Jaccard(a, Fingerprint{}); score != 0 || shared != 0 {
+		t.Errorf("空指纹的相似度应为0，得到%.3f", score)
+	}
+}
+
+func TestLooksLikeCode(t *testing.T) {
+	tests := []struct {
+		text string
+		want bool
+	}{
+		{original, true},
+		{"int main() { return 0; }", false},
+		{"第一步先读题。\n第二步写代码，然后提交。", false},
+		{"x = f(a[0]);\ny = g(x);", true},
+	}
+	for _, tt := range tests {
+		if got := LooksLikeCode(tt.text); got != tt.want {
+			t.Errorf("%q: %v，应为%v", tt.text, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+
+	"github.com/tksky1/glimgate/pkg/config"
+)
+
+// ErrNotExist 文件不存在
+var ErrNotExist = errors.New("文件不存在")
+
+// File 打开的文件，支持随机读取以便处理断点续传
+type File interface {
+	io.ReadSeekCloser
+}
+
+// Storage 文件存储，key为以/分隔的相对路径
+type Storage interface {
+	// Put 写入文件，key已存在时覆盖
+	Put(key string, r io.Reader) error
+	// Open 打开文件，不存在时返回ErrNotExist
+	Open(key string) (File, error)
+	// Delete 删除文件，不存在时不报错
+	Delete(key string) error
+}
+
+// InitStorage 按配置创建附件存储
+func InitStorage() (Storage, error) {
+	cfg := &config.AppConfig.Storage
+	switch cfg.GetDriver() {
+	case config.StorageLocal:
+		store, err := NewLocal(cfg.GetLocalPath())
+		if err != nil {
+			return nil, err
+		}
+		log.Printf("附件存储初始化成功(local: %s)", cfg.GetLocalPath())
+		return store, nil
+	default:
+		return nil, fmt.Errorf("不支持的存储类型: %s", cfg.GetDriver())
+	}
+}
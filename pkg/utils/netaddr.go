@@ -0,0 +1,95 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"syscall"
+)
+
+var (
+	// ErrInvalidURL 地址不是http或https协议的绝对地址
+	ErrInvalidURL = errors.New("地址格式错误，仅支持http和https")
+	// ErrInternalHost 地址指向回环、私有网段等内网地址
+	ErrInternalHost = errors.New("不允许访问内网地址")
+)
+
+// reservedNets 标准库未覆盖的保留网段：本网络、运营商级NAT、IETF协议分配、基准测试、E类保留地址和NAT64前缀
+var reservedNets = func() []*net.IPNet {
+	var nets []*net.IPNet
+	for _, cidr := range []string{"0.0.0.0/8", "100.64.0.0/10", "192.0.0.0/24", "198.18.0.0/15", "240.0.0.0/4", "64:ff9b::/96"} {
+		_, n, _ := net.ParseCIDR(cidr)
+		nets = append(nets, n)
+	}
+	return nets
+}()
+
+// IsInternalIP 判断IP是否为回环、私有、链路本地、未指定、组播或保留地址
+func IsInternalIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return true
+	}
+	for _, n := range reservedNets {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckPublicURL 检查地址为http或https协议，且主机解析出的全部地址都不是内网地址
+// 检查后域名解析结果仍可能改变，发起请求时还需用PublicDialControl限制连接的地址
+func CheckPublicURL(ctx context.Context, raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return ErrInvalidURL
+	}
+	return CheckPublicHost(ctx, u.Hostname())
+}
+
+// CheckPublicHost 检查主机名或IP解析出的全部地址都不是内网地址
+func CheckPublicHost(ctx context.Context, host string) error {
+	_, err := ResolvePublicHost(ctx, host)
+	return err
+}
+
+// ResolvePublicHost 解析主机名或IP，任一地址是内网地址时返回ErrInternalHost
+// 调用方可固定使用返回的地址建立连接，避免检查后域名解析结果改变
+func ResolvePublicHost(ctx context.Context, host string) ([]net.IP, error) {
+	if ip := net.ParseIP(host); ip != nil {
+		if IsInternalIP(ip) {
+			return nil, ErrInternalHost
+		}
+		return []net.IP{ip}, nil
+	}
+
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return nil, fmt.Errorf("解析域名%s失败: %w", host, err)
+	}
+	ips := make([]net.IP, len(addrs))
+	for i, addr := range addrs {
+		if IsInternalIP(addr.IP) {
+			return nil, ErrInternalHost
+		}
+		ips[i] = addr.IP
+	}
+	return ips, nil
+}
+
+// PublicDialControl 用作net.Dialer的Control，拒绝连接内网地址
+// 在建立连接时检查实际连接的IP，重定向和检查后变化的域名解析结果同样受限
+func PublicDialControl(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || IsInternalIP(ip) {
+		return fmt.Errorf("%w: %s", ErrInternalHost, host)
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+)
+
+func TestIsInternalIP(t *testing.T) {
+	tests := []struct {
+		ip       string
+		internal bool
+	}{
+		{"127.0.0.1", true},
+		{"10.1.2.3", true},
+		{"172.16.0.1", true},
+		{"192.168.1.1", true},
+		{"169.254.169.254", true},
+		{"0.0.0.0", true},
+		{"100.64.0.1", true},
+		{"224.0.0.1", true},
+		{"::1", true},
+		{"fe80::1", true},
+		{"fd00::1", true},
+		{"::ffff:127.0.0.1", true},
+		{"8.8.8.8", false},
+		{"172.32.0.1", false},
+		{"2001:4860:4860::8888", false},
+	}
+	for _, tt := range tests {
+		if got := IsInternalIP(net.ParseIP(tt.ip)); got != tt.internal {
+			t.Errorf("IsInternalIP(%s) = %v，应为%v", tt.ip, got, tt.internal)
+		}
+	}
+}
+
+func TestCheckPublicURL(t *testing.T) {
+	tests := []struct {
+		url  string
+		want error
+	}{
+		{"https://8.8.8.8/hook", nil},
+		{"http://[2001:4860:4860::8888]:8080/hook", nil},
+		{"ftp://8.8.8.8/hook", ErrInvalidURL},
+		{"javascript:alert(1)", ErrInvalidURL},
+		{"/relative/hook", ErrInvalidURL},
+		{"http://", ErrInvalidURL},
+		{"http://127.0.0.1:5700/send_private_msg", ErrInternalHost},
+		{"http://[::1]/hook", ErrInternalHost},
+		{"http://169.254.169.254/latest/meta-data", ErrInternalHost},
+		{"http://localhost:5700/", ErrInternalHost},
+	}
+	for _, tt := range tests {
+		if err := CheckPublicURL(context.Background(), tt.url); !errors.Is(err, tt.want) {
+			t.Errorf("CheckPublicURL(%q) = %v，应为%v", tt.url, err, tt.want)
+		}
+	}
+}
+
+func TestPublicDialControl(t *testing.T) {
+	if err := PublicDialControl("tcp", "8.8.8.8:443", nil); err != nil {
+		t.Errorf("公网地址不应被拒绝: %v", err)
+	}
+	for _, addr := range []string{"127.0.0.1:5700", "[::1]:80", "10.0.0.1:3306"} {
+		if err := PublicDialControl("tcp", addr, nil); !errors.Is(err, ErrInternalHost) {
+			t.Errorf("%s应被拒绝，得到%v", addr, err)
+		}
+	}
+}